@@ -13,6 +13,7 @@ import (
 	"github.com/libopenstorage/operator/pkg/controller/storagecluster"
 	_ "github.com/libopenstorage/operator/pkg/log"
 	"github.com/libopenstorage/operator/pkg/version"
+	"github.com/libopenstorage/operator/pkg/webhook"
 	"github.com/operator-framework/operator-sdk/pkg/metrics"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
@@ -29,6 +30,11 @@ const (
 	flagLeaderElectLockName      = "leader-elect-lock-name"
 	flagLeaderElectLockNamespace = "leader-elect-lock-namespace"
 	flagMetricsPort              = "metrics-port"
+	flagWebhook                  = "webhook"
+	flagWebhookPort              = "webhook-port"
+	flagWebhookCertDir           = "webhook-cert-dir"
+	flagWebhookServiceName       = "webhook-service-name"
+	flagWebhookServiceNamespace  = "webhook-service-namespace"
 	defaultLockObjectName        = "openstorage-operator"
 	defaultLockObjectNamespace   = "kube-system"
 	defaultResyncPeriod          = 30 * time.Second
@@ -71,6 +77,30 @@ func main() {
 			Usage: "Port on which the operator metrics are to be exposed",
 			Value: defaultMetricsPort,
 		},
+		cli.BoolFlag{
+			Name:  flagWebhook,
+			Usage: "Enable the operator webhooks. Required to serve the v1beta1 APIs",
+		},
+		cli.IntFlag{
+			Name:  flagWebhookPort,
+			Usage: "Port on which the operator webhooks are served",
+			Value: webhook.DefaultPort,
+		},
+		cli.StringFlag{
+			Name:  flagWebhookCertDir,
+			Usage: "Directory where the webhook serving certificates are stored",
+			Value: webhook.DefaultCertDir,
+		},
+		cli.StringFlag{
+			Name:  flagWebhookServiceName,
+			Usage: "Name of the service fronting the operator webhooks",
+			Value: webhook.DefaultServiceName,
+		},
+		cli.StringFlag{
+			Name:  flagWebhookServiceNamespace,
+			Usage: "Namespace of the service fronting the operator webhooks",
+			Value: defaultLockObjectNamespace,
+		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -160,6 +190,18 @@ func run(c *cli.Context) {
 		log.Fatalf("Error initializing storage cluster controller: %v", err)
 	}
 
+	if c.Bool(flagWebhook) {
+		webhookConfig := webhook.Config{
+			ServiceName:      c.String(flagWebhookServiceName),
+			ServiceNamespace: c.String(flagWebhookServiceNamespace),
+			CertDir:          c.String(flagWebhookCertDir),
+			Driver:           d,
+		}
+		if err := webhook.Setup(mgr, webhookConfig); err != nil {
+			log.Fatalf("Error setting up webhooks: %v", err)
+		}
+	}
+
 	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
 		log.Fatalf("Manager exited non-zero error: %v", err)
 	}
@@ -171,6 +213,10 @@ func createManager(c *cli.Context, config *rest.Config) (manager.Manager, error)
 		SyncPeriod:         &syncPeriod,
 		MetricsBindAddress: fmt.Sprintf("0.0.0.0:%d", c.Int(flagMetricsPort)),
 	}
+	if c.Bool(flagWebhook) {
+		managerOpts.Port = c.Int(flagWebhookPort)
+		managerOpts.CertDir = c.String(flagWebhookCertDir)
+	}
 	if c.BoolT(flagLeaderElect) {
 		managerOpts.LeaderElection = true
 		managerOpts.LeaderElectionID = c.String(flagLeaderElectLockName)
@@ -0,0 +1,331 @@
+package portworx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/libopenstorage/openstorage/api"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/libopenstorage/operator/pkg/util"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// alertsLookback is how far back alerts are fetched when the operator
+	// starts, so alerts raised while it was restarting are not missed
+	alertsLookback = 10 * time.Minute
+	// maxStatusAlerts is the number of alerts shown in the cluster status
+	maxStatusAlerts = 10
+)
+
+// alertCursor tracks the alerts of a storage cluster that have been raised
+type alertCursor struct {
+	// since is the time from which the alerts are fetched next
+	since time.Time
+	// seen is the time of the alerts raised at or after since, so they are not
+	// raised again when fetched with the next window
+	seen map[string]time.Time
+}
+
+// updateAlerts fetches the alerts raised since the last time they were fetched
+// and raises an event for each new alert on the object it relates to: the
+// StorageNode for node alerts, the PVC for volume alerts and the StorageCluster
+// otherwise. The most recent active alerts are kept in the cluster status.
+// The given StorageNodes are keyed by the ID of the portworx node.
+func (p *portworx) updateAlerts(
+	clientConn *grpc.ClientConn,
+	cluster *corev1alpha1.StorageCluster,
+	storageNodes map[string]*corev1alpha1.StorageNode,
+) error {
+	key := clusterKey(cluster)
+	cursor, exists := p.alertCursors[key]
+	if !exists {
+		if p.alertCursors == nil {
+			p.alertCursors = make(map[string]*alertCursor)
+		}
+		cursor = &alertCursor{
+			since: time.Now().Add(-alertsLookback),
+			seen:  make(map[string]time.Time),
+		}
+		p.alertCursors[key] = cursor
+	}
+
+	alerts, err := p.enumerateAlerts(clientConn, cursor.since)
+	if err != nil {
+		return err
+	}
+
+	// Alerts are processed oldest first, so the latest occurrence of an alert
+	// is the one that ends up in the status
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alertTime(alerts[i]).Before(alertTime(alerts[j]))
+	})
+
+	statusAlerts := make(map[string]corev1alpha1.StorageAlert)
+	for _, alert := range cluster.Status.Alerts {
+		statusAlerts[statusAlertKey(alert.ResourceType, alert.ResourceID, alert.Message)] = alert
+	}
+
+	latest := cursor.since
+	for _, alert := range alerts {
+		seenKey := alertKey(alert)
+		timestamp := alertTime(alert)
+		if timestamp.After(latest) {
+			latest = timestamp
+		}
+
+		statusKey := statusAlertKey(mapAlertResourceType(alert.Resource), alert.ResourceId, alert.Message)
+		if alert.Cleared {
+			delete(statusAlerts, statusKey)
+		} else {
+			statusAlerts[statusKey] = corev1alpha1.StorageAlert{
+				Severity:     mapAlertSeverity(alert.Severity),
+				ResourceType: mapAlertResourceType(alert.Resource),
+				ResourceID:   alert.ResourceId,
+				Message:      alert.Message,
+				Count:        alert.Count,
+				LastSeen:     metav1.NewTime(timestamp),
+			}
+		}
+
+		// Alerts older than the fetched window have already been raised or
+		// were raised before the operator started
+		if alert.Cleared || timestamp.Before(cursor.since) {
+			continue
+		} else if seen, exists := cursor.seen[seenKey]; exists && !timestamp.After(seen) {
+			continue
+		}
+		cursor.seen[seenKey] = timestamp
+		p.raiseAlertEvent(clientConn, cluster, alert, storageNodes)
+	}
+
+	// Forget the alerts older than the next window, as they will not be
+	// fetched again
+	cursor.since = latest
+	for seenKey, seen := range cursor.seen {
+		if seen.Before(cursor.since) {
+			delete(cursor.seen, seenKey)
+		}
+	}
+
+	cluster.Status.Alerts = make([]corev1alpha1.StorageAlert, 0, len(statusAlerts))
+	for _, alert := range statusAlerts {
+		cluster.Status.Alerts = append(cluster.Status.Alerts, alert)
+	}
+	sort.Slice(cluster.Status.Alerts, func(i, j int) bool {
+		a, b := cluster.Status.Alerts[i], cluster.Status.Alerts[j]
+		if !a.LastSeen.Equal(&b.LastSeen) {
+			return b.LastSeen.Before(&a.LastSeen)
+		}
+		return statusAlertKey(a.ResourceType, a.ResourceID, a.Message) <
+			statusAlertKey(b.ResourceType, b.ResourceID, b.Message)
+	})
+	if len(cluster.Status.Alerts) > maxStatusAlerts {
+		cluster.Status.Alerts = cluster.Status.Alerts[:maxStatusAlerts]
+	} else if len(cluster.Status.Alerts) == 0 {
+		cluster.Status.Alerts = nil
+	}
+	return nil
+}
+
+// enumerateAlerts returns the alerts of all the resources raised since the given time
+func (p *portworx) enumerateAlerts(
+	clientConn *grpc.ClientConn,
+	since time.Time,
+) ([]*api.Alert, error) {
+	startTime, err := ptypes.TimestampProto(since)
+	if err != nil {
+		return nil, err
+	}
+	endTime, err := ptypes.TimestampProto(time.Now().Add(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+
+	request := &api.SdkAlertsEnumerateWithFiltersRequest{}
+	for _, resourceType := range []api.ResourceType{
+		api.ResourceType_RESOURCE_TYPE_CLUSTER,
+		api.ResourceType_RESOURCE_TYPE_NODE,
+		api.ResourceType_RESOURCE_TYPE_DRIVE,
+		api.ResourceType_RESOURCE_TYPE_VOLUME,
+	} {
+		request.Queries = append(request.Queries, &api.SdkAlertsQuery{
+			Query: &api.SdkAlertsQuery_ResourceTypeQuery{
+				ResourceTypeQuery: &api.SdkAlertsResourceTypeQuery{
+					ResourceType: resourceType,
+				},
+			},
+			Opts: []*api.SdkAlertsOption{
+				{
+					Opt: &api.SdkAlertsOption_TimeSpan{
+						TimeSpan: &api.SdkAlertsTimeSpan{
+							StartTime: startTime,
+							EndTime:   endTime,
+						},
+					},
+				},
+			},
+		})
+	}
+
+	alertsClient := api.NewOpenStorageAlertsClient(clientConn)
+	stream, err := alertsClient.EnumerateWithFilters(context.TODO(), request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate alerts: %v", err)
+	}
+
+	var alerts []*api.Alert
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to enumerate alerts: %v", err)
+		}
+		for _, alert := range resp.Alerts {
+			if alert != nil {
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+	return alerts, nil
+}
+
+// raiseAlertEvent raises an event for the given alert on the object it relates to
+func (p *portworx) raiseAlertEvent(
+	clientConn *grpc.ClientConn,
+	cluster *corev1alpha1.StorageCluster,
+	alert *api.Alert,
+	storageNodes map[string]*corev1alpha1.StorageNode,
+) {
+	eventType := v1.EventTypeNormal
+	if alert.Severity == api.SeverityType_SEVERITY_TYPE_ALARM ||
+		alert.Severity == api.SeverityType_SEVERITY_TYPE_WARNING {
+		eventType = v1.EventTypeWarning
+	}
+
+	var object runtime.Object
+	switch alert.Resource {
+	case api.ResourceType_RESOURCE_TYPE_NODE:
+		if storageNode, exists := storageNodes[alert.ResourceId]; exists {
+			object = storageNode
+		}
+	case api.ResourceType_RESOURCE_TYPE_VOLUME:
+		pvc, err := p.getVolumeClaim(clientConn, alert.ResourceId)
+		if err != nil {
+			logrus.Debugf("Failed to get PVC of volume %s: %v", alert.ResourceId, err)
+		} else if pvc != nil {
+			object = pvc
+		}
+	}
+
+	if object != nil {
+		p.recorder.Event(object, eventType, util.StorageAlertReason, alert.Message)
+		return
+	}
+	message := alert.Message
+	if alert.Resource != api.ResourceType_RESOURCE_TYPE_CLUSTER && alert.ResourceId != "" {
+		message = fmt.Sprintf("%s %s: %s", mapAlertResourceType(alert.Resource), alert.ResourceId, alert.Message)
+	}
+	p.recorder.Event(cluster, eventType, util.StorageAlertReason, message)
+}
+
+// getVolumeClaim returns the PVC bound to the given portworx volume. It
+// returns nil if the volume is not used by a PVC.
+func (p *portworx) getVolumeClaim(
+	clientConn *grpc.ClientConn,
+	volumeID string,
+) (*v1.PersistentVolumeClaim, error) {
+	volumeClient := api.NewOpenStorageVolumeClient(clientConn)
+	resp, err := volumeClient.Inspect(
+		context.TODO(),
+		&api.SdkVolumeInspectRequest{VolumeId: volumeID},
+	)
+	if err != nil {
+		return nil, err
+	} else if resp.Volume == nil || resp.Volume.Locator == nil {
+		return nil, nil
+	}
+
+	// Volumes provisioned for a PVC are named after the PV
+	pv := &v1.PersistentVolume{}
+	err = p.k8sClient.Get(context.TODO(), types.NamespacedName{Name: resp.Volume.Locator.Name}, pv)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else if pv.Spec.ClaimRef == nil {
+		return nil, nil
+	}
+
+	pvc := &v1.PersistentVolumeClaim{}
+	err = p.k8sClient.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      pv.Spec.ClaimRef.Name,
+			Namespace: pv.Spec.ClaimRef.Namespace,
+		},
+		pvc,
+	)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return pvc, nil
+}
+
+func alertKey(alert *api.Alert) string {
+	return fmt.Sprintf("%d/%d/%s/%s", alert.Resource, alert.AlertType, alert.ResourceId, alert.UniqueTag)
+}
+
+func statusAlertKey(resourceType, resourceID, message string) string {
+	return fmt.Sprintf("%s/%s/%s", resourceType, resourceID, message)
+}
+
+func alertTime(alert *api.Alert) time.Time {
+	if alert.Timestamp == nil {
+		return time.Time{}
+	}
+	timestamp, err := ptypes.Timestamp(alert.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return timestamp
+}
+
+func mapAlertSeverity(severity api.SeverityType) string {
+	switch severity {
+	case api.SeverityType_SEVERITY_TYPE_ALARM:
+		return "Alarm"
+	case api.SeverityType_SEVERITY_TYPE_WARNING:
+		return "Warning"
+	case api.SeverityType_SEVERITY_TYPE_NOTIFY:
+		return "Notify"
+	}
+	return ""
+}
+
+func mapAlertResourceType(resourceType api.ResourceType) string {
+	switch resourceType {
+	case api.ResourceType_RESOURCE_TYPE_VOLUME:
+		return "Volume"
+	case api.ResourceType_RESOURCE_TYPE_NODE:
+		return "Node"
+	case api.ResourceType_RESOURCE_TYPE_CLUSTER:
+		return "Cluster"
+	case api.ResourceType_RESOURCE_TYPE_DRIVE:
+		return "Drive"
+	}
+	return ""
+}
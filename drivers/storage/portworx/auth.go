@@ -0,0 +1,102 @@
+package portworx
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libopenstorage/openstorage/pkg/auth"
+	pxutil "github.com/libopenstorage/operator/drivers/storage/portworx/util"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// operatorTokenLifetime is the lifetime of the token used by the operator
+	// to talk to the storage cluster. It is regenerated after half its lifetime.
+	operatorTokenLifetime = 24 * time.Hour
+	operatorTokenName     = "operator"
+	operatorTokenEmail    = "operator@portworx.io"
+)
+
+// tokenCredentials adds a token signed with the shared secret of the cluster
+// to the SDK requests, when authorization is enabled in the cluster. The token
+// is regenerated when the shared secret changes, like when it is rotated.
+type tokenCredentials struct {
+	k8sClient client.Client
+	cluster   *corev1alpha1.StorageCluster
+	token     string
+	expiry    time.Time
+	// checksum of the shared secret the token is signed with
+	checksum [sha256.Size]byte
+	lock     sync.Mutex
+}
+
+func newTokenCredentials(
+	k8sClient client.Client,
+	cluster *corev1alpha1.StorageCluster,
+) *tokenCredentials {
+	return &tokenCredentials{
+		k8sClient: k8sClient,
+		cluster:   cluster.DeepCopy(),
+	}
+}
+
+// GetRequestMetadata returns the authorization header for a request
+func (c *tokenCredentials) GetRequestMetadata(
+	ctx context.Context,
+	uri ...string,
+) (map[string]string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	sharedSecret, err := pxutil.GetSharedSecret(c.k8sClient, c.cluster)
+	if err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256([]byte(sharedSecret))
+
+	if c.token == "" || checksum != c.checksum ||
+		time.Now().After(c.expiry.Add(-operatorTokenLifetime/2)) {
+		token, err := pxutil.GenerateToken(
+			c.cluster,
+			sharedSecret,
+			&auth.Claims{
+				Subject: operatorTokenEmail,
+				Name:    operatorTokenName,
+				Email:   operatorTokenEmail,
+				Roles:   []string{"system.admin"},
+				Groups:  []string{"*"},
+			},
+			operatorTokenLifetime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate token: %v", err)
+		}
+		c.token = token
+		c.expiry = time.Now().Add(operatorTokenLifetime)
+		c.checksum = checksum
+	}
+
+	return map[string]string{
+		"authorization": "bearer " + c.token,
+	}, nil
+}
+
+// RequireTransportSecurity returns false as the SDK may not be using TLS
+func (c *tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// matches returns true if the credentials are the ones needed for the given
+// cluster. Nil credentials match a cluster without authorization.
+func (c *tokenCredentials) matches(cluster *corev1alpha1.StorageCluster) bool {
+	if c == nil {
+		return !pxutil.SecurityEnabled(cluster)
+	}
+	return pxutil.SecurityEnabled(cluster) &&
+		pxutil.SecurityIssuer(c.cluster) == pxutil.SecurityIssuer(cluster) &&
+		pxutil.SharedSecretName(c.cluster) == pxutil.SharedSecretName(cluster)
+}
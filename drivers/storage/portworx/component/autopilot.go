@@ -76,12 +76,15 @@ func (c *autopilot) Reconcile(cluster *corev1alpha1.StorageCluster) error {
 	if err := c.createServiceAccount(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
-	if err := c.createClusterRole(ownerRef); err != nil {
+	if err := c.createClusterRole(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
 	if err := c.createClusterRoleBinding(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, AutopilotClusterRoleName, AutopilotClusterRoleBindingName, *ownerRef); err != nil {
+		return err
+	}
 	if err := c.createDeployment(cluster, ownerRef); err != nil {
 		return err
 	}
@@ -96,10 +99,13 @@ func (c *autopilot) Delete(cluster *corev1alpha1.StorageCluster) error {
 	if err := k8sutil.DeleteServiceAccount(c.k8sClient, AutopilotServiceAccountName, cluster.Namespace, *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRole(c.k8sClient, AutopilotClusterRoleName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRole(c.k8sClient, util.ClusterScopedName(AutopilotClusterRoleName, cluster.Namespace), *ownerRef); err != nil {
+		return err
+	}
+	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, util.ClusterScopedName(AutopilotClusterRoleBindingName, cluster.Namespace), *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, AutopilotClusterRoleBindingName, *ownerRef); err != nil {
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, AutopilotClusterRoleName, AutopilotClusterRoleBindingName, *ownerRef); err != nil {
 		return err
 	}
 	if err := k8sutil.DeleteDeployment(c.k8sClient, AutopilotDeploymentName, cluster.Namespace, *ownerRef); err != nil {
@@ -176,12 +182,15 @@ func (c *autopilot) createServiceAccount(
 	)
 }
 
-func (c *autopilot) createClusterRole(ownerRef *metav1.OwnerReference) error {
+func (c *autopilot) createClusterRole(
+	clusterNamespace string,
+	ownerRef *metav1.OwnerReference,
+) error {
 	return k8sutil.CreateOrUpdateClusterRole(
 		c.k8sClient,
 		&rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            AutopilotClusterRoleName,
+				Name:            util.ClusterScopedName(AutopilotClusterRoleName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Rules: []rbacv1.PolicyRule{
@@ -204,7 +213,7 @@ func (c *autopilot) createClusterRoleBinding(
 		c.k8sClient,
 		&rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            AutopilotClusterRoleBindingName,
+				Name:            util.ClusterScopedName(AutopilotClusterRoleBindingName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Subjects: []rbacv1.Subject{
@@ -216,7 +225,7 @@ func (c *autopilot) createClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     AutopilotClusterRoleName,
+				Name:     util.ClusterScopedName(AutopilotClusterRoleName, clusterNamespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
@@ -295,30 +304,28 @@ func (c *autopilot) createDeployment(
 	var existingImage string
 	var existingCommand []string
 	var existingEnvs []v1.EnvVar
-	var existingCPUQuantity resource.Quantity
 	for _, c := range existingDeployment.Spec.Template.Spec.Containers {
 		if c.Name == AutopilotContainerName {
 			existingImage = c.Image
 			existingCommand = c.Command
 			existingEnvs = append([]v1.EnvVar{}, c.Env...)
 			sort.Sort(envByName(existingEnvs))
-			existingCPUQuantity = c.Resources.Requests[v1.ResourceCPU]
 			break
 		}
 	}
 
+	deployment := c.getAutopilotDeploymentSpec(cluster, ownerRef, imageName,
+		command, envVars, targetCPUQuantity)
+
 	// Check if the deployment has changed
 	modified := existingImage != imageName ||
 		!reflect.DeepEqual(existingCommand, command) ||
 		!reflect.DeepEqual(existingEnvs, envVars) ||
-		existingCPUQuantity.Cmp(targetCPUQuantity) != 0 ||
 		util.HasPullSecretChanged(cluster, existingDeployment.Spec.Template.Spec.ImagePullSecrets) ||
 		util.HasNodeAffinityChanged(cluster, existingDeployment.Spec.Template.Spec.Affinity) ||
-		util.HaveTolerationsChanged(cluster, existingDeployment.Spec.Template.Spec.Tolerations)
+		util.HasComponentConfigChanged(&existingDeployment.Spec.Template.Spec, &deployment.Spec.Template.Spec)
 
 	if !c.isCreated || modified {
-		deployment := c.getAutopilotDeploymentSpec(cluster, ownerRef, imageName,
-			command, envVars, targetCPUQuantity)
 		if err = k8sutil.CreateOrUpdateDeployment(c.k8sClient, deployment, ownerRef); err != nil {
 			return err
 		}
@@ -471,6 +478,8 @@ func (c *autopilot) getAutopilotDeploymentSpec(
 		deployment.Spec.Template.Spec.Containers[0].Env = envVars
 	}
 
+	util.ApplyComponentConfig(&deployment.Spec.Template.Spec,
+		&cluster.Spec.Autopilot.ComponentConfig, AutopilotContainerName)
 	return deployment
 }
 
@@ -80,6 +80,9 @@ func (c *csi) Reconcile(cluster *corev1alpha1.StorageCluster) error {
 	if err := c.createClusterRoleBinding(cluster, ownerRef); err != nil {
 		return err
 	}
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, CSIClusterRoleName, CSIClusterRoleBindingName, *ownerRef); err != nil {
+		return err
+	}
 	if err := c.createService(cluster, ownerRef); err != nil {
 		return err
 	}
@@ -116,10 +119,13 @@ func (c *csi) Delete(cluster *corev1alpha1.StorageCluster) error {
 	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
 	// We don't delete the service account for CSI because it is part of CSV. If
 	// we disable CSI then the CSV upgrades would fail as requirements are not met.
-	if err := k8sutil.DeleteClusterRole(c.k8sClient, CSIClusterRoleName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRole(c.k8sClient, util.ClusterScopedName(CSIClusterRoleName, cluster.Namespace), *ownerRef); err != nil {
+		return err
+	}
+	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, util.ClusterScopedName(CSIClusterRoleBindingName, cluster.Namespace), *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, CSIClusterRoleBindingName, *ownerRef); err != nil {
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, CSIClusterRoleName, CSIClusterRoleBindingName, *ownerRef); err != nil {
 		return err
 	}
 	if err := k8sutil.DeleteService(c.k8sClient, CSIServiceName, cluster.Namespace, *ownerRef); err != nil {
@@ -172,7 +178,7 @@ func (c *csi) createClusterRole(
 ) error {
 	clusterRole := &rbacv1.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            CSIClusterRoleName,
+			Name:            util.ClusterScopedName(CSIClusterRoleName, cluster.Namespace),
 			OwnerReferences: []metav1.OwnerReference{*ownerRef},
 		},
 		Rules: []rbacv1.PolicyRule{
@@ -307,7 +313,7 @@ func (c *csi) createClusterRoleBinding(
 		c.k8sClient,
 		&rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            CSIClusterRoleBindingName,
+				Name:            util.ClusterScopedName(CSIClusterRoleBindingName, cluster.Namespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Subjects: []rbacv1.Subject{
@@ -319,7 +325,7 @@ func (c *csi) createClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     CSIClusterRoleName,
+				Name:     util.ClusterScopedName(CSIClusterRoleName, cluster.Namespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
@@ -399,17 +405,18 @@ func (c *csi) createDeployment(
 		)
 	}
 
+	deployment := getCSIDeploymentSpec(cluster, csiConfig, ownerRef,
+		provisionerImage, attacherImage, snapshotterImage, resizerImage)
+
 	modified := provisionerImage != existingProvisionerImage ||
 		attacherImage != existingAttacherImage ||
 		snapshotterImage != existingSnapshotterImage ||
 		resizerImage != existingResizerImage ||
 		util.HasPullSecretChanged(cluster, existingDeployment.Spec.Template.Spec.ImagePullSecrets) ||
 		util.HasNodeAffinityChanged(cluster, existingDeployment.Spec.Template.Spec.Affinity) ||
-		util.HaveTolerationsChanged(cluster, existingDeployment.Spec.Template.Spec.Tolerations)
+		util.HasComponentConfigChanged(&existingDeployment.Spec.Template.Spec, &deployment.Spec.Template.Spec)
 
 	if !c.isCreated || modified {
-		deployment := getCSIDeploymentSpec(cluster, csiConfig, ownerRef,
-			provisionerImage, attacherImage, snapshotterImage, resizerImage)
 		if err = k8sutil.CreateOrUpdateDeployment(c.k8sClient, deployment, ownerRef); err != nil {
 			return err
 		}
@@ -628,6 +635,10 @@ func getCSIDeploymentSpec(
 		}
 	}
 
+	if cluster.Spec.CSI != nil {
+		util.ApplyComponentConfig(&deployment.Spec.Template.Spec,
+			&cluster.Spec.CSI.ComponentConfig, csiProvisionerContainerName)
+	}
 	return deployment
 }
 
@@ -665,14 +676,15 @@ func (c *csi) createStatefulSet(
 		csiConfig.Attacher,
 	)
 
+	statefulSet := getCSIStatefulSetSpec(cluster, csiConfig, ownerRef, provisionerImage, attacherImage)
+
 	modified := provisionerImage != existingProvisionerImage ||
 		attacherImage != existingAttacherImage ||
 		util.HasPullSecretChanged(cluster, existingSS.Spec.Template.Spec.ImagePullSecrets) ||
 		util.HasNodeAffinityChanged(cluster, existingSS.Spec.Template.Spec.Affinity) ||
-		util.HaveTolerationsChanged(cluster, existingSS.Spec.Template.Spec.Tolerations)
+		util.HasComponentConfigChanged(&existingSS.Spec.Template.Spec, &statefulSet.Spec.Template.Spec)
 
 	if !c.isCreated || modified {
-		statefulSet := getCSIStatefulSetSpec(cluster, csiConfig, ownerRef, provisionerImage, attacherImage)
 		if err = k8sutil.CreateOrUpdateStatefulSet(c.k8sClient, statefulSet, ownerRef); err != nil {
 			return err
 		}
@@ -805,6 +817,10 @@ func getCSIStatefulSetSpec(
 		}
 	}
 
+	if cluster.Spec.CSI != nil {
+		util.ApplyComponentConfig(&statefulSet.Spec.Template.Spec,
+			&cluster.Spec.CSI.ComponentConfig, csiProvisionerContainerName)
+	}
 	return statefulSet
 }
 
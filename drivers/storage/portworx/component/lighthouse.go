@@ -78,12 +78,15 @@ func (c *lighthouse) Reconcile(cluster *corev1alpha1.StorageCluster) error {
 	if err := c.createServiceAccount(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
-	if err := c.createClusterRole(ownerRef); err != nil {
+	if err := c.createClusterRole(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
 	if err := c.createClusterRoleBinding(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, LhClusterRoleName, LhClusterRoleBindingName, *ownerRef); err != nil {
+		return err
+	}
 	if err := c.createService(cluster, ownerRef); err != nil {
 		return err
 	}
@@ -98,10 +101,13 @@ func (c *lighthouse) Delete(cluster *corev1alpha1.StorageCluster) error {
 	if err := k8sutil.DeleteServiceAccount(c.k8sClient, LhServiceAccountName, cluster.Namespace, *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRole(c.k8sClient, LhClusterRoleName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRole(c.k8sClient, util.ClusterScopedName(LhClusterRoleName, cluster.Namespace), *ownerRef); err != nil {
+		return err
+	}
+	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, util.ClusterScopedName(LhClusterRoleBindingName, cluster.Namespace), *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, LhClusterRoleBindingName, *ownerRef); err != nil {
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, LhClusterRoleName, LhClusterRoleBindingName, *ownerRef); err != nil {
 		return err
 	}
 	if err := k8sutil.DeleteService(c.k8sClient, LhServiceName, cluster.Namespace, *ownerRef); err != nil {
@@ -135,12 +141,15 @@ func (c *lighthouse) createServiceAccount(
 	)
 }
 
-func (c *lighthouse) createClusterRole(ownerRef *metav1.OwnerReference) error {
+func (c *lighthouse) createClusterRole(
+	clusterNamespace string,
+	ownerRef *metav1.OwnerReference,
+) error {
 	return k8sutil.CreateOrUpdateClusterRole(
 		c.k8sClient,
 		&rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            LhClusterRoleName,
+				Name:            util.ClusterScopedName(LhClusterRoleName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Rules: []rbacv1.PolicyRule{
@@ -210,7 +219,7 @@ func (c *lighthouse) createClusterRoleBinding(
 		c.k8sClient,
 		&rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            LhClusterRoleBindingName,
+				Name:            util.ClusterScopedName(LhClusterRoleBindingName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Subjects: []rbacv1.Subject{
@@ -222,7 +231,7 @@ func (c *lighthouse) createClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     LhClusterRoleName,
+				Name:     util.ClusterScopedName(LhClusterRoleName, clusterNamespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
@@ -317,16 +326,17 @@ func (c *lighthouse) createDeployment(
 	configSyncImage = util.GetImageURN(imageRegistry, configSyncImage)
 	storkConnectorImage = util.GetImageURN(imageRegistry, storkConnectorImage)
 
+	deployment := getLighthouseDeploymentSpec(cluster, ownerRef, lhImage, configSyncImage, storkConnectorImage)
+
 	modified := lhImage != existingLhImage ||
 		configSyncImage != existingConfigInitImage ||
 		configSyncImage != existingConfigSyncImage ||
 		storkConnectorImage != existingStorkConnectorImage ||
 		util.HasPullSecretChanged(cluster, existingDeployment.Spec.Template.Spec.ImagePullSecrets) ||
 		util.HasNodeAffinityChanged(cluster, existingDeployment.Spec.Template.Spec.Affinity) ||
-		util.HaveTolerationsChanged(cluster, existingDeployment.Spec.Template.Spec.Tolerations)
+		util.HasComponentConfigChanged(&existingDeployment.Spec.Template.Spec, &deployment.Spec.Template.Spec)
 
 	if !c.isCreated || modified {
-		deployment := getLighthouseDeploymentSpec(cluster, ownerRef, lhImage, configSyncImage, storkConnectorImage)
 		if err = k8sutil.CreateOrUpdateDeployment(c.k8sClient, deployment, ownerRef); err != nil {
 			return err
 		}
@@ -478,6 +488,8 @@ func getLighthouseDeploymentSpec(
 		}
 	}
 
+	util.ApplyComponentConfig(&deployment.Spec.Template.Spec,
+		&cluster.Spec.UserInterface.ComponentConfig, LhContainerName)
 	return deployment
 }
 
@@ -26,6 +26,9 @@ const (
 	PxAPIServiceName = "portworx-api"
 	// PxAPIDaemonSetName name of the Portworx API daemon set
 	PxAPIDaemonSetName = "portworx-api"
+
+	pxAPITLSVolumeName = "pxtlscerts"
+	pxAPITLSMountPath  = "/etc/pwx/tls"
 )
 
 type portworxAPI struct {
@@ -155,7 +158,8 @@ func (c *portworxAPI) createDaemonSet(
 	modified := existingImageName != imageName ||
 		util.HasPullSecretChanged(cluster, existingDaemonSet.Spec.Template.Spec.ImagePullSecrets) ||
 		util.HasNodeAffinityChanged(cluster, existingDaemonSet.Spec.Template.Spec.Affinity) ||
-		util.HaveTolerationsChanged(cluster, existingDaemonSet.Spec.Template.Spec.Tolerations)
+		util.HaveTolerationsChanged(cluster, existingDaemonSet.Spec.Template.Spec.Tolerations) ||
+		getTLSSecretName(&existingDaemonSet.Spec.Template.Spec) != portworxAPITLSSecretName(cluster)
 
 	if !c.isCreated || errors.IsNotFound(getErr) || modified {
 		daemonSet := getPortworxAPIDaemonSetSpec(cluster, ownerRef, imageName)
@@ -250,9 +254,70 @@ func getPortworxAPIDaemonSetSpec(
 		}
 	}
 
+	if secretName := portworxAPITLSSecretName(cluster); secretName != "" {
+		addTLSVolume(newDaemonSet, cluster, secretName)
+	}
+
 	return newDaemonSet
 }
 
+// addTLSVolume mounts the CA certificate of the storage endpoints in the
+// daemon set and probes the REST endpoint over TLS. The generated CA may not
+// exist until the first storage pod is created, so the volume is optional.
+func addTLSVolume(
+	daemonSet *appsv1.DaemonSet,
+	cluster *corev1alpha1.StorageCluster,
+	secretName string,
+) {
+	caKey := pxutil.TLSCertKey
+	if pxutil.TLSServerCertSecretName(cluster) != "" {
+		caKey = pxutil.TLSCACertKey
+	}
+	podSpec := &daemonSet.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+		Name: pxAPITLSVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: secretName,
+				Items: []v1.KeyToPath{
+					{
+						Key:  caKey,
+						Path: pxutil.TLSCACertKey,
+					},
+				},
+				Optional: boolPtr(true),
+			},
+		},
+	})
+	container := &podSpec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+		Name:      pxAPITLSVolumeName,
+		MountPath: pxAPITLSMountPath,
+		ReadOnly:  true,
+	})
+	container.ReadinessProbe.HTTPGet.Scheme = v1.URISchemeHTTPS
+}
+
+// portworxAPITLSSecretName returns the secret with the CA certificate of the
+// storage endpoints, or an empty string if TLS is disabled
+func portworxAPITLSSecretName(cluster *corev1alpha1.StorageCluster) string {
+	if !pxutil.TLSEnabled(cluster) {
+		return ""
+	} else if secretName := pxutil.TLSServerCertSecretName(cluster); secretName != "" {
+		return secretName
+	}
+	return pxutil.TLSCASecretNameForCluster(cluster)
+}
+
+func getTLSSecretName(podSpec *v1.PodSpec) string {
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == pxAPITLSVolumeName && volume.Secret != nil {
+			return volume.Secret.SecretName
+		}
+	}
+	return ""
+}
+
 func getPortworxAPIServiceLabels() map[string]string {
 	return map[string]string{
 		"name": PxAPIServiceName,
@@ -6,6 +6,7 @@ import (
 	"github.com/hashicorp/go-version"
 	pxutil "github.com/libopenstorage/operator/drivers/storage/portworx/util"
 	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/libopenstorage/operator/pkg/util"
 	k8sutil "github.com/libopenstorage/operator/pkg/util/k8s"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
@@ -54,12 +55,15 @@ func (c *portworxBasic) Reconcile(cluster *corev1alpha1.StorageCluster) error {
 	if err := c.createServiceAccount(cluster.Namespace, ownerRef); err != nil {
 		return NewError(ErrCritical, err)
 	}
-	if err := c.createClusterRole(ownerRef); err != nil {
+	if err := c.createClusterRole(cluster.Namespace, ownerRef); err != nil {
 		return NewError(ErrCritical, err)
 	}
 	if err := c.createClusterRoleBinding(cluster.Namespace, ownerRef); err != nil {
 		return NewError(ErrCritical, err)
 	}
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, PxClusterRoleName, PxClusterRoleBindingName, *ownerRef); err != nil {
+		return NewError(ErrCritical, err)
+	}
 	if err := c.prepareForSecrets(cluster, ownerRef); err != nil {
 		return NewError(ErrCritical, err)
 	}
@@ -74,10 +78,13 @@ func (c *portworxBasic) Delete(cluster *corev1alpha1.StorageCluster) error {
 	if err := k8sutil.DeleteServiceAccount(c.k8sClient, pxutil.PortworxServiceAccountName, cluster.Namespace, *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRole(c.k8sClient, PxClusterRoleName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRole(c.k8sClient, util.ClusterScopedName(PxClusterRoleName, cluster.Namespace), *ownerRef); err != nil {
+		return err
+	}
+	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, util.ClusterScopedName(PxClusterRoleBindingName, cluster.Namespace), *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, PxClusterRoleBindingName, *ownerRef); err != nil {
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, PxClusterRoleName, PxClusterRoleBindingName, *ownerRef); err != nil {
 		return err
 	}
 	if err := k8sutil.DeleteRole(c.k8sClient, PxRoleName, cluster.Namespace, *ownerRef); err != nil {
@@ -211,12 +218,15 @@ func (c *portworxBasic) createRoleBinding(
 	)
 }
 
-func (c *portworxBasic) createClusterRole(ownerRef *metav1.OwnerReference) error {
+func (c *portworxBasic) createClusterRole(
+	clusterNamespace string,
+	ownerRef *metav1.OwnerReference,
+) error {
 	return k8sutil.CreateOrUpdateClusterRole(
 		c.k8sClient,
 		&rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            PxClusterRoleName,
+				Name:            util.ClusterScopedName(PxClusterRoleName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Rules: []rbacv1.PolicyRule{
@@ -296,7 +306,7 @@ func (c *portworxBasic) createClusterRoleBinding(
 		c.k8sClient,
 		&rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            PxClusterRoleBindingName,
+				Name:            util.ClusterScopedName(PxClusterRoleBindingName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Subjects: []rbacv1.Subject{
@@ -308,7 +318,7 @@ func (c *portworxBasic) createClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     PxClusterRoleName,
+				Name:     util.ClusterScopedName(PxClusterRoleName, clusterNamespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
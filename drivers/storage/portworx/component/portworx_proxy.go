@@ -61,7 +61,7 @@ func (c *portworxProxy) Reconcile(cluster *corev1alpha1.StorageCluster) error {
 	if err := c.createServiceAccount(ownerRef); err != nil {
 		return NewError(ErrCritical, err)
 	}
-	if err := c.createClusterRoleBinding(ownerRef); err != nil {
+	if err := c.createClusterRoleBinding(cluster.Namespace, ownerRef); err != nil {
 		return NewError(ErrCritical, err)
 	}
 	if err := c.createPortworxService(cluster, ownerRef); err != nil {
@@ -116,6 +116,7 @@ func (c *portworxProxy) createServiceAccount(
 }
 
 func (c *portworxProxy) createClusterRoleBinding(
+	clusterNamespace string,
 	ownerRef *metav1.OwnerReference,
 ) error {
 	return k8sutil.CreateOrUpdateClusterRoleBinding(
@@ -134,7 +135,7 @@ func (c *portworxProxy) createClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     PxClusterRoleName,
+				Name:     util.ClusterScopedName(PxClusterRoleName, clusterNamespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
@@ -88,24 +88,30 @@ func (c *prometheus) Reconcile(cluster *corev1alpha1.StorageCluster) error {
 	if err := c.createOperatorServiceAccount(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
-	if err := c.createOperatorClusterRole(ownerRef); err != nil {
+	if err := c.createOperatorClusterRole(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
 	if err := c.createOperatorClusterRoleBinding(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, PrometheusOperatorClusterRoleName, PrometheusOperatorClusterRoleBindingName, *ownerRef); err != nil {
+		return err
+	}
 	if err := c.createOperatorDeployment(cluster, ownerRef); err != nil {
 		return err
 	}
 	if err := c.createPrometheusServiceAccount(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
-	if err := c.createPrometheusClusterRole(ownerRef); err != nil {
+	if err := c.createPrometheusClusterRole(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
 	if err := c.createPrometheusClusterRoleBinding(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, PrometheusClusterRoleName, PrometheusClusterRoleBindingName, *ownerRef); err != nil {
+		return err
+	}
 	if err := c.createPrometheusService(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
@@ -143,10 +149,13 @@ func (c *prometheus) Delete(cluster *corev1alpha1.StorageCluster) error {
 	if err := k8sutil.DeleteServiceAccount(c.k8sClient, PrometheusServiceAccountName, cluster.Namespace, *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRole(c.k8sClient, PrometheusClusterRoleName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRole(c.k8sClient, util.ClusterScopedName(PrometheusClusterRoleName, cluster.Namespace), *ownerRef); err != nil {
+		return err
+	}
+	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, util.ClusterScopedName(PrometheusClusterRoleBindingName, cluster.Namespace), *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, PrometheusClusterRoleBindingName, *ownerRef); err != nil {
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, PrometheusClusterRoleName, PrometheusClusterRoleBindingName, *ownerRef); err != nil {
 		return err
 	}
 	if err := k8sutil.DeleteService(c.k8sClient, PrometheusServiceName, cluster.Namespace, *ownerRef); err != nil {
@@ -155,10 +164,13 @@ func (c *prometheus) Delete(cluster *corev1alpha1.StorageCluster) error {
 	if err := k8sutil.DeleteServiceAccount(c.k8sClient, PrometheusOperatorServiceAccountName, cluster.Namespace, *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRole(c.k8sClient, PrometheusOperatorClusterRoleName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRole(c.k8sClient, util.ClusterScopedName(PrometheusOperatorClusterRoleName, cluster.Namespace), *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, PrometheusOperatorClusterRoleBindingName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, util.ClusterScopedName(PrometheusOperatorClusterRoleBindingName, cluster.Namespace), *ownerRef); err != nil {
+		return err
+	}
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, PrometheusOperatorClusterRoleName, PrometheusOperatorClusterRoleBindingName, *ownerRef); err != nil {
 		return err
 	}
 	if err := k8sutil.DeleteDeployment(c.k8sClient, PrometheusOperatorDeploymentName, cluster.Namespace, *ownerRef); err != nil {
@@ -205,12 +217,15 @@ func (c *prometheus) createPrometheusServiceAccount(
 	)
 }
 
-func (c *prometheus) createOperatorClusterRole(ownerRef *metav1.OwnerReference) error {
+func (c *prometheus) createOperatorClusterRole(
+	clusterNamespace string,
+	ownerRef *metav1.OwnerReference,
+) error {
 	return k8sutil.CreateOrUpdateClusterRole(
 		c.k8sClient,
 		&rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            PrometheusOperatorClusterRoleName,
+				Name:            util.ClusterScopedName(PrometheusOperatorClusterRoleName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Rules: []rbacv1.PolicyRule{
@@ -272,12 +287,15 @@ func (c *prometheus) createOperatorClusterRole(ownerRef *metav1.OwnerReference)
 	)
 }
 
-func (c *prometheus) createPrometheusClusterRole(ownerRef *metav1.OwnerReference) error {
+func (c *prometheus) createPrometheusClusterRole(
+	clusterNamespace string,
+	ownerRef *metav1.OwnerReference,
+) error {
 	return k8sutil.CreateOrUpdateClusterRole(
 		c.k8sClient,
 		&rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            PrometheusClusterRoleName,
+				Name:            util.ClusterScopedName(PrometheusClusterRoleName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Rules: []rbacv1.PolicyRule{
@@ -309,7 +327,7 @@ func (c *prometheus) createOperatorClusterRoleBinding(
 		c.k8sClient,
 		&rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            PrometheusOperatorClusterRoleBindingName,
+				Name:            util.ClusterScopedName(PrometheusOperatorClusterRoleBindingName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Subjects: []rbacv1.Subject{
@@ -321,7 +339,7 @@ func (c *prometheus) createOperatorClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     PrometheusOperatorClusterRoleName,
+				Name:     util.ClusterScopedName(PrometheusOperatorClusterRoleName, clusterNamespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
@@ -337,7 +355,7 @@ func (c *prometheus) createPrometheusClusterRoleBinding(
 		c.k8sClient,
 		&rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            PrometheusClusterRoleBindingName,
+				Name:            util.ClusterScopedName(PrometheusClusterRoleBindingName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Subjects: []rbacv1.Subject{
@@ -349,7 +367,7 @@ func (c *prometheus) createPrometheusClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     PrometheusClusterRoleName,
+				Name:     util.ClusterScopedName(PrometheusClusterRoleName, clusterNamespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
@@ -602,6 +620,32 @@ func (c *prometheus) createPrometheusInstance(
 		}
 	}
 
+	config := cluster.Spec.Monitoring.Prometheus.ComponentConfig
+	if config.Resources != nil {
+		prometheusInst.Spec.Resources = *config.Resources.DeepCopy()
+	}
+	if config.PriorityClassName != "" {
+		prometheusInst.Spec.PriorityClassName = config.PriorityClassName
+	}
+	if len(config.NodeSelector) > 0 {
+		prometheusInst.Spec.NodeSelector = make(map[string]string)
+		for k, v := range config.NodeSelector {
+			prometheusInst.Spec.NodeSelector[k] = v
+		}
+	}
+	if len(config.Tolerations) > 0 {
+		prometheusInst.Spec.Tolerations = make([]v1.Toleration, 0)
+		for _, toleration := range config.Tolerations {
+			prometheusInst.Spec.Tolerations = append(
+				prometheusInst.Spec.Tolerations,
+				*(toleration.DeepCopy()),
+			)
+		}
+	}
+	if config.SecurityContext != nil {
+		prometheusInst.Spec.SecurityContext = config.SecurityContext.DeepCopy()
+	}
+
 	return k8sutil.CreateOrUpdatePrometheus(c.k8sClient, prometheusInst, ownerRef)
 }
 
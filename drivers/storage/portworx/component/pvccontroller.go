@@ -81,12 +81,15 @@ func (c *pvcController) Reconcile(cluster *corev1alpha1.StorageCluster) error {
 	if err := c.createServiceAccount(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
-	if err := c.createClusterRole(ownerRef); err != nil {
+	if err := c.createClusterRole(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
 	if err := c.createClusterRoleBinding(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, PVCClusterRoleName, PVCClusterRoleBindingName, *ownerRef); err != nil {
+		return err
+	}
 	if err := c.createDeployment(cluster, ownerRef); err != nil {
 		return err
 	}
@@ -98,10 +101,13 @@ func (c *pvcController) Delete(cluster *corev1alpha1.StorageCluster) error {
 	if err := k8sutil.DeleteServiceAccount(c.k8sClient, PVCServiceAccountName, cluster.Namespace, *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRole(c.k8sClient, PVCClusterRoleName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRole(c.k8sClient, util.ClusterScopedName(PVCClusterRoleName, cluster.Namespace), *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, PVCClusterRoleBindingName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRoleBinding(c.k8sClient, util.ClusterScopedName(PVCClusterRoleBindingName, cluster.Namespace), *ownerRef); err != nil {
+		return err
+	}
+	if err := util.DeleteLegacyClusterRBAC(c.k8sClient, PVCClusterRoleName, PVCClusterRoleBindingName, *ownerRef); err != nil {
 		return err
 	}
 	if err := k8sutil.DeleteDeployment(c.k8sClient, PVCDeploymentName, cluster.Namespace, *ownerRef); err != nil {
@@ -132,12 +138,15 @@ func (c *pvcController) createServiceAccount(
 	)
 }
 
-func (c *pvcController) createClusterRole(ownerRef *metav1.OwnerReference) error {
+func (c *pvcController) createClusterRole(
+	clusterNamespace string,
+	ownerRef *metav1.OwnerReference,
+) error {
 	return k8sutil.CreateOrUpdateClusterRole(
 		c.k8sClient,
 		&rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            PVCClusterRoleName,
+				Name:            util.ClusterScopedName(PVCClusterRoleName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Rules: []rbacv1.PolicyRule{
@@ -226,7 +235,7 @@ func (c *pvcController) createClusterRoleBinding(
 		c.k8sClient,
 		&rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            PVCClusterRoleBindingName,
+				Name:            util.ClusterScopedName(PVCClusterRoleBindingName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Subjects: []rbacv1.Subject{
@@ -238,7 +247,7 @@ func (c *pvcController) createClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     PVCClusterRoleName,
+				Name:     util.ClusterScopedName(PVCClusterRoleName, clusterNamespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
@@ -290,24 +299,22 @@ func (c *pvcController) createDeployment(
 
 	var existingImage string
 	var existingCommand []string
-	var existingCPUQuantity resource.Quantity
 	for _, container := range existingDeployment.Spec.Template.Spec.Containers {
 		if container.Name == pvcContainerName {
 			existingImage = container.Image
 			existingCommand = container.Command
-			existingCPUQuantity = container.Resources.Requests[v1.ResourceCPU]
 		}
 	}
 
+	deployment := getPVCControllerDeploymentSpec(cluster, ownerRef, imageName, command, targetCPUQuantity)
+
 	modified := existingImage != imageName ||
 		!reflect.DeepEqual(existingCommand, command) ||
-		existingCPUQuantity.Cmp(targetCPUQuantity) != 0 ||
 		util.HasPullSecretChanged(cluster, existingDeployment.Spec.Template.Spec.ImagePullSecrets) ||
 		util.HasNodeAffinityChanged(cluster, existingDeployment.Spec.Template.Spec.Affinity) ||
-		util.HaveTolerationsChanged(cluster, existingDeployment.Spec.Template.Spec.Tolerations)
+		util.HasComponentConfigChanged(&existingDeployment.Spec.Template.Spec, &deployment.Spec.Template.Spec)
 
 	if !c.isCreated || modified {
-		deployment := getPVCControllerDeploymentSpec(cluster, ownerRef, imageName, command, targetCPUQuantity)
 		if err = k8sutil.CreateOrUpdateDeployment(c.k8sClient, deployment, ownerRef); err != nil {
 			return err
 		}
@@ -443,6 +450,10 @@ func getPVCControllerDeploymentSpec(
 		}
 	}
 
+	if cluster.Spec.PVCController != nil {
+		util.ApplyComponentConfig(&deployment.Spec.Template.Spec,
+			&cluster.Spec.PVCController.ComponentConfig, pvcContainerName)
+	}
 	return deployment
 }
 
@@ -0,0 +1,278 @@
+package component
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/hashicorp/go-version"
+	"github.com/libopenstorage/openstorage/pkg/auth"
+	pxutil "github.com/libopenstorage/operator/drivers/storage/portworx/util"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/libopenstorage/operator/pkg/controller/storagecluster"
+	k8sutil "github.com/libopenstorage/operator/pkg/util/k8s"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SecurityComponentName name of the Security component. It generates the
+	// secrets and tokens needed when authorization is enabled in the cluster.
+	SecurityComponentName = "Security"
+
+	adminTokenName  = "admin"
+	adminTokenEmail = "admin@portworx.io"
+	// secretLength is the number of random bytes in the generated secrets
+	secretLength = 48
+)
+
+type security struct {
+	k8sClient client.Client
+}
+
+func (c *security) Initialize(
+	k8sClient client.Client,
+	_ version.Version,
+	_ *runtime.Scheme,
+	_ record.EventRecorder,
+) {
+	c.k8sClient = k8sClient
+}
+
+func (c *security) IsEnabled(cluster *corev1alpha1.StorageCluster) bool {
+	return pxutil.SecurityEnabled(cluster) && pxutil.IsPortworxEnabled(cluster)
+}
+
+func (c *security) Reconcile(cluster *corev1alpha1.StorageCluster) error {
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	// The storage pods cannot start without the secrets, so failing to
+	// create them is critical
+	err := c.createOrRotateSecret(cluster, pxutil.SecuritySystemSecretName,
+		pxutil.SecuritySystemSecretKey, ownerRef)
+	if err != nil {
+		return NewError(ErrCritical, err)
+	}
+	if pxutil.SharedSecretName(cluster) == pxutil.SecuritySharedSecretName {
+		err := c.createOrRotateSecret(cluster, pxutil.SecuritySharedSecretName,
+			pxutil.SecuritySharedSecretKey, ownerRef)
+		if err != nil {
+			return NewError(ErrCritical, err)
+		}
+	}
+
+	sharedSecret, err := pxutil.GetSharedSecret(c.k8sClient, cluster)
+	if err != nil {
+		return NewError(ErrCritical, err)
+	}
+	return c.updateAdminToken(cluster, sharedSecret, ownerRef)
+}
+
+func (c *security) Delete(cluster *corev1alpha1.StorageCluster) error {
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	if err := k8sutil.DeleteSecret(c.k8sClient, pxutil.SecurityAdminTokenSecretName, cluster.Namespace, *ownerRef); err != nil {
+		return err
+	}
+	if err := k8sutil.DeleteSecret(c.k8sClient, pxutil.SecuritySharedSecretName, cluster.Namespace, *ownerRef); err != nil {
+		return err
+	}
+	return k8sutil.DeleteSecret(c.k8sClient, pxutil.SecuritySystemSecretName, cluster.Namespace, *ownerRef)
+}
+
+func (c *security) MarkDeleted() {}
+
+// createOrRotateSecret creates a secret with a random value under the given key.
+// The value is regenerated once the rotation period of the cluster has passed
+// since it was generated, which invalidates the tokens signed with it. The secret
+// is labeled as a storage pod secret, so the storage pods are restarted with the
+// new value.
+func (c *security) createOrRotateSecret(
+	cluster *corev1alpha1.StorageCluster,
+	name, key string,
+	ownerRef *metav1.OwnerReference,
+) error {
+	secret := &v1.Secret{}
+	err := c.k8sClient.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      name,
+			Namespace: cluster.Namespace,
+		},
+		secret,
+	)
+	exists := err == nil
+	if errors.IsNotFound(err) {
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       cluster.Namespace,
+				OwnerReferences: []metav1.OwnerReference{*ownerRef},
+			},
+			Type: v1.SecretTypeOpaque,
+		}
+	} else if err != nil {
+		return err
+	}
+
+	rotate := secretNeedsRotation(secret, key, pxutil.SecretRotationPeriod(cluster))
+	if !rotate && secret.Labels[storagecluster.LabelStoragePodSecret] == "true" {
+		return nil
+	}
+
+	if secret.Labels == nil {
+		secret.Labels = make(map[string]string)
+	}
+	// Secrets generated by older versions are labeled without changing their value
+	secret.Labels[storagecluster.LabelStoragePodSecret] = "true"
+	if rotate {
+		value, err := generateSecret()
+		if err != nil {
+			return fmt.Errorf("failed to generate secret %s: %v", name, err)
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = make(map[string]string)
+		}
+		secret.Annotations[pxutil.AnnotationSecretGeneratedAt] = time.Now().UTC().Format(time.RFC3339)
+		secret.Data = map[string][]byte{
+			key: []byte(value),
+		}
+	}
+
+	if !exists {
+		logrus.Debugf("Creating %v secret", name)
+		return c.k8sClient.Create(context.TODO(), secret)
+	} else if rotate {
+		logrus.Infof("Rotating %v secret", name)
+	}
+	return c.k8sClient.Update(context.TODO(), secret)
+}
+
+// secretNeedsRotation returns true if the secret does not have a value under
+// the given key, or if the value was generated longer than the rotation period
+// ago. Secrets without the generation time are rotated from their creation time.
+func secretNeedsRotation(secret *v1.Secret, key string, rotationPeriod time.Duration) bool {
+	if len(secret.Data[key]) == 0 {
+		return true
+	} else if rotationPeriod == 0 {
+		return false
+	}
+
+	generatedAt := secret.CreationTimestamp.Time
+	if value, exists := secret.Annotations[pxutil.AnnotationSecretGeneratedAt]; exists {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			generatedAt = t
+		}
+	}
+	return time.Now().After(generatedAt.Add(rotationPeriod))
+}
+
+// updateAdminToken generates a token for the admin of the cluster and saves it
+// in a secret. The token is regenerated once half of its lifetime has passed,
+// or if it is no longer valid with the current shared secret and issuer.
+func (c *security) updateAdminToken(
+	cluster *corev1alpha1.StorageCluster,
+	sharedSecret string,
+	ownerRef *metav1.OwnerReference,
+) error {
+	lifetime := pxutil.TokenLifetime(cluster)
+	secret := &v1.Secret{}
+	err := c.k8sClient.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      pxutil.SecurityAdminTokenSecretName,
+			Namespace: cluster.Namespace,
+		},
+		secret,
+	)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err == nil && !tokenNeedsRotation(cluster, string(secret.Data[pxutil.SecurityAuthTokenKey]),
+		sharedSecret, lifetime) {
+		return nil
+	}
+
+	token, err := pxutil.GenerateToken(
+		cluster,
+		sharedSecret,
+		&auth.Claims{
+			Subject: adminTokenEmail,
+			Name:    adminTokenName,
+			Email:   adminTokenEmail,
+			Roles:   []string{"system.admin"},
+			Groups:  []string{"*"},
+		},
+		lifetime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to generate admin token: %v", err)
+	}
+	return k8sutil.CreateOrUpdateSecret(
+		c.k8sClient,
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            pxutil.SecurityAdminTokenSecretName,
+				Namespace:       cluster.Namespace,
+				OwnerReferences: []metav1.OwnerReference{*ownerRef},
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				pxutil.SecurityAuthTokenKey: []byte(token),
+			},
+		},
+		ownerRef,
+	)
+}
+
+// tokenNeedsRotation returns true if the token is not signed with the given
+// shared secret by the issuer of the cluster, if half of its lifetime has
+// passed, or if it outlives the given lifetime
+func tokenNeedsRotation(
+	cluster *corev1alpha1.StorageCluster,
+	token, sharedSecret string,
+	lifetime time.Duration,
+) bool {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return []byte(sharedSecret), nil
+	})
+	if err != nil || !claims.VerifyIssuer(pxutil.SecurityIssuer(cluster), true) {
+		return true
+	}
+
+	issuedAt, iatOk := claims["iat"].(float64)
+	expiresAt, expOk := claims["exp"].(float64)
+	if !iatOk || !expOk {
+		return true
+	}
+	now := time.Now()
+	rotateAt := time.Unix(int64(issuedAt+(expiresAt-issuedAt)/2), 0)
+	return now.After(rotateAt) || time.Unix(int64(expiresAt), 0).After(now.Add(lifetime))
+}
+
+func generateSecret() (string, error) {
+	value := make([]byte, secretLength)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(value), nil
+}
+
+// RegisterSecurityComponent registers the Security component
+func RegisterSecurityComponent() {
+	Register(SecurityComponentName, &security{})
+}
+
+func init() {
+	RegisterSecurityComponent()
+}
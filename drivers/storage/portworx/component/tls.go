@@ -0,0 +1,135 @@
+package component
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-version"
+	pxutil "github.com/libopenstorage/operator/drivers/storage/portworx/util"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	k8sutil "github.com/libopenstorage/operator/pkg/util/k8s"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TLSComponentName name of the TLS component. It renews the certificates
+	// issued by the operator for the storage nodes before they expire.
+	TLSComponentName = "TLS"
+)
+
+type tls struct {
+	k8sClient client.Client
+}
+
+func (c *tls) Initialize(
+	k8sClient client.Client,
+	_ version.Version,
+	_ *runtime.Scheme,
+	_ record.EventRecorder,
+) {
+	c.k8sClient = k8sClient
+}
+
+func (c *tls) IsEnabled(cluster *corev1alpha1.StorageCluster) bool {
+	return pxutil.TLSEnabled(cluster) && pxutil.IsPortworxEnabled(cluster)
+}
+
+// Reconcile renews the node certificates that are about to expire and removes
+// the ones of nodes that no longer exist. The certificates of new nodes are
+// issued when their storage pods are created.
+func (c *tls) Reconcile(cluster *corev1alpha1.StorageCluster) error {
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	if pxutil.TLSServerCertSecretName(cluster) != "" {
+		// All the nodes use the certificate given by the user
+		return c.Delete(cluster)
+	}
+	if pxutil.TLSCASecretNameForCluster(cluster) != pxutil.TLSCASecretName {
+		err := k8sutil.DeleteSecret(c.k8sClient, pxutil.TLSCASecretName, cluster.Namespace, *ownerRef)
+		if err != nil {
+			return err
+		}
+	}
+
+	nodeSecrets, err := c.getNodeSecrets(cluster)
+	if err != nil {
+		return err
+	}
+	for _, secret := range nodeSecrets {
+		nodeName := secret.Labels[pxutil.TLSNodeLabelKey]
+		if secret.Name != pxutil.TLSNodeSecretName(nodeName) {
+			continue
+		}
+		err := c.k8sClient.Get(context.TODO(), types.NamespacedName{Name: nodeName}, &v1.Node{})
+		if errors.IsNotFound(err) {
+			logrus.Debugf("Removing TLS certificate of deleted node %s", nodeName)
+			err = k8sutil.DeleteSecret(c.k8sClient, secret.Name, cluster.Namespace, *ownerRef)
+		} else if err == nil {
+			err = pxutil.EnsureTLSNodeCertificate(c.k8sClient, cluster, nodeName, ownerRef)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *tls) Delete(cluster *corev1alpha1.StorageCluster) error {
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	nodeSecrets, err := c.getNodeSecrets(cluster)
+	if err != nil {
+		return err
+	}
+	for _, secret := range nodeSecrets {
+		if secret.Name != pxutil.TLSNodeSecretName(secret.Labels[pxutil.TLSNodeLabelKey]) {
+			continue
+		}
+		if err := k8sutil.DeleteSecret(c.k8sClient, secret.Name, cluster.Namespace, *ownerRef); err != nil {
+			return err
+		}
+	}
+	return k8sutil.DeleteSecret(c.k8sClient, pxutil.TLSCASecretName, cluster.Namespace, *ownerRef)
+}
+
+func (c *tls) MarkDeleted() {}
+
+// getNodeSecrets returns the secrets with the certificates issued for the nodes.
+// They are found by the node label, so that no other secret is ever removed.
+// Secrets whose name does not match the node in the label are skipped by
+// the callers for the same reason.
+func (c *tls) getNodeSecrets(cluster *corev1alpha1.StorageCluster) ([]v1.Secret, error) {
+	nodeLabel, err := labels.NewRequirement(pxutil.TLSNodeLabelKey, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	secretList := &v1.SecretList{}
+	err = c.k8sClient.List(
+		context.TODO(),
+		secretList,
+		&client.ListOptions{
+			Namespace:     cluster.Namespace,
+			LabelSelector: labels.NewSelector().Add(*nodeLabel),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return secretList.Items, nil
+}
+
+// RegisterTLSComponent registers the TLS component
+func RegisterTLSComponent() {
+	Register(TLSComponentName, &tls{})
+}
+
+func init() {
+	RegisterTLSComponent()
+}
@@ -2,11 +2,16 @@ package portworx
 
 import (
 	"context"
-	"fmt"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"strings"
 	"testing"
+	"time"
 
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/libopenstorage/openstorage/pkg/auth"
 	"github.com/libopenstorage/operator/drivers/storage/portworx/component"
 	pxutil "github.com/libopenstorage/operator/drivers/storage/portworx/util"
 	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
@@ -32,6 +37,8 @@ import (
 	fakediscovery "k8s.io/client-go/discovery/fake"
 	fakek8sclient "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
 	api "k8s.io/kubernetes/pkg/apis/core"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -91,7 +98,7 @@ func TestBasicComponentsInstall(t *testing.T) {
 	// Portworx ClusterRole
 	expectedCR := testutil.GetExpectedClusterRole(t, "portworxClusterRole.yaml")
 	actualCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, actualCR, component.PxClusterRoleName, "")
+	err = testutil.Get(k8sClient, actualCR, util.ClusterScopedName(component.PxClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, actualCR.Name)
 	require.Len(t, actualCR.OwnerReferences, 1)
@@ -101,7 +108,7 @@ func TestBasicComponentsInstall(t *testing.T) {
 	// Portworx ClusterRoleBinding
 	expectedCRB := testutil.GetExpectedClusterRoleBinding(t, "portworxClusterRoleBinding.yaml")
 	actualCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, actualCRB, component.PxClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, actualCRB, util.ClusterScopedName(component.PxClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, actualCRB.Name)
 	require.Len(t, actualCRB.OwnerReferences, 1)
@@ -484,11 +491,11 @@ func TestDisablePortworx(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PxClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PxClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PxClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PxClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	role := &rbacv1.Role{}
@@ -539,11 +546,11 @@ func TestDisablePortworx(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PxClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PxClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PxClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PxClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	role = &rbacv1.Role{}
@@ -583,6 +590,93 @@ func TestDisablePortworx(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 }
 
+func TestBasicComponentsRemoveLegacyClusterRoles(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	reregisterComponents()
+	k8sClient := testutil.FakeK8sClient()
+	driver := portworx{}
+	driver.Init(k8sClient, runtime.NewScheme(), record.NewFakeRecorder(0))
+	startPort := uint32(10001)
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+			UID:       "px-cluster-uid",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			StartPort: &startPort,
+		},
+	}
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	otherOwnerRef := ownerRef.DeepCopy()
+	otherOwnerRef.UID = "other-cluster-uid"
+
+	// ClusterRole and ClusterRoleBinding created by an older operator, before
+	// their names were suffixed with the namespace of the cluster
+	err := k8sClient.Create(context.TODO(), &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            component.PxClusterRoleName,
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+	})
+	require.NoError(t, err)
+	err = k8sClient.Create(context.TODO(), &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            component.PxClusterRoleBindingName,
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+	})
+	require.NoError(t, err)
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	cr := &rbacv1.ClusterRole{}
+	err = testutil.Get(k8sClient, cr, component.PxClusterRoleName, "")
+	require.True(t, errors.IsNotFound(err))
+
+	crb := &rbacv1.ClusterRoleBinding{}
+	err = testutil.Get(k8sClient, crb, component.PxClusterRoleBindingName, "")
+	require.True(t, errors.IsNotFound(err))
+
+	cr = &rbacv1.ClusterRole{}
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PxClusterRoleName, cluster.Namespace), "")
+	require.NoError(t, err)
+
+	crb = &rbacv1.ClusterRoleBinding{}
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PxClusterRoleBindingName, cluster.Namespace), "")
+	require.NoError(t, err)
+
+	// TestCase: Objects with the old names that are owned by another cluster
+	// should not be removed
+	err = k8sClient.Create(context.TODO(), &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            component.PxClusterRoleName,
+			OwnerReferences: []metav1.OwnerReference{*otherOwnerRef},
+		},
+	})
+	require.NoError(t, err)
+	err = k8sClient.Create(context.TODO(), &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            component.PxClusterRoleBindingName,
+			OwnerReferences: []metav1.OwnerReference{*otherOwnerRef},
+		},
+	})
+	require.NoError(t, err)
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	cr = &rbacv1.ClusterRole{}
+	err = testutil.Get(k8sClient, cr, component.PxClusterRoleName, "")
+	require.NoError(t, err)
+
+	crb = &rbacv1.ClusterRoleBinding{}
+	err = testutil.Get(k8sClient, crb, component.PxClusterRoleBindingName, "")
+	require.NoError(t, err)
+}
+
 func TestDefaultStorageClassesWithStork(t *testing.T) {
 	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
 	reregisterComponents()
@@ -974,11 +1068,11 @@ func TestPVCControllerWithInvalidValue(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	deployment := &appsv1.Deployment{}
@@ -1013,11 +1107,11 @@ func TestPVCControllerInstallInKubeSystemNamespace(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	deployment := &appsv1.Deployment{}
@@ -1047,11 +1141,11 @@ func TestPVCControllerInstallInNonKubeSystemNamespace(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	deployment := &appsv1.Deployment{}
@@ -1071,11 +1165,11 @@ func TestPVCControllerInstallInNonKubeSystemNamespace(t *testing.T) {
 	require.NoError(t, err)
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	deployment = &appsv1.Deployment{}
@@ -1270,11 +1364,11 @@ func TestPVCControllerWhenPVCControllerDisabledExplicitly(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	deployment := &appsv1.Deployment{}
@@ -1312,11 +1406,11 @@ func TestPVCControllerInstallWithPortworxDisabled(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	deployment := &appsv1.Deployment{}
@@ -1351,7 +1445,7 @@ func verifyPVCControllerInstall(
 
 	expectedCR := testutil.GetExpectedClusterRole(t, "pvcControllerClusterRole.yaml")
 	actualCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, actualCR, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, actualCR, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, actualCR.Name)
 	require.Len(t, actualCR.OwnerReferences, 1)
@@ -1366,7 +1460,7 @@ func verifyPVCControllerInstall(
 
 	expectedCRB := testutil.GetExpectedClusterRoleBinding(t, "pvcControllerClusterRoleBinding.yaml")
 	actualCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, actualCRB, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, actualCRB, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, actualCRB.Name)
 	require.Len(t, actualCRB.OwnerReferences, 1)
@@ -1439,6 +1533,90 @@ func TestPVCControllerCustomCPU(t *testing.T) {
 	require.Zero(t, expectedCPUQuantity.Cmp(deployment.Spec.Template.Spec.Containers[0].Resources.Requests[v1.ResourceCPU]))
 }
 
+func TestPVCControllerComponentConfigChange(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	reregisterComponents()
+	k8sClient := testutil.FakeK8sClient()
+	driver := portworx{}
+	driver.Init(k8sClient, runtime.NewScheme(), record.NewFakeRecorder(0))
+
+	componentConfig := corev1alpha1.ComponentConfig{
+		Resources: &v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("400m"),
+				v1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+		PriorityClassName: "high-priority",
+		NodeSelector:      map[string]string{"infra": "true"},
+		Tolerations: []v1.Toleration{
+			{
+				Key:      "infra",
+				Operator: v1.TolerationOpExists,
+				Effect:   v1.TaintEffectNoSchedule,
+			},
+		},
+	}
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+			Annotations: map[string]string{
+				annotationPVCController:    "true",
+				annotationPVCControllerCPU: "300m",
+			},
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			PVCController: &corev1alpha1.PVCControllerSpec{
+				ComponentConfig: componentConfig,
+			},
+		},
+	}
+
+	err := driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	// Resources from the component config should override the CPU annotation
+	deployment := &appsv1.Deployment{}
+	err = testutil.Get(k8sClient, deployment, component.PVCDeploymentName, cluster.Namespace)
+	require.NoError(t, err)
+	podSpec := deployment.Spec.Template.Spec
+	require.Equal(t, *componentConfig.Resources, podSpec.Containers[0].Resources)
+	require.Equal(t, componentConfig.PriorityClassName, podSpec.PriorityClassName)
+	require.Equal(t, componentConfig.NodeSelector, podSpec.NodeSelector)
+	require.Equal(t, componentConfig.Tolerations, podSpec.Tolerations)
+
+	// Changing only the memory request should update the deployment
+	cluster.Spec.PVCController.Resources.Requests[v1.ResourceMemory] = resource.MustParse("512Mi")
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	deployment = &appsv1.Deployment{}
+	err = testutil.Get(k8sClient, deployment, component.PVCDeploymentName, cluster.Namespace)
+	require.NoError(t, err)
+	expectedMemoryQuantity := resource.MustParse("512Mi")
+	require.Zero(t, expectedMemoryQuantity.Cmp(
+		deployment.Spec.Template.Spec.Containers[0].Resources.Requests[v1.ResourceMemory]))
+
+	// Removing the component config should fall back to the CPU annotation
+	cluster.Spec.PVCController = nil
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	deployment = &appsv1.Deployment{}
+	err = testutil.Get(k8sClient, deployment, component.PVCDeploymentName, cluster.Namespace)
+	require.NoError(t, err)
+	podSpec = deployment.Spec.Template.Spec
+	expectedCPUQuantity := resource.MustParse("300m")
+	require.Zero(t, expectedCPUQuantity.Cmp(podSpec.Containers[0].Resources.Requests[v1.ResourceCPU]))
+	require.NotContains(t, podSpec.Containers[0].Resources.Requests, v1.ResourceMemory)
+	require.Empty(t, podSpec.PriorityClassName)
+	require.Empty(t, podSpec.NodeSelector)
+	require.Empty(t, podSpec.Tolerations)
+}
+
 func TestPVCControllerInvalidCPU(t *testing.T) {
 	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
 	k8sClient := testutil.FakeK8sClient()
@@ -1457,13 +1635,14 @@ func TestPVCControllerInvalidCPU(t *testing.T) {
 		},
 	}
 
-	// Should not return error but raise an event instead
+	// Should not return error but report the failure in the status instead
 	err := driver.PreInstall(cluster)
 	require.NoError(t, err)
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v Failed to setup %v.", v1.EventTypeWarning,
-			util.FailedComponentReason, component.PVCControllerComponentName))
+	require.Empty(t, recorder.Events)
+	condition := util.GetComponentCondition(cluster, component.PVCControllerComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup PVC Controller.")
 }
 
 func TestPVCControllerRollbackImageChanges(t *testing.T) {
@@ -1602,7 +1781,7 @@ func TestLighthouseInstall(t *testing.T) {
 
 	expectedCR := testutil.GetExpectedClusterRole(t, "lighthouseClusterRole.yaml")
 	actualCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, actualCR, component.LhClusterRoleName, "")
+	err = testutil.Get(k8sClient, actualCR, util.ClusterScopedName(component.LhClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, actualCR.Name)
 	require.Len(t, actualCR.OwnerReferences, 1)
@@ -1617,7 +1796,7 @@ func TestLighthouseInstall(t *testing.T) {
 
 	expectedCRB := testutil.GetExpectedClusterRoleBinding(t, "lighthouseClusterRoleBinding.yaml")
 	actualCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, actualCRB, component.LhClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, actualCRB, util.ClusterScopedName(component.LhClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, actualCRB.Name)
 	require.Len(t, actualCRB.OwnerReferences, 1)
@@ -1661,6 +1840,56 @@ func TestLighthouseInstall(t *testing.T) {
 	require.Equal(t, expectedDeployment.Spec, lhDeployment.Spec)
 }
 
+func TestLighthouseComponentConfig(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	reregisterComponents()
+	k8sClient := testutil.FakeK8sClient()
+	driver := portworx{}
+	driver.Init(k8sClient, runtime.NewScheme(), record.NewFakeRecorder(0))
+
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU: resource.MustParse("500m"),
+		},
+	}
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			UserInterface: &corev1alpha1.UserInterfaceSpec{
+				Enabled: true,
+				Image:   "portworx/px-lighthouse:2.1.1",
+				ComponentConfig: corev1alpha1.ComponentConfig{
+					Resources: &resources,
+				},
+			},
+		},
+	}
+
+	err := driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	// Resources should only be set on the lighthouse container, not on the
+	// sidecars, so they are not multiplied by the number of containers
+	deployment := &appsv1.Deployment{}
+	err = testutil.Get(k8sClient, deployment, component.LhDeploymentName, cluster.Namespace)
+	require.NoError(t, err)
+	podSpec := deployment.Spec.Template.Spec
+	require.Len(t, podSpec.Containers, 3)
+	for _, container := range podSpec.Containers {
+		if container.Name == component.LhContainerName {
+			require.Equal(t, resources, container.Resources)
+		} else {
+			require.Empty(t, container.Resources, container.Name)
+		}
+	}
+	for _, container := range podSpec.InitContainers {
+		require.Empty(t, container.Resources, container.Name)
+	}
+}
+
 func TestLighthouseServiceTypeForAKS(t *testing.T) {
 	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
 	reregisterComponents()
@@ -1851,23 +2080,23 @@ func TestLighthouseWithoutImage(t *testing.T) {
 		},
 	}
 
-	// Should not return an error, instead should raise an event
+	// Should not return an error, instead should report the failure in the status
 	err := driver.PreInstall(cluster)
 	require.NoError(t, err)
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v Failed to setup Lighthouse. lighthouse image cannot be empty",
-			v1.EventTypeWarning, util.FailedComponentReason),
-	)
+	require.Empty(t, recorder.Events)
+	condition := util.GetComponentCondition(cluster, component.LighthouseComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup Lighthouse. lighthouse image cannot be empty")
 
 	cluster.Spec.UserInterface.Image = ""
 	err = driver.PreInstall(cluster)
 	require.NoError(t, err)
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v Failed to setup Lighthouse. lighthouse image cannot be empty",
-			v1.EventTypeWarning, util.FailedComponentReason),
-	)
+	require.Empty(t, recorder.Events)
+	condition = util.GetComponentCondition(cluster, component.LighthouseComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup Lighthouse. lighthouse image cannot be empty")
 }
 
 func TestLighthouseImageChange(t *testing.T) {
@@ -2158,7 +2387,7 @@ func TestAutopilotInstall(t *testing.T) {
 	// Autopilot ClusterRole
 	expectedCR := testutil.GetExpectedClusterRole(t, "autopilotClusterRole.yaml")
 	actualCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, actualCR, component.AutopilotClusterRoleName, "")
+	err = testutil.Get(k8sClient, actualCR, util.ClusterScopedName(component.AutopilotClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, actualCR.Name)
 	require.Len(t, actualCR.OwnerReferences, 1)
@@ -2168,7 +2397,7 @@ func TestAutopilotInstall(t *testing.T) {
 	// Autopilot ClusterRoleBinding
 	expectedCRB := testutil.GetExpectedClusterRoleBinding(t, "autopilotClusterRoleBinding.yaml")
 	actualCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, actualCRB, component.AutopilotClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, actualCRB, util.ClusterScopedName(component.AutopilotClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, actualCRB.Name)
 	require.Len(t, actualCRB.OwnerReferences, 1)
@@ -2213,23 +2442,23 @@ func TestAutopilotWithoutImage(t *testing.T) {
 		},
 	}
 
-	// Should not return an error, instead should raise an event
+	// Should not return an error, instead should report the failure in the status
 	err := driver.PreInstall(cluster)
 	require.NoError(t, err)
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v Failed to setup Autopilot. autopilot image cannot be empty",
-			v1.EventTypeWarning, util.FailedComponentReason),
-	)
+	require.Empty(t, recorder.Events)
+	condition := util.GetComponentCondition(cluster, component.AutopilotComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup Autopilot. autopilot image cannot be empty")
 
 	cluster.Spec.Autopilot.Image = ""
 	err = driver.PreInstall(cluster)
 	require.NoError(t, err)
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v Failed to setup Autopilot. autopilot image cannot be empty",
-			v1.EventTypeWarning, util.FailedComponentReason),
-	)
+	require.Empty(t, recorder.Events)
+	condition = util.GetComponentCondition(cluster, component.AutopilotComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup Autopilot. autopilot image cannot be empty")
 }
 
 func TestAutopilotWithEnvironmentVariables(t *testing.T) {
@@ -2540,6 +2769,100 @@ func TestAutopilotCPUChange(t *testing.T) {
 		autopilotDeployment.Spec.Template.Spec.Containers[0].Resources.Requests[v1.ResourceCPU]))
 }
 
+func TestAutopilotComponentConfigChange(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	reregisterComponents()
+	k8sClient := testutil.FakeK8sClient()
+	driver := portworx{}
+	driver.Init(k8sClient, runtime.NewScheme(), record.NewFakeRecorder(0))
+
+	runAsNonRoot := true
+	componentConfig := corev1alpha1.ComponentConfig{
+		Resources: &v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("300m"),
+				v1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+			Limits: v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+		PriorityClassName: "high-priority",
+		NodeSelector:      map[string]string{"infra": "true"},
+		Tolerations: []v1.Toleration{
+			{
+				Key:      "infra",
+				Operator: v1.TolerationOpExists,
+				Effect:   v1.TaintEffectNoSchedule,
+			},
+		},
+		SecurityContext: &v1.PodSecurityContext{
+			RunAsNonRoot: &runAsNonRoot,
+		},
+	}
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+			Annotations: map[string]string{
+				annotationAutopilotCPU: "0.2",
+			},
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Autopilot: &corev1alpha1.AutopilotSpec{
+				Enabled:         true,
+				Image:           "portworx/autopilot:v1",
+				ComponentConfig: componentConfig,
+			},
+		},
+	}
+
+	err := driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	// Resources from the component config should override the CPU annotation
+	autopilotDeployment := &appsv1.Deployment{}
+	err = testutil.Get(k8sClient, autopilotDeployment, component.AutopilotDeploymentName, cluster.Namespace)
+	require.NoError(t, err)
+	podSpec := autopilotDeployment.Spec.Template.Spec
+	require.Equal(t, *componentConfig.Resources, podSpec.Containers[0].Resources)
+	require.Equal(t, componentConfig.PriorityClassName, podSpec.PriorityClassName)
+	require.Equal(t, componentConfig.NodeSelector, podSpec.NodeSelector)
+	require.ElementsMatch(t, componentConfig.Tolerations, podSpec.Tolerations)
+	require.Equal(t, componentConfig.SecurityContext, podSpec.SecurityContext)
+
+	// Changing only the memory limit should update the deployment
+	cluster.Spec.Autopilot.Resources.Limits[v1.ResourceMemory] = resource.MustParse("512Mi")
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	autopilotDeployment = &appsv1.Deployment{}
+	err = testutil.Get(k8sClient, autopilotDeployment, component.AutopilotDeploymentName, cluster.Namespace)
+	require.NoError(t, err)
+	expectedMemoryQuantity := resource.MustParse("512Mi")
+	require.Zero(t, expectedMemoryQuantity.Cmp(
+		autopilotDeployment.Spec.Template.Spec.Containers[0].Resources.Limits[v1.ResourceMemory]))
+
+	// Removing the component config should fall back to the CPU annotation
+	cluster.Spec.Autopilot.ComponentConfig = corev1alpha1.ComponentConfig{}
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	autopilotDeployment = &appsv1.Deployment{}
+	err = testutil.Get(k8sClient, autopilotDeployment, component.AutopilotDeploymentName, cluster.Namespace)
+	require.NoError(t, err)
+	podSpec = autopilotDeployment.Spec.Template.Spec
+	expectedCPUQuantity := resource.MustParse("0.2")
+	require.Zero(t, expectedCPUQuantity.Cmp(podSpec.Containers[0].Resources.Requests[v1.ResourceCPU]))
+	require.Empty(t, podSpec.Containers[0].Resources.Limits)
+	require.Empty(t, podSpec.PriorityClassName)
+	require.Empty(t, podSpec.NodeSelector)
+	require.Empty(t, podSpec.Tolerations)
+	require.Nil(t, podSpec.SecurityContext)
+}
+
 func TestAutopilotInvalidCPU(t *testing.T) {
 	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
 	reregisterComponents()
@@ -2566,9 +2889,68 @@ func TestAutopilotInvalidCPU(t *testing.T) {
 
 	err := driver.PreInstall(cluster)
 	require.NoError(t, err)
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v Failed to setup Autopilot.", v1.EventTypeWarning, util.FailedComponentReason))
+	require.Empty(t, recorder.Events)
+	condition := util.GetComponentCondition(cluster, component.AutopilotComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup Autopilot.")
+}
+
+func TestComponentConditions(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	reregisterComponents()
+	k8sClient := testutil.FakeK8sClient()
+	recorder := record.NewFakeRecorder(10)
+	driver := portworx{}
+	driver.Init(k8sClient, runtime.NewScheme(), recorder)
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Autopilot: &corev1alpha1.AutopilotSpec{
+				Enabled: true,
+				Image:   "portworx/autopilot:1.1.1",
+			},
+		},
+	}
+
+	err := driver.PreInstall(cluster)
+	require.NoError(t, err)
+	require.Empty(t, recorder.Events)
+
+	// There should be a condition for every component sorted by name
+	require.Len(t, cluster.Status.ComponentConditions, len(component.GetAll()))
+	for i := 1; i < len(cluster.Status.ComponentConditions); i++ {
+		require.True(t, cluster.Status.ComponentConditions[i-1].Name <
+			cluster.Status.ComponentConditions[i].Name)
+	}
+
+	condition := util.GetComponentCondition(cluster, component.AutopilotComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentReady, condition.Status)
+	require.Empty(t, condition.Reason)
+
+	condition = util.GetComponentCondition(cluster, component.PortworxBasicComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentReady, condition.Status)
+
+	condition = util.GetComponentCondition(cluster, component.LighthouseComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentDisabled, condition.Status)
+
+	// The condition should be updated when the component is disabled
+	cluster.Spec.Autopilot.Enabled = false
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+	require.Len(t, cluster.Status.ComponentConditions, len(component.GetAll()))
+
+	condition = util.GetComponentCondition(cluster, component.AutopilotComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentDisabled, condition.Status)
 }
 
 func TestCSIInstall(t *testing.T) {
@@ -2644,7 +3026,7 @@ func TestCSIInstall(t *testing.T) {
 
 	expectedCR := testutil.GetExpectedClusterRole(t, "csiClusterRole_k8s_1.11.yaml")
 	actualCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, actualCR, component.CSIClusterRoleName, "")
+	err = testutil.Get(k8sClient, actualCR, util.ClusterScopedName(component.CSIClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, actualCR.Name)
 	require.Len(t, actualCR.OwnerReferences, 1)
@@ -2659,7 +3041,7 @@ func TestCSIInstall(t *testing.T) {
 
 	expectedCRB := testutil.GetExpectedClusterRoleBinding(t, "csiClusterRoleBinding.yaml")
 	actualCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, actualCRB, component.CSIClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, actualCRB, util.ClusterScopedName(component.CSIClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, actualCRB.Name)
 	require.Len(t, actualCRB.OwnerReferences, 1)
@@ -2784,7 +3166,7 @@ func TestCSIInstallWithOlderPortworxVersion(t *testing.T) {
 
 	expectedCR := testutil.GetExpectedClusterRole(t, "csiClusterRole_k8s_1.13.yaml")
 	actualCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, actualCR, component.CSIClusterRoleName, "")
+	err = testutil.Get(k8sClient, actualCR, util.ClusterScopedName(component.CSIClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, actualCR.Name)
 	require.Len(t, actualCR.OwnerReferences, 1)
@@ -2799,7 +3181,7 @@ func TestCSIInstallWithOlderPortworxVersion(t *testing.T) {
 
 	expectedCRB := testutil.GetExpectedClusterRoleBinding(t, "csiClusterRoleBinding.yaml")
 	actualCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, actualCRB, component.CSIClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, actualCRB, util.ClusterScopedName(component.CSIClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, actualCRB.Name)
 	require.Len(t, actualCRB.OwnerReferences, 1)
@@ -2901,7 +3283,7 @@ func TestCSIInstallWithNewerCSIVersion(t *testing.T) {
 
 	expectedCR := testutil.GetExpectedClusterRole(t, "csiClusterRole_k8s_1.13.yaml")
 	actualCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, actualCR, component.CSIClusterRoleName, "")
+	err = testutil.Get(k8sClient, actualCR, util.ClusterScopedName(component.CSIClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, actualCR.Name)
 	require.Len(t, actualCR.OwnerReferences, 1)
@@ -3315,7 +3697,7 @@ func TestCSIClusterRoleK8sVersionGreaterThan_1_14(t *testing.T) {
 
 	expectedCR := testutil.GetExpectedClusterRole(t, "csiClusterRole_k8s_1.14.yaml")
 	actualCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, actualCR, component.CSIClusterRoleName, "")
+	err = testutil.Get(k8sClient, actualCR, util.ClusterScopedName(component.CSIClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, actualCR.Name)
 	require.Len(t, actualCR.OwnerReferences, 1)
@@ -3982,7 +4364,7 @@ func TestPrometheusInstall(t *testing.T) {
 	// Prometheus operator ClusterRole
 	expectedCR := testutil.GetExpectedClusterRole(t, "prometheusOperatorClusterRole.yaml")
 	actualCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, actualCR, component.PrometheusOperatorClusterRoleName, "")
+	err = testutil.Get(k8sClient, actualCR, util.ClusterScopedName(component.PrometheusOperatorClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, actualCR.Name)
 	require.Len(t, actualCR.OwnerReferences, 1)
@@ -3992,7 +4374,7 @@ func TestPrometheusInstall(t *testing.T) {
 	// Prometheus operator ClusterRoleBinding
 	expectedCRB := testutil.GetExpectedClusterRoleBinding(t, "prometheusOperatorClusterRoleBinding.yaml")
 	actualCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, actualCRB, component.PrometheusOperatorClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, actualCRB, util.ClusterScopedName(component.PrometheusOperatorClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, actualCRB.Name)
 	require.Len(t, actualCRB.OwnerReferences, 1)
@@ -4023,7 +4405,7 @@ func TestPrometheusInstall(t *testing.T) {
 	// Prometheus ClusterRole
 	expectedCR = testutil.GetExpectedClusterRole(t, "prometheusClusterRole.yaml")
 	actualCR = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, actualCR, component.PrometheusClusterRoleName, "")
+	err = testutil.Get(k8sClient, actualCR, util.ClusterScopedName(component.PrometheusClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, actualCR.Name)
 	require.Len(t, actualCR.OwnerReferences, 1)
@@ -4033,7 +4415,7 @@ func TestPrometheusInstall(t *testing.T) {
 	// Prometheus ClusterRoleBinding
 	expectedCRB = testutil.GetExpectedClusterRoleBinding(t, "prometheusClusterRoleBinding.yaml")
 	actualCRB = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, actualCRB, component.PrometheusClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, actualCRB, util.ClusterScopedName(component.PrometheusClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, actualCRB.Name)
 	require.Len(t, actualCRB.OwnerReferences, 1)
@@ -4082,6 +4464,67 @@ func TestPrometheusInstall(t *testing.T) {
 	require.Equal(t, expectedPrometheus.Spec, prometheus.Spec)
 }
 
+func TestPrometheusInstanceComponentConfig(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	reregisterComponents()
+	k8sClient := testutil.FakeK8sClient()
+	driver := portworx{}
+	driver.Init(k8sClient, runtime.NewScheme(), record.NewFakeRecorder(0))
+
+	componentConfig := corev1alpha1.ComponentConfig{
+		Resources: &v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+		PriorityClassName: "high-priority",
+		NodeSelector:      map[string]string{"infra": "true"},
+		Tolerations: []v1.Toleration{
+			{
+				Key:      "infra",
+				Operator: v1.TolerationOpExists,
+			},
+		},
+	}
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Monitoring: &corev1alpha1.MonitoringSpec{
+				Prometheus: &corev1alpha1.PrometheusSpec{
+					Enabled:         true,
+					ComponentConfig: componentConfig,
+				},
+			},
+		},
+	}
+
+	err := driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	prometheus := &monitoringv1.Prometheus{}
+	err = testutil.Get(k8sClient, prometheus, component.PrometheusInstanceName, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, *componentConfig.Resources, prometheus.Spec.Resources)
+	require.Equal(t, componentConfig.PriorityClassName, prometheus.Spec.PriorityClassName)
+	require.Equal(t, componentConfig.NodeSelector, prometheus.Spec.NodeSelector)
+	require.Equal(t, componentConfig.Tolerations, prometheus.Spec.Tolerations)
+
+	// Removing the component config should reset the prometheus instance
+	cluster.Spec.Monitoring.Prometheus.ComponentConfig = corev1alpha1.ComponentConfig{}
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	expectedPrometheus := testutil.GetExpectedPrometheus(t, "prometheusInstance.yaml")
+	prometheus = &monitoringv1.Prometheus{}
+	err = testutil.Get(k8sClient, prometheus, component.PrometheusInstanceName, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, expectedPrometheus.Spec, prometheus.Spec)
+}
+
 func TestCompleteInstallWithImagePullPolicy(t *testing.T) {
 	versionClient := fakek8sclient.NewSimpleClientset()
 	coreops.SetInstance(coreops.New(versionClient))
@@ -6657,6 +7100,439 @@ func TestCompleteInstallWithNodeAffinityChange(t *testing.T) {
 	require.Nil(t, csiDeployment.Spec.Template.Spec.Affinity)
 }
 
+func TestSecurityInstall(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	reregisterComponents()
+	k8sClient := testutil.FakeK8sClient()
+	driver := portworx{}
+	driver.Init(k8sClient, runtime.NewScheme(), record.NewFakeRecorder(0))
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Security: &corev1alpha1.SecuritySpec{
+				Enabled: true,
+			},
+		},
+	}
+
+	err := driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	// The system and shared secrets should be generated
+	systemSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, systemSecret, pxutil.SecuritySystemSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	require.NotEmpty(t, systemSecret.Data[pxutil.SecuritySystemSecretKey])
+	require.Len(t, systemSecret.OwnerReferences, 1)
+	require.Equal(t, cluster.Name, systemSecret.OwnerReferences[0].Name)
+
+	sharedSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, sharedSecret, pxutil.SecuritySharedSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	require.NotEmpty(t, sharedSecret.Data[pxutil.SecuritySharedSecretKey])
+	require.NotEqual(t, systemSecret.Data[pxutil.SecuritySystemSecretKey],
+		sharedSecret.Data[pxutil.SecuritySharedSecretKey])
+
+	// The storage pods should be restarted when the secrets change
+	require.Equal(t, "true", systemSecret.Labels[storagecluster.LabelStoragePodSecret])
+	require.Equal(t, "true", sharedSecret.Labels[storagecluster.LabelStoragePodSecret])
+
+	// The admin token should be signed with the shared secret
+	tokenSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, tokenSecret, pxutil.SecurityAdminTokenSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	token := string(tokenSecret.Data[pxutil.SecurityAuthTokenKey])
+	claims, err := auth.TokenClaims(token)
+	require.NoError(t, err)
+	require.Equal(t, pxutil.DefaultSecurityIssuer, claims.Issuer)
+	require.Equal(t, []string{"system.admin"}, claims.Roles)
+	signature, err := auth.NewSignatureSharedSecret(
+		string(sharedSecret.Data[pxutil.SecuritySharedSecretKey]))
+	require.NoError(t, err)
+	_, err = jwt.Parse(token, func(*jwt.Token) (interface{}, error) {
+		return signature.Key, nil
+	})
+	require.NoError(t, err)
+
+	// Existing secrets and valid tokens should not change
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	actualSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, actualSecret, pxutil.SecuritySharedSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, sharedSecret.Data, actualSecret.Data)
+	err = testutil.Get(k8sClient, actualSecret, pxutil.SecurityAdminTokenSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, token, string(actualSecret.Data[pxutil.SecurityAuthTokenKey]))
+
+	// Secrets should not be rotated before the rotation period passes
+	cluster.Spec.Security.Auth = &corev1alpha1.AuthSpec{
+		SelfSigned: &corev1alpha1.SelfSignedSpec{
+			SecretRotationPeriod: &metav1.Duration{Duration: time.Hour},
+		},
+	}
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	unchangedSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, unchangedSecret, pxutil.SecuritySharedSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, sharedSecret.Data, unchangedSecret.Data)
+
+	// Secrets should be rotated once the rotation period passes, and the admin
+	// token should be signed with the new shared secret
+	for _, name := range []string{pxutil.SecuritySharedSecretName, pxutil.SecuritySystemSecretName} {
+		secret := &v1.Secret{}
+		err = testutil.Get(k8sClient, secret, name, cluster.Namespace)
+		require.NoError(t, err)
+		secret.Annotations[pxutil.AnnotationSecretGeneratedAt] =
+			time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+		err = k8sClient.Update(context.TODO(), secret)
+		require.NoError(t, err)
+	}
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	rotatedSystemSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, rotatedSystemSecret, pxutil.SecuritySystemSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	require.NotEmpty(t, rotatedSystemSecret.Data[pxutil.SecuritySystemSecretKey])
+	require.NotEqual(t, systemSecret.Data, rotatedSystemSecret.Data)
+	require.Equal(t, "true", rotatedSystemSecret.Labels[storagecluster.LabelStoragePodSecret])
+
+	rotatedSharedSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, rotatedSharedSecret, pxutil.SecuritySharedSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	require.NotEmpty(t, rotatedSharedSecret.Data[pxutil.SecuritySharedSecretKey])
+	require.NotEqual(t, sharedSecret.Data, rotatedSharedSecret.Data)
+
+	tokenSecret = &v1.Secret{}
+	err = testutil.Get(k8sClient, tokenSecret, pxutil.SecurityAdminTokenSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	_, err = jwt.Parse(string(tokenSecret.Data[pxutil.SecurityAuthTokenKey]), func(*jwt.Token) (interface{}, error) {
+		return rotatedSharedSecret.Data[pxutil.SecuritySharedSecretKey], nil
+	})
+	require.NoError(t, err)
+
+	// Secrets generated by older versions should be labeled without a rotation
+	rotatedSystemSecret.Labels = nil
+	err = k8sClient.Update(context.TODO(), rotatedSystemSecret)
+	require.NoError(t, err)
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	labeledSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, labeledSecret, pxutil.SecuritySystemSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, rotatedSystemSecret.Data, labeledSecret.Data)
+	require.Equal(t, "true", labeledSecret.Labels[storagecluster.LabelStoragePodSecret])
+
+	// The token should be regenerated when the issuer changes
+	cluster.Spec.Security.Auth = &corev1alpha1.AuthSpec{
+		SelfSigned: &corev1alpha1.SelfSignedSpec{
+			Issuer: stringPtr("test-issuer"),
+		},
+	}
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	err = testutil.Get(k8sClient, actualSecret, pxutil.SecurityAdminTokenSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	claims, err = auth.TokenClaims(string(actualSecret.Data[pxutil.SecurityAuthTokenKey]))
+	require.NoError(t, err)
+	require.Equal(t, "test-issuer", claims.Issuer)
+
+	// The token should be regenerated when it outlives the token lifetime
+	token = string(actualSecret.Data[pxutil.SecurityAuthTokenKey])
+	cluster.Spec.Security.Auth.SelfSigned.TokenLifetime = &metav1.Duration{Duration: time.Hour}
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	err = testutil.Get(k8sClient, actualSecret, pxutil.SecurityAdminTokenSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	require.NotEqual(t, token, string(actualSecret.Data[pxutil.SecurityAuthTokenKey]))
+
+	// A user provided shared secret should be used instead of generating one
+	err = k8sClient.Create(context.TODO(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "user-secret",
+			Namespace: cluster.Namespace,
+		},
+		Data: map[string][]byte{
+			pxutil.SecuritySharedSecretKey: []byte("user-shared-secret"),
+		},
+	})
+	require.NoError(t, err)
+	cluster.Spec.Security.Auth.SelfSigned.SharedSecret = stringPtr("user-secret")
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	err = testutil.Get(k8sClient, actualSecret, pxutil.SecurityAdminTokenSecretName, cluster.Namespace)
+	require.NoError(t, err)
+	signature, err = auth.NewSignatureSharedSecret("user-shared-secret")
+	require.NoError(t, err)
+	_, err = jwt.Parse(string(actualSecret.Data[pxutil.SecurityAuthTokenKey]), func(*jwt.Token) (interface{}, error) {
+		return signature.Key, nil
+	})
+	require.NoError(t, err)
+
+	// The generated secrets should be removed when security is disabled
+	cluster.Spec.Security.Enabled = false
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	err = testutil.Get(k8sClient, actualSecret, pxutil.SecuritySystemSecretName, cluster.Namespace)
+	require.True(t, errors.IsNotFound(err))
+	err = testutil.Get(k8sClient, actualSecret, pxutil.SecuritySharedSecretName, cluster.Namespace)
+	require.True(t, errors.IsNotFound(err))
+	err = testutil.Get(k8sClient, actualSecret, pxutil.SecurityAdminTokenSecretName, cluster.Namespace)
+	require.True(t, errors.IsNotFound(err))
+	err = testutil.Get(k8sClient, actualSecret, "user-secret", cluster.Namespace)
+	require.NoError(t, err)
+}
+
+func TestSecurityInstallWithMissingSharedSecret(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	reregisterComponents()
+	k8sClient := testutil.FakeK8sClient()
+	driver := portworx{}
+	driver.Init(k8sClient, runtime.NewScheme(), record.NewFakeRecorder(0))
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Security: &corev1alpha1.SecuritySpec{
+				Enabled: true,
+				Auth: &corev1alpha1.AuthSpec{
+					SelfSigned: &corev1alpha1.SelfSignedSpec{
+						SharedSecret: stringPtr("missing-secret"),
+					},
+				},
+			},
+		},
+	}
+
+	// The storage pods cannot start without the shared secret
+	err := driver.PreInstall(cluster)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing-secret")
+}
+
+func TestTLSInstall(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	reregisterComponents()
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "10.0.0.1",
+				},
+			},
+		},
+	}
+	k8sClient := testutil.FakeK8sClient(node)
+	driver := portworx{}
+	driver.Init(k8sClient, runtime.NewScheme(), record.NewFakeRecorder(0))
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Security: &corev1alpha1.SecuritySpec{
+				TLS: &corev1alpha1.TLSSpec{
+					Enabled: true,
+				},
+			},
+		},
+	}
+
+	// Certificates are issued when the storage pods are created
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	err := pxutil.EnsureTLSNodeCertificate(k8sClient, cluster, "node1", ownerRef)
+	require.NoError(t, err)
+	err = pxutil.EnsureTLSNodeCertificate(k8sClient, cluster, "node2", ownerRef)
+	require.NoError(t, err)
+
+	nodeSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, nodeSecret, pxutil.TLSNodeSecretName("node1"), cluster.Namespace)
+	require.NoError(t, err)
+
+	// A node named like the CA should not replace the CA
+	caSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, caSecret, pxutil.TLSCASecretName, cluster.Namespace)
+	require.NoError(t, err)
+	err = pxutil.EnsureTLSNodeCertificate(k8sClient, cluster, "ca", ownerRef)
+	require.NoError(t, err)
+
+	// A secret with the node label that was not created for the node
+	otherSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other-secret",
+			Namespace: cluster.Namespace,
+			Labels:    map[string]string{pxutil.TLSNodeLabelKey: "node3"},
+		},
+	}
+	err = k8sClient.Create(context.TODO(), otherSecret)
+	require.NoError(t, err)
+
+	// The certificate should not be written to a secret of another node
+	err = pxutil.EnsureTLSNodeCertificate(k8sClient, cluster, "node3", ownerRef)
+	require.NoError(t, err)
+	conflictingSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.TLSNodeSecretName("node4"),
+			Namespace: cluster.Namespace,
+		},
+	}
+	err = k8sClient.Create(context.TODO(), conflictingSecret)
+	require.NoError(t, err)
+	err = pxutil.EnsureTLSNodeCertificate(k8sClient, cluster, "node4", ownerRef)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not the TLS certificate of node node4")
+
+	// The certificates of the deleted nodes should be removed, while the valid
+	// certificate, the CA and other secrets should not change
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	actualCASecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, actualCASecret, pxutil.TLSCASecretName, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, caSecret.Data, actualCASecret.Data)
+	err = testutil.Get(k8sClient, &v1.Secret{}, pxutil.TLSNodeSecretName("ca"), cluster.Namespace)
+	require.True(t, errors.IsNotFound(err))
+	err = testutil.Get(k8sClient, &v1.Secret{}, pxutil.TLSNodeSecretName("node3"), cluster.Namespace)
+	require.True(t, errors.IsNotFound(err))
+	err = testutil.Get(k8sClient, &v1.Secret{}, "other-secret", cluster.Namespace)
+	require.NoError(t, err)
+	err = testutil.Get(k8sClient, &v1.Secret{}, pxutil.TLSNodeSecretName("node4"), cluster.Namespace)
+	require.NoError(t, err)
+	err = k8sClient.Delete(context.TODO(), otherSecret)
+	require.NoError(t, err)
+	err = k8sClient.Delete(context.TODO(), conflictingSecret)
+	require.NoError(t, err)
+
+	actualSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, actualSecret, pxutil.TLSNodeSecretName("node1"), cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, nodeSecret.Data, actualSecret.Data)
+	err = testutil.Get(k8sClient, actualSecret, pxutil.TLSNodeSecretName("node2"), cluster.Namespace)
+	require.True(t, errors.IsNotFound(err))
+
+	// The portworx-api daemon set should have the CA and probe over TLS
+	ds := &appsv1.DaemonSet{}
+	err = testutil.Get(k8sClient, ds, component.PxAPIDaemonSetName, cluster.Namespace)
+	require.NoError(t, err)
+	require.Len(t, ds.Spec.Template.Spec.Volumes, 1)
+	require.Equal(t, pxutil.TLSCASecretName, ds.Spec.Template.Spec.Volumes[0].Secret.SecretName)
+	require.Equal(t, []v1.KeyToPath{{Key: pxutil.TLSCertKey, Path: pxutil.TLSCACertKey}},
+		ds.Spec.Template.Spec.Volumes[0].Secret.Items)
+	require.Equal(t, "/etc/pwx/tls", ds.Spec.Template.Spec.Containers[0].VolumeMounts[0].MountPath)
+	require.Equal(t, v1.URISchemeHTTPS, ds.Spec.Template.Spec.Containers[0].ReadinessProbe.HTTPGet.Scheme)
+
+	// The certificate should be issued again when the node addresses change
+	node.Status.Addresses[0].Address = "10.0.0.2"
+	err = k8sClient.Update(context.TODO(), node)
+	require.NoError(t, err)
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	err = testutil.Get(k8sClient, actualSecret, pxutil.TLSNodeSecretName("node1"), cluster.Namespace)
+	require.NoError(t, err)
+	require.NotEqual(t, nodeSecret.Data[pxutil.TLSCertKey], actualSecret.Data[pxutil.TLSCertKey])
+	require.Equal(t, nodeSecret.Data[pxutil.TLSCACertKey], actualSecret.Data[pxutil.TLSCACertKey])
+
+	// The certificate should be issued again when it is about to expire
+	expiringSecret := actualSecret.DeepCopy()
+	expiringSecret.Data[pxutil.TLSCertKey] = nodeCertificateExpiringIn(t, k8sClient, actualSecret, 24*time.Hour)
+	err = k8sClient.Update(context.TODO(), expiringSecret)
+	require.NoError(t, err)
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	err = testutil.Get(k8sClient, actualSecret, pxutil.TLSNodeSecretName("node1"), cluster.Namespace)
+	require.NoError(t, err)
+	require.NotEqual(t, expiringSecret.Data[pxutil.TLSCertKey], actualSecret.Data[pxutil.TLSCertKey])
+
+	// The portworx-api daemon set should use the certificate given by the user
+	cluster.Spec.Security.TLS.ServerCertSecret = stringPtr("user-cert")
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	err = testutil.Get(k8sClient, ds, component.PxAPIDaemonSetName, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, "user-cert", ds.Spec.Template.Spec.Volumes[0].Secret.SecretName)
+	require.Equal(t, []v1.KeyToPath{{Key: pxutil.TLSCACertKey, Path: pxutil.TLSCACertKey}},
+		ds.Spec.Template.Spec.Volumes[0].Secret.Items)
+
+	// The generated certificates are not needed with the user certificate
+	secretList := &v1.SecretList{}
+	err = testutil.List(k8sClient, secretList)
+	require.NoError(t, err)
+	require.Empty(t, secretList.Items)
+
+	// The daemon set should not have the CA once TLS is disabled
+	cluster.Spec.Security.TLS.Enabled = false
+
+	err = driver.PreInstall(cluster)
+	require.NoError(t, err)
+
+	ds = &appsv1.DaemonSet{}
+	err = testutil.Get(k8sClient, ds, component.PxAPIDaemonSetName, cluster.Namespace)
+	require.NoError(t, err)
+	require.Empty(t, ds.Spec.Template.Spec.Volumes)
+	require.Empty(t, ds.Spec.Template.Spec.Containers[0].ReadinessProbe.HTTPGet.Scheme)
+}
+
+// nodeCertificateExpiringIn returns the certificate in the given node secret,
+// signed again by the generated CA to expire after the given duration
+func nodeCertificateExpiringIn(
+	t *testing.T,
+	k8sClient client.Client,
+	secret *v1.Secret,
+	expiresIn time.Duration,
+) []byte {
+	caSecret := &v1.Secret{}
+	err := testutil.Get(k8sClient, caSecret, pxutil.TLSCASecretName, secret.Namespace)
+	require.NoError(t, err)
+	caCerts, err := certutil.ParseCertsPEM(caSecret.Data[pxutil.TLSCertKey])
+	require.NoError(t, err)
+	caKey, err := keyutil.ParsePrivateKeyPEM(caSecret.Data[pxutil.TLSPrivateKeyKey])
+	require.NoError(t, err)
+	certs, err := certutil.ParseCertsPEM(secret.Data[pxutil.TLSCertKey])
+	require.NoError(t, err)
+
+	template := *certs[0]
+	template.NotAfter = time.Now().Add(expiresIn)
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, caCerts[0], certs[0].PublicKey, caKey)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: certDER})
+}
+
 func TestRemovePVCController(t *testing.T) {
 	// Set fake kubernetes client for k8s version
 	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
@@ -6684,11 +7560,11 @@ func TestRemovePVCController(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	deployment := &appsv1.Deployment{}
@@ -6706,11 +7582,11 @@ func TestRemovePVCController(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	deployment = &appsv1.Deployment{}
@@ -6745,11 +7621,11 @@ func TestDisablePVCController(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	deployment := &appsv1.Deployment{}
@@ -6767,11 +7643,11 @@ func TestDisablePVCController(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PVCClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PVCClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PVCClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PVCClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	deployment = &appsv1.Deployment{}
@@ -6808,11 +7684,11 @@ func TestRemoveLighthouse(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.LhClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.LhClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.LhClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.LhClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	service := &v1.Service{}
@@ -6834,11 +7710,11 @@ func TestRemoveLighthouse(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.LhClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.LhClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.LhClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.LhClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	service = &v1.Service{}
@@ -6879,11 +7755,11 @@ func TestDisableLighthouse(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.LhClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.LhClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.LhClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.LhClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	service := &v1.Service{}
@@ -6905,11 +7781,11 @@ func TestDisableLighthouse(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.LhClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.LhClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.LhClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.LhClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	service = &v1.Service{}
@@ -6952,11 +7828,11 @@ func TestRemoveAutopilot(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.AutopilotClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.AutopilotClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.AutopilotClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.AutopilotClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	deployment := &appsv1.Deployment{}
@@ -6977,11 +7853,11 @@ func TestRemoveAutopilot(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.AutopilotClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.AutopilotClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.AutopilotClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.AutopilotClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	deployment = &appsv1.Deployment{}
@@ -7021,11 +7897,11 @@ func TestDisableAutopilot(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.AutopilotClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.AutopilotClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.AutopilotClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.AutopilotClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	deployment := &appsv1.Deployment{}
@@ -7046,11 +7922,11 @@ func TestDisableAutopilot(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.AutopilotClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.AutopilotClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.AutopilotClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.AutopilotClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	deployment = &appsv1.Deployment{}
@@ -7089,11 +7965,11 @@ func TestDisableCSI_0_3(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.CSIClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.CSIClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.CSIClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.CSIClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	service := &v1.Service{}
@@ -7115,11 +7991,11 @@ func TestDisableCSI_0_3(t *testing.T) {
 	require.NoError(t, err)
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.CSIClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.CSIClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.CSIClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.CSIClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	service = &v1.Service{}
@@ -7141,11 +8017,11 @@ func TestDisableCSI_0_3(t *testing.T) {
 	require.NoError(t, err)
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.CSIClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.CSIClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.CSIClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.CSIClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	service = &v1.Service{}
@@ -7188,11 +8064,11 @@ func TestDisableCSI_1_0(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.CSIClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.CSIClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.CSIClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.CSIClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	service := &v1.Service{}
@@ -7218,11 +8094,11 @@ func TestDisableCSI_1_0(t *testing.T) {
 	require.NoError(t, err)
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.CSIClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.CSIClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.CSIClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.CSIClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	service = &v1.Service{}
@@ -7248,11 +8124,11 @@ func TestDisableCSI_1_0(t *testing.T) {
 	require.NoError(t, err)
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.CSIClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.CSIClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.CSIClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.CSIClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	service = &v1.Service{}
@@ -7462,11 +8338,11 @@ func TestRemovePrometheus(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PrometheusOperatorClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PrometheusOperatorClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PrometheusOperatorClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PrometheusOperatorClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	deployment := &appsv1.Deployment{}
@@ -7478,11 +8354,11 @@ func TestRemovePrometheus(t *testing.T) {
 	require.NoError(t, err)
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PrometheusClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PrometheusClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PrometheusClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PrometheusClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	service := &v1.Service{}
@@ -7503,11 +8379,11 @@ func TestRemovePrometheus(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PrometheusOperatorClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PrometheusOperatorClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PrometheusOperatorClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PrometheusOperatorClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	deployment = &appsv1.Deployment{}
@@ -7519,11 +8395,11 @@ func TestRemovePrometheus(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PrometheusClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PrometheusClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PrometheusClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PrometheusClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	service = &v1.Service{}
@@ -7563,11 +8439,11 @@ func TestDisablePrometheus(t *testing.T) {
 	require.NoError(t, err)
 
 	cr := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PrometheusOperatorClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PrometheusOperatorClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PrometheusOperatorClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PrometheusOperatorClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	deployment := &appsv1.Deployment{}
@@ -7579,11 +8455,11 @@ func TestDisablePrometheus(t *testing.T) {
 	require.NoError(t, err)
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PrometheusClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PrometheusClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PrometheusClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PrometheusClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	service := &v1.Service{}
@@ -7604,11 +8480,11 @@ func TestDisablePrometheus(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PrometheusOperatorClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PrometheusOperatorClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PrometheusOperatorClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PrometheusOperatorClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	deployment = &appsv1.Deployment{}
@@ -7620,11 +8496,11 @@ func TestDisablePrometheus(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	cr = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, cr, component.PrometheusClusterRoleName, "")
+	err = testutil.Get(k8sClient, cr, util.ClusterScopedName(component.PrometheusClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	crb = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, crb, component.PrometheusClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, crb, util.ClusterScopedName(component.PrometheusClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	service = &v1.Service{}
@@ -7663,4 +8539,6 @@ func reregisterComponents() {
 	component.RegisterPVCControllerComponent()
 	component.RegisterMonitoringComponent()
 	component.RegisterPrometheusComponent()
+	component.RegisterSecurityComponent()
+	component.RegisterTLSComponent()
 }
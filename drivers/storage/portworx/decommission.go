@@ -0,0 +1,434 @@
+package portworx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/libopenstorage/openstorage/api"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/libopenstorage/operator/pkg/controller/storagecluster"
+	"github.com/libopenstorage/operator/pkg/util"
+	k8sutil "github.com/libopenstorage/operator/pkg/util/k8s"
+	operatorops "github.com/portworx/sched-ops/k8s/operator"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// volumeLabelDecommissionNode is the label on volumes whose replica is
+	// being moved off a decommissioned node. The value is the node id.
+	volumeLabelDecommissionNode = pxAnnotationPrefix + "/decommission-node"
+)
+
+// The decommission of a node is requested by the controller by adding the
+// decommission condition to the StorageNode. The driver then goes through
+// the following steps, recording each of them in the condition:
+//   Draining: replicas on the node are moved to other healthy nodes, keeping
+//     the replication level of the volumes. Volumes that have their only
+//     replica on the node block the decommission.
+//   Removing: once there are no replicas left, the controller stops the
+//     portworx pod on the node and the node is removed from the cluster.
+//   Wiping: the node-wiper removes the portworx data from the node.
+//   Decommissioned: the node is no longer part of the storage cluster.
+
+// updateNodeDecommission drains the replicas on a node that is being
+// decommissioned and removes it from the portworx cluster. The progress is
+// recorded in the decommission condition of the given StorageNode status,
+// which is saved by the caller.
+func (p *portworx) updateNodeDecommission(
+	clientConn *grpc.ClientConn,
+	cluster *corev1alpha1.StorageCluster,
+	storageNode *corev1alpha1.StorageNode,
+	node *api.StorageNode,
+) {
+	condition := getNodeCondition(&storageNode.Status, corev1alpha1.NodeDecommissionCondition)
+	if condition == nil {
+		return
+	}
+
+	var newCondition *corev1alpha1.NodeCondition
+	switch condition.Status {
+	case corev1alpha1.NodeInitStatus,
+		corev1alpha1.NodeDrainingStatus,
+		corev1alpha1.NodeFailedStatus:
+		newCondition = p.drainNodeReplicas(clientConn, node)
+	case corev1alpha1.NodeRemovingStatus:
+		newCondition = p.removeNodeFromCluster(cluster, node)
+	default:
+		// Wait for the node to disappear from the cluster before wiping it
+		return
+	}
+	if newCondition == nil {
+		return
+	}
+
+	newCondition.Type = corev1alpha1.NodeDecommissionCondition
+	changed := operatorops.Instance().UpdateStorageNodeCondition(&storageNode.Status, newCondition)
+	if changed && newCondition.Reason != "" {
+		p.warningEvent(cluster, newCondition.Reason,
+			fmt.Sprintf("Node %s: %s", storageNode.Name, newCondition.Message))
+	}
+}
+
+// drainNodeReplicas moves the replicas on the given node to other nodes without
+// lowering the replication level of the volumes. A replica is first added on a
+// healthy node, and the replica on the drained node is removed only once the
+// new replica is in sync. The volumes being moved are labeled with the node id,
+// so the move can be resumed across reconciles.
+func (p *portworx) drainNodeReplicas(
+	clientConn *grpc.ClientConn,
+	node *api.StorageNode,
+) *corev1alpha1.NodeCondition {
+	volumeClient := api.NewOpenStorageVolumeClient(clientConn)
+	resp, err := volumeClient.InspectWithFilters(
+		context.TODO(),
+		&api.SdkVolumeInspectWithFiltersRequest{},
+	)
+	if err != nil {
+		return &corev1alpha1.NodeCondition{
+			Status:  corev1alpha1.NodeFailedStatus,
+			Reason:  util.FailedDecommissionReason,
+			Message: fmt.Sprintf("Failed to get volumes: %v", err),
+		}
+	}
+
+	var blockingVolumes []string
+	var volumesToDrain []*api.Volume
+	replicaCount := make(map[string]int)
+	for _, volumeResp := range resp.Volumes {
+		volume := volumeResp.Volume
+		if volume == nil {
+			continue
+		}
+		for _, replicaSet := range volume.ReplicaSets {
+			for _, nodeID := range replicaSet.Nodes {
+				replicaCount[nodeID]++
+			}
+			if !containsString(replicaSet.Nodes, node.Id) {
+				continue
+			}
+			if len(replicaSet.Nodes) == 1 || len(volume.ReplicaSets) > 1 {
+				// Aggregated volumes are not drained automatically, as the
+				// replicas cannot be moved without changing the aggregation
+				blockingVolumes = append(blockingVolumes, volumeName(volume))
+			} else {
+				volumesToDrain = append(volumesToDrain, volume)
+			}
+		}
+	}
+
+	if len(blockingVolumes) > 0 {
+		sort.Strings(blockingVolumes)
+		return &corev1alpha1.NodeCondition{
+			Status: corev1alpha1.NodeFailedStatus,
+			Reason: util.FailedDecommissionReason,
+			Message: fmt.Sprintf("Volumes [%s] have their only replica on the node and "+
+				"cannot be drained", strings.Join(blockingVolumes, ", ")),
+		}
+	} else if len(volumesToDrain) == 0 {
+		return &corev1alpha1.NodeCondition{
+			Status:  corev1alpha1.NodeRemovingStatus,
+			Message: "Removing the node from the storage cluster",
+		}
+	}
+
+	targetNodes, err := p.getReplicaTargetNodes(clientConn, node)
+	if err != nil {
+		return &corev1alpha1.NodeCondition{
+			Status:  corev1alpha1.NodeFailedStatus,
+			Reason:  util.FailedDecommissionReason,
+			Message: fmt.Sprintf("Failed to get storage nodes: %v", err),
+		}
+	}
+
+	for _, volume := range volumesToDrain {
+		replicaNodes := volume.ReplicaSets[0].Nodes
+		haLevel := volume.GetSpec().GetHaLevel()
+		var update *api.SdkVolumeUpdateRequest
+		if volumeLabel(volume, volumeLabelDecommissionNode) != node.Id {
+			target := pickReplicaTargetNode(targetNodes, replicaNodes, replicaCount)
+			if target == "" {
+				return &corev1alpha1.NodeCondition{
+					Status: corev1alpha1.NodeFailedStatus,
+					Reason: util.FailedDecommissionReason,
+					Message: fmt.Sprintf("No healthy node available to move the replica "+
+						"of volume %s to", volumeName(volume)),
+				}
+			}
+			replicaCount[target]++
+			update = &api.SdkVolumeUpdateRequest{
+				VolumeId: volume.Id,
+				Labels:   map[string]string{volumeLabelDecommissionNode: node.Id},
+				Spec: &api.VolumeSpecUpdate{
+					HaLevelOpt: &api.VolumeSpecUpdate_HaLevel{
+						HaLevel: haLevel + 1,
+					},
+					ReplicaSet: &api.ReplicaSet{
+						Nodes: append(append([]string{}, replicaNodes...), target),
+					},
+				},
+			}
+		} else if int64(len(replicaNodes)) >= haLevel && volume.Status == api.VolumeStatus_VOLUME_STATUS_UP {
+			// The new replica is in sync, so the one on the node can be removed
+			update = &api.SdkVolumeUpdateRequest{
+				VolumeId: volume.Id,
+				Labels:   map[string]string{volumeLabelDecommissionNode: ""},
+				Spec: &api.VolumeSpecUpdate{
+					HaLevelOpt: &api.VolumeSpecUpdate_HaLevel{
+						HaLevel: haLevel - 1,
+					},
+					ReplicaSet: &api.ReplicaSet{
+						Nodes: removeString(replicaNodes, node.Id),
+					},
+				},
+			}
+		} else {
+			// Wait for the new replica to be in sync
+			continue
+		}
+
+		if _, err := volumeClient.Update(context.TODO(), update); err != nil {
+			return &corev1alpha1.NodeCondition{
+				Status: corev1alpha1.NodeFailedStatus,
+				Reason: util.FailedDecommissionReason,
+				Message: fmt.Sprintf("Failed to move the replica of volume %s off the node: %v",
+					volumeName(volume), err),
+			}
+		}
+	}
+	return &corev1alpha1.NodeCondition{
+		Status:  corev1alpha1.NodeDrainingStatus,
+		Message: fmt.Sprintf("Moving replicas of %d volume(s) off the node", len(volumesToDrain)),
+	}
+}
+
+// getReplicaTargetNodes returns the ids of the healthy storage nodes, other
+// than the given node, where the replicas of the node can be moved
+func (p *portworx) getReplicaTargetNodes(
+	clientConn *grpc.ClientConn,
+	node *api.StorageNode,
+) ([]string, error) {
+	nodeClient := api.NewOpenStorageNodeClient(clientConn)
+	resp, err := nodeClient.EnumerateWithFilters(
+		context.TODO(),
+		&api.SdkNodeEnumerateWithFiltersRequest{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var targetNodes []string
+	for _, n := range resp.Nodes {
+		if n == nil || n.Id == node.Id || n.Status != api.Status_STATUS_OK || len(n.Pools) == 0 {
+			continue
+		}
+		targetNodes = append(targetNodes, n.Id)
+	}
+	sort.Strings(targetNodes)
+	return targetNodes, nil
+}
+
+// pickReplicaTargetNode returns the node with the fewest replicas that does not
+// have a replica of the volume yet, or an empty string if there is none
+func pickReplicaTargetNode(
+	targetNodes []string,
+	replicaNodes []string,
+	replicaCount map[string]int,
+) string {
+	target := ""
+	for _, nodeID := range targetNodes {
+		if containsString(replicaNodes, nodeID) {
+			continue
+		}
+		if target == "" || replicaCount[nodeID] < replicaCount[target] {
+			target = nodeID
+		}
+	}
+	return target
+}
+
+// removeNodeFromCluster removes the node from the portworx cluster once the
+// portworx pod on the node has been stopped. The SDK does not expose node
+// removal, so pxctl is run in a portworx pod on another node.
+func (p *portworx) removeNodeFromCluster(
+	cluster *corev1alpha1.StorageCluster,
+	node *api.StorageNode,
+) *corev1alpha1.NodeCondition {
+	if node.Status == api.Status_STATUS_OK {
+		// Wait for the controller to stop the portworx pod on the node
+		return nil
+	}
+
+	pod, err := p.getPortworxPod(cluster, func(pod *v1.Pod) bool {
+		return pod.Spec.NodeName != node.SchedulerNodeName && pod.Status.Phase == v1.PodRunning
+	})
+	if err == nil && pod == nil {
+		err = fmt.Errorf("portworx pod not found on any other node")
+	}
+	if err == nil {
+		cmds := []string{pxctlPath, "cluster", "delete", node.Id}
+		if _, err = runCommandInPod(cmds, pod.Name, pxContainerName, pod.Namespace); err != nil {
+			err = fmt.Errorf("failed to run '%s' in pod %s: %v", strings.Join(cmds, " "), pod.Name, err)
+		}
+	}
+	if err != nil {
+		// The node is retried in the next reconcile, as the portworx pod
+		// on the node is not brought back once it has been stopped
+		return &corev1alpha1.NodeCondition{
+			Status:  corev1alpha1.NodeRemovingStatus,
+			Reason:  util.FailedDecommissionReason,
+			Message: fmt.Sprintf("Failed to remove the node from the storage cluster: %v", err),
+		}
+	}
+	return &corev1alpha1.NodeCondition{
+		Status:  corev1alpha1.NodeWipingStatus,
+		Message: "Wiping portworx from the node",
+	}
+}
+
+// updateRemovedNodeDecommission completes the decommission of a node that is
+// no longer part of the portworx cluster by wiping portworx from the node
+func (p *portworx) updateRemovedNodeDecommission(
+	cluster *corev1alpha1.StorageCluster,
+	storageNode *corev1alpha1.StorageNode,
+) error {
+	condition := getNodeCondition(&storageNode.Status, corev1alpha1.NodeDecommissionCondition)
+	if condition == nil {
+		return nil
+	}
+
+	var newCondition *corev1alpha1.NodeCondition
+	switch condition.Status {
+	case corev1alpha1.NodeRemovingStatus, corev1alpha1.NodeWipingStatus:
+		newCondition = p.wipeNode(cluster, storageNode.Name)
+	default:
+		if storageNode.DeletionTimestamp == nil {
+			// Nodes that have not been drained yet are not touched, as they are
+			// removed from the cluster only after all their replicas are gone
+			return nil
+		}
+		// The StorageNode has been deleted after the node left the cluster,
+		// so there are no replicas left to drain
+		newCondition = &corev1alpha1.NodeCondition{
+			Status:  corev1alpha1.NodeRemovingStatus,
+			Message: "Node is no longer part of the storage cluster",
+		}
+	}
+
+	originalStatus := storageNode.Status.DeepCopy()
+	newCondition.Type = corev1alpha1.NodeDecommissionCondition
+	changed := operatorops.Instance().UpdateStorageNodeCondition(&storageNode.Status, newCondition)
+	if changed && newCondition.Reason != "" {
+		p.warningEvent(cluster, newCondition.Reason,
+			fmt.Sprintf("Node %s: %s", storageNode.Name, newCondition.Message))
+	}
+	if newCondition.Status == corev1alpha1.NodeDecommissionedStatus {
+		storageNode.Status.Phase = string(corev1alpha1.NodeDecommissionedStatus)
+	}
+	if !changed && originalStatus.Phase == storageNode.Status.Phase {
+		return nil
+	}
+
+	logrus.Debugf("Updating StorageNode %s/%s status", storageNode.Namespace, storageNode.Name)
+	return p.k8sClient.Status().Update(context.TODO(), storageNode)
+}
+
+// wipeNode runs the node-wiper on the given node and waits for it to complete
+func (p *portworx) wipeNode(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+) *corev1alpha1.NodeCondition {
+	k8sNode := &v1.Node{}
+	err := p.k8sClient.Get(context.TODO(), types.NamespacedName{Name: nodeName}, k8sNode)
+	if errors.IsNotFound(err) {
+		return &corev1alpha1.NodeCondition{
+			Status:  corev1alpha1.NodeDecommissionedStatus,
+			Message: "Node has been removed from the storage cluster",
+		}
+	}
+
+	u := NewUninstaller(cluster, p.k8sClient)
+	var completed bool
+	if err == nil {
+		completed, err = u.GetNodeWiperStatusOnNode(nodeName)
+		if errors.IsNotFound(err) {
+			nodeWiperImage := k8sutil.GetValueFromEnv(envKeyNodeWiperImage, cluster.Spec.Env)
+			err = u.RunNodeWiperOnNode(nodeWiperImage, nodeName, true)
+		}
+	}
+	if err == nil && completed {
+		err = u.RemoveNodeWiperOnNode(nodeName)
+	}
+	if err != nil {
+		return &corev1alpha1.NodeCondition{
+			Status:  corev1alpha1.NodeWipingStatus,
+			Reason:  util.FailedDecommissionReason,
+			Message: fmt.Sprintf("Failed to wipe portworx from the node: %v", err),
+		}
+	} else if !completed {
+		return &corev1alpha1.NodeCondition{
+			Status:  corev1alpha1.NodeWipingStatus,
+			Message: "Wiping portworx from the node",
+		}
+	}
+	return &corev1alpha1.NodeCondition{
+		Status:  corev1alpha1.NodeDecommissionedStatus,
+		Message: "Node has been removed from the storage cluster and wiped",
+	}
+}
+
+func (p *portworx) removeStorageNodeFinalizer(storageNode *corev1alpha1.StorageNode) error {
+	finalizers := storagecluster.RemoveStorageNodeFinalizer(storageNode.Finalizers)
+	if len(finalizers) == len(storageNode.Finalizers) {
+		return nil
+	}
+	storageNode.Finalizers = finalizers
+	return p.k8sClient.Update(context.TODO(), storageNode)
+}
+
+func getNodeCondition(
+	status *corev1alpha1.NodeStatus,
+	conditionType corev1alpha1.NodeConditionType,
+) *corev1alpha1.NodeCondition {
+	for _, condition := range status.Conditions {
+		if condition.Type == conditionType {
+			return condition.DeepCopy()
+		}
+	}
+	return nil
+}
+
+func volumeLabel(volume *api.Volume, key string) string {
+	return volume.GetLocator().GetVolumeLabels()[key]
+}
+
+func volumeName(volume *api.Volume) string {
+	if volume.Locator != nil && volume.Locator.Name != "" {
+		return volume.Locator.Name
+	}
+	return volume.Id
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, value string) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != value {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -165,6 +165,10 @@ var (
 		name:      "kvdbcerts",
 		mountPath: "/etc/pwx/kvdbcerts",
 	}
+	tlsVolumeInfo = volumeInfo{
+		name:      "pxtlscerts",
+		mountPath: "/etc/pwx/tls",
+	}
 )
 
 type template struct {
@@ -179,6 +183,8 @@ type template struct {
 	csiConfig       *pxutil.CSIConfiguration
 	kvdb            map[string]string
 	cloudConfig     *cloudstorage.Config
+	// tlsSecretName is the secret with the TLS certificate of the node
+	tlsSecretName string
 }
 
 func newTemplate(
@@ -287,11 +293,24 @@ func (p *portworx) GetStoragePodSpec(
 		t.cloudConfig = cloudConfig
 	}
 
+	if pxutil.TLSEnabled(cluster) {
+		t.tlsSecretName = pxutil.TLSServerCertSecretName(cluster)
+		if t.tlsSecretName == "" {
+			ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+			err := pxutil.EnsureTLSNodeCertificate(p.k8sClient, cluster, nodeName, ownerRef)
+			if err != nil {
+				return v1.PodSpec{}, fmt.Errorf("failed to get TLS certificate for node %s: %v", nodeName, err)
+			}
+			t.tlsSecretName = pxutil.TLSNodeSecretName(nodeName)
+		}
+	}
+
 	containers := t.portworxContainer()
 	podSpec := v1.PodSpec{
 		HostNetwork:        true,
 		RestartPolicy:      v1.RestartPolicyAlways,
 		ServiceAccountName: pxutil.PortworxServiceAccountName,
+		PriorityClassName:  t.cluster.Spec.PriorityClassName,
 		Containers:         []v1.Container{containers},
 		Volumes:            t.getVolumes(),
 	}
@@ -404,7 +423,7 @@ func configureStorageNodeSpec(node *corev1alpha1.StorageNode, config *cloudstora
 
 func (t *template) portworxContainer() v1.Container {
 	pxImage := util.GetImageURN(t.cluster.Spec.CustomImageRegistry, t.cluster.Spec.Image)
-	return v1.Container{
+	container := v1.Container{
 		Name:            pxContainerName,
 		Image:           pxImage,
 		ImagePullPolicy: t.imagePullPolicy,
@@ -437,6 +456,16 @@ func (t *template) portworxContainer() v1.Container {
 		},
 		VolumeMounts: t.getVolumeMounts(),
 	}
+
+	if t.tlsSecretName != "" {
+		// The REST endpoint is served over TLS, unlike the health endpoint
+		container.LivenessProbe.HTTPGet.Scheme = v1.URISchemeHTTPS
+	}
+
+	if t.cluster.Spec.Resources != nil {
+		container.Resources = *t.cluster.Spec.Resources.DeepCopy()
+	}
+	return container
 }
 
 func (t *template) csiRegistrarContainer() *v1.Container {
@@ -499,6 +528,9 @@ func (t *template) csiRegistrarContainer() *v1.Container {
 	if container.Name == "" {
 		return nil
 	}
+	if t.cluster.Spec.CSI != nil && t.cluster.Spec.CSI.Resources != nil {
+		container.Resources = *t.cluster.Spec.CSI.Resources.DeepCopy()
+	}
 	return &container
 }
 
@@ -670,6 +702,17 @@ func (t *template) getArguments() []string {
 		args = append(args, "-secret_type", *t.cluster.Spec.SecretsProvider)
 	}
 
+	if t.tlsSecretName != "" {
+		// Clients are not required to have certificates, as they are
+		// authenticated with tokens when authorization is enabled
+		args = append(args,
+			"-apirootca", path.Join(tlsVolumeInfo.mountPath, pxutil.TLSCACertKey),
+			"-apicert", path.Join(tlsVolumeInfo.mountPath, pxutil.TLSCertKey),
+			"-apikey", path.Join(tlsVolumeInfo.mountPath, pxutil.TLSPrivateKeyKey),
+			"-apidisclientauth",
+		)
+	}
+
 	if t.startPort != pxutil.DefaultStartPort {
 		args = append(args, "-r", strconv.Itoa(int(t.startPort)))
 	}
@@ -775,6 +818,12 @@ func (t *template) getEnvList() []v1.EnvVar {
 		}
 	}
 
+	if pxutil.SecurityEnabled(t.cluster) {
+		for _, env := range t.getSecurityEnvList() {
+			envMap[env.Name] = env
+		}
+	}
+
 	// Copy user provided env and overwrite default ones with user's values
 	for _, env := range t.cluster.Spec.Env {
 		envMap[env.Name] = env.DeepCopy()
@@ -787,6 +836,57 @@ func (t *template) getEnvList() []v1.EnvVar {
 	return envList
 }
 
+func (t *template) getSecurityEnvList() []*v1.EnvVar {
+	envList := []*v1.EnvVar{
+		{
+			Name:  pxutil.EnvKeyPortworxAuthJwtIssuer,
+			Value: pxutil.SecurityIssuer(t.cluster),
+		},
+		{
+			Name: pxutil.EnvKeyPortworxAuthJwtSharedSecret,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					Key: pxutil.SecuritySharedSecretKey,
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: pxutil.SharedSecretName(t.cluster),
+					},
+				},
+			},
+		},
+		{
+			Name: pxutil.EnvKeyPortworxAuthSystemKey,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					Key: pxutil.SecuritySystemSecretKey,
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: pxutil.SecuritySystemSecretName,
+					},
+				},
+			},
+		},
+	}
+
+	if auth := t.cluster.Spec.Security.Auth; auth != nil && auth.OIDC != nil {
+		envList = append(envList,
+			&v1.EnvVar{
+				Name:  pxutil.EnvKeyPortworxAuthOIDCIssuer,
+				Value: auth.OIDC.Issuer,
+			},
+			&v1.EnvVar{
+				Name:  pxutil.EnvKeyPortworxAuthOIDCClientID,
+				Value: auth.OIDC.ClientID,
+			},
+		)
+		if auth.OIDC.CustomNamespace != "" {
+			envList = append(envList, &v1.EnvVar{
+				Name:  pxutil.EnvKeyPortworxAuthOIDCCustomNamespace,
+				Value: auth.OIDC.CustomNamespace,
+			})
+		}
+	}
+	return envList
+}
+
 func (t *template) getVolumeMounts() []v1.VolumeMount {
 	volumeInfoList := append([]volumeInfo{}, defaultVolumeInfoList...)
 	volumeMounts := make([]v1.VolumeMount, 0)
@@ -813,6 +913,14 @@ func (t *template) getVolumeMounts() []v1.VolumeMount {
 		})
 	}
 
+	if t.tlsSecretName != "" {
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      tlsVolumeInfo.name,
+			MountPath: tlsVolumeInfo.mountPath,
+			ReadOnly:  true,
+		})
+	}
+
 	return volumeMounts
 }
 
@@ -879,6 +987,17 @@ func (t *template) getVolumes() []v1.Volume {
 		volumes = append(volumes, kvdbVolume)
 	}
 
+	if t.tlsSecretName != "" {
+		volumes = append(volumes, v1.Volume{
+			Name: tlsVolumeInfo.name,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: t.tlsSecretName,
+				},
+			},
+		})
+	}
+
 	return volumes
 }
 
@@ -1,7 +1,9 @@
 package portworx
 
 import (
+	"crypto/x509"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -14,12 +16,14 @@ import (
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
 	fakediscovery "k8s.io/client-go/discovery/fake"
 	fakek8sclient "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	certutil "k8s.io/client-go/util/cert"
 )
 
 func TestBasicRuncPodSpec(t *testing.T) {
@@ -202,6 +206,218 @@ func TestPodSpecWithEnvOverrides(t *testing.T) {
 	assertPodSpecEqual(t, expected, &actual)
 }
 
+func TestPodSpecWithSecurity(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	nodeName := "testNode"
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-system",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "portworx/oci-monitor:2.5.1",
+			Security: &corev1alpha1.SecuritySpec{
+				Enabled: true,
+			},
+		},
+	}
+	issuerEnv := v1.EnvVar{
+		Name:  pxutil.EnvKeyPortworxAuthJwtIssuer,
+		Value: pxutil.DefaultSecurityIssuer,
+	}
+	sharedSecretEnv := v1.EnvVar{
+		Name: pxutil.EnvKeyPortworxAuthJwtSharedSecret,
+		ValueFrom: &v1.EnvVarSource{
+			SecretKeyRef: &v1.SecretKeySelector{
+				Key: pxutil.SecuritySharedSecretKey,
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: pxutil.SecuritySharedSecretName,
+				},
+			},
+		},
+	}
+	systemKeyEnv := v1.EnvVar{
+		Name: pxutil.EnvKeyPortworxAuthSystemKey,
+		ValueFrom: &v1.EnvVarSource{
+			SecretKeyRef: &v1.SecretKeySelector{
+				Key: pxutil.SecuritySystemSecretKey,
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: pxutil.SecuritySystemSecretName,
+				},
+			},
+		},
+	}
+
+	driver := portworx{}
+	actual, err := driver.GetStoragePodSpec(cluster, nodeName)
+	require.NoError(t, err)
+	require.Contains(t, actual.Containers[0].Env, issuerEnv)
+	require.Contains(t, actual.Containers[0].Env, sharedSecretEnv)
+	require.Contains(t, actual.Containers[0].Env, systemKeyEnv)
+
+	// Custom issuer, shared secret and OIDC provider
+	cluster.Spec.Security.Auth = &corev1alpha1.AuthSpec{
+		SelfSigned: &corev1alpha1.SelfSignedSpec{
+			Issuer:       stringPtr("test-issuer"),
+			SharedSecret: stringPtr("test-secret"),
+		},
+		OIDC: &corev1alpha1.OIDCSpec{
+			Issuer:          "https://oidc-issuer",
+			ClientID:        "test-client",
+			CustomNamespace: "test-namespace",
+		},
+	}
+	issuerEnv.Value = "test-issuer"
+	sharedSecretEnv.ValueFrom.SecretKeyRef.Name = "test-secret"
+
+	actual, err = driver.GetStoragePodSpec(cluster, nodeName)
+	require.NoError(t, err)
+	require.Contains(t, actual.Containers[0].Env, issuerEnv)
+	require.Contains(t, actual.Containers[0].Env, sharedSecretEnv)
+	require.Contains(t, actual.Containers[0].Env, systemKeyEnv)
+	require.Contains(t, actual.Containers[0].Env, v1.EnvVar{
+		Name:  pxutil.EnvKeyPortworxAuthOIDCIssuer,
+		Value: "https://oidc-issuer",
+	})
+	require.Contains(t, actual.Containers[0].Env, v1.EnvVar{
+		Name:  pxutil.EnvKeyPortworxAuthOIDCClientID,
+		Value: "test-client",
+	})
+	require.Contains(t, actual.Containers[0].Env, v1.EnvVar{
+		Name:  pxutil.EnvKeyPortworxAuthOIDCCustomNamespace,
+		Value: "test-namespace",
+	})
+
+	// No security env variables if security is disabled
+	cluster.Spec.Security.Enabled = false
+
+	actual, err = driver.GetStoragePodSpec(cluster, nodeName)
+	require.NoError(t, err)
+	for _, env := range actual.Containers[0].Env {
+		require.False(t, strings.HasPrefix(env.Name, "PORTWORX_AUTH_"))
+	}
+}
+
+func TestPodSpecWithTLS(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	nodeName := "testNode"
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: nodeName,
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "10.0.0.1",
+					},
+				},
+			},
+		},
+	)
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-system",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "portworx/oci-monitor:2.5.1",
+			Security: &corev1alpha1.SecuritySpec{
+				TLS: &corev1alpha1.TLSSpec{
+					Enabled: true,
+				},
+			},
+		},
+	}
+	expectedArgs := []string{
+		"-apirootca", "/etc/pwx/tls/ca.crt",
+		"-apicert", "/etc/pwx/tls/tls.crt",
+		"-apikey", "/etc/pwx/tls/tls.key",
+		"-apidisclientauth",
+	}
+	expectedMount := v1.VolumeMount{
+		Name:      "pxtlscerts",
+		MountPath: "/etc/pwx/tls",
+		ReadOnly:  true,
+	}
+
+	driver := portworx{
+		k8sClient: k8sClient,
+	}
+	actual, err := driver.GetStoragePodSpec(cluster, nodeName)
+	require.NoError(t, err)
+	require.Subset(t, actual.Containers[0].Args, expectedArgs)
+	require.Contains(t, actual.Containers[0].VolumeMounts, expectedMount)
+	require.Contains(t, actual.Volumes, v1.Volume{
+		Name: "pxtlscerts",
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: pxutil.TLSNodeSecretName(nodeName),
+			},
+		},
+	})
+	require.Equal(t, v1.URISchemeHTTPS, actual.Containers[0].LivenessProbe.HTTPGet.Scheme)
+
+	// The certificate of the node should be issued by the generated CA
+	caSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, caSecret, pxutil.TLSCASecretName, cluster.Namespace)
+	require.NoError(t, err)
+	nodeSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, nodeSecret, pxutil.TLSNodeSecretName(nodeName), cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, nodeName, nodeSecret.Labels[pxutil.TLSNodeLabelKey])
+	require.Equal(t, caSecret.Data[pxutil.TLSCertKey], nodeSecret.Data[pxutil.TLSCACertKey])
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caSecret.Data[pxutil.TLSCertKey]))
+	certs, err := certutil.ParseCertsPEM(nodeSecret.Data[pxutil.TLSCertKey])
+	require.NoError(t, err)
+	for _, host := range []string{nodeName, "10.0.0.1", "portworx-service.kube-system.svc"} {
+		_, err = certs[0].Verify(x509.VerifyOptions{
+			DNSName:   host,
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+		require.NoError(t, err)
+	}
+
+	// The same certificate should be used for the next pod on the node
+	_, err = driver.GetStoragePodSpec(cluster, nodeName)
+	require.NoError(t, err)
+	actualSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, actualSecret, pxutil.TLSNodeSecretName(nodeName), cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, nodeSecret.Data, actualSecret.Data)
+
+	// The certificate given by the user should be used by all the nodes
+	cluster.Spec.Security.TLS.ServerCertSecret = stringPtr("user-cert")
+
+	actual, err = driver.GetStoragePodSpec(cluster, nodeName)
+	require.NoError(t, err)
+	require.Subset(t, actual.Containers[0].Args, expectedArgs)
+	require.Contains(t, actual.Containers[0].VolumeMounts, expectedMount)
+	require.Contains(t, actual.Volumes, v1.Volume{
+		Name: "pxtlscerts",
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: "user-cert",
+			},
+		},
+	})
+
+	// No TLS configuration if TLS is disabled
+	cluster.Spec.Security.TLS.Enabled = false
+
+	actual, err = driver.GetStoragePodSpec(cluster, nodeName)
+	require.NoError(t, err)
+	require.NotContains(t, actual.Containers[0].Args, "-apicert")
+	require.NotContains(t, actual.Containers[0].VolumeMounts, expectedMount)
+	require.Empty(t, actual.Containers[0].LivenessProbe.HTTPGet.Scheme)
+}
+
 func TestAutoNodeRecoveryTimeoutEnvForPxVersion2_6(t *testing.T) {
 	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
 	nodeName := "testNode"
@@ -1428,6 +1644,106 @@ func TestPodSpecWithImagePullPolicy(t *testing.T) {
 	assert.Equal(t, v1.PullIfNotPresent, actual.Containers[1].ImagePullPolicy)
 }
 
+func TestPodSpecWithResources(t *testing.T) {
+	fakeClient := fakek8sclient.NewSimpleClientset()
+	coreops.SetInstance(coreops.New(fakeClient))
+	fakeClient.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{
+		GitVersion: "v1.12.8",
+	}
+
+	nodeName := "testNode"
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-system",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			CommonConfig: corev1alpha1.CommonConfig{
+				Resources: &v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("1"),
+						v1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+					Limits: v1.ResourceList{
+						v1.ResourceMemory: resource.MustParse("8Gi"),
+					},
+				},
+			},
+			FeatureGates: map[string]string{
+				string(pxutil.FeatureCSI): "True",
+			},
+		},
+	}
+	driver := portworx{}
+
+	actual, err := driver.GetStoragePodSpec(cluster, nodeName)
+	assert.NoError(t, err, "Unexpected error on GetStoragePodSpec")
+
+	// Resources should only be set on the portworx container
+	assert.Len(t, actual.Containers, 2)
+	assert.Equal(t, *cluster.Spec.Resources, actual.Containers[0].Resources)
+	assert.Empty(t, actual.Containers[1].Resources)
+
+	// No resources should be set if not specified
+	cluster.Spec.Resources = nil
+
+	actual, err = driver.GetStoragePodSpec(cluster, nodeName)
+	assert.NoError(t, err, "Unexpected error on GetStoragePodSpec")
+	assert.Empty(t, actual.Containers[0].Resources)
+}
+
+func TestPodSpecWithPriorityClassAndCSIResources(t *testing.T) {
+	fakeClient := fakek8sclient.NewSimpleClientset()
+	coreops.SetInstance(coreops.New(fakeClient))
+	fakeClient.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{
+		GitVersion: "v1.12.8",
+	}
+
+	nodeName := "testNode"
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-system",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			PriorityClassName: "system-node-critical",
+			CSI: &corev1alpha1.CSISpec{
+				ComponentConfig: corev1alpha1.ComponentConfig{
+					Resources: &v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU: resource.MustParse("50m"),
+						},
+					},
+				},
+			},
+			FeatureGates: map[string]string{
+				string(pxutil.FeatureCSI): "True",
+			},
+		},
+	}
+	driver := portworx{}
+
+	actual, err := driver.GetStoragePodSpec(cluster, nodeName)
+	assert.NoError(t, err, "Unexpected error on GetStoragePodSpec")
+
+	// CSI resources should only be set on the CSI registrar container
+	assert.Equal(t, cluster.Spec.PriorityClassName, actual.PriorityClassName)
+	assert.Len(t, actual.Containers, 2)
+	assert.Empty(t, actual.Containers[0].Resources)
+	assert.Equal(t, *cluster.Spec.CSI.Resources, actual.Containers[1].Resources)
+
+	// Nothing should be set if not specified
+	cluster.Spec.PriorityClassName = ""
+	cluster.Spec.CSI = nil
+
+	actual, err = driver.GetStoragePodSpec(cluster, nodeName)
+	assert.NoError(t, err, "Unexpected error on GetStoragePodSpec")
+	assert.Empty(t, actual.PriorityClassName)
+	assert.Empty(t, actual.Containers[1].Resources)
+}
+
 func TestPodSpecWithNilStorageCluster(t *testing.T) {
 	var cluster *corev1alpha1.StorageCluster
 	driver := portworx{}
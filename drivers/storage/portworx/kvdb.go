@@ -0,0 +1,490 @@
+package portworx
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libopenstorage/openstorage/api"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/libopenstorage/operator/pkg/util"
+	"github.com/portworx/kvdb"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// kvdbHealthCheckInterval is how often the external kvdb endpoints are checked
+	kvdbHealthCheckInterval = time.Minute
+	// kvdbProbeTimeout is how long to wait for an endpoint to respond before it
+	// is considered unhealthy. The kvdb library does not time out on its own.
+	kvdbProbeTimeout = 10 * time.Second
+	// kvdbHealthKey is the key read from the endpoints to check their health.
+	// It does not need to exist.
+	kvdbHealthKey = "operator/health"
+	// kvdbCertDirPrefix is the prefix of the directories the kvdb certificates
+	// are written to, as the kvdb library only reads them from files
+	kvdbCertDirPrefix = "px-kvdb-certs"
+	// labelPortworxKvdbMember is the node label set by portworx on the nodes
+	// running the internal kvdb
+	labelPortworxKvdbMember = "PX Kvdb Member"
+)
+
+// kvdbProbeResult is the health of an endpoint along with the client used to
+// check it, so the client can be reused for the next checks
+type kvdbProbeResult struct {
+	status corev1alpha1.KvdbEndpointStatus
+	client kvdb.Kvdb
+}
+
+// kvdbHealth is the state of the external kvdb health checks of a cluster.
+// The endpoints are checked in the background, so an unresponsive kvdb does
+// not block the reconcile loop, and the result is reported in the cluster
+// status on the next status update.
+type kvdbHealth struct {
+	lock sync.Mutex
+	// checking is set while the endpoints are being checked
+	checking bool
+	// probing are the endpoints whose probe has not returned yet, which may
+	// outlive the check that started it
+	probing map[string]bool
+	// result is the health of the endpoints from the last completed check,
+	// until it is reported in the cluster status
+	result    []corev1alpha1.KvdbEndpointStatus
+	checkTime metav1.Time
+	// clients are the kvdb clients of each endpoint, reused across checks.
+	// They are only used by the running check, if any.
+	clients     map[string]kvdb.Kvdb
+	certDir     string
+	authVersion string
+	authOptions map[string]string
+}
+
+// updateExternalKvdbStatus reports the health of the external kvdb endpoints
+// of the cluster and starts a new check in the background, at most once every
+// health check interval. A warning event is raised when the quorum of the kvdb
+// is at risk. The endpoints are checked with the credentials from the kvdb
+// auth secret.
+func (p *portworx) updateExternalKvdbStatus(cluster *corev1alpha1.StorageCluster) {
+	if cluster.Spec.Kvdb == nil || cluster.Spec.Kvdb.Internal || len(cluster.Spec.Kvdb.Endpoints) == 0 {
+		p.forgetKvdbHealth(cluster)
+		if cluster.Status.Kvdb != nil {
+			cluster.Status.Kvdb.Endpoints = nil
+			cluster.Status.Kvdb.LastCheckTime = nil
+		}
+		return
+	}
+
+	if p.kvdbHealth == nil {
+		p.kvdbHealth = make(map[string]*kvdbHealth)
+	}
+	health, exists := p.kvdbHealth[clusterKey(cluster)]
+	if !exists {
+		health = &kvdbHealth{}
+		p.kvdbHealth[clusterKey(cluster)] = health
+	}
+
+	health.lock.Lock()
+	defer health.lock.Unlock()
+
+	endpoints := cluster.Spec.Kvdb.Endpoints
+	if health.result != nil {
+		// Results for endpoints that have since changed are stale
+		if sameKvdbEndpoints(health.result, endpoints) {
+			p.setKvdbEndpointStatuses(cluster, health.result, health.checkTime)
+		}
+		health.result = nil
+	}
+
+	kvdbStatus := cluster.Status.Kvdb
+	if health.checking || (kvdbStatus != nil && kvdbStatus.LastCheckTime != nil &&
+		time.Since(kvdbStatus.LastCheckTime.Time) < kvdbHealthCheckInterval &&
+		sameKvdbEndpoints(kvdbStatus.Endpoints, endpoints)) {
+		return
+	}
+
+	opts, err := health.probeOptions(p.k8sClient, cluster)
+	if err != nil {
+		statuses := make([]corev1alpha1.KvdbEndpointStatus, 0, len(endpoints))
+		for _, endpoint := range endpoints {
+			statuses = append(statuses, corev1alpha1.KvdbEndpointStatus{
+				Endpoint: endpoint,
+				Message:  fmt.Sprintf("Failed to load kvdb credentials: %v", err),
+			})
+		}
+		p.setKvdbEndpointStatuses(cluster, statuses, metav1.Now())
+		return
+	}
+
+	if health.clients == nil {
+		health.clients = make(map[string]kvdb.Kvdb)
+	}
+	health.checking = true
+	go health.check(append([]string(nil), endpoints...), health.clients, opts)
+}
+
+// setKvdbEndpointStatuses sets the health of the external kvdb endpoints in
+// the cluster status, and raises an event if the unhealthy endpoints have
+// changed and put the quorum at risk
+func (p *portworx) setKvdbEndpointStatuses(
+	cluster *corev1alpha1.StorageCluster,
+	statuses []corev1alpha1.KvdbEndpointStatus,
+	checkTime metav1.Time,
+) {
+	var previous []corev1alpha1.KvdbEndpointStatus
+	if cluster.Status.Kvdb == nil {
+		cluster.Status.Kvdb = &corev1alpha1.KvdbStatus{}
+	} else {
+		previous = cluster.Status.Kvdb.Endpoints
+	}
+	cluster.Status.Kvdb.Endpoints = statuses
+	cluster.Status.Kvdb.LastCheckTime = &checkTime
+
+	unhealthy := unhealthyKvdbEndpoints(statuses)
+	if message := kvdbQuorumMessage(len(statuses), unhealthy); message != "" &&
+		strings.Join(unhealthy, ",") != strings.Join(unhealthyKvdbEndpoints(previous), ",") {
+		p.warningEvent(cluster, util.KvdbQuorumAtRiskReason, message)
+	}
+}
+
+// forgetKvdbHealth stops reporting the external kvdb health of the cluster
+// and removes the kvdb certificates written for it. A check that is still
+// running is left to finish, but its result is dropped.
+func (p *portworx) forgetKvdbHealth(cluster *corev1alpha1.StorageCluster) {
+	health, exists := p.kvdbHealth[clusterKey(cluster)]
+	if !exists {
+		return
+	}
+	delete(p.kvdbHealth, clusterKey(cluster))
+
+	health.lock.Lock()
+	defer health.lock.Unlock()
+	health.removeCertDir()
+}
+
+// check checks the health of the given endpoints and saves the result, so it
+// can be reported on the next status update
+func (h *kvdbHealth) check(
+	endpoints []string,
+	clients map[string]kvdb.Kvdb,
+	opts map[string]string,
+) {
+	statuses := h.probeEndpoints(endpoints, clients, opts)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.result = statuses
+	h.checkTime = metav1.Now()
+	h.checking = false
+}
+
+// probeEndpoints checks the health of the given endpoints in parallel.
+// Endpoints that do not respond in time are reported as unhealthy. The kvdb
+// library does not time out, so an endpoint is not probed again until its
+// previous probe returns, which would otherwise leak a goroutine and a client
+// on every check. The given clients are reused and updated with the clients
+// of the healthy endpoints.
+func (h *kvdbHealth) probeEndpoints(
+	endpoints []string,
+	clients map[string]kvdb.Kvdb,
+	opts map[string]string,
+) []corev1alpha1.KvdbEndpointStatus {
+	results := make([]chan kvdbProbeResult, len(endpoints))
+	h.lock.Lock()
+	if h.probing == nil {
+		h.probing = make(map[string]bool)
+	}
+	for i, endpoint := range endpoints {
+		if h.probing[endpoint] {
+			continue
+		}
+		h.probing[endpoint] = true
+		results[i] = make(chan kvdbProbeResult, 1)
+		go func(endpoint string, kv kvdb.Kvdb, result chan<- kvdbProbeResult) {
+			probeResult := probeKvdbEndpoint(endpoint, kv, opts)
+			h.lock.Lock()
+			delete(h.probing, endpoint)
+			h.lock.Unlock()
+			result <- probeResult
+		}(endpoint, clients[endpoint], results[i])
+	}
+	h.lock.Unlock()
+
+	timeout := time.After(kvdbProbeTimeout)
+	statuses := make([]corev1alpha1.KvdbEndpointStatus, len(endpoints))
+	for i, endpoint := range endpoints {
+		if results[i] == nil {
+			statuses[i] = corev1alpha1.KvdbEndpointStatus{
+				Endpoint: endpoint,
+				Message:  "Endpoint has not responded to a previous check",
+			}
+			delete(clients, endpoint)
+			continue
+		}
+		select {
+		case result := <-results[i]:
+			statuses[i] = result.status
+			if result.client != nil {
+				clients[endpoint] = result.client
+			} else {
+				delete(clients, endpoint)
+			}
+		case <-timeout:
+			statuses[i] = corev1alpha1.KvdbEndpointStatus{
+				Endpoint: endpoint,
+				Message:  fmt.Sprintf("Endpoint did not respond in %v", kvdbProbeTimeout),
+			}
+			delete(clients, endpoint)
+		}
+	}
+	return statuses
+}
+
+// probeKvdbEndpoint reads a key from the given endpoint to check its health
+// and measure its latency. A new client is created for the endpoint if the
+// given one is nil.
+func probeKvdbEndpoint(
+	endpoint string,
+	kv kvdb.Kvdb,
+	opts map[string]string,
+) kvdbProbeResult {
+	result := kvdbProbeResult{
+		status: corev1alpha1.KvdbEndpointStatus{
+			Endpoint: endpoint,
+		},
+	}
+
+	if kv == nil {
+		var err error
+		kv, err = getKVDBClient([]string{endpoint}, opts)
+		if err != nil {
+			result.status.Message = fmt.Sprintf("Failed to connect to endpoint: %v", err)
+			return result
+		}
+	}
+
+	start := time.Now()
+	if _, err := kv.Get(kvdbHealthKey); err != nil && err != kvdb.ErrNotFound {
+		result.status.Message = fmt.Sprintf("Failed to read from endpoint: %v", err)
+		return result
+	}
+	result.status.Latency = &metav1.Duration{Duration: time.Since(start)}
+	result.status.Healthy = true
+	result.client = kv
+
+	// Not all kvdbs report their members, in which case the leader is unknown
+	members, err := kv.ListMembers()
+	if err != nil {
+		logrus.Debugf("Failed to list members of kvdb endpoint %s: %v", endpoint, err)
+		return result
+	}
+	host := kvdbEndpointHost(endpoint)
+	for _, member := range members {
+		if member == nil || !member.Leader {
+			continue
+		}
+		for _, clientURL := range member.ClientUrls {
+			if kvdbEndpointHost(clientURL) == host {
+				result.status.Leader = true
+			}
+		}
+	}
+	return result
+}
+
+// probeOptions returns the kvdb client options with the credentials of the
+// cluster. The clients are reset when the credentials change, as they keep
+// using the credentials they were created with. It must not be called while
+// a check is running.
+func (h *kvdbHealth) probeOptions(
+	k8sClient client.Client,
+	cluster *corev1alpha1.StorageCluster,
+) (map[string]string, error) {
+	if cluster.Spec.Kvdb.AuthSecret == "" {
+		if h.authVersion != "" {
+			h.reset()
+		}
+		return nil, nil
+	}
+
+	secret, err := getKvdbAuthSecret(k8sClient, cluster)
+	if err != nil {
+		return nil, err
+	}
+	authVersion := fmt.Sprintf("%s/%s", secret.Name, util.SecretChecksum(secret))
+	if authVersion == h.authVersion {
+		return h.authOptions, nil
+	}
+
+	h.reset()
+	certDir, err := ioutil.TempDir("", kvdbCertDirPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory for kvdb certificates: %v", err)
+	}
+	opts, err := getKvdbAuthOptions(secret, certDir)
+	if err != nil {
+		os.RemoveAll(certDir)
+		return nil, err
+	}
+	h.certDir = certDir
+	h.authVersion = authVersion
+	h.authOptions = opts
+	return opts, nil
+}
+
+// reset forgets the kvdb clients and the credentials they use
+func (h *kvdbHealth) reset() {
+	h.removeCertDir()
+	h.clients = nil
+	h.authVersion = ""
+	h.authOptions = nil
+}
+
+func (h *kvdbHealth) removeCertDir() {
+	if h.certDir != "" {
+		if err := os.RemoveAll(h.certDir); err != nil {
+			logrus.Warnf("Failed to remove kvdb certificates: %v", err)
+		}
+	}
+	h.certDir = ""
+}
+
+// updateKvdbMembers lists the StorageNodes running the internal kvdb in the
+// cluster status. The given StorageNodes are keyed by the ID of the portworx node.
+func updateKvdbMembers(
+	cluster *corev1alpha1.StorageCluster,
+	nodes []*api.StorageNode,
+	storageNodes map[string]*corev1alpha1.StorageNode,
+) {
+	var members []string
+	if cluster.Spec.Kvdb == nil || cluster.Spec.Kvdb.Internal {
+		for _, node := range nodes {
+			if node == nil || node.NodeLabels[labelPortworxKvdbMember] != "true" {
+				continue
+			}
+			if storageNode, exists := storageNodes[node.Id]; exists {
+				members = append(members, storageNode.Name)
+			}
+		}
+		sort.Strings(members)
+	}
+
+	if cluster.Status.Kvdb == nil {
+		if len(members) == 0 {
+			return
+		}
+		cluster.Status.Kvdb = &corev1alpha1.KvdbStatus{}
+	}
+	cluster.Status.Kvdb.Members = members
+	if len(cluster.Status.Kvdb.Members) == 0 && len(cluster.Status.Kvdb.Endpoints) == 0 {
+		cluster.Status.Kvdb = nil
+	}
+}
+
+// getKvdbAuthSecret returns the secret with the kvdb credentials of the cluster
+func getKvdbAuthSecret(
+	k8sClient client.Client,
+	cluster *corev1alpha1.StorageCluster,
+) (*v1.Secret, error) {
+	secretName := cluster.Spec.Kvdb.AuthSecret
+	secret := &v1.Secret{}
+	err := k8sClient.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      secretName,
+			Namespace: cluster.Namespace,
+		},
+		secret,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kvdb auth secret %s: %v", secretName, err)
+	}
+	return secret, nil
+}
+
+// getKvdbAuthOptions returns the kvdb client options with the credentials from
+// the given kvdb auth secret, the same ones given to the storage pods. The
+// client only reads the certificates from files, so they are written to certDir.
+func getKvdbAuthOptions(secret *v1.Secret, certDir string) (map[string]string, error) {
+	opts := make(map[string]string)
+	if len(secret.Data[secretKeyKvdbCert]) > 0 {
+		for key, opt := range map[string]string{
+			secretKeyKvdbCert:    kvdb.CertFileKey,
+			secretKeyKvdbCA:      kvdb.CAFileKey,
+			secretKeyKvdbCertKey: kvdb.CertKeyFileKey,
+		} {
+			if len(secret.Data[key]) == 0 {
+				continue
+			}
+			certFile := path.Join(certDir, key)
+			if err := ioutil.WriteFile(certFile, secret.Data[key], 0600); err != nil {
+				return nil, fmt.Errorf("failed to write kvdb certificate %s: %v", key, err)
+			}
+			opts[opt] = certFile
+		}
+	} else if len(secret.Data[secretKeyKvdbACLToken]) > 0 {
+		opts[kvdb.ACLTokenKey] = string(secret.Data[secretKeyKvdbACLToken])
+	} else if len(secret.Data[secretKeyKvdbUsername]) > 0 && len(secret.Data[secretKeyKvdbPassword]) > 0 {
+		opts[kvdb.UsernameKey] = string(secret.Data[secretKeyKvdbUsername])
+		opts[kvdb.PasswordKey] = string(secret.Data[secretKeyKvdbPassword])
+	}
+	return opts, nil
+}
+
+// kvdbQuorumMessage returns a message if the given unhealthy endpoints leave
+// the kvdb without quorum, or one failure away from losing it
+func kvdbQuorumMessage(total int, unhealthy []string) string {
+	if len(unhealthy) == 0 {
+		return ""
+	}
+	healthy := total - len(unhealthy)
+	quorum := total/2 + 1
+	if healthy < quorum {
+		return fmt.Sprintf("Kvdb has lost quorum: %d of %d endpoints are healthy. Unhealthy endpoints: %s",
+			healthy, total, strings.Join(unhealthy, ", "))
+	} else if healthy == quorum {
+		return fmt.Sprintf("Kvdb quorum is at risk: %d of %d endpoints are healthy. Unhealthy endpoints: %s",
+			healthy, total, strings.Join(unhealthy, ", "))
+	}
+	return ""
+}
+
+func unhealthyKvdbEndpoints(statuses []corev1alpha1.KvdbEndpointStatus) []string {
+	var unhealthy []string
+	for _, status := range statuses {
+		if !status.Healthy {
+			unhealthy = append(unhealthy, status.Endpoint)
+		}
+	}
+	return unhealthy
+}
+
+func sameKvdbEndpoints(statuses []corev1alpha1.KvdbEndpointStatus, endpoints []string) bool {
+	if len(statuses) != len(endpoints) {
+		return false
+	}
+	for i, status := range statuses {
+		if status.Endpoint != endpoints[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// kvdbEndpointHost returns the host and port of a kvdb endpoint or URL, like
+// kvdb.com:2379 for etcd:https://kvdb.com:2379
+func kvdbEndpointHost(endpoint string) string {
+	if index := strings.LastIndex(endpoint, "//"); index >= 0 {
+		endpoint = endpoint[index+2:]
+	}
+	return strings.TrimSuffix(endpoint, "/")
+}
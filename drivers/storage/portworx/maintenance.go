@@ -0,0 +1,152 @@
+package portworx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/libopenstorage/openstorage/api"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/libopenstorage/operator/pkg/util"
+	coreops "github.com/portworx/sched-ops/k8s/core"
+	operatorops "github.com/portworx/sched-ops/k8s/operator"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	pxctlPath = "/opt/pwx/bin/pxctl"
+)
+
+var (
+	// The SDK does not expose maintenance mode, so pxctl is run inside the
+	// portworx pod on the node to enter or exit maintenance mode.
+	runCommandInPod = func(cmds []string, podName, containerName, namespace string) (string, error) {
+		return coreops.Instance().RunCommandInPod(cmds, podName, containerName, namespace)
+	}
+)
+
+// updateNodeMaintenance takes the node in or out of maintenance mode based on
+// the spec of the StorageNode. The progress is recorded in the maintenance
+// condition of the given StorageNode status, which is saved by the caller.
+// Nodes put in maintenance mode outside of the operator are left untouched.
+func (p *portworx) updateNodeMaintenance(
+	cluster *corev1alpha1.StorageCluster,
+	storageNode *corev1alpha1.StorageNode,
+	node *api.StorageNode,
+) {
+	inMaintenance := node.Status == api.Status_STATUS_MAINTENANCE
+	var currentStatus corev1alpha1.NodeConditionStatus
+	if condition := getNodeCondition(&storageNode.Status, corev1alpha1.NodeMaintenanceCondition); condition != nil {
+		currentStatus = condition.Status
+	}
+
+	condition := &corev1alpha1.NodeCondition{
+		Type: corev1alpha1.NodeMaintenanceCondition,
+	}
+	switch {
+	case storageNode.Spec.Maintenance && inMaintenance:
+		condition.Status = corev1alpha1.NodeMaintenanceStatus
+		condition.Message = "Node is in maintenance mode"
+	case storageNode.Spec.Maintenance:
+		if currentStatus == corev1alpha1.NodeEnteringMaintenanceStatus {
+			// Wait for the node to report that it is in maintenance mode
+			return
+		}
+		condition.Status = corev1alpha1.NodeEnteringMaintenanceStatus
+		condition.Message = "Node is entering maintenance mode"
+		if err := p.runMaintenanceCommand(cluster, node.SchedulerNodeName, "--enter"); err != nil {
+			condition.Status = corev1alpha1.NodeFailedStatus
+			condition.Message = fmt.Sprintf("Failed to enter maintenance mode: %v", err)
+		}
+	case inMaintenance:
+		if currentStatus != corev1alpha1.NodeEnteringMaintenanceStatus &&
+			currentStatus != corev1alpha1.NodeMaintenanceStatus &&
+			currentStatus != corev1alpha1.NodeFailedStatus {
+			// Either the node is already exiting maintenance mode, or it was
+			// not put in maintenance mode by the operator
+			return
+		}
+		condition.Status = corev1alpha1.NodeExitingMaintenanceStatus
+		condition.Message = "Node is exiting maintenance mode"
+		if err := p.runMaintenanceCommand(cluster, node.SchedulerNodeName, "--exit"); err != nil {
+			condition.Status = corev1alpha1.NodeFailedStatus
+			condition.Message = fmt.Sprintf("Failed to exit maintenance mode: %v", err)
+		}
+	default:
+		if currentStatus == "" || currentStatus == corev1alpha1.NodeOnlineStatus {
+			return
+		}
+		condition.Status = corev1alpha1.NodeOnlineStatus
+		condition.Message = "Node is out of maintenance mode"
+	}
+
+	changed := operatorops.Instance().UpdateStorageNodeCondition(&storageNode.Status, condition)
+	if changed && condition.Status == corev1alpha1.NodeFailedStatus {
+		p.warningEvent(cluster, util.FailedMaintenanceReason,
+			fmt.Sprintf("Node %s: %s", storageNode.Name, condition.Message))
+	}
+}
+
+// runMaintenanceCommand runs pxctl in the portworx pod of the given node
+// to enter or exit maintenance mode
+func (p *portworx) runMaintenanceCommand(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+	action string,
+) error {
+	pod, err := p.getPortworxPodOnNode(cluster, nodeName)
+	if err != nil {
+		return err
+	}
+
+	cmds := []string{pxctlPath, "service", "maintenance", action, "-y"}
+	if _, err := runCommandInPod(cmds, pod.Name, pxContainerName, pod.Namespace); err != nil {
+		return fmt.Errorf("failed to run '%s' in pod %s: %v", strings.Join(cmds, " "), pod.Name, err)
+	}
+	return nil
+}
+
+func (p *portworx) getPortworxPodOnNode(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+) (*v1.Pod, error) {
+	pod, err := p.getPortworxPod(cluster, func(pod *v1.Pod) bool {
+		return pod.Spec.NodeName == nodeName
+	})
+	if err == nil && pod == nil {
+		err = fmt.Errorf("portworx pod not found on node %s", nodeName)
+	}
+	return pod, err
+}
+
+// getPortworxPod returns the first portworx pod of the cluster that is not being
+// deleted and for which match returns true. It returns nil if there is none.
+func (p *portworx) getPortworxPod(
+	cluster *corev1alpha1.StorageCluster,
+	match func(*v1.Pod) bool,
+) (*v1.Pod, error) {
+	podList := &v1.PodList{}
+	err := p.k8sClient.List(
+		context.TODO(),
+		podList,
+		&client.ListOptions{
+			Namespace:     cluster.Namespace,
+			LabelSelector: labels.SelectorFromSet(p.GetSelectorLabels()),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list portworx pods: %v", err)
+	}
+
+	for _, pod := range podList.Items {
+		owner := metav1.GetControllerOf(&pod)
+		if owner != nil && owner.UID == cluster.UID &&
+			pod.DeletionTimestamp == nil && match(&pod) {
+			return pod.DeepCopy(), nil
+		}
+	}
+	return nil, nil
+}
@@ -39,6 +39,8 @@ var (
 	ErrReleaseNotFound = errors.New("release not found")
 	// ErrInvalidDefaultRelease when the default release is invalid
 	ErrInvalidDefaultRelease = errors.New("invalid default release")
+	// ErrIncompatibleRollback when a release cannot be rolled back to the given version
+	ErrIncompatibleRollback = errors.New("incompatible rollback")
 )
 
 // Methods to override for testing
@@ -59,6 +61,9 @@ type Release struct {
 	Stork      string `yaml:"stork,omitempty"`
 	Lighthouse string `yaml:"lighthouse,omitempty"`
 	Autopilot  string `yaml:"autopilot,omitempty"`
+	// MinRollbackVersion is the oldest Portworx version that a cluster running
+	// this release can be rolled back to
+	MinRollbackVersion string `yaml:"minRollbackVersion,omitempty"`
 }
 
 // NewReleaseManifest returns a release manifest object from the portworx releases file
@@ -116,6 +121,34 @@ func (m *ReleaseManifest) GetDefault() (*Release, error) {
 	return m.Get(m.DefaultRelease)
 }
 
+// ValidateRollback returns an error if the release manifest does not allow
+// rolling back Portworx from the current version to the target version. Releases
+// that are not present in the manifest are not restricted.
+func (m *ReleaseManifest) ValidateRollback(current, target *version.Version) error {
+	if current == nil || target == nil || !target.LessThan(current) {
+		return nil
+	}
+	release, err := m.GetFromVersion(current)
+	if err == ErrReleaseNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if len(release.MinRollbackVersion) == 0 {
+		return nil
+	}
+	minVersion, err := version.NewSemver(release.MinRollbackVersion)
+	if err != nil {
+		return fmt.Errorf("invalid minimum rollback version %s for release %s: %v",
+			release.MinRollbackVersion, current, err)
+	}
+	if target.LessThan(minVersion) {
+		return fmt.Errorf("%v: release %s can only be rolled back to %s or later",
+			ErrIncompatibleRollback, current, minVersion)
+	}
+	return nil
+}
+
 func (m *ReleaseManifest) getLatest() string {
 	versions := make([]string, 0)
 	for version := range m.Releases {
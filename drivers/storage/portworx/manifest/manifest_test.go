@@ -225,6 +225,57 @@ releases:
 	require.Nil(t, release)
 }
 
+func TestValidateRollbackOnReleaseManifest(t *testing.T) {
+	defer unmaskLoadManifest()
+	maskLoadManifest(`
+releases:
+  2.1.0:
+    stork: stork/image:2.1.0
+  2.2.0:
+    stork: stork/image:2.2.0
+    minRollbackVersion: 2.1.0
+  2.3.0:
+    stork: stork/image:2.3.0
+    minRollbackVersion: invalid
+`)
+
+	r, err := NewReleaseManifest()
+	require.NoError(t, err)
+
+	v200, _ := version.NewSemver("2.0.0")
+	v210, _ := version.NewSemver("2.1.0")
+	v220, _ := version.NewSemver("2.2.0")
+	v230, _ := version.NewSemver("2.3.0")
+	v240, _ := version.NewSemver("2.4.0")
+
+	// Should allow if the versions are unknown
+	require.NoError(t, r.ValidateRollback(nil, v200))
+	require.NoError(t, r.ValidateRollback(v220, nil))
+
+	// Should allow if the target version is not older
+	require.NoError(t, r.ValidateRollback(v220, v220))
+	require.NoError(t, r.ValidateRollback(v220, v240))
+
+	// Should allow if the current release is not in the manifest
+	require.NoError(t, r.ValidateRollback(v240, v200))
+
+	// Should allow if the current release has no minimum rollback version
+	require.NoError(t, r.ValidateRollback(v210, v200))
+
+	// Should allow if the target version is not older than the minimum
+	require.NoError(t, r.ValidateRollback(v220, v210))
+
+	// Should return err if the target version is older than the minimum
+	err = r.ValidateRollback(v220, v200)
+	require.EqualError(t, err, "incompatible rollback: "+
+		"release 2.2.0 can only be rolled back to 2.1.0 or later")
+
+	// Should return err if the minimum rollback version is invalid
+	err = r.ValidateRollback(v230, v220)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid minimum rollback version invalid for release 2.3.0")
+}
+
 func TestReadingLocalManifestFile(t *testing.T) {
 	linkPath := path.Join(
 		os.Getenv("GOPATH"),
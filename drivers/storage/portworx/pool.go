@@ -0,0 +1,366 @@
+package portworx
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libopenstorage/openstorage/api"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/libopenstorage/operator/pkg/util"
+	operatorops "github.com/portworx/sched-ops/k8s/operator"
+)
+
+const (
+	bytesPerGiB = 1024 * 1024 * 1024
+	// poolExpansionRetryInterval is how long to wait for the storage pools to
+	// reach their requested size before requesting the expansion again
+	poolExpansionRetryInterval = 15 * time.Minute
+	// poolExpansionTimeout is how long to wait for the storage pools to reach
+	// their requested size before the expansion is considered failed
+	poolExpansionTimeout = 2 * time.Hour
+)
+
+// poolTarget is the requested size of a storage pool on a node
+type poolTarget struct {
+	sizeInGiB uint64
+	operation corev1alpha1.StoragePoolOperation
+	// fromNode is true if the size was requested in the StorageNode spec
+	fromNode bool
+}
+
+// poolExpansion is an expansion of the storage pools of a node requested by
+// the operator. It is kept after the expansion completes, as pxctl may expand
+// a pool slightly above its requested size.
+type poolExpansion struct {
+	// sizes are the requested sizes of the pools in GiB
+	sizes map[int32]uint64
+	// startTime is when the expansion was first requested
+	startTime time.Time
+	// requestTime is when the expansion was last requested
+	requestTime time.Time
+	// timedOut is set if the pools did not reach their requested size in time
+	timedOut bool
+}
+
+// pxctlPoolList is the output of 'pxctl service pool show -j'
+type pxctlPoolList struct {
+	DataPools []struct {
+		ID   int32  `json:"poolID"`
+		UUID string `json:"uuid"`
+	} `json:"datapools"`
+}
+
+// updateNodePools expands the storage pools on the node to the sizes requested
+// in the StorageNode spec or in the capacity specs of the cluster. The progress
+// is recorded in the pool expansion condition of the given StorageNode status,
+// which is saved by the caller. The SDK does not expose pool expansion, so pxctl
+// is run in the portworx pod on the node. The pool sizes are checked on every
+// status update, and an expansion is requested again if the pools have not
+// reached their size after the retry interval, until it times out.
+func (p *portworx) updateNodePools(
+	cluster *corev1alpha1.StorageCluster,
+	storageNode *corev1alpha1.StorageNode,
+	node *api.StorageNode,
+) {
+	if getNodeCondition(&storageNode.Status, corev1alpha1.NodeDecommissionCondition) != nil {
+		return
+	}
+
+	targets := getPoolTargets(cluster, storageNode)
+	condition := getNodeCondition(&storageNode.Status, corev1alpha1.NodePoolExpansionCondition)
+	if len(targets) == 0 {
+		p.forgetPoolExpansion(cluster, storageNode)
+		if condition == nil {
+			return
+		}
+	}
+	expansion := p.getPoolExpansion(cluster, storageNode, condition, targets)
+
+	pools := make(map[int32]*api.StoragePool)
+	for _, pool := range node.Pools {
+		if pool != nil {
+			pools[pool.ID] = pool
+		}
+	}
+
+	poolIDs := make([]int, 0, len(targets))
+	for poolID := range targets {
+		poolIDs = append(poolIDs, int(poolID))
+	}
+	sort.Ints(poolIDs)
+
+	var rejected, expansions []string
+	poolsToExpand := make(map[int32]poolTarget)
+	for _, id := range poolIDs {
+		poolID := int32(id)
+		target := targets[poolID]
+		pool, exists := pools[poolID]
+		if !exists {
+			// Pools requested cluster-wide may not exist on all the nodes
+			if target.fromNode {
+				rejected = append(rejected, fmt.Sprintf("pool %d does not exist on the node", poolID))
+			}
+			continue
+		}
+
+		currentSize := bytesToGiB(pool.TotalSize)
+		if target.operation != corev1alpha1.StoragePoolResizeDiskOperation &&
+			target.operation != corev1alpha1.StoragePoolAddDiskOperation {
+			rejected = append(rejected, fmt.Sprintf("pool %d cannot be expanded with unsupported operation %s",
+				poolID, target.operation))
+		} else if currentSize >= target.sizeInGiB && expansion.requested(poolID, target.sizeInGiB) {
+			// The pool may have been expanded above the requested size
+			continue
+		} else if target.sizeInGiB < currentSize {
+			rejected = append(rejected, fmt.Sprintf("pool %d cannot be shrunk from %d GiB to %d GiB",
+				poolID, currentSize, target.sizeInGiB))
+		} else if target.sizeInGiB == currentSize {
+			continue
+		} else if maxCapacity := getPoolMaxCapacity(cluster, poolID); maxCapacity > 0 &&
+			target.sizeInGiB > maxCapacity {
+			rejected = append(rejected, fmt.Sprintf("pool %d cannot be expanded to %d GiB as it "+
+				"would exceed the maximum capacity of %d GiB", poolID, target.sizeInGiB, maxCapacity))
+		} else {
+			poolsToExpand[poolID] = target
+			expansions = append(expansions, fmt.Sprintf("pool %d to %d GiB", poolID, target.sizeInGiB))
+		}
+	}
+
+	newCondition := &corev1alpha1.NodeCondition{
+		Type: corev1alpha1.NodePoolExpansionCondition,
+	}
+	switch {
+	case len(rejected) > 0:
+		p.forgetPoolExpansion(cluster, storageNode)
+		newCondition.Status = corev1alpha1.NodeFailedStatus
+		newCondition.Reason = util.FailedPoolExpansionReason
+		newCondition.Message = fmt.Sprintf("Cannot expand storage pools: %s", strings.Join(rejected, "; "))
+	case len(poolsToExpand) == 0:
+		if condition == nil {
+			return
+		}
+		newCondition.Status = corev1alpha1.NodeSucceededStatus
+		newCondition.Message = "Storage pools are at their requested size"
+	case expansion.requestedAll(poolsToExpand) && expansion.timedOut:
+		newCondition.Status = corev1alpha1.NodeFailedStatus
+		newCondition.Reason = util.FailedPoolExpansionReason
+		newCondition.Message = fmt.Sprintf("Timed out expanding storage %s", strings.Join(expansions, ", "))
+	default:
+		newCondition.Status = corev1alpha1.NodeExpandingStatus
+		newCondition.Message = fmt.Sprintf("Expanding storage %s", strings.Join(expansions, ", "))
+		if node.Status != api.Status_STATUS_OK {
+			// Wait for the node to be online before expanding the pools
+			return
+		}
+
+		now := time.Now()
+		if expansion.requestedAll(poolsToExpand) {
+			if now.Sub(expansion.startTime) >= poolExpansionTimeout {
+				expansion.timedOut = true
+				newCondition.Status = corev1alpha1.NodeFailedStatus
+				newCondition.Reason = util.FailedPoolExpansionReason
+				newCondition.Message = fmt.Sprintf("Timed out expanding storage %s", strings.Join(expansions, ", "))
+				break
+			} else if condition != nil && condition.Status == newCondition.Status &&
+				now.Sub(expansion.requestTime) < poolExpansionRetryInterval {
+				// Wait for the pools to be expanded before requesting it again
+				return
+			}
+		} else {
+			expansion = p.newPoolExpansion(cluster, storageNode, targets, now)
+		}
+
+		if err := p.expandPools(cluster, node.SchedulerNodeName, poolsToExpand); err != nil {
+			newCondition.Status = corev1alpha1.NodeFailedStatus
+			newCondition.Reason = util.FailedPoolExpansionReason
+			newCondition.Message = fmt.Sprintf("Failed to expand storage pools: %v", err)
+		}
+		expansion.requestTime = now
+	}
+
+	changed := operatorops.Instance().UpdateStorageNodeCondition(&storageNode.Status, newCondition)
+	if changed && newCondition.Reason != "" {
+		p.warningEvent(cluster, newCondition.Reason,
+			fmt.Sprintf("Node %s: %s", storageNode.Name, newCondition.Message))
+	}
+}
+
+// getPoolExpansion returns the pool expansion requested on the node, if any.
+// After a restart of the operator, the expansion is recovered from the pool
+// expansion condition, assuming the current sizes were requested when the
+// condition last transitioned.
+func (p *portworx) getPoolExpansion(
+	cluster *corev1alpha1.StorageCluster,
+	storageNode *corev1alpha1.StorageNode,
+	condition *corev1alpha1.NodeCondition,
+	targets map[int32]poolTarget,
+) *poolExpansion {
+	if expansion, exists := p.poolExpansions[clusterKey(cluster)][storageNode.Name]; exists {
+		return expansion
+	}
+	if condition == nil || (condition.Status != corev1alpha1.NodeExpandingStatus &&
+		condition.Status != corev1alpha1.NodeSucceededStatus) {
+		return nil
+	}
+	return p.newPoolExpansion(cluster, storageNode, targets, condition.LastTransitionTime.Time)
+}
+
+// newPoolExpansion records a new expansion of the pools on the node to the
+// given sizes, replacing any previous one
+func (p *portworx) newPoolExpansion(
+	cluster *corev1alpha1.StorageCluster,
+	storageNode *corev1alpha1.StorageNode,
+	targets map[int32]poolTarget,
+	startTime time.Time,
+) *poolExpansion {
+	expansion := &poolExpansion{
+		sizes:       make(map[int32]uint64),
+		startTime:   startTime,
+		requestTime: startTime,
+	}
+	for poolID, target := range targets {
+		expansion.sizes[poolID] = target.sizeInGiB
+	}
+
+	if p.poolExpansions == nil {
+		p.poolExpansions = make(map[string]map[string]*poolExpansion)
+	}
+	if p.poolExpansions[clusterKey(cluster)] == nil {
+		p.poolExpansions[clusterKey(cluster)] = make(map[string]*poolExpansion)
+	}
+	p.poolExpansions[clusterKey(cluster)][storageNode.Name] = expansion
+	return expansion
+}
+
+func (p *portworx) forgetPoolExpansion(
+	cluster *corev1alpha1.StorageCluster,
+	storageNode *corev1alpha1.StorageNode,
+) {
+	delete(p.poolExpansions[clusterKey(cluster)], storageNode.Name)
+}
+
+// requested returns true if the pool was requested to be expanded to the given size
+func (e *poolExpansion) requested(poolID int32, sizeInGiB uint64) bool {
+	return e != nil && e.sizes[poolID] == sizeInGiB
+}
+
+// requestedAll returns true if all the given pools were requested to be
+// expanded to their target size
+func (e *poolExpansion) requestedAll(targets map[int32]poolTarget) bool {
+	if e == nil {
+		return false
+	}
+	for poolID, target := range targets {
+		if !e.requested(poolID, target.sizeInGiB) {
+			return false
+		}
+	}
+	return true
+}
+
+// expandPools runs pxctl in the portworx pod of the given node to expand the
+// storage pools to their requested size. Pools are identified by their UUID
+// in pxctl, which is not exposed in the SDK.
+func (p *portworx) expandPools(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+	poolsToExpand map[int32]poolTarget,
+) error {
+	pod, err := p.getPortworxPodOnNode(cluster, nodeName)
+	if err != nil {
+		return err
+	}
+
+	cmds := []string{pxctlPath, "service", "pool", "show", "-j"}
+	output, err := runCommandInPod(cmds, pod.Name, pxContainerName, pod.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to run '%s' in pod %s: %v", strings.Join(cmds, " "), pod.Name, err)
+	}
+	poolList := &pxctlPoolList{}
+	if err := json.Unmarshal([]byte(output), poolList); err != nil {
+		return fmt.Errorf("failed to parse storage pools of the node: %v", err)
+	}
+	poolUUIDs := make(map[int32]string)
+	for _, pool := range poolList.DataPools {
+		poolUUIDs[pool.ID] = pool.UUID
+	}
+
+	poolIDs := make([]int, 0, len(poolsToExpand))
+	for poolID := range poolsToExpand {
+		poolIDs = append(poolIDs, int(poolID))
+	}
+	sort.Ints(poolIDs)
+
+	for _, id := range poolIDs {
+		poolID := int32(id)
+		target := poolsToExpand[poolID]
+		uuid, exists := poolUUIDs[poolID]
+		if !exists {
+			return fmt.Errorf("UUID of pool %d not found", poolID)
+		}
+		cmds := []string{pxctlPath, "service", "pool", "expand",
+			"--uid", uuid,
+			"--size", strconv.FormatUint(target.sizeInGiB, 10),
+			"--operation", string(target.operation),
+		}
+		if _, err := runCommandInPod(cmds, pod.Name, pxContainerName, pod.Namespace); err != nil {
+			return fmt.Errorf("failed to run '%s' in pod %s: %v", strings.Join(cmds, " "), pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// getPoolMaxCapacity returns the maximum size of the storage pool with the given
+// ID on a node, or 0 if the size is not limited. The pools are created from the
+// capacity spec at the same index, so the limit is the maximum capacity of that
+// spec, which is validated against the requested pool size on admission.
+func getPoolMaxCapacity(cluster *corev1alpha1.StorageCluster, poolID int32) uint64 {
+	if cluster.Spec.CloudStorage == nil || poolID < 0 ||
+		int(poolID) >= len(cluster.Spec.CloudStorage.CapacitySpecs) {
+		return 0
+	}
+	return cluster.Spec.CloudStorage.CapacitySpecs[poolID].MaxCapacityInGiB
+}
+
+// getPoolTargets returns the requested sizes of the storage pools on the node.
+// The sizes in the StorageNode spec override the ones in the capacity specs.
+func getPoolTargets(
+	cluster *corev1alpha1.StorageCluster,
+	storageNode *corev1alpha1.StorageNode,
+) map[int32]poolTarget {
+	targets := make(map[int32]poolTarget)
+	if cluster.Spec.CloudStorage != nil {
+		for i, capacitySpec := range cluster.Spec.CloudStorage.CapacitySpecs {
+			if capacitySpec.PoolSizeInGiB > 0 {
+				targets[int32(i)] = poolTarget{
+					sizeInGiB: capacitySpec.PoolSizeInGiB,
+					operation: corev1alpha1.StoragePoolResizeDiskOperation,
+				}
+			}
+		}
+	}
+	for _, poolSpec := range storageNode.Spec.CloudStorage.Pools {
+		if poolSpec.SizeInGiB == 0 {
+			continue
+		}
+		operation := poolSpec.Operation
+		if operation == "" {
+			operation = corev1alpha1.StoragePoolResizeDiskOperation
+		}
+		targets[poolSpec.ID] = poolTarget{
+			sizeInGiB: poolSpec.SizeInGiB,
+			operation: operation,
+			fromNode:  true,
+		}
+	}
+	return targets
+}
+
+// bytesToGiB converts the given bytes to GiB, rounded to the nearest GiB
+func bytesToGiB(bytes uint64) uint64 {
+	return (bytes + bytesPerGiB/2) / bytesPerGiB
+}
@@ -46,9 +46,21 @@ type portworx struct {
 	k8sVersion         *version.Version
 	scheme             *runtime.Scheme
 	recorder           record.EventRecorder
-	sdkConn            *grpc.ClientConn
+	sdkConns           map[string]*sdkConnection
 	zoneToInstancesMap map[string]int
 	cloudProvider      string
+	alertCursors       map[string]*alertCursor
+	poolExpansions     map[string]map[string]*poolExpansion
+	kvdbHealth         map[string]*kvdbHealth
+}
+
+// sdkConnection is the grpc connection to the SDK server of a storage
+// cluster, along with the endpoint and credentials it was created with
+type sdkConnection struct {
+	conn        *grpc.ClientConn
+	endpoint    string
+	credentials *tokenCredentials
+	rootCA      []byte
 }
 
 func (p *portworx) String() string {
@@ -145,21 +157,29 @@ func (p *portworx) SetDefaultsOnStorageCluster(toUpdate *corev1alpha1.StorageClu
 
 func (p *portworx) PreInstall(cluster *corev1alpha1.StorageCluster) error {
 	for componentName, comp := range component.GetAll() {
+		condition := corev1alpha1.ComponentCondition{
+			Name:   componentName,
+			Status: corev1alpha1.ComponentReady,
+		}
 		if comp.IsEnabled(cluster) {
 			err := comp.Reconcile(cluster)
 			if ce, ok := err.(*component.Error); ok &&
 				ce.Code() == component.ErrCritical {
 				return err
 			} else if err != nil {
-				msg := fmt.Sprintf("Failed to setup %s. %v", componentName, err)
-				p.warningEvent(cluster, util.FailedComponentReason, msg)
+				condition.Status = corev1alpha1.ComponentFailed
+				condition.Reason = fmt.Sprintf("Failed to setup %s. %v", componentName, err)
+				logrus.Warn(condition.Reason)
 			}
 		} else {
+			condition.Status = corev1alpha1.ComponentDisabled
 			if err := comp.Delete(cluster); err != nil {
-				msg := fmt.Sprintf("Failed to cleanup %v. %v", componentName, err)
-				p.warningEvent(cluster, util.FailedComponentReason, msg)
+				condition.Status = corev1alpha1.ComponentFailed
+				condition.Reason = fmt.Sprintf("Failed to cleanup %v. %v", componentName, err)
+				logrus.Warn(condition.Reason)
 			}
 		}
+		util.SetComponentCondition(cluster, condition)
 	}
 	return nil
 }
@@ -168,6 +188,10 @@ func (p *portworx) DeleteStorage(
 	cluster *corev1alpha1.StorageCluster,
 ) (*corev1alpha1.ClusterCondition, error) {
 	p.markComponentsAsDeleted()
+	p.closePortworxClient(cluster)
+	delete(p.alertCursors, clusterKey(cluster))
+	delete(p.poolExpansions, clusterKey(cluster))
+	p.forgetKvdbHealth(cluster)
 
 	if cluster.Spec.DeleteStrategy == nil || !pxutil.IsPortworxEnabled(cluster) {
 		// No Delete strategy provided or Portworx not installed through the operator,
@@ -243,6 +267,12 @@ func (p *portworx) markComponentsAsDeleted() {
 	}
 }
 
+// clusterKey returns the namespace/name key used to keep the state of the
+// storage clusters managed by the driver apart
+func clusterKey(cluster *corev1alpha1.StorageCluster) string {
+	return cluster.Namespace + "/" + cluster.Name
+}
+
 func (p *portworx) warningEvent(
 	cluster *corev1alpha1.StorageCluster,
 	reason, message string,
@@ -3,15 +3,20 @@ package portworx
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/libopenstorage/openstorage/api"
+	"github.com/libopenstorage/openstorage/pkg/auth"
 	"github.com/libopenstorage/openstorage/pkg/dbg"
 	"github.com/libopenstorage/operator/drivers/storage/portworx/component"
 	"github.com/libopenstorage/operator/drivers/storage/portworx/manifest"
@@ -19,6 +24,7 @@ import (
 	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
 	"github.com/libopenstorage/operator/pkg/controller/storagecluster"
 	"github.com/libopenstorage/operator/pkg/mock"
+	"github.com/libopenstorage/operator/pkg/util"
 	testutil "github.com/libopenstorage/operator/pkg/util/test"
 	"github.com/portworx/kvdb"
 	"github.com/portworx/kvdb/consul"
@@ -28,9 +34,11 @@ import (
 	coreops "github.com/portworx/sched-ops/k8s/core"
 	operatorops "github.com/portworx/sched-ops/k8s/operator"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -1631,98 +1639,58 @@ func TestUpdateClusterStatusForNodeVersions(t *testing.T) {
 	err = testutil.Get(k8sClient, nodeStatus, "node-two", cluster.Namespace)
 	require.NoError(t, err)
 	require.Empty(t, nodeStatus.Spec.Version)
-}
-
-func TestUpdateClusterStatusWithoutPortworxService(t *testing.T) {
-	// Fake client without service
-	k8sClient := testutil.FakeK8sClient()
-
-	driver := portworx{
-		k8sClient: k8sClient,
-	}
-
-	cluster := &corev1alpha1.StorageCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "px-cluster",
-			Namespace: "kube-test",
-		},
-		Status: corev1alpha1.StorageClusterStatus{
-			Phase: "Initializing",
-		},
-	}
-
-	// TestCase: No storage nodes and portworx pods exist
-	err := driver.UpdateStorageClusterStatus(cluster)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "not found")
 
-	storageNodes := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
-	require.NoError(t, err)
-	require.Empty(t, storageNodes.Items)
+	// The cluster version should be the version reported by the nodes
+	require.Equal(t, "5.6.7.8", cluster.Status.Version)
 
-	// TestCase: Portworx pods exist, but no storage nodes present
-	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
-	pod1 := &v1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            "portworx-pod-1",
-			Namespace:       cluster.Namespace,
-			Labels:          driver.GetSelectorLabels(),
-			OwnerReferences: []metav1.OwnerReference{*ownerRef},
-		},
-		Spec: v1.PodSpec{
-			NodeName: "node-1",
-		},
+	// If the nodes run different versions, the cluster version should be
+	// the oldest version
+	expectedNodeTwo.NodeLabels = map[string]string{
+		"PX Version": "5.6.2.1",
 	}
-	k8sClient.Create(context.TODO(), pod1)
 
 	err = driver.UpdateStorageClusterStatus(cluster)
-	require.Contains(t, err.Error(), "not found")
-
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
 	require.NoError(t, err)
-	require.Empty(t, storageNodes.Items)
+	require.Equal(t, "5.6.2.1", cluster.Status.Version)
+}
 
-	// TestCase: Portworx pods and storage nodes both exist
-	storageNode1 := &corev1alpha1.StorageNode{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "node-1",
-			Namespace: cluster.Namespace,
-		},
-	}
-	storageNode2 := &corev1alpha1.StorageNode{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "node-2",
-			Namespace: cluster.Namespace,
-		},
-	}
-	k8sClient.Create(context.TODO(), storageNode1)
-	k8sClient.Create(context.TODO(), storageNode2)
+func TestUpdateClusterStatusWithStoragePools(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
 
-	err = driver.UpdateStorageClusterStatus(cluster)
-	require.Contains(t, err.Error(), "not found")
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
 
-	// Delete extra nodes that do not have corresponding pods
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
-	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
-}
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
 
-func TestUpdateClusterStatusServiceWithoutClusterIP(t *testing.T) {
-	// Fake client with a service that does not have cluster ip
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
 	k8sClient := testutil.FakeK8sClient(&v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      pxutil.PortworxServiceName,
 			Namespace: "kube-test",
 		},
 		Spec: v1.ServiceSpec{
-			ClusterIP: "",
+			ClusterIP: sdkServerIP,
+			Ports: []v1.ServicePort{
+				{
+					Name: pxutil.PortworxSDKPortName,
+					Port: int32(sdkServerPort),
+				},
+			},
 		},
 	})
 
+	// Create driver object with the fake k8s client
 	driver := portworx{
 		k8sClient: k8sClient,
 	}
@@ -1732,143 +1700,210 @@ func TestUpdateClusterStatusServiceWithoutClusterIP(t *testing.T) {
 			Name:      "px-cluster",
 			Namespace: "kube-test",
 		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "test/image:1.2.3.4",
+		},
 		Status: corev1alpha1.StorageClusterStatus{
 			Phase: "Initializing",
 		},
 	}
 
-	// TestCase: No storage nodes and portworx pods exist
-	err := driver.UpdateStorageClusterStatus(cluster)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "failed to get endpoint")
-
-	storageNodes := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
-	require.NoError(t, err)
-	require.Empty(t, storageNodes.Items)
+	// Mock cluster inspect response
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
 
-	// TestCase: Portworx pod exist and storage node exist
-	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
-	pod := &v1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            "portworx-pod-1",
-			Namespace:       cluster.Namespace,
-			Labels:          driver.GetSelectorLabels(),
-			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+	// Mock node enumerate response
+	expectedNode := &api.StorageNode{
+		Id:                "node-1",
+		SchedulerNodeName: "node-one",
+		Status:            api.Status_STATUS_OK,
+		Pools: []*api.StoragePool{
+			{
+				ID:        1,
+				Medium:    api.StorageMedium_STORAGE_MEDIUM_SSD,
+				RaidLevel: "raid0",
+				TotalSize: 200 * 1024 * 1024 * 1024,
+				Used:      20 * 1024 * 1024 * 1024,
+				Labels: map[string]string{
+					"medium": "STORAGE_MEDIUM_SSD",
+				},
+			},
+			{
+				ID:        0,
+				Medium:    api.StorageMedium_STORAGE_MEDIUM_MAGNETIC,
+				RaidLevel: "raid0",
+				TotalSize: 100 * 1024 * 1024 * 1024,
+				Used:      10 * 1024 * 1024 * 1024,
+			},
 		},
-		Spec: v1.PodSpec{
-			NodeName: "node-1",
+		Disks: map[string]*api.StorageResource{
+			"/dev/sdc": {
+				Path:   "/dev/sdc",
+				Medium: api.StorageMedium_STORAGE_MEDIUM_SSD,
+				Size:   200 * 1024 * 1024 * 1024,
+				Used:   20 * 1024 * 1024 * 1024,
+				Online: true,
+			},
+			"/dev/sdb": {
+				Path:     "/dev/sdb",
+				Medium:   api.StorageMedium_STORAGE_MEDIUM_MAGNETIC,
+				Size:     100 * 1024 * 1024 * 1024,
+				Used:     10 * 1024 * 1024 * 1024,
+				Online:   true,
+				Metadata: true,
+			},
 		},
 	}
-	storageNode := &corev1alpha1.StorageNode{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "node-1",
-			Namespace: cluster.Namespace,
-		},
+	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{expectedNode},
 	}
-	k8sClient.Create(context.TODO(), pod)
-	k8sClient.Create(context.TODO(), storageNode)
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		AnyTimes()
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	nodeStatus := &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, nodeStatus, "node-one", cluster.Namespace)
+	require.NoError(t, err)
 
+	storage := nodeStatus.Status.Storage
+	require.NotNil(t, storage)
+	require.Equal(t, "300Gi", storage.TotalSize.String())
+	require.Equal(t, "30Gi", storage.UsedSize.String())
+
+	// Pools should be sorted by their ID
+	require.Len(t, storage.Pools, 2)
+	require.Equal(t, int32(0), storage.Pools[0].ID)
+	require.Equal(t, "HDD", storage.Pools[0].MediaType)
+	require.Equal(t, "raid0", storage.Pools[0].RAIDLevel)
+	require.Equal(t, "100Gi", storage.Pools[0].TotalSize.String())
+	require.Equal(t, "10Gi", storage.Pools[0].UsedSize.String())
+	require.Empty(t, storage.Pools[0].Labels)
+	require.Equal(t, int32(1), storage.Pools[1].ID)
+	require.Equal(t, "SSD", storage.Pools[1].MediaType)
+	require.Equal(t, "200Gi", storage.Pools[1].TotalSize.String())
+	require.Equal(t, "20Gi", storage.Pools[1].UsedSize.String())
+	require.Equal(t, expectedNode.Pools[0].Labels, storage.Pools[1].Labels)
+
+	// Drives should be sorted by their path
+	require.Len(t, storage.Drives, 2)
+	require.Equal(t, "/dev/sdb", storage.Drives[0].Path)
+	require.Equal(t, "HDD", storage.Drives[0].MediaType)
+	require.Equal(t, "100Gi", storage.Drives[0].TotalSize.String())
+	require.Equal(t, "10Gi", storage.Drives[0].UsedSize.String())
+	require.True(t, storage.Drives[0].Online)
+	require.True(t, storage.Drives[0].Metadata)
+	require.Equal(t, "/dev/sdc", storage.Drives[1].Path)
+	require.Equal(t, "SSD", storage.Drives[1].MediaType)
+	require.True(t, storage.Drives[1].Online)
+	require.False(t, storage.Drives[1].Metadata)
+
+	// The status should not be updated if the storage has not changed
 	err = driver.UpdateStorageClusterStatus(cluster)
-	require.Contains(t, err.Error(), "failed to get endpoint")
+	require.NoError(t, err)
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
+	updatedNodeStatus := &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, updatedNodeStatus, "node-one", cluster.Namespace)
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
-}
+	require.Equal(t, nodeStatus.ResourceVersion, updatedNodeStatus.ResourceVersion)
 
-func TestUpdateClusterStatusServiceGrpcServerError(t *testing.T) {
-	k8sClient := testutil.FakeK8sClient(&v1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      pxutil.PortworxServiceName,
-			Namespace: "kube-test",
-		},
-		Spec: v1.ServiceSpec{
-			ClusterIP: "127.0.0.1",
-		},
-	})
+	// The status should be updated when the pool usage changes
+	expectedNode.Pools[0].Used = 50 * 1024 * 1024 * 1024
 
-	driver := portworx{
-		k8sClient: k8sClient,
-	}
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
 
-	cluster := &corev1alpha1.StorageCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "px-cluster",
-			Namespace: "kube-test",
-		},
-		Status: corev1alpha1.StorageClusterStatus{
-			Phase: "Initializing",
-		},
-	}
+	nodeStatus = &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, nodeStatus, "node-one", cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, "60Gi", nodeStatus.Status.Storage.UsedSize.String())
+	require.Equal(t, "50Gi", nodeStatus.Status.Storage.Pools[1].UsedSize.String())
 
-	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
-	pod := &v1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            "portworx-pod-1",
-			Namespace:       cluster.Namespace,
-			Labels:          driver.GetSelectorLabels(),
-			OwnerReferences: []metav1.OwnerReference{*ownerRef},
-		},
-		Spec: v1.PodSpec{
-			NodeName: "node-1",
-		},
-	}
-	storageNode := &corev1alpha1.StorageNode{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "node-1",
-			Namespace: cluster.Namespace,
-		},
-	}
-	k8sClient.Create(context.TODO(), pod)
-	k8sClient.Create(context.TODO(), storageNode)
+	// Storage should be cleared if the node does not report any pools
+	expectedNode.Pools = nil
+	expectedNode.Disks = nil
 
-	err := driver.UpdateStorageClusterStatus(cluster)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "error connecting to GRPC server")
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
 
-	storageNodes := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
+	nodeStatus = &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, nodeStatus, "node-one", cluster.Namespace)
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+	require.Nil(t, nodeStatus.Status.Storage)
 }
 
-func TestUpdateClusterStatusInspectClusterFailure(t *testing.T) {
+func TestUpdateClusterStatusWithStorageAggregate(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	// Create the mock servers that can be used to mock SDK calls
 	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
 
 	// Start a sdk server that implements the mock servers
 	sdkServerIP := "127.0.0.1"
 	sdkServerPort := 21883
 	mockSdk := mock.NewSdkServer(mock.SdkServers{
 		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
 	})
 	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
 	defer mockSdk.Stop()
 
 	// Create fake k8s client with fake service that will point the client
-	// to the mock sdk server address
-	k8sClient := testutil.FakeK8sClient(&v1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      pxutil.PortworxServiceName,
-			Namespace: "kube-test",
+	// to the mock sdk server address, and nodes in different zones
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test",
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
+				},
+			},
 		},
-		Spec: v1.ServiceSpec{
-			ClusterIP: sdkServerIP,
-			Ports: []v1.ServicePort{
-				{
-					Name: pxutil.PortworxSDKPortName,
-					Port: int32(sdkServerPort),
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-one",
+				Labels: map[string]string{
+					v1.LabelZoneRegion:        "region-1",
+					v1.LabelZoneFailureDomain: "zone-a",
 				},
 			},
 		},
-	})
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-two",
+				Labels: map[string]string{
+					v1.LabelZoneRegion:        "region-1",
+					v1.LabelZoneFailureDomain: "zone-a",
+				},
+			},
+		},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-three",
+				Labels: map[string]string{
+					v1.LabelZoneRegion:        "region-1",
+					v1.LabelZoneFailureDomain: "zone-b",
+				},
+			},
+		},
+	)
 
 	// Create driver object with the fake k8s client
 	driver := portworx{
@@ -1880,99 +1915,129 @@ func TestUpdateClusterStatusInspectClusterFailure(t *testing.T) {
 			Name:      "px-cluster",
 			Namespace: "kube-test",
 		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "test/image:1.2.3.4",
+		},
 		Status: corev1alpha1.StorageClusterStatus{
 			Phase: "Initializing",
+			Storage: corev1alpha1.Storage{
+				StorageNodesPerZone: 3,
+			},
 		},
 	}
 
-	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
-	pod := &v1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            "portworx-pod-1",
-			Namespace:       cluster.Namespace,
-			Labels:          driver.GetSelectorLabels(),
-			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+	// Mock cluster inspect response
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
+
+	// Mock node enumerate response
+	expectedNodeOne := &api.StorageNode{
+		Id:                "node-1",
+		SchedulerNodeName: "node-one",
+		NodeLabels: map[string]string{
+			"PX Version": "2.5.1",
 		},
-		Spec: v1.PodSpec{
-			NodeName: "node-1",
+		Pools: []*api.StoragePool{
+			{
+				TotalSize: 100 * 1024 * 1024 * 1024,
+				Used:      10 * 1024 * 1024 * 1024,
+			},
+			{
+				ID:        1,
+				TotalSize: 50 * 1024 * 1024 * 1024,
+				Used:      5 * 1024 * 1024 * 1024,
+			},
 		},
 	}
-	storageNode := &corev1alpha1.StorageNode{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "node-1",
-			Namespace: cluster.Namespace,
+	expectedNodeTwo := &api.StorageNode{
+		Id:                "node-2",
+		SchedulerNodeName: "node-two",
+		NodeLabels: map[string]string{
+			"PX Version": "2.5.0",
+		},
+		Pools: []*api.StoragePool{
+			{
+				TotalSize: 100 * 1024 * 1024 * 1024,
+				Used:      20 * 1024 * 1024 * 1024,
+			},
 		},
 	}
-	k8sClient.Create(context.TODO(), pod)
-	k8sClient.Create(context.TODO(), storageNode)
-
-	// Error from InspectCurrent API
-	mockClusterServer.EXPECT().
-		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
-		Return(nil, fmt.Errorf("InspectCurrent error")).
-		Times(1)
+	expectedNodeThree := &api.StorageNode{
+		Id:                "node-3",
+		SchedulerNodeName: "node-three",
+		NodeLabels: map[string]string{
+			"PX Version": "2.5.1",
+		},
+	}
+	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{expectedNodeOne, expectedNodeTwo, expectedNodeThree},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		AnyTimes()
 
 	err := driver.UpdateStorageClusterStatus(cluster)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "InspectCurrent error")
-
-	storageNodes := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
 
-	// Nil response from InspectCurrent API
-	mockClusterServer.EXPECT().
-		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
-		Return(nil, nil).
-		Times(1)
+	storage := cluster.Status.Storage
+	require.Equal(t, int32(3), storage.StorageNodesPerZone)
+	require.Equal(t, "250Gi", storage.TotalSize.String())
+	require.Equal(t, "35Gi", storage.UsedSize.String())
+	require.Equal(t, int32(2), storage.StorageNodes)
+	require.Equal(t, int32(1), storage.StoragelessNodes)
+	require.Equal(t, map[string]int32{"zone-a": 2, "zone-b": 1}, storage.NodesPerZone)
+	require.Equal(t, []string{"2.5.0", "2.5.1"}, storage.Versions)
 
-	// Reset the storage node status
-	storageNode.Status = corev1alpha1.NodeStatus{}
-	k8sClient.Status().Update(context.TODO(), storageNode)
+	// The zone and region of the nodes should be recorded in the StorageNodes
+	nodeStatus := &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, nodeStatus, "node-three", cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, "zone-b", nodeStatus.Status.Geo.Zone)
+	require.Equal(t, "region-1", nodeStatus.Status.Geo.Region)
 
-	err = driver.UpdateStorageClusterStatus(cluster)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "failed to inspect cluster")
+	// Nodes that are no longer part of the cluster should not be counted
+	expectedNodeEnumerateResp.Nodes = []*api.StorageNode{expectedNodeOne, expectedNodeThree}
+	expectedNodeThree.NodeLabels["PX Version"] = "2.5.2"
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
+	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
 
-	// Nil cluster object in the response of InspectCurrent API
-	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
-		Cluster: nil,
-	}
-	mockClusterServer.EXPECT().
-		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
-		Return(expectedClusterResp, nil).
-		Times(1)
+	storage = cluster.Status.Storage
+	require.Equal(t, "150Gi", storage.TotalSize.String())
+	require.Equal(t, "15Gi", storage.UsedSize.String())
+	require.Equal(t, int32(1), storage.StorageNodes)
+	require.Equal(t, int32(1), storage.StoragelessNodes)
+	require.Equal(t, map[string]int32{"zone-a": 1, "zone-b": 1}, storage.NodesPerZone)
+	require.Equal(t, []string{"2.5.1", "2.5.2"}, storage.Versions)
 
-	// Reset the storage node status
-	storageNode.Status = corev1alpha1.NodeStatus{}
-	k8sClient.Status().Update(context.TODO(), storageNode)
+	// Nodes without a zone should not be counted in any zone
+	k8sNode := &v1.Node{}
+	err = testutil.Get(k8sClient, k8sNode, "node-one", "")
+	require.NoError(t, err)
+	k8sNode.Labels = nil
+	err = k8sClient.Update(context.TODO(), k8sNode)
+	require.NoError(t, err)
 
 	err = driver.UpdateStorageClusterStatus(cluster)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "empty ClusterInspect response")
-
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+	require.Equal(t, map[string]int32{"zone-b": 1}, cluster.Status.Storage.NodesPerZone)
 }
 
-func TestUpdateClusterStatusEnumerateNodesFailure(t *testing.T) {
+func TestUpdateClusterStatusWithAlerts(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	// Create the mock servers that can be used to mock SDK calls
 	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
 	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+	mockVolumeServer := mock.NewMockOpenStorageVolumeServer(mockCtrl)
+	mockAlertsServer := mock.NewMockOpenStorageAlertsServer(mockCtrl)
 
 	// Start a sdk server that implements the mock servers
 	sdkServerIP := "127.0.0.1"
@@ -1980,31 +2045,69 @@ func TestUpdateClusterStatusEnumerateNodesFailure(t *testing.T) {
 	mockSdk := mock.NewSdkServer(mock.SdkServers{
 		Cluster: mockClusterServer,
 		Node:    mockNodeServer,
+		Volume:  mockVolumeServer,
+		Alerts:  mockAlertsServer,
 	})
 	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
 	defer mockSdk.Stop()
 
 	// Create fake k8s client with fake service that will point the client
-	// to the mock sdk server address
-	k8sClient := testutil.FakeK8sClient(&v1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      pxutil.PortworxServiceName,
-			Namespace: "kube-test",
+	// to the mock sdk server address, and a PVC bound to a portworx volume
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test",
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
+				},
+			},
 		},
-		Spec: v1.ServiceSpec{
-			ClusterIP: sdkServerIP,
-			Ports: []v1.ServicePort{
-				{
-					Name: pxutil.PortworxSDKPortName,
-					Port: int32(sdkServerPort),
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test-2",
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
 				},
 			},
 		},
-	})
+		&v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pv-1",
+			},
+			Spec: v1.PersistentVolumeSpec{
+				ClaimRef: &v1.ObjectReference{
+					Name:      "pvc-1",
+					Namespace: "app",
+				},
+			},
+		},
+		&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pvc-1",
+				Namespace: "app",
+			},
+		},
+	)
 
 	// Create driver object with the fake k8s client
+	recorder := record.NewFakeRecorder(10)
 	driver := portworx{
 		k8sClient: k8sClient,
+		recorder:  recorder,
 	}
 
 	cluster := &corev1alpha1.StorageCluster{
@@ -2012,154 +2115,196 @@ func TestUpdateClusterStatusEnumerateNodesFailure(t *testing.T) {
 			Name:      "px-cluster",
 			Namespace: "kube-test",
 		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "test/image:1.2.3.4",
+		},
 		Status: corev1alpha1.StorageClusterStatus{
 			Phase: "Initializing",
 		},
 	}
 
-	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
-	pod := &v1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            "portworx-pod-1",
-			Namespace:       cluster.Namespace,
-			Labels:          driver.GetSelectorLabels(),
-			OwnerReferences: []metav1.OwnerReference{*ownerRef},
-		},
-		Spec: v1.PodSpec{
-			NodeName: "node-1",
-		},
-	}
-	storageNode := &corev1alpha1.StorageNode{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "node-1",
-			Namespace: cluster.Namespace,
-		},
-	}
-	k8sClient.Create(context.TODO(), pod)
-	k8sClient.Create(context.TODO(), storageNode)
-
-	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
-		Cluster: &api.StorageCluster{},
-	}
+	// Mock cluster inspect response
 	mockClusterServer.EXPECT().
 		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
-		Return(expectedClusterResp, nil).
+		Return(&api.SdkClusterInspectCurrentResponse{Cluster: &api.StorageCluster{}}, nil).
 		AnyTimes()
 
-	// TestCase: Error from node Enumerate API
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nil, fmt.Errorf("node Enumerate error")).
-		Times(1)
-
-	err := driver.UpdateStorageClusterStatus(cluster)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "node Enumerate error")
-
-	storageNodes := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
-	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
-
-	// TestCase: Nil response from node Enumerate API
+	// Mock node enumerate response
 	mockNodeServer.EXPECT().
 		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nil, nil).
-		Times(1)
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{
+			Nodes: []*api.StorageNode{
+				{
+					Id:                "node-1",
+					SchedulerNodeName: "node-one",
+				},
+			},
+		}, nil).
+		AnyTimes()
 
-	// Reset the storage node status
-	storageNode.Status = corev1alpha1.NodeStatus{}
-	k8sClient.Status().Update(context.TODO(), storageNode)
+	// Mock volume inspect response
+	mockVolumeServer.EXPECT().
+		Inspect(gomock.Any(), &api.SdkVolumeInspectRequest{VolumeId: "vol-1"}).
+		Return(&api.SdkVolumeInspectResponse{
+			Volume: &api.Volume{
+				Id:      "vol-1",
+				Locator: &api.VolumeLocator{Name: "pv-1"},
+			},
+		}, nil).
+		AnyTimes()
+	mockVolumeServer.EXPECT().
+		Inspect(gomock.Any(), &api.SdkVolumeInspectRequest{VolumeId: "vol-2"}).
+		Return(&api.SdkVolumeInspectResponse{
+			Volume: &api.Volume{
+				Id:      "vol-2",
+				Locator: &api.VolumeLocator{Name: "unknown-pv"},
+			},
+		}, nil).
+		AnyTimes()
 
-	err = driver.UpdateStorageClusterStatus(cluster)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "failed to enumerate nodes")
+	// Mock alerts enumerate response
+	now := time.Now().UTC().Truncate(time.Second)
+	newAlert := func(
+		resource api.ResourceType,
+		resourceID string,
+		severity api.SeverityType,
+		message string,
+		timestamp time.Time,
+	) *api.Alert {
+		ts, _ := ptypes.TimestampProto(timestamp)
+		return &api.Alert{
+			Resource:   resource,
+			ResourceId: resourceID,
+			Severity:   severity,
+			Message:    message,
+			Count:      1,
+			Timestamp:  ts,
+		}
+	}
+	nodeAlert := newAlert(api.ResourceType_RESOURCE_TYPE_NODE, "node-1",
+		api.SeverityType_SEVERITY_TYPE_WARNING, "node alert", now.Add(-4*time.Minute))
+	volumeAlert := newAlert(api.ResourceType_RESOURCE_TYPE_VOLUME, "vol-1",
+		api.SeverityType_SEVERITY_TYPE_ALARM, "volume alert", now.Add(-3*time.Minute))
+	unboundVolumeAlert := newAlert(api.ResourceType_RESOURCE_TYPE_VOLUME, "vol-2",
+		api.SeverityType_SEVERITY_TYPE_WARNING, "unbound volume alert", now.Add(-2*time.Minute))
+	clusterAlert := newAlert(api.ResourceType_RESOURCE_TYPE_CLUSTER, "cluster-id",
+		api.SeverityType_SEVERITY_TYPE_NOTIFY, "cluster alert", now.Add(-time.Minute))
+	alerts := []*api.Alert{clusterAlert, unboundVolumeAlert, volumeAlert, nodeAlert}
+
+	var alertRequests []*api.SdkAlertsEnumerateWithFiltersRequest
+	mockAlertsServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			req *api.SdkAlertsEnumerateWithFiltersRequest,
+			stream api.OpenStorageAlerts_EnumerateWithFiltersServer,
+		) error {
+			alertRequests = append(alertRequests, req)
+			return stream.Send(&api.SdkAlertsEnumerateWithFiltersResponse{Alerts: alerts})
+		}).
+		AnyTimes()
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
+	// TestCase: New alerts should be raised as events on the objects they
+	// relate to and listed in the status, latest first
+	err := driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
-
-	// TestCase: Empty list of nodes should create StorageNode objects only for matching pods
-	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
-		Nodes: []*api.StorageNode{},
-	}
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
-
-	// Reset the storage node status
-	storageNode.Status = corev1alpha1.NodeStatus{}
-	k8sClient.Status().Update(context.TODO(), storageNode)
 
+	require.Len(t, recorder.Events, 4)
+	require.Equal(t, fmt.Sprintf("%v %v node alert", v1.EventTypeWarning, util.StorageAlertReason),
+		<-recorder.Events)
+	require.Equal(t, fmt.Sprintf("%v %v volume alert", v1.EventTypeWarning, util.StorageAlertReason),
+		<-recorder.Events)
+	require.Equal(t, fmt.Sprintf("%v %v Volume vol-2: unbound volume alert",
+		v1.EventTypeWarning, util.StorageAlertReason), <-recorder.Events)
+	require.Equal(t, fmt.Sprintf("%v %v cluster alert", v1.EventTypeNormal, util.StorageAlertReason),
+		<-recorder.Events)
+
+	require.Len(t, cluster.Status.Alerts, 4)
+	require.Equal(t, corev1alpha1.StorageAlert{
+		Severity:     "Notify",
+		ResourceType: "Cluster",
+		ResourceID:   "cluster-id",
+		Message:      "cluster alert",
+		Count:        1,
+		LastSeen:     metav1.NewTime(now.Add(-time.Minute)),
+	}, cluster.Status.Alerts[0])
+	require.Equal(t, "Volume", cluster.Status.Alerts[1].ResourceType)
+	require.Equal(t, "vol-2", cluster.Status.Alerts[1].ResourceID)
+	require.Equal(t, "Alarm", cluster.Status.Alerts[2].Severity)
+	require.Equal(t, "vol-1", cluster.Status.Alerts[2].ResourceID)
+	require.Equal(t, "Warning", cluster.Status.Alerts[3].Severity)
+	require.Equal(t, "Node", cluster.Status.Alerts[3].ResourceType)
+
+	// Alerts of all resource types should be fetched from the last few minutes
+	require.Len(t, alertRequests, 1)
+	require.Len(t, alertRequests[0].Queries, 4)
+	startTime, err := ptypes.Timestamp(alertRequests[0].Queries[0].Opts[0].GetTimeSpan().StartTime)
+	require.NoError(t, err)
+	require.True(t, startTime.Before(now.Add(-4*time.Minute)))
+
+	// TestCase: Alerts that have already been raised should not be raised again
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
+	require.Len(t, recorder.Events, 0)
+	require.Len(t, cluster.Status.Alerts, 4)
+	require.Len(t, alertRequests, 2)
+	startTime, err = ptypes.Timestamp(alertRequests[1].Queries[0].Opts[0].GetTimeSpan().StartTime)
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
-
-	// TestCase: Nil list of nodes should create StorageNode objects only for matching pods
-	expectedNodeEnumerateResp.Nodes = nil
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
+	require.True(t, startTime.Equal(now.Add(-time.Minute)))
 
-	// Reset the storage node status
-	storageNode.Status = corev1alpha1.NodeStatus{}
-	k8sClient.Status().Update(context.TODO(), storageNode)
+	// TestCase: Alerts that occur again should be raised again, and cleared
+	// alerts should be removed from the status
+	nodeAlert.Count = 2
+	nodeAlert.Timestamp, _ = ptypes.TimestampProto(now)
+	volumeAlert.Cleared = true
+	volumeAlert.Timestamp, _ = ptypes.TimestampProto(now)
+	alerts = []*api.Alert{nodeAlert, volumeAlert}
 
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
-	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
-
-	// TestCase: Empty list of nodes should not create any StorageNode objects
-	expectedNodeEnumerateResp = &api.SdkNodeEnumerateWithFiltersResponse{
-		Nodes: []*api.StorageNode{},
-	}
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t, fmt.Sprintf("%v %v node alert", v1.EventTypeWarning, util.StorageAlertReason),
+		<-recorder.Events)
+	require.Len(t, cluster.Status.Alerts, 3)
+	require.Equal(t, "node-1", cluster.Status.Alerts[0].ResourceID)
+	require.Equal(t, int64(2), cluster.Status.Alerts[0].Count)
+	require.Equal(t, "cluster-id", cluster.Status.Alerts[1].ResourceID)
+	require.Equal(t, "vol-2", cluster.Status.Alerts[2].ResourceID)
 
-	k8sClient.Delete(context.TODO(), pod)
+	// Only the alerts that can be fetched again should be remembered
+	cursor := driver.alertCursors[clusterKey(cluster)]
+	require.True(t, cursor.since.Equal(now))
+	require.Len(t, cursor.seen, 1)
 
-	err = driver.UpdateStorageClusterStatus(cluster)
-	require.NoError(t, err)
+	// TestCase: Alerts of another cluster should be tracked separately
+	otherCluster := cluster.DeepCopy()
+	otherCluster.Namespace = "kube-test-2"
+	otherCluster.Status = corev1alpha1.StorageClusterStatus{
+		Phase: "Initializing",
+	}
 
-	nodeStatusList := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
+	err = driver.UpdateStorageClusterStatus(otherCluster)
 	require.NoError(t, err)
-	require.Empty(t, nodeStatusList.Items)
-
-	// TestCase: Nil list of nodes should not create any StorageNode objects
-	expectedNodeEnumerateResp.Nodes = nil
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
 
-	err = driver.UpdateStorageClusterStatus(cluster)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t, fmt.Sprintf("%v %v node alert", v1.EventTypeWarning, util.StorageAlertReason),
+		<-recorder.Events)
+	require.Len(t, otherCluster.Status.Alerts, 1)
+	require.Len(t, alertRequests, 4)
+	startTime, err = ptypes.Timestamp(alertRequests[3].Queries[0].Opts[0].GetTimeSpan().StartTime)
 	require.NoError(t, err)
+	require.True(t, startTime.Before(now.Add(-4*time.Minute)))
+	require.True(t, driver.alertCursors[clusterKey(cluster)].since.Equal(now))
 
-	nodeStatusList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
+	// TestCase: Alerts of a cluster should be forgotten when it is deleted
+	_, err = driver.DeleteStorage(otherCluster)
 	require.NoError(t, err)
-	require.Empty(t, nodeStatusList.Items)
+	require.NotContains(t, driver.alertCursors, clusterKey(otherCluster))
+	require.Contains(t, driver.alertCursors, clusterKey(cluster))
 }
 
-func TestUpdateClusterStatusShouldUpdateStatusIfChanged(t *testing.T) {
+func TestUpdateClusterStatusWithSecurity(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -2178,22 +2323,33 @@ func TestUpdateClusterStatusShouldUpdateStatusIfChanged(t *testing.T) {
 	defer mockSdk.Stop()
 
 	// Create fake k8s client with fake service that will point the client
-	// to the mock sdk server address
-	k8sClient := testutil.FakeK8sClient(&v1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      pxutil.PortworxServiceName,
-			Namespace: "kube-test",
-		},
-		Spec: v1.ServiceSpec{
-			ClusterIP: sdkServerIP,
-			Ports: []v1.ServicePort{
-				{
-					Name: pxutil.PortworxSDKPortName,
-					Port: int32(sdkServerPort),
+	// to the mock sdk server address, and the shared secret of the cluster
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test",
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
 				},
 			},
 		},
-	})
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.SecuritySharedSecretName,
+				Namespace: "kube-test",
+			},
+			Data: map[string][]byte{
+				pxutil.SecuritySharedSecretKey: []byte("shared-secret"),
+			},
+		},
+	)
 
 	// Create driver object with the fake k8s client
 	driver := portworx{
@@ -2205,74 +2361,114 @@ func TestUpdateClusterStatusShouldUpdateStatusIfChanged(t *testing.T) {
 			Name:      "px-cluster",
 			Namespace: "kube-test",
 		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "test/image:1.2.3.4",
+			Security: &corev1alpha1.SecuritySpec{
+				Enabled: true,
+			},
+		},
 		Status: corev1alpha1.StorageClusterStatus{
 			Phase: "Initializing",
 		},
 	}
 
-	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
-		Cluster: &api.StorageCluster{
-			Status: api.Status_STATUS_ERROR,
-		},
+	// Record the tokens sent with the SDK requests
+	var tokens []string
+	recordToken := func(ctx context.Context) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		tokens = append(tokens, strings.Join(md.Get("authorization"), ","))
 	}
 	mockClusterServer.EXPECT().
 		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
-		Return(expectedClusterResp, nil).
-		Times(1)
-
-	expectedNode := &api.StorageNode{
-		Id:                "node-1",
-		SchedulerNodeName: "node-one",
-		DataIp:            "1.1.1.1",
-		Status:            api.Status_STATUS_MAINTENANCE,
-	}
-	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
-		Nodes: []*api.StorageNode{expectedNode},
-	}
+		DoAndReturn(func(
+			ctx context.Context,
+			req *api.SdkClusterInspectCurrentRequest,
+		) (*api.SdkClusterInspectCurrentResponse, error) {
+			recordToken(ctx)
+			return &api.SdkClusterInspectCurrentResponse{
+				Cluster: &api.StorageCluster{
+					Status: api.Status_STATUS_OK,
+				},
+			}, nil
+		}).
+		AnyTimes()
 	mockNodeServer.EXPECT().
 		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{}, nil).
+		AnyTimes()
 
+	// TestCase: The requests should have a token signed with the shared secret
 	err := driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
+	require.Equal(t, string(corev1alpha1.ClusterOnline), cluster.Status.Phase)
 
-	require.Equal(t, "Offline", cluster.Status.Phase)
-	nodeStatusList := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
+	require.Len(t, tokens, 1)
+	require.True(t, strings.HasPrefix(tokens[0], "bearer "))
+	token := strings.TrimPrefix(tokens[0], "bearer ")
+	claims, err := auth.TokenClaims(token)
+	require.NoError(t, err)
+	require.Equal(t, pxutil.DefaultSecurityIssuer, claims.Issuer)
+	require.Equal(t, []string{"system.admin"}, claims.Roles)
+	_, err = jwt.Parse(token, func(*jwt.Token) (interface{}, error) {
+		return []byte("shared-secret"), nil
+	})
 	require.NoError(t, err)
-	require.Len(t, nodeStatusList.Items, 1)
-	require.Equal(t, "node-1", nodeStatusList.Items[0].Status.NodeUID)
-	require.Equal(t, corev1alpha1.NodeMaintenanceStatus, nodeStatusList.Items[0].Status.Conditions[0].Status)
-	require.Equal(t, "1.1.1.1", nodeStatusList.Items[0].Status.Network.DataIP)
 
-	// Update status based on the latest object
-	expectedClusterResp.Cluster.Status = api.Status_STATUS_OK
-	expectedNode.Status = api.Status_STATUS_OK
-	expectedNode.DataIp = "2.2.2.2"
-	mockClusterServer.EXPECT().
-		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
-		Return(expectedClusterResp, nil).
-		Times(1)
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
+	// TestCase: The token should be reused until it is close to expiring
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	require.Equal(t, tokens[0], tokens[1])
+
+	// TestCase: The token should be regenerated when the shared secret changes
+	sharedSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, sharedSecret, pxutil.SecuritySharedSecretName, "kube-test")
+	require.NoError(t, err)
+	sharedSecret.Data[pxutil.SecuritySharedSecretKey] = []byte("rotated-shared-secret")
+	err = k8sClient.Update(context.TODO(), sharedSecret)
+	require.NoError(t, err)
 
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
+	require.Len(t, tokens, 3)
+	_, err = jwt.Parse(strings.TrimPrefix(tokens[2], "bearer "), func(*jwt.Token) (interface{}, error) {
+		return []byte("rotated-shared-secret"), nil
+	})
+	require.NoError(t, err)
 
-	require.Equal(t, "Online", cluster.Status.Phase)
-	nodeStatusList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
+	// TestCase: The connection should use the new issuer when it changes
+	cluster.Spec.Security.Auth = &corev1alpha1.AuthSpec{
+		SelfSigned: &corev1alpha1.SelfSignedSpec{
+			Issuer: stringPtr("test-issuer"),
+		},
+	}
+
+	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, nodeStatusList.Items, 1)
-	require.Equal(t, "node-1", nodeStatusList.Items[0].Status.NodeUID)
-	require.Equal(t, corev1alpha1.NodeOnlineStatus, nodeStatusList.Items[0].Status.Conditions[0].Status)
-	require.Equal(t, "2.2.2.2", nodeStatusList.Items[0].Status.Network.DataIP)
+	require.Len(t, tokens, 4)
+	claims, err = auth.TokenClaims(strings.TrimPrefix(tokens[3], "bearer "))
+	require.NoError(t, err)
+	require.Equal(t, "test-issuer", claims.Issuer)
+
+	// TestCase: No token should be sent once security is disabled
+	cluster.Spec.Security.Enabled = false
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, tokens, 5)
+	require.Empty(t, tokens[4])
+
+	// TestCase: Status update should fail if the shared secret is missing
+	cluster.Spec.Security.Enabled = true
+	cluster.Spec.Security.Auth.SelfSigned.SharedSecret = stringPtr("missing-secret")
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing-secret")
+	require.Len(t, tokens, 5)
 }
 
-func TestUpdateClusterStatusShouldUpdateNodePhaseBasedOnConditions(t *testing.T) {
+func TestUpdateClusterStatusWithTLS(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -2280,34 +2476,29 @@ func TestUpdateClusterStatusShouldUpdateNodePhaseBasedOnConditions(t *testing.T)
 	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
 	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
 
-	// Start a sdk server that implements the mock servers
-	sdkServerIP := "127.0.0.1"
-	sdkServerPort := 21883
-	mockSdk := mock.NewSdkServer(mock.SdkServers{
-		Cluster: mockClusterServer,
-		Node:    mockNodeServer,
-	})
-	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
-	defer mockSdk.Stop()
-
 	// Create fake k8s client with fake service that will point the client
 	// to the mock sdk server address
-	k8sClient := testutil.FakeK8sClient(&v1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      pxutil.PortworxServiceName,
-			Namespace: "kube-test",
-		},
-		Spec: v1.ServiceSpec{
-			ClusterIP: sdkServerIP,
-			Ports: []v1.ServicePort{
-				{
-					Name: pxutil.PortworxSDKPortName,
-					Port: int32(sdkServerPort),
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test",
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
 				},
 			},
 		},
-	})
+	)
 
+	// Create driver object with the fake k8s client
 	driver := portworx{
 		k8sClient: k8sClient,
 	}
@@ -2317,205 +2508,475 @@ func TestUpdateClusterStatusShouldUpdateNodePhaseBasedOnConditions(t *testing.T)
 			Name:      "px-cluster",
 			Namespace: "kube-test",
 		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "test/image:1.2.3.4",
+			Security: &corev1alpha1.SecuritySpec{
+				TLS: &corev1alpha1.TLSSpec{
+					Enabled: true,
+				},
+			},
+		},
 		Status: corev1alpha1.StorageClusterStatus{
 			Phase: "Initializing",
 		},
 	}
 
-	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
-		Cluster: &api.StorageCluster{},
-	}
-	mockClusterServer.EXPECT().
-		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
-		Return(expectedClusterResp, nil).
-		AnyTimes()
-
-	// TestCases: Portworx node present in SDK output
-	// TestCase: Node does not have any condition, node state condition will be
-	// used to populate the node phase
-	expectedNode := &api.StorageNode{
-		Id:                "node-1",
-		SchedulerNodeName: "node-one",
-		DataIp:            "1.1.1.1",
-		Status:            api.Status_STATUS_MAINTENANCE,
-	}
-	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
-		Nodes: []*api.StorageNode{expectedNode},
-	}
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
-
-	err := driver.UpdateStorageClusterStatus(cluster)
+	// Start a sdk server with the certificate issued for the node
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	err := pxutil.EnsureTLSNodeCertificate(k8sClient, cluster, "node1", ownerRef)
+	require.NoError(t, err)
+	nodeSecret := &v1.Secret{}
+	err = testutil.Get(k8sClient, nodeSecret, pxutil.TLSNodeSecretName("node1"), cluster.Namespace)
 	require.NoError(t, err)
 
-	storageNodes := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	err = mockSdk.StartTLSOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort),
+		nodeSecret.Data[pxutil.TLSCertKey], nodeSecret.Data[pxutil.TLSPrivateKeyKey])
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeMaintenanceStatus), storageNodes.Items[0].Status.Phase)
+	defer mockSdk.Stop()
 
-	// TestCase: Node has another condition which is newer than when the node state
-	// was last transitioned
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(&api.SdkClusterInspectCurrentResponse{
+			Cluster: &api.StorageCluster{
+				Status: api.Status_STATUS_OK,
+			},
+		}, nil).
+		Times(2)
 	mockNodeServer.EXPECT().
 		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
-
-	// Sleep is needed because fake k8s client stores the timestamp at sec granularity
-	time.Sleep(time.Second)
-	operatorops.Instance().UpdateStorageNodeCondition(
-		&storageNodes.Items[0].Status,
-		&corev1alpha1.NodeCondition{
-			Type:   corev1alpha1.NodeInitCondition,
-			Status: corev1alpha1.NodeFailedStatus,
-		},
-	)
-	k8sClient.Status().Update(context.TODO(), &storageNodes.Items[0])
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{}, nil).
+		Times(2)
 
+	// TestCase: The client should verify the server certificate with the CA
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
+	require.Equal(t, string(corev1alpha1.ClusterOnline), cluster.Status.Phase)
+	require.Equal(t, nodeSecret.Data[pxutil.TLSCACertKey], driver.sdkConns[clusterKey(cluster)].rootCA)
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
+	// TestCase: The connection should be reused while the CA does not change
+	sdkConn := driver.sdkConns[clusterKey(cluster)].conn
+	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeFailedStatus), storageNodes.Items[0].Status.Phase)
+	require.Equal(t, sdkConn, driver.sdkConns[clusterKey(cluster)].conn)
 
-	// TestCase: Node state has transitioned
-	expectedNode.Status = api.Status_STATUS_DECOMMISSION
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
+	// TestCase: Status update should fail if the CA is missing
+	err = testutil.Delete(k8sClient, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.TLSCASecretName,
+			Namespace: cluster.Namespace,
+		},
+	})
+	require.NoError(t, err)
 
-	time.Sleep(time.Second)
 	err = driver.UpdateStorageClusterStatus(cluster)
-	require.NoError(t, err)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to get TLS CA certificate")
+}
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
-	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeDecommissionedStatus), storageNodes.Items[0].Status.Phase)
+// probeKvdb is a kvdb client for a single endpoint, that fails to read when
+// the endpoint is down and reports the given leader as the kvdb leader
+type probeKvdb struct {
+	kvdb.Kvdb
+	endpoint string
+	down     map[string]bool
+	leader   string
+}
 
-	// TestCases: Portworx node not present in SDK output, but matching pod present
-	// TestCase: If latest node condition is not Init or Failed (here it is Decommissioned)
-	expectedNodeEnumerateResp = &api.SdkNodeEnumerateWithFiltersResponse{
-		Nodes: []*api.StorageNode{},
+func (k *probeKvdb) Get(key string) (*kvdb.KVPair, error) {
+	if k.down[k.endpoint] {
+		return nil, fmt.Errorf("connection refused")
 	}
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		AnyTimes()
+	return k.Kvdb.Get(key)
+}
 
-	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
-	pod := &v1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            "portworx-pod-1",
-			Namespace:       cluster.Namespace,
-			Labels:          driver.GetSelectorLabels(),
-			OwnerReferences: []metav1.OwnerReference{*ownerRef},
-		},
-		Spec: v1.PodSpec{
-			NodeName: "node-one",
+func (k *probeKvdb) ListMembers() (map[string]*kvdb.MemberInfo, error) {
+	return map[string]*kvdb.MemberInfo{
+		"leader": {
+			Leader:     true,
+			ClientUrls: []string{k.leader},
 		},
-	}
-	k8sClient.Create(context.TODO(), pod)
-
-	err = driver.UpdateStorageClusterStatus(cluster)
-	require.NoError(t, err)
+	}, nil
+}
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
-	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeUnknownStatus), storageNodes.Items[0].Status.Phase)
+func TestUpdateClusterStatusWithExternalKvdb(t *testing.T) {
+	defer func() {
+		getKVDBVersion = kvdb.Version
+		newKVDB = kvdb.New
+	}()
 
-	// TestCase: If latest node condition is Failed then phase should be Failed
-	storageNodes.Items[0].Status.Conditions = []corev1alpha1.NodeCondition{
-		{
-			Type:   corev1alpha1.NodeStateCondition,
-			Status: corev1alpha1.NodeFailedStatus,
+	// There is no portworx service, so the status update fails after
+	// checking the external kvdb
+	k8sClient := testutil.FakeK8sClient(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kvdb-auth",
+			Namespace: "kube-test",
 		},
+		Data: map[string][]byte{
+			secretKeyKvdbUsername: []byte("user"),
+			secretKeyKvdbPassword: []byte("password"),
+		},
+	})
+	recorder := record.NewFakeRecorder(10)
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  recorder,
 	}
-	k8sClient.Update(context.TODO(), &storageNodes.Items[0])
-
-	err = driver.UpdateStorageClusterStatus(cluster)
-	require.NoError(t, err)
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
-	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeFailedStatus), storageNodes.Items[0].Status.Phase)
-
-	// TestCase: If latest node condition is NodeInit with succeeded status
-	// then phase should be Init
-	storageNodes.Items[0].Status.Conditions = []corev1alpha1.NodeCondition{
-		{
-			Type:   corev1alpha1.NodeInitCondition,
-			Status: corev1alpha1.NodeSucceededStatus,
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "test/image:1.2.3.4",
+			Kvdb: &corev1alpha1.KvdbSpec{
+				Endpoints: []string{
+					"etcd:http://kvdb1.com:2379",
+					"etcd:http://kvdb2.com:2379",
+					"etcd:http://kvdb3.com:2379",
+				},
+				AuthSecret: "kvdb-auth",
+			},
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
 		},
 	}
-	k8sClient.Update(context.TODO(), &storageNodes.Items[0])
 
-	err = driver.UpdateStorageClusterStatus(cluster)
+	var lock sync.Mutex
+	var clientOpts []map[string]string
+	down := make(map[string]bool)
+	getKVDBVersion = func(_ string, url string, opts map[string]string) (string, error) {
+		return kvdb.EtcdVersion3, nil
+	}
+	newKVDB = func(_, prefix string, machines []string, opts map[string]string, _ kvdb.FatalErrorCB) (kvdb.Kvdb, error) {
+		lock.Lock()
+		clientOpts = append(clientOpts, opts)
+		lock.Unlock()
+		kv, err := kvdb.New(mem.Name, prefix, machines, opts, dbg.Panicf)
+		if err != nil {
+			return nil, err
+		}
+		return &probeKvdb{
+			Kvdb:     kv,
+			endpoint: machines[0],
+			down:     down,
+			leader:   "http://kvdb1.com:2379",
+		}, nil
+	}
+
+	// TestCase: All endpoints should be checked with the kvdb credentials
+	updateStatusWithKvdbCheck(t, &driver, cluster)
+
+	require.NotNil(t, cluster.Status.Kvdb)
+	require.NotNil(t, cluster.Status.Kvdb.LastCheckTime)
+	require.Len(t, cluster.Status.Kvdb.Endpoints, 3)
+	for i, status := range cluster.Status.Kvdb.Endpoints {
+		require.Equal(t, cluster.Spec.Kvdb.Endpoints[i], status.Endpoint)
+		require.True(t, status.Healthy)
+		require.NotNil(t, status.Latency)
+		require.Empty(t, status.Message)
+		require.Equal(t, i == 0, status.Leader)
+	}
+	require.Empty(t, cluster.Status.Kvdb.Members)
+	require.Len(t, recorder.Events, 0)
+	require.Len(t, clientOpts, 3)
+	for _, opts := range clientOpts {
+		require.Equal(t, "user", opts[kvdb.UsernameKey])
+		require.Equal(t, "password", opts[kvdb.PasswordKey])
+	}
+
+	// TestCase: Endpoints should not be checked again before the check interval
+	down["http://kvdb2.com:2379"] = true
+	lastCheckTime := cluster.Status.Kvdb.LastCheckTime
+
+	updateStatusWithKvdbCheck(t, &driver, cluster)
+
+	require.Equal(t, lastCheckTime, cluster.Status.Kvdb.LastCheckTime)
+	require.True(t, cluster.Status.Kvdb.Endpoints[1].Healthy)
+
+	// TestCase: Raise an event if the quorum is at risk. The existing clients
+	// should be reused.
+	cluster.Status.Kvdb.LastCheckTime = &metav1.Time{Time: time.Now().Add(-2 * kvdbHealthCheckInterval)}
+
+	updateStatusWithKvdbCheck(t, &driver, cluster)
+
+	require.True(t, cluster.Status.Kvdb.Endpoints[0].Healthy)
+	require.False(t, cluster.Status.Kvdb.Endpoints[1].Healthy)
+	require.Nil(t, cluster.Status.Kvdb.Endpoints[1].Latency)
+	require.Contains(t, cluster.Status.Kvdb.Endpoints[1].Message, "connection refused")
+	require.True(t, cluster.Status.Kvdb.Endpoints[2].Healthy)
+	require.Len(t, clientOpts, 3)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t,
+		fmt.Sprintf("%v %v Kvdb quorum is at risk: 2 of 3 endpoints are healthy. "+
+			"Unhealthy endpoints: etcd:http://kvdb2.com:2379",
+			v1.EventTypeWarning, util.KvdbQuorumAtRiskReason),
+		<-recorder.Events)
+
+	// TestCase: Do not raise the event again if nothing has changed
+	cluster.Status.Kvdb.LastCheckTime = &metav1.Time{Time: time.Now().Add(-2 * kvdbHealthCheckInterval)}
+
+	updateStatusWithKvdbCheck(t, &driver, cluster)
+
+	require.False(t, cluster.Status.Kvdb.Endpoints[1].Healthy)
+	require.Len(t, recorder.Events, 0)
+
+	// TestCase: Raise an event if the quorum is lost
+	down["http://kvdb3.com:2379"] = true
+	cluster.Status.Kvdb.LastCheckTime = &metav1.Time{Time: time.Now().Add(-2 * kvdbHealthCheckInterval)}
+
+	updateStatusWithKvdbCheck(t, &driver, cluster)
+
+	require.False(t, cluster.Status.Kvdb.Endpoints[2].Healthy)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t,
+		fmt.Sprintf("%v %v Kvdb has lost quorum: 1 of 3 endpoints are healthy. "+
+			"Unhealthy endpoints: etcd:http://kvdb2.com:2379, etcd:http://kvdb3.com:2379",
+			v1.EventTypeWarning, util.KvdbQuorumAtRiskReason),
+		<-recorder.Events)
+
+	// TestCase: Endpoints should be checked right away if they change
+	down = make(map[string]bool)
+	cluster.Spec.Kvdb.Endpoints = []string{"etcd:http://kvdb1.com:2379"}
+
+	updateStatusWithKvdbCheck(t, &driver, cluster)
+
+	require.Len(t, cluster.Status.Kvdb.Endpoints, 1)
+	require.True(t, cluster.Status.Kvdb.Endpoints[0].Healthy)
+	require.True(t, cluster.Status.Kvdb.Endpoints[0].Leader)
+	require.Len(t, recorder.Events, 0)
+
+	// TestCase: New clients should be created if the credentials change
+	secret := &v1.Secret{}
+	err := testutil.Get(k8sClient, secret, "kvdb-auth", "kube-test")
 	require.NoError(t, err)
+	secret.Data = map[string][]byte{
+		secretKeyKvdbACLToken: []byte("token"),
+	}
+	err = k8sClient.Update(context.TODO(), secret)
+	require.NoError(t, err)
+	cluster.Status.Kvdb.LastCheckTime = &metav1.Time{Time: time.Now().Add(-2 * kvdbHealthCheckInterval)}
+	clientOpts = nil
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
+	updateStatusWithKvdbCheck(t, &driver, cluster)
+
+	require.True(t, cluster.Status.Kvdb.Endpoints[0].Healthy)
+	require.Len(t, clientOpts, 1)
+	require.Equal(t, "token", clientOpts[0][kvdb.ACLTokenKey])
+	require.Empty(t, clientOpts[0][kvdb.UsernameKey])
+
+	// TestCase: Endpoints should be unhealthy if the credentials cannot be read
+	err = k8sClient.Delete(context.TODO(), secret)
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+	cluster.Status.Kvdb.LastCheckTime = &metav1.Time{Time: time.Now().Add(-2 * kvdbHealthCheckInterval)}
 
-	// TestCase: If latest node condition does not have status, phase should be Init
-	storageNodes.Items[0].Status.Conditions = []corev1alpha1.NodeCondition{
-		{
-			Type:   corev1alpha1.NodeInitCondition,
-			Status: corev1alpha1.NodeSucceededStatus,
+	updateStatusWithKvdbCheck(t, &driver, cluster)
+
+	require.False(t, cluster.Status.Kvdb.Endpoints[0].Healthy)
+	require.Contains(t, cluster.Status.Kvdb.Endpoints[0].Message, "failed to get kvdb auth secret")
+	require.Len(t, recorder.Events, 1)
+	<-recorder.Events
+
+	// TestCase: Remove the endpoints from the status when using internal kvdb
+	cluster.Spec.Kvdb = &corev1alpha1.KvdbSpec{Internal: true}
+
+	updateStatusWithKvdbCheck(t, &driver, cluster)
+
+	require.Empty(t, cluster.Status.Kvdb.Endpoints)
+	require.Nil(t, cluster.Status.Kvdb.LastCheckTime)
+	require.Empty(t, driver.kvdbHealth)
+}
+
+func TestUpdateClusterStatusWithExternalKvdbInBackground(t *testing.T) {
+	defer func() {
+		getKVDBVersion = kvdb.Version
+		newKVDB = kvdb.New
+	}()
+
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kvdb-auth",
+				Namespace: "kube-test-1",
+			},
+			Data: map[string][]byte{
+				secretKeyKvdbCert: []byte("cert-1"),
+			},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kvdb-auth",
+				Namespace: "kube-test-2",
+			},
+			Data: map[string][]byte{
+				secretKeyKvdbCert: []byte("cert-2"),
+			},
 		},
+	)
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  record.NewFakeRecorder(10),
 	}
-	k8sClient.Update(context.TODO(), &storageNodes.Items[0])
 
-	err = driver.UpdateStorageClusterStatus(cluster)
-	require.NoError(t, err)
+	newCluster := func(namespace, endpoint string) *corev1alpha1.StorageCluster {
+		return &corev1alpha1.StorageCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "px-cluster",
+				Namespace: namespace,
+			},
+			Spec: corev1alpha1.StorageClusterSpec{
+				Image: "test/image:1.2.3.4",
+				Kvdb: &corev1alpha1.KvdbSpec{
+					Endpoints:  []string{endpoint},
+					AuthSecret: "kvdb-auth",
+				},
+			},
+			Status: corev1alpha1.StorageClusterStatus{
+				Phase: "Initializing",
+			},
+		}
+	}
+	slowCluster := newCluster("kube-test-1", "etcd:http://kvdb1.com:2379")
+	otherCluster := newCluster("kube-test-2", "etcd:http://kvdb2.com:2379")
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
-	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+	var lock sync.Mutex
+	clientCerts := make(map[string]string)
+	release := make(chan struct{})
+	getKVDBVersion = func(_ string, url string, opts map[string]string) (string, error) {
+		return kvdb.EtcdVersion3, nil
+	}
+	newKVDB = func(_, prefix string, machines []string, opts map[string]string, _ kvdb.FatalErrorCB) (kvdb.Kvdb, error) {
+		if machines[0] == "http://kvdb1.com:2379" {
+			<-release
+		}
+		cert, err := ioutil.ReadFile(opts[kvdb.CertFileKey])
+		if err != nil {
+			return nil, err
+		}
+		lock.Lock()
+		clientCerts[machines[0]] = string(cert)
+		lock.Unlock()
+		return kvdb.New(mem.Name, prefix, machines, opts, dbg.Panicf)
+	}
 
-	// TestCase: If no condition present, phase should be Init
-	storageNodes.Items[0].Status.Conditions = []corev1alpha1.NodeCondition{}
-	k8sClient.Update(context.TODO(), &storageNodes.Items[0])
+	// TestCase: A slow kvdb should not block the status update
+	start := time.Now()
+	err := driver.UpdateStorageClusterStatus(slowCluster)
+	require.Error(t, err)
 
-	err = driver.UpdateStorageClusterStatus(cluster)
+	require.True(t, time.Since(start) < kvdbProbeTimeout)
+	require.Nil(t, slowCluster.Status.Kvdb)
+
+	// TestCase: Do not start another check while one is running
+	err = driver.UpdateStorageClusterStatus(slowCluster)
+	require.Error(t, err)
+
+	require.Nil(t, slowCluster.Status.Kvdb)
+
+	// TestCase: Clusters should be checked independently, with their own
+	// credentials written to their own directories
+	updateStatusWithKvdbCheck(t, &driver, otherCluster)
+
+	require.Len(t, otherCluster.Status.Kvdb.Endpoints, 1)
+	require.True(t, otherCluster.Status.Kvdb.Endpoints[0].Healthy)
+	require.Nil(t, slowCluster.Status.Kvdb)
+	require.Equal(t, "cert-2", clientCerts["http://kvdb2.com:2379"])
+
+	slowCertDir := driver.kvdbHealth[clusterKey(slowCluster)].certDir
+	otherCertDir := driver.kvdbHealth[clusterKey(otherCluster)].certDir
+	require.NotEmpty(t, slowCertDir)
+	require.NotEmpty(t, otherCertDir)
+	require.NotEqual(t, slowCertDir, otherCertDir)
+
+	// TestCase: Report the result of the slow check once it completes
+	close(release)
+	waitForKvdbCheck(t, &driver, slowCluster)
+
+	err = driver.UpdateStorageClusterStatus(slowCluster)
+	require.Error(t, err)
+
+	require.Len(t, slowCluster.Status.Kvdb.Endpoints, 1)
+	require.True(t, slowCluster.Status.Kvdb.Endpoints[0].Healthy)
+	require.Equal(t, "cert-1", clientCerts["http://kvdb1.com:2379"])
+
+	// TestCase: An endpoint should not be probed again until its previous
+	// probe returns
+	slowHealth := driver.kvdbHealth[clusterKey(slowCluster)]
+	slowHealth.lock.Lock()
+	require.Empty(t, slowHealth.probing)
+	slowHealth.probing = map[string]bool{"etcd:http://kvdb1.com:2379": true}
+	slowHealth.lock.Unlock()
+	slowCluster.Status.Kvdb.LastCheckTime = &metav1.Time{Time: time.Now().Add(-2 * kvdbHealthCheckInterval)}
+
+	updateStatusWithKvdbCheck(t, &driver, slowCluster)
+
+	require.False(t, slowCluster.Status.Kvdb.Endpoints[0].Healthy)
+	require.Equal(t, "Endpoint has not responded to a previous check",
+		slowCluster.Status.Kvdb.Endpoints[0].Message)
+
+	slowHealth.lock.Lock()
+	slowHealth.probing = nil
+	slowHealth.lock.Unlock()
+	slowCluster.Status.Kvdb.LastCheckTime = &metav1.Time{Time: time.Now().Add(-2 * kvdbHealthCheckInterval)}
+
+	updateStatusWithKvdbCheck(t, &driver, slowCluster)
+
+	require.True(t, slowCluster.Status.Kvdb.Endpoints[0].Healthy)
+
+	// TestCase: Deleting a cluster should only remove its own kvdb state
+	_, err = driver.DeleteStorage(slowCluster)
 	require.NoError(t, err)
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
+	require.NotContains(t, driver.kvdbHealth, clusterKey(slowCluster))
+	require.Contains(t, driver.kvdbHealth, clusterKey(otherCluster))
+	_, err = os.Stat(slowCertDir)
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(otherCertDir)
 	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
 
-	// TestCase: If conditions are nil, phase should be Init
-	storageNodes.Items[0].Status.Conditions = nil
-	k8sClient.Update(context.TODO(), &storageNodes.Items[0])
+	_, err = driver.DeleteStorage(otherCluster)
+	require.NoError(t, err)
+
+	require.Empty(t, driver.kvdbHealth)
+	_, err = os.Stat(otherCertDir)
+	require.True(t, os.IsNotExist(err))
+}
+
+// updateStatusWithKvdbCheck updates the cluster status, waits for the kvdb
+// check started by it, if any, and updates the status again to report it
+func updateStatusWithKvdbCheck(t *testing.T, driver *portworx, cluster *corev1alpha1.StorageCluster) {
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.Error(t, err)
+
+	waitForKvdbCheck(t, driver, cluster)
 
 	err = driver.UpdateStorageClusterStatus(cluster)
-	require.NoError(t, err)
+	require.Error(t, err)
+}
 
-	storageNodes = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodes)
-	require.NoError(t, err)
-	require.Len(t, storageNodes.Items, 1)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+func waitForKvdbCheck(t *testing.T, driver *portworx, cluster *corev1alpha1.StorageCluster) {
+	health, exists := driver.kvdbHealth[clusterKey(cluster)]
+	if !exists {
+		return
+	}
+	for i := 0; i < 100; i++ {
+		health.lock.Lock()
+		checking := health.checking
+		health.lock.Unlock()
+		if !checking {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.FailNow(t, "Timed out waiting for the kvdb check")
 }
 
-func TestUpdateClusterStatusWithoutSchedulerNodeName(t *testing.T) {
+func TestUpdateClusterStatusWithInternalKvdbMembers(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -2554,7 +3015,7 @@ func TestUpdateClusterStatusWithoutSchedulerNodeName(t *testing.T) {
 	// Create driver object with the fake k8s client
 	driver := portworx{
 		k8sClient: k8sClient,
-		recorder:  record.NewFakeRecorder(0),
+		recorder:  record.NewFakeRecorder(10),
 	}
 
 	cluster := &corev1alpha1.StorageCluster{
@@ -2562,165 +3023,2518 @@ func TestUpdateClusterStatusWithoutSchedulerNodeName(t *testing.T) {
 			Name:      "px-cluster",
 			Namespace: "kube-test",
 		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "test/image:1.2.3.4",
+			Kvdb: &corev1alpha1.KvdbSpec{
+				Internal: true,
+			},
+		},
 		Status: corev1alpha1.StorageClusterStatus{
 			Phase: "Initializing",
 		},
 	}
 
-	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
-		Cluster: &api.StorageCluster{},
-	}
+	// Mock cluster inspect response
 	mockClusterServer.EXPECT().
 		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
-		Return(expectedClusterResp, nil).
+		Return(&api.SdkClusterInspectCurrentResponse{Cluster: &api.StorageCluster{}}, nil).
 		AnyTimes()
 
-	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
-		Nodes: []*api.StorageNode{
-			{
-				Id:       "node-uid",
-				DataIp:   "1.1.1.1",
-				MgmtIp:   "2.2.2.2",
-				Hostname: "node-hostname",
+	// Mock node enumerate response
+	nodes := []*api.StorageNode{
+		{
+			Id:                "node-3",
+			SchedulerNodeName: "node-three",
+			NodeLabels: map[string]string{
+				labelPortworxKvdbMember: "true",
+			},
+		},
+		{
+			Id:                "node-2",
+			SchedulerNodeName: "node-two",
+		},
+		{
+			Id:                "node-1",
+			SchedulerNodeName: "node-one",
+			NodeLabels: map[string]string{
+				labelPortworxKvdbMember: "true",
 			},
 		},
 	}
 	mockNodeServer.EXPECT().
 		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
+		DoAndReturn(func(
+			_ context.Context,
+			_ *api.SdkNodeEnumerateWithFiltersRequest,
+		) (*api.SdkNodeEnumerateWithFiltersResponse, error) {
+			return &api.SdkNodeEnumerateWithFiltersResponse{Nodes: nodes}, nil
+		}).
 		AnyTimes()
 
-	// Fake a node object without matching ip address or hostname to storage node
-	coreops.SetInstance(
-		coreops.New(
-			fakek8sclient.NewSimpleClientset(&v1.Node{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "node-name",
-				},
-			}),
-		),
-	)
-
+	// TestCase: The StorageNodes running the internal kvdb should be listed
 	err := driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
 
-	nodeStatusList := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
-	require.NoError(t, err)
-	require.Empty(t, nodeStatusList.Items)
+	require.NotNil(t, cluster.Status.Kvdb)
+	require.Equal(t, []string{"node-one", "node-three"}, cluster.Status.Kvdb.Members)
+	require.Empty(t, cluster.Status.Kvdb.Endpoints)
 
-	// Fake a node object with matching data ip address
-	coreops.SetInstance(
-		coreops.New(
-			fakek8sclient.NewSimpleClientset(&v1.Node{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "node-name",
-				},
-				Status: v1.NodeStatus{
-					Addresses: []v1.NodeAddress{
-						{
-							Type:    v1.NodeInternalIP,
-							Address: "1.1.1.1",
-						},
-					},
-				},
-			}),
-		),
-	)
+	// TestCase: Members should be updated when the kvdb moves to other nodes
+	nodes[0].NodeLabels = nil
+	nodes[1].NodeLabels = map[string]string{labelPortworxKvdbMember: "true"}
 
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
 
-	nodeStatusList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
-	require.NoError(t, err)
-	require.Len(t, nodeStatusList.Items, 1)
-	require.Equal(t, "node-uid", nodeStatusList.Items[0].Status.NodeUID)
+	require.Equal(t, []string{"node-one", "node-two"}, cluster.Status.Kvdb.Members)
 
-	// Fake a node object with matching mgmt ip address
-	testutil.Delete(k8sClient, &nodeStatusList.Items[0])
-	coreops.SetInstance(
-		coreops.New(
-			fakek8sclient.NewSimpleClientset(&v1.Node{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "node-name",
-				},
-				Status: v1.NodeStatus{
-					Addresses: []v1.NodeAddress{
-						{
-							Type:    v1.NodeExternalIP,
-							Address: "2.2.2.2",
-						},
-					},
-				},
-			}),
-		),
-	)
+	// TestCase: Remove the kvdb status if there are no members
+	nodes[0].NodeLabels = nil
+	nodes[1].NodeLabels = nil
+	nodes[2].NodeLabels = nil
 
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
 
-	nodeStatusList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
+	require.Nil(t, cluster.Status.Kvdb)
+}
+
+func TestUpdateClusterStatusWithoutPortworxService(t *testing.T) {
+	// Fake client without service
+	k8sClient := testutil.FakeK8sClient()
+
+	driver := portworx{
+		k8sClient: k8sClient,
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	// TestCase: No storage nodes and portworx pods exist
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+
+	storageNodes := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
 	require.NoError(t, err)
-	require.Len(t, nodeStatusList.Items, 1)
-	require.Equal(t, "node-uid", nodeStatusList.Items[0].Status.NodeUID)
+	require.Empty(t, storageNodes.Items)
 
-	// Fake a node object with matching hostname
-	testutil.Delete(k8sClient, &nodeStatusList.Items[0])
-	coreops.SetInstance(
+	// TestCase: Portworx pods exist, but no storage nodes present
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	pod1 := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "portworx-pod-1",
+			Namespace:       cluster.Namespace,
+			Labels:          driver.GetSelectorLabels(),
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node-1",
+		},
+	}
+	k8sClient.Create(context.TODO(), pod1)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.Contains(t, err.Error(), "not found")
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Empty(t, storageNodes.Items)
+
+	// TestCase: Portworx pods and storage nodes both exist
+	storageNode1 := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-1",
+			Namespace: cluster.Namespace,
+		},
+	}
+	storageNode2 := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-2",
+			Namespace: cluster.Namespace,
+		},
+	}
+	k8sClient.Create(context.TODO(), storageNode1)
+	k8sClient.Create(context.TODO(), storageNode2)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.Contains(t, err.Error(), "not found")
+
+	// Delete extra nodes that do not have corresponding pods
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+}
+
+func TestUpdateClusterStatusServiceWithoutClusterIP(t *testing.T) {
+	// Fake client with a service that does not have cluster ip
+	k8sClient := testutil.FakeK8sClient(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.PortworxServiceName,
+			Namespace: "kube-test",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: "",
+		},
+	})
+
+	driver := portworx{
+		k8sClient: k8sClient,
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	// TestCase: No storage nodes and portworx pods exist
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to get endpoint")
+
+	storageNodes := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Empty(t, storageNodes.Items)
+
+	// TestCase: Portworx pod exist and storage node exist
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "portworx-pod-1",
+			Namespace:       cluster.Namespace,
+			Labels:          driver.GetSelectorLabels(),
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node-1",
+		},
+	}
+	storageNode := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-1",
+			Namespace: cluster.Namespace,
+		},
+	}
+	k8sClient.Create(context.TODO(), pod)
+	k8sClient.Create(context.TODO(), storageNode)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.Contains(t, err.Error(), "failed to get endpoint")
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+}
+
+func TestUpdateClusterStatusServiceGrpcServerError(t *testing.T) {
+	k8sClient := testutil.FakeK8sClient(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.PortworxServiceName,
+			Namespace: "kube-test",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: "127.0.0.1",
+		},
+	})
+
+	driver := portworx{
+		k8sClient: k8sClient,
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "portworx-pod-1",
+			Namespace:       cluster.Namespace,
+			Labels:          driver.GetSelectorLabels(),
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node-1",
+		},
+	}
+	storageNode := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-1",
+			Namespace: cluster.Namespace,
+		},
+	}
+	k8sClient.Create(context.TODO(), pod)
+	k8sClient.Create(context.TODO(), storageNode)
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error connecting to GRPC server")
+
+	storageNodes := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+}
+
+func TestUpdateClusterStatusInspectClusterFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.PortworxServiceName,
+			Namespace: "kube-test",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: sdkServerIP,
+			Ports: []v1.ServicePort{
+				{
+					Name: pxutil.PortworxSDKPortName,
+					Port: int32(sdkServerPort),
+				},
+			},
+		},
+	})
+
+	// Create driver object with the fake k8s client
+	driver := portworx{
+		k8sClient: k8sClient,
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "portworx-pod-1",
+			Namespace:       cluster.Namespace,
+			Labels:          driver.GetSelectorLabels(),
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node-1",
+		},
+	}
+	storageNode := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-1",
+			Namespace: cluster.Namespace,
+		},
+	}
+	k8sClient.Create(context.TODO(), pod)
+	k8sClient.Create(context.TODO(), storageNode)
+
+	// Error from InspectCurrent API
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(nil, fmt.Errorf("InspectCurrent error")).
+		Times(1)
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "InspectCurrent error")
+
+	storageNodes := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+
+	// Nil response from InspectCurrent API
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(nil, nil).
+		Times(1)
+
+	// Reset the storage node status
+	storageNode.Status = corev1alpha1.NodeStatus{}
+	k8sClient.Status().Update(context.TODO(), storageNode)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to inspect cluster")
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+
+	// Nil cluster object in the response of InspectCurrent API
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: nil,
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		Times(1)
+
+	// Reset the storage node status
+	storageNode.Status = corev1alpha1.NodeStatus{}
+	k8sClient.Status().Update(context.TODO(), storageNode)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "empty ClusterInspect response")
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+}
+
+func TestUpdateClusterStatusWithMultipleClusters(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Start a mock sdk server for each of the storage clusters
+	mockClusterServer1 := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer1 := mock.NewMockOpenStorageNodeServer(mockCtrl)
+	mockClusterServer2 := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer2 := mock.NewMockOpenStorageNodeServer(mockCtrl)
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort1 := 21883
+	sdkServerPort2 := 21884
+	mockSdk1 := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer1,
+		Node:    mockNodeServer1,
+	})
+	mockSdk1.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort1))
+	defer mockSdk1.Stop()
+	mockSdk2 := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer2,
+		Node:    mockNodeServer2,
+	})
+	mockSdk2.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort2))
+	defer mockSdk2.Stop()
+
+	pxService := func(namespace string, port int) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: namespace,
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(port),
+					},
+				},
+			},
+		}
+	}
+	k8sClient := testutil.FakeK8sClient(
+		pxService("kube-test-1", sdkServerPort1),
+		pxService("kube-test-2", sdkServerPort2),
+	)
+
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	cluster1 := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster-1",
+			Namespace: "kube-test-1",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+	cluster2 := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster-2",
+			Namespace: "kube-test-2",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	mockClusterServer1.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(&api.SdkClusterInspectCurrentResponse{
+			Cluster: &api.StorageCluster{
+				Name:   "px-cluster-1",
+				Id:     "cluster-uid-1",
+				Status: api.Status_STATUS_OK,
+			},
+		}, nil).
+		Times(2)
+	mockNodeServer1.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{}, nil).
+		Times(2)
+	mockClusterServer2.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(&api.SdkClusterInspectCurrentResponse{
+			Cluster: &api.StorageCluster{
+				Name:   "px-cluster-2",
+				Id:     "cluster-uid-2",
+				Status: api.Status_STATUS_OK,
+			},
+		}, nil).
+		Times(2)
+	mockNodeServer2.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{}, nil).
+		Times(2)
+
+	// TestCase: Each cluster should be reached through its own connection
+	err := driver.UpdateStorageClusterStatus(cluster1)
+	require.NoError(t, err)
+	require.Equal(t, "cluster-uid-1", cluster1.Status.ClusterUID)
+
+	err = driver.UpdateStorageClusterStatus(cluster2)
+	require.NoError(t, err)
+	require.Equal(t, "cluster-uid-2", cluster2.Status.ClusterUID)
+
+	require.Len(t, driver.sdkConns, 2)
+	sdkConn1 := driver.sdkConns[clusterKey(cluster1)].conn
+	sdkConn2 := driver.sdkConns[clusterKey(cluster2)].conn
+	require.NotEqual(t, sdkConn1, sdkConn2)
+
+	// TestCase: The connections should be reused when the clusters are
+	// updated alternately
+	err = driver.UpdateStorageClusterStatus(cluster1)
+	require.NoError(t, err)
+	require.Equal(t, "cluster-uid-1", cluster1.Status.ClusterUID)
+	require.Equal(t, sdkConn1, driver.sdkConns[clusterKey(cluster1)].conn)
+
+	err = driver.UpdateStorageClusterStatus(cluster2)
+	require.NoError(t, err)
+	require.Equal(t, "cluster-uid-2", cluster2.Status.ClusterUID)
+	require.Equal(t, sdkConn2, driver.sdkConns[clusterKey(cluster2)].conn)
+
+	// TestCase: A failure in one cluster should only close its own connection
+	mockClusterServer1.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(nil, fmt.Errorf("InspectCurrent error")).
+		Times(1)
+
+	err = driver.UpdateStorageClusterStatus(cluster1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "InspectCurrent error")
+	require.NotContains(t, driver.sdkConns, clusterKey(cluster1))
+	require.Equal(t, sdkConn2, driver.sdkConns[clusterKey(cluster2)].conn)
+
+	// TestCase: Deleting a cluster should close its connection
+	_, err = driver.DeleteStorage(cluster2)
+	require.NoError(t, err)
+	require.Empty(t, driver.sdkConns)
+}
+
+func TestUpdateClusterStatusEnumerateNodesFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.PortworxServiceName,
+			Namespace: "kube-test",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: sdkServerIP,
+			Ports: []v1.ServicePort{
+				{
+					Name: pxutil.PortworxSDKPortName,
+					Port: int32(sdkServerPort),
+				},
+			},
+		},
+	})
+
+	// Create driver object with the fake k8s client
+	driver := portworx{
+		k8sClient: k8sClient,
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "portworx-pod-1",
+			Namespace:       cluster.Namespace,
+			Labels:          driver.GetSelectorLabels(),
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node-1",
+		},
+	}
+	storageNode := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-1",
+			Namespace: cluster.Namespace,
+		},
+	}
+	k8sClient.Create(context.TODO(), pod)
+	k8sClient.Create(context.TODO(), storageNode)
+
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
+
+	// TestCase: Error from node Enumerate API
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nil, fmt.Errorf("node Enumerate error")).
+		Times(1)
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "node Enumerate error")
+
+	storageNodes := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: Nil response from node Enumerate API
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nil, nil).
+		Times(1)
+
+	// Reset the storage node status
+	storageNode.Status = corev1alpha1.NodeStatus{}
+	k8sClient.Status().Update(context.TODO(), storageNode)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to enumerate nodes")
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: Empty list of nodes should create StorageNode objects only for matching pods
+	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	// Reset the storage node status
+	storageNode.Status = corev1alpha1.NodeStatus{}
+	k8sClient.Status().Update(context.TODO(), storageNode)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: Nil list of nodes should create StorageNode objects only for matching pods
+	expectedNodeEnumerateResp.Nodes = nil
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	// Reset the storage node status
+	storageNode.Status = corev1alpha1.NodeStatus{}
+	k8sClient.Status().Update(context.TODO(), storageNode)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: Empty list of nodes should not create any StorageNode objects
+	expectedNodeEnumerateResp = &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	k8sClient.Delete(context.TODO(), pod)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	nodeStatusList := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Empty(t, nodeStatusList.Items)
+
+	// TestCase: Nil list of nodes should not create any StorageNode objects
+	expectedNodeEnumerateResp.Nodes = nil
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	nodeStatusList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Empty(t, nodeStatusList.Items)
+}
+
+func TestUpdateClusterStatusShouldUpdateStatusIfChanged(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.PortworxServiceName,
+			Namespace: "kube-test",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: sdkServerIP,
+			Ports: []v1.ServicePort{
+				{
+					Name: pxutil.PortworxSDKPortName,
+					Port: int32(sdkServerPort),
+				},
+			},
+		},
+	})
+
+	// Create driver object with the fake k8s client
+	driver := portworx{
+		k8sClient: k8sClient,
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{
+			Status: api.Status_STATUS_ERROR,
+		},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		Times(1)
+
+	expectedNode := &api.StorageNode{
+		Id:                "node-1",
+		SchedulerNodeName: "node-one",
+		DataIp:            "1.1.1.1",
+		Status:            api.Status_STATUS_MAINTENANCE,
+	}
+	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{expectedNode},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	require.Equal(t, "Offline", cluster.Status.Phase)
+	nodeStatusList := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Len(t, nodeStatusList.Items, 1)
+	require.Equal(t, "node-1", nodeStatusList.Items[0].Status.NodeUID)
+	require.Equal(t, corev1alpha1.NodeMaintenanceStatus, nodeStatusList.Items[0].Status.Conditions[0].Status)
+	require.Equal(t, "1.1.1.1", nodeStatusList.Items[0].Status.Network.DataIP)
+
+	// Update status based on the latest object
+	expectedClusterResp.Cluster.Status = api.Status_STATUS_OK
+	expectedNode.Status = api.Status_STATUS_OK
+	expectedNode.DataIp = "2.2.2.2"
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		Times(1)
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	require.Equal(t, "Online", cluster.Status.Phase)
+	nodeStatusList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Len(t, nodeStatusList.Items, 1)
+	require.Equal(t, "node-1", nodeStatusList.Items[0].Status.NodeUID)
+	require.Equal(t, corev1alpha1.NodeOnlineStatus, nodeStatusList.Items[0].Status.Conditions[0].Status)
+	require.Equal(t, "2.2.2.2", nodeStatusList.Items[0].Status.Network.DataIP)
+}
+
+func TestUpdateClusterStatusShouldUpdateNodePhaseBasedOnConditions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.PortworxServiceName,
+			Namespace: "kube-test",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: sdkServerIP,
+			Ports: []v1.ServicePort{
+				{
+					Name: pxutil.PortworxSDKPortName,
+					Port: int32(sdkServerPort),
+				},
+			},
+		},
+	})
+
+	driver := portworx{
+		k8sClient: k8sClient,
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
+
+	// TestCases: Portworx node present in SDK output
+	// TestCase: Node does not have any condition, node state condition will be
+	// used to populate the node phase
+	expectedNode := &api.StorageNode{
+		Id:                "node-1",
+		SchedulerNodeName: "node-one",
+		DataIp:            "1.1.1.1",
+		Status:            api.Status_STATUS_MAINTENANCE,
+	}
+	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{expectedNode},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeMaintenanceStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: Node has another condition which is newer than when the node state
+	// was last transitioned
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	// Sleep is needed because fake k8s client stores the timestamp at sec granularity
+	time.Sleep(time.Second)
+	operatorops.Instance().UpdateStorageNodeCondition(
+		&storageNodes.Items[0].Status,
+		&corev1alpha1.NodeCondition{
+			Type:   corev1alpha1.NodeInitCondition,
+			Status: corev1alpha1.NodeFailedStatus,
+		},
+	)
+	k8sClient.Status().Update(context.TODO(), &storageNodes.Items[0])
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeFailedStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: Node state has transitioned
+	expectedNode.Status = api.Status_STATUS_DECOMMISSION
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	time.Sleep(time.Second)
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeDecommissionedStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCases: Portworx node not present in SDK output, but matching pod present
+	// TestCase: If latest node condition is not Init or Failed (here it is Decommissioned)
+	expectedNodeEnumerateResp = &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		AnyTimes()
+
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "portworx-pod-1",
+			Namespace:       cluster.Namespace,
+			Labels:          driver.GetSelectorLabels(),
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node-one",
+		},
+	}
+	k8sClient.Create(context.TODO(), pod)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeUnknownStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: If latest node condition is Failed then phase should be Failed
+	storageNodes.Items[0].Status.Conditions = []corev1alpha1.NodeCondition{
+		{
+			Type:   corev1alpha1.NodeStateCondition,
+			Status: corev1alpha1.NodeFailedStatus,
+		},
+	}
+	k8sClient.Update(context.TODO(), &storageNodes.Items[0])
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeFailedStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: If latest node condition is NodeInit with succeeded status
+	// then phase should be Init
+	storageNodes.Items[0].Status.Conditions = []corev1alpha1.NodeCondition{
+		{
+			Type:   corev1alpha1.NodeInitCondition,
+			Status: corev1alpha1.NodeSucceededStatus,
+		},
+	}
+	k8sClient.Update(context.TODO(), &storageNodes.Items[0])
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: If latest node condition does not have status, phase should be Init
+	storageNodes.Items[0].Status.Conditions = []corev1alpha1.NodeCondition{
+		{
+			Type:   corev1alpha1.NodeInitCondition,
+			Status: corev1alpha1.NodeSucceededStatus,
+		},
+	}
+	k8sClient.Update(context.TODO(), &storageNodes.Items[0])
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: If no condition present, phase should be Init
+	storageNodes.Items[0].Status.Conditions = []corev1alpha1.NodeCondition{}
+	k8sClient.Update(context.TODO(), &storageNodes.Items[0])
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+
+	// TestCase: If conditions are nil, phase should be Init
+	storageNodes.Items[0].Status.Conditions = nil
+	k8sClient.Update(context.TODO(), &storageNodes.Items[0])
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodes = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodes.Items[0].Status.Phase)
+}
+
+func TestUpdateClusterStatusWithoutSchedulerNodeName(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.PortworxServiceName,
+			Namespace: "kube-test",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: sdkServerIP,
+			Ports: []v1.ServicePort{
+				{
+					Name: pxutil.PortworxSDKPortName,
+					Port: int32(sdkServerPort),
+				},
+			},
+		},
+	})
+
+	// Create driver object with the fake k8s client
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  record.NewFakeRecorder(0),
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
+
+	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{
+			{
+				Id:       "node-uid",
+				DataIp:   "1.1.1.1",
+				MgmtIp:   "2.2.2.2",
+				Hostname: "node-hostname",
+			},
+		},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		AnyTimes()
+
+	// Fake a node object without matching ip address or hostname to storage node
+	coreops.SetInstance(
+		coreops.New(
+			fakek8sclient.NewSimpleClientset(&v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node-name",
+				},
+			}),
+		),
+	)
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	nodeStatusList := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Empty(t, nodeStatusList.Items)
+
+	// Fake a node object with matching data ip address
+	coreops.SetInstance(
+		coreops.New(
+			fakek8sclient.NewSimpleClientset(&v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node-name",
+				},
+				Status: v1.NodeStatus{
+					Addresses: []v1.NodeAddress{
+						{
+							Type:    v1.NodeInternalIP,
+							Address: "1.1.1.1",
+						},
+					},
+				},
+			}),
+		),
+	)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	nodeStatusList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Len(t, nodeStatusList.Items, 1)
+	require.Equal(t, "node-uid", nodeStatusList.Items[0].Status.NodeUID)
+
+	// Fake a node object with matching mgmt ip address
+	testutil.Delete(k8sClient, &nodeStatusList.Items[0])
+	coreops.SetInstance(
+		coreops.New(
+			fakek8sclient.NewSimpleClientset(&v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node-name",
+				},
+				Status: v1.NodeStatus{
+					Addresses: []v1.NodeAddress{
+						{
+							Type:    v1.NodeExternalIP,
+							Address: "2.2.2.2",
+						},
+					},
+				},
+			}),
+		),
+	)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	nodeStatusList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Len(t, nodeStatusList.Items, 1)
+	require.Equal(t, "node-uid", nodeStatusList.Items[0].Status.NodeUID)
+
+	// Fake a node object with matching hostname
+	testutil.Delete(k8sClient, &nodeStatusList.Items[0])
+	coreops.SetInstance(
+		coreops.New(
+			fakek8sclient.NewSimpleClientset(&v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node-name",
+				},
+				Status: v1.NodeStatus{
+					Addresses: []v1.NodeAddress{
+						{
+							Type:    v1.NodeHostName,
+							Address: "node-hostname",
+						},
+					},
+				},
+			}),
+		),
+	)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	nodeStatusList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Len(t, nodeStatusList.Items, 1)
+	require.Equal(t, "node-uid", nodeStatusList.Items[0].Status.NodeUID)
+
+	// Fake a node object with matching hostname from labels
+	testutil.Delete(k8sClient, &nodeStatusList.Items[0])
+	coreops.SetInstance(
 		coreops.New(
 			fakek8sclient.NewSimpleClientset(&v1.Node{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "node-name",
+					Labels: map[string]string{
+						"kubernetes.io/hostname": "node-hostname",
+					},
+				},
+			}),
+		),
+	)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	nodeStatusList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Len(t, nodeStatusList.Items, 1)
+	require.Equal(t, "node-uid", nodeStatusList.Items[0].Status.NodeUID)
+}
+
+func TestUpdateClusterStatusShouldDeleteStorageNodeForNonExistingNodes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.PortworxServiceName,
+			Namespace: "kube-test",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: sdkServerIP,
+			Ports: []v1.ServicePort{
+				{
+					Name: pxutil.PortworxSDKPortName,
+					Port: int32(sdkServerPort),
+				},
+			},
+		},
+	})
+
+	// Create driver object with the fake k8s client
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{
+			Status: api.Status_STATUS_OK,
+		},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
+
+	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{
+			{
+				Id:                "node-1",
+				SchedulerNodeName: "node-one",
+			},
+			{
+				Id:                "node-2",
+				SchedulerNodeName: "node-two",
+			},
+		},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	nodeStatusList := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Len(t, nodeStatusList.Items, 2)
+
+	// Node got removed from storage driver
+	expectedNodeEnumerateResp = &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{
+			{
+				Id:                "node-2",
+				SchedulerNodeName: "node-two",
+			},
+		},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	nodeStatusList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Len(t, nodeStatusList.Items, 1)
+	require.Equal(t, "node-2", nodeStatusList.Items[0].Status.NodeUID)
+}
+
+func TestUpdateClusterStatusShouldNotDeleteStorageNodesOfOtherClusters(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+			UID:       "px-cluster-uid",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+	otherCluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other-cluster",
+			Namespace: "kube-test",
+			UID:       "other-cluster-uid",
+		},
+	}
+	clusterRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	otherClusterRef := metav1.NewControllerRef(otherCluster, pxutil.StorageClusterKind())
+
+	// StorageNodes that are not running Portworx for the current cluster
+	orphanNode := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "node-orphan",
+			Namespace:       cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*clusterRef},
+			Finalizers:      []string{storagecluster.StorageNodeFinalizer},
+		},
+	}
+	otherClusterNode := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "node-other-cluster",
+			Namespace:       cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*otherClusterRef},
+		},
+	}
+	otherNamespaceNode := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node-other-namespace",
+			Namespace: "other-ns",
+		},
+	}
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test",
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
+				},
+			},
+		},
+		orphanNode, otherClusterNode, otherNamespaceNode,
+	)
+
+	// Create driver object with the fake k8s client
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{
+			Status: api.Status_STATUS_OK,
+		},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
+
+	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{
+			{
+				Id:                "node-1",
+				SchedulerNodeName: "node-one",
+			},
+		},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	// Only the orphan StorageNode of the current cluster should be removed
+	nodeStatusList := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	require.Len(t, nodeStatusList.Items, 3)
+	nodeNames := make([]string, 0)
+	for _, storageNode := range nodeStatusList.Items {
+		nodeNames = append(nodeNames, storageNode.Name)
+	}
+	require.ElementsMatch(t,
+		[]string{"node-one", "node-other-cluster", "node-other-namespace"},
+		nodeNames)
+}
+
+func TestUpdateClusterStatusShouldNotDeleteStorageNodeIfPodExists(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.PortworxServiceName,
+			Namespace: "kube-test",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: sdkServerIP,
+			Ports: []v1.ServicePort{
+				{
+					Name: pxutil.PortworxSDKPortName,
+					Port: int32(sdkServerPort),
+				},
+			},
+		},
+	})
+
+	// Create driver object with the fake k8s client
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{
+			Status: api.Status_STATUS_OK,
+		},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
+
+	nodeEnumerateRespWithAllNodes := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{
+			{
+				Id:                "node-1",
+				SchedulerNodeName: "node-one",
+				Status:            api.Status_STATUS_OK,
+			},
+			{
+				Id:                "node-2",
+				SchedulerNodeName: "node-two",
+			},
+		},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithAllNodes, nil).
+		Times(1)
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodeList := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodeList)
+	require.NoError(t, err)
+	require.Len(t, storageNodeList.Items, 2)
+	require.Equal(t, string(corev1alpha1.NodeOnlineStatus), storageNodeList.Items[0].Status.Phase)
+
+	// TestCase: Node got removed from portworx sdk response, but corresponding pod exists
+	nodeEnumerateRespWithOneNode := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{
+			{
+				Id:                "node-2",
+				SchedulerNodeName: "node-two",
+			},
+		},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithOneNode, nil).
+		Times(1)
+
+	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+	nodeOnePod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "portworx-pod-1",
+			Namespace:       cluster.Namespace,
+			Labels:          driver.GetSelectorLabels(),
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node-one",
+		},
+	}
+	k8sClient.Create(context.TODO(), nodeOnePod)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodeList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodeList)
+	require.NoError(t, err)
+	require.Len(t, storageNodeList.Items, 2)
+	require.Equal(t, string(corev1alpha1.NodeUnknownStatus), storageNodeList.Items[0].Status.Phase)
+
+	// TestCase: Node is not present in portworx sdk response, corresponding pod exists,
+	// but portworx is in Initializing state; should remain in Initializing state.
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithOneNode, nil).
+		Times(1)
+
+	// No conditions means the node is initializing state
+	storageNodeList.Items[0].Status.Conditions = nil
+	k8sClient.Update(context.TODO(), &storageNodeList.Items[0])
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodeList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodeList)
+	require.NoError(t, err)
+	require.Len(t, storageNodeList.Items, 2)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodeList.Items[0].Status.Phase)
+
+	// TestCase: Node is not present in portworx sdk response, corresponding pod exists,
+	// but portworx is in Failed state; should remain in Failed state.
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithOneNode, nil).
+		Times(1)
+
+	// Latest condition is in failed state
+	storageNodeList.Items[0].Status.Conditions = []corev1alpha1.NodeCondition{
+		{
+			Type:   corev1alpha1.NodeInitCondition,
+			Status: corev1alpha1.NodeFailedStatus,
+		},
+	}
+	k8sClient.Update(context.TODO(), &storageNodeList.Items[0])
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodeList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodeList)
+	require.NoError(t, err)
+	require.Len(t, storageNodeList.Items, 2)
+	require.Equal(t, string(corev1alpha1.NodeFailedStatus), storageNodeList.Items[0].Status.Phase)
+
+	// TestCase: Node is not present in portworx sdk response, but pod labels do not match
+	// that of portworx pods
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithOneNode, nil).
+		Times(1)
+
+	nodeOnePod.Labels = nil
+	k8sClient.Update(context.TODO(), nodeOnePod)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodeList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodeList)
+	require.NoError(t, err)
+	require.Len(t, storageNodeList.Items, 1)
+
+	// TestCase: Node is not present in portworx sdk response, and pod does not
+	// have correct owner references
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithAllNodes, nil).
+		Times(1)
+	nodeOnePod.Labels = driver.GetSelectorLabels()
+	k8sClient.Update(context.TODO(), nodeOnePod)
+
+	driver.UpdateStorageClusterStatus(cluster)
+
+	storageNodeList = &corev1alpha1.StorageNodeList{}
+	testutil.List(k8sClient, storageNodeList)
+	require.Len(t, storageNodeList.Items, 2)
+
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithOneNode, nil).
+		Times(1)
+	nodeOnePod.OwnerReferences[0].UID = types.UID("dummy")
+	k8sClient.Update(context.TODO(), nodeOnePod)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodeList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodeList)
+	require.NoError(t, err)
+	require.Len(t, storageNodeList.Items, 1)
+
+	// TestCase: Node is not present in portworx sdk response, and pod does not
+	// have correct node name
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithAllNodes, nil).
+		Times(1)
+	nodeOnePod.OwnerReferences = []metav1.OwnerReference{*ownerRef}
+	k8sClient.Update(context.TODO(), nodeOnePod)
+
+	driver.UpdateStorageClusterStatus(cluster)
+
+	storageNodeList = &corev1alpha1.StorageNodeList{}
+	testutil.List(k8sClient, storageNodeList)
+	require.Len(t, storageNodeList.Items, 2)
+
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithOneNode, nil).
+		Times(1)
+	nodeOnePod.Spec.NodeName = "dummy"
+	k8sClient.Update(context.TODO(), nodeOnePod)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodeList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodeList)
+	require.NoError(t, err)
+	require.Len(t, storageNodeList.Items, 1)
+
+	// TestCase: Node is not present in portworx sdk response, and pod does not
+	// exist in cluster's namespace
+	k8sClient.Delete(context.TODO(), nodeOnePod)
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithAllNodes, nil).
+		Times(1)
+
+	driver.UpdateStorageClusterStatus(cluster)
+
+	storageNodeList = &corev1alpha1.StorageNodeList{}
+	testutil.List(k8sClient, storageNodeList)
+	require.Len(t, storageNodeList.Items, 2)
+
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nodeEnumerateRespWithOneNode, nil).
+		Times(1)
+	nodeOnePod.Namespace = "dummy"
+	k8sClient.Create(context.TODO(), nodeOnePod)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	storageNodeList = &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodeList)
+	require.NoError(t, err)
+	require.Len(t, storageNodeList.Items, 1)
+}
+
+func TestUpdateClusterStatusShouldNotDeleteStorageNodeIfPreflightPending(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+	createPreflightStorageNode := func(name string, status corev1alpha1.NodeConditionStatus) *corev1alpha1.StorageNode {
+		return &corev1alpha1.StorageNode{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cluster.Namespace,
+			},
+			Status: corev1alpha1.NodeStatus{
+				Conditions: []corev1alpha1.NodeCondition{
+					{
+						Type:   corev1alpha1.NodePreflightCondition,
+						Status: status,
+					},
+				},
+			},
+		}
+	}
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test",
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
+				},
+			},
+		},
+		createPreflightStorageNode("node-running-checks", corev1alpha1.NodeInitStatus),
+		createPreflightStorageNode("node-failed-checks", corev1alpha1.NodeFailedStatus),
+		createPreflightStorageNode("node-passed-checks", corev1alpha1.NodeSucceededStatus),
+		createPreflightStorageNode("node-skipped-checks", corev1alpha1.NodeSkippedStatus),
+	)
+
+	// Create driver object with the fake k8s client
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{
+			Status: api.Status_STATUS_OK,
+		},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
+
+	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{
+			{
+				Id:                "node-1",
+				SchedulerNodeName: "node-one",
+			},
+		},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(expectedNodeEnumerateResp, nil).
+		Times(1)
+
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+
+	// StorageNodes waiting for the preflight checks to pass should not be
+	// deleted, as the portworx pods have not been created on them yet
+	nodeStatusList := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, nodeStatusList)
+	require.NoError(t, err)
+	nodeNames := make([]string, 0)
+	for _, storageNode := range nodeStatusList.Items {
+		nodeNames = append(nodeNames, storageNode.Name)
+	}
+	require.ElementsMatch(t, []string{"node-one", "node-running-checks", "node-failed-checks"}, nodeNames)
+}
+
+func TestUpdateClusterStatusWithNodeMaintenance(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+			UID:       "px-cluster-uid",
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+	clusterRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test",
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
 				},
-				Status: v1.NodeStatus{
-					Addresses: []v1.NodeAddress{
-						{
-							Type:    v1.NodeHostName,
-							Address: "node-hostname",
-						},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "px-pod",
+				Namespace:       "kube-test",
+				Labels:          pxutil.SelectorLabels(),
+				OwnerReferences: []metav1.OwnerReference{*clusterRef},
+			},
+			Spec: v1.PodSpec{
+				NodeName: "node-one",
+			},
+		},
+		&corev1alpha1.StorageNode{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "node-one",
+				Namespace:       "kube-test",
+				OwnerReferences: []metav1.OwnerReference{*clusterRef},
+			},
+			Spec: corev1alpha1.StorageNodeSpec{
+				Maintenance: true,
+			},
+		},
+	)
+
+	// Create driver object with the fake k8s client
+	recorder := record.NewFakeRecorder(10)
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  recorder,
+	}
+
+	var commands [][]string
+	var commandErr error
+	runCommandInPod = func(cmds []string, podName, containerName, namespace string) (string, error) {
+		require.Equal(t, "px-pod", podName)
+		require.Equal(t, pxContainerName, containerName)
+		require.Equal(t, "kube-test", namespace)
+		commands = append(commands, cmds)
+		return "", commandErr
+	}
+	defer func() {
+		runCommandInPod = func(cmds []string, podName, containerName, namespace string) (string, error) {
+			return coreops.Instance().RunCommandInPod(cmds, podName, containerName, namespace)
+		}
+	}()
+
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{
+			Status: api.Status_STATUS_OK,
+		},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
+
+	pxNode := &api.StorageNode{
+		Id:                "node-1",
+		SchedulerNodeName: "node-one",
+		Status:            api.Status_STATUS_OK,
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{Nodes: []*api.StorageNode{pxNode}}, nil).
+		AnyTimes()
+
+	getMaintenanceCondition := func() *corev1alpha1.NodeCondition {
+		storageNode := &corev1alpha1.StorageNode{}
+		err := testutil.Get(k8sClient, storageNode, "node-one", "kube-test")
+		require.NoError(t, err)
+		for _, condition := range storageNode.Status.Conditions {
+			if condition.Type == corev1alpha1.NodeMaintenanceCondition {
+				return condition.DeepCopy()
+			}
+		}
+		return nil
+	}
+	enterCommand := []string{pxctlPath, "service", "maintenance", "--enter", "-y"}
+	exitCommand := []string{pxctlPath, "service", "maintenance", "--exit", "-y"}
+
+	// TestCase: Node should be asked to enter maintenance mode
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{enterCommand}, commands)
+	condition := getMaintenanceCondition()
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.NodeEnteringMaintenanceStatus, condition.Status)
+
+	// TestCase: Operator should wait for the node to enter maintenance mode
+	// without running the command again
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+	require.Equal(t, corev1alpha1.NodeEnteringMaintenanceStatus, getMaintenanceCondition().Status)
+
+	// TestCase: Condition should reflect that the node is in maintenance mode
+	pxNode.Status = api.Status_STATUS_MAINTENANCE
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+	require.Equal(t, corev1alpha1.NodeMaintenanceStatus, getMaintenanceCondition().Status)
+	storageNode := &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, "node-one", "kube-test")
+	require.NoError(t, err)
+	require.Equal(t, string(corev1alpha1.NodeMaintenanceStatus), storageNode.Status.Phase)
+
+	// TestCase: Node should be asked to exit maintenance mode when the
+	// maintenance flag is removed from the spec
+	storageNode.Spec.Maintenance = false
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{enterCommand, exitCommand}, commands)
+	require.Equal(t, corev1alpha1.NodeExitingMaintenanceStatus, getMaintenanceCondition().Status)
+
+	// TestCase: Condition should reflect that the node is out of maintenance mode
+	pxNode.Status = api.Status_STATUS_OK
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+	require.Equal(t, corev1alpha1.NodeOnlineStatus, getMaintenanceCondition().Status)
+
+	// TestCase: Node put in maintenance mode outside of the operator
+	// should not be taken out of maintenance mode
+	pxNode.Status = api.Status_STATUS_MAINTENANCE
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+	require.Equal(t, corev1alpha1.NodeOnlineStatus, getMaintenanceCondition().Status)
+
+	// TestCase: Failure to enter maintenance mode should be reported
+	// in the condition and as an event
+	pxNode.Status = api.Status_STATUS_OK
+	commandErr = fmt.Errorf("pxctl error")
+	err = testutil.Get(k8sClient, storageNode, "node-one", "kube-test")
+	require.NoError(t, err)
+	storageNode.Spec.Maintenance = true
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 3)
+	condition = getMaintenanceCondition()
+	require.Equal(t, corev1alpha1.NodeFailedStatus, condition.Status)
+	require.Contains(t, condition.Message, "Failed to enter maintenance mode")
+	require.Contains(t, condition.Message, "pxctl error")
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedMaintenanceReason))
+
+	// TestCase: Failed command should be retried, without raising
+	// another event if it fails the same way
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 4)
+	require.Equal(t, corev1alpha1.NodeFailedStatus, getMaintenanceCondition().Status)
+	require.Len(t, recorder.Events, 0)
+
+	// TestCase: Failure should be reported if the portworx pod is not
+	// running on the node
+	commandErr = nil
+	err = testutil.Delete(k8sClient, &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-pod",
+			Namespace: "kube-test",
+		},
+	})
+	require.NoError(t, err)
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 4)
+	condition = getMaintenanceCondition()
+	require.Equal(t, corev1alpha1.NodeFailedStatus, condition.Status)
+	require.Contains(t, condition.Message, "portworx pod not found on node node-one")
+}
+
+func TestUpdateClusterStatusWithPoolExpansion(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+			UID:       "px-cluster-uid",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			CloudStorage: &corev1alpha1.CloudStorageSpec{
+				CapacitySpecs: []corev1alpha1.CloudStorageCapacitySpec{
+					{
+						MaxCapacityInGiB: 400,
+						PoolSizeInGiB:    150,
 					},
 				},
-			}),
-		),
+			},
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+	clusterRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test",
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
+				},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "px-pod",
+				Namespace:       "kube-test",
+				Labels:          pxutil.SelectorLabels(),
+				OwnerReferences: []metav1.OwnerReference{*clusterRef},
+			},
+			Spec: v1.PodSpec{
+				NodeName: "node-one",
+			},
+		},
 	)
 
+	// Create driver object with the fake k8s client
+	recorder := record.NewFakeRecorder(10)
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  recorder,
+	}
+
+	var commands [][]string
+	var commandErr error
+	runCommandInPod = func(cmds []string, podName, containerName, namespace string) (string, error) {
+		require.Equal(t, "px-pod", podName)
+		require.Equal(t, pxContainerName, containerName)
+		require.Equal(t, "kube-test", namespace)
+		commands = append(commands, cmds)
+		if cmds[2] == "pool" && cmds[3] == "show" {
+			return `{"datapools":[{"poolID":0,"uuid":"pool-uuid-0"},{"poolID":1,"uuid":"pool-uuid-1"}]}`, nil
+		}
+		return "", commandErr
+	}
+	defer func() {
+		runCommandInPod = func(cmds []string, podName, containerName, namespace string) (string, error) {
+			return coreops.Instance().RunCommandInPod(cmds, podName, containerName, namespace)
+		}
+	}()
+
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{
+			Status: api.Status_STATUS_OK,
+		},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
+
+	pxNodeOne := &api.StorageNode{
+		Id:                "node-1",
+		SchedulerNodeName: "node-one",
+		Status:            api.Status_STATUS_OK,
+		Pools: []*api.StoragePool{
+			{ID: 0, TotalSize: 100 * bytesPerGiB},
+			{ID: 1, TotalSize: 50 * bytesPerGiB},
+		},
+	}
+	pxNodeTwo := &api.StorageNode{
+		Id:                "node-2",
+		SchedulerNodeName: "node-two",
+		Status:            api.Status_STATUS_OK,
+		Pools: []*api.StoragePool{
+			{ID: 0, TotalSize: 150 * bytesPerGiB},
+		},
+	}
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{
+			Nodes: []*api.StorageNode{pxNodeOne, pxNodeTwo},
+		}, nil).
+		AnyTimes()
+
+	getPoolExpansionCondition := func(nodeName string) *corev1alpha1.NodeCondition {
+		storageNode := &corev1alpha1.StorageNode{}
+		err := testutil.Get(k8sClient, storageNode, nodeName, "kube-test")
+		require.NoError(t, err)
+		for _, condition := range storageNode.Status.Conditions {
+			if condition.Type == corev1alpha1.NodePoolExpansionCondition {
+				return condition.DeepCopy()
+			}
+		}
+		return nil
+	}
+	showCommand := []string{pxctlPath, "service", "pool", "show", "-j"}
+	expandCommand := func(uuid, size, operation string) []string {
+		return []string{pxctlPath, "service", "pool", "expand",
+			"--uid", uuid, "--size", size, "--operation", operation}
+	}
+
+	// TestCase: Pool should be expanded to the size requested in the capacity spec
+	err := driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{showCommand, expandCommand("pool-uuid-0", "150", "resize-disk")}, commands)
+	condition := getPoolExpansionCondition("node-one")
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.NodeExpandingStatus, condition.Status)
+	require.Equal(t, "Expanding storage pool 0 to 150 GiB", condition.Message)
+	storageNode := &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, "node-one", "kube-test")
+	require.NoError(t, err)
+	require.Equal(t, string(corev1alpha1.NodeOnlineStatus), storageNode.Status.Phase)
+
+	// Pools already at the requested size should not be touched
+	require.Nil(t, getPoolExpansionCondition("node-two"))
+
+	// TestCase: Operator should wait for the pool to be expanded without
+	// running the command again
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+	require.Equal(t, corev1alpha1.NodeExpandingStatus, getPoolExpansionCondition("node-one").Status)
+
+	// TestCase: Condition should reflect that the pool has been expanded
+	pxNodeOne.Pools[0].TotalSize = 150 * bytesPerGiB
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+	require.Equal(t, corev1alpha1.NodeSucceededStatus, getPoolExpansionCondition("node-one").Status)
+
+	// TestCase: Pool expanded above the requested size should not be
+	// treated as a request to shrink it
+	pxNodeOne.Pools[0].TotalSize = 151 * bytesPerGiB
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+	require.Equal(t, corev1alpha1.NodeSucceededStatus, getPoolExpansionCondition("node-one").Status)
+	require.Len(t, recorder.Events, 0)
+	pxNodeOne.Pools[0].TotalSize = 150 * bytesPerGiB
+
+	// TestCase: Requests to shrink a pool should be rejected
+	commands = nil
+	err = testutil.Get(k8sClient, storageNode, "node-one", "kube-test")
+	require.NoError(t, err)
+	storageNode.Spec.CloudStorage.Pools = []corev1alpha1.StoragePoolSpec{
+		{ID: 0, SizeInGiB: 120},
+	}
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Empty(t, commands)
+	condition = getPoolExpansionCondition("node-one")
+	require.Equal(t, corev1alpha1.NodeFailedStatus, condition.Status)
+	require.Equal(t, util.FailedPoolExpansionReason, condition.Reason)
+	require.Equal(t, "Cannot expand storage pools: pool 0 cannot be shrunk from 150 GiB to 120 GiB",
+		condition.Message)
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v Node node-one: %s", v1.EventTypeWarning, util.FailedPoolExpansionReason, condition.Message))
+
+	// TestCase: Requests that exceed the maximum capacity of the capacity spec
+	// should be rejected
+	storageNode = &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, "node-one", "kube-test")
+	require.NoError(t, err)
+	storageNode.Spec.CloudStorage.Pools = []corev1alpha1.StoragePoolSpec{
+		{ID: 0, SizeInGiB: 450},
+	}
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Empty(t, commands)
+	condition = getPoolExpansionCondition("node-one")
+	require.Equal(t, corev1alpha1.NodeFailedStatus, condition.Status)
+	require.Equal(t, "Cannot expand storage pools: pool 0 cannot be expanded to 450 GiB as it "+
+		"would exceed the maximum capacity of 400 GiB", condition.Message)
+	require.Len(t, recorder.Events, 1)
+	<-recorder.Events
+
+	// TestCase: Pool should be expanded with the operation requested in the
+	// StorageNode, overriding the size in the capacity spec
+	storageNode = &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, "node-one", "kube-test")
+	require.NoError(t, err)
+	storageNode.Spec.CloudStorage.Pools = []corev1alpha1.StoragePoolSpec{
+		{ID: 0, SizeInGiB: 250, Operation: corev1alpha1.StoragePoolAddDiskOperation},
+		{ID: 1, SizeInGiB: 100},
+	}
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		showCommand,
+		expandCommand("pool-uuid-0", "250", "add-disk"),
+		expandCommand("pool-uuid-1", "100", "resize-disk"),
+	}, commands)
+	condition = getPoolExpansionCondition("node-one")
+	require.Equal(t, corev1alpha1.NodeExpandingStatus, condition.Status)
+	require.Equal(t, "Expanding storage pool 0 to 250 GiB, pool 1 to 100 GiB", condition.Message)
+
+	// TestCase: Failure to expand the pool should be reported and retried
+	commands = nil
+	commandErr = fmt.Errorf("pxctl error")
+	storageNode = &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, "node-one", "kube-test")
+	require.NoError(t, err)
+	storageNode.Spec.CloudStorage.Pools = []corev1alpha1.StoragePoolSpec{
+		{ID: 1, SizeInGiB: 80},
+	}
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{showCommand, expandCommand("pool-uuid-1", "80", "resize-disk")}, commands)
+	condition = getPoolExpansionCondition("node-one")
+	require.Equal(t, corev1alpha1.NodeFailedStatus, condition.Status)
+	require.Contains(t, condition.Message, "Failed to expand storage pools")
+	require.Contains(t, condition.Message, "pxctl error")
+	require.Len(t, recorder.Events, 1)
+	<-recorder.Events
+
+	commands = nil
+	commandErr = nil
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{showCommand, expandCommand("pool-uuid-1", "80", "resize-disk")}, commands)
+	require.Equal(t, corev1alpha1.NodeExpandingStatus, getPoolExpansionCondition("node-one").Status)
+
+	// TestCase: Expansion should not be requested again after a restart of
+	// the operator while the pools are being expanded
+	commands = nil
+	driver.poolExpansions = nil
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
+	require.Empty(t, commands)
+	require.Equal(t, corev1alpha1.NodeExpandingStatus, getPoolExpansionCondition("node-one").Status)
 
-	nodeStatusList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
+	// TestCase: Expansion should be requested again if the pools have not
+	// been expanded after the retry interval
+	expansion := driver.poolExpansions[clusterKey(cluster)]["node-one"]
+	require.NotNil(t, expansion)
+	expansion.requestTime = expansion.requestTime.Add(-poolExpansionRetryInterval)
+	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, nodeStatusList.Items, 1)
-	require.Equal(t, "node-uid", nodeStatusList.Items[0].Status.NodeUID)
+	require.Equal(t, [][]string{showCommand, expandCommand("pool-uuid-1", "80", "resize-disk")}, commands)
+	require.Equal(t, corev1alpha1.NodeExpandingStatus, getPoolExpansionCondition("node-one").Status)
+	require.Len(t, recorder.Events, 0)
 
-	// Fake a node object with matching hostname from labels
-	testutil.Delete(k8sClient, &nodeStatusList.Items[0])
-	coreops.SetInstance(
-		coreops.New(
-			fakek8sclient.NewSimpleClientset(&v1.Node{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "node-name",
-					Labels: map[string]string{
-						"kubernetes.io/hostname": "node-hostname",
-					},
-				},
-			}),
-		),
-	)
+	// TestCase: Expansion should fail if the pools have not been expanded
+	// before the timeout
+	commands = nil
+	expansion.startTime = expansion.startTime.Add(-poolExpansionTimeout)
+	expansion.requestTime = expansion.requestTime.Add(-poolExpansionRetryInterval)
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Empty(t, commands)
+	condition = getPoolExpansionCondition("node-one")
+	require.Equal(t, corev1alpha1.NodeFailedStatus, condition.Status)
+	require.Equal(t, util.FailedPoolExpansionReason, condition.Reason)
+	require.Equal(t, "Timed out expanding storage pool 1 to 80 GiB", condition.Message)
+	require.Len(t, recorder.Events, 1)
+	<-recorder.Events
 
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
+	require.Empty(t, commands)
+	require.Equal(t, corev1alpha1.NodeFailedStatus, getPoolExpansionCondition("node-one").Status)
+	require.Len(t, recorder.Events, 0)
 
-	nodeStatusList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
+	// TestCase: Expansion should succeed if the pools reach their size after
+	// the timeout
+	pxNodeOne.Pools[1].TotalSize = 80 * bytesPerGiB
+	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, nodeStatusList.Items, 1)
-	require.Equal(t, "node-uid", nodeStatusList.Items[0].Status.NodeUID)
+	require.Empty(t, commands)
+	require.Equal(t, corev1alpha1.NodeSucceededStatus, getPoolExpansionCondition("node-one").Status)
+
+	// TestCase: Pools that do not exist on the node should be rejected
+	commands = nil
+	storageNode = &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, "node-one", "kube-test")
+	require.NoError(t, err)
+	storageNode.Spec.CloudStorage.Pools = []corev1alpha1.StoragePoolSpec{
+		{ID: 2, SizeInGiB: 100},
+	}
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Empty(t, commands)
+	condition = getPoolExpansionCondition("node-one")
+	require.Equal(t, corev1alpha1.NodeFailedStatus, condition.Status)
+	require.Equal(t, "Cannot expand storage pools: pool 2 does not exist on the node", condition.Message)
 }
 
-func TestUpdateClusterStatusShouldDeleteStorageNodeForNonExistingNodes(t *testing.T) {
+func TestUpdateClusterStatusWithPoolExpansionOnMultipleNodes(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -2738,39 +5552,90 @@ func TestUpdateClusterStatusShouldDeleteStorageNodeForNonExistingNodes(t *testin
 	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
 	defer mockSdk.Stop()
 
-	// Create fake k8s client with fake service that will point the client
-	// to the mock sdk server address
-	k8sClient := testutil.FakeK8sClient(&v1.Service{
+	cluster := &corev1alpha1.StorageCluster{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      pxutil.PortworxServiceName,
+			Name:      "px-cluster",
 			Namespace: "kube-test",
+			UID:       "px-cluster-uid",
 		},
-		Spec: v1.ServiceSpec{
-			ClusterIP: sdkServerIP,
-			Ports: []v1.ServicePort{
-				{
-					Name: pxutil.PortworxSDKPortName,
-					Port: int32(sdkServerPort),
+		Spec: corev1alpha1.StorageClusterSpec{
+			CloudStorage: &corev1alpha1.CloudStorageSpec{
+				CapacitySpecs: []corev1alpha1.CloudStorageCapacitySpec{
+					{
+						MaxCapacityInGiB: 400,
+						PoolSizeInGiB:    300,
+					},
 				},
 			},
 		},
-	})
+		Status: corev1alpha1.StorageClusterStatus{
+			Phase: "Initializing",
+		},
+	}
+	clusterRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test",
+			},
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
+				},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "px-pod-one",
+				Namespace:       "kube-test",
+				Labels:          pxutil.SelectorLabels(),
+				OwnerReferences: []metav1.OwnerReference{*clusterRef},
+			},
+			Spec: v1.PodSpec{
+				NodeName: "node-one",
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "px-pod-two",
+				Namespace:       "kube-test",
+				Labels:          pxutil.SelectorLabels(),
+				OwnerReferences: []metav1.OwnerReference{*clusterRef},
+			},
+			Spec: v1.PodSpec{
+				NodeName: "node-two",
+			},
+		},
+	)
 
 	// Create driver object with the fake k8s client
+	recorder := record.NewFakeRecorder(10)
 	driver := portworx{
 		k8sClient: k8sClient,
-		recorder:  record.NewFakeRecorder(10),
+		recorder:  recorder,
 	}
 
-	cluster := &corev1alpha1.StorageCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "px-cluster",
-			Namespace: "kube-test",
-		},
-		Status: corev1alpha1.StorageClusterStatus{
-			Phase: "Initializing",
-		},
+	commands := make(map[string][][]string)
+	runCommandInPod = func(cmds []string, podName, containerName, namespace string) (string, error) {
+		commands[podName] = append(commands[podName], cmds)
+		if cmds[2] == "pool" && cmds[3] == "show" {
+			return `{"datapools":[{"poolID":0,"uuid":"` + podName + `-pool"}]}`, nil
+		}
+		return "", nil
 	}
+	defer func() {
+		runCommandInPod = func(cmds []string, podName, containerName, namespace string) (string, error) {
+			return coreops.Instance().RunCommandInPod(cmds, podName, containerName, namespace)
+		}
+	}()
 
 	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
 		Cluster: &api.StorageCluster{
@@ -2782,62 +5647,96 @@ func TestUpdateClusterStatusShouldDeleteStorageNodeForNonExistingNodes(t *testin
 		Return(expectedClusterResp, nil).
 		AnyTimes()
 
-	expectedNodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
-		Nodes: []*api.StorageNode{
-			{
-				Id:                "node-1",
-				SchedulerNodeName: "node-one",
-			},
-			{
-				Id:                "node-2",
-				SchedulerNodeName: "node-two",
-			},
-		},
-	}
 	mockNodeServer.EXPECT().
 		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{
+			Nodes: []*api.StorageNode{
+				{
+					Id:                "node-1",
+					SchedulerNodeName: "node-one",
+					Status:            api.Status_STATUS_OK,
+					Pools: []*api.StoragePool{
+						{ID: 0, TotalSize: 100 * bytesPerGiB},
+					},
+				},
+				{
+					Id:                "node-2",
+					SchedulerNodeName: "node-two",
+					Status:            api.Status_STATUS_OK,
+					Pools: []*api.StoragePool{
+						{ID: 0, TotalSize: 100 * bytesPerGiB},
+					},
+				},
+			},
+		}, nil).
+		AnyTimes()
 
+	getPoolExpansionCondition := func(nodeName string) *corev1alpha1.NodeCondition {
+		storageNode := &corev1alpha1.StorageNode{}
+		err := testutil.Get(k8sClient, storageNode, nodeName, "kube-test")
+		require.NoError(t, err)
+		for _, condition := range storageNode.Status.Conditions {
+			if condition.Type == corev1alpha1.NodePoolExpansionCondition {
+				return condition.DeepCopy()
+			}
+		}
+		return nil
+	}
+	showCommand := []string{pxctlPath, "service", "pool", "show", "-j"}
+	expandCommand := func(uuid, size string) []string {
+		return []string{pxctlPath, "service", "pool", "expand",
+			"--uid", uuid, "--size", size, "--operation", "resize-disk"}
+	}
+
+	// TestCase: Pools on every node should be expanded up to the maximum
+	// capacity, even if their total size across the nodes is larger
 	err := driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
+	require.Equal(t, map[string][][]string{
+		"px-pod-one": {showCommand, expandCommand("px-pod-one-pool", "300")},
+		"px-pod-two": {showCommand, expandCommand("px-pod-two-pool", "300")},
+	}, commands)
+	condition := getPoolExpansionCondition("node-one")
+	require.Equal(t, corev1alpha1.NodeExpandingStatus, condition.Status)
+	require.Equal(t, "Expanding storage pool 0 to 300 GiB", condition.Message)
+	condition = getPoolExpansionCondition("node-two")
+	require.Equal(t, corev1alpha1.NodeExpandingStatus, condition.Status)
+	require.Equal(t, "Expanding storage pool 0 to 300 GiB", condition.Message)
+	require.Empty(t, recorder.Events)
 
-	nodeStatusList := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
+	// TestCase: A request over the maximum capacity should be rejected only on
+	// the node it is made for
+	commands = make(map[string][][]string)
+	storageNode := &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, "node-two", "kube-test")
 	require.NoError(t, err)
-	require.Len(t, nodeStatusList.Items, 2)
-
-	// Node got removed from storage driver
-	expectedNodeEnumerateResp = &api.SdkNodeEnumerateWithFiltersResponse{
-		Nodes: []*api.StorageNode{
-			{
-				Id:                "node-2",
-				SchedulerNodeName: "node-two",
-			},
-		},
+	storageNode.Spec.CloudStorage.Pools = []corev1alpha1.StoragePoolSpec{
+		{ID: 0, SizeInGiB: 500},
 	}
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(expectedNodeEnumerateResp, nil).
-		Times(1)
-
-	err = driver.UpdateStorageClusterStatus(cluster)
+	err = k8sClient.Update(context.TODO(), storageNode)
 	require.NoError(t, err)
 
-	nodeStatusList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, nodeStatusList)
+	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, nodeStatusList.Items, 1)
-	require.Equal(t, "node-2", nodeStatusList.Items[0].Status.NodeUID)
+	require.Empty(t, commands)
+	require.Equal(t, corev1alpha1.NodeExpandingStatus, getPoolExpansionCondition("node-one").Status)
+	condition = getPoolExpansionCondition("node-two")
+	require.Equal(t, corev1alpha1.NodeFailedStatus, condition.Status)
+	require.Equal(t, "Cannot expand storage pools: pool 0 cannot be expanded to 500 GiB as it "+
+		"would exceed the maximum capacity of 400 GiB", condition.Message)
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v Node node-two: %s", v1.EventTypeWarning, util.FailedPoolExpansionReason, condition.Message))
 }
 
-func TestUpdateClusterStatusShouldNotDeleteStorageNodeIfPodExists(t *testing.T) {
+func TestUpdateClusterStatusWithNodeDecommission(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	// Create the mock servers that can be used to mock SDK calls
 	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
 	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+	mockVolumeServer := mock.NewMockOpenStorageVolumeServer(mockCtrl)
 
 	// Start a sdk server that implements the mock servers
 	sdkServerIP := "127.0.0.1"
@@ -2845,269 +5744,290 @@ func TestUpdateClusterStatusShouldNotDeleteStorageNodeIfPodExists(t *testing.T)
 	mockSdk := mock.NewSdkServer(mock.SdkServers{
 		Cluster: mockClusterServer,
 		Node:    mockNodeServer,
+		Volume:  mockVolumeServer,
 	})
 	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
 	defer mockSdk.Stop()
 
-	// Create fake k8s client with fake service that will point the client
-	// to the mock sdk server address
-	k8sClient := testutil.FakeK8sClient(&v1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      pxutil.PortworxServiceName,
-			Namespace: "kube-test",
-		},
-		Spec: v1.ServiceSpec{
-			ClusterIP: sdkServerIP,
-			Ports: []v1.ServicePort{
-				{
-					Name: pxutil.PortworxSDKPortName,
-					Port: int32(sdkServerPort),
-				},
-			},
-		},
-	})
-
-	// Create driver object with the fake k8s client
-	driver := portworx{
-		k8sClient: k8sClient,
-		recorder:  record.NewFakeRecorder(10),
-	}
-
 	cluster := &corev1alpha1.StorageCluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "px-cluster",
 			Namespace: "kube-test",
+			UID:       "px-cluster-uid",
 		},
 		Status: corev1alpha1.StorageClusterStatus{
 			Phase: "Initializing",
 		},
 	}
+	clusterRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
 
-	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
-		Cluster: &api.StorageCluster{
-			Status: api.Status_STATUS_OK,
-		},
-	}
-	mockClusterServer.EXPECT().
-		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
-		Return(expectedClusterResp, nil).
-		AnyTimes()
-
-	nodeEnumerateRespWithAllNodes := &api.SdkNodeEnumerateWithFiltersResponse{
-		Nodes: []*api.StorageNode{
-			{
-				Id:                "node-1",
-				SchedulerNodeName: "node-one",
-				Status:            api.Status_STATUS_OK,
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pxutil.PortworxServiceName,
+				Namespace: "kube-test",
 			},
-			{
-				Id:                "node-2",
-				SchedulerNodeName: "node-two",
+			Spec: v1.ServiceSpec{
+				ClusterIP: sdkServerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name: pxutil.PortworxSDKPortName,
+						Port: int32(sdkServerPort),
+					},
+				},
 			},
 		},
-	}
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithAllNodes, nil).
-		Times(1)
-
-	err := driver.UpdateStorageClusterStatus(cluster)
-	require.NoError(t, err)
-
-	storageNodeList := &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodeList)
-	require.NoError(t, err)
-	require.Len(t, storageNodeList.Items, 2)
-	require.Equal(t, string(corev1alpha1.NodeOnlineStatus), storageNodeList.Items[0].Status.Phase)
-
-	// TestCase: Node got removed from portworx sdk response, but corresponding pod exists
-	nodeEnumerateRespWithOneNode := &api.SdkNodeEnumerateWithFiltersResponse{
-		Nodes: []*api.StorageNode{
-			{
-				Id:                "node-2",
-				SchedulerNodeName: "node-two",
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-one",
 			},
 		},
-	}
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithOneNode, nil).
-		Times(1)
-
-	ownerRef := metav1.NewControllerRef(cluster, pxutil.StorageClusterKind())
-	nodeOnePod := &v1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            "portworx-pod-1",
-			Namespace:       cluster.Namespace,
-			Labels:          driver.GetSelectorLabels(),
-			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "px-pod-two",
+				Namespace:       "kube-test",
+				Labels:          pxutil.SelectorLabels(),
+				OwnerReferences: []metav1.OwnerReference{*clusterRef},
+			},
+			Spec: v1.PodSpec{
+				NodeName: "node-two",
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodRunning,
+			},
 		},
-		Spec: v1.PodSpec{
-			NodeName: "node-one",
+		&corev1alpha1.StorageNode{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "node-one",
+				Namespace:       "kube-test",
+				OwnerReferences: []metav1.OwnerReference{*clusterRef},
+			},
+			Status: corev1alpha1.NodeStatus{
+				Conditions: []corev1alpha1.NodeCondition{
+					{
+						Type:   corev1alpha1.NodeDecommissionCondition,
+						Status: corev1alpha1.NodeInitStatus,
+					},
+				},
+			},
 		},
-	}
-	k8sClient.Create(context.TODO(), nodeOnePod)
-
-	err = driver.UpdateStorageClusterStatus(cluster)
-	require.NoError(t, err)
-
-	storageNodeList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodeList)
-	require.NoError(t, err)
-	require.Len(t, storageNodeList.Items, 2)
-	require.Equal(t, string(corev1alpha1.NodeUnknownStatus), storageNodeList.Items[0].Status.Phase)
-
-	// TestCase: Node is not present in portworx sdk response, corresponding pod exists,
-	// but portworx is in Initializing state; should remain in Initializing state.
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithOneNode, nil).
-		Times(1)
+	)
 
-	// No conditions means the node is initializing state
-	storageNodeList.Items[0].Status.Conditions = nil
-	k8sClient.Update(context.TODO(), &storageNodeList.Items[0])
+	// Create driver object with the fake k8s client
+	recorder := record.NewFakeRecorder(10)
+	driver := portworx{
+		k8sClient: k8sClient,
+		recorder:  recorder,
+	}
 
-	err = driver.UpdateStorageClusterStatus(cluster)
-	require.NoError(t, err)
+	var commands [][]string
+	runCommandInPod = func(cmds []string, podName, containerName, namespace string) (string, error) {
+		require.Equal(t, "px-pod-two", podName)
+		require.Equal(t, pxContainerName, containerName)
+		commands = append(commands, cmds)
+		return "", nil
+	}
+	defer func() {
+		runCommandInPod = func(cmds []string, podName, containerName, namespace string) (string, error) {
+			return coreops.Instance().RunCommandInPod(cmds, podName, containerName, namespace)
+		}
+	}()
 
-	storageNodeList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodeList)
-	require.NoError(t, err)
-	require.Len(t, storageNodeList.Items, 2)
-	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNodeList.Items[0].Status.Phase)
+	expectedClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{
+			Status: api.Status_STATUS_OK,
+		},
+	}
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(expectedClusterResp, nil).
+		AnyTimes()
 
-	// TestCase: Node is not present in portworx sdk response, corresponding pod exists,
-	// but portworx is in Failed state; should remain in Failed state.
+	pxNode1 := &api.StorageNode{
+		Id:                "node-1",
+		SchedulerNodeName: "node-one",
+		Status:            api.Status_STATUS_OK,
+	}
+	pxNode2 := &api.StorageNode{
+		Id:                "node-2",
+		SchedulerNodeName: "node-two",
+		Status:            api.Status_STATUS_OK,
+	}
+	pxNode3 := &api.StorageNode{
+		Id:                "node-3",
+		SchedulerNodeName: "node-three",
+		Status:            api.Status_STATUS_OK,
+		Pools:             []*api.StoragePool{{ID: 3}},
+	}
+	nodeEnumerateResp := &api.SdkNodeEnumerateWithFiltersResponse{
+		Nodes: []*api.StorageNode{pxNode1, pxNode2},
+	}
 	mockNodeServer.EXPECT().
 		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithOneNode, nil).
-		Times(1)
+		Return(nodeEnumerateResp, nil).
+		AnyTimes()
 
-	// Latest condition is in failed state
-	storageNodeList.Items[0].Status.Conditions = []corev1alpha1.NodeCondition{
-		{
-			Type:   corev1alpha1.NodeInitCondition,
-			Status: corev1alpha1.NodeFailedStatus,
+	createVolume := func(id string, nodes ...string) *api.SdkVolumeInspectResponse {
+		return &api.SdkVolumeInspectResponse{
+			Volume: &api.Volume{
+				Id:          id,
+				Locator:     &api.VolumeLocator{Name: id + "-name"},
+				Spec:        &api.VolumeSpec{HaLevel: int64(len(nodes))},
+				ReplicaSets: []*api.ReplicaSet{{Nodes: nodes}},
+				Status:      api.VolumeStatus_VOLUME_STATUS_UP,
+			},
+		}
+	}
+	volumesResp := &api.SdkVolumeInspectWithFiltersResponse{
+		Volumes: []*api.SdkVolumeInspectResponse{
+			createVolume("vol-1", "node-1", "node-2"),
+			createVolume("vol-2", "node-2"),
+			createVolume("vol-3", "node-1"),
 		},
 	}
-	k8sClient.Update(context.TODO(), &storageNodeList.Items[0])
+	mockVolumeServer.EXPECT().
+		InspectWithFilters(gomock.Any(), &api.SdkVolumeInspectWithFiltersRequest{}).
+		Return(volumesResp, nil).
+		AnyTimes()
 
-	err = driver.UpdateStorageClusterStatus(cluster)
-	require.NoError(t, err)
+	getDecommissionCondition := func() (*corev1alpha1.NodeCondition, *corev1alpha1.StorageNode) {
+		storageNode := &corev1alpha1.StorageNode{}
+		err := testutil.Get(k8sClient, storageNode, "node-one", "kube-test")
+		require.NoError(t, err)
+		return getNodeCondition(&storageNode.Status, corev1alpha1.NodeDecommissionCondition), storageNode
+	}
 
-	storageNodeList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodeList)
+	// TestCase: Decommission should fail if a volume has its only replica on the node
+	err := driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, storageNodeList.Items, 2)
-	require.Equal(t, string(corev1alpha1.NodeFailedStatus), storageNodeList.Items[0].Status.Phase)
+	condition, _ := getDecommissionCondition()
+	require.Equal(t, corev1alpha1.NodeFailedStatus, condition.Status)
+	require.Contains(t, condition.Message, "Volumes [vol-3-name] have their only replica on the node")
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedDecommissionReason))
 
-	// TestCase: Node is not present in portworx sdk response, but pod labels do not match
-	// that of portworx pods
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithOneNode, nil).
+	// TestCase: Decommission should fail if there is no healthy node with
+	// storage to move the replicas to
+	volumesResp.Volumes = volumesResp.Volumes[:2]
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	condition, _ = getDecommissionCondition()
+	require.Equal(t, corev1alpha1.NodeFailedStatus, condition.Status)
+	require.Contains(t, condition.Message, "No healthy node available to move the replica of volume vol-1-name")
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedDecommissionReason))
+
+	// TestCase: A replica should be added on a healthy node first, keeping
+	// the replication level of the volume
+	nodeEnumerateResp.Nodes = append(nodeEnumerateResp.Nodes, pxNode3)
+	mockVolumeServer.EXPECT().
+		Update(gomock.Any(), &api.SdkVolumeUpdateRequest{
+			VolumeId: "vol-1",
+			Labels:   map[string]string{volumeLabelDecommissionNode: "node-1"},
+			Spec: &api.VolumeSpecUpdate{
+				HaLevelOpt: &api.VolumeSpecUpdate_HaLevel{HaLevel: 3},
+				ReplicaSet: &api.ReplicaSet{Nodes: []string{"node-1", "node-2", "node-3"}},
+			},
+		}).
+		Return(&api.SdkVolumeUpdateResponse{}, nil).
 		Times(1)
 
-	nodeOnePod.Labels = nil
-	k8sClient.Update(context.TODO(), nodeOnePod)
-
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
+	condition, _ = getDecommissionCondition()
+	require.Equal(t, corev1alpha1.NodeDrainingStatus, condition.Status)
+	require.Len(t, recorder.Events, 0)
 
-	storageNodeList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodeList)
+	// TestCase: Volumes should not be updated again while the new replica
+	// is being added or is not in sync
+	movingVolume := createVolume("vol-1", "node-1", "node-2")
+	movingVolume.Volume.Spec.HaLevel = 3
+	movingVolume.Volume.Locator.VolumeLabels = map[string]string{volumeLabelDecommissionNode: "node-1"}
+	volumesResp.Volumes[0] = movingVolume
+	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, storageNodeList.Items, 1)
-
-	// TestCase: Node is not present in portworx sdk response, and pod does not
-	// have correct owner references
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithAllNodes, nil).
-		Times(1)
-	nodeOnePod.Labels = driver.GetSelectorLabels()
-	k8sClient.Update(context.TODO(), nodeOnePod)
-
-	driver.UpdateStorageClusterStatus(cluster)
-
-	storageNodeList = &corev1alpha1.StorageNodeList{}
-	testutil.List(k8sClient, storageNodeList)
-	require.Len(t, storageNodeList.Items, 2)
+	condition, _ = getDecommissionCondition()
+	require.Equal(t, corev1alpha1.NodeDrainingStatus, condition.Status)
 
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithOneNode, nil).
+	movingVolume.Volume.ReplicaSets[0].Nodes = []string{"node-1", "node-2", "node-3"}
+	movingVolume.Volume.Status = api.VolumeStatus_VOLUME_STATUS_DEGRADED
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	condition, _ = getDecommissionCondition()
+	require.Equal(t, corev1alpha1.NodeDrainingStatus, condition.Status)
+
+	// TestCase: Replica on the node should be removed once the new replica
+	// is in sync
+	movingVolume.Volume.Status = api.VolumeStatus_VOLUME_STATUS_UP
+	mockVolumeServer.EXPECT().
+		Update(gomock.Any(), &api.SdkVolumeUpdateRequest{
+			VolumeId: "vol-1",
+			Labels:   map[string]string{volumeLabelDecommissionNode: ""},
+			Spec: &api.VolumeSpecUpdate{
+				HaLevelOpt: &api.VolumeSpecUpdate_HaLevel{HaLevel: 2},
+				ReplicaSet: &api.ReplicaSet{Nodes: []string{"node-2", "node-3"}},
+			},
+		}).
+		Return(&api.SdkVolumeUpdateResponse{}, nil).
 		Times(1)
-	nodeOnePod.OwnerReferences[0].UID = types.UID("dummy")
-	k8sClient.Update(context.TODO(), nodeOnePod)
 
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
+	condition, _ = getDecommissionCondition()
+	require.Equal(t, corev1alpha1.NodeDrainingStatus, condition.Status)
 
-	storageNodeList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodeList)
+	// TestCase: Node should be removed from the cluster once all the replicas
+	// are removed, after the storage pod on the node has been stopped
+	volumesResp.Volumes[0] = createVolume("vol-1", "node-2", "node-3")
+	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, storageNodeList.Items, 1)
-
-	// TestCase: Node is not present in portworx sdk response, and pod does not
-	// have correct node name
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithAllNodes, nil).
-		Times(1)
-	nodeOnePod.OwnerReferences = []metav1.OwnerReference{*ownerRef}
-	k8sClient.Update(context.TODO(), nodeOnePod)
-
-	driver.UpdateStorageClusterStatus(cluster)
-
-	storageNodeList = &corev1alpha1.StorageNodeList{}
-	testutil.List(k8sClient, storageNodeList)
-	require.Len(t, storageNodeList.Items, 2)
-
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithOneNode, nil).
-		Times(1)
-	nodeOnePod.Spec.NodeName = "dummy"
-	k8sClient.Update(context.TODO(), nodeOnePod)
+	condition, _ = getDecommissionCondition()
+	require.Equal(t, corev1alpha1.NodeRemovingStatus, condition.Status)
 
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
+	require.Empty(t, commands)
 
-	storageNodeList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodeList)
+	pxNode1.Status = api.Status_STATUS_OFFLINE
+	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
-	require.Len(t, storageNodeList.Items, 1)
-
-	// TestCase: Node is not present in portworx sdk response, and pod does not
-	// exist in cluster's namespace
-	k8sClient.Delete(context.TODO(), nodeOnePod)
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithAllNodes, nil).
-		Times(1)
+	require.Equal(t, [][]string{{pxctlPath, "cluster", "delete", "node-1"}}, commands)
+	condition, _ = getDecommissionCondition()
+	require.Equal(t, corev1alpha1.NodeWipingStatus, condition.Status)
 
-	driver.UpdateStorageClusterStatus(cluster)
+	// TestCase: Node-wiper should run on the node once it is no longer
+	// part of the cluster
+	nodeEnumerateResp.Nodes = []*api.StorageNode{pxNode2, pxNode3}
+	err = driver.UpdateStorageClusterStatus(cluster)
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+	condition, _ = getDecommissionCondition()
+	require.Equal(t, corev1alpha1.NodeWipingStatus, condition.Status)
 
-	storageNodeList = &corev1alpha1.StorageNodeList{}
-	testutil.List(k8sClient, storageNodeList)
-	require.Len(t, storageNodeList.Items, 2)
+	wiperPod := &v1.Pod{}
+	err = testutil.Get(k8sClient, wiperPod, pxNodeWiperDaemonSetName+"-node-one", "kube-test")
+	require.NoError(t, err)
+	require.Equal(t, "node-one", wiperPod.Spec.NodeName)
+	require.Equal(t, []string{"-w", "-r"}, wiperPod.Spec.Containers[0].Args)
 
-	mockNodeServer.EXPECT().
-		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
-		Return(nodeEnumerateRespWithOneNode, nil).
-		Times(1)
-	nodeOnePod.Namespace = "dummy"
-	k8sClient.Create(context.TODO(), nodeOnePod)
+	// TestCase: Node should be decommissioned once the node-wiper completes,
+	// and the StorageNode should be kept for the controller
+	wiperPod.Status.ContainerStatuses = []v1.ContainerStatus{{Ready: true}}
+	err = k8sClient.Status().Update(context.TODO(), wiperPod)
+	require.NoError(t, err)
 
 	err = driver.UpdateStorageClusterStatus(cluster)
 	require.NoError(t, err)
+	condition, storageNode := getDecommissionCondition()
+	require.Equal(t, corev1alpha1.NodeDecommissionedStatus, condition.Status)
+	require.Equal(t, string(corev1alpha1.NodeDecommissionedStatus), storageNode.Status.Phase)
 
-	storageNodeList = &corev1alpha1.StorageNodeList{}
-	err = testutil.List(k8sClient, storageNodeList)
-	require.NoError(t, err)
-	require.Len(t, storageNodeList.Items, 1)
+	err = testutil.Get(k8sClient, wiperPod, pxNodeWiperDaemonSetName+"-node-one", "kube-test")
+	require.True(t, errors.IsNotFound(err))
 }
 
 func TestUpdateClusterStatusShouldDeleteStorageNodeIfSchedulerNodeNameNotPresent(t *testing.T) {
@@ -3589,7 +6509,7 @@ func TestDeleteClusterWithUninstallStrategy(t *testing.T) {
 	// Check wiper cluster role
 	expectedCR := testutil.GetExpectedClusterRole(t, "nodeWiperClusterRole.yaml")
 	wiperCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, wiperCR, pxNodeWiperClusterRoleName, "")
+	err = testutil.Get(k8sClient, wiperCR, util.ClusterScopedName(pxNodeWiperClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, wiperCR.Name)
 	require.Len(t, wiperCR.OwnerReferences, 1)
@@ -3599,7 +6519,7 @@ func TestDeleteClusterWithUninstallStrategy(t *testing.T) {
 	// Check wiper cluster role binding
 	expectedCRB := testutil.GetExpectedClusterRoleBinding(t, "nodeWiperClusterRoleBinding.yaml")
 	wiperCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, wiperCRB, pxNodeWiperClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, wiperCRB, util.ClusterScopedName(pxNodeWiperClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, wiperCRB.Name)
 	require.Len(t, wiperCRB.OwnerReferences, 1)
@@ -3861,7 +6781,7 @@ func TestDeleteClusterWithUninstallStrategyForPKS(t *testing.T) {
 	// Check wiper cluster role
 	expectedCR := testutil.GetExpectedClusterRole(t, "nodeWiperClusterRole.yaml")
 	wiperCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, wiperCR, pxNodeWiperClusterRoleName, "")
+	err = testutil.Get(k8sClient, wiperCR, util.ClusterScopedName(pxNodeWiperClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, wiperCR.Name)
 	require.Len(t, wiperCR.OwnerReferences, 1)
@@ -3871,7 +6791,7 @@ func TestDeleteClusterWithUninstallStrategyForPKS(t *testing.T) {
 	// Check wiper cluster role binding
 	expectedCRB := testutil.GetExpectedClusterRoleBinding(t, "nodeWiperClusterRoleBinding.yaml")
 	wiperCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, wiperCRB, pxNodeWiperClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, wiperCRB, util.ClusterScopedName(pxNodeWiperClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, wiperCRB.Name)
 	require.Len(t, wiperCRB.OwnerReferences, 1)
@@ -3926,7 +6846,7 @@ func TestDeleteClusterWithUninstallAndWipeStrategy(t *testing.T) {
 	// Check wiper cluster role
 	expectedCR := testutil.GetExpectedClusterRole(t, "nodeWiperClusterRole.yaml")
 	wiperCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, wiperCR, pxNodeWiperClusterRoleName, "")
+	err = testutil.Get(k8sClient, wiperCR, util.ClusterScopedName(pxNodeWiperClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCR.Name, wiperCR.Name)
 	require.Len(t, wiperCR.OwnerReferences, 1)
@@ -3936,7 +6856,7 @@ func TestDeleteClusterWithUninstallAndWipeStrategy(t *testing.T) {
 	// Check wiper cluster role binding
 	expectedCRB := testutil.GetExpectedClusterRoleBinding(t, "nodeWiperClusterRoleBinding.yaml")
 	wiperCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, wiperCRB, pxNodeWiperClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, wiperCRB, util.ClusterScopedName(pxNodeWiperClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedCRB.Name, wiperCRB.Name)
 	require.Len(t, wiperCRB.OwnerReferences, 1)
@@ -4381,76 +7301,205 @@ func TestDeleteClusterWithUninstallWipeStrategyShouldRemoveKvdbData(t *testing.T
 
 	condition, err = driver.DeleteStorage(cluster)
 	require.NoError(t, err)
-
-	require.Equal(t, corev1alpha1.ClusterConditionTypeDelete, condition.Type)
+
+	require.Equal(t, corev1alpha1.ClusterConditionTypeDelete, condition.Type)
+	require.Equal(t, corev1alpha1.ClusterOperationCompleted, condition.Status)
+	require.Contains(t, condition.Reason, storageClusterUninstallAndWipeMsg)
+
+	_, err = kvdbMem.Get(cluster.Name + "/foo")
+	require.Error(t, err)
+	require.Equal(t, kvdb.ErrNotFound, err)
+
+	// Test etcd base version
+	getKVDBVersion = func(_ string, url string, opts map[string]string) (string, error) {
+		return kvdb.EtcdBaseVersion, nil
+	}
+	cluster.Spec.Kvdb.Endpoints = []string{
+		"etcd:https://kvdb1.com:2001",
+		"etcd:https://kvdb2.com:2001",
+	}
+	kvdbMem.Put(cluster.Name+"/foo", "bar", 0)
+	newKVDB = func(name, _ string, machines []string, opts map[string]string, _ kvdb.FatalErrorCB) (kvdb.Kvdb, error) {
+		require.Equal(t, e2.Name, name)
+		require.ElementsMatch(t, []string{"https://kvdb1.com:2001", "https://kvdb2.com:2001"}, machines)
+		require.Empty(t, opts)
+		return kvdbMem, nil
+	}
+
+	kp, err = kvdbMem.Get(cluster.Name + "/foo")
+	require.NoError(t, err)
+	require.Equal(t, "bar", string(kp.Value))
+
+	condition, err = driver.DeleteStorage(cluster)
+	require.NoError(t, err)
+
+	require.Equal(t, corev1alpha1.ClusterConditionTypeDelete, condition.Type)
+	require.Equal(t, corev1alpha1.ClusterOperationCompleted, condition.Status)
+	require.Contains(t, condition.Reason, storageClusterUninstallAndWipeMsg)
+
+	_, err = kvdbMem.Get(cluster.Name + "/foo")
+	require.Error(t, err)
+	require.Equal(t, kvdb.ErrNotFound, err)
+
+	// Test consul
+	getKVDBVersion = func(_ string, url string, opts map[string]string) (string, error) {
+		return kvdb.ConsulVersion1, nil
+	}
+	cluster.Spec.Kvdb.Endpoints = []string{
+		"consul:http://kvdb1.com:2001",
+		"consul:http://kvdb2.com:2001",
+	}
+	kvdbMem.Put(cluster.Name+"/foo", "bar", 0)
+	newKVDB = func(name, _ string, machines []string, opts map[string]string, _ kvdb.FatalErrorCB) (kvdb.Kvdb, error) {
+		require.Equal(t, consul.Name, name)
+		require.ElementsMatch(t, []string{"http://kvdb1.com:2001", "http://kvdb2.com:2001"}, machines)
+		require.Empty(t, opts)
+		return kvdbMem, nil
+	}
+
+	kp, err = kvdbMem.Get(cluster.Name + "/foo")
+	require.NoError(t, err)
+	require.Equal(t, "bar", string(kp.Value))
+
+	condition, err = driver.DeleteStorage(cluster)
+	require.NoError(t, err)
+
+	require.Equal(t, corev1alpha1.ClusterConditionTypeDelete, condition.Type)
+	require.Equal(t, corev1alpha1.ClusterOperationCompleted, condition.Status)
+	require.Contains(t, condition.Reason, storageClusterUninstallAndWipeMsg)
+
+	_, err = kvdbMem.Get(cluster.Name + "/foo")
+	require.Error(t, err)
+	require.Equal(t, kvdb.ErrNotFound, err)
+}
+
+func TestDeleteClusterWithUninstallWipeStrategyShouldRemoveAuthenticatedKvdbData(t *testing.T) {
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Kvdb: &corev1alpha1.KvdbSpec{
+				Endpoints:  []string{"etcd:https://kvdb.com:2001"},
+				AuthSecret: "kvdb-auth",
+			},
+			DeleteStrategy: &corev1alpha1.StorageClusterDeleteStrategy{
+				Type: corev1alpha1.UninstallAndWipeStorageClusterStrategyType,
+			},
+		},
+	}
+	authSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kvdb-auth",
+			Namespace: cluster.Namespace,
+		},
+		Data: map[string][]byte{
+			secretKeyKvdbCA:      []byte("ca-data"),
+			secretKeyKvdbCert:    []byte("cert-data"),
+			secretKeyKvdbCertKey: []byte("key-data"),
+		},
+	}
+
+	k8sClient := fakeClientWithWiperPod(cluster.Namespace)
+	err := k8sClient.Create(context.TODO(), authSecret)
+	require.NoError(t, err)
+	driver := portworx{
+		k8sClient: k8sClient,
+	}
+
+	kvdbMem, err := kvdb.New(mem.Name, pxKvdbPrefix, nil, nil, dbg.Panicf)
+	require.NoError(t, err)
+
+	// TestCase: The kvdb client should use the certificates from the secret
+	kvdbMem.Put(cluster.Name+"/foo", "bar", 0)
+	var versionOpts map[string]string
+	getKVDBVersion = func(_ string, url string, opts map[string]string) (string, error) {
+		versionOpts = opts
+		return kvdb.EtcdVersion3, nil
+	}
+	newKVDB = func(name, _ string, machines []string, opts map[string]string, _ kvdb.FatalErrorCB) (kvdb.Kvdb, error) {
+		require.Equal(t, versionOpts, opts)
+		require.Len(t, opts, 3)
+		for opt, expected := range map[string]string{
+			kvdb.CAFileKey:      "ca-data",
+			kvdb.CertFileKey:    "cert-data",
+			kvdb.CertKeyFileKey: "key-data",
+		} {
+			data, err := ioutil.ReadFile(opts[opt])
+			require.NoError(t, err)
+			require.Equal(t, expected, string(data))
+		}
+		return kvdbMem, nil
+	}
+
+	condition, err := driver.DeleteStorage(cluster)
+	require.NoError(t, err)
+	require.Equal(t, corev1alpha1.ClusterOperationCompleted, condition.Status)
+	require.Contains(t, condition.Reason, storageClusterUninstallAndWipeMsg)
+
+	_, err = kvdbMem.Get(cluster.Name + "/foo")
+	require.Equal(t, kvdb.ErrNotFound, err)
+
+	// The certificates should not be left behind
+	_, err = os.Stat(path.Dir(versionOpts[kvdb.CertFileKey]))
+	require.True(t, os.IsNotExist(err))
+
+	// TestCase: The kvdb client should use the ACL token from the secret
+	kvdbMem.Put(cluster.Name+"/foo", "bar", 0)
+	authSecret.Data = map[string][]byte{
+		secretKeyKvdbACLToken: []byte("acl-token"),
+	}
+	err = k8sClient.Update(context.TODO(), authSecret)
+	require.NoError(t, err)
+	newKVDB = func(name, _ string, machines []string, opts map[string]string, _ kvdb.FatalErrorCB) (kvdb.Kvdb, error) {
+		require.Equal(t, map[string]string{kvdb.ACLTokenKey: "acl-token"}, opts)
+		return kvdbMem, nil
+	}
+
+	condition, err = driver.DeleteStorage(cluster)
+	require.NoError(t, err)
 	require.Equal(t, corev1alpha1.ClusterOperationCompleted, condition.Status)
-	require.Contains(t, condition.Reason, storageClusterUninstallAndWipeMsg)
 
 	_, err = kvdbMem.Get(cluster.Name + "/foo")
-	require.Error(t, err)
 	require.Equal(t, kvdb.ErrNotFound, err)
 
-	// Test etcd base version
-	getKVDBVersion = func(_ string, url string, opts map[string]string) (string, error) {
-		return kvdb.EtcdBaseVersion, nil
-	}
-	cluster.Spec.Kvdb.Endpoints = []string{
-		"etcd:https://kvdb1.com:2001",
-		"etcd:https://kvdb2.com:2001",
-	}
+	// TestCase: The kvdb client should use the username and password from the secret
 	kvdbMem.Put(cluster.Name+"/foo", "bar", 0)
+	authSecret.Data = map[string][]byte{
+		secretKeyKvdbUsername: []byte("user"),
+		secretKeyKvdbPassword: []byte("pass"),
+	}
+	err = k8sClient.Update(context.TODO(), authSecret)
+	require.NoError(t, err)
 	newKVDB = func(name, _ string, machines []string, opts map[string]string, _ kvdb.FatalErrorCB) (kvdb.Kvdb, error) {
-		require.Equal(t, e2.Name, name)
-		require.ElementsMatch(t, []string{"https://kvdb1.com:2001", "https://kvdb2.com:2001"}, machines)
-		require.Empty(t, opts)
+		require.Equal(t, map[string]string{
+			kvdb.UsernameKey: "user",
+			kvdb.PasswordKey: "pass",
+		}, opts)
 		return kvdbMem, nil
 	}
 
-	kp, err = kvdbMem.Get(cluster.Name + "/foo")
-	require.NoError(t, err)
-	require.Equal(t, "bar", string(kp.Value))
-
 	condition, err = driver.DeleteStorage(cluster)
 	require.NoError(t, err)
-
-	require.Equal(t, corev1alpha1.ClusterConditionTypeDelete, condition.Type)
 	require.Equal(t, corev1alpha1.ClusterOperationCompleted, condition.Status)
-	require.Contains(t, condition.Reason, storageClusterUninstallAndWipeMsg)
 
 	_, err = kvdbMem.Get(cluster.Name + "/foo")
-	require.Error(t, err)
 	require.Equal(t, kvdb.ErrNotFound, err)
 
-	// Test consul
-	getKVDBVersion = func(_ string, url string, opts map[string]string) (string, error) {
-		return kvdb.ConsulVersion1, nil
-	}
-	cluster.Spec.Kvdb.Endpoints = []string{
-		"consul:http://kvdb1.com:2001",
-		"consul:http://kvdb2.com:2001",
-	}
+	// TestCase: Wipe should fail if the kvdb auth secret is missing
 	kvdbMem.Put(cluster.Name+"/foo", "bar", 0)
-	newKVDB = func(name, _ string, machines []string, opts map[string]string, _ kvdb.FatalErrorCB) (kvdb.Kvdb, error) {
-		require.Equal(t, consul.Name, name)
-		require.ElementsMatch(t, []string{"http://kvdb1.com:2001", "http://kvdb2.com:2001"}, machines)
-		require.Empty(t, opts)
-		return kvdbMem, nil
-	}
-
-	kp, err = kvdbMem.Get(cluster.Name + "/foo")
+	err = testutil.Delete(k8sClient, authSecret)
 	require.NoError(t, err)
-	require.Equal(t, "bar", string(kp.Value))
 
 	condition, err = driver.DeleteStorage(cluster)
 	require.NoError(t, err)
-
-	require.Equal(t, corev1alpha1.ClusterConditionTypeDelete, condition.Type)
-	require.Equal(t, corev1alpha1.ClusterOperationCompleted, condition.Status)
-	require.Contains(t, condition.Reason, storageClusterUninstallAndWipeMsg)
+	require.Equal(t, corev1alpha1.ClusterOperationFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to wipe metadata")
+	require.Contains(t, condition.Reason, "kvdb-auth")
 
 	_, err = kvdbMem.Get(cluster.Name + "/foo")
-	require.Error(t, err)
-	require.Equal(t, kvdb.ErrNotFound, err)
+	require.NoError(t, err)
 }
 
 func TestDeleteClusterWithUninstallWipeStrategyFailedRemoveKvdbData(t *testing.T) {
@@ -4619,3 +7668,394 @@ func manifestCleanup() {
 	os.Unsetenv(manifest.EnvKeyReleaseManifestURL)
 	os.RemoveAll(manifest.ManifestDir)
 }
+
+func TestPreNodeUpgrade(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Create the mock servers that can be used to mock SDK calls
+	mockClusterServer := mock.NewMockOpenStorageClusterServer(mockCtrl)
+	mockNodeServer := mock.NewMockOpenStorageNodeServer(mockCtrl)
+
+	// Start a sdk server that implements the mock servers
+	sdkServerIP := "127.0.0.1"
+	sdkServerPort := 21883
+	mockSdk := mock.NewSdkServer(mock.SdkServers{
+		Cluster: mockClusterServer,
+		Node:    mockNodeServer,
+	})
+	mockSdk.StartOnAddress(sdkServerIP, strconv.Itoa(sdkServerPort))
+	defer mockSdk.Stop()
+
+	// Create fake k8s client with fake service that will point the client
+	// to the mock sdk server address
+	k8sClient := testutil.FakeK8sClient(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pxutil.PortworxServiceName,
+			Namespace: "kube-test",
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: sdkServerIP,
+			Ports: []v1.ServicePort{
+				{
+					Name: pxutil.PortworxSDKPortName,
+					Port: int32(sdkServerPort),
+				},
+			},
+		},
+	})
+
+	driver := portworx{
+		k8sClient: k8sClient,
+	}
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+	}
+
+	healthyClusterResp := &api.SdkClusterInspectCurrentResponse{
+		Cluster: &api.StorageCluster{
+			Status: api.Status_STATUS_OK,
+		},
+	}
+
+	// Error from InspectCurrent API
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(nil, fmt.Errorf("InspectCurrent error")).
+		Times(1)
+
+	err := driver.PreNodeUpgrade(cluster, "node-1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "InspectCurrent error")
+
+	// Storage cluster is not healthy
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(&api.SdkClusterInspectCurrentResponse{
+			Cluster: &api.StorageCluster{
+				Status: api.Status_STATUS_NOT_IN_QUORUM,
+			},
+		}, nil).
+		Times(1)
+
+	err = driver.PreNodeUpgrade(cluster, "node-1")
+	require.EqualError(t, err, "storage cluster is not healthy, status is STATUS_NOT_IN_QUORUM")
+
+	// Error from EnumerateWithFilters API
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(healthyClusterResp, nil).
+		Times(1)
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(nil, fmt.Errorf("EnumerateWithFilters error")).
+		Times(1)
+
+	err = driver.PreNodeUpgrade(cluster, "node-1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "EnumerateWithFilters error")
+
+	// Another storage node is offline
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(healthyClusterResp, nil).
+		Times(1)
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{
+			Nodes: []*api.StorageNode{
+				{SchedulerNodeName: "node-1", Status: api.Status_STATUS_OK},
+				{SchedulerNodeName: "node-2", Status: api.Status_STATUS_OFFLINE},
+			},
+		}, nil).
+		Times(1)
+
+	err = driver.PreNodeUpgrade(cluster, "node-1")
+	require.EqualError(t, err, "node node-2 is not healthy, status is Offline")
+
+	// Replicas are being resynced on a storage node
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(healthyClusterResp, nil).
+		Times(1)
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{
+			Nodes: []*api.StorageNode{
+				{SchedulerNodeName: "node-1", Status: api.Status_STATUS_OK},
+				{Id: "node-2-id", Status: api.Status_STATUS_STORAGE_REBALANCE},
+			},
+		}, nil).
+		Times(1)
+
+	err = driver.PreNodeUpgrade(cluster, "node-1")
+	require.EqualError(t, err, "replicas are being resynced on node node-2-id")
+
+	// The node being upgraded is allowed to be offline
+	mockClusterServer.EXPECT().
+		InspectCurrent(gomock.Any(), &api.SdkClusterInspectCurrentRequest{}).
+		Return(healthyClusterResp, nil).
+		Times(1)
+	mockNodeServer.EXPECT().
+		EnumerateWithFilters(gomock.Any(), &api.SdkNodeEnumerateWithFiltersRequest{}).
+		Return(&api.SdkNodeEnumerateWithFiltersResponse{
+			Nodes: []*api.StorageNode{
+				{SchedulerNodeName: "node-1", Status: api.Status_STATUS_OFFLINE},
+				{SchedulerNodeName: "node-2", Status: api.Status_STATUS_OK},
+				{SchedulerNodeName: "node-3", Status: api.Status_STATUS_DECOMMISSION},
+			},
+		}, nil).
+		Times(1)
+
+	err = driver.PreNodeUpgrade(cluster, "node-1")
+	require.NoError(t, err)
+
+	// Nothing to check if portworx is disabled
+	cluster.Annotations = map[string]string{
+		storagecluster.AnnotationDisableStorage: "true",
+	}
+	err = driver.PreNodeUpgrade(cluster, "node-1")
+	require.NoError(t, err)
+}
+
+func TestValidateRollback(t *testing.T) {
+	manifestSetup()
+	defer manifestCleanup()
+
+	driver := portworx{}
+	current := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "portworx/oci-monitor:2.1.5.1",
+		},
+	}
+	target := current.DeepCopy()
+
+	// Rolling back to the same or newer version is always allowed
+	err := driver.ValidateRollback(current, target)
+	require.NoError(t, err)
+
+	target.Spec.Image = "portworx/oci-monitor:2.2.0"
+	err = driver.ValidateRollback(current, target)
+	require.NoError(t, err)
+
+	// Rolling back to a version allowed by the release manifest
+	target.Spec.Image = "portworx/oci-monitor:2.1.4"
+	err = driver.ValidateRollback(current, target)
+	require.NoError(t, err)
+
+	// Rolling back to a version older than allowed by the release manifest
+	target.Spec.Image = "portworx/oci-monitor:2.1.3"
+	err = driver.ValidateRollback(current, target)
+	require.EqualError(t, err, "incompatible rollback: "+
+		"release 2.1.5.1 can only be rolled back to 2.1.4 or later")
+
+	// Releases not present in the release manifest are not restricted
+	current.Spec.Image = "portworx/oci-monitor:2.1.6"
+	err = driver.ValidateRollback(current, target)
+	require.NoError(t, err)
+
+	// Nothing to validate if portworx is disabled
+	current.Spec.Image = "portworx/oci-monitor:2.1.5.1"
+	current.Annotations = map[string]string{
+		storagecluster.AnnotationDisableStorage: "true",
+	}
+	err = driver.ValidateRollback(current, target)
+	require.NoError(t, err)
+}
+
+func TestGetPodsUsingStorage(t *testing.T) {
+	pxPV := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "px-pv"},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				PortworxVolume: &v1.PortworxVolumeSource{VolumeID: "vol1"},
+			},
+		},
+	}
+	csiPV := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "csi-pv"},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{
+					Driver:       pxutil.CSIDriverName,
+					VolumeHandle: "vol2",
+				},
+			},
+		},
+	}
+	otherPV := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-pv"},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				HostPath: &v1.HostPathVolumeSource{Path: "/data"},
+			},
+		},
+	}
+	pvc := func(name, volumeName string) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec:       v1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+		}
+	}
+	pod := func(name, nodeName string, volume v1.VolumeSource) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec: v1.PodSpec{
+				NodeName: nodeName,
+				Volumes:  []v1.Volume{{Name: "data", VolumeSource: volume}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	claim := func(name string) v1.VolumeSource {
+		return v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: name},
+		}
+	}
+
+	inTreePod := pod("in-tree", "node1", claim("px-pvc"))
+	csiPod := pod("csi", "node1", claim("csi-pvc"))
+	inlinePod := pod("inline", "node1", v1.VolumeSource{
+		PortworxVolume: &v1.PortworxVolumeSource{VolumeID: "vol3"},
+	})
+	otherVolumePod := pod("other-volume", "node1", claim("other-pvc"))
+	unboundPod := pod("unbound", "node1", claim("unbound-pvc"))
+	otherNodePod := pod("other-node", "node2", claim("px-pvc"))
+	completedPod := pod("completed", "node1", claim("px-pvc"))
+	completedPod.Status.Phase = v1.PodSucceeded
+
+	k8sClient := testutil.FakeK8sClient(
+		pxPV, csiPV, otherPV,
+		pvc("px-pvc", pxPV.Name), pvc("csi-pvc", csiPV.Name),
+		pvc("other-pvc", otherPV.Name), pvc("unbound-pvc", ""),
+		inTreePod, csiPod, inlinePod, otherVolumePod, unboundPod,
+		otherNodePod, completedPod,
+	)
+	driver := portworx{k8sClient: k8sClient}
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+	}
+
+	pods, err := driver.GetPodsUsingStorage(cluster, "node1")
+	require.NoError(t, err)
+	podNames := make([]string, 0)
+	for _, p := range pods {
+		podNames = append(podNames, p.Name)
+	}
+	require.ElementsMatch(t, []string{"in-tree", "csi", "inline"}, podNames)
+
+	pods, err = driver.GetPodsUsingStorage(cluster, "node3")
+	require.NoError(t, err)
+	require.Empty(t, pods)
+
+	// No pods are using portworx if it is disabled
+	cluster.Annotations = map[string]string{
+		storagecluster.AnnotationDisableStorage: "true",
+	}
+	pods, err = driver.GetPodsUsingStorage(cluster, "node1")
+	require.NoError(t, err)
+	require.Empty(t, pods)
+}
+
+func TestGetPreflightPodSpec(t *testing.T) {
+	driver := portworx{}
+	startPort := uint32(10001)
+	imagePullSecret := "pull-secret"
+	devices := []string{"/dev/sdb", "/dev/sdc"}
+	journalDevice := "/dev/sdd"
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image:               "portworx/oci-monitor:2.1.0",
+			CustomImageRegistry: "registry.io",
+			ImagePullPolicy:     v1.PullIfNotPresent,
+			ImagePullSecret:     &imagePullSecret,
+			StartPort:           &startPort,
+			Placement: &corev1alpha1.PlacementSpec{
+				Tolerations: []v1.Toleration{
+					{
+						Key:      "storage",
+						Operator: v1.TolerationOpExists,
+					},
+				},
+			},
+			Kvdb: &corev1alpha1.KvdbSpec{
+				Endpoints: []string{
+					"etcd:http://etcd-1.company.org:2379",
+					"etcd:https://10.0.0.2",
+					"consul:http://consul.company.org",
+					"etcd:http://[fd00::2]:2379",
+				},
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Storage: &corev1alpha1.StorageSpec{
+					Devices:       &devices,
+					JournalDevice: &journalDevice,
+				},
+			},
+		},
+	}
+
+	podSpec, err := driver.GetPreflightPodSpec(cluster, "node1")
+	require.NoError(t, err)
+
+	require.True(t, podSpec.HostNetwork)
+	require.Equal(t, v1.RestartPolicyNever, podSpec.RestartPolicy)
+	require.Equal(t, cluster.Spec.Placement.Tolerations, podSpec.Tolerations)
+	require.Equal(t, []v1.LocalObjectReference{{Name: imagePullSecret}}, podSpec.ImagePullSecrets)
+	require.Len(t, podSpec.Volumes, 1)
+	require.Equal(t, "/", podSpec.Volumes[0].HostPath.Path)
+
+	require.Len(t, podSpec.Containers, 1)
+	container := podSpec.Containers[0]
+	require.Equal(t, "registry.io/portworx/oci-monitor:2.1.0", container.Image)
+	require.Equal(t, v1.PullIfNotPresent, container.ImagePullPolicy)
+	require.Equal(t, []string{"/bin/bash", "-c", preflightScript}, container.Command)
+	require.Len(t, container.VolumeMounts, 1)
+	require.Equal(t, "/host", container.VolumeMounts[0].MountPath)
+	require.True(t, container.VolumeMounts[0].ReadOnly)
+
+	expectedEnv := []v1.EnvVar{
+		{Name: "MIN_KERNEL_VERSION", Value: "3.10"},
+		{Name: "HOST_PATHS", Value: "/etc/pwx /opt/pwx /var/lib/osd"},
+		{Name: "DEVICES", Value: "/dev/sdb /dev/sdc /dev/sdd"},
+		{Name: "START_PORT", Value: "10001"},
+		{Name: "END_PORT", Value: "10022"},
+		{Name: "KVDB_ADDRESSES", Value: "etcd-1.company.org:2379 10.0.0.2:2379 consul.company.org:8500 [fd00::2]:2379"},
+	}
+	require.Equal(t, expectedEnv, container.Env)
+
+	// Internal kvdb and storage devices selected by the driver should not be checked
+	cluster.Spec.Kvdb.Internal = true
+	cluster.Spec.Storage = nil
+
+	podSpec, err = driver.GetPreflightPodSpec(cluster, "node1")
+	require.NoError(t, err)
+	require.Contains(t, podSpec.Containers[0].Env, v1.EnvVar{Name: "DEVICES", Value: ""})
+	require.Contains(t, podSpec.Containers[0].Env, v1.EnvVar{Name: "KVDB_ADDRESSES", Value: ""})
+
+	// Invalid kvdb endpoint
+	cluster.Spec.Kvdb.Internal = false
+	cluster.Spec.Kvdb.Endpoints = []string{"etcd:%"}
+
+	_, err = driver.GetPreflightPodSpec(cluster, "node1")
+	require.EqualError(t, err, "invalid kvdb endpoint etcd:%")
+
+	// Nil cluster
+	_, err = driver.GetPreflightPodSpec(nil, "node1")
+	require.EqualError(t, err, "storage cluster cannot be empty")
+}
@@ -0,0 +1,245 @@
+package portworx
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	pxutil "github.com/libopenstorage/operator/drivers/storage/portworx/util"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/libopenstorage/operator/pkg/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	preflightContainerName       = "preflight"
+	preflightHostVolumeName      = "host"
+	preflightHostMountPath       = "/host"
+	preflightMinKernelVersion    = "3.10"
+	preflightPortCount           = 22
+	pxVarLibOsd                  = "/var/lib/osd"
+	defaultEtcdPort              = "2379"
+	defaultConsulPort            = "8500"
+	envKeyPreflightMinKernel     = "MIN_KERNEL_VERSION"
+	envKeyPreflightHostPaths     = "HOST_PATHS"
+	envKeyPreflightDevices       = "DEVICES"
+	envKeyPreflightStartPort     = "START_PORT"
+	envKeyPreflightEndPort       = "END_PORT"
+	envKeyPreflightKvdbAddresses = "KVDB_ADDRESSES"
+)
+
+// preflightScript checks that the node meets the requirements of Portworx. The
+// host filesystem is mounted at /host and the pod runs in the host network, so
+// the ports in use on the host are visible in /proc/net. Every failed check is
+// written to the termination log of the container, which is reported back in
+// the StorageNode of the node. The checks are configured using the environment
+// variables set in GetPreflightPodSpec.
+const preflightScript = `
+failures=""
+fail() {
+  failures="${failures}$1
+"
+}
+
+kernel=$(uname -r)
+oldest=$(printf '%s\n%s\n' "$MIN_KERNEL_VERSION" "${kernel%%-*}" | sort -V | head -n 1)
+if [ "$oldest" != "$MIN_KERNEL_VERSION" ]; then
+  fail "kernel version $kernel is older than the minimum required version $MIN_KERNEL_VERSION"
+fi
+
+for path in $HOST_PATHS; do
+  if [ -e "/host$path" ]; then
+    if [ ! -d "/host$path" ]; then
+      fail "host path $path exists but is not a directory"
+    fi
+    continue
+  fi
+  # Missing paths are created when the storage pod mounts them, which needs
+  # their closest existing parent to be a directory
+  parent=$(dirname "$path")
+  while [ ! -e "/host$parent" ]; do
+    parent=$(dirname "$parent")
+  done
+  if [ ! -d "/host$parent" ]; then
+    fail "host path $path cannot be created as $parent is not a directory"
+  fi
+done
+
+for device in $DEVICES; do
+  if [ ! -b "/host$device" ]; then
+    fail "device $device is not a block device"
+  elif grep -q "^$device " /host/proc/1/mounts; then
+    fail "device $device is already mounted"
+  fi
+done
+
+for port in $(seq "$START_PORT" "$END_PORT"); do
+  hex=$(printf '%04X' "$port")
+  if cat /proc/net/tcp /proc/net/tcp6 2>/dev/null | grep -q "^ *[0-9]*: [0-9A-F]*:$hex [0-9A-F]*:0000 0A"; then
+    fail "port $port is already in use"
+  fi
+done
+
+for address in $KVDB_ADDRESSES; do
+  # IPv6 hosts are enclosed in brackets, which /dev/tcp does not accept
+  host=${address%:*}
+  host=${host#[}
+  host=${host%]}
+  if ! timeout 5 bash -c "</dev/tcp/$host/${address##*:}" 2>/dev/null; then
+    fail "kvdb endpoint $address is not reachable"
+  fi
+done
+
+if [ -n "$failures" ]; then
+  printf '%s' "$failures" > /dev/termination-log
+  printf 'Preflight checks failed:\n%s' "$failures"
+  exit 1
+fi
+echo "Preflight checks passed"
+`
+
+// GetPreflightPodSpec returns the spec of the pod that verifies the kernel version,
+// host paths, storage devices, ports and kvdb endpoints used by Portworx on the node
+func (p *portworx) GetPreflightPodSpec(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+) (v1.PodSpec, error) {
+	if cluster == nil {
+		return v1.PodSpec{}, fmt.Errorf("storage cluster cannot be empty")
+	}
+
+	kvdbAddresses, err := preflightKvdbAddresses(cluster)
+	if err != nil {
+		return v1.PodSpec{}, err
+	}
+
+	startPort := pxutil.StartPort(cluster)
+	privileged := true
+	podSpec := v1.PodSpec{
+		HostNetwork:   true,
+		RestartPolicy: v1.RestartPolicyNever,
+		Containers: []v1.Container{
+			{
+				Name:            preflightContainerName,
+				Image:           util.GetImageURN(cluster.Spec.CustomImageRegistry, cluster.Spec.Image),
+				ImagePullPolicy: pxutil.ImagePullPolicy(cluster),
+				Command:         []string{"/bin/bash", "-c", preflightScript},
+				Env: []v1.EnvVar{
+					{
+						Name:  envKeyPreflightMinKernel,
+						Value: preflightMinKernelVersion,
+					},
+					{
+						Name:  envKeyPreflightHostPaths,
+						Value: strings.Join([]string{pxEtcPwx, pxOptPwx, pxVarLibOsd}, " "),
+					},
+					{
+						Name:  envKeyPreflightDevices,
+						Value: strings.Join(preflightDevices(cluster), " "),
+					},
+					{
+						Name:  envKeyPreflightStartPort,
+						Value: strconv.Itoa(startPort),
+					},
+					{
+						Name:  envKeyPreflightEndPort,
+						Value: strconv.Itoa(startPort + preflightPortCount - 1),
+					},
+					{
+						Name:  envKeyPreflightKvdbAddresses,
+						Value: strings.Join(kvdbAddresses, " "),
+					},
+				},
+				SecurityContext: &v1.SecurityContext{
+					Privileged: &privileged,
+				},
+				VolumeMounts: []v1.VolumeMount{
+					{
+						Name:      preflightHostVolumeName,
+						MountPath: preflightHostMountPath,
+						ReadOnly:  true,
+					},
+				},
+			},
+		},
+		Volumes: []v1.Volume{
+			{
+				Name: preflightHostVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: "/",
+					},
+				},
+			},
+		},
+	}
+
+	if cluster.Spec.Placement != nil && len(cluster.Spec.Placement.Tolerations) > 0 {
+		podSpec.Tolerations = make([]v1.Toleration, 0)
+		for _, toleration := range cluster.Spec.Placement.Tolerations {
+			podSpec.Tolerations = append(podSpec.Tolerations, *(toleration.DeepCopy()))
+		}
+	}
+
+	if cluster.Spec.ImagePullSecret != nil && *cluster.Spec.ImagePullSecret != "" {
+		podSpec.ImagePullSecrets = []v1.LocalObjectReference{
+			{
+				Name: *cluster.Spec.ImagePullSecret,
+			},
+		}
+	}
+
+	return podSpec, nil
+}
+
+// preflightDevices returns the storage devices that Portworx is going to use on
+// the node. Devices that Portworx picks by itself are not checked.
+func preflightDevices(cluster *corev1alpha1.StorageCluster) []string {
+	devices := make([]string, 0)
+	storage := cluster.Spec.Storage
+	if storage == nil {
+		return devices
+	}
+	if storage.Devices != nil {
+		devices = append(devices, *storage.Devices...)
+	}
+	for _, device := range []*string{storage.JournalDevice, storage.SystemMdDevice, storage.KvdbDevice} {
+		if device != nil && *device != "" {
+			devices = append(devices, *device)
+		}
+	}
+	return devices
+}
+
+// preflightKvdbAddresses returns the host:port addresses of the external kvdb
+// endpoints, with IPv6 hosts enclosed in brackets. Endpoints are of the form
+// <kvdb type>:<url>, like etcd:http://host:port.
+func preflightKvdbAddresses(cluster *corev1alpha1.StorageCluster) ([]string, error) {
+	addresses := make([]string, 0)
+	if cluster.Spec.Kvdb == nil || cluster.Spec.Kvdb.Internal {
+		return addresses, nil
+	}
+
+	for _, endpoint := range cluster.Spec.Kvdb.Endpoints {
+		kvdbType := ""
+		endpointURL := endpoint
+		if parts := strings.SplitN(endpoint, ":", 2); len(parts) == 2 && !strings.HasPrefix(parts[1], "//") {
+			kvdbType, endpointURL = parts[0], parts[1]
+		}
+		u, err := url.Parse(endpointURL)
+		if err != nil || u.Hostname() == "" {
+			return nil, fmt.Errorf("invalid kvdb endpoint %s", endpoint)
+		}
+		port := u.Port()
+		if port == "" {
+			port = defaultEtcdPort
+			if kvdbType == "consul" {
+				port = defaultConsulPort
+			}
+		}
+		addresses = append(addresses, net.JoinHostPort(u.Hostname(), port))
+	}
+	return addresses, nil
+}
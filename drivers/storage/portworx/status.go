@@ -1,15 +1,18 @@
 package portworx
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/go-version"
 	"github.com/libopenstorage/openstorage/api"
 	"github.com/libopenstorage/openstorage/pkg/grpcserver"
 	pxutil "github.com/libopenstorage/operator/drivers/storage/portworx/util"
@@ -21,7 +24,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
@@ -42,6 +47,9 @@ func (p *portworx) UpdateStorageClusterStatus(
 		return nil
 	}
 
+	// The external kvdb is checked even if the storage cluster is not reachable
+	p.updateExternalKvdbStatus(cluster)
+
 	clientConn, err := p.getPortworxClient(cluster)
 	if err != nil {
 		p.updateRemainingStorageNodesWithoutError(cluster, nil)
@@ -51,10 +59,7 @@ func (p *portworx) UpdateStorageClusterStatus(
 	clusterClient := api.NewOpenStorageClusterClient(clientConn)
 	pxCluster, err := clusterClient.InspectCurrent(context.TODO(), &api.SdkClusterInspectCurrentRequest{})
 	if err != nil {
-		if closeErr := p.sdkConn.Close(); closeErr != nil {
-			logrus.Warnf("Failed to close grpc connection. %v", closeErr)
-		}
-		p.sdkConn = nil
+		p.closePortworxClient(cluster)
 		p.updateRemainingStorageNodesWithoutError(cluster, nil)
 		return fmt.Errorf("failed to inspect cluster: %v", err)
 	} else if pxCluster.Cluster == nil {
@@ -85,7 +90,15 @@ func (p *portworx) updateStorageNodes(
 
 	// Find all k8s nodes where Portworx is actually running
 	currentPxNodes := make(map[string]bool)
+	storageNodes := make(map[string]*corev1alpha1.StorageNode)
+	var oldestVersion *version.Version
 	for _, node := range nodeEnumerateResponse.Nodes {
+		if nodeVersion, err := version.NewVersion(node.NodeLabels[labelPortworxVersion]); err == nil &&
+			(oldestVersion == nil || nodeVersion.LessThan(oldestVersion)) {
+			oldestVersion = nodeVersion
+			cluster.Status.Version = node.NodeLabels[labelPortworxVersion]
+		}
+
 		if node.SchedulerNodeName == "" {
 			k8sNode, err := coreops.Instance().SearchNodeByAddresses(
 				[]string{node.DataIp, node.MgmtIp, node.Hostname},
@@ -106,16 +119,77 @@ func (p *portworx) updateStorageNodes(
 			continue
 		}
 
-		err = p.updateStorageNodeStatus(storageNode, node)
+		err = p.updateStorageNodeStatus(clientConn, cluster, storageNode, node)
 		if err != nil {
 			msg := fmt.Sprintf("Failed to update StorageNode status for nodeID %v: %v", node.Id, err)
 			p.warningEvent(cluster, util.FailedSyncReason, msg)
 		}
+		storageNodes[node.Id] = storageNode
 	}
 
+	updateClusterStorageStatus(cluster, nodeEnumerateResponse.Nodes, storageNodes)
+	updateKvdbMembers(cluster, nodeEnumerateResponse.Nodes, storageNodes)
+	if err := p.updateAlerts(clientConn, cluster, storageNodes); err != nil {
+		logrus.Warnf("Failed to update alerts: %v", err)
+	}
 	return p.updateRemainingStorageNodes(cluster, currentPxNodes)
 }
 
+// updateClusterStorageStatus aggregates the capacity, node counts, zones and
+// versions of the given nodes in the storage status of the cluster. The zones
+// and versions are taken from the StorageNodes, keyed by the storage node ID.
+func updateClusterStorageStatus(
+	cluster *corev1alpha1.StorageCluster,
+	nodes []*api.StorageNode,
+	storageNodes map[string]*corev1alpha1.StorageNode,
+) {
+	var totalSize, usedSize uint64
+	var storageCount, storagelessCount int32
+	nodesPerZone := make(map[string]int32)
+	versions := make(map[string]bool)
+	for _, node := range nodes {
+		if len(node.Pools) == 0 {
+			storagelessCount++
+		} else {
+			storageCount++
+		}
+		for _, pool := range node.Pools {
+			if pool != nil {
+				totalSize += pool.TotalSize
+				usedSize += pool.Used
+			}
+		}
+
+		storageNode, ok := storageNodes[node.Id]
+		if !ok {
+			continue
+		}
+		if zone := storageNode.Status.Geo.Zone; zone != "" {
+			nodesPerZone[zone]++
+		}
+		if storageNode.Spec.Version != "" {
+			versions[storageNode.Spec.Version] = true
+		}
+	}
+
+	status := &cluster.Status.Storage
+	total := bytesToQuantity(totalSize)
+	used := bytesToQuantity(usedSize)
+	status.TotalSize = &total
+	status.UsedSize = &used
+	status.StorageNodes = storageCount
+	status.StoragelessNodes = storagelessCount
+	status.NodesPerZone = nil
+	if len(nodesPerZone) > 0 {
+		status.NodesPerZone = nodesPerZone
+	}
+	status.Versions = nil
+	for v := range versions {
+		status.Versions = append(status.Versions, v)
+	}
+	sort.Strings(status.Versions)
+}
+
 func (p *portworx) updateRemainingStorageNodesWithoutError(
 	cluster *corev1alpha1.StorageCluster,
 	currentPxNodes map[string]bool,
@@ -153,18 +227,50 @@ func (p *portworx) updateRemainingStorageNodes(
 	}
 
 	storageNodes := &corev1alpha1.StorageNodeList{}
-	if err = p.k8sClient.List(context.TODO(), storageNodes, &client.ListOptions{}); err != nil {
+	err = p.k8sClient.List(
+		context.TODO(),
+		storageNodes,
+		&client.ListOptions{Namespace: cluster.Namespace},
+	)
+	if err != nil {
 		return fmt.Errorf("failed to get a list of StorageNode: %v", err)
 	}
 
 	for _, storageNode := range storageNodes.Items {
+		// Skip StorageNodes that belong to other StorageClusters in the namespace
+		controllerRef := metav1.GetControllerOf(&storageNode)
+		if controllerRef != nil && controllerRef.UID != cluster.UID {
+			continue
+		}
+
 		pxNodeExists := currentPxNodes[storageNode.Name]
 		pxPodExists := currentPxPodNodes[storageNode.Name]
-		if !pxNodeExists && !pxPodExists {
+		if getNodeCondition(&storageNode.Status, corev1alpha1.NodeDecommissionCondition) != nil {
+			// StorageNodes of nodes being decommissioned are removed by the controller.
+			// A node missing from a successful enumerate is no longer in the cluster.
+			if !pxNodeExists && currentPxNodes != nil {
+				if err := p.updateRemovedNodeDecommission(cluster, storageNode.DeepCopy()); err != nil {
+					msg := fmt.Sprintf("Failed to update StorageNode %v/%v: %v",
+						storageNode.Namespace, storageNode.Name, err)
+					p.warningEvent(cluster, util.FailedSyncReason, msg)
+				}
+			}
+			continue
+		}
+		if !pxNodeExists && !pxPodExists && isPreflightPending(&storageNode.Status) {
+			// The StorageNode has been created for the preflight checks on the node
+			// and is managed by the controller until the portworx pod is created
+			continue
+		} else if !pxNodeExists && !pxPodExists {
 			logrus.Debugf("Deleting orphan StorageNode %v/%v",
 				storageNode.Namespace, storageNode.Name)
 
-			err = p.k8sClient.Delete(context.TODO(), storageNode.DeepCopy())
+			// The node is no longer in the cluster, so it does not need to be
+			// decommissioned when the StorageNode is deleted
+			err = p.removeStorageNodeFinalizer(storageNode.DeepCopy())
+			if err == nil {
+				err = p.k8sClient.Delete(context.TODO(), storageNode.DeepCopy())
+			}
 			if err != nil && !errors.IsNotFound(err) {
 				msg := fmt.Sprintf("Failed to delete StorageNode %v/%v: %v",
 					storageNode.Namespace, storageNode.Name, err)
@@ -241,6 +347,8 @@ func (p *portworx) updateStorageNodeSpec(
 }
 
 func (p *portworx) updateStorageNodeStatus(
+	clientConn *grpc.ClientConn,
+	cluster *corev1alpha1.StorageCluster,
 	storageNode *corev1alpha1.StorageNode,
 	node *api.StorageNode,
 ) error {
@@ -250,14 +358,21 @@ func (p *portworx) updateStorageNodeStatus(
 		DataIP: node.DataIp,
 		MgmtIP: node.MgmtIp,
 	}
+	storageNode.Status.Storage = getStorageStatus(node)
+	if err := p.updateNodeGeography(storageNode); err != nil {
+		logrus.Warnf("Failed to get topology of node %s: %v", storageNode.Name, err)
+	}
 	nodeStateCondition := &corev1alpha1.NodeCondition{
 		Type:   corev1alpha1.NodeStateCondition,
 		Status: mapNodeStatus(node.Status),
 	}
 	operatorops.Instance().UpdateStorageNodeCondition(&storageNode.Status, nodeStateCondition)
+	p.updateNodeMaintenance(cluster, storageNode, node)
+	p.updateNodeDecommission(clientConn, cluster, storageNode, node)
+	p.updateNodePools(cluster, storageNode, node)
 	storageNode.Status.Phase = getStorageNodePhase(&storageNode.Status)
 
-	if !reflect.DeepEqual(originalStorageNodeStatus, &storageNode.Status) {
+	if !equality.Semantic.DeepEqual(originalStorageNodeStatus, &storageNode.Status) {
 		logrus.Debugf("Updating StorageNode %s/%s status",
 			storageNode.Namespace, storageNode.Name)
 		return p.k8sClient.Status().Update(context.TODO(), storageNode)
@@ -265,11 +380,97 @@ func (p *portworx) updateStorageNodeStatus(
 	return nil
 }
 
+// updateNodeGeography sets the zone and region of the StorageNode from the
+// topology labels of the corresponding kubernetes node
+func (p *portworx) updateNodeGeography(storageNode *corev1alpha1.StorageNode) error {
+	k8sNode := &v1.Node{}
+	err := p.k8sClient.Get(context.TODO(), types.NamespacedName{Name: storageNode.Name}, k8sNode)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	storageNode.Status.Geo.Zone = k8sNode.Labels[v1.LabelZoneFailureDomain]
+	storageNode.Status.Geo.Region = k8sNode.Labels[v1.LabelZoneRegion]
+	return nil
+}
+
+// getStorageStatus returns the storage pools and drives of the given node,
+// along with the capacity of the node. It returns nil for nodes without storage.
+func getStorageStatus(node *api.StorageNode) *corev1alpha1.StorageStatus {
+	if len(node.Pools) == 0 && len(node.Disks) == 0 {
+		return nil
+	}
+
+	var totalSize, usedSize uint64
+	status := &corev1alpha1.StorageStatus{}
+	for _, pool := range node.Pools {
+		if pool == nil {
+			continue
+		}
+		totalSize += pool.TotalSize
+		usedSize += pool.Used
+		status.Pools = append(status.Pools, corev1alpha1.StoragePoolStatus{
+			ID:        pool.ID,
+			MediaType: mapStorageMedium(pool.Medium),
+			RAIDLevel: pool.RaidLevel,
+			TotalSize: bytesToQuantity(pool.TotalSize),
+			UsedSize:  bytesToQuantity(pool.Used),
+			Labels:    pool.Labels,
+		})
+	}
+	sort.Slice(status.Pools, func(i, j int) bool {
+		return status.Pools[i].ID < status.Pools[j].ID
+	})
+
+	for _, disk := range node.Disks {
+		if disk == nil {
+			continue
+		}
+		status.Drives = append(status.Drives, corev1alpha1.StorageDriveStatus{
+			Path:      disk.Path,
+			MediaType: mapStorageMedium(disk.Medium),
+			TotalSize: bytesToQuantity(disk.Size),
+			UsedSize:  bytesToQuantity(disk.Used),
+			Online:    disk.Online,
+			Metadata:  disk.Metadata,
+		})
+	}
+	sort.Slice(status.Drives, func(i, j int) bool {
+		return status.Drives[i].Path < status.Drives[j].Path
+	})
+
+	status.TotalSize = bytesToQuantity(totalSize)
+	status.UsedSize = bytesToQuantity(usedSize)
+	return status
+}
+
+func bytesToQuantity(bytes uint64) resource.Quantity {
+	return *resource.NewQuantity(int64(bytes), resource.BinarySI)
+}
+
+func mapStorageMedium(medium api.StorageMedium) string {
+	switch medium {
+	case api.StorageMedium_STORAGE_MEDIUM_MAGNETIC:
+		return "HDD"
+	case api.StorageMedium_STORAGE_MEDIUM_SSD:
+		return "SSD"
+	case api.StorageMedium_STORAGE_MEDIUM_NVME:
+		return "NVMe"
+	}
+	return ""
+}
+
 func (p *portworx) getPortworxClient(
 	cluster *corev1alpha1.StorageCluster,
 ) (*grpc.ClientConn, error) {
-	if p.sdkConn != nil {
-		return p.sdkConn, nil
+	var rootCA []byte
+	if pxutil.TLSEnabled(cluster) {
+		var err error
+		rootCA, err = pxutil.GetTLSRootCA(p.k8sClient, cluster)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get TLS CA certificate: %v", err)
+		}
 	}
 
 	pxService := &v1.Service{}
@@ -298,19 +499,78 @@ func (p *portworx) getPortworxClient(
 	}
 
 	endpoint = fmt.Sprintf("%s:%d", endpoint, sdkPort)
-	return p.getGrpcConn(endpoint)
+
+	// Connections are cached per storage cluster, as each cluster has its
+	// own endpoint and security settings
+	key := clusterKey(cluster)
+	if sdkConn, exists := p.sdkConns[key]; exists {
+		if sdkConn.endpoint == endpoint &&
+			sdkConn.credentials.matches(cluster) &&
+			bytes.Equal(sdkConn.rootCA, rootCA) {
+			return sdkConn.conn, nil
+		}
+		// Reconnect with the endpoint, credentials and CA needed for the
+		// current cluster spec
+		p.closePortworxClient(cluster)
+	}
+
+	var tokenCreds *tokenCredentials
+	if pxutil.SecurityEnabled(cluster) {
+		tokenCreds = newTokenCredentials(p.k8sClient, cluster)
+	}
+	conn, err := getGrpcConn(endpoint, tokenCreds, rootCA, pxutil.TLSServerName(cluster))
+	if err != nil {
+		return nil, err
+	}
+	if p.sdkConns == nil {
+		p.sdkConns = make(map[string]*sdkConnection)
+	}
+	p.sdkConns[key] = &sdkConnection{
+		conn:        conn,
+		endpoint:    endpoint,
+		credentials: tokenCreds,
+		rootCA:      rootCA,
+	}
+	return conn, nil
+}
+
+// closePortworxClient closes the cached grpc connection of the given cluster
+func (p *portworx) closePortworxClient(cluster *corev1alpha1.StorageCluster) {
+	key := clusterKey(cluster)
+	sdkConn, exists := p.sdkConns[key]
+	if !exists {
+		return
+	}
+	if closeErr := sdkConn.conn.Close(); closeErr != nil {
+		logrus.Warnf("Failed to close grpc connection. %v", closeErr)
+	}
+	delete(p.sdkConns, key)
 }
 
-func (p *portworx) getGrpcConn(endpoint string) (*grpc.ClientConn, error) {
-	dialOptions, err := getDialOptions(isTLSEnabled())
+func getGrpcConn(
+	endpoint string,
+	tokenCreds *tokenCredentials,
+	rootCA []byte,
+	serverName string,
+) (*grpc.ClientConn, error) {
+	var dialOptions []grpc.DialOption
+	var err error
+	if len(rootCA) > 0 {
+		dialOptions, err = getVerifiedDialOptions(rootCA, serverName)
+	} else {
+		dialOptions, err = getDialOptions(isTLSEnabled())
+	}
 	if err != nil {
 		return nil, err
 	}
-	p.sdkConn, err = grpcserver.Connect(endpoint, dialOptions)
+	if tokenCreds != nil {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(tokenCreds))
+	}
+	conn, err := grpcserver.Connect(endpoint, dialOptions)
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to GRPC server [%s]: %v", endpoint, err)
 	}
-	return p.sdkConn, nil
+	return conn, nil
 }
 
 func getDialOptions(tls bool) ([]grpc.DialOption, error) {
@@ -326,6 +586,19 @@ func getDialOptions(tls bool) ([]grpc.DialOption, error) {
 	)}, nil
 }
 
+// getVerifiedDialOptions returns the options to connect over TLS to a server
+// whose certificate is issued by the given CA for the given server name. The
+// server name is needed as the connection is made to the service IP.
+func getVerifiedDialOptions(rootCA []byte, serverName string) ([]grpc.DialOption, error) {
+	capool := x509.NewCertPool()
+	if !capool.AppendCertsFromPEM(rootCA) {
+		return nil, fmt.Errorf("failed to parse TLS CA certificate")
+	}
+	return []grpc.DialOption{grpc.WithTransportCredentials(
+		credentials.NewClientTLSFromCert(capool, serverName),
+	)}, nil
+}
+
 func mapClusterStatus(status api.Status) corev1alpha1.ClusterConditionStatus {
 	switch status {
 	case api.Status_STATUS_NONE:
@@ -411,6 +684,17 @@ func getStorageNodePhase(status *corev1alpha1.NodeStatus) string {
 	var latestCondition *corev1alpha1.NodeCondition
 
 	for _, condition := range status.Conditions {
+		// The upgrade, preflight, maintenance, decommission and pool expansion
+		// conditions track the progress of a rolling update, the checks before
+		// install and the requests made through the StorageNode and StorageCluster
+		// specs, and do not reflect the state of the node
+		if condition.Type == corev1alpha1.NodeUpgradeCondition ||
+			condition.Type == corev1alpha1.NodePreflightCondition ||
+			condition.Type == corev1alpha1.NodeMaintenanceCondition ||
+			condition.Type == corev1alpha1.NodeDecommissionCondition ||
+			condition.Type == corev1alpha1.NodePoolExpansionCondition {
+			continue
+		}
 		if latestTime.Before(&condition.LastTransitionTime) ||
 			latestTime.Equal(&condition.LastTransitionTime) {
 			latestCondition = condition.DeepCopy()
@@ -429,6 +713,18 @@ func getStorageNodePhase(status *corev1alpha1.NodeStatus) string {
 	return string(latestCondition.Status)
 }
 
+// isPreflightPending returns true if the preflight checks on the node are still
+// running or have failed, so the portworx pod has not been created on it yet
+func isPreflightPending(status *corev1alpha1.NodeStatus) bool {
+	for _, condition := range status.Conditions {
+		if condition.Type == corev1alpha1.NodePreflightCondition {
+			return condition.Status == corev1alpha1.NodeInitStatus ||
+				condition.Status == corev1alpha1.NodeFailedStatus
+		}
+	}
+	return false
+}
+
 func isTLSEnabled() bool {
 	enabled, err := strconv.ParseBool(os.Getenv(envKeyPortworxEnableTLS))
 	return err == nil && enabled
@@ -3,6 +3,8 @@ package portworx
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
 	"regexp"
 	"strconv"
@@ -70,6 +72,13 @@ type UninstallPortworx interface {
 	// GetNodeWiperStatus returns the status of the node-wiper daemonset
 	// returns the no. of completed, in progress and total pods
 	GetNodeWiperStatus() (int32, int32, int32, error)
+	// RunNodeWiperOnNode runs the node-wiper pod on the given node only
+	RunNodeWiperOnNode(wiperImage, nodeName string, removeData bool) error
+	// GetNodeWiperStatusOnNode returns true if the node-wiper pod on the given
+	// node has completed
+	GetNodeWiperStatusOnNode(nodeName string) (bool, error)
+	// RemoveNodeWiperOnNode deletes the node-wiper pod of the given node
+	RemoveNodeWiperOnNode(nodeName string) error
 	// WipeMetadata wipes the metadata associated with Portworx cluster
 	WipeMetadata() error
 }
@@ -137,12 +146,24 @@ func (u *uninstallPortworx) WipeMetadata() error {
 		return nil
 	}
 
+	var opts map[string]string
 	if len(u.cluster.Spec.Kvdb.AuthSecret) != 0 {
-		// TODO: Add support for wiping authenticated kvdb
-		logrus.Warnf("Wiping kvdb data from an authenticated kvdb not supported")
-		return nil
+		secret, err := getKvdbAuthSecret(u.k8sClient, u.cluster)
+		if err != nil {
+			return err
+		}
+		certDir, err := ioutil.TempDir("", kvdbCertDirPrefix)
+		if err != nil {
+			return fmt.Errorf("failed to create directory for kvdb certificates: %v", err)
+		}
+		defer os.RemoveAll(certDir)
+
+		opts, err = getKvdbAuthOptions(secret, certDir)
+		if err != nil {
+			return err
+		}
 	}
-	kv, err := getKVDBClient(u.cluster.Spec.Kvdb.Endpoints, nil)
+	kv, err := getKVDBClient(u.cluster.Spec.Kvdb.Endpoints, opts)
 	if err != nil {
 		logrus.Warnf("Failed to create a kvdb client for %v", u.cluster.Spec.Kvdb.Endpoints)
 		return err
@@ -154,33 +175,108 @@ func (u *uninstallPortworx) RunNodeWiper(
 	wiperImage string,
 	removeData bool,
 ) error {
-	pwxHostPathRoot := "/"
+	labels := map[string]string{
+		"name": pxNodeWiperDaemonSetName,
+	}
 
-	enabled, err := strconv.ParseBool(u.cluster.Annotations[annotationIsPKS])
-	isPKS := err == nil && enabled
+	ownerRef := metav1.NewControllerRef(u.cluster, pxutil.StorageClusterKind())
+	if err := u.createNodeWiperRBAC(ownerRef); err != nil {
+		return err
+	}
 
-	if isPKS {
-		pwxHostPathRoot = pksPersistentStoreRoot
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pxNodeWiperDaemonSetName,
+			Namespace:       u.cluster.Namespace,
+			Labels:          labels,
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: u.nodeWiperPodSpec(wiperImage, removeData),
+			},
+		},
 	}
 
-	trueVar := true
-	labels := map[string]string{
-		"name": pxNodeWiperDaemonSetName,
+	if u.cluster.Spec.Placement != nil && u.cluster.Spec.Placement.NodeAffinity != nil {
+		ds.Spec.Template.Spec.Affinity = &v1.Affinity{
+			NodeAffinity: u.cluster.Spec.Placement.NodeAffinity.DeepCopy(),
+		}
 	}
 
-	if len(wiperImage) == 0 {
-		wiperImage = defaultNodeWiperImage
+	return u.k8sClient.Create(context.TODO(), ds)
+}
+
+func (u *uninstallPortworx) RunNodeWiperOnNode(
+	wiperImage string,
+	nodeName string,
+	removeData bool,
+) error {
+	ownerRef := metav1.NewControllerRef(u.cluster, pxutil.StorageClusterKind())
+	if err := u.createNodeWiperRBAC(ownerRef); err != nil {
+		return err
 	}
-	wiperImage = util.GetImageURN(u.cluster.Spec.CustomImageRegistry, wiperImage)
 
-	args := []string{"-w"}
-	if removeData {
-		args = append(args, "-r")
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nodeWiperPodName(nodeName),
+			Namespace: u.cluster.Namespace,
+			Labels: map[string]string{
+				"name": pxNodeWiperDaemonSetName,
+			},
+			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+		},
+		Spec: u.nodeWiperPodSpec(wiperImage, removeData),
 	}
+	// The node may no longer match the placement of the cluster, so the
+	// pod is bound to the node directly without the placement affinity
+	pod.Spec.NodeName = nodeName
 
-	ownerRef := metav1.NewControllerRef(u.cluster, pxutil.StorageClusterKind())
+	err := u.k8sClient.Create(context.TODO(), pod)
+	if errors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (u *uninstallPortworx) GetNodeWiperStatusOnNode(nodeName string) (bool, error) {
+	pod := &v1.Pod{}
+	err := u.k8sClient.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      nodeWiperPodName(nodeName),
+			Namespace: u.cluster.Namespace,
+		},
+		pod,
+	)
+	if err != nil {
+		return false, err
+	}
+	return len(pod.Status.ContainerStatuses) > 0 && pod.Status.ContainerStatuses[0].Ready, nil
+}
+
+func (u *uninstallPortworx) RemoveNodeWiperOnNode(nodeName string) error {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nodeWiperPodName(nodeName),
+			Namespace: u.cluster.Namespace,
+		},
+	}
+	err := u.k8sClient.Delete(context.TODO(), pod)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
 
-	err = u.createServiceAccount(ownerRef)
+func (u *uninstallPortworx) createNodeWiperRBAC(ownerRef *metav1.OwnerReference) error {
+	err := u.createServiceAccount(ownerRef)
 	if err != nil && !errors.IsAlreadyExists(err) {
 		return err
 	}
@@ -194,170 +290,179 @@ func (u *uninstallPortworx) RunNodeWiper(
 	if err != nil && !errors.IsAlreadyExists(err) {
 		return err
 	}
+	return nil
+}
 
-	ds := &appsv1.DaemonSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            pxNodeWiperDaemonSetName,
-			Namespace:       u.cluster.Namespace,
-			Labels:          labels,
-			OwnerReferences: []metav1.OwnerReference{*ownerRef},
+func (u *uninstallPortworx) nodeWiperPodSpec(
+	wiperImage string,
+	removeData bool,
+) v1.PodSpec {
+	pwxHostPathRoot := "/"
+
+	enabled, err := strconv.ParseBool(u.cluster.Annotations[annotationIsPKS])
+	isPKS := err == nil && enabled
+
+	if isPKS {
+		pwxHostPathRoot = pksPersistentStoreRoot
+	}
+
+	trueVar := true
+
+	if len(wiperImage) == 0 {
+		wiperImage = defaultNodeWiperImage
+	}
+	wiperImage = util.GetImageURN(u.cluster.Spec.CustomImageRegistry, wiperImage)
+
+	args := []string{"-w"}
+	if removeData {
+		args = append(args, "-r")
+	}
+
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{
+				Name:            pxNodeWiperDaemonSetName,
+				Image:           wiperImage,
+				ImagePullPolicy: pxutil.ImagePullPolicy(u.cluster),
+				Args:            args,
+				SecurityContext: &v1.SecurityContext{
+					Privileged: &trueVar,
+				},
+				ReadinessProbe: &v1.Probe{
+					InitialDelaySeconds: 30,
+					Handler: v1.Handler{
+						Exec: &v1.ExecAction{
+							Command: []string{"cat", "/tmp/px-node-wipe-done"},
+						},
+					},
+				},
+				VolumeMounts: []v1.VolumeMount{
+					{
+						Name:      dsEtcPwxVolumeName,
+						MountPath: pxEtcPwx,
+					},
+					{
+						Name:      dsHostProcVolumeName,
+						MountPath: "/hostproc",
+					},
+					{
+						Name:      dsOptPwxVolumeName,
+						MountPath: pxOptPwx,
+					},
+					{
+						Name:      dsDbusVolumeName,
+						MountPath: dbusPath,
+					},
+					{
+						Name:      dsSysdVolumeName,
+						MountPath: sysdmount,
+					},
+					{
+						Name:      dsDevVolumeName,
+						MountPath: devMount,
+					},
+					{
+						Name:      dsLvmVolumeName,
+						MountPath: lvmMount,
+					},
+					{
+						Name:      dsMultipathVolumeName,
+						MountPath: multipathMount,
+					},
+					{
+						Name:      dsUdevVolumeName,
+						MountPath: udevMount,
+						ReadOnly:  true,
+					},
+					{
+						Name:      dsSysVolumeName,
+						MountPath: sysMount,
+					},
+				},
+			},
 		},
-		Spec: appsv1.DaemonSetSpec{
-			Selector: &metav1.LabelSelector{
-				MatchLabels: labels,
+		RestartPolicy:      "Always",
+		ServiceAccountName: pxNodeWiperServiceAccountName,
+		Volumes: []v1.Volume{
+			{
+				Name: dsEtcPwxVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: path.Join(pwxHostPathRoot, pxEtcPwx),
+					},
+				},
 			},
-			Template: v1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
+			{
+				Name: dsHostProcVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: "/proc",
+					},
 				},
-				Spec: v1.PodSpec{
-					Containers: []v1.Container{
-						{
-							Name:            pxNodeWiperDaemonSetName,
-							Image:           wiperImage,
-							ImagePullPolicy: pxutil.ImagePullPolicy(u.cluster),
-							Args:            args,
-							SecurityContext: &v1.SecurityContext{
-								Privileged: &trueVar,
-							},
-							ReadinessProbe: &v1.Probe{
-								InitialDelaySeconds: 30,
-								Handler: v1.Handler{
-									Exec: &v1.ExecAction{
-										Command: []string{"cat", "/tmp/px-node-wipe-done"},
-									},
-								},
-							},
-							VolumeMounts: []v1.VolumeMount{
-								{
-									Name:      dsEtcPwxVolumeName,
-									MountPath: pxEtcPwx,
-								},
-								{
-									Name:      dsHostProcVolumeName,
-									MountPath: "/hostproc",
-								},
-								{
-									Name:      dsOptPwxVolumeName,
-									MountPath: pxOptPwx,
-								},
-								{
-									Name:      dsDbusVolumeName,
-									MountPath: dbusPath,
-								},
-								{
-									Name:      dsSysdVolumeName,
-									MountPath: sysdmount,
-								},
-								{
-									Name:      dsDevVolumeName,
-									MountPath: devMount,
-								},
-								{
-									Name:      dsLvmVolumeName,
-									MountPath: lvmMount,
-								},
-								{
-									Name:      dsMultipathVolumeName,
-									MountPath: multipathMount,
-								},
-								{
-									Name:      dsUdevVolumeName,
-									MountPath: udevMount,
-									ReadOnly:  true,
-								},
-								{
-									Name:      dsSysVolumeName,
-									MountPath: sysMount,
-								},
-							},
-						},
+			},
+			{
+				Name: dsOptPwxVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: path.Join(pwxHostPathRoot, pxOptPwx),
 					},
-					RestartPolicy:      "Always",
-					ServiceAccountName: pxNodeWiperServiceAccountName,
-					Volumes: []v1.Volume{
-						{
-							Name: dsEtcPwxVolumeName,
-							VolumeSource: v1.VolumeSource{
-								HostPath: &v1.HostPathVolumeSource{
-									Path: path.Join(pwxHostPathRoot, pxEtcPwx),
-								},
-							},
-						},
-						{
-							Name: dsHostProcVolumeName,
-							VolumeSource: v1.VolumeSource{
-								HostPath: &v1.HostPathVolumeSource{
-									Path: "/proc",
-								},
-							},
-						},
-						{
-							Name: dsOptPwxVolumeName,
-							VolumeSource: v1.VolumeSource{
-								HostPath: &v1.HostPathVolumeSource{
-									Path: path.Join(pwxHostPathRoot, pxOptPwx),
-								},
-							},
-						},
-						{
-							Name: dsDbusVolumeName,
-							VolumeSource: v1.VolumeSource{
-								HostPath: &v1.HostPathVolumeSource{
-									Path: dbusPath,
-								},
-							},
-						},
-						{
-							Name: dsSysdVolumeName,
-							VolumeSource: v1.VolumeSource{
-								HostPath: &v1.HostPathVolumeSource{
-									Path: sysdmount,
-								},
-							},
-						},
-						{
-							Name: dsDevVolumeName,
-							VolumeSource: v1.VolumeSource{
-								HostPath: &v1.HostPathVolumeSource{
-									Path: devMount,
-								},
-							},
-						},
-						{
-							Name: dsMultipathVolumeName,
-							VolumeSource: v1.VolumeSource{
-								HostPath: &v1.HostPathVolumeSource{
-									Path: multipathMount,
-									Type: hostPathTypePtr(v1.HostPathDirectoryOrCreate),
-								},
-							},
-						},
-						{
-							Name: dsLvmVolumeName,
-							VolumeSource: v1.VolumeSource{
-								HostPath: &v1.HostPathVolumeSource{
-									Path: lvmMount,
-									Type: hostPathTypePtr(v1.HostPathDirectoryOrCreate),
-								},
-							},
-						},
-						{
-							Name: dsUdevVolumeName,
-							VolumeSource: v1.VolumeSource{
-								HostPath: &v1.HostPathVolumeSource{
-									Path: udevMount,
-								},
-							},
-						},
-						{
-							Name: dsSysVolumeName,
-							VolumeSource: v1.VolumeSource{
-								HostPath: &v1.HostPathVolumeSource{
-									Path: sysMount,
-								},
-							},
-						},
+				},
+			},
+			{
+				Name: dsDbusVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: dbusPath,
+					},
+				},
+			},
+			{
+				Name: dsSysdVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: sysdmount,
+					},
+				},
+			},
+			{
+				Name: dsDevVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: devMount,
+					},
+				},
+			},
+			{
+				Name: dsMultipathVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: multipathMount,
+						Type: hostPathTypePtr(v1.HostPathDirectoryOrCreate),
+					},
+				},
+			},
+			{
+				Name: dsLvmVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: lvmMount,
+						Type: hostPathTypePtr(v1.HostPathDirectoryOrCreate),
+					},
+				},
+			},
+			{
+				Name: dsUdevVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: udevMount,
+					},
+				},
+			},
+			{
+				Name: dsSysVolumeName,
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{
+						Path: sysMount,
 					},
 				},
 			},
@@ -365,7 +470,7 @@ func (u *uninstallPortworx) RunNodeWiper(
 	}
 
 	if u.cluster.Spec.ImagePullSecret != nil && *u.cluster.Spec.ImagePullSecret != "" {
-		ds.Spec.Template.Spec.ImagePullSecrets = append(
+		podSpec.ImagePullSecrets = append(
 			[]v1.LocalObjectReference{},
 			v1.LocalObjectReference{
 				Name: *u.cluster.Spec.ImagePullSecret,
@@ -373,25 +478,21 @@ func (u *uninstallPortworx) RunNodeWiper(
 		)
 	}
 
-	if u.cluster.Spec.Placement != nil {
-		if u.cluster.Spec.Placement.NodeAffinity != nil {
-			ds.Spec.Template.Spec.Affinity = &v1.Affinity{
-				NodeAffinity: u.cluster.Spec.Placement.NodeAffinity.DeepCopy(),
-			}
-		}
-
-		if len(u.cluster.Spec.Placement.Tolerations) > 0 {
-			ds.Spec.Template.Spec.Tolerations = make([]v1.Toleration, 0)
-			for _, toleration := range u.cluster.Spec.Placement.Tolerations {
-				ds.Spec.Template.Spec.Tolerations = append(
-					ds.Spec.Template.Spec.Tolerations,
-					*(toleration.DeepCopy()),
-				)
-			}
+	if u.cluster.Spec.Placement != nil && len(u.cluster.Spec.Placement.Tolerations) > 0 {
+		podSpec.Tolerations = make([]v1.Toleration, 0)
+		for _, toleration := range u.cluster.Spec.Placement.Tolerations {
+			podSpec.Tolerations = append(
+				podSpec.Tolerations,
+				*(toleration.DeepCopy()),
+			)
 		}
 	}
 
-	return u.k8sClient.Create(context.TODO(), ds)
+	return podSpec
+}
+
+func nodeWiperPodName(nodeName string) string {
+	return fmt.Sprintf("%s-%s", pxNodeWiperDaemonSetName, nodeName)
 }
 
 func (u *uninstallPortworx) createServiceAccount(
@@ -417,7 +518,7 @@ func (u *uninstallPortworx) createClusterRole(
 		u.k8sClient,
 		&rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            pxNodeWiperClusterRoleName,
+				Name:            util.ClusterScopedName(pxNodeWiperClusterRoleName, u.cluster.Namespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Rules: []rbacv1.PolicyRule{
@@ -440,7 +541,7 @@ func (u *uninstallPortworx) createClusterRoleBinding(
 		u.k8sClient,
 		&rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            pxNodeWiperClusterRoleBindingName,
+				Name:            util.ClusterScopedName(pxNodeWiperClusterRoleBindingName, u.cluster.Namespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Subjects: []rbacv1.Subject{
@@ -452,7 +553,7 @@ func (u *uninstallPortworx) createClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     pxNodeWiperClusterRoleName,
+				Name:     util.ClusterScopedName(pxNodeWiperClusterRoleName, u.cluster.Namespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
@@ -462,6 +563,9 @@ func (u *uninstallPortworx) createClusterRoleBinding(
 
 func getKVDBClient(endpoints []string, opts map[string]string) (kvdb.Kvdb, error) {
 	var urlPrefix, kvdbType, kvdbName string
+	// Do not change the endpoints in the cluster spec, as the wipe is retried
+	// with them if it fails
+	endpoints = append([]string{}, endpoints...)
 	for i, url := range endpoints {
 		urlTokens := strings.Split(url, ":")
 		if i == 0 {
@@ -0,0 +1,183 @@
+package portworx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libopenstorage/openstorage/api"
+	"github.com/libopenstorage/operator/drivers/storage/portworx/manifest"
+	pxutil "github.com/libopenstorage/operator/drivers/storage/portworx/util"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func (p *portworx) PreNodeUpgrade(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+) error {
+	if !pxutil.IsPortworxEnabled(cluster) {
+		return nil
+	}
+
+	clientConn, err := p.getPortworxClient(cluster)
+	if err != nil {
+		return err
+	}
+
+	// The cluster should be in quorum and not degraded before taking down
+	// another node
+	clusterClient := api.NewOpenStorageClusterClient(clientConn)
+	pxCluster, err := clusterClient.InspectCurrent(context.TODO(), &api.SdkClusterInspectCurrentRequest{})
+	if err != nil {
+		p.closePortworxClient(cluster)
+		return fmt.Errorf("failed to inspect cluster: %v", err)
+	} else if pxCluster.Cluster == nil {
+		return fmt.Errorf("empty ClusterInspect response")
+	} else if pxCluster.Cluster.Status != api.Status_STATUS_OK {
+		return fmt.Errorf("storage cluster is not healthy, status is %s",
+			pxCluster.Cluster.Status)
+	}
+
+	// All other nodes should be online and no node should be resyncing data,
+	// so that the volume replicas on the upgraded node are available elsewhere
+	nodeClient := api.NewOpenStorageNodeClient(clientConn)
+	nodeEnumerateResponse, err := nodeClient.EnumerateWithFilters(
+		context.TODO(),
+		&api.SdkNodeEnumerateWithFiltersRequest{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enumerate nodes: %v", err)
+	}
+
+	for _, node := range nodeEnumerateResponse.Nodes {
+		switch node.Status {
+		case api.Status_STATUS_OK, api.Status_STATUS_DECOMMISSION:
+			continue
+		case api.Status_STATUS_STORAGE_REBALANCE, api.Status_STATUS_STORAGE_DEGRADED:
+			return fmt.Errorf("replicas are being resynced on node %s", storageNodeName(node))
+		}
+		if node.SchedulerNodeName == nodeName {
+			// The node being upgraded can already be down
+			continue
+		}
+		return fmt.Errorf("node %s is not healthy, status is %s",
+			storageNodeName(node), mapNodeStatus(node.Status))
+	}
+	return nil
+}
+
+func (p *portworx) ValidateRollback(
+	current *corev1alpha1.StorageCluster,
+	target *corev1alpha1.StorageCluster,
+) error {
+	if !pxutil.IsPortworxEnabled(current) {
+		return nil
+	}
+
+	currentVersion := pxutil.GetPortworxVersion(current)
+	targetVersion := pxutil.GetPortworxVersion(target)
+	if !targetVersion.LessThan(currentVersion) {
+		return nil
+	}
+
+	// Not all Portworx releases can be rolled back to older releases, for
+	// instance if the on-disk format has changed
+	releases, err := manifest.NewReleaseManifest()
+	if err != nil {
+		return fmt.Errorf("failed to load release manifest to check if Portworx "+
+			"can be rolled back from %s to %s: %v", currentVersion, targetVersion, err)
+	}
+	return releases.ValidateRollback(currentVersion, targetVersion)
+}
+
+func (p *portworx) GetPodsUsingStorage(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+) ([]v1.Pod, error) {
+	if !pxutil.IsPortworxEnabled(cluster) {
+		return nil, nil
+	}
+
+	podList := &v1.PodList{}
+	err := p.k8sClient.List(context.TODO(), podList, &client.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list of pods: %v", err)
+	}
+
+	pods := make([]v1.Pod, 0)
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != nodeName ||
+			pod.Status.Phase == v1.PodSucceeded ||
+			pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		usesPortworx, err := p.podUsesPortworxVolume(&pod)
+		if err != nil {
+			return nil, err
+		}
+		if usesPortworx {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func (p *portworx) podUsesPortworxVolume(pod *v1.Pod) (bool, error) {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PortworxVolume != nil || isPortworxCSIVolume(volume.CSI) {
+			return true, nil
+		}
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+
+		pvc := &v1.PersistentVolumeClaim{}
+		err := p.k8sClient.Get(
+			context.TODO(),
+			types.NamespacedName{
+				Name:      volume.PersistentVolumeClaim.ClaimName,
+				Namespace: pod.Namespace,
+			},
+			pvc,
+		)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, fmt.Errorf("failed to get PVC %s/%s: %v",
+				pod.Namespace, volume.PersistentVolumeClaim.ClaimName, err)
+		} else if len(pvc.Spec.VolumeName) == 0 {
+			continue
+		}
+
+		pv := &v1.PersistentVolume{}
+		err = p.k8sClient.Get(context.TODO(), types.NamespacedName{Name: pvc.Spec.VolumeName}, pv)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, fmt.Errorf("failed to get PV %s: %v", pvc.Spec.VolumeName, err)
+		}
+		if pv.Spec.PortworxVolume != nil ||
+			(pv.Spec.CSI != nil && isPortworxCSIDriver(pv.Spec.CSI.Driver)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isPortworxCSIVolume(csi *v1.CSIVolumeSource) bool {
+	return csi != nil && isPortworxCSIDriver(csi.Driver)
+}
+
+func isPortworxCSIDriver(driver string) bool {
+	return driver == pxutil.CSIDriverName || driver == pxutil.DeprecatedCSIDriverName
+}
+
+func storageNodeName(node *api.StorageNode) string {
+	if len(node.SchedulerNodeName) > 0 {
+		return node.SchedulerNodeName
+	}
+	return node.Id
+}
@@ -0,0 +1,390 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+	"time"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	k8sutil "github.com/libopenstorage/operator/pkg/util/k8s"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TLSCASecretName name of the secret with the CA generated by the operator
+	TLSCASecretName = "px-tls-ca"
+	// TLSNodeLabelKey label key of the node certificate secrets, whose value
+	// is the name of the node the certificate is issued for
+	TLSNodeLabelKey = "portworx.io/tls-node"
+	// TLSCertKey key of the certificate in the TLS secrets
+	TLSCertKey = v1.TLSCertKey
+	// TLSPrivateKeyKey key of the private key in the TLS secrets
+	TLSPrivateKeyKey = v1.TLSPrivateKeyKey
+	// TLSCACertKey key of the CA certificate in the node certificate secrets
+	TLSCACertKey = "ca.crt"
+	// TLSCertLifetime is the lifetime of the node certificates
+	TLSCertLifetime = 365 * 24 * time.Hour
+	// TLSCertRenewBefore is how long before expiry the certificates are renewed
+	TLSCertRenewBefore = 30 * 24 * time.Hour
+
+	// tlsNodeSecretPrefix is the prefix of the node certificate secrets. It is
+	// different from the CA secret name, so no node secret can replace the CA.
+	tlsNodeSecretPrefix = "px-tls-node-"
+	tlsKeySize          = 2048
+)
+
+// TLSEnabled returns true if TLS is enabled on the storage endpoints in the
+// cluster spec
+func TLSEnabled(cluster *corev1alpha1.StorageCluster) bool {
+	spec := tlsSpec(cluster)
+	return spec != nil && spec.Enabled
+}
+
+// TLSServerCertSecretName returns the name of the user provided secret with
+// the certificate used by all the storage nodes. It returns an empty string
+// if the operator issues the node certificates.
+func TLSServerCertSecretName(cluster *corev1alpha1.StorageCluster) string {
+	spec := tlsSpec(cluster)
+	if spec != nil && spec.ServerCertSecret != nil {
+		return *spec.ServerCertSecret
+	}
+	return ""
+}
+
+// TLSCASecretNameForCluster returns the name of the secret with the CA used to
+// issue the node certificates, either given by the user or generated
+func TLSCASecretNameForCluster(cluster *corev1alpha1.StorageCluster) string {
+	spec := tlsSpec(cluster)
+	if spec != nil && spec.CASecret != nil && *spec.CASecret != "" {
+		return *spec.CASecret
+	}
+	return TLSCASecretName
+}
+
+// TLSNodeSecretName returns the name of the secret with the certificate
+// issued for the given node
+func TLSNodeSecretName(nodeName string) string {
+	return tlsNodeSecretPrefix + nodeName
+}
+
+// TLSServerName returns the host name the storage endpoints are verified
+// against when connecting through the portworx service
+func TLSServerName(cluster *corev1alpha1.StorageCluster) string {
+	return fmt.Sprintf("%s.%s.svc", PortworxServiceName, cluster.Namespace)
+}
+
+// GetTLSRootCA returns the PEM encoded CA certificate that the certificates
+// of the storage endpoints should be verified against
+func GetTLSRootCA(
+	k8sClient client.Client,
+	cluster *corev1alpha1.StorageCluster,
+) ([]byte, error) {
+	secretName, key := TLSCASecretNameForCluster(cluster), TLSCertKey
+	if serverCertSecret := TLSServerCertSecretName(cluster); serverCertSecret != "" {
+		secretName, key = serverCertSecret, TLSCACertKey
+	}
+	secret, err := getSecret(k8sClient, secretName, cluster.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	caPEM := secret.Data[key]
+	if len(caPEM) == 0 {
+		return nil, fmt.Errorf("secret %s does not have the %s key", secretName, key)
+	}
+	return caPEM, nil
+}
+
+// EnsureTLSNodeCertificate makes sure the secret of the given node has a
+// certificate issued by the CA of the cluster for the current addresses of the
+// node. The certificate is renewed before it expires. The CA is generated if
+// the user has not provided one.
+func EnsureTLSNodeCertificate(
+	k8sClient client.Client,
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+	ownerRef *metav1.OwnerReference,
+) error {
+	caCert, caKey, caPEM, err := ensureTLSCA(k8sClient, cluster, ownerRef)
+	if err != nil {
+		return err
+	}
+
+	dnsNames, ips, err := nodeCertificateSANs(k8sClient, cluster, nodeName)
+	if err != nil {
+		return err
+	}
+
+	secretName := TLSNodeSecretName(nodeName)
+	secret, err := getSecret(k8sClient, secretName, cluster.Namespace)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err == nil && secret.Labels[TLSNodeLabelKey] != nodeName {
+		return fmt.Errorf("secret %s already exists and is not the TLS certificate of node %s",
+			secretName, nodeName)
+	} else if err == nil && nodeCertificateValid(secret, caCert, caPEM, dnsNames, ips) {
+		return nil
+	}
+
+	logrus.Infof("Issuing TLS certificate for node %s", nodeName)
+	certPEM, keyPEM, err := issueNodeCertificate(caCert, caKey, nodeName, dnsNames, ips)
+	if err != nil {
+		return fmt.Errorf("failed to issue TLS certificate for node %s: %v", nodeName, err)
+	}
+	return k8sutil.CreateOrUpdateSecret(
+		k8sClient,
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            secretName,
+				Namespace:       cluster.Namespace,
+				Labels:          map[string]string{TLSNodeLabelKey: nodeName},
+				OwnerReferences: []metav1.OwnerReference{*ownerRef},
+			},
+			Type: v1.SecretTypeTLS,
+			Data: map[string][]byte{
+				TLSCertKey:       certPEM,
+				TLSPrivateKeyKey: keyPEM,
+				TLSCACertKey:     caPEM,
+			},
+		},
+		ownerRef,
+	)
+}
+
+// ensureTLSCA returns the CA used to issue the node certificates. A user
+// provided CA is used as is, while the generated CA is regenerated before
+// it expires.
+func ensureTLSCA(
+	k8sClient client.Client,
+	cluster *corev1alpha1.StorageCluster,
+	ownerRef *metav1.OwnerReference,
+) (*x509.Certificate, crypto.Signer, []byte, error) {
+	secretName := TLSCASecretNameForCluster(cluster)
+	secret, err := getSecret(k8sClient, secretName, cluster.Namespace)
+	if err != nil && (secretName != TLSCASecretName || !errors.IsNotFound(err)) {
+		return nil, nil, nil, err
+	}
+
+	if err == nil {
+		caCert, caKey, err := parseCA(secret)
+		if secretName != TLSCASecretName {
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("invalid CA in secret %s: %v", secretName, err)
+			}
+			return caCert, caKey, secret.Data[TLSCertKey], nil
+		} else if err == nil && time.Now().Add(TLSCertRenewBefore).Before(caCert.NotAfter) {
+			return caCert, caKey, secret.Data[TLSCertKey], nil
+		}
+	}
+
+	logrus.Infof("Generating TLS CA in secret %s/%s", cluster.Namespace, secretName)
+	caKey, err := rsa.GenerateKey(rand.Reader, tlsKeySize)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate TLS CA key: %v", err)
+	}
+	caCert, err := certutil.NewSelfSignedCACert(
+		certutil.Config{CommonName: fmt.Sprintf("%s-ca", cluster.Name)},
+		caKey,
+	)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate TLS CA: %v", err)
+	}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: caCert.Raw})
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = k8sutil.CreateOrUpdateSecret(
+		k8sClient,
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            secretName,
+				Namespace:       cluster.Namespace,
+				OwnerReferences: []metav1.OwnerReference{*ownerRef},
+			},
+			Type: v1.SecretTypeTLS,
+			Data: map[string][]byte{
+				TLSCertKey:       caPEM,
+				TLSPrivateKeyKey: keyPEM,
+			},
+		},
+		ownerRef,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return caCert, caKey, caPEM, nil
+}
+
+func parseCA(secret *v1.Secret) (*x509.Certificate, crypto.Signer, error) {
+	certs, err := certutil.ParseCertsPEM(secret.Data[TLSCertKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := keyutil.ParsePrivateKeyPEM(secret.Data[TLSPrivateKeyKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return certs[0], signer, nil
+}
+
+// nodeCertificateSANs returns the host names and addresses the certificate of
+// the given node should be valid for. Besides the node itself, the storage
+// endpoints are reached through the portworx service.
+func nodeCertificateSANs(
+	k8sClient client.Client,
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+) ([]string, []net.IP, error) {
+	serviceName := TLSServerName(cluster)
+	dnsNames := []string{
+		nodeName,
+		"localhost",
+		PortworxServiceName,
+		fmt.Sprintf("%s.%s", PortworxServiceName, cluster.Namespace),
+		serviceName,
+		serviceName + ".cluster.local",
+	}
+	ips := []net.IP{net.ParseIP("127.0.0.1")}
+
+	node := &v1.Node{}
+	err := k8sClient.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, nil, err
+	}
+	for _, address := range node.Status.Addresses {
+		if ip := net.ParseIP(address.Address); ip != nil {
+			ips = append(ips, ip)
+		} else if address.Address != "" && address.Address != nodeName {
+			dnsNames = append(dnsNames, address.Address)
+		}
+	}
+	return dnsNames, ips, nil
+}
+
+// nodeCertificateValid checks if the certificate in the given secret is issued
+// by the given CA for the given host names and addresses, and is not about to
+// expire
+func nodeCertificateValid(
+	secret *v1.Secret,
+	caCert *x509.Certificate,
+	caPEM []byte,
+	dnsNames []string,
+	ips []net.IP,
+) bool {
+	if len(secret.Data[TLSPrivateKeyKey]) == 0 || !bytes.Equal(secret.Data[TLSCACertKey], caPEM) {
+		return false
+	}
+	certs, err := certutil.ParseCertsPEM(secret.Data[TLSCertKey])
+	if err != nil || len(certs) == 0 {
+		return false
+	}
+	cert := certs[0]
+	if time.Now().Add(TLSCertRenewBefore).After(cert.NotAfter) {
+		return false
+	} else if cert.CheckSignatureFrom(caCert) != nil {
+		return false
+	}
+	return reflect.DeepEqual(sortedStrings(cert.DNSNames), sortedStrings(dnsNames)) &&
+		reflect.DeepEqual(sortedIPs(cert.IPAddresses), sortedIPs(ips))
+}
+
+func issueNodeCertificate(
+	caCert *x509.Certificate,
+	caKey crypto.Signer,
+	nodeName string,
+	dnsNames []string,
+	ips []net.IP,
+) ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, tlsKeySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: nodeName},
+		NotBefore:             now.Add(-time.Hour).UTC(),
+		NotAfter:              now.Add(TLSCertLifetime).UTC(),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              dnsNames,
+		IPAddresses:           ips,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: certDER})
+	return certPEM, keyPEM, nil
+}
+
+func getSecret(
+	k8sClient client.Client,
+	name, namespace string,
+) (*v1.Secret, error) {
+	secret := &v1.Secret{}
+	err := k8sClient.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      name,
+			Namespace: namespace,
+		},
+		secret,
+	)
+	return secret, err
+}
+
+func sortedStrings(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func sortedIPs(ips []net.IP) []string {
+	values := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		values = append(values, ip.String())
+	}
+	sort.Strings(values)
+	return values
+}
+
+func tlsSpec(cluster *corev1alpha1.StorageCluster) *corev1alpha1.TLSSpec {
+	if cluster.Spec.Security == nil {
+		return nil
+	}
+	return cluster.Spec.Security.TLS
+}
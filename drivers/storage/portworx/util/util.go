@@ -1,16 +1,22 @@
 package util
 
 import (
+	"context"
+	"fmt"
 	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-version"
+	"github.com/libopenstorage/openstorage/pkg/auth"
 	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
 	"github.com/libopenstorage/operator/pkg/controller/storagecluster"
 	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -59,6 +65,9 @@ const (
 	// AnnotationDisableStorageClass annotation to disable installing default portworx
 	// storage classes
 	AnnotationDisableStorageClass = pxAnnotationPrefix + "/disable-storage-class"
+	// AnnotationSecretGeneratedAt annotation with the time when the value of a
+	// generated security secret was generated
+	AnnotationSecretGeneratedAt = pxAnnotationPrefix + "/generated-at"
 
 	// EnvKeyPXImage key for the environment variable that specifies Portworx image
 	EnvKeyPXImage = "PX_IMAGE"
@@ -77,6 +86,40 @@ const (
 	// EnvKeyDisableCSIAlpha key for the env var that is used to disable CSI
 	// alpha features
 	EnvKeyDisableCSIAlpha = "PORTWORX_DISABLE_CSI_ALPHA"
+	// EnvKeyPortworxAuthJwtIssuer key for the env var with the issuer of the
+	// self signed tokens
+	EnvKeyPortworxAuthJwtIssuer = "PORTWORX_AUTH_JWT_ISSUER"
+	// EnvKeyPortworxAuthJwtSharedSecret key for the env var with the shared
+	// secret used to verify the self signed tokens
+	EnvKeyPortworxAuthJwtSharedSecret = "PORTWORX_AUTH_JWT_SHAREDSECRET"
+	// EnvKeyPortworxAuthSystemKey key for the env var with the secret used by
+	// the storage nodes to authenticate with each other
+	EnvKeyPortworxAuthSystemKey = "PORTWORX_AUTH_SYSTEM_KEY"
+	// EnvKeyPortworxAuthOIDCIssuer key for the env var with the OIDC issuer
+	EnvKeyPortworxAuthOIDCIssuer = "PORTWORX_AUTH_OIDC_ISSUER"
+	// EnvKeyPortworxAuthOIDCClientID key for the env var with the OIDC client ID
+	EnvKeyPortworxAuthOIDCClientID = "PORTWORX_AUTH_OIDC_CLIENTID"
+	// EnvKeyPortworxAuthOIDCCustomNamespace key for the env var with the
+	// namespace of the custom claims in the OIDC tokens
+	EnvKeyPortworxAuthOIDCCustomNamespace = "PORTWORX_AUTH_OIDC_CUSTOM_NAMESPACE"
+
+	// SecuritySharedSecretName name of the generated secret with the shared
+	// secret used to sign the self signed tokens
+	SecuritySharedSecretName = "px-shared-secret"
+	// SecuritySharedSecretKey key of the shared secret in its secret
+	SecuritySharedSecretKey = "shared-secret"
+	// SecuritySystemSecretName name of the generated secret with the system key
+	SecuritySystemSecretName = "px-system-secrets"
+	// SecuritySystemSecretKey key of the system key in its secret
+	SecuritySystemSecretKey = "system-secret"
+	// SecurityAdminTokenSecretName name of the secret with the admin token
+	SecurityAdminTokenSecretName = "px-admin-token"
+	// SecurityAuthTokenKey key of the token in the token secrets
+	SecurityAuthTokenKey = "auth-token"
+	// DefaultSecurityIssuer is the default issuer of the self signed tokens
+	DefaultSecurityIssuer = "operator.portworx.io"
+	// DefaultTokenLifetime is the default lifetime of the admin token
+	DefaultTokenLifetime = 24 * time.Hour
 
 	pxAnnotationPrefix = "portworx.io"
 	labelKeyName       = "name"
@@ -191,6 +234,97 @@ func DisableCSIAlpha(cluster *corev1alpha1.StorageCluster) bool {
 	return false
 }
 
+// SecurityEnabled returns true if authorization is enabled in the cluster spec
+func SecurityEnabled(cluster *corev1alpha1.StorageCluster) bool {
+	return cluster.Spec.Security != nil && cluster.Spec.Security.Enabled
+}
+
+// SecurityIssuer returns the issuer of the self signed tokens from the
+// cluster spec if present, else returns the default issuer
+func SecurityIssuer(cluster *corev1alpha1.StorageCluster) string {
+	selfSigned := selfSignedSpec(cluster)
+	if selfSigned != nil && selfSigned.Issuer != nil && *selfSigned.Issuer != "" {
+		return *selfSigned.Issuer
+	}
+	return DefaultSecurityIssuer
+}
+
+// SharedSecretName returns the name of the secret with the shared secret
+// from the cluster spec if present, else returns the generated secret name
+func SharedSecretName(cluster *corev1alpha1.StorageCluster) string {
+	selfSigned := selfSignedSpec(cluster)
+	if selfSigned != nil && selfSigned.SharedSecret != nil && *selfSigned.SharedSecret != "" {
+		return *selfSigned.SharedSecret
+	}
+	return SecuritySharedSecretName
+}
+
+// TokenLifetime returns the lifetime of the admin token from the cluster spec
+// if present, else returns the default lifetime
+func TokenLifetime(cluster *corev1alpha1.StorageCluster) time.Duration {
+	selfSigned := selfSignedSpec(cluster)
+	if selfSigned != nil && selfSigned.TokenLifetime != nil && selfSigned.TokenLifetime.Duration > 0 {
+		return selfSigned.TokenLifetime.Duration
+	}
+	return DefaultTokenLifetime
+}
+
+// SecretRotationPeriod returns the rotation period of the generated shared and
+// system secrets from the cluster spec. Zero means the secrets are not rotated.
+func SecretRotationPeriod(cluster *corev1alpha1.StorageCluster) time.Duration {
+	selfSigned := selfSignedSpec(cluster)
+	if selfSigned != nil && selfSigned.SecretRotationPeriod != nil && selfSigned.SecretRotationPeriod.Duration > 0 {
+		return selfSigned.SecretRotationPeriod.Duration
+	}
+	return 0
+}
+
+// GenerateToken generates a token with the given claims, signed with the
+// given shared secret and valid for the given duration. The issuer of the
+// token is the self signed issuer of the cluster.
+func GenerateToken(
+	cluster *corev1alpha1.StorageCluster,
+	sharedSecret string,
+	claims *auth.Claims,
+	lifetime time.Duration,
+) (string, error) {
+	signature, err := auth.NewSignatureSharedSecret(sharedSecret)
+	if err != nil {
+		return "", err
+	}
+	claims.Issuer = SecurityIssuer(cluster)
+	return auth.Token(claims, signature, &auth.Options{
+		Expiration: time.Now().Add(lifetime).Unix(),
+		// Guard against clock drift between the operator and the storage nodes
+		IATSubtract: time.Minute,
+	})
+}
+
+// GetSharedSecret returns the shared secret used to sign the self signed tokens
+func GetSharedSecret(
+	k8sClient client.Client,
+	cluster *corev1alpha1.StorageCluster,
+) (string, error) {
+	secretName := SharedSecretName(cluster)
+	secret := &v1.Secret{}
+	err := k8sClient.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      secretName,
+			Namespace: cluster.Namespace,
+		},
+		secret,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to get shared secret %s: %v", secretName, err)
+	}
+	sharedSecret := secret.Data[SecuritySharedSecretKey]
+	if len(sharedSecret) == 0 {
+		return "", fmt.Errorf("secret %s does not have the %s key", secretName, SecuritySharedSecretKey)
+	}
+	return string(sharedSecret), nil
+}
+
 // GetPortworxVersion returns the Portworx version based on the image provided.
 // We first look at spec.Image, if not valid image tag found, we check the PX_IMAGE
 // env variable. If that is not present or invalid semvar, then we fallback to an
@@ -242,6 +376,13 @@ func StorageClusterKind() schema.GroupVersionKind {
 	return corev1alpha1.SchemeGroupVersion.WithKind("StorageCluster")
 }
 
+func selfSignedSpec(cluster *corev1alpha1.StorageCluster) *corev1alpha1.SelfSignedSpec {
+	if cluster.Spec.Security == nil || cluster.Spec.Security.Auth == nil {
+		return nil
+	}
+	return cluster.Spec.Security.Auth.SelfSigned
+}
+
 func getSpecsBaseDir() string {
 	return PortworxSpecsDir
 }
@@ -53,6 +53,11 @@ type ClusterPluginInterface interface {
 	PreInstall(*corev1alpha1.StorageCluster) error
 	// GetStoragePodSpec given the storage cluster spec and node name it returns the pod spec for a specific node
 	GetStoragePodSpec(*corev1alpha1.StorageCluster, string) (v1.PodSpec, error)
+	// GetPreflightPodSpec given the storage cluster spec and node name it returns the spec
+	// of the pod that runs the preflight checks on the node. The pod should fail if the
+	// node does not meet the requirements of the driver, and report the failed checks
+	// in the termination message of its container.
+	GetPreflightPodSpec(*corev1alpha1.StorageCluster, string) (v1.PodSpec, error)
 	// GetSelectorLabels returns driver specific labels that are applied on the pods
 	GetSelectorLabels() map[string]string
 	// SetDefaultsOnStorageCluster sets the driver specific defaults on the storage
@@ -60,11 +65,26 @@ type ClusterPluginInterface interface {
 	SetDefaultsOnStorageCluster(*corev1alpha1.StorageCluster)
 	// UpdateStorageClusterStatus update the status of storage cluster
 	UpdateStorageClusterStatus(*corev1alpha1.StorageCluster) error
+	// PreNodeUpgrade is called during a rolling update before the storage pod on
+	// the given node is taken down. The driver should return an error if the pod
+	// cannot be taken down yet without affecting the health of the storage cluster,
+	// for instance if the cluster is degraded or data is being resynced. The error
+	// is reported as the reason the upgrade is blocked.
+	PreNodeUpgrade(*corev1alpha1.StorageCluster, string) error
+	// ValidateRollback checks if the storage cluster can be rolled back from the
+	// current spec to the spec of a previous revision. The first argument is the
+	// current cluster and the second one is the cluster after the rollback.
+	ValidateRollback(*corev1alpha1.StorageCluster, *corev1alpha1.StorageCluster) error
+	// GetPodsUsingStorage returns the pods on the given node that use volumes
+	// provided by the storage driver. These pods are evicted before the storage
+	// pod on the node is restarted, if the nodes are drained during an update.
+	GetPodsUsingStorage(*corev1alpha1.StorageCluster, string) ([]v1.Pod, error)
 	// DeleteStorage is going to uninstall and delete the storage service based on
 	// StorageClusterDeleteStrategy. DeleteStorage should provide idempotent behavior
 	// and subsequent calls should result in the same result.
 	// If the storage service has already been deleted then it will return nil
 	// If the storage service deletion is in progress then it will return the appropriate status
+	// in a condition of type Delete, which is translated to the Deleting condition of the cluster
 	DeleteStorage(*corev1alpha1.StorageCluster) (*corev1alpha1.ClusterCondition, error)
 }
 
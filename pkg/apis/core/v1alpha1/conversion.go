@@ -0,0 +1,11 @@
+package v1alpha1
+
+// Hub marks v1alpha1 StorageCluster as the conversion hub. All other versions
+// of StorageCluster are converted to and from this version, which is also the
+// version persisted in etcd.
+func (*StorageCluster) Hub() {}
+
+// Hub marks v1alpha1 StorageNode as the conversion hub. All other versions
+// of StorageNode are converted to and from this version, which is also the
+// version persisted in etcd.
+func (*StorageNode) Hub() {}
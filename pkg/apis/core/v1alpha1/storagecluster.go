@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -46,8 +47,14 @@ type StorageClusterSpec struct {
 	// This is a pointer to distinguish between explicit zero and not specified.
 	// Defaults to 10.
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+	// RollbackTo is the revision of the StorageCluster to roll back to. The spec
+	// is restored from the stored revision and the storage pods are updated as
+	// per the current update strategy. It is cleared once the rollback is done.
+	RollbackTo *RollbackConfig `json:"rollbackTo,omitempty"`
 	// Placement configuration for the storage cluster nodes
 	Placement *PlacementSpec `json:"placement,omitempty"`
+	// PriorityClassName is the name of the priority class of the storage pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
 	// Image is docker image of the storage driver
 	Image string `json:"image,omitempty"`
 	// Version is the version of storage driver
@@ -64,14 +71,16 @@ type StorageClusterSpec struct {
 	CustomImageRegistry string `json:"customImageRegistry,omitempty"`
 	// Kvdb is the information of kvdb that storage driver uses
 	Kvdb *KvdbSpec `json:"kvdb,omitempty"`
-	// CloudStorage details of storage in cloud environment.
-	// Keep this only at cluster level for now until support to change
-	// cloud storage at node level is added.
+	// CloudStorage details of storage in cloud environment. This can be
+	// overriden for a group of nodes using the CloudStorage in NodeSpec.
 	CloudStorage *CloudStorageSpec `json:"cloudStorage,omitempty"`
 	// SecretsProvider is the name of secret provider that driver will connect to
 	SecretsProvider *string `json:"secretsProvider,omitempty"`
 	// StartPort is the starting port in the range of ports used by the cluster
 	StartPort *uint32 `json:"startPort,omitempty"`
+	// Preflight contains the configuration of the checks run on a node before
+	// the first storage pod is created on it
+	Preflight *PreflightSpec `json:"preflight,omitempty"`
 	// FeatureGates are a set of key-value pairs that describe what experimental
 	// features need to be enabled
 	FeatureGates map[string]string `json:"featureGates,omitempty"`
@@ -90,11 +99,29 @@ type StorageClusterSpec struct {
 	Autopilot *AutopilotSpec `json:"autopilot,omitempty"`
 	// Monitoring contains monitoring configuration for the storage cluster.
 	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+	// CSI contains the configuration of the CSI sidecars deployed with the
+	// storage driver. CSI itself is enabled using the CSI feature gate.
+	CSI *CSISpec `json:"csi,omitempty"`
+	// PVCController contains the configuration of the PVC controller deployed
+	// with the storage driver. The PVC controller itself is enabled using the
+	// portworx.io/pvc-controller annotation.
+	PVCController *PVCControllerSpec `json:"pvcController,omitempty"`
+	// Security contains the authorization and TLS configuration of the storage cluster
+	Security *SecuritySpec `json:"security,omitempty"`
 	// Nodes node level configurations that will override the ones at cluster
 	// level. These configurations can be grouped based on label selectors.
 	Nodes []NodeSpec `json:"nodes,omitempty"`
 }
 
+// PreflightSpec contains the configuration of the preflight checks. The checks
+// verify that a node meets the requirements of the storage driver, before the
+// first storage pod is created on it. Nodes that fail the checks do not get a
+// storage pod, unless the checks are skipped on the StorageNode of the node.
+type PreflightSpec struct {
+	// Enabled decides whether the preflight checks are run on the nodes
+	Enabled bool `json:"enabled,omitempty"`
+}
+
 // NodeSpec is the spec used to define node level configuration. Values
 // here will override the ones present at cluster-level for nodes matching
 // the selector.
@@ -102,6 +129,15 @@ type NodeSpec struct {
 	// Selector rest of the attributes are applied to a node that matches
 	// the selector
 	Selector NodeSelector `json:"selector,omitempty"`
+	// Image is docker image of the storage driver for the group of nodes.
+	// This will override the cluster-level image.
+	Image string `json:"image,omitempty"`
+	// Tolerations for the storage pods on the group of nodes to tolerate node
+	// taints. These are added to the cluster-level placement tolerations.
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// CloudStorage details of storage in cloud environment for the group of
+	// nodes. This will override the cluster-level cloud storage configuration.
+	CloudStorage *CloudStorageNodeSpec `json:"cloudStorage,omitempty"`
 	// CommonConfig contains storage, network and other configuration specific
 	// to the group of nodes. This will override the cluster-level configuration.
 	CommonConfig
@@ -118,6 +154,28 @@ type CommonConfig struct {
 	Env []v1.EnvVar `json:"env,omitempty"`
 	// RuntimeOpts is a map of options with extra configs for storage driver
 	RuntimeOpts map[string]string `json:"runtimeOptions,omitempty"`
+	// Resources are the compute resource requirements of the storage driver
+	// container
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// ComponentConfig contains the compute resources and scheduling configuration
+// of the pods of a component deployed by the operator
+type ComponentConfig struct {
+	// Resources are the compute resource requirements of the main container of
+	// the component. Other containers in the component pods, like sidecars, keep
+	// their default resources. If not set, the default resources are used.
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// PriorityClassName is the name of the priority class of the component pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// NodeSelector is a selector which must match the labels of a node for the
+	// component pods to be scheduled on that node
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations for the component pods to tolerate node taints. If set, these
+	// are used instead of the tolerations in the cluster placement.
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// SecurityContext holds pod-level security attributes of the component pods
+	SecurityContext *v1.PodSecurityContext `json:"securityContext,omitempty"`
 }
 
 // NodeSelector let's the user select a node or group of nodes based on either
@@ -175,6 +233,56 @@ type RollingUpdateStorageCluster struct {
 	// that at least 70% of original number of StorageCluster pods are available at
 	// all times during the update.
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// Partition restricts the update to the storage pods running on a subset of
+	// the nodes. Storage pods on the remaining nodes keep running the old revision.
+	Partition *RollingUpdatePartition `json:"partition,omitempty"`
+	// Paused freezes the rollout. No more storage pods are updated until the
+	// rollout is resumed.
+	Paused bool `json:"paused,omitempty"`
+	// Canary updates only a few storage pods to the new revision and waits for the
+	// revision to be promoted before updating the remaining storage pods.
+	Canary *CanaryUpdate `json:"canary,omitempty"`
+	// FailureDomain makes the rolling update topology aware. The storage pods are
+	// updated one failure domain (zone or rack) at a time, finishing a domain
+	// before moving to the next one. MaxUnavailable applies within the domain
+	// being updated. If not set, the topology of the nodes is ignored.
+	FailureDomain FailureDomainType `json:"failureDomain,omitempty"`
+	// DrainNodes cordons the node and evicts the application pods using storage
+	// volumes on it, before restarting the storage pod on that node. Evictions
+	// respect the PodDisruptionBudgets of the applications. The node is uncordoned
+	// once the storage node is back online.
+	DrainNodes bool `json:"drainNodes,omitempty"`
+}
+
+// FailureDomainType is the enum for the failure domains used during a rolling update
+type FailureDomainType string
+
+const (
+	// FailureDomainZone updates the storage pods one zone at a time
+	FailureDomainZone FailureDomainType = "Zone"
+	// FailureDomainRack updates the storage pods one rack at a time
+	FailureDomainRack FailureDomainType = "Rack"
+)
+
+// RollingUpdatePartition selects the nodes whose storage pods are updated during
+// a rolling update. If both ordinal and selector are given, only the nodes matching
+// both of them are updated.
+type RollingUpdatePartition struct {
+	// Ordinal is the index of the first node to be updated, when the nodes running
+	// storage pods are sorted by name. Similar to the partition of a StatefulSet,
+	// storage pods on nodes with an index lower than the ordinal are not updated.
+	Ordinal *int32 `json:"ordinal,omitempty"`
+	// Selector is a label query over the nodes whose storage pods are updated
+	Selector *meta.LabelSelector `json:"selector,omitempty"`
+}
+
+// CanaryUpdate controls the canary rollout of a new revision
+type CanaryUpdate struct {
+	// Nodes is the number of nodes that are updated to the new revision before
+	// the rollout waits for promotion. The revision is promoted by setting the
+	// operator.libopenstorage.org/promote-canary annotation on the StorageCluster
+	// to the update revision in the rollout status.
+	Nodes int32 `json:"nodes"`
 }
 
 // StorageClusterDeleteStrategyType is enum for storage cluster delete strategies
@@ -197,6 +305,13 @@ type StorageClusterDeleteStrategy struct {
 	Type StorageClusterDeleteStrategyType `json:"type,omitempty"`
 }
 
+// RollbackConfig is the revision of the StorageCluster to roll back to
+type RollbackConfig struct {
+	// Revision to roll back to. If set to 0, rolls back to the last revision
+	// before the current one.
+	Revision int64 `json:"revision,omitempty"`
+}
+
 // KvdbSpec contains the details to access kvdb
 type KvdbSpec struct {
 	// Internal flag indicates whether to use internal kvdb or an external one
@@ -245,8 +360,14 @@ type CloudStorageCapacitySpec struct {
 	MinIOPS uint32 `json:"minIOPS,omitempty"`
 	// MinCapacityInGiB minimum capacity for this cloud device spec
 	MinCapacityInGiB uint64 `json:"minCapacityInGiB,omitempty"`
-	// MaxCapacityInGiB capacity for this cloud device spec should not go above this threshold
+	// MaxCapacityInGiB capacity for this cloud device spec should not go above this threshold.
+	// It also limits the size of each storage pool created from this spec, as the
+	// pools are not expanded beyond it on any node.
 	MaxCapacityInGiB uint64 `json:"maxCapacityInGiB,omitempty"`
+	// PoolSizeInGiB requested size of the storage pool created from this spec on
+	// every storage node. The pool at the same index as the spec is expanded to
+	// this size, up to MaxCapacityInGiB. Storage pools are never shrunk.
+	PoolSizeInGiB uint64 `json:"poolSizeInGiB,omitempty"`
 	// Options additional options required to provision the drive in cloud
 	Options map[string]string `json:"options,omitempty"`
 }
@@ -254,9 +375,9 @@ type CloudStorageCapacitySpec struct {
 // CloudStorageSpec details of storage in cloud environment
 type CloudStorageSpec struct {
 	// DeviceSpecs list of storage device specs. A cloud storage device will
-	// be created for every spec in the DeviceSpecs list. Currently,
-	// CloudStorageSpec is only at the cluster level, so the below specs
-	// be applied to all storage nodes in the cluster.
+	// be created for every spec in the DeviceSpecs list. The below specs
+	// will be applied to all storage nodes in the cluster, unless overriden
+	// by the CloudStorage in NodeSpec.
 	// (Deprecated) DeviceSpecs will be removed from StorageCluster in v1alpha2.
 	// Use CapacitySpecs instead
 	DeviceSpecs *[]string `json:"deviceSpecs,omitempty"`
@@ -282,6 +403,22 @@ type CloudStorageSpec struct {
 	MaxStorageNodesPerZone *uint32 `json:"maxStorageNodesPerZone,omitempty"`
 }
 
+// CloudStorageNodeSpec details of storage in cloud environment for a group
+// of nodes. Values here will override the ones in the cluster-level
+// CloudStorageSpec for the nodes in the group.
+type CloudStorageNodeSpec struct {
+	// CapacitySpecs list of storage types and their capacities for the
+	// group of nodes. It replaces the cluster-level capacity specs and
+	// device specs.
+	CapacitySpecs []CloudStorageCapacitySpec `json:"capacitySpecs,omitempty"`
+	// JournalDeviceSpec spec for the journal device
+	JournalDeviceSpec *string `json:"journalDeviceSpec,omitempty"`
+	// SystemMdDeviceSpec spec for the metadata device
+	SystemMdDeviceSpec *string `json:"systemMetadataDeviceSpec,omitempty"`
+	// KvdbDeviceSpec spec for the internal kvdb device
+	KvdbDeviceSpec *string `json:"kvdbDeviceSpec,omitempty"`
+}
+
 // Geography is topology information for a node
 type Geography struct {
 	// Region region in which the node is placed
@@ -304,6 +441,9 @@ type UserInterfaceSpec struct {
 	LockImage bool `json:"lockImage,omitempty"`
 	// Env is a list of environment variables used by UI component
 	Env []v1.EnvVar `json:"env,omitempty"`
+	// ComponentConfig contains the resources and scheduling configuration
+	// of the user interface pods
+	ComponentConfig
 }
 
 // StorkSpec contains STORK related spec
@@ -320,6 +460,9 @@ type StorkSpec struct {
 	Args map[string]string `json:"args,omitempty"`
 	// Env is a list of environment variables used by stork
 	Env []v1.EnvVar `json:"env,omitempty"`
+	// ComponentConfig contains the resources and scheduling configuration
+	// of the STORK and STORK scheduler pods
+	ComponentConfig
 }
 
 // AutopilotSpec contains details of an autopilot component
@@ -338,6 +481,9 @@ type AutopilotSpec struct {
 	Args map[string]string `json:"args,omitempty"`
 	// Env is a list of environment variables used by autopilot
 	Env []v1.EnvVar `json:"env,omitempty"`
+	// ComponentConfig contains the resources and scheduling configuration
+	// of the autopilot pods
+	ComponentConfig
 }
 
 // DataProviderSpec contains the details for data providers for components like autopilot
@@ -368,6 +514,94 @@ type PrometheusSpec struct {
 	Enabled bool `json:"enabled,omitempty"`
 	// RemoteWriteEndpoint specifies the remote write endpoint
 	RemoteWriteEndpoint string `json:"remoteWriteEndpoint,omitempty"`
+	// ComponentConfig contains the resources and scheduling configuration
+	// of the Prometheus instance
+	ComponentConfig
+}
+
+// CSISpec contains the configuration of the CSI sidecars
+type CSISpec struct {
+	// ComponentConfig contains the resources and scheduling configuration of
+	// the CSI sidecars. The resources are used for the CSI provisioner, and
+	// for the CSI registrar running in the storage pods.
+	ComponentConfig
+}
+
+// PVCControllerSpec contains the configuration of the PVC controller
+type PVCControllerSpec struct {
+	// ComponentConfig contains the resources and scheduling configuration
+	// of the PVC controller pods
+	ComponentConfig
+}
+
+// SecuritySpec contains the authorization and TLS configuration of the storage
+// cluster. When enabled, the storage driver only accepts requests with a valid
+// token. TLS can be enabled independently of authorization.
+type SecuritySpec struct {
+	// Enabled decides whether authorization is enabled in the storage cluster
+	Enabled bool `json:"enabled,omitempty"`
+	// Auth contains the configuration of the issuers of the tokens
+	Auth *AuthSpec `json:"auth,omitempty"`
+	// TLS contains the TLS configuration of the SDK and REST endpoints
+	TLS *TLSSpec `json:"tls,omitempty"`
+}
+
+// TLSSpec contains the TLS configuration of the SDK and REST endpoints of the
+// storage cluster. If no secret is given, the operator generates a self signed
+// CA and issues a certificate for each storage node, which is renewed before
+// it expires.
+type TLSSpec struct {
+	// Enabled decides whether TLS is enabled on the storage endpoints
+	Enabled bool `json:"enabled,omitempty"`
+	// ServerCertSecret is the name of a kubernetes secret, in the namespace of
+	// the storage cluster, with the tls.crt, tls.key and ca.crt keys used by
+	// all the storage nodes. The certificate should be valid for the
+	// portworx-service.<namespace>.svc host. It takes precedence over CASecret.
+	ServerCertSecret *string `json:"serverCertSecret,omitempty"`
+	// CASecret is the name of a kubernetes secret, in the namespace of the
+	// storage cluster, with the tls.crt and tls.key of the CA used to issue
+	// the certificates of the storage nodes. The operator does not renew it.
+	CASecret *string `json:"caSecret,omitempty"`
+}
+
+// AuthSpec contains the configuration of the issuers of the tokens accepted
+// by the storage cluster
+type AuthSpec struct {
+	// SelfSigned contains the configuration of the tokens signed with a shared
+	// secret. These are used by the operator and the admin of the cluster.
+	SelfSigned *SelfSignedSpec `json:"selfSigned,omitempty"`
+	// OIDC contains the configuration of an OpenID Connect provider whose
+	// tokens are accepted in addition to the self signed tokens
+	OIDC *OIDCSpec `json:"oidc,omitempty"`
+}
+
+// SelfSignedSpec contains the configuration of the self signed tokens
+type SelfSignedSpec struct {
+	// Issuer is the issuer of the self signed tokens.
+	// Defaults to operator.portworx.io.
+	Issuer *string `json:"issuer,omitempty"`
+	// SharedSecret is the name of the kubernetes secret, in the namespace of
+	// the storage cluster, with the secret used to sign the tokens under the
+	// shared-secret key. A secret is generated if not specified.
+	SharedSecret *string `json:"sharedSecret,omitempty"`
+	// TokenLifetime is the lifetime of the generated admin token. The token
+	// is regenerated once half of its lifetime has passed. Defaults to 24h.
+	TokenLifetime *meta.Duration `json:"tokenLifetime,omitempty"`
+	// SecretRotationPeriod is how often the generated shared and system secrets
+	// are regenerated. The storage pods are restarted to use the new secrets.
+	// The secrets are not rotated if not specified.
+	SecretRotationPeriod *meta.Duration `json:"secretRotationPeriod,omitempty"`
+}
+
+// OIDCSpec contains the configuration of an OpenID Connect provider
+type OIDCSpec struct {
+	// Issuer is the URL of the OpenID Connect provider
+	Issuer string `json:"issuer,omitempty"`
+	// ClientID is the client ID of the storage cluster in the provider
+	ClientID string `json:"clientId,omitempty"`
+	// CustomNamespace is the namespace of the custom claims, like roles and
+	// groups, in the tokens of the provider
+	CustomNamespace string `json:"customNamespace,omitempty"`
 }
 
 // StorageClusterStatus is the status of a storage cluster
@@ -386,28 +620,234 @@ type StorageClusterStatus struct {
 	Conditions []ClusterCondition `json:"conditions,omitempty"`
 	// Storage represents cluster storage details
 	Storage Storage `json:"storage,omitempty"`
+	// Rollout is the progress of rolling out the latest revision to the storage pods
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// ObservedGeneration is the most recent generation of the StorageCluster
+	// observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// DesiredNumberScheduled is the number of nodes that should be running the
+	// storage pod
+	DesiredNumberScheduled int32 `json:"desiredNumberScheduled"`
+	// CurrentNumberScheduled is the number of nodes that are running the storage
+	// pod and are supposed to run the storage pod
+	CurrentNumberScheduled int32 `json:"currentNumberScheduled"`
+	// NumberReady is the number of nodes that should be running the storage pod
+	// and have the storage pod running and ready
+	NumberReady int32 `json:"numberReady"`
+	// UpdatedNumberScheduled is the number of nodes that are running the storage
+	// pod of the update revision
+	UpdatedNumberScheduled int32 `json:"updatedNumberScheduled,omitempty"`
+	// NumberAvailable is the number of nodes that should be running the storage
+	// pod and have the storage pod running and available
+	NumberAvailable int32 `json:"numberAvailable,omitempty"`
+	// CurrentRevision is the hash of the StorageCluster revision that the storage
+	// pods ran before the update revision was rolled out to them
+	CurrentRevision string `json:"currentRevision,omitempty"`
+	// UpdateRevision is the hash of the latest StorageCluster revision
+	UpdateRevision string `json:"updateRevision,omitempty"`
+	// Version is the version of the storage driver running in the cluster. If
+	// the nodes run different versions, it is the oldest of them.
+	Version string `json:"version,omitempty"`
+	// ComponentConditions describes the reconciliation status of each component
+	// of the cluster
+	ComponentConditions []ComponentCondition `json:"componentConditions,omitempty"`
+	// Alerts is the list of the most recent active alerts raised by the storage
+	// driver, latest first
+	Alerts []StorageAlert `json:"alerts,omitempty"`
+	// Kvdb is the health of the kvdb used by the storage cluster
+	Kvdb *KvdbStatus `json:"kvdb,omitempty"`
+}
+
+// ComponentCondition contains the reconciliation status of a component of the
+// cluster
+type ComponentCondition struct {
+	// Name of the component
+	Name string `json:"name"`
+	// Status of the component
+	Status ComponentConditionStatus `json:"status"`
+	// Reason is human readable message indicating details about the current
+	// state of the component, like the error if the reconciliation failed
+	Reason string `json:"reason,omitempty"`
 }
 
+// ComponentConditionStatus is the enum type for component condition statuses
+type ComponentConditionStatus string
+
+// These are valid component condition statuses
+const (
+	// ComponentReady means the component has been reconciled successfully
+	ComponentReady ComponentConditionStatus = "Ready"
+	// ComponentFailed means the reconciliation of the component failed
+	ComponentFailed ComponentConditionStatus = "Failed"
+	// ComponentDisabled means the component is not enabled and has been removed
+	ComponentDisabled ComponentConditionStatus = "Disabled"
+)
+
+// RolloutStatus describes the progress of rolling out a StorageCluster revision
+type RolloutStatus struct {
+	// UpdateRevision is the hash of the StorageCluster revision being rolled out
+	UpdateRevision string `json:"updateRevision,omitempty"`
+	// State is the current state of the rollout
+	State RolloutState `json:"state,omitempty"`
+	// TotalPods is the number of storage pods in the cluster
+	TotalPods int32 `json:"totalPods"`
+	// UpdatedPods is the number of storage pods running the update revision
+	UpdatedPods int32 `json:"updatedPods"`
+}
+
+// RolloutState is the enum type for the states of a rollout
+type RolloutState string
+
+// These are valid rollout states
+const (
+	// RolloutStateComplete means all storage pods run the update revision
+	RolloutStateComplete RolloutState = "Complete"
+	// RolloutStateProgressing means storage pods are being updated
+	RolloutStateProgressing RolloutState = "Progressing"
+	// RolloutStatePaused means the rollout has been paused
+	RolloutStatePaused RolloutState = "Paused"
+	// RolloutStatePartitioned means all storage pods in the update partition
+	// run the update revision and the remaining pods are left on older revisions
+	RolloutStatePartitioned RolloutState = "Partitioned"
+	// RolloutStateAwaitingPromotion means the canary pods run the update revision
+	// and the rollout is waiting for the revision to be promoted
+	RolloutStateAwaitingPromotion RolloutState = "AwaitingPromotion"
+)
+
 // Storage represents cluster storage details
 type Storage struct {
 	// StorageNodesPerZone describes the amount of instances per zone
 	StorageNodesPerZone int32 `json:"storageNodesPerZone,omitempty"`
+	// TotalSize is the total raw capacity of all the storage nodes
+	TotalSize *resource.Quantity `json:"totalSize,omitempty"`
+	// UsedSize is the raw capacity used across all the storage nodes
+	UsedSize *resource.Quantity `json:"usedSize,omitempty"`
+	// StorageNodes is the number of nodes in the cluster that have storage
+	StorageNodes int32 `json:"storageNodes,omitempty"`
+	// StoragelessNodes is the number of nodes in the cluster without storage
+	StoragelessNodes int32 `json:"storagelessNodes,omitempty"`
+	// NodesPerZone is the number of nodes in the cluster in each zone
+	NodesPerZone map[string]int32 `json:"nodesPerZone,omitempty"`
+	// Versions is the list of distinct versions running on the nodes
+	Versions []string `json:"versions,omitempty"`
+}
+
+// StorageAlert is an alert raised by the storage driver
+type StorageAlert struct {
+	// Severity of the alert
+	Severity string `json:"severity,omitempty"`
+	// ResourceType is the type of the resource the alert is about
+	ResourceType string `json:"resourceType,omitempty"`
+	// ResourceID is the ID of the resource in the storage driver
+	ResourceID string `json:"resourceId,omitempty"`
+	// Message describing the alert
+	Message string `json:"message,omitempty"`
+	// Count is the number of times the alert has been raised
+	Count int64 `json:"count,omitempty"`
+	// LastSeen is the last time the alert was raised
+	LastSeen meta.Time `json:"lastSeen,omitempty"`
 }
 
-// ClusterCondition contains condition information for the cluster
+// KvdbStatus describes the health of the kvdb used by the storage cluster
+type KvdbStatus struct {
+	// Endpoints is the health of each external kvdb endpoint when it was
+	// last checked
+	Endpoints []KvdbEndpointStatus `json:"endpoints,omitempty"`
+	// LastCheckTime is the last time the external kvdb endpoints were checked
+	LastCheckTime *meta.Time `json:"lastCheckTime,omitempty"`
+	// Members is the list of the StorageNodes running the internal kvdb
+	Members []string `json:"members,omitempty"`
+}
+
+// KvdbEndpointStatus describes the health of an external kvdb endpoint
+type KvdbEndpointStatus struct {
+	// Endpoint is the kvdb endpoint as given in the cluster spec
+	Endpoint string `json:"endpoint"`
+	// Healthy is true if the endpoint could be read from
+	Healthy bool `json:"healthy"`
+	// Leader is true if the endpoint is the leader of the kvdb cluster
+	Leader bool `json:"leader,omitempty"`
+	// Latency is how long a read from the endpoint took
+	Latency *meta.Duration `json:"latency,omitempty"`
+	// Message is the error if the endpoint is not healthy
+	Message string `json:"message,omitempty"`
+}
+
+// ClusterCondition contains condition information for the cluster. It follows
+// the shape of the standard Kubernetes conditions, so tools like
+// `kubectl wait --for=condition=Available` work with the StorageCluster.
 type ClusterCondition struct {
 	// Type is the type of condition
 	Type ClusterConditionType `json:"type"`
-	// Status of the condition
-	Status ClusterConditionStatus `json:"status"`
-	// Reason is human readable message indicating details about the current state of the cluster
-	Reason string `json:"reason"`
+	// Status of the condition, one of True, False or Unknown
+	Status ConditionStatus `json:"status"`
+	// ObservedGeneration is the generation of the cluster spec when the
+	// condition was last set
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastTransitionTime is the last time the condition changed its status
+	LastTransitionTime meta.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a one-word CamelCase reason for the current status of the condition
+	Reason string `json:"reason,omitempty"`
+	// Message is human readable message indicating details about the current
+	// status of the condition
+	Message string `json:"message,omitempty"`
 }
 
 // ClusterConditionType is the enum type for different cluster conditions
 type ClusterConditionType string
 
 // These are valid cluster condition types
+const (
+	// ClusterConditionTypeAvailable indicates whether the storage cluster is
+	// online and available for use
+	ClusterConditionTypeAvailable ClusterConditionType = "Available"
+	// ClusterConditionTypeProgressing indicates whether the storage pods are
+	// being created or updated to reach the desired state
+	ClusterConditionTypeProgressing ClusterConditionType = "Progressing"
+	// ClusterConditionTypeDegraded indicates whether the cluster or any of its
+	// components failed to reach the desired state
+	ClusterConditionTypeDegraded ClusterConditionType = "Degraded"
+	// ClusterConditionTypeUpgrading indicates whether the storage pods are being
+	// updated to a new revision of the cluster spec
+	ClusterConditionTypeUpgrading ClusterConditionType = "Upgrading"
+	// ClusterConditionTypeDeleting indicates whether the storage cluster is
+	// being deleted
+	ClusterConditionTypeDeleting ClusterConditionType = "Deleting"
+	// ClusterConditionTypeNodesClaimed indicates whether nodes selected by the
+	// placement of the cluster are claimed by other storage clusters
+	ClusterConditionTypeNodesClaimed ClusterConditionType = "NodesClaimed"
+)
+
+// ConditionStatus is the enum type for the status of a condition
+type ConditionStatus string
+
+// These are valid condition statuses
+const (
+	// ConditionTrue means the cluster is in the condition
+	ConditionTrue ConditionStatus = "True"
+	// ConditionFalse means the cluster is not in the condition
+	ConditionFalse ConditionStatus = "False"
+	// ConditionUnknown means it cannot be decided if the cluster is in the condition
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// These are the reasons of the Deleting condition. They are also used as
+// the phase of the cluster while it is being deleted.
+const (
+	// ClusterDeleteInProgressReason means the storage is being removed
+	ClusterDeleteInProgressReason = "DeleteInProgress"
+	// ClusterDeleteCompletedReason means the storage has been removed
+	ClusterDeleteCompletedReason = "DeleteCompleted"
+	// ClusterDeleteFailedReason means the storage could not be removed
+	ClusterDeleteFailedReason = "DeleteFailed"
+	// ClusterDeleteTimeoutReason means the storage was not removed in time
+	ClusterDeleteTimeoutReason = "DeleteTimeout"
+)
+
+// These are the cluster condition types used by older versions of the operator.
+// Storage drivers still report the progress of a delete operation with the
+// Delete type, which is translated to the Deleting condition. Conditions of the
+// other types are removed from the status of existing clusters.
 const (
 	// ClusterConditionTypeUpgrade indicates the status for an upgrade operation on the cluster
 	ClusterConditionTypeUpgrade ClusterConditionType = "Upgrade"
@@ -415,9 +855,25 @@ const (
 	ClusterConditionTypeDelete ClusterConditionType = "Delete"
 	// ClusterConditionTypeInstall indicates the status for an install operation on the cluster
 	ClusterConditionTypeInstall ClusterConditionType = "Install"
+	// ClusterConditionTypeRollback indicates the status for a rollback operation on the cluster
+	ClusterConditionTypeRollback ClusterConditionType = "Rollback"
+)
+
+// These are the statuses of the condition types used by older versions of
+// the operator
+const (
+	// ClusterOperationInProgress means the cluster operation is in progress
+	ClusterOperationInProgress ConditionStatus = "InProgress"
+	// ClusterOperationCompleted means the cluster operation has completed
+	ClusterOperationCompleted ConditionStatus = "Completed"
+	// ClusterOperationFailed means the cluster operation failed
+	ClusterOperationFailed ConditionStatus = "Failed"
+	// ClusterOperationTimeout means the cluster operation timedout
+	ClusterOperationTimeout ConditionStatus = "Timeout"
 )
 
-// ClusterConditionStatus is the enum type for cluster condition statuses
+// ClusterConditionStatus is the enum type for the statuses of the storage
+// cluster, that are reported as the phase of the cluster
 type ClusterConditionStatus string
 
 // These are valid cluster statuses.
@@ -432,14 +888,6 @@ const (
 	ClusterNotInQuorum ClusterConditionStatus = "NotInQuorum"
 	// ClusterUnknown means the cluser status is not known
 	ClusterUnknown ClusterConditionStatus = "Unknown"
-	// ClusterOperationInProgress means the cluster operation is in progress
-	ClusterOperationInProgress ClusterConditionStatus = "InProgress"
-	// ClusterOperationCompleted means the cluster operation has completed
-	ClusterOperationCompleted ClusterConditionStatus = "Completed"
-	// ClusterOperationFailed means the cluster operation failed
-	ClusterOperationFailed ClusterConditionStatus = "Failed"
-	// ClusterOperationTimeout means the cluster operation timedout
-	ClusterOperationTimeout ClusterConditionStatus = "Timeout"
 )
 
 func init() {
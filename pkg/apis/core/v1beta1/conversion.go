@@ -0,0 +1,133 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Annotations used in v1alpha1 for the settings that are typed fields in v1beta1.
+// They are used to carry those fields when converting to and from v1alpha1.
+const (
+	// AnnotationDisableStorage is the v1alpha1 annotation for Spec.DisableStorage
+	AnnotationDisableStorage = "operator.libopenstorage.org/disable-storage"
+	// AnnotationServiceType is the v1alpha1 annotation for Spec.ServiceType
+	AnnotationServiceType = "portworx.io/service-type"
+	// AnnotationMiscArgs is the v1alpha1 annotation for Spec.MiscArgs
+	AnnotationMiscArgs = "portworx.io/misc-args"
+	// AnnotationLogFile is the v1alpha1 annotation for Spec.LogFile
+	AnnotationLogFile = "portworx.io/log-file"
+)
+
+// ConvertTo converts this StorageCluster to the v1alpha1 hub version.
+// Fields that are not present in v1alpha1 are stored as annotations.
+func (src *StorageCluster) ConvertTo(dst *v1alpha1.StorageCluster) error {
+	dst.TypeMeta = src.TypeMeta
+	dst.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	if src.Spec.DisableStorage {
+		setAnnotation(&dst.ObjectMeta.Annotations, AnnotationDisableStorage, strconv.FormatBool(true))
+	}
+	if len(src.Spec.ServiceType) > 0 {
+		setAnnotation(&dst.ObjectMeta.Annotations, AnnotationServiceType, string(src.Spec.ServiceType))
+	}
+	if len(src.Spec.MiscArgs) > 0 {
+		setAnnotation(&dst.ObjectMeta.Annotations, AnnotationMiscArgs, src.Spec.MiscArgs)
+	}
+	if len(src.Spec.LogFile) > 0 {
+		setAnnotation(&dst.ObjectMeta.Annotations, AnnotationLogFile, src.Spec.LogFile)
+	}
+	return nil
+}
+
+// ConvertFrom converts the given v1alpha1 hub version to this StorageCluster.
+// Annotations that have a corresponding typed field in v1beta1 are moved to
+// that field. An annotation is only moved if converting back to v1alpha1 will
+// restore it exactly, so that no information is lost in a round trip.
+func (dst *StorageCluster) ConvertFrom(src *v1alpha1.StorageCluster) error {
+	dst.TypeMeta = src.TypeMeta
+	dst.APIVersion = SchemeGroupVersion.String()
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	annotations := dst.ObjectMeta.Annotations
+	if val, exists := annotations[AnnotationDisableStorage]; exists &&
+		val == strconv.FormatBool(true) {
+		dst.Spec.DisableStorage = true
+		delete(annotations, AnnotationDisableStorage)
+	}
+	if val := annotations[AnnotationServiceType]; len(val) > 0 {
+		dst.Spec.ServiceType = v1.ServiceType(val)
+		delete(annotations, AnnotationServiceType)
+	}
+	if val := annotations[AnnotationMiscArgs]; len(val) > 0 {
+		dst.Spec.MiscArgs = val
+		delete(annotations, AnnotationMiscArgs)
+	}
+	if val := annotations[AnnotationLogFile]; len(val) > 0 {
+		dst.Spec.LogFile = val
+		delete(annotations, AnnotationLogFile)
+	}
+	if annotations != nil && len(annotations) == 0 {
+		dst.ObjectMeta.Annotations = nil
+	}
+	return nil
+}
+
+// ConvertTo converts this StorageNode to the v1alpha1 hub version.
+func (src *StorageNode) ConvertTo(dst *v1alpha1.StorageNode) error {
+	dst.TypeMeta = src.TypeMeta
+	dst.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	return convertJSON(&src.Status, &dst.Status)
+}
+
+// ConvertFrom converts the given v1alpha1 hub version to this StorageNode.
+func (dst *StorageNode) ConvertFrom(src *v1alpha1.StorageNode) error {
+	dst.TypeMeta = src.TypeMeta
+	dst.APIVersion = SchemeGroupVersion.String()
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	return convertJSON(&src.Status, &dst.Status)
+}
+
+// convertJSON copies the fields that are common between two versions of the same
+// type. Both versions share the json field names, so fields that do not exist in
+// the destination type are ignored.
+func convertJSON(src, dst interface{}) error {
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+func setAnnotation(annotations *map[string]string, key, value string) {
+	if *annotations == nil {
+		*annotations = make(map[string]string)
+	}
+	(*annotations)[key] = value
+}
@@ -0,0 +1,4 @@
+// Package v1beta1 contains API Schema definitions for the core v1beta1 API group
+// +k8s:deepcopy-gen=package,register
+// +groupName=core.libopenstorage.org
+package v1beta1
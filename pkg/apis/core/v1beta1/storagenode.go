@@ -0,0 +1,248 @@
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// StorageNodeResourceName is name for "storagenode" resource
+	StorageNodeResourceName = "storagenode"
+	// StorageNodeResourcePlural is plural for "storagenode" resource
+	StorageNodeResourcePlural = "storagenodes"
+	// StorageNodeShortName is the shortname for "storagenode" resource
+	StorageNodeShortName = "sn"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StorageNode represents a storage node
+type StorageNode struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+	Spec            StorageNodeSpec `json:"spec,omitempty"`
+	Status          NodeStatus      `json:"status,omitemtpy"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StorageNodeList is a list of storage nodes
+type StorageNodeList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []StorageNode `json:"items"`
+}
+
+// StorageNodeSpec is the spec used to define a storage node
+type StorageNodeSpec struct {
+	// Version of the storage driver on the node
+	Version string `json:"version,omitempty"`
+	// CloudStorage configuration specifying storage for the node in cloud environments
+	CloudStorage StorageNodeCloudDriveConfigs `json:"cloudStorage,omitempty"`
+	// Maintenance puts the storage node in maintenance mode when true. The node
+	// is taken out of maintenance mode when it is set back to false.
+	Maintenance bool `json:"maintenance,omitempty"`
+}
+
+// StorageNodeCloudDriveConfigs specifies storage for the node in cloud environments
+type StorageNodeCloudDriveConfigs struct {
+	// DriveConfigs list of cloud drive configs for the storage node
+	DriveConfigs []StorageNodeCloudDriveConfig `json:"driveConfigs,omitempty"`
+	// Pools list of requested sizes for the storage pools on the node. They
+	// override the pool sizes requested in the capacity specs of the cluster.
+	// Storage pools can only be expanded, requests to shrink a pool are rejected.
+	Pools []StoragePoolSpec `json:"pools,omitempty"`
+}
+
+// StoragePoolSpec is the requested size of a storage pool on the node
+type StoragePoolSpec struct {
+	// ID of the storage pool on the node
+	ID int32 `json:"id"`
+	// SizeInGiB is the requested size of the storage pool. It cannot exceed the
+	// maxCapacityInGiB of the capacity spec the pool is created from.
+	SizeInGiB uint64 `json:"sizeInGiB,omitempty"`
+	// Operation used to expand the storage pool. Defaults to resizing the
+	// existing drives of the pool.
+	Operation StoragePoolOperation `json:"operation,omitempty"`
+}
+
+// StoragePoolOperation is the enum type for the operations used to expand a storage pool
+type StoragePoolOperation string
+
+const (
+	// StoragePoolResizeDiskOperation expands the pool by resizing its drives
+	StoragePoolResizeDiskOperation StoragePoolOperation = "resize-disk"
+	// StoragePoolAddDiskOperation expands the pool by adding a new drive to it
+	StoragePoolAddDiskOperation StoragePoolOperation = "add-disk"
+)
+
+// StorageNodeCloudDriveConfig is a structure for storing a configuration for a single drive
+type StorageNodeCloudDriveConfig struct {
+	// Type of cloud storage
+	Type string `json:"type,omitempty"`
+	// Size of cloud storage
+	SizeInGiB uint64 `json:"sizeInGiB,omitempty"`
+	// IOPS provided by cloud storage
+	IOPS uint32 `json:"iops,omitempty"`
+	// Options are additional options to the storage
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// NodeStatus contains the status of the storage node
+type NodeStatus struct {
+	// NodeUID unique identifier for the node
+	NodeUID string `json:"nodeUid,omitempty"`
+	// Phase is the current status of the storage node
+	Phase string `json:"phase,omitempty"`
+	// Network details used by the storage driver
+	Network NetworkStatus `json:"network,omitempty"`
+	// Geo topology information for a node
+	Geo Geography `json:"geography,omitempty"`
+	// Conditions is an array of current node conditions
+	Conditions []NodeCondition `json:"conditions,omitempty"`
+	// Storage details of the storage node
+	Storage *StorageStatus `json:"storage,omitempty"`
+}
+
+// NetworkStatus network status of the storage node
+type NetworkStatus struct {
+	// DataIP is the IP address used by storage driver for data traffic
+	DataIP string `json:"dataIP,omitempty"`
+	// MgmtIP is the IP address used by storage driver for management traffic
+	MgmtIP string `json:"mgmtIP,omitempty"`
+}
+
+// StorageStatus captures the storage layout and capacity of the storage node
+type StorageStatus struct {
+	// TotalSize is the total capacity of the storage pools on the node
+	TotalSize resource.Quantity `json:"totalSize,omitempty"`
+	// UsedSize is the capacity used in the storage pools on the node
+	UsedSize resource.Quantity `json:"usedSize,omitempty"`
+	// Pools is the list of storage pools on the node
+	Pools []StoragePoolStatus `json:"pools,omitempty"`
+	// Drives is the list of drives used by the storage driver on the node
+	Drives []StorageDriveStatus `json:"drives,omitempty"`
+}
+
+// StoragePoolStatus captures the details of a storage pool on the node
+type StoragePoolStatus struct {
+	// ID of the storage pool on the node
+	ID int32 `json:"id"`
+	// MediaType of the drives in the storage pool
+	MediaType string `json:"mediaType,omitempty"`
+	// RAIDLevel of the storage pool
+	RAIDLevel string `json:"raidLevel,omitempty"`
+	// TotalSize is the total capacity of the storage pool
+	TotalSize resource.Quantity `json:"totalSize,omitempty"`
+	// UsedSize is the capacity used in the storage pool
+	UsedSize resource.Quantity `json:"usedSize,omitempty"`
+	// Labels of the storage pool
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// StorageDriveStatus captures the details of a drive used by the storage driver
+type StorageDriveStatus struct {
+	// Path of the drive on the node
+	Path string `json:"path,omitempty"`
+	// MediaType of the drive
+	MediaType string `json:"mediaType,omitempty"`
+	// TotalSize is the capacity of the drive
+	TotalSize resource.Quantity `json:"totalSize,omitempty"`
+	// UsedSize is the capacity used on the drive
+	UsedSize resource.Quantity `json:"usedSize,omitempty"`
+	// Online is true if the drive is online
+	Online bool `json:"online,omitempty"`
+	// Metadata is true if the drive is used to store the metadata of the storage driver
+	Metadata bool `json:"metadata,omitempty"`
+}
+
+// NodeCondition contains condition information for a storage node
+type NodeCondition struct {
+	// Type of the node condition
+	Type NodeConditionType `json:"type,omitempty"`
+	// Status of the condition
+	Status NodeConditionStatus `json:"status,omitempty"`
+	// LastTransitionTime the condition transitioned from one status to another
+	LastTransitionTime meta.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is unique one-word, CamelCase reason for the condition's last transition
+	Reason string `json:"reason,omitempty"`
+	// Message is human readable message indicating details about the last transition
+	Message string `json:"message,omitempty"`
+}
+
+// NodeConditionType is the enum type for different node conditions
+type NodeConditionType string
+
+// These are valid conditions of the storage node. They correspond to different
+// components in the storage cluster node.
+const (
+	// NodeInitCondition is used for initialization state of the node
+	NodeInitCondition NodeConditionType = "NodeInit"
+	// NodeStateCondition is used for overall state of the node
+	NodeStateCondition NodeConditionType = "NodeState"
+	// NodeUpgradeCondition is used for the progress of the node during an update
+	// of the storage cluster
+	NodeUpgradeCondition NodeConditionType = "NodeUpgrade"
+	// NodePreflightCondition is used for the result of the preflight checks run
+	// on the node before the first storage pod is created on it
+	NodePreflightCondition NodeConditionType = "NodePreflight"
+	// NodeMaintenanceCondition is used for the progress of the node entering or
+	// exiting maintenance mode as requested in the spec of the StorageNode
+	NodeMaintenanceCondition NodeConditionType = "NodeMaintenance"
+	// NodeDecommissionCondition is used for the progress of the node while it
+	// is being removed from the storage cluster
+	NodeDecommissionCondition NodeConditionType = "NodeDecommission"
+	// NodePoolExpansionCondition is used for the progress of the expansion of the
+	// storage pools on the node to their requested size
+	NodePoolExpansionCondition NodeConditionType = "NodePoolExpansion"
+)
+
+// NodeConditionStatus is the enum type for node condition statuses
+type NodeConditionStatus string
+
+// These are valid statuses of different node conditions.
+const (
+	// NodeSucceeded means the node condition status is succeeded
+	NodeSucceededStatus NodeConditionStatus = "Succeeded"
+	// NodeFailed means the node condition status is failed
+	NodeFailedStatus NodeConditionStatus = "Failed"
+	// NodeOnlineStatus means the node condition is online and healthy
+	NodeOnlineStatus NodeConditionStatus = "Online"
+	// NodeInitStatus means the node condition is in initializing state
+	NodeInitStatus NodeConditionStatus = "Initializing"
+	// NodeNotInQuorumStatus means the node is not in quorum
+	NodeNotInQuorumStatus NodeConditionStatus = "NotInQuorum"
+	// NodeMaintenanceStatus means the node condition is in maintenance state
+	NodeMaintenanceStatus NodeConditionStatus = "Maintenance"
+	// NodeDecommissionedStatus means the node condition is in decommissioned state
+	NodeDecommissionedStatus NodeConditionStatus = "Decommissioned"
+	// NodeDegradedStatus means the node condition is in degraded state
+	NodeDegradedStatus NodeConditionStatus = "Degraded"
+	// NodeOfflineStatus means the node condition is in offline state
+	NodeOfflineStatus NodeConditionStatus = "Offline"
+	// NodeUnknownStatus means the node condition is not known
+	NodeUnknownStatus NodeConditionStatus = "Unknown"
+	// NodeCordonedStatus means the node has been cordoned for an update
+	NodeCordonedStatus NodeConditionStatus = "Cordoned"
+	// NodeDrainingStatus means the pods using storage are being evicted from the node
+	NodeDrainingStatus NodeConditionStatus = "Draining"
+	// NodeRestartingStatus means the storage pod on the node is being restarted
+	NodeRestartingStatus NodeConditionStatus = "Restarting"
+	// NodeEnteringMaintenanceStatus means the node is entering maintenance mode
+	NodeEnteringMaintenanceStatus NodeConditionStatus = "EnteringMaintenance"
+	// NodeExitingMaintenanceStatus means the node is exiting maintenance mode
+	NodeExitingMaintenanceStatus NodeConditionStatus = "ExitingMaintenance"
+	// NodeRemovingStatus means the node is being removed from the storage cluster
+	NodeRemovingStatus NodeConditionStatus = "Removing"
+	// NodeWipingStatus means the storage driver data is being wiped from the node
+	NodeWipingStatus NodeConditionStatus = "Wiping"
+	// NodeExpandingStatus means the storage pools on the node are being expanded
+	NodeExpandingStatus NodeConditionStatus = "Expanding"
+	// NodeSkippedStatus means the node condition was overridden by the user
+	NodeSkippedStatus NodeConditionStatus = "Skipped"
+)
+
+func init() {
+	SchemeBuilder.Register(&StorageNode{}, &StorageNodeList{})
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1beta1 "github.com/libopenstorage/operator/pkg/client/clientset/versioned/typed/core/v1beta1"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeCoreV1beta1 struct {
+	*testing.Fake
+}
+
+func (c *FakeCoreV1beta1) StorageClusters(namespace string) v1beta1.StorageClusterInterface {
+	return &FakeStorageClusters{c, namespace}
+}
+
+func (c *FakeCoreV1beta1) StorageNodes(namespace string) v1beta1.StorageNodeInterface {
+	return &FakeStorageNodes{c, namespace}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeCoreV1beta1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	v1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	v1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -58,6 +59,12 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1alpha1.SchemeGroupVersion.WithResource("storagenodes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha1().StorageNodes().Informer()}, nil
 
+		// Group=core.libopenstorage.org, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("storageclusters"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1beta1().StorageClusters().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("storagenodes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1beta1().StorageNodes().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
@@ -0,0 +1,242 @@
+package storagecluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	k8sutil "github.com/libopenstorage/operator/pkg/util/k8s"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Reasons of the conditions set by the controller on the StorageCluster
+const (
+	reasonStoragePodsProgressing  = "StoragePodsProgressing"
+	reasonStoragePodsAvailable    = "StoragePodsAvailable"
+	reasonComponentFailed         = "ComponentFailed"
+	reasonReconcileFailed         = "ReconcileFailed"
+	reasonStatusUnavailable       = "StatusUnavailable"
+	reasonRollbackFailed          = "RollbackFailed"
+	reasonAsExpected              = "AsExpected"
+	reasonUpdateInProgress        = "UpdateInProgress"
+	reasonUpdateBlocked           = "UpdateBlocked"
+	reasonUpdatePaused            = "UpdatePaused"
+	reasonUpdatePartitioned       = "UpdatePartitioned"
+	reasonUpdateAwaitingPromotion = "UpdateAwaitingPromotion"
+	reasonUpdatePending           = "UpdatePending"
+	reasonUpdateCompleted         = "UpdateCompleted"
+	reasonRolledBack              = "RolledBack"
+)
+
+// setClusterCondition merges the given condition into the status of the cluster.
+// An existing condition of the same type is updated in place, and its transition
+// time changes only if the status of the condition changes.
+func setClusterCondition(
+	cluster *corev1alpha1.StorageCluster,
+	condition corev1alpha1.ClusterCondition,
+) {
+	condition.ObservedGeneration = cluster.Generation
+	existing := getClusterCondition(cluster, condition.Type)
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		cluster.Status.Conditions = append(cluster.Status.Conditions, condition)
+		return
+	}
+
+	if existing.Status != condition.Status {
+		existing.Status = condition.Status
+		existing.LastTransitionTime = condition.LastTransitionTime
+		if existing.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = metav1.Now()
+		}
+	}
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+	existing.ObservedGeneration = condition.ObservedGeneration
+}
+
+// getClusterCondition returns the condition of the given type from the status
+// of the cluster, or nil if not present
+func getClusterCondition(
+	cluster *corev1alpha1.StorageCluster,
+	conditionType corev1alpha1.ClusterConditionType,
+) *corev1alpha1.ClusterCondition {
+	for i, condition := range cluster.Status.Conditions {
+		if condition.Type == conditionType {
+			return &cluster.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// isClusterConditionTrue returns true if the cluster has a condition of the
+// given type with status True
+func isClusterConditionTrue(
+	cluster *corev1alpha1.StorageCluster,
+	conditionType corev1alpha1.ClusterConditionType,
+) bool {
+	condition := getClusterCondition(cluster, conditionType)
+	return condition != nil && condition.Status == corev1alpha1.ConditionTrue
+}
+
+// toDeletingCondition translates the delete condition reported by the storage
+// driver, or recorded by older versions of the operator, to the Deleting
+// condition. The status of the legacy condition becomes the reason, which is
+// also the phase of the cluster, and its reason becomes the message.
+func toDeletingCondition(condition corev1alpha1.ClusterCondition) corev1alpha1.ClusterCondition {
+	if condition.Type != corev1alpha1.ClusterConditionTypeDelete {
+		return condition
+	}
+	return corev1alpha1.ClusterCondition{
+		Type:               corev1alpha1.ClusterConditionTypeDeleting,
+		Status:             corev1alpha1.ConditionTrue,
+		ObservedGeneration: condition.ObservedGeneration,
+		LastTransitionTime: condition.LastTransitionTime,
+		Reason:             "Delete" + string(condition.Status),
+		Message:            condition.Reason,
+	}
+}
+
+// migrateLegacyConditions translates the delete condition set by older versions
+// of the operator to the Deleting condition, and removes the install, upgrade
+// and rollback conditions, whose state is reported by the Available, Progressing,
+// Upgrading and Degraded conditions instead. It returns true if the conditions
+// of the cluster have changed.
+func migrateLegacyConditions(cluster *corev1alpha1.StorageCluster) bool {
+	changed := false
+	conditions := make([]corev1alpha1.ClusterCondition, 0, len(cluster.Status.Conditions))
+	for _, condition := range cluster.Status.Conditions {
+		switch condition.Type {
+		case corev1alpha1.ClusterConditionTypeDelete:
+			conditions = append(conditions, toDeletingCondition(condition))
+			changed = true
+		case corev1alpha1.ClusterConditionTypeInstall,
+			corev1alpha1.ClusterConditionTypeUpgrade,
+			corev1alpha1.ClusterConditionTypeRollback:
+			changed = true
+		default:
+			conditions = append(conditions, condition)
+		}
+	}
+	if changed {
+		cluster.Status.Conditions = conditions
+	}
+	return changed
+}
+
+// updateClusterConditions sets the Available, Progressing and Degraded conditions
+// of the cluster based on its current status. The given error is the one returned
+// by the driver while getting the status of the storage cluster, if any.
+func updateClusterConditions(
+	cluster *corev1alpha1.StorageCluster,
+	statusErr error,
+) {
+	available := corev1alpha1.ClusterCondition{
+		Type:    corev1alpha1.ClusterConditionTypeAvailable,
+		Status:  corev1alpha1.ConditionFalse,
+		Reason:  cluster.Status.Phase,
+		Message: fmt.Sprintf("Storage cluster is %s", cluster.Status.Phase),
+	}
+	switch cluster.Status.Phase {
+	case string(corev1alpha1.ClusterOnline):
+		available.Status = corev1alpha1.ConditionTrue
+	case "", string(corev1alpha1.ClusterUnknown):
+		available.Status = corev1alpha1.ConditionUnknown
+		available.Reason = string(corev1alpha1.ClusterUnknown)
+		available.Message = "Status of the storage cluster is not known"
+	}
+	setClusterCondition(cluster, available)
+
+	desired := cluster.Status.DesiredNumberScheduled
+	progressing := corev1alpha1.ClusterCondition{
+		Type:   corev1alpha1.ClusterConditionTypeProgressing,
+		Status: corev1alpha1.ConditionFalse,
+		Reason: reasonStoragePodsAvailable,
+		Message: fmt.Sprintf("All %d storage pods are updated and available",
+			desired),
+	}
+	if cluster.Status.UpdatedNumberScheduled < desired || cluster.Status.NumberAvailable < desired {
+		progressing.Status = corev1alpha1.ConditionTrue
+		progressing.Reason = reasonStoragePodsProgressing
+		progressing.Message = fmt.Sprintf("%d of %d storage pods are updated and %d are available",
+			cluster.Status.UpdatedNumberScheduled, desired, cluster.Status.NumberAvailable)
+	}
+	setClusterCondition(cluster, progressing)
+
+	degraded := corev1alpha1.ClusterCondition{
+		Type:   corev1alpha1.ClusterConditionTypeDegraded,
+		Status: corev1alpha1.ConditionFalse,
+		Reason: reasonAsExpected,
+	}
+	var failedComponents []string
+	for _, component := range cluster.Status.ComponentConditions {
+		if component.Status == corev1alpha1.ComponentFailed {
+			failedComponents = append(failedComponents, component.Name)
+		}
+	}
+	if len(failedComponents) > 0 {
+		degraded.Status = corev1alpha1.ConditionTrue
+		degraded.Reason = reasonComponentFailed
+		degraded.Message = fmt.Sprintf("Failed to setup components: %s",
+			strings.Join(failedComponents, ", "))
+	} else if statusErr != nil {
+		degraded.Status = corev1alpha1.ConditionTrue
+		degraded.Reason = reasonStatusUnavailable
+		degraded.Message = fmt.Sprintf("Failed to get status of the storage cluster: %v", statusErr)
+	}
+	setClusterCondition(cluster, degraded)
+}
+
+// syncLegacyConditions migrates the conditions set on the cluster by older
+// versions of the operator, and saves them before the cluster is reconciled
+func (c *Controller) syncLegacyConditions(cluster *corev1alpha1.StorageCluster) error {
+	toUpdate := cluster.DeepCopy()
+	if !migrateLegacyConditions(toUpdate) {
+		return nil
+	}
+	if err := k8sutil.UpdateStorageClusterStatus(c.client, toUpdate); err != nil {
+		return fmt.Errorf("failed to update conditions of StorageCluster %v/%v: %v",
+			cluster.Namespace, cluster.Name, err)
+	}
+	cluster.ResourceVersion = toUpdate.ResourceVersion
+	cluster.Status.Conditions = toUpdate.Status.Conditions
+	return nil
+}
+
+// setReconcileFailedCondition marks the cluster as degraded as the last
+// reconcile failed with the given error. Failures to update the condition are
+// only logged, as the reconcile is retried anyway.
+func (c *Controller) setReconcileFailedCondition(
+	cluster *corev1alpha1.StorageCluster,
+	reconcileErr error,
+) {
+	toUpdate := &corev1alpha1.StorageCluster{}
+	err := c.client.Get(
+		context.TODO(),
+		types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace},
+		toUpdate,
+	)
+	if errors.IsNotFound(err) {
+		return
+	} else if err != nil {
+		logrus.Warnf("Failed to get StorageCluster %v/%v. %v", cluster.Namespace, cluster.Name, err)
+		return
+	}
+
+	setClusterCondition(toUpdate, corev1alpha1.ClusterCondition{
+		Type:    corev1alpha1.ClusterConditionTypeDegraded,
+		Status:  corev1alpha1.ConditionTrue,
+		Reason:  reasonReconcileFailed,
+		Message: reconcileErr.Error(),
+	})
+	if err := k8sutil.UpdateStorageClusterStatus(c.client, toUpdate); err != nil && !errors.IsNotFound(err) {
+		logrus.Warnf("Failed to update status of StorageCluster %v/%v. %v",
+			cluster.Namespace, cluster.Name, err)
+	}
+}
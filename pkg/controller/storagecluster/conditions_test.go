@@ -0,0 +1,216 @@
+package storagecluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetClusterCondition(t *testing.T) {
+	cluster := createStorageCluster()
+	cluster.Generation = 2
+
+	// A new condition should get the transition time and generation set
+	setClusterCondition(cluster, corev1alpha1.ClusterCondition{
+		Type:    corev1alpha1.ClusterConditionTypeAvailable,
+		Status:  corev1alpha1.ConditionFalse,
+		Reason:  "Initializing",
+		Message: "Storage cluster is Initializing",
+	})
+
+	require.Len(t, cluster.Status.Conditions, 1)
+	condition := getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeAvailable)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ConditionFalse, condition.Status)
+	require.Equal(t, "Initializing", condition.Reason)
+	require.Equal(t, "Storage cluster is Initializing", condition.Message)
+	require.Equal(t, int64(2), condition.ObservedGeneration)
+	require.False(t, condition.LastTransitionTime.IsZero())
+	require.False(t, isClusterConditionTrue(cluster, corev1alpha1.ClusterConditionTypeAvailable))
+
+	// The transition time should not change if the status is unchanged
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	condition.LastTransitionTime = transitionTime
+	cluster.Generation = 3
+
+	setClusterCondition(cluster, corev1alpha1.ClusterCondition{
+		Type:    corev1alpha1.ClusterConditionTypeAvailable,
+		Status:  corev1alpha1.ConditionFalse,
+		Reason:  "Offline",
+		Message: "Storage cluster is Offline",
+	})
+
+	require.Len(t, cluster.Status.Conditions, 1)
+	condition = getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeAvailable)
+	require.Equal(t, "Offline", condition.Reason)
+	require.Equal(t, "Storage cluster is Offline", condition.Message)
+	require.Equal(t, int64(3), condition.ObservedGeneration)
+	require.Equal(t, transitionTime, condition.LastTransitionTime)
+
+	// The transition time should change if the status changes
+	setClusterCondition(cluster, corev1alpha1.ClusterCondition{
+		Type:   corev1alpha1.ClusterConditionTypeAvailable,
+		Status: corev1alpha1.ConditionTrue,
+		Reason: "Online",
+	})
+
+	require.Len(t, cluster.Status.Conditions, 1)
+	condition = getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeAvailable)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, "Online", condition.Reason)
+	require.Empty(t, condition.Message)
+	require.True(t, transitionTime.Before(&condition.LastTransitionTime))
+	require.True(t, isClusterConditionTrue(cluster, corev1alpha1.ClusterConditionTypeAvailable))
+
+	// Conditions of other types should not be affected
+	setClusterCondition(cluster, corev1alpha1.ClusterCondition{
+		Type:   corev1alpha1.ClusterConditionTypeDegraded,
+		Status: corev1alpha1.ConditionFalse,
+		Reason: reasonAsExpected,
+	})
+
+	require.Len(t, cluster.Status.Conditions, 2)
+	require.True(t, isClusterConditionTrue(cluster, corev1alpha1.ClusterConditionTypeAvailable))
+	require.False(t, isClusterConditionTrue(cluster, corev1alpha1.ClusterConditionTypeDegraded))
+	require.Nil(t, getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeDeleting))
+}
+
+func TestUpdateClusterConditions(t *testing.T) {
+	cluster := createStorageCluster()
+
+	// Availability is unknown until the driver reports the cluster status
+	updateClusterConditions(cluster, nil)
+
+	require.Len(t, cluster.Status.Conditions, 3)
+	condition := getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeAvailable)
+	require.Equal(t, corev1alpha1.ConditionUnknown, condition.Status)
+	require.Equal(t, string(corev1alpha1.ClusterUnknown), condition.Reason)
+
+	condition = getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeProgressing)
+	require.Equal(t, corev1alpha1.ConditionFalse, condition.Status)
+	require.Equal(t, reasonStoragePodsAvailable, condition.Reason)
+
+	condition = getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeDegraded)
+	require.Equal(t, corev1alpha1.ConditionFalse, condition.Status)
+	require.Equal(t, reasonAsExpected, condition.Reason)
+
+	// Cluster is not available and progressing while pods are coming up
+	cluster.Status.Phase = string(corev1alpha1.ClusterInit)
+	cluster.Status.DesiredNumberScheduled = 3
+	cluster.Status.UpdatedNumberScheduled = 3
+	cluster.Status.NumberAvailable = 1
+
+	updateClusterConditions(cluster, nil)
+
+	condition = getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeAvailable)
+	require.Equal(t, corev1alpha1.ConditionFalse, condition.Status)
+	require.Equal(t, "Initializing", condition.Reason)
+	require.Equal(t, "Storage cluster is Initializing", condition.Message)
+
+	condition = getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeProgressing)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonStoragePodsProgressing, condition.Reason)
+	require.Equal(t, "3 of 3 storage pods are updated and 1 are available", condition.Message)
+
+	// Cluster is available once it is online and all pods are available
+	cluster.Status.Phase = string(corev1alpha1.ClusterOnline)
+	cluster.Status.NumberAvailable = 3
+
+	updateClusterConditions(cluster, nil)
+
+	require.True(t, isClusterConditionTrue(cluster, corev1alpha1.ClusterConditionTypeAvailable))
+	condition = getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeProgressing)
+	require.Equal(t, corev1alpha1.ConditionFalse, condition.Status)
+	require.Equal(t, "All 3 storage pods are updated and available", condition.Message)
+	require.False(t, isClusterConditionTrue(cluster, corev1alpha1.ClusterConditionTypeDegraded))
+
+	// Cluster is degraded if the status cannot be fetched from the driver
+	updateClusterConditions(cluster, fmt.Errorf("status error"))
+
+	condition = getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeDegraded)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonStatusUnavailable, condition.Reason)
+	require.Equal(t, "Failed to get status of the storage cluster: status error", condition.Message)
+
+	// Cluster is degraded if any component failed
+	cluster.Status.ComponentConditions = []corev1alpha1.ComponentCondition{
+		{Name: "Autopilot", Status: corev1alpha1.ComponentFailed},
+		{Name: "Lighthouse", Status: corev1alpha1.ComponentDisabled},
+		{Name: "Stork", Status: corev1alpha1.ComponentFailed},
+	}
+
+	updateClusterConditions(cluster, nil)
+
+	condition = getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeDegraded)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonComponentFailed, condition.Reason)
+	require.Equal(t, "Failed to setup components: Autopilot, Stork", condition.Message)
+
+	// Cluster is no longer degraded once the components are fixed
+	cluster.Status.ComponentConditions[0].Status = corev1alpha1.ComponentReady
+	cluster.Status.ComponentConditions[2].Status = corev1alpha1.ComponentReady
+
+	updateClusterConditions(cluster, nil)
+
+	require.Len(t, cluster.Status.Conditions, 3)
+	condition = getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeDegraded)
+	require.Equal(t, corev1alpha1.ConditionFalse, condition.Status)
+	require.Equal(t, reasonAsExpected, condition.Reason)
+	require.Empty(t, condition.Message)
+}
+
+func TestMigrateLegacyConditions(t *testing.T) {
+	cluster := createStorageCluster()
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	cluster.Status.Conditions = []corev1alpha1.ClusterCondition{
+		{
+			Type:   corev1alpha1.ClusterConditionTypeInstall,
+			Status: corev1alpha1.ClusterOperationCompleted,
+			Reason: "Portworx installed",
+		},
+		{
+			Type:   corev1alpha1.ClusterConditionTypeAvailable,
+			Status: corev1alpha1.ConditionTrue,
+			Reason: "Online",
+		},
+		{
+			Type:               corev1alpha1.ClusterConditionTypeDelete,
+			Status:             corev1alpha1.ClusterOperationInProgress,
+			Reason:             "Started node wiper daemonset",
+			LastTransitionTime: transitionTime,
+		},
+		{
+			Type:   corev1alpha1.ClusterConditionTypeUpgrade,
+			Status: corev1alpha1.ClusterOperationFailed,
+		},
+		{
+			Type:   corev1alpha1.ClusterConditionTypeRollback,
+			Status: corev1alpha1.ClusterOperationCompleted,
+		},
+	}
+
+	// The delete condition should be translated and the others removed
+	require.True(t, migrateLegacyConditions(cluster))
+	require.Equal(t, []corev1alpha1.ClusterCondition{
+		{
+			Type:   corev1alpha1.ClusterConditionTypeAvailable,
+			Status: corev1alpha1.ConditionTrue,
+			Reason: "Online",
+		},
+		{
+			Type:               corev1alpha1.ClusterConditionTypeDeleting,
+			Status:             corev1alpha1.ConditionTrue,
+			Reason:             corev1alpha1.ClusterDeleteInProgressReason,
+			Message:            "Started node wiper daemonset",
+			LastTransitionTime: transitionTime,
+		},
+	}, cluster.Status.Conditions)
+
+	// Conditions should not change once migrated
+	require.False(t, migrateLegacyConditions(cluster))
+	require.Len(t, cluster.Status.Conditions, 2)
+}
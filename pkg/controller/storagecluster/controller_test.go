@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/hashicorp/go-version"
 	"github.com/libopenstorage/operator/drivers/storage"
 	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	corev1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
 	"github.com/libopenstorage/operator/pkg/client/clientset/versioned/fake"
 	"github.com/libopenstorage/operator/pkg/util"
 	testutil "github.com/libopenstorage/operator/pkg/util/test"
@@ -21,7 +23,9 @@ import (
 	operatorops "github.com/portworx/sched-ops/k8s/operator"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	fakeextclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -30,13 +34,16 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	kversion "k8s.io/apimachinery/pkg/version"
 	fakediscovery "k8s.io/client-go/discovery/fake"
 	fakek8sclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	k8scontroller "k8s.io/kubernetes/pkg/controller"
 	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -89,10 +96,13 @@ func TestRegisterCRD(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, storageClusterCRDName, crd.Name)
 	require.Equal(t, corev1alpha1.SchemeGroupVersion.Group, crd.Spec.Group)
-	require.Len(t, crd.Spec.Versions, 1)
+	require.Len(t, crd.Spec.Versions, 2)
 	require.Equal(t, corev1alpha1.SchemeGroupVersion.Version, crd.Spec.Versions[0].Name)
 	require.True(t, crd.Spec.Versions[0].Served)
 	require.True(t, crd.Spec.Versions[0].Storage)
+	require.Equal(t, corev1beta1.SchemeGroupVersion.Version, crd.Spec.Versions[1].Name)
+	require.False(t, crd.Spec.Versions[1].Served)
+	require.False(t, crd.Spec.Versions[1].Storage)
 	require.Equal(t, apiextensionsv1beta1.NamespaceScoped, crd.Spec.Scope)
 	require.Equal(t, corev1alpha1.StorageClusterResourceName, crd.Spec.Names.Singular)
 	require.Equal(t, corev1alpha1.StorageClusterResourcePlural, crd.Spec.Names.Plural)
@@ -111,10 +121,13 @@ func TestRegisterCRD(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, storageNodeCRDName, crd.Name)
 	require.Equal(t, corev1alpha1.SchemeGroupVersion.Group, crd.Spec.Group)
-	require.Len(t, crd.Spec.Versions, 1)
+	require.Len(t, crd.Spec.Versions, 2)
 	require.Equal(t, corev1alpha1.SchemeGroupVersion.Version, crd.Spec.Versions[0].Name)
 	require.True(t, crd.Spec.Versions[0].Served)
 	require.True(t, crd.Spec.Versions[0].Storage)
+	require.Equal(t, corev1beta1.SchemeGroupVersion.Version, crd.Spec.Versions[1].Name)
+	require.False(t, crd.Spec.Versions[1].Served)
+	require.False(t, crd.Spec.Versions[1].Storage)
 	require.Equal(t, apiextensionsv1beta1.NamespaceScoped, crd.Spec.Scope)
 	require.Equal(t, corev1alpha1.StorageNodeResourceName, crd.Spec.Names.Singular)
 	require.Equal(t, corev1alpha1.StorageNodeResourcePlural, crd.Spec.Names.Plural)
@@ -135,6 +148,60 @@ func TestRegisterCRD(t *testing.T) {
 	require.Len(t, crds.Items, 2)
 	require.Equal(t, storageClusterCRDName, crds.Items[0].Name)
 	require.Equal(t, storageNodeCRDName, crds.Items[1].Name)
+
+	// If CRDs from an older version are present, then the new versions should be added
+	for _, existingCRD := range crds.Items {
+		existingCRD.Spec.Versions = existingCRD.Spec.Versions[:1]
+		_, err = fakeExtClient.ApiextensionsV1beta1().
+			CustomResourceDefinitions().
+			Update(&existingCRD)
+		require.NoError(t, err)
+	}
+
+	err = controller.RegisterCRD()
+	require.NoError(t, err)
+
+	crds, err = fakeExtClient.ApiextensionsV1beta1().
+		CustomResourceDefinitions().
+		List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, crds.Items, 2)
+	for _, crd := range crds.Items {
+		require.Len(t, crd.Spec.Versions, 2)
+		require.Equal(t, corev1beta1.SchemeGroupVersion.Version, crd.Spec.Versions[1].Name)
+	}
+
+	// If the conversion webhook has been set up, then the versions it serves
+	// and the conversion strategy should not be reset
+	conversion := &apiextensionsv1beta1.CustomResourceConversion{
+		Strategy: apiextensionsv1beta1.WebhookConverter,
+		WebhookClientConfig: &apiextensionsv1beta1.WebhookClientConfig{
+			CABundle: []byte("ca-bundle"),
+		},
+	}
+	for _, existingCRD := range crds.Items {
+		existingCRD.Spec.Versions[1].Served = true
+		existingCRD.Spec.Conversion = conversion
+		_, err = fakeExtClient.ApiextensionsV1beta1().
+			CustomResourceDefinitions().
+			Update(&existingCRD)
+		require.NoError(t, err)
+	}
+
+	err = controller.RegisterCRD()
+	require.NoError(t, err)
+
+	crds, err = fakeExtClient.ApiextensionsV1beta1().
+		CustomResourceDefinitions().
+		List(metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, crds.Items, 2)
+	for _, crd := range crds.Items {
+		require.Len(t, crd.Spec.Versions, 2)
+		require.True(t, crd.Spec.Versions[0].Served)
+		require.True(t, crd.Spec.Versions[1].Served)
+		require.Equal(t, conversion, crd.Spec.Conversion)
+	}
 }
 
 func TestRegisterCRDShouldRemoveNodeStatusCRD(t *testing.T) {
@@ -240,7 +307,7 @@ func TestKubernetesVersionValidation(t *testing.T) {
 			v1.EventTypeWarning, util.FailedValidationReason))
 }
 
-func TestSingleClusterValidation(t *testing.T) {
+func TestSingleClusterPerNamespaceValidation(t *testing.T) {
 	existingCluster := &corev1alpha1.StorageCluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:       "main-cluster",
@@ -251,7 +318,7 @@ func TestSingleClusterValidation(t *testing.T) {
 	cluster := &corev1alpha1.StorageCluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "extra-cluster",
-			Namespace: "extra-ns",
+			Namespace: "main-ns",
 		},
 	}
 
@@ -272,16 +339,161 @@ func TestSingleClusterValidation(t *testing.T) {
 	}
 	result, err := controller.Reconcile(request)
 	require.Empty(t, result)
-	require.Contains(t, err.Error(), fmt.Sprintf("only one StorageCluster is allowed in a Kubernetes cluster. "+
+	require.Contains(t, err.Error(), fmt.Sprintf("only one StorageCluster is allowed in a namespace. "+
 		"StorageCluster %s/%s already exists", existingCluster.Namespace, existingCluster.Name))
 
 	require.Len(t, recorder.Events, 1)
 	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v only one StorageCluster is allowed in a Kubernetes cluster. "+
+		fmt.Sprintf("%v %v only one StorageCluster is allowed in a namespace. "+
 			"StorageCluster %s/%s already exists", v1.EventTypeWarning, util.FailedValidationReason,
 			existingCluster.Namespace, existingCluster.Name))
 }
 
+func TestStoragePodsShouldNotBeScheduledOnNodesOfOtherClusters(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.CreationTimestamp = metav1.Now()
+
+	// An older cluster in another namespace selecting the pool-a nodes
+	olderCluster := createStorageCluster()
+	olderCluster.UID = "older-uid"
+	olderCluster.Name = "older-cluster"
+	olderCluster.Namespace = "older-ns"
+	olderCluster.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	olderCluster.Spec.Placement = &corev1alpha1.PlacementSpec{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{
+								Key:      "pool",
+								Operator: v1.NodeSelectorOpIn,
+								Values:   []string{"pool-a"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// k8s-node-1 is selected by the older cluster, k8s-node-2 is free,
+	// k8s-node-3 already runs a pod of the older cluster and k8s-node-4
+	// already runs a pod of the current cluster
+	k8sNode1 := createK8sNode("k8s-node-1", 10)
+	k8sNode1.Labels = map[string]string{"pool": "pool-a"}
+	k8sNode2 := createK8sNode("k8s-node-2", 10)
+	k8sNode3 := createK8sNode("k8s-node-3", 10)
+	k8sNode4 := createK8sNode("k8s-node-4", 10)
+	k8sNode4.Labels = map[string]string{"pool": "pool-a"}
+	olderPod := createStoragePod(olderCluster, "older-pod", k8sNode3.Name, nil)
+	currentPod := createStoragePod(cluster, "current-pod", k8sNode4.Name, map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	})
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster, olderCluster,
+		k8sNode1, k8sNode2, k8sNode3, k8sNode4, olderPod, currentPod)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).
+		Return(v1.PodSpec{}, nil).
+		AnyTimes()
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// Verify the overlapping node claims are reported
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v Nodes [k8s-node-1 k8s-node-3] selected by the cluster are already "+
+			"claimed by StorageCluster older-ns/older-cluster",
+			v1.EventTypeWarning, util.FailedNodeClaimReason))
+
+	// Verify a pod is created only on the node that is not claimed by any cluster
+	require.Len(t, podControl.Templates, 1)
+	storageNodes := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, k8sNode2.Name, storageNodes.Items[0].Name)
+	require.Equal(t, cluster.Namespace, storageNodes.Items[0].Namespace)
+	require.NotContains(t, podControl.DeletePodName, olderPod.Name)
+
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	err = testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.NoError(t, err)
+	condition := getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeNodesClaimed)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, util.FailedNodeClaimReason, condition.Reason)
+	require.Contains(t, condition.Message, "Nodes [k8s-node-1 k8s-node-3]")
+
+	// The same node claims should not be reported again
+	podControl.Templates = nil
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, recorder.Events)
+
+	// Once the older cluster is deleted, its nodes can be claimed
+	err = k8sClient.Delete(context.TODO(), olderCluster)
+	require.NoError(t, err)
+	err = k8sClient.Delete(context.TODO(), olderPod)
+	require.NoError(t, err)
+	podControl.Templates = nil
+
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, recorder.Events)
+	require.Len(t, podControl.Templates, 3)
+
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	err = testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.NoError(t, err)
+	condition = getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeNodesClaimed)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ConditionFalse, condition.Status)
+}
+
 func TestStorageClusterDefaults(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -538,7 +750,8 @@ func TestFailureDuringStorkInstallation(t *testing.T) {
 	driver.EXPECT().String().Return(driverName).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 
-	// Reconcile should not fail on stork install failure. Only event should be raised.
+	// Reconcile should not fail on stork install failure. The failure should
+	// be reported in the status of the cluster.
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      cluster.Name,
@@ -549,9 +762,13 @@ func TestFailureDuringStorkInstallation(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, result)
 
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedComponentReason))
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Empty(t, recorder.Events)
+	condition := util.GetComponentCondition(updatedCluster, storkComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup Stork.")
 }
 
 func TestFailureDuringDriverPreInstall(t *testing.T) {
@@ -699,8 +916,11 @@ func TestStoragePodGetsScheduled(t *testing.T) {
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
 	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).
 		Do(func(c *corev1alpha1.StorageCluster) {
-			hash := computeHash(&c.Spec, nil)
+			hash := computeHash(&c.Spec, "", nil)
 			expectedPodTemplate.Labels[defaultStorageClusterUniqueLabelKey] = hash
+			expectedPodTemplate.Annotations = map[string]string{
+				annotationNodeGroupHash: computeNodeGroupHash(&c.Spec),
+			}
 		})
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).
 		Return(expectedPodSpec, nil).
@@ -842,195 +1062,2033 @@ func TestStorageNodeGetsCreated(t *testing.T) {
 	require.Len(t, storageNodes.Items, 2)
 }
 
-func TestStoragePodGetsScheduledWithCustomNodeSpecs(t *testing.T) {
+func TestStoragePodGetsScheduledAfterPreflightChecks(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
-	useAllDevices := true
-	cluster.Spec.Storage = &corev1alpha1.StorageSpec{
-		UseAll: &useAllDevices,
+	cluster.Spec.Preflight = &corev1alpha1.PreflightSpec{Enabled: true}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
+	k8sNode := createK8sNode("k8s-node-1", 1)
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster, k8sNode)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
 	}
-	cluster.Spec.Network = &corev1alpha1.NetworkSpec{
-		DataInterface: stringPtr("cluster_data_intf"),
-		MgmtInterface: stringPtr("cluster_mgmt_intf"),
+
+	preflightPodSpec := v1.PodSpec{
+		Containers: []v1.Container{{Name: "preflight"}},
 	}
-	cluster.Spec.Env = []v1.EnvVar{
-		{
-			Name:  "ENV_CLUSTER",
-			Value: "cluster_value",
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().GetPreflightPodSpec(gomock.Any(), k8sNode.Name).Return(preflightPodSpec, nil).Times(1)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
 		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, recorder.Events)
+
+	// Storage pod should not be created until the preflight checks have passed
+	require.Empty(t, podControl.Templates)
+
+	jobs := &batchv1.JobList{}
+	err = testutil.List(k8sClient, jobs)
+	require.NoError(t, err)
+	require.Len(t, jobs.Items, 1)
+	job := jobs.Items[0]
+	require.Equal(t, preflightJobName(cluster.Name, k8sNode.Name), job.Name)
+	require.Equal(t, cluster.Namespace, job.Namespace)
+	require.Equal(t, []metav1.OwnerReference{*clusterRef}, job.OwnerReferences)
+	require.Equal(t, preflightLabels(cluster), job.Labels)
+	require.Equal(t, preflightLabels(cluster), job.Spec.Template.Labels)
+	require.Equal(t, int32(0), *job.Spec.BackoffLimit)
+	require.Equal(t, k8sNode.Name, job.Spec.Template.Spec.NodeName)
+	require.Equal(t, v1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+	require.Equal(t, preflightPodSpec.Containers, job.Spec.Template.Spec.Containers)
+
+	storageNode := &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, k8sNode.Name, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNode.Status.Phase)
+	require.Len(t, storageNode.Status.Conditions, 1)
+	require.Equal(t, corev1alpha1.NodePreflightCondition, storageNode.Status.Conditions[0].Type)
+	require.Equal(t, corev1alpha1.NodeInitStatus, storageNode.Status.Conditions[0].Status)
+
+	// Storage pod should not be created while the preflight checks are running,
+	// and the checks should not be started again
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.Templates)
+
+	jobs = &batchv1.JobList{}
+	err = testutil.List(k8sClient, jobs)
+	require.NoError(t, err)
+	require.Len(t, jobs.Items, 1)
+
+	// Storage pod should be created once the preflight checks have passed
+	job = jobs.Items[0]
+	job.Status.Conditions = []batchv1.JobCondition{
 		{
-			Name:  "ENV_OVERRIDE",
-			Value: "override_cluster_value",
+			Type:   batchv1.JobComplete,
+			Status: v1.ConditionTrue,
 		},
 	}
-	cluster.Spec.RuntimeOpts = map[string]string{
-		"cluster_rt_one": "rt_val_1",
+	err = k8sClient.Update(context.TODO(), &job)
+	require.NoError(t, err)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, recorder.Events)
+	require.Len(t, podControl.Templates, 1)
+
+	storageNode = &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, k8sNode.Name, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNode.Status.Phase)
+	require.Len(t, storageNode.Status.Conditions, 1)
+	require.Equal(t, corev1alpha1.NodeSucceededStatus, storageNode.Status.Conditions[0].Status)
+
+	// The preflight job should be removed after the checks have passed
+	jobs = &batchv1.JobList{}
+	err = testutil.List(k8sClient, jobs)
+	require.NoError(t, err)
+	require.Empty(t, jobs.Items)
+
+	// Storage pod should be recreated without running the checks again
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Len(t, podControl.Templates, 2)
+
+	jobs = &batchv1.JobList{}
+	err = testutil.List(k8sClient, jobs)
+	require.NoError(t, err)
+	require.Empty(t, jobs.Items)
+}
+
+func TestStoragePodNotScheduledIfPreflightChecksFail(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Spec.Preflight = &corev1alpha1.PreflightSpec{Enabled: true}
+	k8sNode := createK8sNode("k8s-node-1", 1)
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster, k8sNode)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
 	}
-	cluster.Spec.Nodes = []corev1alpha1.NodeSpec{
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().GetPreflightPodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).Times(1)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.Templates)
+
+	// Fail the preflight job and report the failed checks from the pod
+	job := &batchv1.Job{}
+	err = testutil.Get(k8sClient, job, preflightJobName(cluster.Name, k8sNode.Name), cluster.Namespace)
+	require.NoError(t, err)
+	job.Status.Conditions = []batchv1.JobCondition{
 		{
-			// Match using node name
-			Selector: corev1alpha1.NodeSelector{
-				NodeName: "k8s-node-1",
-			},
-			CommonConfig: corev1alpha1.CommonConfig{
-				Storage: &corev1alpha1.StorageSpec{
-					Devices: stringSlicePtr([]string{"dev1"}),
-				},
-				Network: &corev1alpha1.NetworkSpec{
-					DataInterface: stringPtr("dface"),
-					MgmtInterface: stringPtr("mface"),
-				},
-				Env: []v1.EnvVar{
-					{
-						Name:  "ENV_NODE",
-						Value: "node_value",
-					},
-					{
-						Name:  "ENV_OVERRIDE",
-						Value: "override_node_value",
+			Type:   batchv1.JobFailed,
+			Status: v1.ConditionTrue,
+		},
+	}
+	err = k8sClient.Update(context.TODO(), job)
+	require.NoError(t, err)
+
+	preflightPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name + "-abcde",
+			Namespace: cluster.Namespace,
+			Labels: map[string]string{
+				labelKeyJobName: job.Name,
+			},
+		},
+		Spec: v1.PodSpec{
+			NodeName: k8sNode.Name,
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodFailed,
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					State: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{
+							ExitCode: 1,
+							Message:  "port 9001 is already in use\ndevice /dev/sdb is not a block device\n",
+						},
 					},
 				},
-				RuntimeOpts: map[string]string{
-					"rt_one": "rt_val_1",
-					"rt_two": "rt_val_2",
-				},
 			},
 		},
-		{
-			// Match using a label selector
-			Selector: corev1alpha1.NodeSelector{
-				LabelSelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{
-						"test": "node2",
+	}
+	err = k8sClient.Create(context.TODO(), preflightPod)
+	require.NoError(t, err)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.Templates)
+
+	expectedMessage := "Preflight checks failed: port 9001 is already in use, " +
+		"device /dev/sdb is not a block device"
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t, fmt.Sprintf("%v %v Not creating storage pod on node %s. %s",
+		v1.EventTypeWarning, util.FailedPreflightReason, k8sNode.Name, expectedMessage),
+		<-recorder.Events)
+
+	storageNode := &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, k8sNode.Name, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, string(corev1alpha1.NodeFailedStatus), storageNode.Status.Phase)
+	require.Len(t, storageNode.Status.Conditions, 1)
+	require.Equal(t, corev1alpha1.NodePreflightCondition, storageNode.Status.Conditions[0].Type)
+	require.Equal(t, corev1alpha1.NodeFailedStatus, storageNode.Status.Conditions[0].Status)
+	require.Equal(t, expectedMessage, storageNode.Status.Conditions[0].Message)
+
+	// The failed job should be kept, so the checks are not run again
+	// and the same failure is not reported again
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.Templates)
+	require.Empty(t, recorder.Events)
+
+	err = testutil.Get(k8sClient, job, job.Name, job.Namespace)
+	require.NoError(t, err)
+
+	// Storage pod should be created if the checks are skipped on the node
+	storageNode.Annotations = map[string]string{AnnotationSkipPreflight: "true"}
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Len(t, podControl.Templates, 1)
+
+	storageNode = &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, k8sNode.Name, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, string(corev1alpha1.NodeInitStatus), storageNode.Status.Phase)
+	require.Equal(t, corev1alpha1.NodeSkippedStatus, storageNode.Status.Conditions[0].Status)
+
+	jobs := &batchv1.JobList{}
+	err = testutil.List(k8sClient, jobs)
+	require.NoError(t, err)
+	require.Empty(t, jobs.Items)
+}
+
+func TestPreflightChecksOnNodesWithExistingStorageNodes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Spec.Preflight = &corev1alpha1.PreflightSpec{Enabled: true}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
+	k8sNode1 := createK8sNode("k8s-node-1", 1)
+	k8sNode2 := createK8sNode("k8s-node-2", 1)
+	// Storage node of a node that was running a storage pod before the checks were enabled
+	storageNode := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            k8sNode1.Name,
+			Namespace:       cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*clusterRef},
+		},
+		Status: corev1alpha1.NodeStatus{
+			Phase: string(corev1alpha1.NodeOnlineStatus),
+		},
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster, k8sNode1, k8sNode2, storageNode)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().GetPreflightPodSpec(gomock.Any(), k8sNode2.Name).Return(v1.PodSpec{}, nil).Times(1)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// Storage pod should be created only on the node that has run a storage pod before
+	require.Len(t, podControl.Templates, 1)
+
+	jobs := &batchv1.JobList{}
+	err = testutil.List(k8sClient, jobs)
+	require.NoError(t, err)
+	require.Len(t, jobs.Items, 1)
+	require.Equal(t, k8sNode2.Name, jobs.Items[0].Spec.Template.Spec.NodeName)
+
+	// The preflight job and storage node should be removed if the node does
+	// not need a storage pod anymore
+	err = k8sClient.Delete(context.TODO(), k8sNode2)
+	require.NoError(t, err)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	jobs = &batchv1.JobList{}
+	err = testutil.List(k8sClient, jobs)
+	require.NoError(t, err)
+	require.Empty(t, jobs.Items)
+
+	storageNodes := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, k8sNode1.Name, storageNodes.Items[0].Name)
+}
+
+func TestPreflightJobNameWithLongNames(t *testing.T) {
+	longName := strings.Repeat("ab.c-", 50)
+	nodeName := "node." + longName
+
+	names := []string{
+		preflightJobName("px-cluster", "k8s-node"),
+		preflightJobName(longName, nodeName),
+		preflightJobName(longName+"-other", nodeName),
+		preflightJobName(longName, nodeName+"-other"),
+	}
+	for _, name := range names {
+		require.Empty(t, validation.IsValidLabelValue(name), name)
+		require.Empty(t, validation.IsDNS1123Subdomain(name), name)
+	}
+	require.True(t, strings.HasPrefix(names[0], "px-cluster-preflight-"))
+	require.True(t, strings.HasPrefix(names[1], strings.Repeat("ab.c-", 5)))
+
+	// Truncated names should still be unique per cluster and node
+	require.NotEqual(t, names[1], names[2])
+	require.NotEqual(t, names[1], names[3])
+	require.Equal(t, names[1], preflightJobName(longName, nodeName))
+}
+
+func TestNodeDecommission(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Spec.Placement = &corev1alpha1.PlacementSpec{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{
+								Key:      "storage",
+								Operator: v1.NodeSelectorOpIn,
+								Values:   []string{"true"},
+							},
+						},
 					},
 				},
 			},
 		},
-		{
-			// Duplicate selector with same node name. If the node has already
-			// matched a previous spec, then this spec will not be used by that node.
-			Selector: corev1alpha1.NodeSelector{
-				NodeName: "k8s-node-1",
+	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+
+	// k8s-node-1 is annotated for decommission, while k8s-node-2 is
+	// removed from the placement of the cluster
+	k8sNode1 := createK8sNode("k8s-node-1", 10)
+	k8sNode1.Labels = map[string]string{"storage": "true"}
+	k8sNode2 := createK8sNode("k8s-node-2", 10)
+	storagePod1 := createStoragePod(cluster, "storage-pod-1", k8sNode1.Name, storageLabels)
+	storagePod2 := createStoragePod(cluster, "storage-pod-2", k8sNode2.Name, storageLabels)
+	storageNode1 := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            k8sNode1.Name,
+			Namespace:       cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*clusterRef},
+			Annotations: map[string]string{
+				AnnotationDecommission: "true",
 			},
-			CommonConfig: corev1alpha1.CommonConfig{
-				Storage: &corev1alpha1.StorageSpec{
-					Devices: stringSlicePtr([]string{"unused"}),
-				},
+		},
+		Status: corev1alpha1.NodeStatus{
+			NodeUID: "node-uid-1",
+		},
+	}
+	storageNode2 := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            k8sNode2.Name,
+			Namespace:       cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*clusterRef},
+		},
+		Status: corev1alpha1.NodeStatus{
+			NodeUID: "node-uid-2",
+		},
+	}
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+	k8sClient.Create(context.TODO(), storagePod1)
+	k8sClient.Create(context.TODO(), storagePod2)
+	k8sClient.Create(context.TODO(), storageNode1)
+	k8sClient.Create(context.TODO(), storageNode2)
+
+	getDecommissionStatus := func(name string) corev1alpha1.NodeConditionStatus {
+		storageNode := &corev1alpha1.StorageNode{}
+		err := testutil.Get(k8sClient, storageNode, name, cluster.Namespace)
+		require.NoError(t, err)
+		if condition := getDecommissionCondition(storageNode); condition != nil {
+			return condition.Status
+		}
+		return ""
+	}
+	setDecommissionStatus := func(name string, status corev1alpha1.NodeConditionStatus) {
+		storageNode := &corev1alpha1.StorageNode{}
+		err := testutil.Get(k8sClient, storageNode, name, cluster.Namespace)
+		require.NoError(t, err)
+		storageNode.Status.Conditions = []corev1alpha1.NodeCondition{
+			{
+				Type:   corev1alpha1.NodeDecommissionCondition,
+				Status: status,
 			},
+		}
+		err = k8sClient.Status().Update(context.TODO(), storageNode)
+		require.NoError(t, err)
+	}
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+
+	// TestCase: Decommission should be requested for both the nodes. The storage
+	// pod on the annotated node should keep running until the node is drained.
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	require.Equal(t, corev1alpha1.NodeInitStatus, getDecommissionStatus(k8sNode1.Name))
+	require.Equal(t, corev1alpha1.NodeInitStatus, getDecommissionStatus(k8sNode2.Name))
+	require.Empty(t, podControl.Templates)
+	require.ElementsMatch(t, []string{storagePod2.Name}, podControl.DeletePodName)
+
+	// TestCase: Storage pod should be removed once the node is being removed
+	// from the storage cluster
+	setDecommissionStatus(k8sNode1.Name, corev1alpha1.NodeRemovingStatus)
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	require.Empty(t, podControl.Templates)
+	require.ElementsMatch(t, []string{storagePod1.Name, storagePod2.Name}, podControl.DeletePodName)
+
+	// TestCase: Decommission should be cancelled if the node is added back to
+	// the placement before it is drained
+	k8sNode2.Labels = map[string]string{"storage": "true"}
+	err = k8sClient.Update(context.TODO(), k8sNode2)
+	require.NoError(t, err)
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	require.Empty(t, getDecommissionStatus(k8sNode2.Name))
+	require.Equal(t, corev1alpha1.NodeRemovingStatus, getDecommissionStatus(k8sNode1.Name))
+	require.ElementsMatch(t, []string{storagePod1.Name}, podControl.DeletePodName)
+
+	// TestCase: Decommission should not be cancelled once the node is being
+	// removed from the storage cluster
+	storageNode := &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, storageNode, k8sNode1.Name, cluster.Namespace)
+	require.NoError(t, err)
+	storageNode.Annotations = nil
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	require.Equal(t, corev1alpha1.NodeRemovingStatus, getDecommissionStatus(k8sNode1.Name))
+
+	// TestCase: StorageNode of a decommissioned node should be removed once the
+	// decommission is not requested anymore, so the node can join again
+	setDecommissionStatus(k8sNode1.Name, corev1alpha1.NodeDecommissionedStatus)
+	err = k8sClient.Delete(context.TODO(), storagePod1)
+	require.NoError(t, err)
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	storageNodes := &corev1alpha1.StorageNodeList{}
+	err = testutil.List(k8sClient, storageNodes)
+	require.NoError(t, err)
+	require.Len(t, storageNodes.Items, 1)
+	require.Equal(t, k8sNode2.Name, storageNodes.Items[0].Name)
+	require.Empty(t, podControl.DeletePodName)
+}
+
+func TestNodeDecommissionNotRequestedForTaintedNodes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Spec.Placement = &corev1alpha1.PlacementSpec{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{
+								Key:      "storage",
+								Operator: v1.NodeSelectorOpIn,
+								Values:   []string{"true"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+
+	// The node is in the placement of the cluster, but has a taint that the
+	// storage pods do not tolerate
+	k8sNode := createK8sNode("k8s-node-1", 10)
+	k8sNode.Labels = map[string]string{"storage": "true"}
+	k8sNode.Spec.Taints = []v1.Taint{
+		{
+			Key:    "maintenance",
+			Value:  "true",
+			Effect: v1.TaintEffectNoSchedule,
+		},
+	}
+	storagePod := createStoragePod(cluster, "storage-pod-1", k8sNode.Name, storageLabels)
+	storageNode := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            k8sNode.Name,
+			Namespace:       cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*clusterRef},
+		},
+		Status: corev1alpha1.NodeStatus{
+			NodeUID: "node-uid-1",
+		},
+	}
+	k8sClient.Create(context.TODO(), k8sNode)
+	k8sClient.Create(context.TODO(), storagePod)
+	k8sClient.Create(context.TODO(), storageNode)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+
+	// TestCase: Decommission should not be requested for the tainted node
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	actualStorageNode := &corev1alpha1.StorageNode{}
+	err = testutil.Get(k8sClient, actualStorageNode, k8sNode.Name, cluster.Namespace)
+	require.NoError(t, err)
+	require.Nil(t, getDecommissionCondition(actualStorageNode))
+	require.Empty(t, podControl.DeletePodName)
+}
+
+func TestNodeDecommissionOnStorageNodeDelete(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+
+	// k8s-node-1 is part of the storage cluster, while k8s-node-2 has
+	// not joined the storage cluster yet
+	k8sNode1 := createK8sNode("k8s-node-1", 10)
+	k8sNode2 := createK8sNode("k8s-node-2", 10)
+	storagePod1 := createStoragePod(cluster, "storage-pod-1", k8sNode1.Name, storageLabels)
+	storagePod2 := createStoragePod(cluster, "storage-pod-2", k8sNode2.Name, storageLabels)
+	storageNode1 := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            k8sNode1.Name,
+			Namespace:       cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*clusterRef},
+		},
+		Status: corev1alpha1.NodeStatus{
+			NodeUID: "node-uid-1",
+		},
+	}
+	storageNode2 := &corev1alpha1.StorageNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            k8sNode2.Name,
+			Namespace:       cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*clusterRef},
+		},
+	}
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+	k8sClient.Create(context.TODO(), storagePod1)
+	k8sClient.Create(context.TODO(), storagePod2)
+	k8sClient.Create(context.TODO(), storageNode1)
+	k8sClient.Create(context.TODO(), storageNode2)
+
+	getStorageNode := func(name string) *corev1alpha1.StorageNode {
+		storageNode := &corev1alpha1.StorageNode{}
+		err := testutil.Get(k8sClient, storageNode, name, cluster.Namespace)
+		require.NoError(t, err)
+		return storageNode
+	}
+	deleteStorageNode := func(name string) {
+		storageNode := getStorageNode(name)
+		deletionTimestamp := metav1.Now()
+		storageNode.DeletionTimestamp = &deletionTimestamp
+		err := k8sClient.Update(context.TODO(), storageNode)
+		require.NoError(t, err)
+	}
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+
+	// TestCase: Only the StorageNode of the node that is part of the storage
+	// cluster should get the finalizer
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	require.Equal(t, []string{StorageNodeFinalizer}, getStorageNode(k8sNode1.Name).Finalizers)
+	require.Empty(t, getStorageNode(k8sNode2.Name).Finalizers)
+
+	// TestCase: Deleting the StorageNode should request the decommission of
+	// the node, keeping the StorageNode until the node is decommissioned
+	deleteStorageNode(k8sNode1.Name)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	storageNode := getStorageNode(k8sNode1.Name)
+	require.Equal(t, []string{StorageNodeFinalizer}, storageNode.Finalizers)
+	condition := getDecommissionCondition(storageNode)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.NodeInitStatus, condition.Status)
+
+	// TestCase: Finalizer should be removed once the node is decommissioned
+	storageNode.Status.Conditions = []corev1alpha1.NodeCondition{
+		{
+			Type:   corev1alpha1.NodeDecommissionCondition,
+			Status: corev1alpha1.NodeDecommissionedStatus,
+		},
+	}
+	err = k8sClient.Status().Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	require.Empty(t, getStorageNode(k8sNode1.Name).Finalizers)
+
+	// TestCase: Finalizer should be removed without a decommission if the
+	// node has not joined the storage cluster
+	storageNode = getStorageNode(k8sNode2.Name)
+	storageNode.Finalizers = []string{StorageNodeFinalizer}
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+	deleteStorageNode(k8sNode2.Name)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	storageNode = getStorageNode(k8sNode2.Name)
+	require.Empty(t, storageNode.Finalizers)
+	require.Nil(t, getDecommissionCondition(storageNode))
+
+	// TestCase: Finalizers should be removed when the cluster is deleted,
+	// without decommissioning the nodes
+	storageNode = getStorageNode(k8sNode1.Name)
+	storageNode.DeletionTimestamp = nil
+	storageNode.Finalizers = []string{StorageNodeFinalizer}
+	err = k8sClient.Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+	storageNode.Status.Conditions = nil
+	err = k8sClient.Status().Update(context.TODO(), storageNode)
+	require.NoError(t, err)
+
+	err = testutil.Get(k8sClient, cluster, cluster.Name, cluster.Namespace)
+	require.NoError(t, err)
+	deletionTimestamp := metav1.Now()
+	cluster.DeletionTimestamp = &deletionTimestamp
+	cluster.Finalizers = nil
+	err = k8sClient.Update(context.TODO(), cluster)
+	require.NoError(t, err)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	storageNode = getStorageNode(k8sNode1.Name)
+	require.Empty(t, storageNode.Finalizers)
+	require.Nil(t, getDecommissionCondition(storageNode))
+}
+
+func TestStoragePodGetsScheduledWithCustomNodeSpecs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	useAllDevices := true
+	cluster.Spec.Storage = &corev1alpha1.StorageSpec{
+		UseAll: &useAllDevices,
+	}
+	cluster.Spec.Network = &corev1alpha1.NetworkSpec{
+		DataInterface: stringPtr("cluster_data_intf"),
+		MgmtInterface: stringPtr("cluster_mgmt_intf"),
+	}
+	cluster.Spec.Env = []v1.EnvVar{
+		{
+			Name:  "ENV_CLUSTER",
+			Value: "cluster_value",
+		},
+		{
+			Name:  "ENV_OVERRIDE",
+			Value: "override_cluster_value",
+		},
+	}
+	cluster.Spec.RuntimeOpts = map[string]string{
+		"cluster_rt_one": "rt_val_1",
+	}
+	cluster.Spec.Image = "image/cluster:1"
+	cluster.Spec.Placement = &corev1alpha1.PlacementSpec{
+		Tolerations: []v1.Toleration{
+			{
+				Key:      "cluster_taint",
+				Operator: v1.TolerationOpExists,
+			},
+			{
+				Key:      "common_taint",
+				Operator: v1.TolerationOpEqual,
+				Value:    "common_value",
+			},
+		},
+	}
+	cluster.Spec.CloudStorage = &corev1alpha1.CloudStorageSpec{
+		DeviceSpecs:       stringSlicePtr([]string{"type=cluster"}),
+		JournalDeviceSpec: stringPtr("type=cluster_journal"),
+	}
+	cluster.Spec.Resources = &v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}
+	cluster.Spec.Nodes = []corev1alpha1.NodeSpec{
+		{
+			// Match using node name
+			Selector: corev1alpha1.NodeSelector{
+				NodeName: "k8s-node-1",
+			},
+			Image: "image/node:1",
+			Tolerations: []v1.Toleration{
+				{
+					// Same toleration as the cluster level should not be duplicated
+					Key:      "common_taint",
+					Operator: v1.TolerationOpEqual,
+					Value:    "common_value",
+				},
+				{
+					Key:      "node_taint",
+					Operator: v1.TolerationOpExists,
+				},
+			},
+			CloudStorage: &corev1alpha1.CloudStorageNodeSpec{
+				CapacitySpecs: []corev1alpha1.CloudStorageCapacitySpec{
+					{MinCapacityInGiB: 100},
+				},
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Resources: &v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+				},
+				Storage: &corev1alpha1.StorageSpec{
+					Devices: stringSlicePtr([]string{"dev1"}),
+				},
+				Network: &corev1alpha1.NetworkSpec{
+					DataInterface: stringPtr("dface"),
+					MgmtInterface: stringPtr("mface"),
+				},
+				Env: []v1.EnvVar{
+					{
+						Name:  "ENV_NODE",
+						Value: "node_value",
+					},
+					{
+						Name:  "ENV_OVERRIDE",
+						Value: "override_node_value",
+					},
+				},
+				RuntimeOpts: map[string]string{
+					"rt_one": "rt_val_1",
+					"rt_two": "rt_val_2",
+				},
+			},
+		},
+		{
+			// Match using a label selector
+			Selector: corev1alpha1.NodeSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "node2",
+					},
+				},
+			},
+		},
+		{
+			// Duplicate selector with same node name. If the node has already
+			// matched a previous spec, then this spec will not be used by that node.
+			Selector: corev1alpha1.NodeSelector{
+				NodeName: "k8s-node-1",
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Storage: &corev1alpha1.StorageSpec{
+					Devices: stringSlicePtr([]string{"unused"}),
+				},
+			},
+		},
+		{
+			// Even though the labels match a valid node, if the node has already
+			// matched a previous spec, then this spec will not be used by that node.
+			Selector: corev1alpha1.NodeSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test2": "node2",
+					},
+				},
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Storage: &corev1alpha1.StorageSpec{
+					Devices: stringSlicePtr([]string{"unused"}),
+				},
+			},
+		},
+		{
+			// Even though the node name matches a valid node, if the node has already
+			// matched a previous spec, then this spec will not be used by that node.
+			Selector: corev1alpha1.NodeSelector{
+				NodeName: "k8s-node-2",
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Storage: &corev1alpha1.StorageSpec{
+					Devices: stringSlicePtr([]string{"unused"}),
+				},
+			},
+		},
+		{
+			// Even though the labels match a valid node, if the node has already
+			// matched a previous spec, then this spec will not be used by that node.
+			Selector: corev1alpha1.NodeSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "node1",
+					},
+				},
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Storage: &corev1alpha1.StorageSpec{
+					Devices: stringSlicePtr([]string{"unused"}),
+				},
+			},
+		},
+		{
+			// Selector with node name that does not exist. No pod should
+			// be deployed with this configuration
+			Selector: corev1alpha1.NodeSelector{
+				NodeName: "non-existent-node",
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Storage: &corev1alpha1.StorageSpec{
+					Devices: stringSlicePtr([]string{"unused"}),
+				},
+			},
+		},
+		{
+			// Selector with requirements that do not match any node. No pod
+			// should be deployed with this configuration
+			Selector: corev1alpha1.NodeSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "not-matching-label",
+					},
+				},
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Storage: &corev1alpha1.StorageSpec{
+					Devices: stringSlicePtr([]string{"unused"}),
+				},
+			},
+		},
+		{
+			// Selector with invalid requirements. No pod should be
+			// deployed with this configuration
+			Selector: corev1alpha1.NodeSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{
+							Key:      "test",
+							Operator: "InvalidOperator",
+						},
+					},
+				},
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Storage: &corev1alpha1.StorageSpec{
+					Devices: stringSlicePtr([]string{"unused"}),
+				},
+			},
+		},
+	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
+
+	// Kubernetes node with resources to create a pod
+	k8sNode1 := createK8sNode("k8s-node-1", 1)
+	k8sNode1.Labels = map[string]string{
+		"test": "node1",
+	}
+	k8sNode2 := createK8sNode("k8s-node-2", 1)
+	k8sNode2.Labels = map[string]string{
+		"test":  "node2",
+		"test2": "node2",
+	}
+	k8sNode3 := createK8sNode("k8s-node-3", 1)
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster, k8sNode1, k8sNode2, k8sNode3)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	expectedPodSpec := v1.PodSpec{
+		Containers: []v1.Container{{Name: "test"}},
+	}
+	addOrUpdateStoragePodTolerations(&expectedPodSpec)
+	expectedPodTemplate := &v1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Labels: map[string]string{
+				labelKeyName:       cluster.Name,
+				labelKeyDriverName: driverName,
+			},
+		},
+		Spec: expectedPodSpec,
+	}
+	expectedPodTemplates := []v1.PodTemplateSpec{
+		*expectedPodTemplate,
+		*expectedPodTemplate.DeepCopy(),
+		*expectedPodTemplate.DeepCopy(),
+	}
+
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).
+		Do(func(c *corev1alpha1.StorageCluster) {
+			hash := computeHash(&c.Spec, "", nil)
+			expectedPodTemplates[0].Labels[defaultStorageClusterUniqueLabelKey] = hash
+			expectedPodTemplates[1].Labels[defaultStorageClusterUniqueLabelKey] = hash
+			expectedPodTemplates[2].Labels[defaultStorageClusterUniqueLabelKey] = hash
+		})
+	gomock.InOrder(
+		driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-1").
+			DoAndReturn(func(c *corev1alpha1.StorageCluster, _ string) (v1.PodSpec, error) {
+				require.Equal(t, cluster.Spec.Nodes[0].Storage, c.Spec.Storage)
+				require.Equal(t, cluster.Spec.Nodes[0].Network, c.Spec.Network)
+				require.Equal(t, cluster.Spec.Nodes[0].RuntimeOpts, c.Spec.RuntimeOpts)
+				require.Equal(t, cluster.Spec.Nodes[0].Image, c.Spec.Image)
+				require.Equal(t, cluster.Spec.Nodes[0].Resources, c.Spec.Resources)
+				expectedTolerations := []v1.Toleration{
+					cluster.Spec.Placement.Tolerations[0],
+					cluster.Spec.Placement.Tolerations[1],
+					cluster.Spec.Nodes[0].Tolerations[1],
+				}
+				require.Equal(t, expectedTolerations, c.Spec.Placement.Tolerations)
+				expectedCloudStorage := &corev1alpha1.CloudStorageSpec{
+					CapacitySpecs:     cluster.Spec.Nodes[0].CloudStorage.CapacitySpecs,
+					JournalDeviceSpec: cluster.Spec.CloudStorage.JournalDeviceSpec,
+				}
+				require.Equal(t, expectedCloudStorage, c.Spec.CloudStorage)
+				expectedEnv := []v1.EnvVar{
+					{
+						Name:  "ENV_CLUSTER",
+						Value: "cluster_value",
+					},
+					{
+						Name:  "ENV_OVERRIDE",
+						Value: "override_node_value",
+					},
+					{
+						Name:  "ENV_NODE",
+						Value: "node_value",
+					},
+				}
+				require.ElementsMatch(t, expectedEnv, c.Spec.Env)
+				nodeLabels, _ := json.Marshal(k8sNode1.Labels)
+				expectedPodTemplates[0].Annotations = map[string]string{
+					annotationNodeLabels:    string(nodeLabels),
+					annotationNodeGroupHash: computeNodeGroupHash(&c.Spec),
+				}
+				return expectedPodSpec, nil
+			}).
+			Times(1),
+		driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-2").
+			DoAndReturn(func(c *corev1alpha1.StorageCluster, _ string) (v1.PodSpec, error) {
+				require.Empty(t, cluster.Spec.Nodes[1].CommonConfig, c.Spec.CommonConfig)
+				nodeLabels, _ := json.Marshal(k8sNode2.Labels)
+				expectedPodTemplates[1].Annotations = map[string]string{
+					annotationNodeLabels:    string(nodeLabels),
+					annotationNodeGroupHash: computeNodeGroupHash(&c.Spec),
+				}
+				return expectedPodSpec, nil
+			}).
+			Times(1),
+		driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-3").
+			DoAndReturn(func(c *corev1alpha1.StorageCluster, _ string) (v1.PodSpec, error) {
+				require.Equal(t, cluster.Spec.CommonConfig, c.Spec.CommonConfig)
+				expectedPodTemplates[2].Annotations = map[string]string{
+					annotationNodeGroupHash: computeNodeGroupHash(&c.Spec),
+				}
+				return expectedPodSpec, nil
+			}).
+			Times(1),
+	)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// Verify there is no event raised
+	require.Empty(t, recorder.Events)
+
+	// Verify there is one revision for the new StorageCluster object
+	revisions := &appsv1.ControllerRevisionList{}
+	err = testutil.List(k8sClient, revisions)
+	require.NoError(t, err)
+	require.Len(t, revisions.Items, 1)
+
+	// Verify a pod is created for the given node with correct owner ref
+	require.Len(t, podControl.Templates, 3)
+	require.ElementsMatch(t, expectedPodTemplates, podControl.Templates)
+	require.Len(t, podControl.ControllerRefs, 3)
+	require.Equal(t, *clusterRef, podControl.ControllerRefs[0])
+	require.Equal(t, *clusterRef, podControl.ControllerRefs[1])
+	require.Equal(t, *clusterRef, podControl.ControllerRefs[2])
+}
+
+func TestStoragePodsInNodeGroupGetNodeSpecificPodSpecs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Spec.Nodes = []corev1alpha1.NodeSpec{
+		{
+			Selector: corev1alpha1.NodeSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"group": "storage",
+					},
+				},
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Storage: &corev1alpha1.StorageSpec{
+					Devices: stringSlicePtr([]string{"dev1"}),
+				},
+			},
+		},
+	}
+
+	// Kubernetes nodes in the same node group
+	k8sNode1 := createK8sNode("k8s-node-1", 1)
+	k8sNode1.Labels = map[string]string{"group": "storage"}
+	k8sNode2 := createK8sNode("k8s-node-2", 1)
+	k8sNode2.Labels = map[string]string{"group": "storage"}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster, k8sNode1, k8sNode2)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	// The driver returns a pod spec that is specific to the node
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ *corev1alpha1.StorageCluster, nodeName string) (v1.PodSpec, error) {
+			return v1.PodSpec{
+				Volumes: []v1.Volume{
+					{
+						Name: "node-secret",
+						VolumeSource: v1.VolumeSource{
+							Secret: &v1.SecretVolumeSource{
+								SecretName: "secret-" + nodeName,
+							},
+						},
+					},
+				},
+			}, nil
+		}).
+		Times(2)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// Verify each pod gets the pod spec created for its own node
+	require.Len(t, podControl.Templates, 2)
+	secretNames := []string{
+		podControl.Templates[0].Spec.Volumes[0].Secret.SecretName,
+		podControl.Templates[1].Spec.Volumes[0].Secret.SecretName,
+	}
+	require.ElementsMatch(t, []string{"secret-k8s-node-1", "secret-k8s-node-2"}, secretNames)
+}
+
+func TestFailedStoragePodsGetRemoved(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes nodes with enough resources to create new pods
+	k8sNode1 := createK8sNode("k8s-node-1", 10)
+	k8sNode2 := createK8sNode("k8s-node-2", 10)
+	k8sNode3 := createK8sNode("k8s-node-3", 10)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+
+	// Running pod should not get deleted if nothing has changed
+	runningPod := createStoragePod(cluster, "running-pod", k8sNode1.Name, storageLabels)
+
+	// Failed pod should be deleted on reconcile
+	failedPod := createStoragePod(cluster, "failed-pod", k8sNode2.Name, storageLabels)
+	failedPod.Status = v1.PodStatus{
+		Phase: v1.PodFailed,
+	}
+
+	// Deleted pod should not be deleted again
+	deletedPod := createStoragePod(cluster, "deleted-pod", k8sNode3.Name, storageLabels)
+	deletionTimestamp := metav1.Now()
+	deletedPod.DeletionTimestamp = &deletionTimestamp
+
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+	k8sClient.Create(context.TODO(), k8sNode3)
+	k8sClient.Create(context.TODO(), runningPod)
+	k8sClient.Create(context.TODO(), failedPod)
+	k8sClient.Create(context.TODO(), deletedPod)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// Verify there is event raised for the failed pod
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedStoragePodReason))
+
+	// Verify no pod is created on first node, failed pod is deleted,
+	// and already deleted pod is not deleted again
+	require.Empty(t, podControl.Templates)
+	require.Len(t, podControl.DeletePodName, 1)
+	require.Equal(t, failedPod.Name, podControl.DeletePodName[0])
+}
+
+func TestExtraStoragePodsGetRemoved(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes node with enough resources to create new pods
+	k8sNode := createK8sNode("k8s-node", 10)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	affinity := &v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchFields: []v1.NodeSelectorRequirement{
+							{
+								Key:      schedulerapi.NodeFieldSelectorKeyNodeName,
+								Operator: v1.NodeSelectorOpIn,
+								Values:   []string{k8sNode.Name},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// If multiple pods are running on a single node, only one with earliest
+	// timestamp and which is scheduled should be retained. If spec.NodeName
+	// is set, it is assumed that the pod is scheduled.
+	unscheduledPod1 := createStoragePod(cluster, "unscheduled-pod-1", "", storageLabels)
+	unscheduledPod1.CreationTimestamp = metav1.Now()
+	unscheduledPod1.Spec.Affinity = affinity
+
+	creationTimestamp := metav1.NewTime(time.Now().Add(1 * time.Minute))
+	runningPod1 := createStoragePod(cluster, "running-pod-1", k8sNode.Name, storageLabels)
+	runningPod1.CreationTimestamp = creationTimestamp
+	runningPod2 := createStoragePod(cluster, "running-pod-2", k8sNode.Name, storageLabels)
+	runningPod2.CreationTimestamp = creationTimestamp
+
+	unscheduledPod2 := createStoragePod(cluster, "unscheduled-pod-2", "", storageLabels)
+	unscheduledPod2.CreationTimestamp = metav1.NewTime(time.Now().Add(2 * time.Minute))
+	unscheduledPod2.Spec.Affinity = affinity
+
+	extraRunningPod := createStoragePod(cluster, "extra-running-pod", k8sNode.Name, storageLabels)
+	extraRunningPod.CreationTimestamp = metav1.NewTime(time.Now().Add(3 * time.Minute))
+
+	k8sClient.Create(context.TODO(), k8sNode)
+	k8sClient.Create(context.TODO(), unscheduledPod1)
+	k8sClient.Create(context.TODO(), runningPod1)
+	k8sClient.Create(context.TODO(), runningPod2)
+	k8sClient.Create(context.TODO(), unscheduledPod2)
+	k8sClient.Create(context.TODO(), extraRunningPod)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// Verify there is no event raised for the extra pods
+	require.Empty(t, recorder.Events)
+
+	require.Empty(t, podControl.Templates)
+	require.ElementsMatch(t,
+		[]string{runningPod2.Name, unscheduledPod1.Name, unscheduledPod2.Name, extraRunningPod.Name},
+		podControl.DeletePodName,
+	)
+}
+
+func TestStoragePodsAreRemovedIfDisabled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Annotations = map[string]string{
+		AnnotationDisableStorage: "1",
+	}
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes node with enough resources to create new pods
+	k8sNode := createK8sNode("k8s-node", 10)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	runningPod := createStoragePod(cluster, "running-pod", k8sNode.Name, storageLabels)
+
+	k8sClient.Create(context.TODO(), k8sNode)
+	k8sClient.Create(context.TODO(), runningPod)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// Verify there is no event raised for the extra pods
+	require.Empty(t, recorder.Events)
+
+	require.Empty(t, podControl.Templates)
+	require.ElementsMatch(t, []string{runningPod.Name}, podControl.DeletePodName)
+}
+
+func TestStoragePodFailureDueToInsufficientResources(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes nodes without enough resources to create a pod
+	k8sNode1 := createK8sNode("k8s-node-1", 0)
+	k8sNode2 := createK8sNode("k8s-node-2", 0)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	runningPod := createStoragePod(cluster, "running-pod", k8sNode2.Name, storageLabels)
+
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+	k8sClient.Create(context.TODO(), runningPod)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// Verify event is raised if failed to place pod
+	// Two events per pod are raised as we simulate every pod on the node twice
+	require.Len(t, recorder.Events, 4)
+	expectedEvent := fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedPlacementReason)
+	require.Contains(t, <-recorder.Events, expectedEvent)
+	require.Contains(t, <-recorder.Events, expectedEvent)
+	require.Contains(t, <-recorder.Events, expectedEvent)
+	require.Contains(t, <-recorder.Events, expectedEvent)
+
+	// Verify there is one revision for the new StorageCluster object
+	revisions := &appsv1.ControllerRevisionList{}
+	err = testutil.List(k8sClient, revisions)
+	require.NoError(t, err)
+	require.Len(t, revisions.Items, 1)
+
+	// Verify no pod is created due to insufficient resources.
+	// Existing pods are not removed from nodes without resources.
+	require.Empty(t, podControl.Templates)
+	require.Empty(t, podControl.DeletePodName)
+}
+
+func TestStoragePodFailureDueToNodeSelectorNotMatch(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Spec.Placement = &corev1alpha1.PlacementSpec{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchFields: []v1.NodeSelectorRequirement{
+							{
+								Key:      schedulerapi.NodeFieldSelectorKeyNodeName,
+								Operator: v1.NodeSelectorOpNotIn,
+								Values:   []string{"k8s-node-1", "k8s-node-2"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes node with enough resources to create new pods
+	k8sNode1 := createK8sNode("k8s-node-1", 10)
+	k8sNode1.Labels = map[string]string{
+		"test": "node1",
+	}
+	k8sNode2 := createK8sNode("k8s-node-2", 10)
+	k8sNode2.Labels = map[string]string{
+		"test": "node2",
+	}
+	k8sNode3 := createK8sNode("k8s-node-3", 10)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	runningPod := createStoragePod(cluster, "running-pod", k8sNode3.Name, storageLabels)
+
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+	k8sClient.Create(context.TODO(), k8sNode3)
+	k8sClient.Create(context.TODO(), runningPod)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// No need to raise events if node selectors don't match for a node
+	// Verify no pod is created due to node selector mismatch. Also remove any
+	// running pod if the selectors don't match.
+	require.Empty(t, recorder.Events)
+	require.Empty(t, podControl.Templates)
+	require.ElementsMatch(t, []string{runningPod.Name}, podControl.DeletePodName)
+}
+
+func TestStoragePodSchedulingWithTolerations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Spec.Placement = &corev1alpha1.PlacementSpec{
+		Tolerations: []v1.Toleration{
+			{
+				Key:      "must-exist",
+				Operator: v1.TolerationOpExists,
+				Effect:   v1.TaintEffectNoExecute,
+			},
+			{
+				Key:      "foo",
+				Operator: v1.TolerationOpEqual,
+				Value:    "bar",
+				Effect:   v1.TaintEffectNoSchedule,
+			},
+		},
+	}
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).Times(3)
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).Times(3)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).Times(3)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).Times(3)
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes node with enough resources to create new pods
+	k8sNode1 := createK8sNode("k8s-node-1", 10)
+	k8sNode1.Spec.Taints = []v1.Taint{
+		{
+			Key:    "must-exist",
+			Value:  "anything",
+			Effect: v1.TaintEffectNoExecute,
 		},
 		{
-			// Even though the labels match a valid node, if the node has already
-			// matched a previous spec, then this spec will not be used by that node.
-			Selector: corev1alpha1.NodeSelector{
-				LabelSelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{
-						"test2": "node2",
-					},
-				},
-			},
-			CommonConfig: corev1alpha1.CommonConfig{
-				Storage: &corev1alpha1.StorageSpec{
-					Devices: stringSlicePtr([]string{"unused"}),
-				},
-			},
+			Key:    "foo",
+			Value:  "bar",
+			Effect: v1.TaintEffectNoSchedule,
 		},
+	}
+	k8sNode2 := createK8sNode("k8s-node-2", 10)
+	k8sNode2.Spec.Taints = []v1.Taint{
 		{
-			// Even though the node name matches a valid node, if the node has already
-			// matched a previous spec, then this spec will not be used by that node.
-			Selector: corev1alpha1.NodeSelector{
-				NodeName: "k8s-node-2",
-			},
-			CommonConfig: corev1alpha1.CommonConfig{
-				Storage: &corev1alpha1.StorageSpec{
-					Devices: stringSlicePtr([]string{"unused"}),
-				},
-			},
+			Key:    "foo",
+			Value:  "bar",
+			Effect: v1.TaintEffectNoSchedule,
+		},
+	}
+	k8sNode3 := createK8sNode("k8s-node-3", 10)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	runningPod1 := createStoragePod(cluster, "running-pod-1", k8sNode1.Name, storageLabels)
+	runningPod2 := createStoragePod(cluster, "running-pod-2", k8sNode2.Name, storageLabels)
+	runningPod3 := createStoragePod(cluster, "running-pod-3", k8sNode3.Name, storageLabels)
+
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+	k8sClient.Create(context.TODO(), k8sNode3)
+	k8sClient.Create(context.TODO(), runningPod1)
+	k8sClient.Create(context.TODO(), runningPod2)
+	k8sClient.Create(context.TODO(), runningPod3)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
 		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// No pods should be deleted as they have tolerations for the node taints
+	require.Empty(t, recorder.Events)
+	require.Empty(t, podControl.Templates)
+	require.Empty(t, podControl.DeletePodName)
+
+	// Case: Remove tolerations and the pods on nodes with NoExecute should be removed.
+	// Pods on nodes with NoSchedule should NOT be removed
+	cluster.Spec.Placement = nil
+	k8sClient.Update(context.TODO(), cluster)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	require.Empty(t, recorder.Events)
+	require.Empty(t, podControl.Templates)
+	require.ElementsMatch(t, []string{runningPod1.Name}, podControl.DeletePodName)
+
+	// Case: Delete a pod lacking NoSchedule toleration and it should not be started again
+	k8sClient.Delete(context.TODO(), runningPod2)
+	k8sClient.Delete(context.TODO(), runningPod1)
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	require.Empty(t, recorder.Events)
+	require.Empty(t, podControl.Templates)
+	require.Empty(t, podControl.DeletePodName)
+}
+
+func TestStoragePodSchedulingWithNodeGroupTolerations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Spec.Nodes = []corev1alpha1.NodeSpec{
 		{
-			// Even though the labels match a valid node, if the node has already
-			// matched a previous spec, then this spec will not be used by that node.
 			Selector: corev1alpha1.NodeSelector{
 				LabelSelector: &metav1.LabelSelector{
 					MatchLabels: map[string]string{
-						"test": "node1",
+						"dedicated": "storage",
 					},
 				},
 			},
-			CommonConfig: corev1alpha1.CommonConfig{
-				Storage: &corev1alpha1.StorageSpec{
-					Devices: stringSlicePtr([]string{"unused"}),
+			Tolerations: []v1.Toleration{
+				{
+					Key:      "dedicated",
+					Operator: v1.TolerationOpEqual,
+					Value:    "storage",
+					Effect:   v1.TaintEffectNoSchedule,
 				},
 			},
 		},
+	}
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+
+	// Both nodes are tainted, but only the first node belongs to the
+	// node group that tolerates the taint
+	taints := []v1.Taint{
 		{
-			// Selector with node name that does not exist. No pod should
-			// be deployed with this configuration
-			Selector: corev1alpha1.NodeSelector{
-				NodeName: "non-existent-node",
-			},
-			CommonConfig: corev1alpha1.CommonConfig{
-				Storage: &corev1alpha1.StorageSpec{
-					Devices: stringSlicePtr([]string{"unused"}),
-				},
-			},
+			Key:    "dedicated",
+			Value:  "storage",
+			Effect: v1.TaintEffectNoSchedule,
 		},
-		{
-			// Selector with requirements that do not match any node. No pod
-			// should be deployed with this configuration
-			Selector: corev1alpha1.NodeSelector{
-				LabelSelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{
-						"test": "not-matching-label",
-					},
-				},
-			},
-			CommonConfig: corev1alpha1.CommonConfig{
-				Storage: &corev1alpha1.StorageSpec{
-					Devices: stringSlicePtr([]string{"unused"}),
-				},
-			},
+	}
+	k8sNode1 := createK8sNode("k8s-node-1", 10)
+	k8sNode1.Labels = map[string]string{"dedicated": "storage"}
+	k8sNode1.Spec.Taints = taints
+	k8sNode2 := createK8sNode("k8s-node-2", 10)
+	k8sNode2.Spec.Taints = taints
+
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
 		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// A storage pod should be created only on the node of the node group
+	require.Empty(t, recorder.Events)
+	require.Len(t, podControl.Templates, 1)
+	nodeLabels, _ := json.Marshal(k8sNode1.Labels)
+	require.Equal(t, string(nodeLabels), podControl.Templates[0].Annotations[annotationNodeLabels])
+}
+
+func TestFailureDuringPodTemplateCreation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	useAllDevices := true
+	cluster.Spec.Storage = &corev1alpha1.StorageSpec{
+		UseAll: &useAllDevices,
+	}
+	cluster.Spec.Nodes = []corev1alpha1.NodeSpec{
 		{
-			// Selector with invalid requirements. No pod should be
-			// deployed with this configuration
 			Selector: corev1alpha1.NodeSelector{
-				LabelSelector: &metav1.LabelSelector{
-					MatchExpressions: []metav1.LabelSelectorRequirement{
-						{
-							Key:      "test",
-							Operator: "InvalidOperator",
-						},
-					},
-				},
-			},
-			CommonConfig: corev1alpha1.CommonConfig{
-				Storage: &corev1alpha1.StorageSpec{
-					Devices: stringSlicePtr([]string{"unused"}),
-				},
+				NodeName: "k8s-node-1",
 			},
 		},
 	}
-	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
-	// Kubernetes node with resources to create a pod
-	k8sNode1 := createK8sNode("k8s-node-1", 1)
-	k8sNode1.Labels = map[string]string{
-		"test": "node1",
-	}
-	k8sNode2 := createK8sNode("k8s-node-2", 1)
-	k8sNode2.Labels = map[string]string{
-		"test":  "node2",
-		"test2": "node2",
+	// Kubernetes node with resources to create a pod
+	k8sNode1 := createK8sNode("k8s-node-1", 1)
+	k8sNode2 := createK8sNode("k8s-node-2", 1)
+
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster, k8sNode1, k8sNode2)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-1").
+		Return(v1.PodSpec{}, fmt.Errorf("pod template error for k8s-node-1")).
+		Times(1)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "pod template error for k8s-node-1")
+	require.Empty(t, result)
+
+	// Verify there is event raised for failure to create pod templates for node spec
+	require.Len(t, recorder.Events, 1)
+	eventMsg := <-recorder.Events
+	require.Contains(t, eventMsg, fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
+	require.Contains(t, eventMsg, "pod template error for k8s-node-1")
+
+	// When pod template creation passes for some and fails for others
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-1").
+		Return(v1.PodSpec{}, nil).
+		Times(1)
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-2").
+		Return(v1.PodSpec{}, fmt.Errorf("pod template error for k8s-node-2")).
+		Times(1)
+
+	result, err = controller.Reconcile(request)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "pod template error for k8s-node-2")
+	require.Empty(t, result)
+
+	// Verify there is event raised for failure to create pod templates for node spec
+	require.Len(t, recorder.Events, 1)
+	eventMsg = <-recorder.Events
+	require.Contains(t, eventMsg, fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
+	require.Contains(t, eventMsg, "pod template error for k8s-node-2")
+}
+
+func TestFailureDuringCreateDeletePods(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
 	}
-	k8sNode3 := createK8sNode("k8s-node-3", 1)
 
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
-	k8sClient := testutil.FakeK8sClient(cluster, k8sNode1, k8sNode2, k8sNode3)
-	podControl := &k8scontroller.FakePodControl{}
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{
+		Err: fmt.Errorf("pod control error"),
+	}
 	recorder := record.NewFakeRecorder(10)
 	controller := Controller{
 		client:            k8sClient,
@@ -1040,80 +3098,88 @@ func TestStoragePodGetsScheduledWithCustomNodeSpecs(t *testing.T) {
 		kubernetesVersion: k8sVersion,
 	}
 
-	expectedPodSpec := v1.PodSpec{
-		Containers: []v1.Container{{Name: "test"}},
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes node with enough resources to create new pods
+	k8sNode1 := createK8sNode("k8s-node-1", 10)
+	k8sNode2 := createK8sNode("k8s-node-2", 10)
+	k8sNode3 := createK8sNode("k8s-node-3", 10)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	failedPod := createStoragePod(cluster, "failed-pod", k8sNode1.Name, storageLabels)
+	failedPod.Status = v1.PodStatus{
+		Phase: v1.PodFailed,
 	}
-	addOrUpdateStoragePodTolerations(&expectedPodSpec)
-	expectedPodTemplate := &v1.PodTemplateSpec{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "test-ns",
-			Labels: map[string]string{
-				labelKeyName:       cluster.Name,
-				labelKeyDriverName: driverName,
-			},
+
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+	k8sClient.Create(context.TODO(), k8sNode3)
+	k8sClient.Create(context.TODO(), failedPod)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
 		},
-		Spec: expectedPodSpec,
 	}
-	expectedPodTemplates := []v1.PodTemplateSpec{
-		*expectedPodTemplate,
-		*expectedPodTemplate.DeepCopy(),
-		*expectedPodTemplate.DeepCopy(),
+	result, err := controller.Reconcile(request)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "pod control error")
+	require.Empty(t, result)
+
+	// Verify there is event raised for failure to create/delete pods
+	require.Len(t, recorder.Events, 2)
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedStoragePodReason))
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
+}
+
+func TestTimeoutFailureDuringCreatePods(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	recorder := record.NewFakeRecorder(10)
+	podControl := &k8scontroller.FakePodControl{
+		Err: errors.NewTimeoutError("timeout error", 0),
+	}
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
 	}
 
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).
-		Do(func(c *corev1alpha1.StorageCluster) {
-			hash := computeHash(&c.Spec, nil)
-			expectedPodTemplates[0].Labels[defaultStorageClusterUniqueLabelKey] = hash
-			expectedPodTemplates[1].Labels[defaultStorageClusterUniqueLabelKey] = hash
-			expectedPodTemplates[2].Labels[defaultStorageClusterUniqueLabelKey] = hash
-		})
-	gomock.InOrder(
-		driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-1").
-			DoAndReturn(func(c *corev1alpha1.StorageCluster, _ string) (v1.PodSpec, error) {
-				require.Equal(t, cluster.Spec.Nodes[0].Storage, c.Spec.Storage)
-				require.Equal(t, cluster.Spec.Nodes[0].Network, c.Spec.Network)
-				require.Equal(t, cluster.Spec.Nodes[0].RuntimeOpts, c.Spec.RuntimeOpts)
-				expectedEnv := []v1.EnvVar{
-					{
-						Name:  "ENV_CLUSTER",
-						Value: "cluster_value",
-					},
-					{
-						Name:  "ENV_OVERRIDE",
-						Value: "override_node_value",
-					},
-					{
-						Name:  "ENV_NODE",
-						Value: "node_value",
-					},
-				}
-				require.ElementsMatch(t, expectedEnv, c.Spec.Env)
-				nodeLabels, _ := json.Marshal(k8sNode1.Labels)
-				expectedPodTemplates[0].Annotations = map[string]string{annotationNodeLabels: string(nodeLabels)}
-				return expectedPodSpec, nil
-			}).
-			Times(1),
-		driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-2").
-			DoAndReturn(func(c *corev1alpha1.StorageCluster, _ string) (v1.PodSpec, error) {
-				require.Empty(t, cluster.Spec.Nodes[1].CommonConfig, c.Spec.CommonConfig)
-				nodeLabels, _ := json.Marshal(k8sNode2.Labels)
-				expectedPodTemplates[1].Annotations = map[string]string{annotationNodeLabels: string(nodeLabels)}
-				return expectedPodSpec, nil
-			}).
-			Times(1),
-		driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-3").
-			DoAndReturn(func(c *corev1alpha1.StorageCluster, _ string) (v1.PodSpec, error) {
-				require.Equal(t, cluster.Spec.CommonConfig, c.Spec.CommonConfig)
-				return expectedPodSpec, nil
-			}).
-			Times(1),
-	)
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 
+	// Kubernetes node with enough resources to create new pods
+	k8sNode := createK8sNode("k8s-node", 10)
+	k8sClient.Create(context.TODO(), k8sNode)
+
+	// Verify there is no error or event raised for timeout errors
+	// during pod creation
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      cluster.Name,
@@ -1124,35 +3190,15 @@ func TestStoragePodGetsScheduledWithCustomNodeSpecs(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, result)
 
-	// Verify there is no event raised
-	require.Empty(t, recorder.Events)
-
-	// Verify there is one revision for the new StorageCluster object
-	revisions := &appsv1.ControllerRevisionList{}
-	err = testutil.List(k8sClient, revisions)
-	require.NoError(t, err)
-	require.Len(t, revisions.Items, 1)
-
-	// Verify a pod is created for the given node with correct owner ref
-	require.Len(t, podControl.Templates, 3)
-	require.ElementsMatch(t, expectedPodTemplates, podControl.Templates)
-	require.Len(t, podControl.ControllerRefs, 3)
-	require.Equal(t, *clusterRef, podControl.ControllerRefs[0])
-	require.Equal(t, *clusterRef, podControl.ControllerRefs[1])
-	require.Equal(t, *clusterRef, podControl.ControllerRefs[2])
+	require.Len(t, recorder.Events, 0)
 }
 
-func TestFailedStoragePodsGetRemoved(t *testing.T) {
+func TestUpdateClusterStatusFromDriver(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
-	storageLabels := map[string]string{
-		labelKeyName:       cluster.Name,
-		labelKeyDriverName: driverName,
-	}
-
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
 	k8sClient := testutil.FakeK8sClient(cluster)
@@ -1166,46 +3212,67 @@ func TestFailedStoragePodsGetRemoved(t *testing.T) {
 		kubernetesVersion: k8sVersion,
 	}
 
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
 	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().
+		UpdateStorageClusterStatus(gomock.Any()).
+		Do(func(c *corev1alpha1.StorageCluster) {
+			c.Status.Phase = "Online"
+		}).
+		Return(nil)
 
-	// This will create a revision which we will map to our pre-created pods
-	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
+	require.Empty(t, result)
 
-	// Kubernetes nodes with enough resources to create new pods
-	k8sNode1 := createK8sNode("k8s-node-1", 10)
-	k8sNode2 := createK8sNode("k8s-node-2", 10)
-	k8sNode3 := createK8sNode("k8s-node-3", 10)
-
-	// Pods that are already running on the k8s nodes with same hash
-	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	require.Len(t, recorder.Events, 0)
 
-	// Running pod should not get deleted if nothing has changed
-	runningPod := createStoragePod(cluster, "running-pod", k8sNode1.Name, storageLabels)
+	newCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, newCluster, cluster.Name, cluster.Namespace)
+	require.Equal(t, "Online", newCluster.Status.Phase)
+}
 
-	// Failed pod should be deleted on reconcile
-	failedPod := createStoragePod(cluster, "failed-pod", k8sNode2.Name, storageLabels)
-	failedPod.Status = v1.PodStatus{
-		Phase: v1.PodFailed,
-	}
+func TestUpdateClusterStatusErrorFromDriver(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
 
-	// Deleted pod should not be deleted again
-	deletedPod := createStoragePod(cluster, "deleted-pod", k8sNode3.Name, storageLabels)
-	deletionTimestamp := metav1.Now()
-	deletedPod.DeletionTimestamp = &deletionTimestamp
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
 
-	k8sClient.Create(context.TODO(), k8sNode1)
-	k8sClient.Create(context.TODO(), k8sNode2)
-	k8sClient.Create(context.TODO(), k8sNode3)
-	k8sClient.Create(context.TODO(), runningPod)
-	k8sClient.Create(context.TODO(), failedPod)
-	k8sClient.Create(context.TODO(), deletedPod)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().
+		UpdateStorageClusterStatus(gomock.Any()).
+		Do(func(c *corev1alpha1.StorageCluster) {
+			c.Status.Phase = "Offline"
+		}).
+		Return(fmt.Errorf("update status error"))
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -1217,29 +3284,45 @@ func TestFailedStoragePodsGetRemoved(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, result)
 
-	// Verify there is event raised for the failed pod
 	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedStoragePodReason))
+	require.Equal(t, <-recorder.Events,
+		fmt.Sprintf("%v %v update status error", v1.EventTypeWarning, util.FailedSyncReason))
 
-	// Verify no pod is created on first node, failed pod is deleted,
-	// and already deleted pod is not deleted again
-	require.Empty(t, podControl.Templates)
-	require.Len(t, podControl.DeletePodName, 1)
-	require.Equal(t, failedPod.Name, podControl.DeletePodName[0])
+	newCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, newCluster, cluster.Name, cluster.Namespace)
+	require.Equal(t, "Offline", newCluster.Status.Phase)
+
+	condition := getClusterCondition(newCluster, corev1alpha1.ClusterConditionTypeAvailable)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ConditionFalse, condition.Status)
+	require.Equal(t, "Offline", condition.Reason)
+
+	condition = getClusterCondition(newCluster, corev1alpha1.ClusterConditionTypeDegraded)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonStatusUnavailable, condition.Reason)
+	require.Contains(t, condition.Message, "update status error")
 }
 
-func TestExtraStoragePodsGetRemoved(t *testing.T) {
+func TestReconcileWithLegacyConditions(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
-	storageLabels := map[string]string{
-		labelKeyName:       cluster.Name,
-		labelKeyDriverName: driverName,
+	cluster.Status.Phase = string(corev1alpha1.ClusterOnline)
+	cluster.Status.Conditions = []corev1alpha1.ClusterCondition{
+		{
+			Type:   corev1alpha1.ClusterConditionTypeInstall,
+			Status: corev1alpha1.ClusterOperationCompleted,
+			Reason: "Portworx installed",
+		},
+		{
+			Type:   corev1alpha1.ClusterConditionTypeUpgrade,
+			Status: corev1alpha1.ClusterOperationInProgress,
+			Reason: "Upgrading portworx",
+		},
 	}
-
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
 	k8sClient := testutil.FakeK8sClient(cluster)
@@ -1256,64 +3339,10 @@ func TestExtraStoragePodsGetRemoved(t *testing.T) {
 	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 
-	// This will create a revision which we will map to our pre-created pods
-	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
-	require.NoError(t, err)
-
-	// Kubernetes node with enough resources to create new pods
-	k8sNode := createK8sNode("k8s-node", 10)
-
-	// Pods that are already running on the k8s nodes with same hash
-	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
-	affinity := &v1.Affinity{
-		NodeAffinity: &v1.NodeAffinity{
-			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
-				NodeSelectorTerms: []v1.NodeSelectorTerm{
-					{
-						MatchFields: []v1.NodeSelectorRequirement{
-							{
-								Key:      schedulerapi.NodeFieldSelectorKeyNodeName,
-								Operator: v1.NodeSelectorOpIn,
-								Values:   []string{k8sNode.Name},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-
-	// If multiple pods are running on a single node, only one with earliest
-	// timestamp and which is scheduled should be retained. If spec.NodeName
-	// is set, it is assumed that the pod is scheduled.
-	unscheduledPod1 := createStoragePod(cluster, "unscheduled-pod-1", "", storageLabels)
-	unscheduledPod1.CreationTimestamp = metav1.Now()
-	unscheduledPod1.Spec.Affinity = affinity
-
-	creationTimestamp := metav1.NewTime(time.Now().Add(1 * time.Minute))
-	runningPod1 := createStoragePod(cluster, "running-pod-1", k8sNode.Name, storageLabels)
-	runningPod1.CreationTimestamp = creationTimestamp
-	runningPod2 := createStoragePod(cluster, "running-pod-2", k8sNode.Name, storageLabels)
-	runningPod2.CreationTimestamp = creationTimestamp
-
-	unscheduledPod2 := createStoragePod(cluster, "unscheduled-pod-2", "", storageLabels)
-	unscheduledPod2.CreationTimestamp = metav1.NewTime(time.Now().Add(2 * time.Minute))
-	unscheduledPod2.Spec.Affinity = affinity
-
-	extraRunningPod := createStoragePod(cluster, "extra-running-pod", k8sNode.Name, storageLabels)
-	extraRunningPod.CreationTimestamp = metav1.NewTime(time.Now().Add(3 * time.Minute))
-
-	k8sClient.Create(context.TODO(), k8sNode)
-	k8sClient.Create(context.TODO(), unscheduledPod1)
-	k8sClient.Create(context.TODO(), runningPod1)
-	k8sClient.Create(context.TODO(), runningPod2)
-	k8sClient.Create(context.TODO(), unscheduledPod2)
-	k8sClient.Create(context.TODO(), extraRunningPod)
+	// TestCase: Legacy conditions should be removed even if the reconcile fails
+	driver.EXPECT().PreInstall(gomock.Any()).Return(fmt.Errorf("pre-install error"))
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -1321,34 +3350,56 @@ func TestExtraStoragePodsGetRemoved(t *testing.T) {
 			Namespace: cluster.Namespace,
 		},
 	}
+	_, err := controller.Reconcile(request)
+	require.Error(t, err)
+	require.Len(t, recorder.Events, 1)
+	<-recorder.Events
+
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Len(t, updatedCluster.Status.Conditions, 1)
+	require.Equal(t, corev1alpha1.ClusterConditionTypeDegraded, updatedCluster.Status.Conditions[0].Type)
+	require.Equal(t, reasonReconcileFailed, updatedCluster.Status.Conditions[0].Reason)
+
+	// TestCase: Legacy delete condition should be translated to the Deleting
+	// condition and kept while the driver has no newer one
+	updatedCluster.Status.Conditions = append(updatedCluster.Status.Conditions,
+		corev1alpha1.ClusterCondition{
+			Type:   corev1alpha1.ClusterConditionTypeDelete,
+			Status: corev1alpha1.ClusterOperationInProgress,
+			Reason: "Started node wiper daemonset",
+		},
+	)
+	deletionTimestamp := metav1.Now()
+	updatedCluster.DeletionTimestamp = &deletionTimestamp
+	updatedCluster.Finalizers = []string{deleteFinalizerName}
+	k8sClient.Update(context.TODO(), updatedCluster)
+
+	driver.EXPECT().DeleteStorage(gomock.Any()).Return(nil, nil)
+
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
 
-	// Verify there is no event raised for the extra pods
-	require.Empty(t, recorder.Events)
-
-	require.Empty(t, podControl.Templates)
-	require.ElementsMatch(t,
-		[]string{runningPod2.Name, unscheduledPod1.Name, unscheduledPod2.Name, extraRunningPod.Name},
-		podControl.DeletePodName,
-	)
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Len(t, updatedCluster.Status.Conditions, 2)
+	condition := getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeDeleting)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, corev1alpha1.ClusterDeleteInProgressReason, condition.Reason)
+	require.Equal(t, "Started node wiper daemonset", condition.Message)
+	require.Nil(t, getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeDelete))
+	require.Equal(t, "DeleteInProgress", updatedCluster.Status.Phase)
+	require.Equal(t, []string{deleteFinalizerName}, updatedCluster.Finalizers)
 }
 
-func TestStoragePodsAreRemovedIfDisabled(t *testing.T) {
+func TestFailedPreInstallFromDriver(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
-	cluster.Annotations = map[string]string{
-		AnnotationDisableStorage: "1",
-	}
-	storageLabels := map[string]string{
-		labelKeyName:       cluster.Name,
-		labelKeyDriverName: driverName,
-	}
-
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
 	k8sClient := testutil.FakeK8sClient(cluster)
@@ -1362,26 +3413,10 @@ func TestStoragePodsAreRemovedIfDisabled(t *testing.T) {
 		kubernetesVersion: k8sVersion,
 	}
 
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
-
-	// This will create a revision which we will map to our pre-created pods
-	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
-	require.NoError(t, err)
-
-	// Kubernetes node with enough resources to create new pods
-	k8sNode := createK8sNode("k8s-node", 10)
-
-	// Pods that are already running on the k8s nodes with same hash
-	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
-	runningPod := createStoragePod(cluster, "running-pod", k8sNode.Name, storageLabels)
-
-	k8sClient.Create(context.TODO(), k8sNode)
-	k8sClient.Create(context.TODO(), runningPod)
+	driver.EXPECT().PreInstall(gomock.Any()).Return(fmt.Errorf("pre-install error"))
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -1390,30 +3425,42 @@ func TestStoragePodsAreRemovedIfDisabled(t *testing.T) {
 		},
 	}
 	result, err := controller.Reconcile(request)
-	require.NoError(t, err)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "pre-install error")
 	require.Empty(t, result)
 
-	// Verify there is no event raised for the extra pods
-	require.Empty(t, recorder.Events)
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
 
-	require.Empty(t, podControl.Templates)
-	require.ElementsMatch(t, []string{runningPod.Name}, podControl.DeletePodName)
+	// The cluster should be marked as degraded as the reconcile failed
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	condition := getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeDegraded)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonReconcileFailed, condition.Reason)
+	require.Contains(t, condition.Message, "pre-install error")
 }
 
-func TestStoragePodFailureDueToInsufficientResources(t *testing.T) {
+func TestUpdateDriverWithInstanceInformation(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
-	storageLabels := map[string]string{
-		labelKeyName:       cluster.Name,
-		labelKeyDriverName: driverName,
-	}
+
+	// Kubernetes node with resources to create a pod
+	k8sNode1 := createK8sNode("k8s-node-1", 1)
+	k8sNode1.Labels = map[string]string{failureDomainZoneKey: "z1"}
+	k8sNode2 := createK8sNode("k8s-node-2", 1)
+	k8sNode2.Labels = map[string]string{failureDomainZoneKey: "z1"}
+	k8sNode3 := createK8sNode("k8s-node-3", 1)
+	k8sNode3.Labels = map[string]string{failureDomainZoneKey: "z2"}
 
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
-	k8sClient := testutil.FakeK8sClient(cluster)
+	k8sClient := testutil.FakeK8sClient(cluster, k8sNode1, k8sNode2, k8sNode3)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
 	controller := Controller{
@@ -1424,29 +3471,19 @@ func TestStoragePodFailureDueToInsufficientResources(t *testing.T) {
 		kubernetesVersion: k8sVersion,
 	}
 
-	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
-
-	// This will create a revision which we will map to our pre-created pods
-	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
-	require.NoError(t, err)
-
-	// Kubernetes nodes without enough resources to create a pod
-	k8sNode1 := createK8sNode("k8s-node-1", 0)
-	k8sNode2 := createK8sNode("k8s-node-2", 0)
-
-	// Pods that are already running on the k8s nodes with same hash
-	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
-	runningPod := createStoragePod(cluster, "running-pod", k8sNode2.Name, storageLabels)
-
-	k8sClient.Create(context.TODO(), k8sNode1)
-	k8sClient.Create(context.TODO(), k8sNode2)
-	k8sClient.Create(context.TODO(), runningPod)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	expectedDriverInfo := &storage.UpdateDriverInfo{
+		ZoneToInstancesMap: map[string]int{
+			"z1": 2,
+			"z2": 1,
+		},
+	}
+	driver.EXPECT().UpdateDriver(expectedDriverInfo).Return(nil)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -1457,51 +3494,42 @@ func TestStoragePodFailureDueToInsufficientResources(t *testing.T) {
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Empty(t, recorder.Events)
 
-	// Verify event is raised if failed to place pod
-	// Two events per pod are raised as we simulate every pod on the node twice
-	require.Len(t, recorder.Events, 4)
-	expectedEvent := fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedPlacementReason)
-	require.Contains(t, <-recorder.Events, expectedEvent)
-	require.Contains(t, <-recorder.Events, expectedEvent)
-	require.Contains(t, <-recorder.Events, expectedEvent)
-	require.Contains(t, <-recorder.Events, expectedEvent)
+	// Should contain cloud provider information if any node has it
+	k8sNode2.Spec.ProviderID = "invalid"
+	k8sClient.Update(context.TODO(), k8sNode2)
+	k8sNode3.Spec.ProviderID = "testcloud://test-instance-id"
+	k8sClient.Update(context.TODO(), k8sNode3)
 
-	// Verify there is one revision for the new StorageCluster object
-	revisions := &appsv1.ControllerRevisionList{}
-	err = testutil.List(k8sClient, revisions)
+	expectedDriverInfo.CloudProvider = "testcloud"
+	driver.EXPECT().UpdateDriver(expectedDriverInfo).Return(nil)
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+
+	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
-	require.Len(t, revisions.Items, 1)
+	require.Empty(t, result)
+	require.Empty(t, recorder.Events)
 
-	// Verify no pod is created due to insufficient resources.
-	// Existing pods are not removed from nodes without resources.
-	require.Empty(t, podControl.Templates)
-	require.Empty(t, podControl.DeletePodName)
+	// Should not fail reconcile even if there is an error on UpdateDriver
+	driver.EXPECT().UpdateDriver(expectedDriverInfo).Return(fmt.Errorf("update error"))
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, recorder.Events)
 }
 
-func TestStoragePodFailureDueToNodeSelectorNotMatch(t *testing.T) {
+func TestDeleteStorageClusterWithoutFinalizers(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
+	cluster := createStorageCluster()
+	cluster.Finalizers = []string{}
+	deletionTimeStamp := metav1.Now()
+	cluster.DeletionTimestamp = &deletionTimeStamp
+
 	driverName := "mock-driver"
-	cluster := createStorageCluster()
-	cluster.Spec.Placement = &corev1alpha1.PlacementSpec{
-		NodeAffinity: &v1.NodeAffinity{
-			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
-				NodeSelectorTerms: []v1.NodeSelectorTerm{
-					{
-						MatchFields: []v1.NodeSelectorRequirement{
-							{
-								Key:      schedulerapi.NodeFieldSelectorKeyNodeName,
-								Operator: v1.NodeSelectorOpNotIn,
-								Values:   []string{"k8s-node-1", "k8s-node-2"},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
 	storageLabels := map[string]string{
 		labelKeyName:       cluster.Name,
 		labelKeyDriverName: driverName,
@@ -1520,37 +3548,33 @@ func TestStoragePodFailureDueToNodeSelectorNotMatch(t *testing.T) {
 		kubernetesVersion: k8sVersion,
 	}
 
-	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
-
-	// This will create a revision which we will map to our pre-created pods
-	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
-	require.NoError(t, err)
 
 	// Kubernetes node with enough resources to create new pods
 	k8sNode1 := createK8sNode("k8s-node-1", 10)
-	k8sNode1.Labels = map[string]string{
-		"test": "node1",
-	}
 	k8sNode2 := createK8sNode("k8s-node-2", 10)
-	k8sNode2.Labels = map[string]string{
-		"test": "node2",
-	}
 	k8sNode3 := createK8sNode("k8s-node-3", 10)
 
-	// Pods that are already running on the k8s nodes with same hash
-	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
-	runningPod := createStoragePod(cluster, "running-pod", k8sNode3.Name, storageLabels)
+	// Only pods that are associated with this cluster should get deleted
+	storagePod1 := createStoragePod(cluster, "pod-1", k8sNode1.Name, storageLabels)
+	storagePod2 := createStoragePod(cluster, "pod-2", k8sNode2.Name, storageLabels)
+
+	storagePod3 := createStoragePod(cluster, "pod-3", k8sNode3.Name, nil)
+	storagePod3.OwnerReferences = nil
+
+	otherCluster := createStorageCluster()
+	otherCluster.UID = "other-uid"
+	otherCluster.Name = "other-cluster"
+	storagePod4 := createStoragePod(otherCluster, "pod-4", k8sNode3.Name, storageLabels)
 
 	k8sClient.Create(context.TODO(), k8sNode1)
 	k8sClient.Create(context.TODO(), k8sNode2)
 	k8sClient.Create(context.TODO(), k8sNode3)
-	k8sClient.Create(context.TODO(), runningPod)
+	k8sClient.Create(context.TODO(), storagePod1)
+	k8sClient.Create(context.TODO(), storagePod2)
+	k8sClient.Create(context.TODO(), storagePod3)
+	k8sClient.Create(context.TODO(), storagePod4)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -1562,35 +3586,23 @@ func TestStoragePodFailureDueToNodeSelectorNotMatch(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, result)
 
-	// No need to raise events if node selectors don't match for a node
-	// Verify no pod is created due to node selector mismatch. Also remove any
-	// running pod if the selectors don't match.
 	require.Empty(t, recorder.Events)
 	require.Empty(t, podControl.Templates)
-	require.ElementsMatch(t, []string{runningPod.Name}, podControl.DeletePodName)
+	require.ElementsMatch(t,
+		[]string{storagePod1.Name, storagePod2.Name},
+		podControl.DeletePodName,
+	)
 }
 
-func TestStoragePodSchedulingWithTolerations(t *testing.T) {
+func TestDeleteStorageClusterWithFinalizers(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
-	driverName := "mock-driver"
 	cluster := createStorageCluster()
-	cluster.Spec.Placement = &corev1alpha1.PlacementSpec{
-		Tolerations: []v1.Toleration{
-			{
-				Key:      "must-exist",
-				Operator: v1.TolerationOpExists,
-				Effect:   v1.TaintEffectNoExecute,
-			},
-			{
-				Key:      "foo",
-				Operator: v1.TolerationOpEqual,
-				Value:    "bar",
-				Effect:   v1.TaintEffectNoSchedule,
-			},
-		},
-	}
+	deletionTimeStamp := metav1.Now()
+	cluster.DeletionTimestamp = &deletionTimeStamp
+
+	driverName := "mock-driver"
 	storageLabels := map[string]string{
 		labelKeyName:       cluster.Name,
 		labelKeyDriverName: driverName,
@@ -1609,54 +3621,17 @@ func TestStoragePodSchedulingWithTolerations(t *testing.T) {
 		kubernetesVersion: k8sVersion,
 	}
 
-	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).Times(3)
+	// Empty delete condition should not remove finalizer
+	driver.EXPECT().DeleteStorage(gomock.Any()).Return(nil, nil)
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).Times(3)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).Times(3)
-	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).Times(3)
-
-	// This will create a revision which we will map to our pre-created pods
-	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
-	require.NoError(t, err)
-
-	// Kubernetes node with enough resources to create new pods
-	k8sNode1 := createK8sNode("k8s-node-1", 10)
-	k8sNode1.Spec.Taints = []v1.Taint{
-		{
-			Key:    "must-exist",
-			Value:  "anything",
-			Effect: v1.TaintEffectNoExecute,
-		},
-		{
-			Key:    "foo",
-			Value:  "bar",
-			Effect: v1.TaintEffectNoSchedule,
-		},
-	}
-	k8sNode2 := createK8sNode("k8s-node-2", 10)
-	k8sNode2.Spec.Taints = []v1.Taint{
-		{
-			Key:    "foo",
-			Value:  "bar",
-			Effect: v1.TaintEffectNoSchedule,
-		},
-	}
-	k8sNode3 := createK8sNode("k8s-node-3", 10)
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 
-	// Pods that are already running on the k8s nodes with same hash
-	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
-	runningPod1 := createStoragePod(cluster, "running-pod-1", k8sNode1.Name, storageLabels)
-	runningPod2 := createStoragePod(cluster, "running-pod-2", k8sNode2.Name, storageLabels)
-	runningPod3 := createStoragePod(cluster, "running-pod-3", k8sNode3.Name, storageLabels)
+	k8sNode := createK8sNode("k8s-node", 10)
+	storagePod := createStoragePod(cluster, "storage-pod", k8sNode.Name, storageLabels)
 
-	k8sClient.Create(context.TODO(), k8sNode1)
-	k8sClient.Create(context.TODO(), k8sNode2)
-	k8sClient.Create(context.TODO(), k8sNode3)
-	k8sClient.Create(context.TODO(), runningPod1)
-	k8sClient.Create(context.TODO(), runningPod2)
-	k8sClient.Create(context.TODO(), runningPod3)
+	k8sClient.Create(context.TODO(), k8sNode)
+	k8sClient.Create(context.TODO(), storagePod)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -1667,64 +3642,123 @@ func TestStoragePodSchedulingWithTolerations(t *testing.T) {
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-
-	// No pods should be deleted as they have tolerations for the node taints
 	require.Empty(t, recorder.Events)
+
 	require.Empty(t, podControl.Templates)
-	require.Empty(t, podControl.DeletePodName)
+	require.ElementsMatch(t, []string{storagePod.Name}, podControl.DeletePodName)
 
-	// Case: Remove tolerations and the pods on nodes with NoExecute should be removed.
-	// Pods on nodes with NoSchedule should NOT be removed
-	cluster.Spec.Placement = nil
-	k8sClient.Update(context.TODO(), cluster)
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Len(t, updatedCluster.Status.Conditions, 1)
+	deleteCondition := updatedCluster.Status.Conditions[0]
+	require.Equal(t, corev1alpha1.ClusterConditionTypeDeleting, deleteCondition.Type)
+	require.Equal(t, corev1alpha1.ConditionTrue, deleteCondition.Status)
+	require.Equal(t, corev1alpha1.ClusterDeleteInProgressReason, deleteCondition.Reason)
+	require.False(t, deleteCondition.LastTransitionTime.IsZero())
+	require.Equal(t, "DeleteInProgress", updatedCluster.Status.Phase)
+	require.Equal(t, []string{deleteFinalizerName}, updatedCluster.Finalizers)
+
+	// If storage driver returns error, then controller should not return error but raise an event
+	driver.EXPECT().DeleteStorage(gomock.Any()).Return(nil, fmt.Errorf("delete error"))
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
 
+	require.Len(t, recorder.Events, 1)
+	raisedEvent := <-recorder.Events
+	require.Contains(t, raisedEvent,
+		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
+	require.Contains(t, raisedEvent, "delete error")
+
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Len(t, updatedCluster.Status.Conditions, 1)
+	require.Equal(t, deleteCondition, updatedCluster.Status.Conditions[0])
+	require.Equal(t, "DeleteInProgress", updatedCluster.Status.Phase)
+	require.Equal(t, []string{deleteFinalizerName}, updatedCluster.Finalizers)
+
+	// If delete condition is not present already, then add to the cluster
+	updatedCluster.Status.Conditions = []corev1alpha1.ClusterCondition{
+		{
+			Type:   corev1alpha1.ClusterConditionTypeAvailable,
+			Status: corev1alpha1.ConditionTrue,
+		},
+	}
+	k8sClient.Update(context.TODO(), updatedCluster)
+	condition := &corev1alpha1.ClusterCondition{
+		Type:   corev1alpha1.ClusterConditionTypeDelete,
+		Status: corev1alpha1.ClusterOperationFailed,
+		Reason: "delete failed",
+	}
+	driver.EXPECT().DeleteStorage(gomock.Any()).Return(condition, nil)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
 	require.Empty(t, recorder.Events)
-	require.Empty(t, podControl.Templates)
-	require.ElementsMatch(t, []string{runningPod1.Name}, podControl.DeletePodName)
 
-	// Case: Delete a pod lacking NoSchedule toleration and it should not be started again
-	k8sClient.Delete(context.TODO(), runningPod2)
-	k8sClient.Delete(context.TODO(), runningPod1)
-	podControl.DeletePodName = nil
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Len(t, updatedCluster.Status.Conditions, 2)
+	deleteCondition = updatedCluster.Status.Conditions[1]
+	require.Equal(t, corev1alpha1.ClusterConditionTypeDeleting, deleteCondition.Type)
+	require.Equal(t, corev1alpha1.ConditionTrue, deleteCondition.Status)
+	require.Equal(t, corev1alpha1.ClusterDeleteFailedReason, deleteCondition.Reason)
+	require.Equal(t, "delete failed", deleteCondition.Message)
+	require.Equal(t, "DeleteFailed", updatedCluster.Status.Phase)
+	require.Equal(t, []string{deleteFinalizerName}, updatedCluster.Finalizers)
+
+	// If delete condition is present, then update it. The transition time
+	// should not change as the status of the condition has not changed.
+	condition = &corev1alpha1.ClusterCondition{
+		Type:   corev1alpha1.ClusterConditionTypeDelete,
+		Status: corev1alpha1.ClusterOperationTimeout,
+	}
+	driver.EXPECT().DeleteStorage(gomock.Any()).Return(condition, nil)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Empty(t, recorder.Events)
+
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Len(t, updatedCluster.Status.Conditions, 2)
+	require.Equal(t, corev1alpha1.ClusterDeleteTimeoutReason, updatedCluster.Status.Conditions[1].Reason)
+	require.Equal(t, deleteCondition.LastTransitionTime, updatedCluster.Status.Conditions[1].LastTransitionTime)
+	require.Equal(t, "DeleteTimeout", updatedCluster.Status.Phase)
+	require.Equal(t, []string{deleteFinalizerName}, updatedCluster.Finalizers)
+
+	// If delete condition status is completed, then remove delete finalizer
+	condition = &corev1alpha1.ClusterCondition{
+		Type:   corev1alpha1.ClusterConditionTypeDelete,
+		Status: corev1alpha1.ClusterOperationCompleted,
+	}
+	driver.EXPECT().DeleteStorage(gomock.Any()).Return(condition, nil)
 
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
 	require.Empty(t, recorder.Events)
-	require.Empty(t, podControl.Templates)
-	require.Empty(t, podControl.DeletePodName)
+
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Len(t, updatedCluster.Status.Conditions, 2)
+	require.Equal(t, corev1alpha1.ClusterDeleteCompletedReason, updatedCluster.Status.Conditions[1].Reason)
+	require.Equal(t, "DeleteCompleted", updatedCluster.Status.Phase)
+	require.Empty(t, updatedCluster.Finalizers)
 }
 
-func TestFailureDuringPodTemplateCreation(t *testing.T) {
+func TestUpdateStorageClusterWithRollingUpdateStrategy(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
-	useAllDevices := true
-	cluster.Spec.Storage = &corev1alpha1.StorageSpec{
-		UseAll: &useAllDevices,
-	}
-	cluster.Spec.Nodes = []corev1alpha1.NodeSpec{
-		{
-			Selector: corev1alpha1.NodeSelector{
-				NodeName: "k8s-node-1",
-			},
-		},
-	}
-
-	// Kubernetes node with resources to create a pod
-	k8sNode1 := createK8sNode("k8s-node-1", 1)
-	k8sNode2 := createK8sNode("k8s-node-2", 1)
-
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
-	k8sClient := testutil.FakeK8sClient(cluster, k8sNode1, k8sNode2)
+	k8sClient := testutil.FakeK8sClient(cluster)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
 	controller := Controller{
@@ -1734,15 +3768,19 @@ func TestFailureDuringPodTemplateCreation(t *testing.T) {
 		recorder:          recorder,
 		kubernetesVersion: k8sVersion,
 	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
-	driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-1").
-		Return(v1.PodSpec{}, fmt.Errorf("pod template error for k8s-node-1")).
-		Times(1)
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	k8sNode := createK8sNode("k8s-node", 10)
+	k8sClient.Create(context.TODO(), k8sNode)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -1751,56 +3789,104 @@ func TestFailureDuringPodTemplateCreation(t *testing.T) {
 		},
 	}
 	result, err := controller.Reconcile(request)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "pod template error for k8s-node-1")
+	require.NoError(t, err)
 	require.Empty(t, result)
 
-	// Verify there is event raised for failure to create pod templates for node spec
-	require.Len(t, recorder.Events, 1)
-	eventMsg := <-recorder.Events
-	require.Contains(t, eventMsg, fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
-	require.Contains(t, eventMsg, "pod template error for k8s-node-1")
+	revisions := &appsv1.ControllerRevisionList{}
+	err = testutil.List(k8sClient, revisions)
+	require.NoError(t, err)
+	require.Len(t, revisions.Items, 1)
+	require.Equal(t, int64(1), revisions.Items[0].Revision)
 
-	// When pod template creation passes for some and fails for others
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
-	driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-1").
-		Return(v1.PodSpec{}, nil).
-		Times(1)
-	driver.EXPECT().GetStoragePodSpec(gomock.Any(), "k8s-node-2").
-		Return(v1.PodSpec{}, fmt.Errorf("pod template error for k8s-node-2")).
-		Times(1)
+	require.Len(t, podControl.ControllerRefs, 1)
+	require.Equal(t, *clusterRef, podControl.ControllerRefs[0])
+
+	// Verify that the revision hash matches that of the new pod
+	require.Len(t, podControl.Templates, 1)
+	require.Equal(t, revisions.Items[0].Labels[defaultStorageClusterUniqueLabelKey],
+		podControl.Templates[0].Labels[defaultStorageClusterUniqueLabelKey])
+
+	// Test case: Changing the cluster spec -
+	// A new revision should be created for the new cluster spec
+	// Also the pod should be changed with the updated spec
+	cluster.Spec.Image = "new/image"
+	k8sClient.Update(context.TODO(), cluster)
+	oldPod, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
+	require.NoError(t, err)
+	oldPod.Name = oldPod.GenerateName + "1"
+	oldPod.Namespace = cluster.Namespace
+	oldPod.Spec.NodeName = k8sNode.Name
+	oldPod.Status.Conditions = append(oldPod.Status.Conditions, v1.PodCondition{
+		Type:   v1.PodReady,
+		Status: v1.ConditionTrue,
+	})
+	k8sClient.Create(context.TODO(), oldPod)
+
+	// Reset the fake pod controller
+	podControl.Templates = nil
+	podControl.ControllerRefs = nil
+	podControl.DeletePodName = nil
 
 	result, err = controller.Reconcile(request)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "pod template error for k8s-node-2")
+	require.NoError(t, err)
 	require.Empty(t, result)
 
-	// Verify there is event raised for failure to create pod templates for node spec
-	require.Len(t, recorder.Events, 1)
-	eventMsg = <-recorder.Events
-	require.Contains(t, eventMsg, fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
-	require.Contains(t, eventMsg, "pod template error for k8s-node-2")
+	// New revision should be created for the updated cluster spec
+	revisions = &appsv1.ControllerRevisionList{}
+	err = testutil.List(k8sClient, revisions)
+	require.NoError(t, err)
+	require.Len(t, revisions.Items, 2)
+	require.Equal(t, int64(2), revisions.Items[1].Revision)
+
+	// The old pod should be marked for deletion
+	require.Empty(t, podControl.Templates)
+	require.Empty(t, podControl.ControllerRefs)
+	require.Len(t, podControl.DeletePodName, 1)
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
+
+	// Test case: Running reconcile again should start a new pod with new
+	// revision hash.
+	err = k8sClient.Delete(context.TODO(), oldPod)
+	require.NoError(t, err)
+	podControl.Templates = nil
+	podControl.ControllerRefs = nil
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// New revision should not be created as the cluster spec is unchanged
+	revisions = &appsv1.ControllerRevisionList{}
+	err = testutil.List(k8sClient, revisions)
+	require.NoError(t, err)
+	require.Len(t, revisions.Items, 2)
+
+	require.Empty(t, podControl.DeletePodName)
+
+	require.Len(t, podControl.ControllerRefs, 1)
+	require.Equal(t, *clusterRef, podControl.ControllerRefs[0])
+
+	// New revision's hash should match that of the new pod.
+	require.Len(t, podControl.Templates, 1)
+	require.Equal(t, revisions.Items[1].Labels[defaultStorageClusterUniqueLabelKey],
+		podControl.Templates[0].Labels[defaultStorageClusterUniqueLabelKey])
 }
 
-func TestFailureDuringCreateDeletePods(t *testing.T) {
+func TestUpdateStorageClusterShouldNotExceedMaxUnavailable(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
 	storageLabels := map[string]string{
 		labelKeyName:       cluster.Name,
 		labelKeyDriverName: driverName,
 	}
-
-	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
-	driver := testutil.MockDriver(mockCtrl)
 	k8sClient := testutil.FakeK8sClient(cluster)
-	podControl := &k8scontroller.FakePodControl{
-		Err: fmt.Errorf("pod control error"),
-	}
+	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
 	controller := Controller{
 		client:            k8sClient,
@@ -1809,34 +3895,65 @@ func TestFailureDuringCreateDeletePods(t *testing.T) {
 		recorder:          recorder,
 		kubernetesVersion: k8sVersion,
 	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	// This will create a revision which we will map to our pre-created pods
-	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
-	require.NoError(t, err)
-
-	// Kubernetes node with enough resources to create new pods
 	k8sNode1 := createK8sNode("k8s-node-1", 10)
 	k8sNode2 := createK8sNode("k8s-node-2", 10)
 	k8sNode3 := createK8sNode("k8s-node-3", 10)
+	k8sNode4 := createK8sNode("k8s-node-4", 10)
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+	k8sClient.Create(context.TODO(), k8sNode3)
+	k8sClient.Create(context.TODO(), k8sNode4)
 
 	// Pods that are already running on the k8s nodes with same hash
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
 	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
-	failedPod := createStoragePod(cluster, "failed-pod", k8sNode1.Name, storageLabels)
-	failedPod.Status = v1.PodStatus{
-		Phase: v1.PodFailed,
+	oldPod1 := createStoragePod(cluster, "old-pod-1", k8sNode1.Name, storageLabels)
+	oldPod1.Status.Conditions = []v1.PodCondition{
+		{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
+		},
 	}
 
-	k8sClient.Create(context.TODO(), k8sNode1)
-	k8sClient.Create(context.TODO(), k8sNode2)
-	k8sClient.Create(context.TODO(), k8sNode3)
-	k8sClient.Create(context.TODO(), failedPod)
+	oldPod2 := oldPod1.DeepCopy()
+	oldPod2.Name = "old-pod-2"
+	oldPod2.Spec.NodeName = k8sNode2.Name
+
+	oldPod3 := oldPod1.DeepCopy()
+	oldPod3.Name = "old-pod-3"
+	oldPod3.Spec.NodeName = k8sNode3.Name
+
+	oldPod4 := oldPod1.DeepCopy()
+	oldPod4.Name = "old-pod-4"
+	oldPod4.Spec.NodeName = k8sNode4.Name
+
+	k8sClient.Create(context.TODO(), oldPod1)
+	k8sClient.Create(context.TODO(), oldPod2)
+	k8sClient.Create(context.TODO(), oldPod3)
+	k8sClient.Create(context.TODO(), oldPod4)
+
+	// Should delete pods only up to maxUnavailable value. In this case - 2 pods
+	maxUnavailable := intstr.FromInt(2)
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+	cluster.Spec.Image = "test/image:v2"
+	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -1845,19 +3962,105 @@ func TestFailureDuringCreateDeletePods(t *testing.T) {
 		},
 	}
 	result, err := controller.Reconcile(request)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "pod control error")
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Len(t, podControl.DeletePodName, 2)
+
+	// Next reconcile loop should not delete more pods as 2 are already down.
+	// If a pod is marked for deletion but not actually deleted do not send it
+	// for deletion again.
+	deletedPods := make([]*v1.Pod, 0)
+	deletedPod1 := &v1.Pod{}
+	testutil.Get(k8sClient, deletedPod1, podControl.DeletePodName[0], cluster.Namespace)
+	deletedPods = append(deletedPods, deletedPod1)
+	k8sClient.Delete(context.TODO(), deletedPod1)
+
+	deletedPod2 := &v1.Pod{}
+	testutil.Get(k8sClient, deletedPod2, podControl.DeletePodName[1], cluster.Namespace)
+	deletionTimestamp := metav1.Now()
+	deletedPod2.DeletionTimestamp = &deletionTimestamp
+	deletedPod2.Status.Conditions[0].Status = v1.ConditionFalse
+	k8sClient.Update(context.TODO(), deletedPod2)
+
+	podControl.Templates = nil
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+	// One pod is still being deleted, so only 1 template created instead of 2
+	require.Len(t, podControl.Templates, 1)
+
+	// If the pods are created but not ready, even then no extra pod should be deleted
+	replacedPod1, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
+	require.NoError(t, err)
+	replacedPod1.Name = replacedPod1.GenerateName + "replaced-1"
+	replacedPod1.Namespace = cluster.Namespace
+	replacedPod1.Spec.NodeName = deletedPods[0].Spec.NodeName
+	k8sClient.Create(context.TODO(), replacedPod1)
+
+	k8sClient.Delete(context.TODO(), deletedPod2)
+	deletedPods = append(deletedPods, deletedPod2)
+
+	podControl.Templates = nil
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+	require.Len(t, podControl.Templates, 1)
+
+	// If another update happens to storage cluster, we should account for non-running
+	// and non-ready pods in max unavailable pods. Also we should delete an old not ready
+	// pod before a running one.
+	cluster.Spec.Image = "test/image:v3"
+	k8sClient.Update(context.TODO(), cluster)
+
+	podControl.Templates = nil
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Equal(t, []string{replacedPod1.Name}, podControl.DeletePodName)
+	require.Len(t, podControl.Templates, 1)
+
+	// Once the new pods are up and in ready state, we should delete remaining
+	// pods with older versions.
+	k8sClient.Delete(context.TODO(), replacedPod1)
+
+	replacedPod2, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
+	require.NoError(t, err)
+	replacedPod2.Name = replacedPod2.GenerateName + "replaced-2"
+	replacedPod2.Namespace = cluster.Namespace
+	replacedPod2.Spec.NodeName = deletedPods[0].Spec.NodeName
+	replacedPod2.Status.Conditions = []v1.PodCondition{
+		{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
+		},
+	}
+	k8sClient.Create(context.TODO(), replacedPod2)
+
+	replacedPod3 := replacedPod2.DeepCopy()
+	replacedPod3.Name = replacedPod2.GenerateName + "replaced-3"
+	replacedPod3.Spec.NodeName = deletedPods[1].Spec.NodeName
+	k8sClient.Create(context.TODO(), replacedPod3)
 
-	// Verify there is event raised for failure to create/delete pods
-	require.Len(t, recorder.Events, 2)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedStoragePodReason))
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
+	podControl.Templates = nil
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Len(t, podControl.DeletePodName, 2)
+	require.NotContains(t, podControl.DeletePodName, replacedPod2.Name)
+	require.NotContains(t, podControl.DeletePodName, replacedPod3.Name)
 }
 
-func TestTimeoutFailureDuringCreatePods(t *testing.T) {
+func TestUpdateStorageClusterWithPercentageMaxUnavailable(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -1865,11 +4068,13 @@ func TestTimeoutFailureDuringCreatePods(t *testing.T) {
 	cluster := createStorageCluster()
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
 	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
-	podControl := &k8scontroller.FakePodControl{
-		Err: errors.NewTimeoutError("timeout error", 0),
-	}
 	controller := Controller{
 		client:            k8sClient,
 		Driver:            driver,
@@ -1877,21 +4082,66 @@ func TestTimeoutFailureDuringCreatePods(t *testing.T) {
 		recorder:          recorder,
 		kubernetesVersion: k8sVersion,
 	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil)
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	// Kubernetes node with enough resources to create new pods
-	k8sNode := createK8sNode("k8s-node", 10)
-	k8sClient.Create(context.TODO(), k8sNode)
+	k8sNode1 := createK8sNode("k8s-node-1", 10)
+	k8sNode2 := createK8sNode("k8s-node-2", 10)
+	k8sNode3 := createK8sNode("k8s-node-3", 10)
+	k8sNode4 := createK8sNode("k8s-node-4", 10)
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+	k8sClient.Create(context.TODO(), k8sNode3)
+	k8sClient.Create(context.TODO(), k8sNode4)
+
+	// Pods that are already running on the k8s nodes with same hash
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	oldPod1 := createStoragePod(cluster, "old-pod-1", k8sNode1.Name, storageLabels)
+	oldPod1.Status.Conditions = []v1.PodCondition{
+		{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
+		},
+	}
+
+	oldPod2 := oldPod1.DeepCopy()
+	oldPod2.Name = "old-pod-2"
+	oldPod2.Spec.NodeName = k8sNode2.Name
+
+	oldPod3 := oldPod1.DeepCopy()
+	oldPod3.Name = "old-pod-3"
+	oldPod3.Spec.NodeName = k8sNode3.Name
+
+	oldPod4 := oldPod1.DeepCopy()
+	oldPod4.Name = "old-pod-4"
+	oldPod4.Spec.NodeName = k8sNode4.Name
+
+	k8sClient.Create(context.TODO(), oldPod1)
+	k8sClient.Create(context.TODO(), oldPod2)
+	k8sClient.Create(context.TODO(), oldPod3)
+	k8sClient.Create(context.TODO(), oldPod4)
+
+	// Should delete pods only up to maxUnavailable value. In this case - 75%
+	maxUnavailable := intstr.FromString("75%")
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+	cluster.Spec.Image = "test/image:v2"
+	k8sClient.Update(context.TODO(), cluster)
 
-	// Verify there is no error or event raised for timeout errors
-	// during pod creation
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      cluster.Name,
@@ -1901,11 +4151,63 @@ func TestTimeoutFailureDuringCreatePods(t *testing.T) {
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Empty(t, podControl.Templates)
+	require.Len(t, podControl.DeletePodName, 3)
 
-	require.Len(t, recorder.Events, 0)
+	// Next reconcile loop should not delete more pods as 75% are already down,
+	// rather it should try to create 3 pods (75%).
+	deletedPods := make([]*v1.Pod, 0)
+	for _, name := range podControl.DeletePodName {
+		pod := &v1.Pod{}
+		testutil.Get(k8sClient, pod, name, cluster.Namespace)
+		deletedPods = append(deletedPods, pod)
+		k8sClient.Delete(context.TODO(), pod)
+	}
+
+	podControl.Templates = nil
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+	require.Len(t, podControl.Templates, 3)
+
+	// If the pods are up and ready, then the remaining pods are deleted
+	replacedPod1, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
+	require.NoError(t, err)
+	replacedPod1.Name = replacedPod1.GenerateName + "replaced-1"
+	replacedPod1.Namespace = cluster.Namespace
+	replacedPod1.Spec.NodeName = deletedPods[0].Spec.NodeName
+	replacedPod1.Status.Conditions = []v1.PodCondition{
+		{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
+		},
+	}
+	k8sClient.Create(context.TODO(), replacedPod1)
+
+	replacedPod2 := replacedPod1.DeepCopy()
+	replacedPod2.Name = replacedPod1.GenerateName + "replaced-2"
+	replacedPod2.Spec.NodeName = deletedPods[1].Spec.NodeName
+	k8sClient.Create(context.TODO(), replacedPod2)
+
+	replacedPod3 := replacedPod2.DeepCopy()
+	replacedPod3.Name = replacedPod2.GenerateName + "replaced-3"
+	replacedPod3.Spec.NodeName = deletedPods[2].Spec.NodeName
+	k8sClient.Create(context.TODO(), replacedPod3)
+
+	podControl.Templates = nil
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Len(t, podControl.DeletePodName, 1)
+	require.Empty(t, podControl.Templates)
 }
 
-func TestUpdateClusterStatusFromDriver(t *testing.T) {
+func TestUpdateStorageClusterWithPausedRollout(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -1913,6 +4215,10 @@ func TestUpdateClusterStatusFromDriver(t *testing.T) {
 	cluster := createStorageCluster()
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
 	k8sClient := testutil.FakeK8sClient(cluster)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
@@ -1923,19 +4229,48 @@ func TestUpdateClusterStatusFromDriver(t *testing.T) {
 		recorder:          recorder,
 		kubernetesVersion: k8sVersion,
 	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
-	driver.EXPECT().
-		UpdateStorageClusterStatus(gomock.Any()).
-		Do(func(c *corev1alpha1.StorageCluster) {
-			c.Status.Phase = "Online"
-		}).
-		Return(nil)
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	// Ready pods that are already running on the k8s nodes with the same hash
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	oldPods := make([]*v1.Pod, 0)
+	for i := 1; i <= 4; i++ {
+		k8sNode := createK8sNode(fmt.Sprintf("k8s-node-%d", i), 10)
+		k8sNode.Labels = map[string]string{"rack": fmt.Sprintf("rack-%d", (i+1)%2)}
+		k8sClient.Create(context.TODO(), k8sNode)
+
+		oldPod := createStoragePod(cluster, fmt.Sprintf("old-pod-%d", i), k8sNode.Name, storageLabels)
+		oldPod.Status.Conditions = []v1.PodCondition{
+			{
+				Type:   v1.PodReady,
+				Status: v1.ConditionTrue,
+			},
+		}
+		k8sClient.Create(context.TODO(), oldPod)
+		oldPods = append(oldPods, oldPod)
+	}
+
+	maxUnavailable := intstr.FromInt(4)
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+			MaxUnavailable: &maxUnavailable,
+			Paused:         true,
+		},
+	}
+	cluster.Spec.Image = "test/image:v2"
+	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -1943,18 +4278,40 @@ func TestUpdateClusterStatusFromDriver(t *testing.T) {
 			Namespace: cluster.Namespace,
 		},
 	}
+
+	// No pods should be deleted while the rollout is paused
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
 
-	require.Len(t, recorder.Events, 0)
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.NotNil(t, updatedCluster.Status.Rollout)
+	require.NotEmpty(t, updatedCluster.Status.Rollout.UpdateRevision)
+	require.NotEqual(t, rev1Hash, updatedCluster.Status.Rollout.UpdateRevision)
+	require.Equal(t, corev1alpha1.RolloutStatePaused, updatedCluster.Status.Rollout.State)
+	require.Equal(t, int32(4), updatedCluster.Status.Rollout.TotalPods)
+	require.Equal(t, int32(0), updatedCluster.Status.Rollout.UpdatedPods)
+
+	// The rollout should continue once it is resumed
+	updatedCluster.Spec.UpdateStrategy.RollingUpdate.Paused = false
+	k8sClient.Update(context.TODO(), updatedCluster)
 
-	newCluster := &corev1alpha1.StorageCluster{}
-	testutil.Get(k8sClient, newCluster, cluster.Name, cluster.Namespace)
-	require.Equal(t, "Online", newCluster.Status.Phase)
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.ElementsMatch(t,
+		[]string{oldPods[0].Name, oldPods[1].Name, oldPods[2].Name, oldPods[3].Name},
+		podControl.DeletePodName)
+	require.Empty(t, podControl.Templates)
+	require.Equal(t, cluster.UID, clusterRef.UID)
+
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Equal(t, corev1alpha1.RolloutStateProgressing, updatedCluster.Status.Rollout.State)
 }
 
-func TestUpdateClusterStatusErrorFromDriver(t *testing.T) {
+func TestUpdateStorageClusterWithPartition(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -1962,6 +4319,10 @@ func TestUpdateClusterStatusErrorFromDriver(t *testing.T) {
 	cluster := createStorageCluster()
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
 	k8sClient := testutil.FakeK8sClient(cluster)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
@@ -1972,19 +4333,50 @@ func TestUpdateClusterStatusErrorFromDriver(t *testing.T) {
 		recorder:          recorder,
 		kubernetesVersion: k8sVersion,
 	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
-	driver.EXPECT().
-		UpdateStorageClusterStatus(gomock.Any()).
-		Do(func(c *corev1alpha1.StorageCluster) {
-			c.Status.Phase = "Offline"
-		}).
-		Return(fmt.Errorf("update status error"))
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	// Ready pods that are already running on the k8s nodes with the same hash
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	oldPods := make([]*v1.Pod, 0)
+	for i := 1; i <= 4; i++ {
+		k8sNode := createK8sNode(fmt.Sprintf("k8s-node-%d", i), 10)
+		k8sNode.Labels = map[string]string{"rack": fmt.Sprintf("rack-%d", (i+1)%2)}
+		k8sClient.Create(context.TODO(), k8sNode)
+
+		oldPod := createStoragePod(cluster, fmt.Sprintf("old-pod-%d", i), k8sNode.Name, storageLabels)
+		oldPod.Status.Conditions = []v1.PodCondition{
+			{
+				Type:   v1.PodReady,
+				Status: v1.ConditionTrue,
+			},
+		}
+		k8sClient.Create(context.TODO(), oldPod)
+		oldPods = append(oldPods, oldPod)
+	}
+
+	maxUnavailable := intstr.FromInt(4)
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+			MaxUnavailable: &maxUnavailable,
+			Partition: &corev1alpha1.RollingUpdatePartition{
+				Ordinal: int32Ptr(2),
+			},
+		},
+	}
+	cluster.Spec.Image = "test/image:v2"
+	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -1992,20 +4384,61 @@ func TestUpdateClusterStatusErrorFromDriver(t *testing.T) {
 			Namespace: cluster.Namespace,
 		},
 	}
-	result, err := controller.Reconcile(request)
+
+	// Only pods on nodes at or beyond the partition ordinal should be updated
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.ElementsMatch(t, []string{oldPods[2].Name, oldPods[3].Name}, podControl.DeletePodName)
+
+	// Once the pods in the partition are replaced, the rollout is partitioned
+	k8sClient.Delete(context.TODO(), oldPods[2])
+	k8sClient.Delete(context.TODO(), oldPods[3])
+	podControl.Templates = nil
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+	require.Len(t, podControl.Templates, 2)
+
+	for i, nodeName := range []string{"k8s-node-3", "k8s-node-4"} {
+		newPod, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[i], cluster, clusterRef)
+		require.NoError(t, err)
+		newPod.Name = fmt.Sprintf("new-pod-%d", i)
+		newPod.Namespace = cluster.Namespace
+		newPod.Spec.NodeName = nodeName
+		k8sClient.Create(context.TODO(), newPod)
+	}
+	podControl.Templates = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Equal(t, corev1alpha1.RolloutStatePartitioned, updatedCluster.Status.Rollout.State)
+	require.Equal(t, int32(4), updatedCluster.Status.Rollout.TotalPods)
+	require.Equal(t, int32(2), updatedCluster.Status.Rollout.UpdatedPods)
+
+	// Only pods on nodes matching the partition selector should be updated
+	updatedCluster.Spec.UpdateStrategy.RollingUpdate.Partition = &corev1alpha1.RollingUpdatePartition{
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"rack": "rack-0"},
+		},
+	}
+	k8sClient.Update(context.TODO(), updatedCluster)
+
+	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-
-	require.Len(t, recorder.Events, 1)
-	require.Equal(t, <-recorder.Events,
-		fmt.Sprintf("%v %v update status error", v1.EventTypeWarning, util.FailedSyncReason))
-
-	newCluster := &corev1alpha1.StorageCluster{}
-	testutil.Get(k8sClient, newCluster, cluster.Name, cluster.Namespace)
-	require.Equal(t, "Offline", newCluster.Status.Phase)
+	require.Equal(t, []string{oldPods[0].Name}, podControl.DeletePodName)
 }
 
-func TestFailedPreInstallFromDriver(t *testing.T) {
+func TestUpdateStorageClusterWithCanary(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -2013,6 +4446,10 @@ func TestFailedPreInstallFromDriver(t *testing.T) {
 	cluster := createStorageCluster()
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
 	k8sClient := testutil.FakeK8sClient(cluster)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
@@ -2023,11 +4460,50 @@ func TestFailedPreInstallFromDriver(t *testing.T) {
 		recorder:          recorder,
 		kubernetesVersion: k8sVersion,
 	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(fmt.Errorf("pre-install error"))
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	// Ready pods that are already running on the k8s nodes with the same hash
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	oldPods := make([]*v1.Pod, 0)
+	for i := 1; i <= 4; i++ {
+		k8sNode := createK8sNode(fmt.Sprintf("k8s-node-%d", i), 10)
+		k8sNode.Labels = map[string]string{"rack": fmt.Sprintf("rack-%d", (i+1)%2)}
+		k8sClient.Create(context.TODO(), k8sNode)
+
+		oldPod := createStoragePod(cluster, fmt.Sprintf("old-pod-%d", i), k8sNode.Name, storageLabels)
+		oldPod.Status.Conditions = []v1.PodCondition{
+			{
+				Type:   v1.PodReady,
+				Status: v1.ConditionTrue,
+			},
+		}
+		k8sClient.Create(context.TODO(), oldPod)
+		oldPods = append(oldPods, oldPod)
+	}
+
+	maxUnavailable := intstr.FromInt(4)
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+			MaxUnavailable: &maxUnavailable,
+			Canary: &corev1alpha1.CanaryUpdate{
+				Nodes: 1,
+			},
+		},
+	}
+	cluster.Spec.Image = "test/image:v2"
+	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -2035,34 +4511,93 @@ func TestFailedPreInstallFromDriver(t *testing.T) {
 			Namespace: cluster.Namespace,
 		},
 	}
+
+	// Only the canary pods should be updated
 	result, err := controller.Reconcile(request)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "pre-install error")
+	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Len(t, podControl.DeletePodName, 1)
 
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Equal(t, corev1alpha1.RolloutStateProgressing, updatedCluster.Status.Rollout.State)
+
+	// Pods being replaced count towards the canary pods
+	canaryPod := &v1.Pod{}
+	testutil.Get(k8sClient, canaryPod, podControl.DeletePodName[0], cluster.Namespace)
+	deletionTimestamp := metav1.Now()
+	canaryPod.DeletionTimestamp = &deletionTimestamp
+	k8sClient.Update(context.TODO(), canaryPod)
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+
+	// Once the canary pod is replaced, the rollout should wait for promotion
+	k8sClient.Delete(context.TODO(), canaryPod)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+	require.Len(t, podControl.Templates, 1)
+
+	newPod, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
+	require.NoError(t, err)
+	newPod.Name = "new-pod"
+	newPod.Namespace = cluster.Namespace
+	newPod.Spec.NodeName = canaryPod.Spec.NodeName
+	k8sClient.Create(context.TODO(), newPod)
+	podControl.Templates = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Equal(t, corev1alpha1.RolloutStateAwaitingPromotion, updatedCluster.Status.Rollout.State)
+	require.Equal(t, int32(4), updatedCluster.Status.Rollout.TotalPods)
+	require.Equal(t, int32(1), updatedCluster.Status.Rollout.UpdatedPods)
+
+	// Promoting an older revision should not continue the rollout
+	updatedCluster.Annotations = map[string]string{
+		AnnotationPromoteCanary: rev1Hash,
+	}
+	k8sClient.Update(context.TODO(), updatedCluster)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+
+	// Promoting the update revision should update the remaining pods
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	updatedCluster.Annotations[AnnotationPromoteCanary] = updatedCluster.Status.Rollout.UpdateRevision
+	k8sClient.Update(context.TODO(), updatedCluster)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Len(t, podControl.DeletePodName, 3)
+	require.NotContains(t, podControl.DeletePodName, newPod.Name)
 }
 
-func TestUpdateDriverWithInstanceInformation(t *testing.T) {
+func TestUpdateStorageClusterShouldWaitForStorageHealthBeforeUpgrade(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
-
-	// Kubernetes node with resources to create a pod
-	k8sNode1 := createK8sNode("k8s-node-1", 1)
-	k8sNode1.Labels = map[string]string{failureDomainZoneKey: "z1"}
-	k8sNode2 := createK8sNode("k8s-node-2", 1)
-	k8sNode2.Labels = map[string]string{failureDomainZoneKey: "z1"}
-	k8sNode3 := createK8sNode("k8s-node-3", 1)
-	k8sNode3.Labels = map[string]string{failureDomainZoneKey: "z2"}
-
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
-	k8sClient := testutil.FakeK8sClient(cluster, k8sNode1, k8sNode2, k8sNode3)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+	k8sClient := testutil.FakeK8sClient(cluster)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
 	controller := Controller{
@@ -2072,20 +4607,39 @@ func TestUpdateDriverWithInstanceInformation(t *testing.T) {
 		recorder:          recorder,
 		kubernetesVersion: k8sVersion,
 	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
-	expectedDriverInfo := &storage.UpdateDriverInfo{
-		ZoneToInstancesMap: map[string]int{
-			"z1": 2,
-			"z2": 1,
-		},
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+
+	// Ready pods that are already running on the k8s nodes with the same hash
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	for i := 1; i <= 2; i++ {
+		k8sNode := createK8sNode(fmt.Sprintf("k8s-node-%d", i), 10)
+		k8sClient.Create(context.TODO(), k8sNode)
+
+		oldPod := createStoragePod(cluster, fmt.Sprintf("old-pod-%d", i), k8sNode.Name, storageLabels)
+		oldPod.Status.Conditions = []v1.PodCondition{
+			{
+				Type:   v1.PodReady,
+				Status: v1.ConditionTrue,
+			},
+		}
+		k8sClient.Create(context.TODO(), oldPod)
 	}
-	driver.EXPECT().UpdateDriver(expectedDriverInfo).Return(nil)
+
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+	}
+	cluster.Spec.Image = "test/image:v2"
+	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -2093,52 +4647,94 @@ func TestUpdateDriverWithInstanceInformation(t *testing.T) {
 			Namespace: cluster.Namespace,
 		},
 	}
+
+	// No pod should be deleted if the storage cluster is not healthy
+	driver.EXPECT().
+		PreNodeUpgrade(gomock.Any(), "k8s-node-1").
+		Return(fmt.Errorf("replicas are being resynced on node k8s-node-2"))
+
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Empty(t, recorder.Events)
+	require.Empty(t, podControl.DeletePodName)
 
-	// Should contain cloud provider information if any node has it
-	k8sNode2.Spec.ProviderID = "invalid"
-	k8sClient.Update(context.TODO(), k8sNode2)
-	k8sNode3.Spec.ProviderID = "testcloud://test-instance-id"
-	k8sClient.Update(context.TODO(), k8sNode3)
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	condition := getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeUpgrading)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonUpdateBlocked, condition.Reason)
+	require.Equal(t, "Upgrade of node k8s-node-1 is blocked: "+
+		"replicas are being resynced on node k8s-node-2",
+		condition.Message)
+
+	// The pod should be deleted once the storage cluster is healthy
+	driver.EXPECT().
+		PreNodeUpgrade(gomock.Any(), "k8s-node-1").
+		Return(nil)
 
-	expectedDriverInfo.CloudProvider = "testcloud"
-	driver.EXPECT().UpdateDriver(expectedDriverInfo).Return(nil)
-	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{"old-pod-1"}, podControl.DeletePodName)
+
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	condition = getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeUpgrading)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonUpdateInProgress, condition.Reason)
+	require.Equal(t, "Updated 0 of 2 storage pods to revision "+updatedCluster.Status.Rollout.UpdateRevision,
+		condition.Message)
+
+	// The upgrade should be marked complete once all pods are replaced
+	for i := 1; i <= 2; i++ {
+		oldPod := &v1.Pod{}
+		testutil.Get(k8sClient, oldPod, fmt.Sprintf("old-pod-%d", i), cluster.Namespace)
+		k8sClient.Delete(context.TODO(), oldPod)
+	}
+	podControl.DeletePodName = nil
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Empty(t, recorder.Events)
+	require.Len(t, podControl.Templates, 2)
 
-	// Should not fail reconcile even if there is an error on UpdateDriver
-	driver.EXPECT().UpdateDriver(expectedDriverInfo).Return(fmt.Errorf("update error"))
+	for i, template := range podControl.Templates {
+		newPod, err := k8scontroller.GetPodFromTemplate(&template, cluster, clusterRef)
+		require.NoError(t, err)
+		newPod.Name = fmt.Sprintf("new-pod-%d", i+1)
+		newPod.Namespace = cluster.Namespace
+		newPod.Spec.NodeName = fmt.Sprintf("k8s-node-%d", i+1)
+		k8sClient.Create(context.TODO(), newPod)
+	}
+	podControl.Templates = nil
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Empty(t, recorder.Events)
+	require.Empty(t, podControl.DeletePodName)
+
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	condition = getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeUpgrading)
+	require.Equal(t, corev1alpha1.ConditionFalse, condition.Status)
+	require.Equal(t, reasonUpdateCompleted, condition.Reason)
+	require.Equal(t, "Updated all storage pods to revision "+updatedCluster.Status.Rollout.UpdateRevision,
+		condition.Message)
 }
 
-func TestDeleteStorageClusterWithoutFinalizers(t *testing.T) {
+func TestUpdateStorageClusterOneFailureDomainAtATime(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
-	cluster := createStorageCluster()
-	cluster.Finalizers = []string{}
-	deletionTimeStamp := metav1.Now()
-	cluster.DeletionTimestamp = &deletionTimeStamp
-
 	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
 	storageLabels := map[string]string{
 		labelKeyName:       cluster.Name,
 		labelKeyDriverName: driverName,
 	}
-
-	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
-	driver := testutil.MockDriver(mockCtrl)
 	k8sClient := testutil.FakeK8sClient(cluster)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
@@ -2149,67 +4745,184 @@ func TestDeleteStorageClusterWithoutFinalizers(t *testing.T) {
 		recorder:          recorder,
 		kubernetesVersion: k8sVersion,
 	}
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	// Kubernetes node with enough resources to create new pods
-	k8sNode1 := createK8sNode("k8s-node-1", 10)
-	k8sNode2 := createK8sNode("k8s-node-2", 10)
-	k8sNode3 := createK8sNode("k8s-node-3", 10)
+	// Ready pods that are already running on the k8s nodes with the same hash.
+	// The zone of first two nodes is taken from the k8s node labels and the
+	// zone of the last two nodes from the storage node status.
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	for i := 1; i <= 4; i++ {
+		k8sNode := createK8sNode(fmt.Sprintf("k8s-node-%d", i), 10)
+		if i <= 2 {
+			k8sNode.Labels = map[string]string{
+				"failure-domain.beta.kubernetes.io/zone": "zone-a",
+			}
+		} else {
+			storageNode := &corev1alpha1.StorageNode{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      k8sNode.Name,
+					Namespace: cluster.Namespace,
+				},
+				Status: corev1alpha1.NodeStatus{
+					Geo: corev1alpha1.Geography{
+						Zone: "zone-b",
+					},
+				},
+			}
+			k8sClient.Create(context.TODO(), storageNode)
+		}
+		k8sClient.Create(context.TODO(), k8sNode)
 
-	// Only pods that are associated with this cluster should get deleted
-	storagePod1 := createStoragePod(cluster, "pod-1", k8sNode1.Name, storageLabels)
-	storagePod2 := createStoragePod(cluster, "pod-2", k8sNode2.Name, storageLabels)
+		oldPod := createStoragePod(cluster, fmt.Sprintf("old-pod-%d", i), k8sNode.Name, storageLabels)
+		oldPod.Status.Conditions = []v1.PodCondition{
+			{
+				Type:   v1.PodReady,
+				Status: v1.ConditionTrue,
+			},
+		}
+		k8sClient.Create(context.TODO(), oldPod)
+	}
 
-	storagePod3 := createStoragePod(cluster, "pod-3", k8sNode3.Name, nil)
-	storagePod3.OwnerReferences = nil
+	// Start with an unavailable pod in zone-b
+	oldPod := &v1.Pod{}
+	testutil.Get(k8sClient, oldPod, "old-pod-3", cluster.Namespace)
+	oldPod.Status.Conditions[0].Status = v1.ConditionFalse
+	k8sClient.Update(context.TODO(), oldPod)
 
-	otherCluster := createStorageCluster()
-	otherCluster.UID = "other-uid"
-	otherCluster.Name = "other-cluster"
-	storagePod4 := createStoragePod(otherCluster, "pod-4", k8sNode3.Name, storageLabels)
+	maxUnavailable := intstr.FromInt(1)
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+			MaxUnavailable: &maxUnavailable,
+			FailureDomain:  corev1alpha1.FailureDomainZone,
+		},
+	}
+	cluster.Spec.Image = "test/image:v2"
+	k8sClient.Update(context.TODO(), cluster)
 
-	k8sClient.Create(context.TODO(), k8sNode1)
-	k8sClient.Create(context.TODO(), k8sNode2)
-	k8sClient.Create(context.TODO(), k8sNode3)
-	k8sClient.Create(context.TODO(), storagePod1)
-	k8sClient.Create(context.TODO(), storagePod2)
-	k8sClient.Create(context.TODO(), storagePod3)
-	k8sClient.Create(context.TODO(), storagePod4)
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+
+	// deletePod removes the old pod and lets the controller create its replacement
+	deletePod := func(podName string) {
+		oldPod := &v1.Pod{}
+		testutil.Get(k8sClient, oldPod, podName, cluster.Namespace)
+		k8sClient.Delete(context.TODO(), oldPod)
+		podControl.DeletePodName = nil
+
+		result, err := controller.Reconcile(request)
+		require.NoError(t, err)
+		require.Empty(t, result)
+		require.Empty(t, podControl.DeletePodName)
+		require.Len(t, podControl.Templates, 1)
+	}
+
+	// createNewPod creates the pod from the template of the replacement pod
+	createNewPod := func(podName, nodeName string, ready bool) {
+		newPod, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
+		require.NoError(t, err)
+		newPod.Name = podName
+		newPod.Namespace = cluster.Namespace
+		newPod.Spec.NodeName = nodeName
+		newPod.Status.Conditions = []v1.PodCondition{
+			{
+				Type:   v1.PodReady,
+				Status: v1.ConditionFalse,
+			},
+		}
+		if ready {
+			newPod.Status.Conditions[0].Status = v1.ConditionTrue
+		}
+		k8sClient.Create(context.TODO(), newPod)
+		podControl.Templates = nil
+		podControl.DeletePodName = nil
+	}
+
+	// The unavailable pod is replaced first, without taking down other pods
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{"old-pod-3"}, podControl.DeletePodName)
+
+	deletePod("old-pod-3")
+	createNewPod("new-pod-3", "k8s-node-3", true)
+
+	// The partially updated zone should be finished before moving to other zones
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{"old-pod-4"}, podControl.DeletePodName)
 
-	request := reconcile.Request{
-		NamespacedName: types.NamespacedName{
-			Name:      cluster.Name,
-			Namespace: cluster.Namespace,
-		},
-	}
-	result, err := controller.Reconcile(request)
+	// Pods of other zones should not be updated until all pods in the zone
+	// being updated are available
+	deletePod("old-pod-4")
+
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	condition := getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeUpgrading)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonUpdateBlocked, condition.Reason)
+	require.Equal(t, "Upgrade is waiting for storage pods in zone \"zone-b\" to become available",
+		condition.Message)
+
+	createNewPod("new-pod-4", "k8s-node-4", false)
+
+	// Pods should not be taken down if pods are unavailable in multiple zones
+	oldPod = &v1.Pod{}
+	testutil.Get(k8sClient, oldPod, "old-pod-1", cluster.Namespace)
+	oldPod.Status.Conditions[0].Status = v1.ConditionFalse
+	k8sClient.Update(context.TODO(), oldPod)
+
+	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Equal(t, []string{"old-pod-1"}, podControl.DeletePodName)
 
-	require.Empty(t, recorder.Events)
-	require.Empty(t, podControl.Templates)
-	require.ElementsMatch(t,
-		[]string{storagePod1.Name, storagePod2.Name},
-		podControl.DeletePodName,
-	)
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	condition = getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeUpgrading)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonUpdateBlocked, condition.Reason)
+	require.Equal(t, "Upgrade is blocked as storage pods are unavailable in multiple zones [zone-a zone-b]",
+		condition.Message)
+
+	// Once zone-b is updated, the pods in zone-a should be updated
+	newPod := &v1.Pod{}
+	testutil.Get(k8sClient, newPod, "new-pod-4", cluster.Namespace)
+	newPod.Status.Conditions[0].Status = v1.ConditionTrue
+	k8sClient.Update(context.TODO(), newPod)
+	oldPod.Status.Conditions[0].Status = v1.ConditionTrue
+	k8sClient.Update(context.TODO(), oldPod)
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Len(t, podControl.DeletePodName, 1)
+	require.Contains(t, []string{"old-pod-1", "old-pod-2"}, podControl.DeletePodName[0])
 }
 
-func TestDeleteStorageClusterWithFinalizers(t *testing.T) {
+func TestRollbackStorageCluster(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
-	cluster := createStorageCluster()
-	deletionTimeStamp := metav1.Now()
-	cluster.DeletionTimestamp = &deletionTimeStamp
-
 	driverName := "mock-driver"
-	storageLabels := map[string]string{
-		labelKeyName:       cluster.Name,
-		labelKeyDriverName: driverName,
-	}
-
+	cluster := createStorageCluster()
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
 	k8sClient := testutil.FakeK8sClient(cluster)
@@ -2223,17 +4936,35 @@ func TestDeleteStorageClusterWithFinalizers(t *testing.T) {
 		kubernetesVersion: k8sVersion,
 	}
 
-	// Empty delete condition should not remove finalizer
-	driver.EXPECT().DeleteStorage(gomock.Any()).Return(nil, nil)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 
-	k8sNode := createK8sNode("k8s-node", 10)
-	storagePod := createStoragePod(cluster, "storage-pod", k8sNode.Name, storageLabels)
+	// Create revisions for the older specs of the cluster, the last one being
+	// the current spec of the cluster
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.OnDeleteStorageClusterStrategyType,
+	}
+	maxUnavailable := intstr.FromInt(2)
+	for i, image := range []string{"test/image:v1", "test/image:v2", "test/image:v3"} {
+		if i == 2 {
+			cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+				Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+				RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+					MaxUnavailable: &maxUnavailable,
+				},
+			}
+		}
+		cluster.Spec.Image = image
+		history, err := getRevision(k8sClient, cluster, driverName)
+		require.NoError(t, err)
+		history.Revision = int64(i + 1)
+		err = k8sClient.Create(context.TODO(), history)
+		require.NoError(t, err)
+	}
 
-	k8sClient.Create(context.TODO(), k8sNode)
-	k8sClient.Create(context.TODO(), storagePod)
+	cluster.Spec.RollbackTo = &corev1alpha1.RollbackConfig{}
+	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -2241,107 +4972,109 @@ func TestDeleteStorageClusterWithFinalizers(t *testing.T) {
 			Namespace: cluster.Namespace,
 		},
 	}
-	result, err := controller.Reconcile(request)
-	require.NoError(t, err)
-	require.Empty(t, result)
-	require.Empty(t, recorder.Events)
-
-	require.Empty(t, podControl.Templates)
-	require.ElementsMatch(t, []string{storagePod.Name}, podControl.DeletePodName)
-
-	updatedCluster := &corev1alpha1.StorageCluster{}
-	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
-	require.Len(t, updatedCluster.Status.Conditions, 1)
-	require.Equal(t, corev1alpha1.ClusterConditionTypeDelete, updatedCluster.Status.Conditions[0].Type)
-	require.Equal(t, corev1alpha1.ClusterOperationInProgress, updatedCluster.Status.Conditions[0].Status)
-	require.Equal(t, "DeleteInProgress", updatedCluster.Status.Phase)
-	require.Equal(t, []string{deleteFinalizerName}, updatedCluster.Finalizers)
 
-	// If storage driver returns error, then controller should not return error but raise an event
-	driver.EXPECT().DeleteStorage(gomock.Any()).Return(nil, fmt.Errorf("delete error"))
+	// Revision 0 should roll back to the last revision before the current one.
+	// The update strategy should not be rolled back.
+	driver.EXPECT().
+		ValidateRollback(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(current, target *corev1alpha1.StorageCluster) error {
+			require.Equal(t, "test/image:v3", current.Spec.Image)
+			require.Equal(t, "test/image:v2", target.Spec.Image)
+			return nil
+		})
 
-	result, err = controller.Reconcile(request)
+	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
 
-	require.Len(t, recorder.Events, 1)
-	raisedEvent := <-recorder.Events
-	require.Contains(t, raisedEvent,
-		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
-	require.Contains(t, raisedEvent, "delete error")
-
+	updatedCluster := &corev1alpha1.StorageCluster{}
 	updatedCluster = &corev1alpha1.StorageCluster{}
 	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
-	require.Len(t, updatedCluster.Status.Conditions, 1)
-	require.Equal(t, corev1alpha1.ClusterConditionTypeDelete, updatedCluster.Status.Conditions[0].Type)
-	require.Equal(t, corev1alpha1.ClusterOperationInProgress, updatedCluster.Status.Conditions[0].Status)
-	require.Equal(t, "DeleteInProgress", updatedCluster.Status.Phase)
-	require.Equal(t, []string{deleteFinalizerName}, updatedCluster.Finalizers)
+	require.Equal(t, "test/image:v2", updatedCluster.Spec.Image)
+	require.Nil(t, updatedCluster.Spec.RollbackTo)
+	require.Equal(t, cluster.Spec.UpdateStrategy, updatedCluster.Spec.UpdateStrategy)
+	condition := getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeUpgrading)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonRolledBack, condition.Reason)
+	require.Equal(t, "Rolled back StorageCluster to revision 2", condition.Message)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t, fmt.Sprintf("%v %v Rolled back StorageCluster to revision 2",
+		v1.EventTypeNormal, util.RollbackDoneReason), <-recorder.Events)
 
-	// If delete condition is not present already, then add to the cluster
-	updatedCluster.Status.Conditions = []corev1alpha1.ClusterCondition{
-		{
-			Type: corev1alpha1.ClusterConditionTypeInstall,
-		},
-	}
+	// The spec should not be changed if the driver does not allow the rollback
+	updatedCluster.Spec.RollbackTo = &corev1alpha1.RollbackConfig{Revision: 1}
 	k8sClient.Update(context.TODO(), updatedCluster)
-	condition := &corev1alpha1.ClusterCondition{
-		Type:   corev1alpha1.ClusterConditionTypeDelete,
-		Status: corev1alpha1.ClusterOperationFailed,
-	}
-	driver.EXPECT().DeleteStorage(gomock.Any()).Return(condition, nil)
+
+	driver.EXPECT().
+		ValidateRollback(gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("incompatible rollback"))
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Empty(t, recorder.Events)
 
 	updatedCluster = &corev1alpha1.StorageCluster{}
 	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
-	require.Len(t, updatedCluster.Status.Conditions, 2)
-	require.Equal(t, *condition, updatedCluster.Status.Conditions[1])
-	require.Equal(t, "DeleteFailed", updatedCluster.Status.Phase)
-	require.Equal(t, []string{deleteFinalizerName}, updatedCluster.Finalizers)
+	require.Equal(t, "test/image:v2", updatedCluster.Spec.Image)
+	require.Nil(t, updatedCluster.Spec.RollbackTo)
+	condition = getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeDegraded)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonRollbackFailed, condition.Reason)
+	require.Equal(t, "Failed to roll back StorageCluster: incompatible rollback", condition.Message)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t, fmt.Sprintf("%v %v Failed to roll back StorageCluster: incompatible rollback",
+		v1.EventTypeWarning, util.FailedRollbackReason), <-recorder.Events)
 
-	// If delete condition is present, then update it
-	condition = &corev1alpha1.ClusterCondition{
-		Type:   corev1alpha1.ClusterConditionTypeDelete,
-		Status: corev1alpha1.ClusterOperationTimeout,
-	}
-	driver.EXPECT().DeleteStorage(gomock.Any()).Return(condition, nil)
+	// Rollback to a specific revision
+	updatedCluster.Spec.RollbackTo = &corev1alpha1.RollbackConfig{Revision: 1}
+	k8sClient.Update(context.TODO(), updatedCluster)
+
+	driver.EXPECT().
+		ValidateRollback(gomock.Any(), gomock.Any()).
+		Return(nil)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Empty(t, recorder.Events)
 
 	updatedCluster = &corev1alpha1.StorageCluster{}
 	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
-	require.Len(t, updatedCluster.Status.Conditions, 2)
-	require.Equal(t, *condition, updatedCluster.Status.Conditions[1])
-	require.Equal(t, "DeleteTimeout", updatedCluster.Status.Phase)
-	require.Equal(t, []string{deleteFinalizerName}, updatedCluster.Finalizers)
+	require.Equal(t, "test/image:v1", updatedCluster.Spec.Image)
+	require.Nil(t, updatedCluster.Spec.RollbackTo)
+	require.Equal(t, cluster.Spec.UpdateStrategy, updatedCluster.Spec.UpdateStrategy)
+	condition = getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeUpgrading)
+	require.Equal(t, reasonRolledBack, condition.Reason)
+	require.Equal(t, "Rolled back StorageCluster to revision 1", condition.Message)
+	require.Len(t, recorder.Events, 1)
+	<-recorder.Events
 
-	// If delete condition status is completed, then remove delete finalizer
-	condition = &corev1alpha1.ClusterCondition{
-		Type:   corev1alpha1.ClusterConditionTypeDelete,
-		Status: corev1alpha1.ClusterOperationCompleted,
-	}
-	driver.EXPECT().DeleteStorage(gomock.Any()).Return(condition, nil)
+	// Rollback to a revision that does not exist
+	updatedCluster.Spec.RollbackTo = &corev1alpha1.RollbackConfig{Revision: 5}
+	k8sClient.Update(context.TODO(), updatedCluster)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Empty(t, recorder.Events)
 
 	updatedCluster = &corev1alpha1.StorageCluster{}
 	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
-	require.Len(t, updatedCluster.Status.Conditions, 2)
-	require.Equal(t, *condition, updatedCluster.Status.Conditions[1])
-	require.Empty(t, updatedCluster.Finalizers)
+	require.Equal(t, "test/image:v1", updatedCluster.Spec.Image)
+	require.Nil(t, updatedCluster.Spec.RollbackTo)
+	condition = getClusterCondition(updatedCluster, corev1alpha1.ClusterConditionTypeDegraded)
+	require.Equal(t, corev1alpha1.ConditionTrue, condition.Status)
+	require.Equal(t, reasonRollbackFailed, condition.Reason)
+	require.Equal(t, "Failed to roll back StorageCluster: unable to find revision 5 to roll back to",
+		condition.Message)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t, fmt.Sprintf("%v %v Failed to roll back StorageCluster: "+
+		"unable to find revision 5 to roll back to",
+		v1.EventTypeWarning, util.FailedRollbackReason), <-recorder.Events)
 }
 
-func TestUpdateStorageClusterWithRollingUpdateStrategy(t *testing.T) {
+func TestUpdateStorageClusterShouldDrainNodes(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -2349,11 +5082,17 @@ func TestUpdateStorageClusterWithRollingUpdateStrategy(t *testing.T) {
 	cluster := createStorageCluster()
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
 	k8sClient := testutil.FakeK8sClient(cluster)
+	kubeClient := fakek8sclient.NewSimpleClientset()
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
 	controller := Controller{
 		client:            k8sClient,
+		kubeClient:        kubeClient,
 		Driver:            driver,
 		podControl:        podControl,
 		recorder:          recorder,
@@ -2369,8 +5108,59 @@ func TestUpdateStorageClusterWithRollingUpdateStrategy(t *testing.T) {
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
 
-	k8sNode := createK8sNode("k8s-node", 10)
-	k8sClient.Create(context.TODO(), k8sNode)
+	// The first eviction is blocked by the PodDisruptionBudget of the pod
+	var evictedPods []string
+	kubeClient.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		if len(evictedPods) == 0 {
+			evictedPods = append(evictedPods, "")
+			return true, nil, errors.NewTooManyRequests("Cannot evict pod as it would "+
+				"violate the pod's disruption budget.", 10)
+		}
+		evictedPods = append(evictedPods, eviction.Namespace+"/"+eviction.Name)
+		return true, nil, nil
+	})
+
+	// Ready pods that are already running on the k8s nodes with the same hash
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	for i := 1; i <= 2; i++ {
+		k8sNode := createK8sNode(fmt.Sprintf("k8s-node-%d", i), 10)
+		k8sClient.Create(context.TODO(), k8sNode)
+
+		oldPod := createStoragePod(cluster, fmt.Sprintf("old-pod-%d", i), k8sNode.Name, storageLabels)
+		oldPod.Status.Conditions = []v1.PodCondition{
+			{
+				Type:   v1.PodReady,
+				Status: v1.ConditionTrue,
+			},
+		}
+		k8sClient.Create(context.TODO(), oldPod)
+
+		storageNode := &corev1alpha1.StorageNode{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      k8sNode.Name,
+				Namespace: cluster.Namespace,
+			},
+			Status: corev1alpha1.NodeStatus{
+				Phase: string(corev1alpha1.NodeOnlineStatus),
+			},
+		}
+		k8sClient.Create(context.TODO(), storageNode)
+	}
+
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+			DrainNodes: true,
+		},
+	}
+	cluster.Spec.Image = "test/image:v2"
+	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -2378,103 +5168,202 @@ func TestUpdateStorageClusterWithRollingUpdateStrategy(t *testing.T) {
 			Namespace: cluster.Namespace,
 		},
 	}
+	appPod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-pod",
+			Namespace: "app",
+		},
+		Spec: v1.PodSpec{
+			NodeName: "k8s-node-1",
+		},
+	}
+
+	// The node should be cordoned and the storage pod should not be deleted
+	// as the eviction of the application pod is blocked
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), "k8s-node-1").Return(nil).Times(1)
+	driver.EXPECT().
+		GetPodsUsingStorage(gomock.Any(), "k8s-node-1").
+		Return([]v1.Pod{appPod}, nil).
+		Times(2)
+
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
 
-	revisions := &appsv1.ControllerRevisionList{}
-	err = testutil.List(k8sClient, revisions)
+	k8sNode := &v1.Node{}
+	testutil.Get(k8sClient, k8sNode, "k8s-node-1", "")
+	require.True(t, k8sNode.Spec.Unschedulable)
+	require.Equal(t, "true", k8sNode.Annotations[annotationNodeDrain])
+
+	storageNode := &corev1alpha1.StorageNode{}
+	testutil.Get(k8sClient, storageNode, "k8s-node-1", cluster.Namespace)
+	require.Len(t, storageNode.Status.Conditions, 1)
+	require.Equal(t, corev1alpha1.NodeUpgradeCondition, storageNode.Status.Conditions[0].Type)
+	require.Equal(t, corev1alpha1.NodeDrainingStatus, storageNode.Status.Conditions[0].Status)
+	require.Equal(t, "Evicting 1 pods using storage volumes. "+
+		"Eviction of pods [app/app-pod] is blocked by their PodDisruptionBudgets",
+		storageNode.Status.Conditions[0].Message)
+
+	// The eviction should be retried in the next reconcile. The health of the
+	// cluster should not be checked again for the node being drained
+	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
-	require.Len(t, revisions.Items, 1)
-	require.Equal(t, int64(1), revisions.Items[0].Revision)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+	require.Equal(t, []string{"", "app/app-pod"}, evictedPods)
 
-	require.Len(t, podControl.ControllerRefs, 1)
-	require.Equal(t, *clusterRef, podControl.ControllerRefs[0])
+	storageNode = &corev1alpha1.StorageNode{}
+	testutil.Get(k8sClient, storageNode, "k8s-node-1", cluster.Namespace)
+	require.Equal(t, corev1alpha1.NodeDrainingStatus, storageNode.Status.Conditions[0].Status)
+	require.Equal(t, "Evicting 1 pods using storage volumes", storageNode.Status.Conditions[0].Message)
 
-	// Verify that the revision hash matches that of the new pod
-	require.Len(t, podControl.Templates, 1)
-	require.Equal(t, revisions.Items[0].Labels[defaultStorageClusterUniqueLabelKey],
-		podControl.Templates[0].Labels[defaultStorageClusterUniqueLabelKey])
+	// The storage pod should be deleted once the node is drained
+	driver.EXPECT().
+		GetPodsUsingStorage(gomock.Any(), "k8s-node-1").
+		Return([]v1.Pod{}, nil).
+		Times(1)
 
-	// Test case: Changing the cluster spec -
-	// A new revision should be created for the new cluster spec
-	// Also the pod should be changed with the updated spec
-	cluster.Spec.Image = "new/image"
-	k8sClient.Update(context.TODO(), cluster)
-	oldPod, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
+	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
-	oldPod.Name = oldPod.GenerateName + "1"
-	oldPod.Namespace = cluster.Namespace
-	oldPod.Spec.NodeName = k8sNode.Name
-	oldPod.Status.Conditions = append(oldPod.Status.Conditions, v1.PodCondition{
-		Type:   v1.PodReady,
-		Status: v1.ConditionTrue,
-	})
-	k8sClient.Create(context.TODO(), oldPod)
+	require.Empty(t, result)
+	require.Equal(t, []string{"old-pod-1"}, podControl.DeletePodName)
 
-	// Reset the fake pod controller
-	podControl.Templates = nil
-	podControl.ControllerRefs = nil
+	storageNode = &corev1alpha1.StorageNode{}
+	testutil.Get(k8sClient, storageNode, "k8s-node-1", cluster.Namespace)
+	require.Equal(t, corev1alpha1.NodeRestartingStatus, storageNode.Status.Conditions[0].Status)
+
+	k8sNode = &v1.Node{}
+	testutil.Get(k8sClient, k8sNode, "k8s-node-1", "")
+	require.True(t, k8sNode.Spec.Unschedulable)
+
+	// The node should not be uncordoned until the new storage pod is ready
+	// and the storage node is online
+	oldPod := &v1.Pod{}
+	testutil.Get(k8sClient, oldPod, "old-pod-1", cluster.Namespace)
+	k8sClient.Delete(context.TODO(), oldPod)
 	podControl.DeletePodName = nil
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Len(t, podControl.Templates, 1)
 
-	// New revision should be created for the updated cluster spec
-	revisions = &appsv1.ControllerRevisionList{}
-	err = testutil.List(k8sClient, revisions)
+	newPod, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
 	require.NoError(t, err)
-	require.Len(t, revisions.Items, 2)
-	require.Equal(t, int64(2), revisions.Items[1].Revision)
+	newPod.Name = "new-pod-1"
+	newPod.Namespace = cluster.Namespace
+	newPod.Spec.NodeName = "k8s-node-1"
+	newPod.Status.Conditions = []v1.PodCondition{
+		{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
+		},
+	}
+	k8sClient.Create(context.TODO(), newPod)
+	podControl.Templates = nil
 
-	// The old pod should be marked for deletion
-	require.Empty(t, podControl.Templates)
-	require.Empty(t, podControl.ControllerRefs)
-	require.Len(t, podControl.DeletePodName, 1)
-	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
+	storageNode = &corev1alpha1.StorageNode{}
+	testutil.Get(k8sClient, storageNode, "k8s-node-1", cluster.Namespace)
+	storageNode.Status.Phase = string(corev1alpha1.NodeInitStatus)
+	k8sClient.Status().Update(context.TODO(), storageNode)
 
-	// Test case: Running reconcile again should start a new pod with new
-	// revision hash.
-	err = k8sClient.Delete(context.TODO(), oldPod)
+	driver.EXPECT().
+		PreNodeUpgrade(gomock.Any(), "k8s-node-2").
+		Return(fmt.Errorf("storage cluster is not healthy")).
+		Times(1)
+
+	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
-	podControl.Templates = nil
-	podControl.ControllerRefs = nil
-	podControl.DeletePodName = nil
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+
+	k8sNode = &v1.Node{}
+	testutil.Get(k8sClient, k8sNode, "k8s-node-1", "")
+	require.True(t, k8sNode.Spec.Unschedulable)
+
+	// The node should be uncordoned once the storage node is online, even
+	// if the rollout is paused
+	storageNode.Status.Phase = string(corev1alpha1.NodeOnlineStatus)
+	k8sClient.Status().Update(context.TODO(), storageNode)
+
+	cluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, cluster, request.Name, request.Namespace)
+	cluster.Spec.UpdateStrategy.RollingUpdate.Paused = true
+	k8sClient.Update(context.TODO(), cluster)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
 
-	// New revision should not be created as the cluster spec is unchanged
-	revisions = &appsv1.ControllerRevisionList{}
-	err = testutil.List(k8sClient, revisions)
+	k8sNode = &v1.Node{}
+	testutil.Get(k8sClient, k8sNode, "k8s-node-1", "")
+	require.False(t, k8sNode.Spec.Unschedulable)
+	require.NotContains(t, k8sNode.Annotations, annotationNodeDrain)
+
+	storageNode = &corev1alpha1.StorageNode{}
+	testutil.Get(k8sClient, storageNode, "k8s-node-1", cluster.Namespace)
+	require.Equal(t, corev1alpha1.NodeSucceededStatus, storageNode.Status.Conditions[0].Status)
+	require.Equal(t, "Storage pod is updated and the node is online",
+		storageNode.Status.Conditions[0].Message)
+
+	// A node that was already cordoned should stay cordoned after the update
+	k8sNode = &v1.Node{}
+	testutil.Get(k8sClient, k8sNode, "k8s-node-2", "")
+	k8sNode.Spec.Unschedulable = true
+	k8sClient.Update(context.TODO(), k8sNode)
+
+	cluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, cluster, request.Name, request.Namespace)
+	cluster.Spec.UpdateStrategy.RollingUpdate.Paused = false
+	k8sClient.Update(context.TODO(), cluster)
+
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), "k8s-node-2").Return(nil).Times(1)
+	driver.EXPECT().
+		GetPodsUsingStorage(gomock.Any(), "k8s-node-2").
+		Return([]v1.Pod{}, nil).
+		Times(1)
+
+	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
-	require.Len(t, revisions.Items, 2)
+	require.Empty(t, result)
+	require.Equal(t, []string{"old-pod-2"}, podControl.DeletePodName)
 
-	require.Empty(t, podControl.DeletePodName)
+	k8sNode = &v1.Node{}
+	testutil.Get(k8sClient, k8sNode, "k8s-node-2", "")
+	require.Equal(t, "false", k8sNode.Annotations[annotationNodeDrain])
 
-	require.Len(t, podControl.ControllerRefs, 1)
-	require.Equal(t, *clusterRef, podControl.ControllerRefs[0])
+	oldPod = &v1.Pod{}
+	testutil.Get(k8sClient, oldPod, "old-pod-2", cluster.Namespace)
+	k8sClient.Delete(context.TODO(), oldPod)
+	newPod = newPod.DeepCopy()
+	newPod.ResourceVersion = ""
+	newPod.Name = "new-pod-2"
+	newPod.Spec.NodeName = "k8s-node-2"
+	k8sClient.Create(context.TODO(), newPod)
 
-	// New revision's hash should match that of the new pod.
-	require.Len(t, podControl.Templates, 1)
-	require.Equal(t, revisions.Items[1].Labels[defaultStorageClusterUniqueLabelKey],
-		podControl.Templates[0].Labels[defaultStorageClusterUniqueLabelKey])
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	k8sNode = &v1.Node{}
+	testutil.Get(k8sClient, k8sNode, "k8s-node-2", "")
+	require.True(t, k8sNode.Spec.Unschedulable)
+	require.NotContains(t, k8sNode.Annotations, annotationNodeDrain)
 }
 
-func TestUpdateStorageClusterShouldNotExceedMaxUnavailable(t *testing.T) {
+func TestUpdateStorageClusterPodStatus(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
+	cluster.Generation = 5
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.OnDeleteStorageClusterStrategyType,
+	}
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
-	storageLabels := map[string]string{
-		labelKeyName:       cluster.Name,
-		labelKeyDriverName: driverName,
-	}
 	k8sClient := testutil.FakeK8sClient(cluster)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
@@ -2485,7 +5374,6 @@ func TestUpdateStorageClusterShouldNotExceedMaxUnavailable(t *testing.T) {
 		recorder:          recorder,
 		kubernetesVersion: k8sVersion,
 	}
-	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
 	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
@@ -2495,54 +5383,34 @@ func TestUpdateStorageClusterShouldNotExceedMaxUnavailable(t *testing.T) {
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
 
-	k8sNode1 := createK8sNode("k8s-node-1", 10)
-	k8sNode2 := createK8sNode("k8s-node-2", 10)
-	k8sNode3 := createK8sNode("k8s-node-3", 10)
-	k8sNode4 := createK8sNode("k8s-node-4", 10)
-	k8sClient.Create(context.TODO(), k8sNode1)
-	k8sClient.Create(context.TODO(), k8sNode2)
-	k8sClient.Create(context.TODO(), k8sNode3)
-	k8sClient.Create(context.TODO(), k8sNode4)
+	k8sClient.Create(context.TODO(), createK8sNode("k8s-node-1", 10))
+	k8sClient.Create(context.TODO(), createK8sNode("k8s-node-2", 10))
+	k8sClient.Create(context.TODO(), createK8sNode("k8s-node-3", 10))
 
-	// Pods that are already running on the k8s nodes with same hash
-	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	hash, err := createRevision(k8sClient, cluster, driverName)
 	require.NoError(t, err)
-	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
-	oldPod1 := createStoragePod(cluster, "old-pod-1", k8sNode1.Name, storageLabels)
-	oldPod1.Status.Conditions = []v1.PodCondition{
-		{
-			Type:   v1.PodReady,
-			Status: v1.ConditionTrue,
-		},
-	}
-
-	oldPod2 := oldPod1.DeepCopy()
-	oldPod2.Name = "old-pod-2"
-	oldPod2.Spec.NodeName = k8sNode2.Name
-
-	oldPod3 := oldPod1.DeepCopy()
-	oldPod3.Name = "old-pod-3"
-	oldPod3.Spec.NodeName = k8sNode3.Name
-
-	oldPod4 := oldPod1.DeepCopy()
-	oldPod4.Name = "old-pod-4"
-	oldPod4.Spec.NodeName = k8sNode4.Name
 
-	k8sClient.Create(context.TODO(), oldPod1)
-	k8sClient.Create(context.TODO(), oldPod2)
-	k8sClient.Create(context.TODO(), oldPod3)
-	k8sClient.Create(context.TODO(), oldPod4)
+	// Updated and ready pod on the first node
+	storageLabels := map[string]string{
+		labelKeyName:                        cluster.Name,
+		labelKeyDriverName:                  driverName,
+		defaultStorageClusterUniqueLabelKey: hash,
+	}
+	updatedPod := createStoragePod(cluster, "updated-pod", "k8s-node-1", storageLabels)
+	updatedPod.Status.Conditions = []v1.PodCondition{{
+		Type:   v1.PodReady,
+		Status: v1.ConditionTrue,
+	}}
+	k8sClient.Create(context.TODO(), updatedPod)
 
-	// Should delete pods only up to maxUnavailable value. In this case - 2 pods
-	maxUnavailable := intstr.FromInt(2)
-	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
-		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
-		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
-			MaxUnavailable: &maxUnavailable,
-		},
+	// Old pod on the second node that is not ready yet
+	oldLabels := map[string]string{
+		labelKeyName:                        cluster.Name,
+		labelKeyDriverName:                  driverName,
+		defaultStorageClusterUniqueLabelKey: "old-hash",
 	}
-	cluster.Spec.Image = "test/image:v2"
-	k8sClient.Update(context.TODO(), cluster)
+	oldPod := createStoragePod(cluster, "old-pod", "k8s-node-2", oldLabels)
+	k8sClient.Create(context.TODO(), oldPod)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -2553,103 +5421,107 @@ func TestUpdateStorageClusterShouldNotExceedMaxUnavailable(t *testing.T) {
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Len(t, podControl.DeletePodName, 2)
-
-	// Next reconcile loop should not delete more pods as 2 are already down.
-	// If a pod is marked for deletion but not actually deleted do not send it
-	// for deletion again.
-	deletedPods := make([]*v1.Pod, 0)
-	deletedPod1 := &v1.Pod{}
-	testutil.Get(k8sClient, deletedPod1, podControl.DeletePodName[0], cluster.Namespace)
-	deletedPods = append(deletedPods, deletedPod1)
-	k8sClient.Delete(context.TODO(), deletedPod1)
-
-	deletedPod2 := &v1.Pod{}
-	testutil.Get(k8sClient, deletedPod2, podControl.DeletePodName[1], cluster.Namespace)
-	deletionTimestamp := metav1.Now()
-	deletedPod2.DeletionTimestamp = &deletionTimestamp
-	deletedPod2.Status.Conditions[0].Status = v1.ConditionFalse
-	k8sClient.Update(context.TODO(), deletedPod2)
-
-	podControl.Templates = nil
-	podControl.DeletePodName = nil
 
-	result, err = controller.Reconcile(request)
-	require.NoError(t, err)
-	require.Empty(t, result)
-	require.Empty(t, podControl.DeletePodName)
-	// One pod is still being deleted, so only 1 template created instead of 2
+	// A new pod should be created only on the third node
 	require.Len(t, podControl.Templates, 1)
+	require.Empty(t, podControl.DeletePodName)
 
-	// If the pods are created but not ready, even then no extra pod should be deleted
-	replacedPod1, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	err = testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), updatedCluster.Status.ObservedGeneration)
+	require.Equal(t, int32(3), updatedCluster.Status.DesiredNumberScheduled)
+	require.Equal(t, int32(2), updatedCluster.Status.CurrentNumberScheduled)
+	require.Equal(t, int32(1), updatedCluster.Status.NumberReady)
+	require.Equal(t, int32(1), updatedCluster.Status.NumberAvailable)
+	require.Equal(t, int32(1), updatedCluster.Status.UpdatedNumberScheduled)
+	require.Equal(t, hash, updatedCluster.Status.UpdateRevision)
+	require.Equal(t, "old-hash", updatedCluster.Status.CurrentRevision)
+
+	// Once all the pods are updated, the current revision should be the
+	// same as the update revision
+	err = k8sClient.Delete(context.TODO(), oldPod)
 	require.NoError(t, err)
-	replacedPod1.Name = replacedPod1.GenerateName + "replaced-1"
-	replacedPod1.Namespace = cluster.Namespace
-	replacedPod1.Spec.NodeName = deletedPods[0].Spec.NodeName
-	k8sClient.Create(context.TODO(), replacedPod1)
-
-	k8sClient.Delete(context.TODO(), deletedPod2)
-	deletedPods = append(deletedPods, deletedPod2)
-
+	for _, nodeName := range []string{"k8s-node-2", "k8s-node-3"} {
+		pod := createStoragePod(cluster, "pod-"+nodeName, nodeName, storageLabels)
+		pod.Status.Conditions = []v1.PodCondition{{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
+		}}
+		k8sClient.Create(context.TODO(), pod)
+	}
 	podControl.Templates = nil
-	podControl.DeletePodName = nil
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Empty(t, podControl.DeletePodName)
-	require.Len(t, podControl.Templates, 1)
+	require.Empty(t, podControl.Templates)
 
-	// If another update happens to storage cluster, we should account for non-running
-	// and non-ready pods in max unavailable pods. Also we should delete an old not ready
-	// pod before a running one.
-	cluster.Spec.Image = "test/image:v3"
-	k8sClient.Update(context.TODO(), cluster)
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	err = testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), updatedCluster.Status.DesiredNumberScheduled)
+	require.Equal(t, int32(3), updatedCluster.Status.CurrentNumberScheduled)
+	require.Equal(t, int32(3), updatedCluster.Status.NumberReady)
+	require.Equal(t, int32(3), updatedCluster.Status.NumberAvailable)
+	require.Equal(t, int32(3), updatedCluster.Status.UpdatedNumberScheduled)
+	require.Equal(t, hash, updatedCluster.Status.UpdateRevision)
+	require.Equal(t, hash, updatedCluster.Status.CurrentRevision)
+}
 
-	podControl.Templates = nil
-	podControl.DeletePodName = nil
+func TestUpdateStorageClusterWithInvalidMaxUnavailableValue(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
 
-	result, err = controller.Reconcile(request)
-	require.NoError(t, err)
-	require.Empty(t, result)
-	require.Equal(t, []string{replacedPod1.Name}, podControl.DeletePodName)
-	require.Len(t, podControl.Templates, 1)
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
 
-	// Once the new pods are up and in ready state, we should delete remaining
-	// pods with older versions.
-	k8sClient.Delete(context.TODO(), replacedPod1)
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 
-	replacedPod2, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
-	require.NoError(t, err)
-	replacedPod2.Name = replacedPod2.GenerateName + "replaced-2"
-	replacedPod2.Namespace = cluster.Namespace
-	replacedPod2.Spec.NodeName = deletedPods[0].Spec.NodeName
-	replacedPod2.Status.Conditions = []v1.PodCondition{
-		{
-			Type:   v1.PodReady,
-			Status: v1.ConditionTrue,
+	// Reconcile should fail due to invalid maxUnavailable value in RollingUpdate strategy
+	maxUnavailable := intstr.FromString("invalid-value")
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+			MaxUnavailable: &maxUnavailable,
 		},
 	}
-	k8sClient.Create(context.TODO(), replacedPod2)
-
-	replacedPod3 := replacedPod2.DeepCopy()
-	replacedPod3.Name = replacedPod2.GenerateName + "replaced-3"
-	replacedPod3.Spec.NodeName = deletedPods[1].Spec.NodeName
-	k8sClient.Create(context.TODO(), replacedPod3)
-
-	podControl.Templates = nil
-	podControl.DeletePodName = nil
+	k8sClient.Update(context.TODO(), cluster)
 
-	result, err = controller.Reconcile(request)
-	require.NoError(t, err)
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
 	require.Empty(t, result)
-	require.Len(t, podControl.DeletePodName, 2)
-	require.NotContains(t, podControl.DeletePodName, replacedPod2.Name)
-	require.NotContains(t, podControl.DeletePodName, replacedPod3.Name)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid value for MaxUnavailable")
+
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events,
+		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
 }
 
-func TestUpdateStorageClusterWithPercentageMaxUnavailable(t *testing.T) {
+func TestUpdateStorageClusterShouldRestartPodIfItDoesNotHaveAnyHash(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -2671,7 +5543,6 @@ func TestUpdateStorageClusterWithPercentageMaxUnavailable(t *testing.T) {
 		recorder:          recorder,
 		kubernetesVersion: k8sVersion,
 	}
-	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
 
 	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
@@ -2680,54 +5551,86 @@ func TestUpdateStorageClusterWithPercentageMaxUnavailable(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	k8sNode1 := createK8sNode("k8s-node-1", 10)
-	k8sNode2 := createK8sNode("k8s-node-2", 10)
-	k8sNode3 := createK8sNode("k8s-node-3", 10)
-	k8sNode4 := createK8sNode("k8s-node-4", 10)
-	k8sClient.Create(context.TODO(), k8sNode1)
-	k8sClient.Create(context.TODO(), k8sNode2)
-	k8sClient.Create(context.TODO(), k8sNode3)
-	k8sClient.Create(context.TODO(), k8sNode4)
+	k8sNode := createK8sNode("k8s-node", 10)
+	k8sClient.Create(context.TODO(), k8sNode)
+
+	// Pods that is already running but does not any revision hash associated
+	runningPod := createStoragePod(cluster, "old-pod", k8sNode.Name, storageLabels)
+	runningPod.Status.Conditions = []v1.PodCondition{
+		{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
+		},
+	}
+	k8sClient.Create(context.TODO(), runningPod)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{runningPod.Name}, podControl.DeletePodName)
+}
+
+func TestUpdateStorageClusterImagePullSecret(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := &Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	// Pods that are already running on the k8s nodes with same hash
+	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
 	require.NoError(t, err)
+
+	// Kubernetes node with enough resources to create new pods
+	k8sNode := createK8sNode("k8s-node", 10)
+	k8sClient.Create(context.TODO(), k8sNode)
+
+	// Pods that are already running on the k8s nodes with same hash
 	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
-	oldPod1 := createStoragePod(cluster, "old-pod-1", k8sNode1.Name, storageLabels)
-	oldPod1.Status.Conditions = []v1.PodCondition{
+	storagePod := createStoragePod(cluster, "storage-pod", k8sNode.Name, storageLabels)
+	storagePod.Status.Conditions = []v1.PodCondition{
 		{
 			Type:   v1.PodReady,
 			Status: v1.ConditionTrue,
 		},
 	}
+	k8sClient.Create(context.TODO(), storagePod)
 
-	oldPod2 := oldPod1.DeepCopy()
-	oldPod2.Name = "old-pod-2"
-	oldPod2.Spec.NodeName = k8sNode2.Name
-
-	oldPod3 := oldPod1.DeepCopy()
-	oldPod3.Name = "old-pod-3"
-	oldPod3.Spec.NodeName = k8sNode3.Name
-
-	oldPod4 := oldPod1.DeepCopy()
-	oldPod4.Name = "old-pod-4"
-	oldPod4.Spec.NodeName = k8sNode4.Name
-
-	k8sClient.Create(context.TODO(), oldPod1)
-	k8sClient.Create(context.TODO(), oldPod2)
-	k8sClient.Create(context.TODO(), oldPod3)
-	k8sClient.Create(context.TODO(), oldPod4)
-
-	// Should delete pods only up to maxUnavailable value. In this case - 75%
-	maxUnavailable := intstr.FromString("75%")
-	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
-		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
-		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
-			MaxUnavailable: &maxUnavailable,
-		},
-	}
-	cluster.Spec.Image = "test/image:v2"
+	// TestCase: Add imagePullSecret
+	cluster.Spec.ImagePullSecret = stringPtr("pull-secret")
 	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
@@ -2739,63 +5642,38 @@ func TestUpdateStorageClusterWithPercentageMaxUnavailable(t *testing.T) {
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Empty(t, podControl.Templates)
-	require.Len(t, podControl.DeletePodName, 3)
 
-	// Next reconcile loop should not delete more pods as 75% are already down,
-	// rather it should try to create 3 pods (75%).
-	deletedPods := make([]*v1.Pod, 0)
-	for _, name := range podControl.DeletePodName {
-		pod := &v1.Pod{}
-		testutil.Get(k8sClient, pod, name, cluster.Namespace)
-		deletedPods = append(deletedPods, pod)
-		k8sClient.Delete(context.TODO(), pod)
-	}
+	// The old pod should be marked for deletion, which means the pod
+	// is detected to be updated
+	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
 
-	podControl.Templates = nil
-	podControl.DeletePodName = nil
+	// TestCase: Update imagePullSecret
+	// Replace old pod with new configuration so that it has the image pull secret
+	storagePod = replaceOldPod(storagePod, cluster, controller, podControl)
+
+	// Change the image pull secret
+	cluster.Spec.ImagePullSecret = stringPtr("new-pull-secret")
+	k8sClient.Update(context.TODO(), cluster)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Empty(t, podControl.DeletePodName)
-	require.Len(t, podControl.Templates, 3)
-
-	// If the pods are up and ready, then the remaining pods are deleted
-	replacedPod1, err := k8scontroller.GetPodFromTemplate(&podControl.Templates[0], cluster, clusterRef)
-	require.NoError(t, err)
-	replacedPod1.Name = replacedPod1.GenerateName + "replaced-1"
-	replacedPod1.Namespace = cluster.Namespace
-	replacedPod1.Spec.NodeName = deletedPods[0].Spec.NodeName
-	replacedPod1.Status.Conditions = []v1.PodCondition{
-		{
-			Type:   v1.PodReady,
-			Status: v1.ConditionTrue,
-		},
-	}
-	k8sClient.Create(context.TODO(), replacedPod1)
-
-	replacedPod2 := replacedPod1.DeepCopy()
-	replacedPod2.Name = replacedPod1.GenerateName + "replaced-2"
-	replacedPod2.Spec.NodeName = deletedPods[1].Spec.NodeName
-	k8sClient.Create(context.TODO(), replacedPod2)
+	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
 
-	replacedPod3 := replacedPod2.DeepCopy()
-	replacedPod3.Name = replacedPod2.GenerateName + "replaced-3"
-	replacedPod3.Spec.NodeName = deletedPods[2].Spec.NodeName
-	k8sClient.Create(context.TODO(), replacedPod3)
+	// TestCase: Remove imagePullSecret
+	// Replace old pod with new configuration so that it has the new image pull secret
+	storagePod = replaceOldPod(storagePod, cluster, controller, podControl)
 
-	podControl.Templates = nil
-	podControl.DeletePodName = nil
+	cluster.Spec.ImagePullSecret = nil
+	k8sClient.Update(context.TODO(), cluster)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Len(t, podControl.DeletePodName, 1)
-	require.Empty(t, podControl.Templates)
+	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
 }
 
-func TestUpdateStorageClusterWithInvalidMaxUnavailableValue(t *testing.T) {
+func TestUpdateStorageClusterCustomImageRegistry(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -2803,10 +5681,14 @@ func TestUpdateStorageClusterWithInvalidMaxUnavailableValue(t *testing.T) {
 	cluster := createStorageCluster()
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
 	k8sClient := testutil.FakeK8sClient(cluster)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
-	controller := Controller{
+	controller := &Controller{
 		client:            k8sClient,
 		Driver:            driver,
 		podControl:        podControl,
@@ -2814,21 +5696,36 @@ func TestUpdateStorageClusterWithInvalidMaxUnavailableValue(t *testing.T) {
 		kubernetesVersion: k8sVersion,
 	}
 
-	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any())
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
 	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
 	driver.EXPECT().String().Return(driverName).AnyTimes()
-	driver.EXPECT().PreInstall(gomock.Any()).Return(nil)
-	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil)
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	// Reconcile should fail due to invalid maxUnavailable value in RollingUpdate strategy
-	maxUnavailable := intstr.FromString("invalid-value")
-	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
-		Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
-		RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
-			MaxUnavailable: &maxUnavailable,
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes node with enough resources to create new pods
+	k8sNode := createK8sNode("k8s-node", 10)
+	k8sClient.Create(context.TODO(), k8sNode)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	storagePod := createStoragePod(cluster, "storage-pod", k8sNode.Name, storageLabels)
+	storagePod.Status.Conditions = []v1.PodCondition{
+		{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
 		},
 	}
+	k8sClient.Create(context.TODO(), storagePod)
+
+	// TestCase: Add customImageRegistry
+	cluster.Spec.CustomImageRegistry = "registry.first"
 	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
@@ -2838,16 +5735,40 @@ func TestUpdateStorageClusterWithInvalidMaxUnavailableValue(t *testing.T) {
 		},
 	}
 	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "invalid value for MaxUnavailable")
 
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v", v1.EventTypeWarning, util.FailedSyncReason))
+	// The old pod should be marked for deletion, which means the pod
+	// is detected to be updated
+	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
+
+	// TestCase: Update customImageRegistry
+	// Replace old pod with new configuration so that it has the custom image registry
+	storagePod = replaceOldPod(storagePod, cluster, controller, podControl)
+
+	// Change the custom image registry
+	cluster.Spec.CustomImageRegistry = "registry.second"
+	k8sClient.Update(context.TODO(), cluster)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
+
+	// TestCase: Remove customImageRegistry
+	// Replace old pod with new configuration so that it has the new custom image registry
+	storagePod = replaceOldPod(storagePod, cluster, controller, podControl)
+
+	cluster.Spec.CustomImageRegistry = ""
+	k8sClient.Update(context.TODO(), cluster)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
 }
 
-func TestUpdateStorageClusterShouldRestartPodIfItDoesNotHaveAnyHash(t *testing.T) {
+func TestUpdateStorageClusterKvdbSpec(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -2877,19 +5798,32 @@ func TestUpdateStorageClusterShouldRestartPodIfItDoesNotHaveAnyHash(t *testing.T
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
 
+	// Kubernetes node with enough resources to create new pods
 	k8sNode := createK8sNode("k8s-node", 10)
 	k8sClient.Create(context.TODO(), k8sNode)
 
-	// Pods that is already running but does not any revision hash associated
-	runningPod := createStoragePod(cluster, "old-pod", k8sNode.Name, storageLabels)
-	runningPod.Status.Conditions = []v1.PodCondition{
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	oldPod := createStoragePod(cluster, "old-pod", k8sNode.Name, storageLabels)
+	oldPod.Status.Conditions = []v1.PodCondition{
 		{
 			Type:   v1.PodReady,
 			Status: v1.ConditionTrue,
 		},
 	}
-	k8sClient.Create(context.TODO(), runningPod)
+	k8sClient.Create(context.TODO(), oldPod)
+
+	// TestCase: Change spec.kvdb.internal
+	cluster.Spec.Kvdb = &corev1alpha1.KvdbSpec{
+		Internal: true,
+	}
+	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -2900,25 +5834,78 @@ func TestUpdateStorageClusterShouldRestartPodIfItDoesNotHaveAnyHash(t *testing.T
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Equal(t, []string{runningPod.Name}, podControl.DeletePodName)
+
+	// The old pod should be marked for deletion, which means the pod
+	// is detected to be updated.
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
+
+	// TestCase: Add spec.kvdb.endpoints
+	cluster.Spec.Kvdb.Endpoints = []string{"kvdb1", "kvdb2"}
+	k8sClient.Update(context.TODO(), cluster)
+
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
+
+	// TestCase: Change spec.kvdb.endpoints
+	cluster.Spec.Kvdb.Endpoints = []string{"kvdb2"}
+	k8sClient.Update(context.TODO(), cluster)
+
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
+
+	// TestCase: Change spec.kvdb.authSecrets
+	cluster.Spec.Kvdb.AuthSecret = "test-secret"
+	k8sClient.Update(context.TODO(), cluster)
+
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 }
 
-func TestUpdateStorageClusterImagePullSecret(t *testing.T) {
+func TestUpdateStorageClusterKvdbAuthSecretData(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
+	cluster.Spec.Kvdb = &corev1alpha1.KvdbSpec{
+		Endpoints:  []string{"kvdb1", "kvdb2"},
+		AuthSecret: "kvdb-auth",
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kvdb-auth",
+			Namespace: cluster.Namespace,
+		},
+		Data: map[string][]byte{
+			"username": []byte("user"),
+			"password": []byte("password"),
+		},
+	}
+	cluster.Annotations = map[string]string{
+		annotationSecretChecksum: util.SecretChecksum(secret),
+	}
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
 	storageLabels := map[string]string{
 		labelKeyName:       cluster.Name,
 		labelKeyDriverName: driverName,
 	}
-	k8sClient := testutil.FakeK8sClient(cluster)
+	k8sClient := testutil.FakeK8sClient(cluster, secret)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
-	controller := &Controller{
+	controller := Controller{
 		client:            k8sClient,
 		Driver:            driver,
 		podControl:        podControl,
@@ -2933,6 +5920,7 @@ func TestUpdateStorageClusterImagePullSecret(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -2942,20 +5930,20 @@ func TestUpdateStorageClusterImagePullSecret(t *testing.T) {
 	k8sNode := createK8sNode("k8s-node", 10)
 	k8sClient.Create(context.TODO(), k8sNode)
 
-	// Pods that are already running on the k8s nodes with same hash
+	// Pods that are already running on the k8s nodes with same hash and
+	// kvdb credentials
 	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
-	storagePod := createStoragePod(cluster, "storage-pod", k8sNode.Name, storageLabels)
-	storagePod.Status.Conditions = []v1.PodCondition{
+	oldPod := createStoragePod(cluster, "old-pod", k8sNode.Name, storageLabels)
+	oldPod.Annotations = map[string]string{
+		annotationSecretChecksum: util.SecretChecksum(secret),
+	}
+	oldPod.Status.Conditions = []v1.PodCondition{
 		{
 			Type:   v1.PodReady,
 			Status: v1.ConditionTrue,
 		},
 	}
-	k8sClient.Create(context.TODO(), storagePod)
-
-	// TestCase: Add imagePullSecret
-	cluster.Spec.ImagePullSecret = stringPtr("pull-secret")
-	k8sClient.Update(context.TODO(), cluster)
+	k8sClient.Create(context.TODO(), oldPod)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -2963,56 +5951,100 @@ func TestUpdateStorageClusterImagePullSecret(t *testing.T) {
 			Namespace: cluster.Namespace,
 		},
 	}
+
+	// TestCase: The cluster should be reconciled when its kvdb secret changes
+	requests := controller.storageClustersForSecret(handler.MapObject{Meta: secret})
+	require.Equal(t, []reconcile.Request{request}, requests)
+
+	otherSecret := secret.DeepCopy()
+	otherSecret.Name = "other-secret"
+	requests = controller.storageClustersForSecret(handler.MapObject{Meta: otherSecret})
+	require.Empty(t, requests)
+
+	// TestCase: Pods should not be updated if the kvdb credentials are unchanged
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
 
-	// The old pod should be marked for deletion, which means the pod
-	// is detected to be updated
-	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
+	// TestCase: Pods should be updated if the kvdb credentials change
+	secret.Data["password"] = []byte("new-password")
+	k8sClient.Update(context.TODO(), secret)
 
-	// TestCase: Update imagePullSecret
-	// Replace old pod with new configuration so that it has the image pull secret
-	storagePod = replaceOldPod(storagePod, cluster, controller, podControl)
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 
-	// Change the image pull secret
-	cluster.Spec.ImagePullSecret = stringPtr("new-pull-secret")
-	k8sClient.Update(context.TODO(), cluster)
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Equal(t, util.SecretChecksum(secret), updatedCluster.Annotations[annotationSecretChecksum])
+
+	// TestCase: New pods should have the checksum of the new credentials
+	k8sClient.Delete(context.TODO(), oldPod)
+	podControl.DeletePodName = nil
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
+	require.Len(t, podControl.Templates, 1)
+	require.Equal(t, util.SecretChecksum(secret),
+		podControl.Templates[0].Annotations[annotationSecretChecksum])
 
-	// TestCase: Remove imagePullSecret
-	// Replace old pod with new configuration so that it has the new image pull secret
-	storagePod = replaceOldPod(storagePod, cluster, controller, podControl)
+	// TestCase: Keep the last checksum if the secret is deleted
+	k8sClient.Delete(context.TODO(), secret)
 
-	cluster.Spec.ImagePullSecret = nil
-	k8sClient.Update(context.TODO(), cluster)
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Equal(t, util.SecretChecksum(secret), updatedCluster.Annotations[annotationSecretChecksum])
+
+	// TestCase: Remove the checksum if the cluster does not use a kvdb secret
+	updatedCluster.Spec.Kvdb.AuthSecret = ""
+	k8sClient.Update(context.TODO(), updatedCluster)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
+
+	updatedCluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.NotContains(t, updatedCluster.Annotations, annotationSecretChecksum)
 }
 
-func TestUpdateStorageClusterCustomImageRegistry(t *testing.T) {
+func TestUpdateStorageClusterSecretChecksumAfterUpgrade(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
+	cluster.Spec.Kvdb = &corev1alpha1.KvdbSpec{
+		Endpoints:  []string{"kvdb1", "kvdb2"},
+		AuthSecret: "kvdb-auth",
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kvdb-auth",
+			Namespace: cluster.Namespace,
+		},
+		Data: map[string][]byte{
+			"username": []byte("user"),
+			"password": []byte("password"),
+		},
+	}
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
 	storageLabels := map[string]string{
 		labelKeyName:       cluster.Name,
 		labelKeyDriverName: driverName,
 	}
-	k8sClient := testutil.FakeK8sClient(cluster)
+	k8sClient := testutil.FakeK8sClient(cluster, secret)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
-	controller := &Controller{
+	controller := Controller{
 		client:            k8sClient,
 		Driver:            driver,
 		podControl:        podControl,
@@ -3027,29 +6059,25 @@ func TestUpdateStorageClusterCustomImageRegistry(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	// This will create a revision which we will map to our pre-created pods
+	// Revision created by an operator that did not record the secret checksum
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
 	require.NoError(t, err)
 
-	// Kubernetes node with enough resources to create new pods
 	k8sNode := createK8sNode("k8s-node", 10)
 	k8sClient.Create(context.TODO(), k8sNode)
 
-	// Pods that are already running on the k8s nodes with same hash
+	// Pod created by an operator that did not record the secret checksum
 	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
-	storagePod := createStoragePod(cluster, "storage-pod", k8sNode.Name, storageLabels)
-	storagePod.Status.Conditions = []v1.PodCondition{
+	oldPod := createStoragePod(cluster, "old-pod", k8sNode.Name, storageLabels)
+	oldPod.Status.Conditions = []v1.PodCondition{
 		{
 			Type:   v1.PodReady,
 			Status: v1.ConditionTrue,
 		},
 	}
-	k8sClient.Create(context.TODO(), storagePod)
-
-	// TestCase: Add customImageRegistry
-	cluster.Spec.CustomImageRegistry = "registry.first"
-	k8sClient.Update(context.TODO(), cluster)
+	k8sClient.Create(context.TODO(), oldPod)
 
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -3057,53 +6085,113 @@ func TestUpdateStorageClusterCustomImageRegistry(t *testing.T) {
 			Namespace: cluster.Namespace,
 		},
 	}
+
+	// TestCase: Existing pods should not be updated when the checksum is
+	// recorded for the first time, but should get the current checksum
 	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
 
-	// The old pod should be marked for deletion, which means the pod
-	// is detected to be updated
-	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Equal(t, util.SecretChecksum(secret), updatedCluster.Annotations[annotationSecretChecksum])
 
-	// TestCase: Update customImageRegistry
-	// Replace old pod with new configuration so that it has the custom image registry
-	storagePod = replaceOldPod(storagePod, cluster, controller, podControl)
+	updatedPod := &v1.Pod{}
+	testutil.Get(k8sClient, updatedPod, oldPod.Name, oldPod.Namespace)
+	require.Equal(t, util.SecretChecksum(secret), updatedPod.Annotations[annotationSecretChecksum])
 
-	// Change the custom image registry
-	cluster.Spec.CustomImageRegistry = "registry.second"
-	k8sClient.Update(context.TODO(), cluster)
+	// TestCase: Pods should not be updated on subsequent reconciles
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+
+	// TestCase: Pods should be updated if the secret changes after the upgrade
+	secret.Data["password"] = []byte("new-password")
+	k8sClient.Update(context.TODO(), secret)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 
-	// TestCase: Remove customImageRegistry
-	// Replace old pod with new configuration so that it has the new custom image registry
-	storagePod = replaceOldPod(storagePod, cluster, controller, podControl)
+	// TestCase: Pods without a checksum should be updated only if their spec
+	// has changed, even if the recorded checksum of the cluster changes
+	podControl.DeletePodName = nil
+	k8sClient.Delete(context.TODO(), oldPod)
+	oldPod = createStoragePod(cluster, "old-pod", k8sNode.Name, storageLabels)
+	oldPod.Status.Conditions = []v1.PodCondition{
+		{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
+		},
+	}
+	k8sClient.Create(context.TODO(), oldPod)
 
-	cluster.Spec.CustomImageRegistry = ""
-	k8sClient.Update(context.TODO(), cluster)
+	secret.Data["password"] = []byte("newer-password")
+	k8sClient.Update(context.TODO(), secret)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Equal(t, []string{storagePod.Name}, podControl.DeletePodName)
+	require.Empty(t, podControl.DeletePodName)
 }
 
-func TestUpdateStorageClusterKvdbSpec(t *testing.T) {
+func TestUpdateStorageClusterStoragePodSecretData(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	driverName := "mock-driver"
 	cluster := createStorageCluster()
+	clusterRef := metav1.NewControllerRef(cluster, controllerKind)
+	secret1 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "pod-secret-1",
+			Namespace:       cluster.Namespace,
+			Labels:          map[string]string{LabelStoragePodSecret: "true"},
+			OwnerReferences: []metav1.OwnerReference{*clusterRef},
+		},
+		Data: map[string][]byte{
+			"key": []byte("value1"),
+		},
+	}
+	secret2 := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "pod-secret-2",
+			Namespace:       cluster.Namespace,
+			Labels:          map[string]string{LabelStoragePodSecret: "true"},
+			OwnerReferences: []metav1.OwnerReference{*clusterRef},
+		},
+		Data: map[string][]byte{
+			"key": []byte("value2"),
+		},
+	}
+	// Secrets without the label or of other clusters are not used by the pods
+	unlabeledSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "unlabeled-secret",
+			Namespace:       cluster.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*clusterRef},
+		},
+		Data: map[string][]byte{
+			"key": []byte("value"),
+		},
+	}
+	otherClusterSecret := secret1.DeepCopy()
+	otherClusterSecret.Name = "other-cluster-secret"
+	otherClusterSecret.OwnerReferences[0].UID = "other-uid"
+
+	cluster.Annotations = map[string]string{
+		annotationSecretChecksum: util.SecretChecksum(secret1, secret2),
+	}
 	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
 	driver := testutil.MockDriver(mockCtrl)
 	storageLabels := map[string]string{
 		labelKeyName:       cluster.Name,
 		labelKeyDriverName: driverName,
 	}
-	k8sClient := testutil.FakeK8sClient(cluster)
+	k8sClient := testutil.FakeK8sClient(cluster, secret1, secret2, unlabeledSecret, otherClusterSecret)
 	podControl := &k8scontroller.FakePodControl{}
 	recorder := record.NewFakeRecorder(10)
 	controller := Controller{
@@ -3121,6 +6209,7 @@ func TestUpdateStorageClusterKvdbSpec(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -3130,9 +6219,12 @@ func TestUpdateStorageClusterKvdbSpec(t *testing.T) {
 	k8sNode := createK8sNode("k8s-node", 10)
 	k8sClient.Create(context.TODO(), k8sNode)
 
-	// Pods that are already running on the k8s nodes with same hash
+	// Pods that are already running on the k8s nodes with same hash and secrets
 	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
 	oldPod := createStoragePod(cluster, "old-pod", k8sNode.Name, storageLabels)
+	oldPod.Annotations = map[string]string{
+		annotationSecretChecksum: util.SecretChecksum(secret1, secret2),
+	}
 	oldPod.Status.Conditions = []v1.PodCondition{
 		{
 			Type:   v1.PodReady,
@@ -3141,58 +6233,52 @@ func TestUpdateStorageClusterKvdbSpec(t *testing.T) {
 	}
 	k8sClient.Create(context.TODO(), oldPod)
 
-	// TestCase: Change spec.kvdb.internal
-	cluster.Spec.Kvdb = &corev1alpha1.KvdbSpec{
-		Internal: true,
-	}
-	k8sClient.Update(context.TODO(), cluster)
-
 	request := reconcile.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      cluster.Name,
 			Namespace: cluster.Namespace,
 		},
 	}
-	result, err := controller.Reconcile(request)
-	require.NoError(t, err)
-	require.Empty(t, result)
-
-	// The old pod should be marked for deletion, which means the pod
-	// is detected to be updated.
-	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
-
-	// TestCase: Add spec.kvdb.endpoints
-	cluster.Spec.Kvdb.Endpoints = []string{"kvdb1", "kvdb2"}
-	k8sClient.Update(context.TODO(), cluster)
 
-	podControl.DeletePodName = nil
+	// TestCase: The cluster should be reconciled only when its storage pod
+	// secrets change
+	requests := controller.storageClustersForSecret(handler.MapObject{Meta: secret1})
+	require.Equal(t, []reconcile.Request{request}, requests)
+	requests = controller.storageClustersForSecret(handler.MapObject{Meta: unlabeledSecret})
+	require.Empty(t, requests)
+	requests = controller.storageClustersForSecret(handler.MapObject{Meta: otherClusterSecret})
+	require.Empty(t, requests)
 
-	result, err = controller.Reconcile(request)
+	// TestCase: Pods should not be updated if the secrets are unchanged
+	result, err := controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
-
-	// TestCase: Change spec.kvdb.endpoints
-	cluster.Spec.Kvdb.Endpoints = []string{"kvdb2"}
-	k8sClient.Update(context.TODO(), cluster)
+	require.Empty(t, podControl.DeletePodName)
 
-	podControl.DeletePodName = nil
+	// TestCase: Pods should not be updated if other secrets change
+	unlabeledSecret.Data["key"] = []byte("new-value")
+	k8sClient.Update(context.TODO(), unlabeledSecret)
+	otherClusterSecret.Data["key"] = []byte("new-value")
+	k8sClient.Update(context.TODO(), otherClusterSecret)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
-	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
-
-	// TestCase: Change spec.kvdb.authSecrets
-	cluster.Spec.Kvdb.AuthSecret = "test-secret"
-	k8sClient.Update(context.TODO(), cluster)
+	require.Empty(t, podControl.DeletePodName)
 
-	podControl.DeletePodName = nil
+	// TestCase: Pods should be updated if a storage pod secret changes
+	secret2.Data["key"] = []byte("new-value2")
+	k8sClient.Update(context.TODO(), secret2)
 
 	result, err = controller.Reconcile(request)
 	require.NoError(t, err)
 	require.Empty(t, result)
 	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
+
+	updatedCluster := &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, updatedCluster, cluster.Name, cluster.Namespace)
+	require.Equal(t, util.SecretChecksum(secret1, secret2),
+		updatedCluster.Annotations[annotationSecretChecksum])
 }
 
 func TestUpdateStorageClusterCloudStorageSpec(t *testing.T) {
@@ -3225,6 +6311,7 @@ func TestUpdateStorageClusterCloudStorageSpec(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -3392,6 +6479,7 @@ func TestUpdateStorageClusterStorageSpec(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -3545,6 +6633,91 @@ func TestUpdateStorageClusterNetworkSpec(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes node with enough resources to create new pods
+	k8sNode := createK8sNode("k8s-node", 10)
+	k8sClient.Create(context.TODO(), k8sNode)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	oldPod := createStoragePod(cluster, "old-pod", k8sNode.Name, storageLabels)
+	oldPod.Status.Conditions = []v1.PodCondition{
+		{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
+		},
+	}
+	k8sClient.Create(context.TODO(), oldPod)
+
+	// TestCase: Change spec.network.dataInterface
+	nwInterface := "eth0"
+	cluster.Spec.Network = &corev1alpha1.NetworkSpec{
+		DataInterface: &nwInterface,
+	}
+	k8sClient.Update(context.TODO(), cluster)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// The old pod should be marked for deletion, which means the pod
+	// is detected to be updated.
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
+
+	// TestCase: Change spec.network.mgmtInterface
+	cluster.Spec.Network.MgmtInterface = &nwInterface
+	k8sClient.Update(context.TODO(), cluster)
+
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
+}
+
+func TestUpdateStorageClusterEnvVariables(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -3565,10 +6738,12 @@ func TestUpdateStorageClusterNetworkSpec(t *testing.T) {
 	}
 	k8sClient.Create(context.TODO(), oldPod)
 
-	// TestCase: Change spec.network.dataInterface
-	nwInterface := "eth0"
-	cluster.Spec.Network = &corev1alpha1.NetworkSpec{
-		DataInterface: &nwInterface,
+	// TestCase: Add spec.env
+	cluster.Spec.Env = []v1.EnvVar{
+		{
+			Name:  "key1",
+			Value: "value1",
+		},
 	}
 	k8sClient.Update(context.TODO(), cluster)
 
@@ -3586,8 +6761,8 @@ func TestUpdateStorageClusterNetworkSpec(t *testing.T) {
 	// is detected to be updated.
 	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 
-	// TestCase: Change spec.network.mgmtInterface
-	cluster.Spec.Network.MgmtInterface = &nwInterface
+	// TestCase: Change spec.env
+	cluster.Spec.Env = append(cluster.Spec.Env, v1.EnvVar{Name: "key2", Value: "value2"})
 	k8sClient.Update(context.TODO(), cluster)
 
 	podControl.DeletePodName = nil
@@ -3598,7 +6773,7 @@ func TestUpdateStorageClusterNetworkSpec(t *testing.T) {
 	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 }
 
-func TestUpdateStorageClusterEnvVariables(t *testing.T) {
+func TestUpdateStorageClusterRuntimeOptions(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -3628,6 +6803,7 @@ func TestUpdateStorageClusterEnvVariables(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -3648,12 +6824,9 @@ func TestUpdateStorageClusterEnvVariables(t *testing.T) {
 	}
 	k8sClient.Create(context.TODO(), oldPod)
 
-	// TestCase: Add spec.env
-	cluster.Spec.Env = []v1.EnvVar{
-		{
-			Name:  "key1",
-			Value: "value1",
-		},
+	// TestCase: Add spec.runtimeOptions
+	cluster.Spec.RuntimeOpts = map[string]string{
+		"key1": "value1",
 	}
 	k8sClient.Update(context.TODO(), cluster)
 
@@ -3671,8 +6844,8 @@ func TestUpdateStorageClusterEnvVariables(t *testing.T) {
 	// is detected to be updated.
 	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 
-	// TestCase: Change spec.env
-	cluster.Spec.Env = append(cluster.Spec.Env, v1.EnvVar{Name: "key2", Value: "value2"})
+	// TestCase: Change spec.runtimeOptions
+	cluster.Spec.RuntimeOpts["key1"] = "value2"
 	k8sClient.Update(context.TODO(), cluster)
 
 	podControl.DeletePodName = nil
@@ -3683,7 +6856,7 @@ func TestUpdateStorageClusterEnvVariables(t *testing.T) {
 	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 }
 
-func TestUpdateStorageClusterRuntimeOptions(t *testing.T) {
+func TestUpdateStorageClusterSecretsProvider(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -3713,6 +6886,7 @@ func TestUpdateStorageClusterRuntimeOptions(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -3733,10 +6907,9 @@ func TestUpdateStorageClusterRuntimeOptions(t *testing.T) {
 	}
 	k8sClient.Create(context.TODO(), oldPod)
 
-	// TestCase: Add spec.runtimeOptions
-	cluster.Spec.RuntimeOpts = map[string]string{
-		"key1": "value1",
-	}
+	// TestCase: Add spec.secretsProvider
+	secretsProvider := "vault"
+	cluster.Spec.SecretsProvider = &secretsProvider
 	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
@@ -3753,8 +6926,8 @@ func TestUpdateStorageClusterRuntimeOptions(t *testing.T) {
 	// is detected to be updated.
 	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 
-	// TestCase: Change spec.runtimeOptions
-	cluster.Spec.RuntimeOpts["key1"] = "value2"
+	// TestCase: Change spec.secretsProvider
+	secretsProvider = "aws-kms"
 	k8sClient.Update(context.TODO(), cluster)
 
 	podControl.DeletePodName = nil
@@ -3765,7 +6938,7 @@ func TestUpdateStorageClusterRuntimeOptions(t *testing.T) {
 	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 }
 
-func TestUpdateStorageClusterSecretsProvider(t *testing.T) {
+func TestUpdateStorageClusterSecuritySpec(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
@@ -3795,6 +6968,7 @@ func TestUpdateStorageClusterSecretsProvider(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -3804,9 +6978,13 @@ func TestUpdateStorageClusterSecretsProvider(t *testing.T) {
 	k8sNode := createK8sNode("k8s-node", 10)
 	k8sClient.Create(context.TODO(), k8sNode)
 
-	// Pods that are already running on the k8s nodes with same hash
+	// Pods that are already running on the k8s nodes with same hash. The node
+	// group hash should not hide changes to the security spec.
 	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
 	oldPod := createStoragePod(cluster, "old-pod", k8sNode.Name, storageLabels)
+	oldPod.Annotations = map[string]string{
+		annotationNodeGroupHash: computeNodeGroupHash(&cluster.Spec),
+	}
 	oldPod.Status.Conditions = []v1.PodCondition{
 		{
 			Type:   v1.PodReady,
@@ -3815,9 +6993,10 @@ func TestUpdateStorageClusterSecretsProvider(t *testing.T) {
 	}
 	k8sClient.Create(context.TODO(), oldPod)
 
-	// TestCase: Add spec.secretsProvider
-	secretsProvider := "vault"
-	cluster.Spec.SecretsProvider = &secretsProvider
+	// TestCase: Enable security
+	cluster.Spec.Security = &corev1alpha1.SecuritySpec{
+		Enabled: true,
+	}
 	k8sClient.Update(context.TODO(), cluster)
 
 	request := reconcile.Request{
@@ -3834,8 +7013,28 @@ func TestUpdateStorageClusterSecretsProvider(t *testing.T) {
 	// is detected to be updated.
 	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 
-	// TestCase: Change spec.secretsProvider
-	secretsProvider = "aws-kms"
+	// TestCase: Enable TLS on a pod that already has security enabled.
+	// The revision of the current spec was created by the controller.
+	rev2, err := getRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+	oldPod.Labels[defaultStorageClusterUniqueLabelKey] = rev2.Labels[defaultStorageClusterUniqueLabelKey]
+	oldPod.Annotations[annotationNodeGroupHash] = computeNodeGroupHash(&cluster.Spec)
+	k8sClient.Update(context.TODO(), oldPod)
+
+	cluster.Spec.Security.TLS = &corev1alpha1.TLSSpec{
+		Enabled: true,
+	}
+	k8sClient.Update(context.TODO(), cluster)
+
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
+
+	// TestCase: Disable TLS
+	cluster.Spec.Security.TLS.Enabled = false
 	k8sClient.Update(context.TODO(), cluster)
 
 	podControl.DeletePodName = nil
@@ -3876,6 +7075,7 @@ func TestUpdateStorageClusterStartPort(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -3957,6 +7157,7 @@ func TestUpdateStorageClusterFeatureGates(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -4049,6 +7250,7 @@ func TestUpdateStorageClusterNodeSpec(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -4381,6 +7583,172 @@ func TestUpdateStorageClusterNodeSpec(t *testing.T) {
 	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
 }
 
+func TestUpdateStorageClusterNodeGroupOverrides(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Spec.Image = "image/cluster:1"
+	cluster.Spec.Nodes = []corev1alpha1.NodeSpec{
+		{
+			Selector: corev1alpha1.NodeSelector{
+				NodeName: "k8s-node-1",
+			},
+			Image: "image/node:1",
+		},
+		{
+			Selector: corev1alpha1.NodeSelector{
+				NodeName: "k8s-node-2",
+			},
+			CommonConfig: corev1alpha1.CommonConfig{
+				Resources: &v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+				},
+			},
+		},
+	}
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes nodes with enough resources to create new pods
+	k8sNode1 := createK8sNode("k8s-node-1", 10)
+	k8sNode2 := createK8sNode("k8s-node-2", 10)
+	k8sClient.Create(context.TODO(), k8sNode1)
+	k8sClient.Create(context.TODO(), k8sNode2)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	pod1 := createStoragePod(cluster, "pod-1", k8sNode1.Name, storageLabels)
+	pod2 := createStoragePod(cluster, "pod-2", k8sNode2.Name, storageLabels)
+	for _, pod := range []*v1.Pod{pod1, pod2} {
+		node := k8sNode1
+		if pod.Spec.NodeName == k8sNode2.Name {
+			node = k8sNode2
+		}
+		pod.Annotations = map[string]string{
+			annotationNodeGroupHash: computeNodeGroupHash(clusterSpecForNode(node, &cluster.Spec)),
+		}
+		pod.Status.Conditions = []v1.PodCondition{
+			{
+				Type:   v1.PodReady,
+				Status: v1.ConditionTrue,
+			},
+		}
+		k8sClient.Create(context.TODO(), pod)
+	}
+
+	// TestCase: Change the image of the first node group.
+	// Only the pod in that node group should be updated.
+	cluster.Spec.Nodes[0].Image = "image/node:2"
+	k8sClient.Update(context.TODO(), cluster)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{pod1.Name}, podControl.DeletePodName)
+
+	// Simulate the first pod getting updated to the latest spec
+	revs := &appsv1.ControllerRevisionList{}
+	k8sClient.List(context.TODO(), revs, &client.ListOptions{})
+	pod1.Labels[defaultStorageClusterUniqueLabelKey] = revs.Items[len(revs.Items)-1].Labels[defaultStorageClusterUniqueLabelKey]
+	pod1.Annotations[annotationNodeGroupHash] = computeNodeGroupHash(clusterSpecForNode(k8sNode1, &cluster.Spec))
+	k8sClient.Update(context.TODO(), pod1)
+
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Empty(t, podControl.DeletePodName)
+
+	// TestCase: Change the cluster level resources. The pod in the second node
+	// group should not be updated as it has its own resources.
+	cluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, cluster, request.Name, request.Namespace)
+	cluster.Spec.Resources = &v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}
+	k8sClient.Update(context.TODO(), cluster)
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{pod1.Name}, podControl.DeletePodName)
+
+	// TestCase: Change the resources of the second node group.
+	// Only the pod in that node group should be updated.
+	cluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, cluster, request.Name, request.Namespace)
+	cluster.Spec.Resources = nil
+	cluster.Spec.Nodes[1].Resources.Requests[v1.ResourceMemory] = resource.MustParse("8Gi")
+	k8sClient.Update(context.TODO(), cluster)
+
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{pod2.Name}, podControl.DeletePodName)
+
+	// TestCase: Adding tolerations to a node group should restart the pods of
+	// that node group, as they are part of the effective spec of the group.
+	cluster = &corev1alpha1.StorageCluster{}
+	testutil.Get(k8sClient, cluster, request.Name, request.Namespace)
+	cluster.Spec.Nodes[1].Resources.Requests[v1.ResourceMemory] = resource.MustParse("4Gi")
+	cluster.Spec.Nodes[0].Tolerations = []v1.Toleration{
+		{
+			Key:      "foo",
+			Operator: v1.TolerationOpExists,
+		},
+	}
+	k8sClient.Update(context.TODO(), cluster)
+
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{pod1.Name}, podControl.DeletePodName)
+}
+
 func TestUpdateStorageClusterK8sNodeChanges(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -4428,6 +7796,7 @@ func TestUpdateStorageClusterK8sNodeChanges(t *testing.T) {
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
@@ -4628,6 +7997,94 @@ func TestUpdateStorageClusterShouldNotRestartPodsForSomeOptions(t *testing.T) {
 	require.Empty(t, podControl.DeletePodName)
 }
 
+func TestUpdateStorageClusterShouldNotRestartPodsForPoolSize(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	driverName := "mock-driver"
+	cluster := createStorageCluster()
+	cluster.Spec.CloudStorage = &corev1alpha1.CloudStorageSpec{
+		CapacitySpecs: []corev1alpha1.CloudStorageCapacitySpec{
+			{
+				MinCapacityInGiB: 100,
+				PoolSizeInGiB:    100,
+			},
+		},
+	}
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	storageLabels := map[string]string{
+		labelKeyName:       cluster.Name,
+		labelKeyDriverName: driverName,
+	}
+	k8sClient := testutil.FakeK8sClient(cluster)
+	podControl := &k8scontroller.FakePodControl{}
+	recorder := record.NewFakeRecorder(10)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		podControl:        podControl,
+		recorder:          recorder,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().SetDefaultsOnStorageCluster(gomock.Any()).AnyTimes()
+	driver.EXPECT().GetSelectorLabels().Return(nil).AnyTimes()
+	driver.EXPECT().String().Return(driverName).AnyTimes()
+	driver.EXPECT().PreInstall(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
+	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	// This will create a revision which we will map to our pre-created pods
+	rev1Hash, err := createRevision(k8sClient, cluster, driverName)
+	require.NoError(t, err)
+
+	// Kubernetes node with enough resources to create new pods
+	k8sNode := createK8sNode("k8s-node", 10)
+	k8sClient.Create(context.TODO(), k8sNode)
+
+	// Pods that are already running on the k8s nodes with same hash
+	storageLabels[defaultStorageClusterUniqueLabelKey] = rev1Hash
+	oldPod := createStoragePod(cluster, "old-pod", k8sNode.Name, storageLabels)
+	oldPod.Status.Conditions = []v1.PodCondition{
+		{
+			Type:   v1.PodReady,
+			Status: v1.ConditionTrue,
+		},
+	}
+	k8sClient.Create(context.TODO(), oldPod)
+
+	// TestCase: Change spec.cloudStorage.capacitySpecs[].poolSizeInGiB
+	cluster.Spec.CloudStorage.CapacitySpecs[0].PoolSizeInGiB = 200
+	k8sClient.Update(context.TODO(), cluster)
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      cluster.Name,
+			Namespace: cluster.Namespace,
+		},
+	}
+	result, err := controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	// The old pod should not be deleted as the pools are expanded by the driver
+	require.Empty(t, podControl.DeletePodName)
+
+	// TestCase: Change other fields of spec.cloudStorage.capacitySpecs
+	cluster.Spec.CloudStorage.CapacitySpecs[0].MinCapacityInGiB = 200
+	k8sClient.Update(context.TODO(), cluster)
+
+	podControl.DeletePodName = nil
+
+	result, err = controller.Reconcile(request)
+	require.NoError(t, err)
+	require.Empty(t, result)
+	require.Equal(t, []string{oldPod.Name}, podControl.DeletePodName)
+}
+
 func TestUpdateStorageClusterShouldRestartPodIfItsHistoryHasInvalidSpec(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -4658,6 +8115,7 @@ func TestUpdateStorageClusterShouldRestartPodIfItsHistoryHasInvalidSpec(t *testi
 	driver.EXPECT().UpdateDriver(gomock.Any()).Return(nil).AnyTimes()
 	driver.EXPECT().GetStoragePodSpec(gomock.Any(), gomock.Any()).Return(v1.PodSpec{}, nil).AnyTimes()
 	driver.EXPECT().UpdateStorageClusterStatus(gomock.Any()).Return(nil).AnyTimes()
+	driver.EXPECT().PreNodeUpgrade(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// This will create a revision which we will map to our pre-created pods
 	cluster.Spec.Image = "image/v2"
@@ -5340,7 +8798,8 @@ func getRevision(
 		return nil, err
 	}
 
-	hash := computeHash(&cluster.Spec, cluster.Status.CollisionCount)
+	hash := computeHash(&cluster.Spec, cluster.Annotations[annotationSecretChecksum],
+		cluster.Status.CollisionCount)
 	return &appsv1.ControllerRevision{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      historyName(cluster.Name, hash),
@@ -5396,3 +8855,7 @@ func stringSlicePtr(slice []string) *[]string {
 func stringPtr(str string) *string {
 	return &str
 }
+
+func int32Ptr(val int32) *int32 {
+	return &val
+}
@@ -0,0 +1,219 @@
+package storagecluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationDecommission is the annotation on a StorageNode to decommission the
+	// node, removing it from the storage cluster. Removing the annotation before the
+	// node is removed from the storage cluster cancels the decommission.
+	AnnotationDecommission = operatorPrefix + "/decommission"
+	// StorageNodeFinalizer is the finalizer on the StorageNodes of nodes that are
+	// part of the storage cluster. Deleting such a StorageNode decommissions the
+	// node, and the StorageNode goes away once the node has been decommissioned.
+	StorageNodeFinalizer = operatorPrefix + "/decommission-on-delete"
+)
+
+// syncNodeDecommission requests the decommission of storage nodes that are annotated
+// for decommission, have their StorageNode deleted or no longer match the placement
+// of the cluster. The storage
+// driver drains and removes the node from the storage cluster, reporting the
+// progress in the decommission condition of the StorageNode. It returns the nodes
+// where the storage pods should not run anymore as they are being removed.
+func (c *Controller) syncNodeDecommission(
+	cluster *corev1alpha1.StorageCluster,
+	nodes []v1.Node,
+	nodesOutOfPlacement map[string]bool,
+) (map[string]bool, error) {
+	storageNodeList := &corev1alpha1.StorageNodeList{}
+	err := c.client.List(context.TODO(), storageNodeList, &client.ListOptions{Namespace: cluster.Namespace})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list of storage nodes: %v", err)
+	}
+
+	k8sNodes := make(map[string]bool)
+	for _, node := range nodes {
+		k8sNodes[node.Name] = true
+	}
+
+	nodesToStop := make(map[string]bool)
+	for _, storageNode := range storageNodeList.Items {
+		if !metav1.IsControlledBy(&storageNode, cluster) {
+			continue
+		}
+
+		deleted := storageNode.DeletionTimestamp != nil
+		if !deleted && storageNode.Status.NodeUID != "" {
+			c.addStorageNodeFinalizer(storageNode.DeepCopy())
+		}
+
+		decommission, _ := strconv.ParseBool(storageNode.Annotations[AnnotationDecommission])
+		// Only nodes that are part of the storage cluster are decommissioned when
+		// removed from placement, and not the ones waiting for the preflight checks
+		requested := decommission || deleted ||
+			(nodesOutOfPlacement[storageNode.Name] && storageNode.Status.NodeUID != "")
+
+		condition := getDecommissionCondition(&storageNode)
+		switch {
+		case condition == nil && deleted && storageNode.Status.NodeUID == "":
+			// The node never joined the storage cluster, so there is nothing to decommission
+			c.removeStorageNodeFinalizer(storageNode.DeepCopy())
+		case condition == nil && requested:
+			logrus.Infof("Decommissioning storage node %s", storageNode.Name)
+			c.updateStorageNodeCondition(cluster, storageNode.Name, "", &corev1alpha1.NodeCondition{
+				Type:    corev1alpha1.NodeDecommissionCondition,
+				Status:  corev1alpha1.NodeInitStatus,
+				Message: "Decommission of the node has been requested",
+			})
+		case condition == nil:
+		case condition.Status == corev1alpha1.NodeDecommissionedStatus && deleted:
+			c.removeStorageNodeFinalizer(storageNode.DeepCopy())
+		case condition.Status == corev1alpha1.NodeDecommissionedStatus &&
+			(!requested || !k8sNodes[storageNode.Name]):
+			// Once the request is withdrawn, the StorageNode is removed so that
+			// the node can join the storage cluster again as a new node
+			logrus.Debugf("Deleting StorageNode %s/%s of decommissioned node",
+				storageNode.Namespace, storageNode.Name)
+			err := c.client.Delete(context.TODO(), storageNode.DeepCopy())
+			if err != nil && !errors.IsNotFound(err) {
+				logrus.Warnf("Failed to delete StorageNode %s/%s: %v",
+					storageNode.Namespace, storageNode.Name, err)
+			}
+		case !requested && !decommissionStarted(condition):
+			logrus.Infof("Cancelling decommission of storage node %s", storageNode.Name)
+			c.removeDecommissionCondition(storageNode.DeepCopy())
+		}
+
+		if condition != nil && decommissionStarted(condition) {
+			nodesToStop[storageNode.Name] = true
+		}
+	}
+	return nodesToStop, nil
+}
+
+func (c *Controller) addStorageNodeFinalizer(storageNode *corev1alpha1.StorageNode) {
+	for _, finalizer := range storageNode.Finalizers {
+		if finalizer == StorageNodeFinalizer {
+			return
+		}
+	}
+	storageNode.Finalizers = append(storageNode.Finalizers, StorageNodeFinalizer)
+	if err := c.client.Update(context.TODO(), storageNode); err != nil {
+		logrus.Warnf("Failed to add finalizer to StorageNode %s/%s. %v",
+			storageNode.Namespace, storageNode.Name, err)
+	}
+}
+
+func (c *Controller) removeStorageNodeFinalizer(storageNode *corev1alpha1.StorageNode) {
+	finalizers := RemoveStorageNodeFinalizer(storageNode.Finalizers)
+	if len(finalizers) == len(storageNode.Finalizers) {
+		return
+	}
+	storageNode.Finalizers = finalizers
+	if err := c.client.Update(context.TODO(), storageNode); err != nil && !errors.IsNotFound(err) {
+		logrus.Warnf("Failed to remove finalizer from StorageNode %s/%s. %v",
+			storageNode.Namespace, storageNode.Name, err)
+	}
+}
+
+// removeStorageNodeFinalizers removes the finalizer from all the StorageNodes of
+// the cluster, so they are not left behind when the cluster is deleted
+func (c *Controller) removeStorageNodeFinalizers(cluster *corev1alpha1.StorageCluster) error {
+	storageNodeList := &corev1alpha1.StorageNodeList{}
+	err := c.client.List(context.TODO(), storageNodeList, &client.ListOptions{Namespace: cluster.Namespace})
+	if err != nil {
+		return fmt.Errorf("failed to get list of storage nodes: %v", err)
+	}
+	for _, storageNode := range storageNodeList.Items {
+		if metav1.IsControlledBy(&storageNode, cluster) {
+			c.removeStorageNodeFinalizer(storageNode.DeepCopy())
+		}
+	}
+	return nil
+}
+
+// RemoveStorageNodeFinalizer returns the given finalizers without the
+// StorageNode decommission finalizer
+func RemoveStorageNodeFinalizer(finalizers []string) []string {
+	newFinalizers := []string{}
+	for _, finalizer := range finalizers {
+		if finalizer != StorageNodeFinalizer {
+			newFinalizers = append(newFinalizers, finalizer)
+		}
+	}
+	return newFinalizers
+}
+
+func (c *Controller) removeDecommissionCondition(storageNode *corev1alpha1.StorageNode) {
+	conditions := make([]corev1alpha1.NodeCondition, 0, len(storageNode.Status.Conditions))
+	for _, condition := range storageNode.Status.Conditions {
+		if condition.Type != corev1alpha1.NodeDecommissionCondition {
+			conditions = append(conditions, condition)
+		}
+	}
+	storageNode.Status.Conditions = conditions
+	if err := c.client.Status().Update(context.TODO(), storageNode); err != nil {
+		logrus.Warnf("Failed to update status of StorageNode %s/%s. %v",
+			storageNode.Namespace, storageNode.Name, err)
+	}
+}
+
+// decommissionStarted returns true once the node has been drained and is being
+// removed from the storage cluster. The decommission cannot be cancelled then.
+func decommissionStarted(condition *corev1alpha1.NodeCondition) bool {
+	return condition.Status == corev1alpha1.NodeRemovingStatus ||
+		condition.Status == corev1alpha1.NodeWipingStatus ||
+		condition.Status == corev1alpha1.NodeDecommissionedStatus
+}
+
+func getDecommissionCondition(storageNode *corev1alpha1.StorageNode) *corev1alpha1.NodeCondition {
+	for _, condition := range storageNode.Status.Conditions {
+		if condition.Type == corev1alpha1.NodeDecommissionCondition {
+			return condition.DeepCopy()
+		}
+	}
+	return nil
+}
+
+// stopStoragePodsOnNodes removes the given nodes from the nodes needing storage
+// pods and adds the storage pods running on them to the pods to be deleted
+func stopStoragePodsOnNodes(
+	nodesToStop map[string]bool,
+	nodeToStoragePods map[string][]*v1.Pod,
+	nodesNeedingStoragePods, podsToDelete []string,
+) ([]string, []string) {
+	if len(nodesToStop) == 0 {
+		return nodesNeedingStoragePods, podsToDelete
+	}
+
+	nodesToStart := make([]string, 0, len(nodesNeedingStoragePods))
+	for _, nodeName := range nodesNeedingStoragePods {
+		if !nodesToStop[nodeName] {
+			nodesToStart = append(nodesToStart, nodeName)
+		}
+	}
+
+	deleting := make(map[string]bool)
+	for _, podName := range podsToDelete {
+		deleting[podName] = true
+	}
+	for nodeName := range nodesToStop {
+		for _, pod := range nodeToStoragePods[nodeName] {
+			if !deleting[pod.Name] && pod.DeletionTimestamp == nil {
+				podsToDelete = append(podsToDelete, pod.Name)
+				deleting[pod.Name] = true
+			}
+		}
+	}
+	return nodesToStart, podsToDelete
+}
@@ -0,0 +1,164 @@
+package storagecluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getNodesBeingDrained returns the nodes that have been cordoned and drained
+// during a rolling update and are waiting for their storage pods to be updated.
+func (c *Controller) getNodesBeingDrained() (map[string]*v1.Node, error) {
+	nodeList := &v1.NodeList{}
+	if err := c.client.List(context.TODO(), nodeList, &client.ListOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to get list of nodes: %v", err)
+	}
+
+	drainingNodes := make(map[string]*v1.Node)
+	for _, node := range nodeList.Items {
+		if _, draining := node.Annotations[annotationNodeDrain]; draining {
+			drainingNodes[node.Name] = node.DeepCopy()
+		}
+	}
+	return drainingNodes, nil
+}
+
+// drainNode cordons the given node and evicts the pods that use storage volumes
+// from it. Evictions respect the PodDisruptionBudgets of the pods, so they may
+// have to be retried in later reconciles. It returns true once there are no pods
+// using storage left on the node and the storage pod can be restarted.
+func (c *Controller) drainNode(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+) (bool, error) {
+	node := &v1.Node{}
+	err := c.client.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node)
+	if err != nil {
+		return false, fmt.Errorf("failed to get node %s: %v", nodeName, err)
+	}
+
+	if _, draining := node.Annotations[annotationNodeDrain]; !draining {
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string)
+		}
+		// Remember if the node was cordoned by us, so we do not uncordon
+		// nodes that were already cordoned by someone else
+		node.Annotations[annotationNodeDrain] = strconv.FormatBool(!node.Spec.Unschedulable)
+		node.Spec.Unschedulable = true
+		if err := c.client.Update(context.TODO(), node); err != nil {
+			return false, fmt.Errorf("failed to cordon node %s: %v", nodeName, err)
+		}
+		logrus.Infof("Cordoned node %s to update the storage pod", nodeName)
+		c.updateNodeUpgradeCondition(cluster, nodeName, corev1alpha1.NodeCordonedStatus,
+			"Cordoned node to update the storage pod")
+	}
+
+	pods, err := c.Driver.GetPodsUsingStorage(cluster, nodeName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get pods using storage on node %s: %v", nodeName, err)
+	}
+	if len(pods) == 0 {
+		return true, nil
+	}
+
+	var blockedPods []string
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		eviction := &policyv1beta1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+			},
+		}
+		err := c.kubeClient.CoreV1().Pods(pod.Namespace).Evict(eviction)
+		if errors.IsTooManyRequests(err) {
+			// The eviction would violate the PodDisruptionBudget of the pod
+			blockedPods = append(blockedPods, pod.Namespace+"/"+pod.Name)
+		} else if err != nil && !errors.IsNotFound(err) {
+			message := fmt.Sprintf("Failed to evict pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			c.updateNodeUpgradeCondition(cluster, nodeName, corev1alpha1.NodeFailedStatus, message)
+			return false, fmt.Errorf("failed to evict pod %s/%s from node %s: %v",
+				pod.Namespace, pod.Name, nodeName, err)
+		} else if err == nil {
+			logrus.Infof("Evicted pod %s/%s from node %s", pod.Namespace, pod.Name, nodeName)
+		}
+	}
+
+	message := fmt.Sprintf("Evicting %d pods using storage volumes", len(pods))
+	if len(blockedPods) > 0 {
+		message = fmt.Sprintf("%s. Eviction of pods %v is blocked by their PodDisruptionBudgets",
+			message, blockedPods)
+	}
+	c.updateNodeUpgradeCondition(cluster, nodeName, corev1alpha1.NodeDrainingStatus, message)
+	return false, nil
+}
+
+// uncordonUpdatedNodes uncordons the drained nodes once the updated storage pod
+// on them is ready and the storage node is back online.
+func (c *Controller) uncordonUpdatedNodes(
+	cluster *corev1alpha1.StorageCluster,
+	drainingNodes map[string]*v1.Node,
+	newPods []*v1.Pod,
+) error {
+	for _, pod := range newPods {
+		node, draining := drainingNodes[pod.Spec.NodeName]
+		if !draining || !podutil.IsPodReady(pod) {
+			continue
+		}
+
+		storageNode := &corev1alpha1.StorageNode{}
+		err := c.client.Get(
+			context.TODO(),
+			types.NamespacedName{Name: node.Name, Namespace: cluster.Namespace},
+			storageNode,
+		)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get StorageNode %s/%s: %v",
+				cluster.Namespace, node.Name, err)
+		} else if err == nil && storageNode.Status.Phase != string(corev1alpha1.NodeOnlineStatus) {
+			logrus.Debugf("Waiting for storage node %s to come online before uncordoning it", node.Name)
+			continue
+		}
+
+		cordonedByUs, _ := strconv.ParseBool(node.Annotations[annotationNodeDrain])
+		delete(node.Annotations, annotationNodeDrain)
+		if cordonedByUs {
+			node.Spec.Unschedulable = false
+		}
+		if err := c.client.Update(context.TODO(), node); err != nil {
+			return fmt.Errorf("failed to uncordon node %s: %v", node.Name, err)
+		}
+		logrus.Infof("Storage pod on node %s is updated. Uncordoned the node", node.Name)
+		delete(drainingNodes, node.Name)
+		c.updateNodeUpgradeCondition(cluster, node.Name, corev1alpha1.NodeSucceededStatus,
+			"Storage pod is updated and the node is online")
+	}
+	return nil
+}
+
+// updateNodeUpgradeCondition records the progress of the update of the given
+// node in the conditions of its StorageNode
+func (c *Controller) updateNodeUpgradeCondition(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+	status corev1alpha1.NodeConditionStatus,
+	message string,
+) {
+	c.updateStorageNodeCondition(cluster, nodeName, "", &corev1alpha1.NodeCondition{
+		Type:    corev1alpha1.NodeUpgradeCondition,
+		Status:  status,
+		Message: message,
+	})
+}
@@ -0,0 +1,358 @@
+package storagecluster
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/libopenstorage/operator/pkg/util"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationSkipPreflight annotation on a StorageNode to create the storage pod
+	// on the node even if it has failed the preflight checks. Defaults to false.
+	AnnotationSkipPreflight = operatorPrefix + "/skip-preflight"
+	labelKeyPreflight       = operatorPrefix + "/preflight"
+	labelKeyJobName         = "job-name"
+	preflightTimeout        = 5 * time.Minute
+)
+
+// runPreflightChecks runs the preflight checks on the given nodes that need a
+// storage pod and returns the nodes on which the storage pods can be created.
+// The checks run only once, before the first storage pod is created on a node,
+// and their result is recorded in the StorageNode of the node. The checks on
+// nodes that do not need a storage pod anymore are cleaned up.
+func (c *Controller) runPreflightChecks(
+	cluster *corev1alpha1.StorageCluster,
+	nodesNeedingStoragePods []string,
+	nodeToStoragePods map[string][]*v1.Pod,
+) ([]string, error) {
+	storageNodeList := &corev1alpha1.StorageNodeList{}
+	err := c.client.List(context.TODO(), storageNodeList, &client.ListOptions{Namespace: cluster.Namespace})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list of storage nodes: %v", err)
+	}
+	storageNodes := make(map[string]*corev1alpha1.StorageNode)
+	for _, storageNode := range storageNodeList.Items {
+		if metav1.IsControlledBy(&storageNode, cluster) {
+			storageNodes[storageNode.Name] = storageNode.DeepCopy()
+		}
+	}
+
+	jobList := &batchv1.JobList{}
+	err = c.client.List(context.TODO(), jobList, &client.ListOptions{
+		Namespace:     cluster.Namespace,
+		LabelSelector: labels.SelectorFromSet(preflightLabels(cluster)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list of preflight jobs: %v", err)
+	}
+	jobs := make(map[string]*batchv1.Job)
+	for _, job := range jobList.Items {
+		if metav1.IsControlledBy(&job, cluster) {
+			jobs[job.Spec.Template.Spec.NodeName] = job.DeepCopy()
+		}
+	}
+
+	readyNodes := make([]string, 0)
+	needsStoragePod := make(map[string]bool)
+	for _, nodeName := range nodesNeedingStoragePods {
+		needsStoragePod[nodeName] = true
+		ready, err := c.preflightNode(cluster, nodeName, storageNodes[nodeName], jobs[nodeName])
+		if err != nil {
+			logrus.Warnf("Failed to run preflight checks on node %s: %v", nodeName, err)
+		} else if ready {
+			readyNodes = append(readyNodes, nodeName)
+		}
+	}
+
+	for nodeName, job := range jobs {
+		if !needsStoragePod[nodeName] {
+			if err := c.deletePreflightJob(job); err != nil {
+				logrus.Warnf("Failed to delete preflight job on node %s: %v", nodeName, err)
+			}
+		}
+	}
+	for nodeName, storageNode := range storageNodes {
+		if !needsStoragePod[nodeName] && len(nodeToStoragePods[nodeName]) == 0 &&
+			isPreflightPending(storageNode) {
+			logrus.Debugf("Deleting StorageNode %s/%s of node that does not need a storage pod anymore",
+				storageNode.Namespace, storageNode.Name)
+			err := c.client.Delete(context.TODO(), storageNode)
+			if err != nil && !errors.IsNotFound(err) {
+				logrus.Warnf("Failed to delete StorageNode %s/%s: %v",
+					storageNode.Namespace, storageNode.Name, err)
+			}
+		}
+	}
+
+	return readyNodes, nil
+}
+
+// preflightNode returns true if the storage pod can be created on the given node.
+// It starts the preflight checks on the node if they have not been run yet and
+// records their result once they are done.
+func (c *Controller) preflightNode(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+	storageNode *corev1alpha1.StorageNode,
+	job *batchv1.Job,
+) (bool, error) {
+	skipped := storageNode != nil && isPreflightSkipped(storageNode)
+	if !preflightEnabled(cluster) || skipped {
+		if job != nil {
+			if err := c.deletePreflightJob(job); err != nil {
+				return false, err
+			}
+		}
+		if isPreflightPending(storageNode) {
+			message := "Preflight checks are disabled"
+			if skipped {
+				message = "Preflight checks are skipped on the node"
+			}
+			c.updateNodePreflightCondition(cluster, nodeName, corev1alpha1.NodeSkippedStatus, message)
+		}
+		return true, nil
+	}
+
+	if job != nil {
+		finished, passed, jobMessage := preflightJobResult(job)
+		if !finished {
+			c.updateNodePreflightCondition(cluster, nodeName, corev1alpha1.NodeInitStatus,
+				"Running preflight checks")
+			return false, nil
+		} else if passed {
+			c.updateNodePreflightCondition(cluster, nodeName, corev1alpha1.NodeSucceededStatus,
+				"Preflight checks passed")
+			return true, c.deletePreflightJob(job)
+		}
+
+		// The failed job is kept so the checks are not run again. The job has to be
+		// deleted to rerun the checks, or the checks have to be skipped on the node.
+		message := c.preflightFailureMessage(job, jobMessage)
+		if c.updateNodePreflightCondition(cluster, nodeName, corev1alpha1.NodeFailedStatus, message) {
+			c.warningEvent(cluster, util.FailedPreflightReason,
+				fmt.Sprintf("Not creating storage pod on node %s. %s", nodeName, message))
+		}
+		return false, nil
+	}
+
+	condition := getPreflightCondition(storageNode)
+	if storageNode != nil && condition == nil {
+		// The node has been running a storage pod from before the checks were enabled
+		return true, nil
+	} else if condition != nil && (condition.Status == corev1alpha1.NodeSucceededStatus ||
+		condition.Status == corev1alpha1.NodeSkippedStatus) {
+		return true, nil
+	}
+	return false, c.startPreflightJob(cluster, nodeName, storageNode)
+}
+
+// startPreflightJob creates a job that runs the preflight checks of the storage
+// driver on the given node
+func (c *Controller) startPreflightJob(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+	storageNode *corev1alpha1.StorageNode,
+) error {
+	node := &v1.Node{}
+	err := c.client.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node)
+	if err != nil {
+		return fmt.Errorf("failed to get node %s: %v", nodeName, err)
+	}
+
+	clusterForNode := cluster.DeepCopy()
+	clusterForNode.Spec = *clusterSpecForNode(node, &cluster.Spec)
+	podSpec, err := c.Driver.GetPreflightPodSpec(clusterForNode, nodeName)
+	if err != nil {
+		return fmt.Errorf("failed to create preflight pod spec: %v", err)
+	}
+	podSpec.NodeName = nodeName
+	podSpec.RestartPolicy = v1.RestartPolicyNever
+
+	backoffLimit := int32(0)
+	activeDeadlineSeconds := int64(preflightTimeout.Seconds())
+	jobLabels := preflightLabels(cluster)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            preflightJobName(cluster.Name, nodeName),
+			Namespace:       cluster.Namespace,
+			Labels:          jobLabels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cluster, controllerKind)},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabels,
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+	err = c.client.Create(context.TODO(), job)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create preflight job %s/%s: %v", job.Namespace, job.Name, err)
+	}
+	logrus.Infof("Running preflight checks on node %s", nodeName)
+
+	if storageNode == nil {
+		c.createStorageNode(cluster, nodeName)
+	}
+	c.updateNodePreflightCondition(cluster, nodeName, corev1alpha1.NodeInitStatus,
+		"Running preflight checks")
+	return nil
+}
+
+// preflightFailureMessage returns the checks that failed in the given job. The
+// failed checks are reported in the termination message of the preflight pod.
+func (c *Controller) preflightFailureMessage(job *batchv1.Job, jobMessage string) string {
+	podList := &v1.PodList{}
+	err := c.client.List(context.TODO(), podList, &client.ListOptions{
+		Namespace:     job.Namespace,
+		LabelSelector: labels.SelectorFromSet(map[string]string{labelKeyJobName: job.Name}),
+	})
+	if err != nil {
+		logrus.Warnf("Failed to get pods of preflight job %s/%s: %v", job.Namespace, job.Name, err)
+	}
+
+	failures := make([]string, 0)
+	for _, pod := range podList.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated == nil {
+				continue
+			}
+			for _, line := range strings.Split(status.State.Terminated.Message, "\n") {
+				if line = strings.TrimSpace(line); len(line) > 0 {
+					failures = append(failures, line)
+				}
+			}
+		}
+	}
+	if len(failures) == 0 && len(jobMessage) > 0 {
+		failures = append(failures, jobMessage)
+	}
+
+	message := "Preflight checks failed"
+	if len(failures) > 0 {
+		message = fmt.Sprintf("%s: %s", message, strings.Join(failures, ", "))
+	}
+	return message
+}
+
+func (c *Controller) deletePreflightJob(job *batchv1.Job) error {
+	err := c.client.Delete(context.TODO(), job,
+		client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete preflight job %s/%s: %v", job.Namespace, job.Name, err)
+	}
+	return nil
+}
+
+// updateNodePreflightCondition records the result of the preflight checks on the
+// given node in its StorageNode. The node is in the failed phase if it has failed
+// the checks, else it is initializing until the storage pod is created on it.
+// It returns true if the StorageNode has been updated.
+func (c *Controller) updateNodePreflightCondition(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+	status corev1alpha1.NodeConditionStatus,
+	message string,
+) bool {
+	phase := string(corev1alpha1.NodeInitStatus)
+	if status == corev1alpha1.NodeFailedStatus {
+		phase = string(corev1alpha1.NodeFailedStatus)
+	}
+	return c.updateStorageNodeCondition(cluster, nodeName, phase, &corev1alpha1.NodeCondition{
+		Type:    corev1alpha1.NodePreflightCondition,
+		Status:  status,
+		Message: message,
+	})
+}
+
+// preflightJobResult returns whether the given preflight job has finished and
+// whether the checks have passed. If the job has failed, it also returns the
+// reason of the failure reported by the job.
+func preflightJobResult(job *batchv1.Job) (finished, passed bool, message string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, true, ""
+		case batchv1.JobFailed:
+			return true, false, condition.Message
+		}
+	}
+	return false, false, ""
+}
+
+func getPreflightCondition(storageNode *corev1alpha1.StorageNode) *corev1alpha1.NodeCondition {
+	if storageNode == nil {
+		return nil
+	}
+	for _, condition := range storageNode.Status.Conditions {
+		if condition.Type == corev1alpha1.NodePreflightCondition {
+			return condition.DeepCopy()
+		}
+	}
+	return nil
+}
+
+// isPreflightPending returns true if the preflight checks on the node are still
+// running or have failed
+func isPreflightPending(storageNode *corev1alpha1.StorageNode) bool {
+	condition := getPreflightCondition(storageNode)
+	return condition != nil &&
+		(condition.Status == corev1alpha1.NodeInitStatus ||
+			condition.Status == corev1alpha1.NodeFailedStatus)
+}
+
+func isPreflightSkipped(storageNode *corev1alpha1.StorageNode) bool {
+	skipped, err := strconv.ParseBool(storageNode.Annotations[AnnotationSkipPreflight])
+	return err == nil && skipped
+}
+
+func preflightEnabled(cluster *corev1alpha1.StorageCluster) bool {
+	return cluster.Spec.Preflight != nil && cluster.Spec.Preflight.Enabled
+}
+
+func preflightLabels(cluster *corev1alpha1.StorageCluster) map[string]string {
+	return map[string]string{
+		labelKeyName:      cluster.Name,
+		labelKeyPreflight: "true",
+	}
+}
+
+// preflightJobName returns the name of the preflight job of the given node.
+// The job name has to be a valid label value, so the node name is hashed and
+// the cluster name is truncated to fit the maximum length. The cluster name is
+// hashed too, so truncated names of different clusters do not collide.
+func preflightJobName(clusterName, nodeName string) string {
+	hasher := fnv.New32a()
+	hasher.Write([]byte(clusterName))
+	hasher.Write([]byte{0})
+	hasher.Write([]byte(nodeName))
+	suffix := fmt.Sprintf("-preflight-%s", rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())))
+	if maxLength := validation.LabelValueMaxLength - len(suffix); len(clusterName) > maxLength {
+		// The name cannot have a dot next to a dash
+		clusterName = strings.TrimRight(clusterName[:maxLength], ".-")
+	}
+	return clusterName + suffix
+}
@@ -39,6 +39,7 @@ import (
 	operatorops "github.com/portworx/sched-ops/k8s/operator"
 	"github.com/sirupsen/logrus"
 	apps "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -60,6 +61,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -69,7 +71,15 @@ const (
 	ControllerName = "storagecluster-controller"
 	// AnnotationDisableStorage annotation to disable the storage pods from running.
 	// Defaults to false value.
-	AnnotationDisableStorage            = operatorPrefix + "/disable-storage"
+	AnnotationDisableStorage = operatorPrefix + "/disable-storage"
+	// LabelStoragePodSecret label on secrets, controlled by a StorageCluster, that
+	// the storage pods read at startup. A change in such a secret is rolled out to
+	// the storage pods like a change in the spec.
+	LabelStoragePodSecret = operatorPrefix + "/storage-pod-secret"
+	// AnnotationPromoteCanary annotation to promote a canary rollout. The value is
+	// the update revision from the rollout status that should be rolled out to the
+	// remaining storage pods.
+	AnnotationPromoteCanary             = operatorPrefix + "/promote-canary"
 	slowStartInitialBatchSize           = 1
 	validateCRDInterval                 = 5 * time.Second
 	validateCRDTimeout                  = 1 * time.Minute
@@ -77,6 +87,9 @@ const (
 	labelKeyName                        = operatorPrefix + "/name"
 	labelKeyDriverName                  = operatorPrefix + "/driver"
 	annotationNodeLabels                = operatorPrefix + "/node-labels"
+	annotationNodeGroupHash             = operatorPrefix + "/node-group-hash"
+	annotationNodeDrain                 = operatorPrefix + "/drain-for-update"
+	annotationSecretChecksum            = operatorPrefix + "/secret-checksum"
 	deleteFinalizerName                 = operatorPrefix + "/delete"
 	nodeNameIndex                       = "nodeName"
 	defaultStorageClusterUniqueLabelKey = apps.ControllerRevisionHashLabelKey
@@ -102,6 +115,7 @@ type Controller struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
 	client                        client.Client
+	kubeClient                    kubernetes.Interface
 	scheme                        *runtime.Scheme
 	recorder                      record.EventRecorder
 	podControl                    k8scontroller.PodControlInterface
@@ -145,6 +159,32 @@ func (c *Controller) Init(mgr manager.Manager) error {
 		return err
 	}
 
+	// Watch for changes to preflight Jobs that belong to StorageCluster object
+	err = ctrl.Watch(
+		&source.Kind{Type: &batchv1.Job{}},
+		&handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &corev1alpha1.StorageCluster{},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Watch for spec changes to StorageNodes that belong to StorageCluster object.
+	// Status updates are ignored as they are made by the operator itself.
+	err = ctrl.Watch(
+		&source.Kind{Type: &corev1alpha1.StorageNode{}},
+		&handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &corev1alpha1.StorageCluster{},
+		},
+		predicate.GenerationChangedPredicate{},
+	)
+	if err != nil {
+		return err
+	}
+
 	// Watch for changes to ControllerRevisions that belong to StorageCluster object
 	err = ctrl.Watch(
 		&source.Kind{Type: &apps.ControllerRevision{}},
@@ -157,10 +197,23 @@ func (c *Controller) Init(mgr manager.Manager) error {
 		return err
 	}
 
+	// Watch for changes to the secrets used by the storage pods, so that the
+	// storage pods can be updated with the new credentials
+	err = ctrl.Watch(
+		&source.Kind{Type: &v1.Secret{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(c.storageClustersForSecret),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
 	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
 	if err != nil {
 		return fmt.Errorf("error getting kubernetes client: %v", err)
 	}
+	c.kubeClient = clientset
 	// Create pod control interface object to manage pods under storage cluster
 	c.podControl = k8scontroller.RealPodControl{
 		KubeClient: clientset,
@@ -211,8 +264,14 @@ func (c *Controller) Reconcile(request reconcile.Request) (reconcile.Result, err
 		return reconcile.Result{}, err
 	}
 
+	if err := c.syncLegacyConditions(cluster); err != nil {
+		c.warningEvent(cluster, util.FailedSyncReason, err.Error())
+		return reconcile.Result{}, err
+	}
+
 	if err := c.syncStorageCluster(cluster); err != nil {
 		c.warningEvent(cluster, util.FailedSyncReason, err.Error())
+		c.setReconcileFailedCondition(cluster, err)
 		return reconcile.Result{}, err
 	}
 
@@ -223,7 +282,7 @@ func (c *Controller) validate(cluster *corev1alpha1.StorageCluster) error {
 	if err := c.validateK8sVersion(); err != nil {
 		return err
 	}
-	if err := c.validateSingleCluster(cluster); err != nil {
+	if err := c.validateSingleClusterInNamespace(cluster); err != nil {
 		return err
 	}
 	return nil
@@ -248,7 +307,7 @@ func (c *Controller) validateK8sVersion() error {
 	return nil
 }
 
-func (c *Controller) validateSingleCluster(current *corev1alpha1.StorageCluster) error {
+func (c *Controller) validateSingleClusterInNamespace(current *corev1alpha1.StorageCluster) error {
 	// If the current cluster has the delete finalizer then it has been already reconciled
 	for _, finalizer := range current.Finalizers {
 		if finalizer == deleteFinalizerName {
@@ -256,22 +315,24 @@ func (c *Controller) validateSingleCluster(current *corev1alpha1.StorageCluster)
 		}
 	}
 
-	// If the cluster current cluster does not have the finalizer, check if any existing
-	// StorageClusters have the finalizer. If none of them have it, then current just got
-	// lucky and is the only StorageCluster that will get reconciled; else if a cluster
-	// is found with the finalizer then we cannot process current as we support only 1.
+	// The objects created for a StorageCluster are named the same for every
+	// cluster, so there can only be one StorageCluster in a namespace. If another
+	// cluster in the namespace has the finalizer, then it is already reconciled
+	// and the current cluster cannot be processed.
 	clusterList := &corev1alpha1.StorageClusterList{}
-	err := c.client.List(context.TODO(), clusterList, &client.ListOptions{})
+	err := c.client.List(context.TODO(), clusterList, &client.ListOptions{
+		Namespace: current.Namespace,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to list storage clusters. %v", err)
 	}
 	for _, cluster := range clusterList.Items {
-		if cluster.Name == current.Name && cluster.Namespace == current.Namespace {
+		if cluster.Name == current.Name {
 			continue
 		}
 		for _, finalizer := range cluster.Finalizers {
 			if finalizer == deleteFinalizerName {
-				return fmt.Errorf("only one StorageCluster is allowed in a Kubernetes cluster. "+
+				return fmt.Errorf("only one StorageCluster is allowed in a namespace. "+
 					"StorageCluster %s/%s already exists", cluster.Namespace, cluster.Name)
 			}
 		}
@@ -287,8 +348,7 @@ func (c *Controller) RegisterCRD() error {
 	if err != nil {
 		return err
 	}
-	err = apiextensionsops.Instance().RegisterCRD(crd)
-	if err != nil && !errors.IsAlreadyExists(err) {
+	if err = registerCRD(crd); err != nil {
 		return err
 	}
 
@@ -306,8 +366,7 @@ func (c *Controller) RegisterCRD() error {
 	if err != nil {
 		return err
 	}
-	err = apiextensionsops.Instance().RegisterCRD(crd)
-	if err != nil && !errors.IsAlreadyExists(err) {
+	if err = registerCRD(crd); err != nil {
 		return err
 	}
 
@@ -347,6 +406,19 @@ func (c *Controller) syncStorageCluster(
 			cluster.Namespace, cluster.Name, err)
 	}
 
+	// Record the checksum of the secrets used by the storage pods, so that the
+	// storage pods are updated when the secrets change
+	if err := c.syncSecretChecksum(cluster); err != nil {
+		return fmt.Errorf("failed to update secret checksum of StorageCluster %v/%v: %v",
+			cluster.Namespace, cluster.Name, err)
+	}
+
+	// Restore the spec from a previous revision if a rollback is requested. The
+	// storage pods are updated in the reconcile triggered by the spec update.
+	if cluster.Spec.RollbackTo != nil {
+		return c.rollback(cluster)
+	}
+
 	// Ensure Stork is deployed with right configuration
 	if err := c.syncStork(cluster); err != nil {
 		return err
@@ -362,7 +434,7 @@ func (c *Controller) syncStorageCluster(
 
 	// TODO: Don't process a storage cluster until all its previous creations and
 	// deletions have been processed.
-	err = c.manage(cluster, hash)
+	desiredNodes, err := c.manage(cluster, hash)
 	if err != nil {
 		return err
 	}
@@ -381,6 +453,16 @@ func (c *Controller) syncStorageCluster(
 			cluster.Namespace, cluster.Name, err)
 	}
 
+	if err := c.updateRolloutStatus(cluster, hash); err != nil {
+		logrus.Warnf("Failed to get rollout status of StorageCluster %v/%v: %v",
+			cluster.Namespace, cluster.Name, err)
+	}
+	if err := c.updatePodStatus(cluster, hash, desiredNodes); err != nil {
+		logrus.Warnf("Failed to get status of storage pods of StorageCluster %v/%v: %v",
+			cluster.Namespace, cluster.Name, err)
+	}
+	cluster.Status.ObservedGeneration = cluster.Generation
+
 	// Update status of the cluster
 	return c.updateStorageClusterStatus(cluster)
 }
@@ -408,45 +490,47 @@ func (c *Controller) deleteStorageCluster(
 		return err
 	}
 
+	// The storage nodes are not decommissioned when the whole cluster is deleted
+	if err := c.removeStorageNodeFinalizers(cluster); err != nil {
+		return err
+	}
+
 	if deleteFinalizerExists(cluster) {
 		toDelete := cluster.DeepCopy()
-		deleteClusterCondition, driverErr := c.Driver.DeleteStorage(toDelete)
+		deleteCondition, driverErr := c.Driver.DeleteStorage(toDelete)
 		if driverErr != nil {
 			msg := fmt.Sprintf("Driver failed to delete storage. %v", driverErr)
 			c.warningEvent(toDelete, util.FailedSyncReason, msg)
 		}
-		// Check if there is an existing delete condition and overwrite it
-		foundIndex := -1
-		for i, deleteCondition := range toDelete.Status.Conditions {
-			if deleteCondition.Type == corev1alpha1.ClusterConditionTypeDelete {
-				foundIndex = i
-				break
-			}
-		}
-		if foundIndex == -1 {
-			if deleteClusterCondition == nil {
-				deleteClusterCondition = &corev1alpha1.ClusterCondition{
-					Type:   corev1alpha1.ClusterConditionTypeDelete,
-					Status: corev1alpha1.ClusterOperationInProgress,
+		if deleteCondition == nil {
+			// Keep the existing delete condition unless we have a newer one
+			if existing := getClusterCondition(toDelete, corev1alpha1.ClusterConditionTypeDeleting); existing != nil {
+				deleteCondition = existing.DeepCopy()
+			} else {
+				deleteCondition = &corev1alpha1.ClusterCondition{
+					Type:   corev1alpha1.ClusterConditionTypeDeleting,
+					Status: corev1alpha1.ConditionTrue,
+					Reason: corev1alpha1.ClusterDeleteInProgressReason,
 				}
 				if driverErr != nil {
-					deleteClusterCondition.Reason = err.Error()
+					deleteCondition.Message = driverErr.Error()
 				}
 			}
-			foundIndex = len(toDelete.Status.Conditions)
-			toDelete.Status.Conditions = append(toDelete.Status.Conditions, *deleteClusterCondition)
-		} else if deleteClusterCondition != nil {
-			// Update existing delete condition only if we have the latest Delete condition
-			toDelete.Status.Conditions[foundIndex] = *deleteClusterCondition
+		} else {
+			// Storage drivers report the progress with the legacy Delete condition
+			translated := toDeletingCondition(*deleteCondition)
+			deleteCondition = &translated
 		}
+		setClusterCondition(toDelete, *deleteCondition)
 
-		toDelete.Status.Phase = string(corev1alpha1.ClusterConditionTypeDelete) + string(toDelete.Status.Conditions[foundIndex].Status)
+		// The reason of the delete condition is the phase of the cluster
+		toDelete.Status.Phase = deleteCondition.Reason
 		if err := k8sutil.UpdateStorageClusterStatus(c.client, toDelete); err != nil && !errors.IsNotFound(err) {
 			return fmt.Errorf("error updating delete status for StorageCluster %v/%v: %v",
 				toDelete.Namespace, toDelete.Name, err)
 		}
 
-		if toDelete.Status.Conditions[foundIndex].Status == corev1alpha1.ClusterOperationCompleted {
+		if deleteCondition.Reason == corev1alpha1.ClusterDeleteCompletedReason {
 			newFinalizers := removeDeleteFinalizer(toDelete.Finalizers)
 			toDelete.Finalizers = newFinalizers
 			if err := c.client.Update(context.TODO(), toDelete); err != nil && !errors.IsNotFound(err) {
@@ -465,26 +549,30 @@ func (c *Controller) updateStorageClusterStatus(
 	cluster *corev1alpha1.StorageCluster,
 ) error {
 	toUpdate := cluster.DeepCopy()
-	if err := c.Driver.UpdateStorageClusterStatus(toUpdate); err != nil {
+	err := c.Driver.UpdateStorageClusterStatus(toUpdate)
+	if err != nil {
 		c.warningEvent(cluster, util.FailedSyncReason, err.Error())
 	}
+	updateClusterConditions(toUpdate, err)
 	return k8sutil.UpdateStorageClusterStatus(c.client, toUpdate)
 }
 
+// manage creates and deletes the storage pods on the nodes as per the placement
+// of the cluster. It returns the nodes that should be running the storage pods.
 func (c *Controller) manage(
 	cluster *corev1alpha1.StorageCluster,
 	hash string,
-) error {
+) (map[string]bool, error) {
 	// Run the pre install hook for the driver to ensure we are ready to create storage pods
 	if err := c.Driver.PreInstall(cluster); err != nil {
-		return fmt.Errorf("failed to run preinstall hooks for %v/%v: %v",
+		return nil, fmt.Errorf("failed to run preinstall hooks for %v/%v: %v",
 			cluster.Namespace, cluster.Name, err)
 	}
 
 	// Find out the pods which are created for the nodes by StorageCluster
 	nodeToStoragePods, err := c.getNodeToStoragePods(cluster)
 	if err != nil {
-		return fmt.Errorf("couldn't get node to storage cluster pods mapping for storage cluster %v: %v",
+		return nil, fmt.Errorf("couldn't get node to storage cluster pods mapping for storage cluster %v: %v",
 			cluster.Name, err)
 	}
 
@@ -493,27 +581,15 @@ func (c *Controller) manage(
 	nodeList := &v1.NodeList{}
 	err = c.client.List(context.TODO(), nodeList, &client.ListOptions{})
 	if err != nil {
-		return fmt.Errorf("couldn't get list of nodes when syncing storage cluster %#v: %v",
+		return nil, fmt.Errorf("couldn't get list of nodes when syncing storage cluster %#v: %v",
 			cluster, err)
 	}
-	var (
-		nodesNeedingStoragePods, podsToDelete []string
-		cloudProviderName                     string
-	)
+	var nodesNeedingStoragePods, podsToDelete []string
+	desiredNodes := make(map[string]bool)
+	nodesOutOfPlacement := make(map[string]bool)
 	zoneMap := make(map[string]int)
 
-	for _, node := range nodeList.Items {
-		// Get the cloud provider
-		// From kubernetes node spec:  <ProviderName>://<ProviderSpecificNodeID>
-		if len(node.Spec.ProviderID) != 0 {
-			tokens := strings.Split(node.Spec.ProviderID, "://")
-			if len(tokens) == 2 {
-				cloudProviderName = tokens[0]
-				break
-			} // else provider id is invalid
-		}
-	}
-
+	cloudProviderName := getCloudProviderName(nodeList.Items)
 	cloudProvider := cloudprovider.New(cloudProviderName)
 
 	for _, node := range nodeList.Items {
@@ -530,22 +606,188 @@ func (c *Controller) manage(
 		logrus.Debugf("Failed to update driver: %v", err)
 	}
 
+	// Nodes claimed by other StorageClusters are left to those clusters, so that
+	// multiple clusters with overlapping placement do not run on the same node
+	claimedNodes, err := c.getNodesClaimedByOtherClusters(cluster, nodeList.Items, nodeToStoragePods)
+	if err != nil {
+		return nil, err
+	}
+	c.reportClaimedNodes(cluster, claimedNodes)
+
 	for _, node := range nodeList.Items {
-		nodesNeedingStoragePodsOnNode, podsToDeleteOnNode, err := c.podsShouldBeOnNode(&node, nodeToStoragePods, cluster)
+		if _, claimed := claimedNodes[node.Name]; claimed {
+			continue
+		}
+		wantToRun, nodesNeedingStoragePodsOnNode, podsToDeleteOnNode, err := c.podsShouldBeOnNode(&node, nodeToStoragePods, cluster)
 		if err != nil {
 			continue
 		}
+		if wantToRun {
+			desiredNodes[node.Name] = true
+		} else if storagePodsEnabled(cluster) && !util.PlacementMatchesNode(cluster.Spec.Placement, &node) {
+			// Only nodes removed from the placement of the cluster are decommissioned,
+			// and not the ones where the storage pods cannot run for now, like nodes
+			// with taints that are not tolerated
+			nodesOutOfPlacement[node.Name] = true
+		}
 
 		nodesNeedingStoragePods = append(nodesNeedingStoragePods, nodesNeedingStoragePodsOnNode...)
 		podsToDelete = append(podsToDelete, podsToDeleteOnNode...)
 	}
 
+	// Storage pods are stopped on nodes that are being removed from the storage cluster
+	nodesToStop, err := c.syncNodeDecommission(cluster, nodeList.Items, nodesOutOfPlacement)
+	if err != nil {
+		return nil, err
+	}
+	for nodeName := range nodesToStop {
+		delete(desiredNodes, nodeName)
+	}
+	nodesNeedingStoragePods, podsToDelete = stopStoragePodsOnNodes(
+		nodesToStop, nodeToStoragePods, nodesNeedingStoragePods, podsToDelete)
+
+	// Storage pods are only created on nodes that have passed the preflight checks
+	nodesNeedingStoragePods, err = c.runPreflightChecks(cluster, nodesNeedingStoragePods, nodeToStoragePods)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := c.syncNodes(cluster, podsToDelete, nodesNeedingStoragePods, hash); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
-	// TODO: Update the status of the pods in the CR (available, running, etc)
+	return desiredNodes, nil
+}
+
+// getNodesClaimedByOtherClusters returns the nodes selected by the given cluster
+// that belong to other StorageClusters, mapped to the name of the owning cluster.
+// A node belongs to the cluster already running a storage pod on it; if there is
+// none, it belongs to the oldest cluster whose placement selects the node.
+func (c *Controller) getNodesClaimedByOtherClusters(
+	cluster *corev1alpha1.StorageCluster,
+	nodes []v1.Node,
+	nodeToStoragePods map[string][]*v1.Pod,
+) (map[string]string, error) {
+	clusterList := &corev1alpha1.StorageClusterList{}
+	err := c.client.List(context.TODO(), clusterList, &client.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage clusters: %v", err)
+	}
+
+	otherClusters := make(map[types.UID]*corev1alpha1.StorageCluster)
+	for _, other := range clusterList.Items {
+		if other.UID != cluster.UID && other.DeletionTimestamp == nil {
+			otherClusters[other.UID] = other.DeepCopy()
+		}
+	}
+	claimedNodes := make(map[string]string)
+	if len(otherClusters) == 0 {
+		return claimedNodes, nil
+	}
+
+	// Find the nodes already running storage pods of the other clusters
+	podList := &v1.PodList{}
+	err = c.client.List(context.TODO(), podList, &client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(c.Driver.GetSelectorLabels()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage pods: %v", err)
+	}
+	nodeToOtherCluster := make(map[string]*corev1alpha1.StorageCluster)
+	for _, pod := range podList.Items {
+		controllerRef := metav1.GetControllerOf(&pod)
+		if controllerRef == nil || controllerRef.Kind != controllerKind.Kind ||
+			len(pod.Spec.NodeName) == 0 {
+			continue
+		}
+		if other, exists := otherClusters[controllerRef.UID]; exists {
+			nodeToOtherCluster[pod.Spec.NodeName] = other
+		}
+	}
+
+	clusterKey := func(sc *corev1alpha1.StorageCluster) string {
+		return sc.Namespace + "/" + sc.Name
+	}
+	isOlder := func(a, b *corev1alpha1.StorageCluster) bool {
+		if a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return clusterKey(a) < clusterKey(b)
+		}
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+
+	for _, node := range nodes {
+		if !util.PlacementMatchesNode(cluster.Spec.Placement, &node) {
+			continue
+		}
+		var owner *corev1alpha1.StorageCluster
+		if other, exists := nodeToOtherCluster[node.Name]; exists {
+			owner = other
+		} else if len(nodeToStoragePods[node.Name]) == 0 {
+			for _, other := range otherClusters {
+				if util.PlacementMatchesNode(other.Spec.Placement, &node) &&
+					isOlder(other, cluster) &&
+					(owner == nil || isOlder(other, owner)) {
+					owner = other
+				}
+			}
+		}
+		if owner != nil {
+			claimedNodes[node.Name] = clusterKey(owner)
+		}
+	}
+	return claimedNodes, nil
+}
+
+// reportClaimedNodes sets the NodesClaimed condition of the given cluster for
+// the nodes it selects that are claimed by other StorageClusters. An event is
+// raised for every owning cluster only when the condition changes, so that the
+// same conflict is not reported on every reconcile.
+func (c *Controller) reportClaimedNodes(
+	cluster *corev1alpha1.StorageCluster,
+	claimedNodes map[string]string,
+) {
+	existing := getClusterCondition(cluster, corev1alpha1.ClusterConditionTypeNodesClaimed)
+	if len(claimedNodes) == 0 {
+		if existing != nil && existing.Status != corev1alpha1.ConditionFalse {
+			setClusterCondition(cluster, corev1alpha1.ClusterCondition{
+				Type:   corev1alpha1.ClusterConditionTypeNodesClaimed,
+				Status: corev1alpha1.ConditionFalse,
+				Reason: reasonAsExpected,
+			})
+		}
+		return
+	}
+
+	clusterToNodes := make(map[string][]string)
+	for nodeName, owner := range claimedNodes {
+		clusterToNodes[owner] = append(clusterToNodes[owner], nodeName)
+	}
+
+	owners := make([]string, 0, len(clusterToNodes))
+	for owner := range clusterToNodes {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	messages := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		sort.Strings(clusterToNodes[owner])
+		messages = append(messages, fmt.Sprintf("Nodes %v selected by the cluster are already claimed by "+
+			"StorageCluster %s. Not running storage pods on them.", clusterToNodes[owner], owner))
+	}
+
+	message := strings.Join(messages, " ")
+	if existing != nil && existing.Status == corev1alpha1.ConditionTrue && existing.Message == message {
+		return
+	}
+	setClusterCondition(cluster, corev1alpha1.ClusterCondition{
+		Type:    corev1alpha1.ClusterConditionTypeNodesClaimed,
+		Status:  corev1alpha1.ConditionTrue,
+		Reason:  util.FailedNodeClaimReason,
+		Message: message,
+	})
+	for _, msg := range messages {
+		c.warningEvent(cluster, util.FailedNodeClaimReason, msg)
+	}
 }
 
 // syncNodes deletes given pods and creates new storage pods on the given nodes
@@ -738,8 +980,9 @@ func (c *Controller) podTemplatesForNodes(
 }
 
 // createPodTemplateForNodeGroup creates pod templates for the given list of nodes.
-// It creates a single pod template and makes a deep copy for each node so it is easier
-// during pod creation.
+// All nodes in the group share the same configuration, but a pod template is still
+// created for each node, as the storage driver may add node specific configuration
+// like the TLS certificate of the node.
 func (c *Controller) createPodTemplateForNodeGroup(
 	cluster *corev1alpha1.StorageCluster,
 	nodeGroup []*v1.Node,
@@ -748,14 +991,13 @@ func (c *Controller) createPodTemplateForNodeGroup(
 	remainingNodes map[string]*v1.Node,
 	hash string,
 ) error {
-	podTemplate, err := c.createPodTemplate(cluster, nodeGroup[0], hash)
-	if err != nil {
-		return err
-	}
-
 	for _, node := range nodeGroup {
+		podTemplate, err := c.createPodTemplate(cluster, node, hash)
+		if err != nil {
+			return err
+		}
 		*nodesNeedingStoragePods = append(*nodesNeedingStoragePods, node.Name)
-		*podTemplates = append(*podTemplates, podTemplate.DeepCopy())
+		*podTemplates = append(*podTemplates, &podTemplate)
 		delete(remainingNodes, node.Name)
 	}
 	return nil
@@ -765,7 +1007,7 @@ func (c *Controller) podsShouldBeOnNode(
 	node *v1.Node,
 	nodeToStoragePods map[string][]*v1.Pod,
 	cluster *corev1alpha1.StorageCluster,
-) (nodesNeedingStoragePods, podsToDelete []string, err error) {
+) (wantToRun bool, nodesNeedingStoragePods, podsToDelete []string, err error) {
 	wantToRun, shouldSchedule, shouldContinueRunning, err := c.nodeShouldRunStoragePod(node, cluster)
 	if err != nil {
 		return
@@ -811,7 +1053,7 @@ func (c *Controller) podsShouldBeOnNode(
 		}
 	}
 
-	return nodesNeedingStoragePods, podsToDelete, nil
+	return wantToRun, nodesNeedingStoragePods, podsToDelete, nil
 }
 
 // nodeShouldRunStoragePod simulates a storage pod on the given node which helps
@@ -825,7 +1067,7 @@ func (c *Controller) nodeShouldRunStoragePod(
 		return false, false, false, nil
 	}
 
-	newPod, err := c.newSimulationPod(cluster, node.Name)
+	newPod, err := c.newSimulationPod(cluster, node)
 	if err != nil {
 		logrus.Debugf("Failed to create a pod spec for node %v: %v", node.Name, err)
 		return false, false, false, err
@@ -928,16 +1170,22 @@ func (c *Controller) createPodTemplate(
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: cluster.Namespace,
 			Labels:    c.storageClusterSelectorLabels(cluster),
+			Annotations: map[string]string{
+				annotationNodeGroupHash: computeNodeGroupHash(&cluster.Spec),
+			},
 		},
 		Spec: podSpec,
 	}
+	if checksum := cluster.Annotations[annotationSecretChecksum]; len(checksum) > 0 {
+		newTemplate.Annotations[annotationSecretChecksum] = checksum
+	}
 
 	if len(node.Labels) > 0 {
 		encodedNodeLabels, err := json.Marshal(node.Labels)
 		if err != nil {
 			return v1.PodTemplateSpec{}, fmt.Errorf("failed to encode node labels")
 		}
-		newTemplate.Annotations = map[string]string{annotationNodeLabels: string(encodedNodeLabels)}
+		newTemplate.Annotations[annotationNodeLabels] = string(encodedNodeLabels)
 	}
 	if len(hash) > 0 {
 		newTemplate.Labels[defaultStorageClusterUniqueLabelKey] = hash
@@ -947,7 +1195,7 @@ func (c *Controller) createPodTemplate(
 
 func (c *Controller) newSimulationPod(
 	cluster *corev1alpha1.StorageCluster,
-	nodeName string,
+	node *v1.Node,
 ) (*v1.Pod, error) {
 	newPod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -955,19 +1203,22 @@ func (c *Controller) newSimulationPod(
 			Labels:    c.storageClusterSelectorLabels(cluster),
 		},
 		Spec: v1.PodSpec{
-			NodeName: nodeName,
+			NodeName: node.Name,
 		},
 	}
 
-	if cluster.Spec.Placement != nil {
-		if cluster.Spec.Placement.NodeAffinity != nil {
+	// Use the placement of the node group the node belongs to, as that may
+	// have tolerations in addition to the cluster level placement
+	clusterSpec := clusterSpecForNode(node, &cluster.Spec)
+	if clusterSpec.Placement != nil {
+		if clusterSpec.Placement.NodeAffinity != nil {
 			newPod.Spec.Affinity = &v1.Affinity{
-				NodeAffinity: cluster.Spec.Placement.NodeAffinity.DeepCopy(),
+				NodeAffinity: clusterSpec.Placement.NodeAffinity.DeepCopy(),
 			}
 		}
-		if len(cluster.Spec.Placement.Tolerations) > 0 {
+		if len(clusterSpec.Placement.Tolerations) > 0 {
 			newPod.Spec.Tolerations = make([]v1.Toleration, 0)
-			for _, t := range cluster.Spec.Placement.Tolerations {
+			for _, t := range clusterSpec.Placement.Tolerations {
 				newPod.Spec.Tolerations = append(newPod.Spec.Tolerations, *(t.DeepCopy()))
 			}
 		}
@@ -1001,6 +1252,10 @@ func (c *Controller) simulate(
 		if isControlledByStorageCluster(&pod, cluster.GetUID()) {
 			continue
 		}
+		// Terminated pods, like the finished preflight pods, do not use node resources
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
 		nodeInfo.AddPod(&pod)
 	}
 
@@ -1008,10 +1263,11 @@ func (c *Controller) simulate(
 	return reasons, nodeInfo, err
 }
 
-func (c *Controller) setStorageClusterDefaults(cluster *corev1alpha1.StorageCluster) error {
-	toUpdate := cluster.DeepCopy()
-
-	updateStrategy := &toUpdate.Spec.UpdateStrategy
+// SetDefaults sets the default values on the given StorageCluster. Driver
+// agnostic defaults are set first, followed by the defaults from the driver.
+// It is used by the controller and the defaulting webhook.
+func SetDefaults(cluster *corev1alpha1.StorageCluster, driver storage.Driver) {
+	updateStrategy := &cluster.Spec.UpdateStrategy
 	if updateStrategy.Type == "" {
 		updateStrategy.Type = corev1alpha1.RollingUpdateStorageClusterStrategyType
 	}
@@ -1026,15 +1282,21 @@ func (c *Controller) setStorageClusterDefaults(cluster *corev1alpha1.StorageClus
 		}
 	}
 
-	if toUpdate.Spec.RevisionHistoryLimit == nil {
-		toUpdate.Spec.RevisionHistoryLimit = new(int32)
-		*toUpdate.Spec.RevisionHistoryLimit = defaultRevisionHistoryLimit
+	if cluster.Spec.RevisionHistoryLimit == nil {
+		cluster.Spec.RevisionHistoryLimit = new(int32)
+		*cluster.Spec.RevisionHistoryLimit = defaultRevisionHistoryLimit
 	}
 
-	if toUpdate.Spec.ImagePullPolicy == "" {
-		toUpdate.Spec.ImagePullPolicy = v1.PullAlways
+	if cluster.Spec.ImagePullPolicy == "" {
+		cluster.Spec.ImagePullPolicy = v1.PullAlways
 	}
 
+	driver.SetDefaultsOnStorageCluster(cluster)
+}
+
+func (c *Controller) setStorageClusterDefaults(cluster *corev1alpha1.StorageCluster) error {
+	toUpdate := cluster.DeepCopy()
+
 	foundDeleteFinalizer := false
 	for _, finalizer := range toUpdate.Finalizers {
 		if finalizer == deleteFinalizerName {
@@ -1046,7 +1308,11 @@ func (c *Controller) setStorageClusterDefaults(cluster *corev1alpha1.StorageClus
 		toUpdate.Finalizers = append(toUpdate.Finalizers, deleteFinalizerName)
 	}
 
-	c.Driver.SetDefaultsOnStorageCluster(toUpdate)
+	// The defaults are already set at admission time if the operator webhooks are
+	// enabled. They are set here again for clusters that were admitted without
+	// the webhook, and for defaults like component images that may have changed
+	// since the cluster was admitted.
+	SetDefaults(toUpdate, c.Driver)
 
 	// Update the spec only if anything has changed
 	if !reflect.DeepEqual(cluster.Spec, toUpdate.Spec) || !foundDeleteFinalizer {
@@ -1060,6 +1326,168 @@ func (c *Controller) setStorageClusterDefaults(cluster *corev1alpha1.StorageClus
 	return nil
 }
 
+// syncSecretChecksum records the checksum of the kvdb auth secret and of the
+// storage pod secrets of the cluster in the annotations of the cluster. The
+// checksum is part of the revision of the cluster, so a change in the secrets
+// is rolled out to the storage pods like a change in the spec. The last checksum
+// is kept if the kvdb auth secret is missing, as the storage pods would not start
+// without the credentials anyway.
+func (c *Controller) syncSecretChecksum(cluster *corev1alpha1.StorageCluster) error {
+	var secrets []*v1.Secret
+	if cluster.Spec.Kvdb != nil && cluster.Spec.Kvdb.AuthSecret != "" {
+		secret := &v1.Secret{}
+		err := c.client.Get(
+			context.TODO(),
+			types.NamespacedName{
+				Name:      cluster.Spec.Kvdb.AuthSecret,
+				Namespace: cluster.Namespace,
+			},
+			secret,
+		)
+		if errors.IsNotFound(err) {
+			logrus.Warnf("Kvdb auth secret %v/%v not found", cluster.Namespace, cluster.Spec.Kvdb.AuthSecret)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		secrets = append(secrets, secret)
+	}
+
+	podSecrets, err := c.getStoragePodSecrets(cluster)
+	if err != nil {
+		return err
+	}
+	secrets = append(secrets, podSecrets...)
+
+	checksum := ""
+	if len(secrets) > 0 {
+		checksum = util.SecretChecksum(secrets...)
+	}
+	recordedChecksum, recorded := cluster.Annotations[annotationSecretChecksum]
+	if recordedChecksum == checksum {
+		return nil
+	}
+
+	// Storage pods running before the checksum is recorded for the first time,
+	// like the ones running before an operator upgrade, already use the current
+	// secrets. Record the checksum on them, so that they are only updated when
+	// the secrets change afterwards.
+	if !recorded && len(checksum) > 0 {
+		if err := c.recordPodSecretChecksum(cluster, checksum); err != nil {
+			return err
+		}
+	}
+
+	toUpdate := cluster.DeepCopy()
+	if len(checksum) > 0 {
+		if toUpdate.Annotations == nil {
+			toUpdate.Annotations = make(map[string]string)
+		}
+		toUpdate.Annotations[annotationSecretChecksum] = checksum
+	} else {
+		delete(toUpdate.Annotations, annotationSecretChecksum)
+	}
+	if err := c.client.Update(context.TODO(), toUpdate); err != nil {
+		return err
+	}
+	cluster.Annotations = toUpdate.Annotations
+	return nil
+}
+
+// recordPodSecretChecksum records the given secret checksum on the storage pods
+// of the cluster that do not have one yet
+func (c *Controller) recordPodSecretChecksum(
+	cluster *corev1alpha1.StorageCluster,
+	checksum string,
+) error {
+	podList := &v1.PodList{}
+	err := c.client.List(context.TODO(), podList, &client.ListOptions{Namespace: cluster.Namespace})
+	if err != nil {
+		return fmt.Errorf("failed to get list of storage pods: %v", err)
+	}
+
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !isControlledByStorageCluster(pod, cluster.UID) {
+			continue
+		} else if _, exists := pod.Annotations[annotationSecretChecksum]; exists {
+			continue
+		}
+		toUpdate := pod.DeepCopy()
+		if toUpdate.Annotations == nil {
+			toUpdate.Annotations = make(map[string]string)
+		}
+		toUpdate.Annotations[annotationSecretChecksum] = checksum
+		if err := c.client.Update(context.TODO(), toUpdate); err != nil {
+			return fmt.Errorf("failed to record secret checksum on pod %v/%v: %v",
+				pod.Namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// getStoragePodSecrets returns the secrets of the cluster that are labeled as
+// storage pod secrets, sorted by name
+func (c *Controller) getStoragePodSecrets(cluster *corev1alpha1.StorageCluster) ([]*v1.Secret, error) {
+	secretList := &v1.SecretList{}
+	err := c.client.List(
+		context.TODO(),
+		secretList,
+		&client.ListOptions{
+			Namespace:     cluster.Namespace,
+			LabelSelector: labels.SelectorFromSet(map[string]string{LabelStoragePodSecret: "true"}),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list of storage pod secrets: %v", err)
+	}
+
+	secrets := make([]*v1.Secret, 0, len(secretList.Items))
+	for i := range secretList.Items {
+		if metav1.IsControlledBy(&secretList.Items[i], cluster) {
+			secrets = append(secrets, &secretList.Items[i])
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+	return secrets, nil
+}
+
+// storageClustersForSecret returns reconcile requests for the StorageClusters
+// that use the given secret for kvdb credentials or that control the given
+// storage pod secret
+func (c *Controller) storageClustersForSecret(obj handler.MapObject) []reconcile.Request {
+	clusterList := &corev1alpha1.StorageClusterList{}
+	err := c.client.List(
+		context.TODO(),
+		clusterList,
+		&client.ListOptions{
+			Namespace: obj.Meta.GetNamespace(),
+		},
+	)
+	if err != nil {
+		logrus.Warnf("Failed to list StorageClusters using secret %v/%v: %v",
+			obj.Meta.GetNamespace(), obj.Meta.GetName(), err)
+		return nil
+	}
+
+	podSecret := obj.Meta.GetLabels()[LabelStoragePodSecret] == "true"
+	var requests []reconcile.Request
+	for _, cluster := range clusterList.Items {
+		if (cluster.Spec.Kvdb != nil && cluster.Spec.Kvdb.AuthSecret == obj.Meta.GetName()) ||
+			(podSecret && metav1.IsControlledBy(obj.Meta, &cluster)) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      cluster.Name,
+					Namespace: cluster.Namespace,
+				},
+			})
+		}
+	}
+	return requests
+}
+
 func isControlledByStorageCluster(pod *v1.Pod, uid types.UID) bool {
 	for _, ref := range pod.OwnerReferences {
 		if ref.Controller != nil && *ref.Controller && ref.UID == uid {
@@ -1105,6 +1533,9 @@ func (c *Controller) getNodeToStoragePods(
 
 	nodeToPodsMap := make(map[string][]*v1.Pod)
 	for _, pod := range claimedPods {
+		if !isControlledByStorageCluster(pod, cluster.UID) {
+			continue
+		}
 		nodeName, err := daemonutil.GetTargetNodeName(pod)
 		if err != nil {
 			logrus.Warnf("Failed to get target node name of Pod %v in StorageCluster %v",
@@ -1190,6 +1621,44 @@ func (c *Controller) createStorageNode(
 	}
 }
 
+// updateStorageNodeCondition updates the given condition in the StorageNode of
+// the given node. The phase of the StorageNode is updated too if it is not empty.
+// It returns true if the StorageNode has been updated.
+func (c *Controller) updateStorageNodeCondition(
+	cluster *corev1alpha1.StorageCluster,
+	nodeName string,
+	phase string,
+	condition *corev1alpha1.NodeCondition,
+) bool {
+	storageNode := &corev1alpha1.StorageNode{}
+	err := c.client.Get(
+		context.TODO(),
+		types.NamespacedName{Name: nodeName, Namespace: cluster.Namespace},
+		storageNode,
+	)
+	if errors.IsNotFound(err) {
+		return false
+	} else if err != nil {
+		logrus.Warnf("Failed to get StorageNode %s/%s. %v", cluster.Namespace, nodeName, err)
+		return false
+	}
+
+	changed := operatorops.Instance().UpdateStorageNodeCondition(&storageNode.Status, condition)
+	if len(phase) > 0 && storageNode.Status.Phase != phase {
+		storageNode.Status.Phase = phase
+		changed = true
+	}
+	if !changed {
+		return false
+	}
+	if err := c.client.Status().Update(context.TODO(), storageNode); err != nil {
+		logrus.Warnf("Failed to update status of StorageNode %s/%s. %v",
+			cluster.Namespace, nodeName, err)
+		return false
+	}
+	return true
+}
+
 func (c *Controller) storageClusterSelectorLabels(cluster *corev1alpha1.StorageCluster) map[string]string {
 	labels := c.Driver.GetSelectorLabels()
 	if labels == nil {
@@ -1215,6 +1684,38 @@ func storagePodsEnabled(
 	return err != nil || !disabled
 }
 
+// registerCRD creates the given CRD. If the CRD already exists, its versions,
+// schema and conversion strategy are updated to match the given CRD, so new API
+// versions get added on upgrade. Once the conversion webhook is set up, it owns
+// the served versions, conversion and schema of the CRD, so the CRD is left as is.
+func registerCRD(crd *apiextensionsv1beta1.CustomResourceDefinition) error {
+	err := apiextensionsops.Instance().RegisterCRD(crd)
+	if err == nil || !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existingCRD, err := apiextensionsops.Instance().GetCRD(crd.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if existingCRD.Spec.Conversion != nil &&
+		existingCRD.Spec.Conversion.Strategy == apiextensionsv1beta1.WebhookConverter {
+		// Updating the CRD from the spec would stop serving the versions enabled
+		// by the webhook until it is set up again. It would also drop the fields
+		// the webhook sets to preserve unknown fields, which are not part of the
+		// vendored CRD types.
+		logrus.Debugf("Not updating CRD %s as it is managed by the conversion webhook", crd.Name)
+		return nil
+	}
+	existingCRD.Spec.Version = crd.Spec.Version
+	existingCRD.Spec.Versions = crd.Spec.Versions
+	existingCRD.Spec.Validation = crd.Spec.Validation
+	existingCRD.Spec.AdditionalPrinterColumns = crd.Spec.AdditionalPrinterColumns
+	existingCRD.Spec.Conversion = crd.Spec.Conversion
+	_, err = apiextensionsops.Instance().UpdateCRD(existingCRD)
+	return err
+}
+
 func getCRDFromFile(
 	filename string,
 ) (*apiextensionsv1beta1.CustomResourceDefinition, error) {
@@ -40,6 +40,7 @@ const (
 	storkSchedDeploymentName         = "stork-scheduler"
 	storkSchedContainerName          = "stork-scheduler"
 	storkServicePort                 = 8099
+	storkComponentName               = "Stork"
 )
 
 const (
@@ -51,20 +52,31 @@ const (
 func (c *Controller) syncStork(
 	cluster *corev1alpha1.StorageCluster,
 ) error {
+	condition := corev1alpha1.ComponentCondition{
+		Name:   storkComponentName,
+		Status: corev1alpha1.ComponentReady,
+	}
+	defer func() {
+		util.SetComponentCondition(cluster, condition)
+	}()
+
 	if cluster.Spec.Stork != nil && cluster.Spec.Stork.Enabled {
 		_, err := c.Driver.GetStorkDriverName()
 		if err == nil {
 			if err := c.setupStork(cluster); err != nil {
-				msg := fmt.Sprintf("Failed to setup Stork. %v", err)
-				c.warningEvent(cluster, util.FailedComponentReason, msg)
+				condition.Status = corev1alpha1.ComponentFailed
+				condition.Reason = fmt.Sprintf("Failed to setup Stork. %v", err)
+				logrus.Warn(condition.Reason)
 			}
 			return nil
 		}
 		logrus.Warnf("Cannot install Stork for %s driver: %v", c.Driver.String(), err)
 	}
+	condition.Status = corev1alpha1.ComponentDisabled
 	if err := c.removeStork(cluster); err != nil {
-		msg := fmt.Sprintf("Failed to cleanup Stork. %v", err)
-		c.warningEvent(cluster, util.FailedComponentReason, msg)
+		condition.Status = corev1alpha1.ComponentFailed
+		condition.Reason = fmt.Sprintf("Failed to cleanup Stork. %v", err)
+		logrus.Warn(condition.Reason)
 	}
 	return nil
 }
@@ -77,12 +89,15 @@ func (c *Controller) setupStork(cluster *corev1alpha1.StorageCluster) error {
 	if err := c.createStorkServiceAccount(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
-	if err := c.createStorkClusterRole(ownerRef); err != nil {
+	if err := c.createStorkClusterRole(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
 	if err := c.createStorkClusterRoleBinding(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
+	if err := util.DeleteLegacyClusterRBAC(c.client, storkClusterRoleName, storkClusterRoleBindingName, *ownerRef); err != nil {
+		return err
+	}
 	if err := c.createStorkService(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
@@ -100,12 +115,15 @@ func (c *Controller) setupStorkScheduler(cluster *corev1alpha1.StorageCluster) e
 	if err := c.createStorkSchedServiceAccount(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
-	if err := c.createStorkSchedClusterRole(ownerRef); err != nil {
+	if err := c.createStorkSchedClusterRole(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
 	if err := c.createStorkSchedClusterRoleBinding(cluster.Namespace, ownerRef); err != nil {
 		return err
 	}
+	if err := util.DeleteLegacyClusterRBAC(c.client, storkSchedClusterRoleName, storkSchedClusterRoleBindingName, *ownerRef); err != nil {
+		return err
+	}
 	if err := c.createStorkSchedDeployment(cluster, ownerRef); err != nil {
 		return err
 	}
@@ -121,10 +139,13 @@ func (c *Controller) removeStork(cluster *corev1alpha1.StorageCluster) error {
 	if err := k8sutil.DeleteServiceAccount(c.client, storkServiceAccountName, namespace, *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRole(c.client, storkClusterRoleName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRole(c.client, util.ClusterScopedName(storkClusterRoleName, namespace), *ownerRef); err != nil {
+		return err
+	}
+	if err := k8sutil.DeleteClusterRoleBinding(c.client, util.ClusterScopedName(storkClusterRoleBindingName, namespace), *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRoleBinding(c.client, storkClusterRoleBindingName, *ownerRef); err != nil {
+	if err := util.DeleteLegacyClusterRBAC(c.client, storkClusterRoleName, storkClusterRoleBindingName, *ownerRef); err != nil {
 		return err
 	}
 	if err := k8sutil.DeleteService(c.client, storkServiceName, namespace, *ownerRef); err != nil {
@@ -147,10 +168,13 @@ func (c *Controller) removeStorkScheduler(
 	if err := k8sutil.DeleteServiceAccount(c.client, storkSchedServiceAccountName, namespace, *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRole(c.client, storkSchedClusterRoleName, *ownerRef); err != nil {
+	if err := k8sutil.DeleteClusterRole(c.client, util.ClusterScopedName(storkSchedClusterRoleName, namespace), *ownerRef); err != nil {
+		return err
+	}
+	if err := k8sutil.DeleteClusterRoleBinding(c.client, util.ClusterScopedName(storkSchedClusterRoleBindingName, namespace), *ownerRef); err != nil {
 		return err
 	}
-	if err := k8sutil.DeleteClusterRoleBinding(c.client, storkSchedClusterRoleBindingName, *ownerRef); err != nil {
+	if err := util.DeleteLegacyClusterRBAC(c.client, storkSchedClusterRoleName, storkSchedClusterRoleBindingName, *ownerRef); err != nil {
 		return err
 	}
 	if err := k8sutil.DeleteDeployment(c.client, storkSchedDeploymentName, namespace, *ownerRef); err != nil {
@@ -254,12 +278,15 @@ func (c *Controller) createStorkSchedServiceAccount(
 	)
 }
 
-func (c *Controller) createStorkClusterRole(ownerRef *metav1.OwnerReference) error {
+func (c *Controller) createStorkClusterRole(
+	clusterNamespace string,
+	ownerRef *metav1.OwnerReference,
+) error {
 	return k8sutil.CreateOrUpdateClusterRole(
 		c.client,
 		&rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            storkClusterRoleName,
+				Name:            util.ClusterScopedName(storkClusterRoleName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Rules: []rbacv1.PolicyRule{
@@ -274,12 +301,15 @@ func (c *Controller) createStorkClusterRole(ownerRef *metav1.OwnerReference) err
 	)
 }
 
-func (c *Controller) createStorkSchedClusterRole(ownerRef *metav1.OwnerReference) error {
+func (c *Controller) createStorkSchedClusterRole(
+	clusterNamespace string,
+	ownerRef *metav1.OwnerReference,
+) error {
 	return k8sutil.CreateOrUpdateClusterRole(
 		c.client,
 		&rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            storkSchedClusterRoleName,
+				Name:            util.ClusterScopedName(storkSchedClusterRoleName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Rules: []rbacv1.PolicyRule{
@@ -373,7 +403,7 @@ func (c *Controller) createStorkClusterRoleBinding(
 		c.client,
 		&rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            storkClusterRoleBindingName,
+				Name:            util.ClusterScopedName(storkClusterRoleBindingName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Subjects: []rbacv1.Subject{
@@ -385,7 +415,7 @@ func (c *Controller) createStorkClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     storkClusterRoleName,
+				Name:     util.ClusterScopedName(storkClusterRoleName, clusterNamespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
@@ -401,7 +431,7 @@ func (c *Controller) createStorkSchedClusterRoleBinding(
 		c.client,
 		&rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:            storkSchedClusterRoleBindingName,
+				Name:            util.ClusterScopedName(storkSchedClusterRoleBindingName, clusterNamespace),
 				OwnerReferences: []metav1.OwnerReference{*ownerRef},
 			},
 			Subjects: []rbacv1.Subject{
@@ -413,7 +443,7 @@ func (c *Controller) createStorkSchedClusterRoleBinding(
 			},
 			RoleRef: rbacv1.RoleRef{
 				Kind:     "ClusterRole",
-				Name:     storkSchedClusterRoleName,
+				Name:     util.ClusterScopedName(storkSchedClusterRoleName, clusterNamespace),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
@@ -518,30 +548,28 @@ func (c *Controller) createStorkDeployment(
 	var existingImage string
 	var existingCommand []string
 	var existingEnvs []v1.EnvVar
-	var existingCPUQuantity resource.Quantity
 	for _, c := range existingDeployment.Spec.Template.Spec.Containers {
 		if c.Name == storkContainerName {
 			existingImage = c.Image
 			existingCommand = c.Command
 			existingEnvs = append([]v1.EnvVar{}, c.Env...)
 			sort.Sort(envByName(existingEnvs))
-			existingCPUQuantity = c.Resources.Requests[v1.ResourceCPU]
 			break
 		}
 	}
 
-	// Check if image, envs, cpu or args are modified
+	deployment := c.getStorkDeploymentSpec(cluster, ownerRef, imageName,
+		command, envVars, targetCPUQuantity)
+
+	// Check if image, envs, args, resources or scheduling config are modified
 	modified := existingImage != imageName ||
 		!reflect.DeepEqual(existingCommand, command) ||
 		!reflect.DeepEqual(existingEnvs, envVars) ||
-		existingCPUQuantity.Cmp(targetCPUQuantity) != 0 ||
 		util.HasPullSecretChanged(cluster, existingDeployment.Spec.Template.Spec.ImagePullSecrets) ||
 		util.HasNodeAffinityChanged(cluster, existingDeployment.Spec.Template.Spec.Affinity) ||
-		util.HaveTolerationsChanged(cluster, existingDeployment.Spec.Template.Spec.Tolerations)
+		util.HasComponentConfigChanged(&existingDeployment.Spec.Template.Spec, &deployment.Spec.Template.Spec)
 
 	if !c.isStorkDeploymentCreated || modified {
-		deployment := c.getStorkDeploymentSpec(cluster, ownerRef, imageName,
-			command, envVars, targetCPUQuantity)
 		if err = k8sutil.CreateOrUpdateDeployment(c.client, deployment, ownerRef); err != nil {
 			return err
 		}
@@ -667,6 +695,8 @@ func (c *Controller) getStorkDeploymentSpec(
 		}
 	}
 
+	util.ApplyComponentConfig(&deployment.Spec.Template.Spec,
+		&cluster.Spec.Stork.ComponentConfig, storkContainerName)
 	return deployment
 }
 
@@ -711,24 +741,22 @@ func (c *Controller) createStorkSchedDeployment(
 
 	var existingImage string
 	var existingCommand []string
-	var existingCPUQuantity resource.Quantity
 	for _, c := range existingDeployment.Spec.Template.Spec.Containers {
 		if c.Name == storkSchedContainerName {
 			existingImage = c.Image
 			existingCommand = c.Command
-			existingCPUQuantity = c.Resources.Requests[v1.ResourceCPU]
 		}
 	}
 
+	deployment := getStorkSchedDeploymentSpec(cluster, ownerRef, imageName, command, targetCPUQuantity)
+
 	modified := existingImage != imageName ||
 		!reflect.DeepEqual(existingCommand, command) ||
-		existingCPUQuantity.Cmp(targetCPUQuantity) != 0 ||
 		util.HasPullSecretChanged(cluster, existingDeployment.Spec.Template.Spec.ImagePullSecrets) ||
 		util.HasNodeAffinityChanged(cluster, existingDeployment.Spec.Template.Spec.Affinity) ||
-		util.HaveTolerationsChanged(cluster, existingDeployment.Spec.Template.Spec.Tolerations)
+		util.HasComponentConfigChanged(&existingDeployment.Spec.Template.Spec, &deployment.Spec.Template.Spec)
 
 	if !c.isStorkSchedDeploymentCreated || modified {
-		deployment := getStorkSchedDeploymentSpec(cluster, ownerRef, imageName, command, targetCPUQuantity)
 		if err = k8sutil.CreateOrUpdateDeployment(c.client, deployment, ownerRef); err != nil {
 			return err
 		}
@@ -858,6 +886,8 @@ func getStorkSchedDeploymentSpec(
 		}
 	}
 
+	util.ApplyComponentConfig(&deployment.Spec.Template.Spec,
+		&cluster.Spec.Stork.ComponentConfig, storkSchedContainerName)
 	return deployment
 }
 
@@ -137,7 +137,7 @@ func TestStorkInstallation(t *testing.T) {
 	// Stork ClusterRole
 	expectedStorkCR := testutil.GetExpectedClusterRole(t, "storkClusterRole.yaml")
 	storkCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, storkCR, storkClusterRoleName, "")
+	err = testutil.Get(k8sClient, storkCR, util.ClusterScopedName(storkClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedStorkCR.Name, storkCR.Name)
 	require.Len(t, storkCR.OwnerReferences, 1)
@@ -147,7 +147,7 @@ func TestStorkInstallation(t *testing.T) {
 	// Stork Scheduler ClusterRole
 	expectedSchedCR := testutil.GetExpectedClusterRole(t, "storkSchedClusterRole.yaml")
 	schedCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, schedCR, storkSchedClusterRoleName, "")
+	err = testutil.Get(k8sClient, schedCR, util.ClusterScopedName(storkSchedClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedSchedCR.Name, schedCR.Name)
 	require.Len(t, schedCR.OwnerReferences, 1)
@@ -163,7 +163,7 @@ func TestStorkInstallation(t *testing.T) {
 	// Stork ClusterRoleBinding
 	expectedStorkCRB := testutil.GetExpectedClusterRoleBinding(t, "storkClusterRoleBinding.yaml")
 	storkCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, storkCRB, storkClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, storkCRB, util.ClusterScopedName(storkClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedStorkCRB.Name, storkCRB.Name)
 	require.Len(t, storkCRB.OwnerReferences, 1)
@@ -174,7 +174,7 @@ func TestStorkInstallation(t *testing.T) {
 	// Stork Scheduler ClusterRoleBinding
 	expectedSchedCRB := testutil.GetExpectedClusterRoleBinding(t, "storkSchedClusterRoleBinding.yaml")
 	schedCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, schedCRB, storkSchedClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, schedCRB, util.ClusterScopedName(storkSchedClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 	require.Equal(t, expectedSchedCRB.Name, schedCRB.Name)
 	require.Len(t, schedCRB.OwnerReferences, 1)
@@ -276,20 +276,20 @@ func TestStorkWithoutImage(t *testing.T) {
 
 	err := controller.syncStork(cluster)
 	require.NoError(t, err)
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v Failed to setup Stork. stork image cannot be empty",
-			v1.EventTypeWarning, util.FailedComponentReason),
-	)
+	require.Empty(t, recorder.Events)
+	condition := util.GetComponentCondition(cluster, storkComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup Stork. stork image cannot be empty")
 
 	cluster.Spec.Stork.Image = ""
 	err = controller.syncStork(cluster)
 	require.NoError(t, err)
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v Failed to setup Stork. stork image cannot be empty",
-			v1.EventTypeWarning, util.FailedComponentReason),
-	)
+	require.Empty(t, recorder.Events)
+	condition = util.GetComponentCondition(cluster, storkComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup Stork. stork image cannot be empty")
 }
 
 func TestStorkImageChange(t *testing.T) {
@@ -1275,6 +1275,119 @@ func TestStorkSchedulerCPUChange(t *testing.T) {
 	require.Zero(t, expectedCPUQuantity.Cmp(deployment.Spec.Template.Spec.Containers[0].Resources.Requests[v1.ResourceCPU]))
 }
 
+func TestStorkComponentConfigChange(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+			Annotations: map[string]string{
+				annotationStorkCPU: "0.2",
+			},
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Placement: &corev1alpha1.PlacementSpec{
+				Tolerations: []v1.Toleration{
+					{
+						Key:      "cluster",
+						Operator: v1.TolerationOpExists,
+					},
+				},
+			},
+			Stork: &corev1alpha1.StorkSpec{
+				Enabled: true,
+				Image:   "osd/stork:test",
+			},
+		},
+	}
+
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().GetStorkDriverName().Return("pxd", nil).AnyTimes()
+	driver.EXPECT().GetStorkEnvList(cluster).
+		Return([]v1.EnvVar{{Name: "PX_NAMESPACE", Value: cluster.Namespace}}).
+		AnyTimes()
+
+	err := controller.syncStork(cluster)
+	require.NoError(t, err)
+
+	// Add resources and scheduling configuration for stork. The resources
+	// should override the CPU from the annotation.
+	expectedResources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("500m"),
+			v1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+	}
+	runAsNonRoot := true
+	cluster.Spec.Stork.ComponentConfig = corev1alpha1.ComponentConfig{
+		Resources:         expectedResources.DeepCopy(),
+		PriorityClassName: "stork-priority",
+		NodeSelector: map[string]string{
+			"stork": "true",
+		},
+		Tolerations: []v1.Toleration{
+			{
+				Key:      "stork",
+				Operator: v1.TolerationOpEqual,
+				Value:    "true",
+				Effect:   v1.TaintEffectNoSchedule,
+			},
+		},
+		SecurityContext: &v1.PodSecurityContext{
+			RunAsNonRoot: &runAsNonRoot,
+		},
+	}
+
+	err = controller.syncStork(cluster)
+	require.NoError(t, err)
+
+	for _, name := range []string{storkDeploymentName, storkSchedDeploymentName} {
+		deployment := &appsv1.Deployment{}
+		err = testutil.Get(k8sClient, deployment, name, cluster.Namespace)
+		require.NoError(t, err)
+		podSpec := deployment.Spec.Template.Spec
+		require.Equal(t, expectedResources, podSpec.Containers[0].Resources)
+		require.Equal(t, "stork-priority", podSpec.PriorityClassName)
+		require.Equal(t, cluster.Spec.Stork.NodeSelector, podSpec.NodeSelector)
+		require.Equal(t, cluster.Spec.Stork.Tolerations, podSpec.Tolerations)
+		require.Equal(t, cluster.Spec.Stork.SecurityContext, podSpec.SecurityContext)
+	}
+
+	// Remove the configuration. Stork should go back to the CPU from the
+	// annotation and the cluster level tolerations.
+	cluster.Spec.Stork.ComponentConfig = corev1alpha1.ComponentConfig{}
+
+	err = controller.syncStork(cluster)
+	require.NoError(t, err)
+
+	deployment := &appsv1.Deployment{}
+	err = testutil.Get(k8sClient, deployment, storkDeploymentName, cluster.Namespace)
+	require.NoError(t, err)
+	podSpec := deployment.Spec.Template.Spec
+	expectedCPUQuantity := resource.MustParse("0.2")
+	require.Len(t, podSpec.Containers[0].Resources.Requests, 1)
+	require.Zero(t, expectedCPUQuantity.Cmp(podSpec.Containers[0].Resources.Requests[v1.ResourceCPU]))
+	require.Empty(t, podSpec.Containers[0].Resources.Limits)
+	require.Empty(t, podSpec.PriorityClassName)
+	require.Empty(t, podSpec.NodeSelector)
+	require.Equal(t, cluster.Spec.Placement.Tolerations, podSpec.Tolerations)
+	require.Nil(t, podSpec.SecurityContext)
+}
+
 func TestStorkInvalidCPU(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -1311,12 +1424,14 @@ func TestStorkInvalidCPU(t *testing.T) {
 
 	driver.EXPECT().GetStorkDriverName().Return("pxd", nil).AnyTimes()
 
-	// Should not return error, instead raise an event
+	// Should not return error, instead the failure is reported in the status
 	err := controller.syncStork(cluster)
 	require.NoError(t, err)
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v Failed to setup Stork.", v1.EventTypeWarning, util.FailedComponentReason))
+	require.Empty(t, recorder.Events)
+	condition := util.GetComponentCondition(cluster, storkComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup Stork.")
 }
 
 func TestStorkSchedulerInvalidCPU(t *testing.T) {
@@ -1358,12 +1473,14 @@ func TestStorkSchedulerInvalidCPU(t *testing.T) {
 		Return([]v1.EnvVar{{Name: "PX_NAMESPACE", Value: cluster.Namespace}}).
 		AnyTimes()
 
-	// Should not return error, instead raise an event
+	// Should not return error, instead the failure is reported in the status
 	err := controller.syncStork(cluster)
 	require.NoError(t, err)
-	require.Len(t, recorder.Events, 1)
-	require.Contains(t, <-recorder.Events,
-		fmt.Sprintf("%v %v Failed to setup Stork.", v1.EventTypeWarning, util.FailedComponentReason))
+	require.Empty(t, recorder.Events)
+	condition := util.GetComponentCondition(cluster, storkComponentName)
+	require.NotNil(t, condition)
+	require.Equal(t, corev1alpha1.ComponentFailed, condition.Status)
+	require.Contains(t, condition.Reason, "Failed to setup Stork.")
 }
 
 func TestStorkSchedulerRollbackImageChange(t *testing.T) {
@@ -1589,19 +1706,19 @@ func TestDisableStork(t *testing.T) {
 	require.NoError(t, err)
 
 	storkCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, storkCR, storkClusterRoleName, "")
+	err = testutil.Get(k8sClient, storkCR, util.ClusterScopedName(storkClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	schedCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, schedCR, storkSchedClusterRoleName, "")
+	err = testutil.Get(k8sClient, schedCR, util.ClusterScopedName(storkSchedClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	storkCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, storkCRB, storkClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, storkCRB, util.ClusterScopedName(storkClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	schedCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, schedCRB, storkSchedClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, schedCRB, util.ClusterScopedName(storkSchedClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	storkService := &v1.Service{}
@@ -1638,19 +1755,19 @@ func TestDisableStork(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	storkCR = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, storkCR, storkClusterRoleName, "")
+	err = testutil.Get(k8sClient, storkCR, util.ClusterScopedName(storkClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	schedCR = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, schedCR, storkSchedClusterRoleName, "")
+	err = testutil.Get(k8sClient, schedCR, util.ClusterScopedName(storkSchedClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	storkCRB = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, storkCRB, storkClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, storkCRB, util.ClusterScopedName(storkClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	schedCRB = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, schedCRB, storkSchedClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, schedCRB, util.ClusterScopedName(storkSchedClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	storkService = &v1.Service{}
@@ -1719,19 +1836,19 @@ func TestRemoveStork(t *testing.T) {
 	require.NoError(t, err)
 
 	storkCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, storkCR, storkClusterRoleName, "")
+	err = testutil.Get(k8sClient, storkCR, util.ClusterScopedName(storkClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	schedCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, schedCR, storkSchedClusterRoleName, "")
+	err = testutil.Get(k8sClient, schedCR, util.ClusterScopedName(storkSchedClusterRoleName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	storkCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, storkCRB, storkClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, storkCRB, util.ClusterScopedName(storkClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	schedCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, schedCRB, storkSchedClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, schedCRB, util.ClusterScopedName(storkSchedClusterRoleBindingName, cluster.Namespace), "")
 	require.NoError(t, err)
 
 	storkService := &v1.Service{}
@@ -1768,19 +1885,19 @@ func TestRemoveStork(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	storkCR = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, storkCR, storkClusterRoleName, "")
+	err = testutil.Get(k8sClient, storkCR, util.ClusterScopedName(storkClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	schedCR = &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, schedCR, storkSchedClusterRoleName, "")
+	err = testutil.Get(k8sClient, schedCR, util.ClusterScopedName(storkSchedClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	storkCRB = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, storkCRB, storkClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, storkCRB, util.ClusterScopedName(storkClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	schedCRB = &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, schedCRB, storkSchedClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, schedCRB, util.ClusterScopedName(storkSchedClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	storkService = &v1.Service{}
@@ -1800,6 +1917,98 @@ func TestRemoveStork(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 }
 
+func TestStorkRemovesLegacyClusterRoles(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-test",
+			UID:       "px-cluster-uid",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Stork: &corev1alpha1.StorkSpec{
+				Enabled: true,
+				Image:   "osd/stork:test",
+			},
+		},
+	}
+
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	k8sVersion, _ := version.NewVersion(minSupportedK8sVersion)
+	driver := testutil.MockDriver(mockCtrl)
+	k8sClient := testutil.FakeK8sClient(cluster)
+	controller := Controller{
+		client:            k8sClient,
+		Driver:            driver,
+		kubernetesVersion: k8sVersion,
+	}
+
+	driver.EXPECT().GetStorkDriverName().Return("pxd", nil).AnyTimes()
+	driver.EXPECT().GetStorkEnvList(cluster).
+		Return([]v1.EnvVar{{Name: "PX_NAMESPACE", Value: cluster.Namespace}}).
+		AnyTimes()
+
+	// ClusterRoles and ClusterRoleBindings created by an older operator, before
+	// their names were suffixed with the namespace of the cluster
+	ownerRef := metav1.NewControllerRef(cluster, controllerKind)
+	createLegacyObjects := func() {
+		for _, name := range []string{storkClusterRoleName, storkSchedClusterRoleName} {
+			err := k8sClient.Create(context.TODO(), &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            name,
+					OwnerReferences: []metav1.OwnerReference{*ownerRef},
+				},
+			})
+			require.NoError(t, err)
+		}
+		for _, name := range []string{storkClusterRoleBindingName, storkSchedClusterRoleBindingName} {
+			err := k8sClient.Create(context.TODO(), &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            name,
+					OwnerReferences: []metav1.OwnerReference{*ownerRef},
+				},
+			})
+			require.NoError(t, err)
+		}
+	}
+	requireLegacyObjectsRemoved := func() {
+		for _, name := range []string{storkClusterRoleName, storkSchedClusterRoleName} {
+			err := testutil.Get(k8sClient, &rbacv1.ClusterRole{}, name, "")
+			require.True(t, errors.IsNotFound(err))
+		}
+		for _, name := range []string{storkClusterRoleBindingName, storkSchedClusterRoleBindingName} {
+			err := testutil.Get(k8sClient, &rbacv1.ClusterRoleBinding{}, name, "")
+			require.True(t, errors.IsNotFound(err))
+		}
+	}
+
+	createLegacyObjects()
+
+	err := controller.syncStork(cluster)
+	require.NoError(t, err)
+
+	requireLegacyObjectsRemoved()
+
+	storkCR := &rbacv1.ClusterRole{}
+	err = testutil.Get(k8sClient, storkCR, util.ClusterScopedName(storkClusterRoleName, cluster.Namespace), "")
+	require.NoError(t, err)
+
+	storkCRB := &rbacv1.ClusterRoleBinding{}
+	err = testutil.Get(k8sClient, storkCRB, util.ClusterScopedName(storkClusterRoleBindingName, cluster.Namespace), "")
+	require.NoError(t, err)
+
+	// TestCase: Legacy objects should also be removed when stork is disabled
+	createLegacyObjects()
+
+	cluster.Spec.Stork.Enabled = false
+	err = controller.syncStork(cluster)
+	require.NoError(t, err)
+
+	requireLegacyObjectsRemoved()
+}
+
 func TestStorkDriverNotImplemented(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -1846,19 +2055,19 @@ func TestStorkDriverNotImplemented(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 
 	storkCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, storkCR, storkClusterRoleName, "")
+	err = testutil.Get(k8sClient, storkCR, util.ClusterScopedName(storkClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	schedCR := &rbacv1.ClusterRole{}
-	err = testutil.Get(k8sClient, schedCR, storkSchedClusterRoleName, "")
+	err = testutil.Get(k8sClient, schedCR, util.ClusterScopedName(storkSchedClusterRoleName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	storkCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, storkCRB, storkClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, storkCRB, util.ClusterScopedName(storkClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	schedCRB := &rbacv1.ClusterRoleBinding{}
-	err = testutil.Get(k8sClient, schedCRB, storkSchedClusterRoleBindingName, "")
+	err = testutil.Get(k8sClient, schedCRB, util.ClusterScopedName(storkSchedClusterRoleBindingName, cluster.Namespace), "")
 	require.True(t, errors.IsNotFound(err))
 
 	storkService := &v1.Service{}
@@ -24,8 +24,12 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 
 	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/libopenstorage/operator/pkg/cloudprovider"
+	"github.com/libopenstorage/operator/pkg/util"
+	k8sutil "github.com/libopenstorage/operator/pkg/util/k8s"
 	operatorops "github.com/portworx/sched-ops/k8s/operator"
 	"github.com/sirupsen/logrus"
 	apps "k8s.io/api/apps/v1"
@@ -42,7 +46,11 @@ import (
 )
 
 // rollingUpdate deletes old storage cluster pods making sure that no more than
-// cluster.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable pods are unavailable
+// cluster.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable pods are unavailable.
+// Only the pods in the update partition are updated. If the rollout is paused,
+// or the canary pods have been updated and the revision is not promoted yet, no
+// more pods are deleted. If nodes are drained during the update, the pods using
+// storage are evicted from a node before its storage pod is deleted.
 func (c *Controller) rollingUpdate(cluster *corev1alpha1.StorageCluster, hash string) error {
 	nodeToStoragePods, err := c.getNodeToStoragePods(cluster)
 	if err != nil {
@@ -50,13 +58,43 @@ func (c *Controller) rollingUpdate(cluster *corev1alpha1.StorageCluster, hash st
 			cluster.Name, err)
 	}
 
-	_, oldPods := c.getAllStorageClusterPods(cluster, nodeToStoragePods, hash)
-	maxUnavailable, numUnavailable, err := c.getUnavailableNumbers(cluster, nodeToStoragePods)
+	newPods, oldPods := c.getAllStorageClusterPods(cluster, nodeToStoragePods, hash)
+
+	// Uncordon the drained nodes whose storage pods are updated, even if the
+	// rollout is paused, so applications can be scheduled on them again
+	drainingNodes, err := c.getNodesBeingDrained()
+	if err != nil {
+		return err
+	}
+	if err := c.uncordonUpdatedNodes(cluster, drainingNodes, newPods); err != nil {
+		return err
+	}
+
+	if cluster.Spec.UpdateStrategy.RollingUpdate.Paused {
+		logrus.Debugf("Rollout of storage cluster %v/%v is paused", cluster.Namespace, cluster.Name)
+		return nil
+	}
+
+	oldPods, err = c.podsInUpdatePartition(cluster, nodeToStoragePods, oldPods)
+	if err != nil {
+		return fmt.Errorf("couldn't get pods in the update partition: %v", err)
+	}
+	maxUnavailable, unavailableNodes, numMissing, err := c.getUnavailableNumbers(cluster, nodeToStoragePods)
 	if err != nil {
 		return fmt.Errorf("couldn't get unavailable numbers: %v", err)
 	}
+	numUnavailable := len(unavailableNodes)
 	oldAvailablePods, oldUnavailablePods := splitByAvailablePods(oldPods)
 
+	// In canary mode only update as many pods as allowed by the canary budget,
+	// counting pods already running the new revision and pods being replaced
+	canaryBudget, canaryWaiting := canaryUpdateBudget(cluster, hash, newPods, oldPods, numMissing)
+	if canaryWaiting {
+		logrus.Debugf("Canary pods of storage cluster %v/%v are updated to revision %s. "+
+			"Waiting for the revision to be promoted", cluster.Namespace, cluster.Name, hash)
+		return nil
+	}
+
 	// for oldPods delete all not running pods
 	var oldPodsToDelete []string
 	logrus.Debugf("Marking all unavailable old pods for deletion")
@@ -65,8 +103,34 @@ func (c *Controller) rollingUpdate(cluster *corev1alpha1.StorageCluster, hash st
 		if pod.DeletionTimestamp != nil {
 			continue
 		}
+		if canaryBudget == 0 {
+			break
+		}
 		logrus.Debugf("Marking pod %s/%s for deletion", cluster.Name, pod.Name)
 		oldPodsToDelete = append(oldPodsToDelete, pod.Name)
+		canaryBudget--
+	}
+
+	// In topology aware mode only update the pods in the failure domain that is
+	// currently being updated, so that only one domain is down at a time
+	var blockedReason string
+	if cluster.Spec.UpdateStrategy.RollingUpdate.FailureDomain != "" {
+		oldAvailablePods, blockedReason, err = c.podsInUpdateDomain(
+			cluster, newPods, oldAvailablePods, unavailableNodes)
+		if err != nil {
+			return fmt.Errorf("couldn't get pods in the failure domain being updated: %v", err)
+		}
+	}
+
+	// Finish draining the nodes that are already being drained before
+	// moving on to other nodes
+	drainNodes := cluster.Spec.UpdateStrategy.RollingUpdate.DrainNodes
+	if drainNodes {
+		sort.SliceStable(oldAvailablePods, func(i, j int) bool {
+			_, iDraining := drainingNodes[oldAvailablePods[i].Spec.NodeName]
+			_, jDraining := drainingNodes[oldAvailablePods[j].Spec.NodeName]
+			return iDraining && !jDraining
+		})
 	}
 
 	logrus.Debugf("Marking old pods for deletion")
@@ -76,13 +140,490 @@ func (c *Controller) rollingUpdate(cluster *corev1alpha1.StorageCluster, hash st
 				"to or exceeds allowed maximum: %d", numUnavailable, maxUnavailable)
 			break
 		}
+		if canaryBudget == 0 {
+			logrus.Debugf("Number of canary pods reached the allowed maximum: %d",
+				cluster.Spec.UpdateStrategy.RollingUpdate.Canary.Nodes)
+			break
+		}
+		// Let the driver decide if the storage cluster is healthy enough
+		// to take down the storage pod on the next node. Nodes that are
+		// already being drained have been checked before.
+		if _, draining := drainingNodes[pod.Spec.NodeName]; !draining {
+			if err := c.Driver.PreNodeUpgrade(cluster, pod.Spec.NodeName); err != nil {
+				blockedReason = fmt.Sprintf("Upgrade of node %s is blocked: %v", pod.Spec.NodeName, err)
+				logrus.Infof("%s. Will retry in the next reconcile.", blockedReason)
+				break
+			}
+		}
+		if drainNodes {
+			drained, err := c.drainNode(cluster, pod.Spec.NodeName)
+			if err != nil {
+				blockedReason = fmt.Sprintf("Upgrade of node %s is blocked: %v", pod.Spec.NodeName, err)
+				logrus.Infof("%s. Will retry in the next reconcile.", blockedReason)
+				break
+			} else if !drained {
+				// The node is unavailable to applications while it is being
+				// drained, so count it against the unavailable budget
+				numUnavailable++
+				canaryBudget--
+				continue
+			}
+			c.updateNodeUpgradeCondition(cluster, pod.Spec.NodeName,
+				corev1alpha1.NodeRestartingStatus, "Restarting the storage pod")
+		}
 		logrus.Debugf("Marking pod %s/%s for deletion", cluster.Name, pod.Name)
 		oldPodsToDelete = append(oldPodsToDelete, pod.Name)
 		numUnavailable++
+		canaryBudget--
+	}
+
+	if len(blockedReason) > 0 {
+		setClusterCondition(cluster, corev1alpha1.ClusterCondition{
+			Type:    corev1alpha1.ClusterConditionTypeUpgrading,
+			Status:  corev1alpha1.ConditionTrue,
+			Reason:  reasonUpdateBlocked,
+			Message: blockedReason,
+		})
+	} else if len(oldPodsToDelete) > 0 {
+		setClusterCondition(cluster, corev1alpha1.ClusterCondition{
+			Type:   corev1alpha1.ClusterConditionTypeUpgrading,
+			Status: corev1alpha1.ConditionTrue,
+			Reason: reasonUpdateInProgress,
+			Message: fmt.Sprintf("Updated %d of %d storage pods to revision %s",
+				len(newPods), len(newPods)+len(oldPods), hash),
+		})
 	}
 	return c.syncNodes(cluster, oldPodsToDelete, []string{}, hash)
 }
 
+// podsInUpdatePartition returns the given pods that are running on nodes selected
+// by the partition of the rolling update.
+func (c *Controller) podsInUpdatePartition(
+	cluster *corev1alpha1.StorageCluster,
+	nodeToStoragePods map[string][]*v1.Pod,
+	pods []*v1.Pod,
+) ([]*v1.Pod, error) {
+	partition := cluster.Spec.UpdateStrategy.RollingUpdate.Partition
+	if partition == nil {
+		return pods, nil
+	}
+
+	partitionNodes := make(map[string]bool)
+	nodeNames := make([]string, 0, len(nodeToStoragePods))
+	for nodeName := range nodeToStoragePods {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+	for i, nodeName := range nodeNames {
+		if partition.Ordinal == nil || i >= int(*partition.Ordinal) {
+			partitionNodes[nodeName] = true
+		}
+	}
+
+	if partition.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(partition.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid partition selector: %v", err)
+		}
+		for nodeName := range partitionNodes {
+			node := &v1.Node{}
+			err := c.client.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node)
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			if !selector.Matches(labels.Set(node.Labels)) {
+				delete(partitionNodes, nodeName)
+			}
+		}
+	}
+
+	podsInPartition := make([]*v1.Pod, 0)
+	for _, pod := range pods {
+		if partitionNodes[pod.Spec.NodeName] {
+			podsInPartition = append(podsInPartition, pod)
+		}
+	}
+	return podsInPartition, nil
+}
+
+// podsInUpdateDomain returns the given old pods that are running in the failure
+// domain currently being updated. The domain with unavailable storage pods is the
+// one being updated. If there is none, a partially updated domain is preferred,
+// so that a domain is finished before moving on to the next one. If storage pods
+// are unavailable in multiple domains, or the domain being updated is waiting for
+// its storage pods to become available, no pods are returned along with the reason
+// the update is blocked.
+func (c *Controller) podsInUpdateDomain(
+	cluster *corev1alpha1.StorageCluster,
+	newPods, oldPods []*v1.Pod,
+	unavailableNodes []string,
+) ([]*v1.Pod, string, error) {
+	domainType := strings.ToLower(string(cluster.Spec.UpdateStrategy.RollingUpdate.FailureDomain))
+	nodeToDomain, err := c.getNodeFailureDomains(cluster)
+	if err != nil {
+		return nil, "", err
+	}
+
+	unavailableDomains := make(map[string]bool)
+	for _, nodeName := range unavailableNodes {
+		unavailableDomains[nodeToDomain[nodeName]] = true
+	}
+	if len(unavailableDomains) > 1 {
+		domains := make([]string, 0, len(unavailableDomains))
+		for domain := range unavailableDomains {
+			domains = append(domains, domain)
+		}
+		sort.Strings(domains)
+		return nil, fmt.Sprintf("Upgrade is blocked as storage pods are unavailable "+
+			"in multiple %ss %v", domainType, domains), nil
+	}
+
+	var updateDomain string
+	if len(unavailableDomains) == 1 {
+		for domain := range unavailableDomains {
+			updateDomain = domain
+		}
+	} else {
+		updatedDomains := make(map[string]bool)
+		for _, pod := range newPods {
+			updatedDomains[nodeToDomain[pod.Spec.NodeName]] = true
+		}
+		pendingDomains := make([]string, 0)
+		seen := make(map[string]bool)
+		for _, pod := range oldPods {
+			domain := nodeToDomain[pod.Spec.NodeName]
+			if !seen[domain] {
+				seen[domain] = true
+				pendingDomains = append(pendingDomains, domain)
+			}
+		}
+		if len(pendingDomains) == 0 {
+			return nil, "", nil
+		}
+		sort.Strings(pendingDomains)
+		updateDomain = pendingDomains[0]
+		for _, domain := range pendingDomains {
+			if updatedDomains[domain] {
+				updateDomain = domain
+				break
+			}
+		}
+	}
+
+	podsInDomain := make([]*v1.Pod, 0)
+	for _, pod := range oldPods {
+		if nodeToDomain[pod.Spec.NodeName] == updateDomain {
+			podsInDomain = append(podsInDomain, pod)
+		}
+	}
+	if len(podsInDomain) == 0 && len(oldPods) > 0 {
+		return nil, fmt.Sprintf("Upgrade is waiting for storage pods in %s %q "+
+			"to become available", domainType, updateDomain), nil
+	}
+	logrus.Debugf("Updating storage pods in %s %q of storage cluster %v/%v",
+		domainType, updateDomain, cluster.Namespace, cluster.Name)
+	return podsInDomain, "", nil
+}
+
+// getNodeFailureDomains returns the failure domain of every node, as per the
+// failure domain of the rolling update strategy. The topology reported in the
+// status of the StorageNodes is used, falling back to the zone of the node from
+// the cloud provider.
+func (c *Controller) getNodeFailureDomains(
+	cluster *corev1alpha1.StorageCluster,
+) (map[string]string, error) {
+	nodeList := &v1.NodeList{}
+	if err := c.client.List(context.TODO(), nodeList, &client.ListOptions{}); err != nil {
+		return nil, fmt.Errorf("couldn't get list of nodes: %v", err)
+	}
+	storageNodeList := &corev1alpha1.StorageNodeList{}
+	err := c.client.List(context.TODO(), storageNodeList, &client.ListOptions{
+		Namespace: cluster.Namespace,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get list of storage nodes: %v", err)
+	}
+
+	nodeToGeo := make(map[string]corev1alpha1.Geography)
+	for _, storageNode := range storageNodeList.Items {
+		nodeToGeo[storageNode.Name] = storageNode.Status.Geo
+	}
+
+	cloudProvider := cloudprovider.New(getCloudProviderName(nodeList.Items))
+	nodeToDomain := make(map[string]string)
+	for _, node := range nodeList.Items {
+		geo := nodeToGeo[node.Name]
+		switch cluster.Spec.UpdateStrategy.RollingUpdate.FailureDomain {
+		case corev1alpha1.FailureDomainZone:
+			if len(geo.Zone) == 0 {
+				if zone, err := cloudProvider.GetZone(&node); err == nil {
+					geo.Zone = zone
+				}
+			}
+			nodeToDomain[node.Name] = geo.Zone
+		case corev1alpha1.FailureDomainRack:
+			nodeToDomain[node.Name] = geo.Rack
+		}
+	}
+	return nodeToDomain, nil
+}
+
+// canaryUpdateBudget returns the number of old pods that can still be updated
+// before the canary rollout of the given revision has to wait for promotion.
+// A negative budget means there is no limit. It also returns whether the canary
+// pods are updated and the rollout is waiting for the revision to be promoted.
+func canaryUpdateBudget(
+	cluster *corev1alpha1.StorageCluster,
+	hash string,
+	newPods, oldPods []*v1.Pod,
+	numMissing int,
+) (int, bool) {
+	canary := cluster.Spec.UpdateStrategy.RollingUpdate.Canary
+	if canary == nil || cluster.Annotations[AnnotationPromoteCanary] == hash {
+		return -1, false
+	}
+
+	// Pods being deleted and nodes missing storage pods will get pods running
+	// the new revision
+	updating := len(newPods) + numMissing
+	for _, pod := range oldPods {
+		if pod.DeletionTimestamp != nil {
+			updating++
+		}
+	}
+	budget := int(canary.Nodes) - updating
+	if budget <= 0 {
+		return 0, len(oldPods) > 0
+	}
+	return budget, false
+}
+
+// updateRolloutStatus updates the progress of rolling out the given revision in
+// the status of the StorageCluster.
+func (c *Controller) updateRolloutStatus(cluster *corev1alpha1.StorageCluster, hash string) error {
+	nodeToStoragePods, err := c.getNodeToStoragePods(cluster)
+	if err != nil {
+		return fmt.Errorf("couldn't get node to storage pod mapping for storage cluster %v: %v",
+			cluster.Name, err)
+	}
+	newPods, oldPods := c.getAllStorageClusterPods(cluster, nodeToStoragePods, hash)
+
+	rollout := &corev1alpha1.RolloutStatus{
+		UpdateRevision: hash,
+		State:          corev1alpha1.RolloutStateProgressing,
+		TotalPods:      int32(len(newPods) + len(oldPods)),
+		UpdatedPods:    int32(len(newPods)),
+	}
+	if len(oldPods) == 0 {
+		rollout.State = corev1alpha1.RolloutStateComplete
+		if isClusterConditionTrue(cluster, corev1alpha1.ClusterConditionTypeUpgrading) {
+			setClusterCondition(cluster, corev1alpha1.ClusterCondition{
+				Type:    corev1alpha1.ClusterConditionTypeUpgrading,
+				Status:  corev1alpha1.ConditionFalse,
+				Reason:  reasonUpdateCompleted,
+				Message: fmt.Sprintf("Updated all storage pods to revision %s", hash),
+			})
+		}
+	} else if cluster.Spec.UpdateStrategy.Type == corev1alpha1.OnDeleteStorageClusterStrategyType {
+		setClusterCondition(cluster, corev1alpha1.ClusterCondition{
+			Type:   corev1alpha1.ClusterConditionTypeUpgrading,
+			Status: corev1alpha1.ConditionTrue,
+			Reason: reasonUpdatePending,
+			Message: fmt.Sprintf("%d storage pods will be updated to revision %s once they are deleted",
+				len(oldPods), hash),
+		})
+	} else if cluster.Spec.UpdateStrategy.Type == corev1alpha1.RollingUpdateStorageClusterStrategyType {
+		oldPodsInPartition, err := c.podsInUpdatePartition(cluster, nodeToStoragePods, oldPods)
+		if err != nil {
+			return fmt.Errorf("couldn't get pods in the update partition: %v", err)
+		}
+		_, _, numMissing, err := c.getUnavailableNumbers(cluster, nodeToStoragePods)
+		if err != nil {
+			return fmt.Errorf("couldn't get unavailable numbers: %v", err)
+		}
+		_, canaryWaiting := canaryUpdateBudget(cluster, hash, newPods, oldPodsInPartition, numMissing)
+		upgrading := corev1alpha1.ClusterCondition{
+			Type:   corev1alpha1.ClusterConditionTypeUpgrading,
+			Status: corev1alpha1.ConditionTrue,
+		}
+		if cluster.Spec.UpdateStrategy.RollingUpdate.Paused {
+			rollout.State = corev1alpha1.RolloutStatePaused
+			upgrading.Reason = reasonUpdatePaused
+			upgrading.Message = fmt.Sprintf("Update of storage pods to revision %s is paused", hash)
+		} else if len(oldPodsInPartition) == 0 {
+			rollout.State = corev1alpha1.RolloutStatePartitioned
+			upgrading.Reason = reasonUpdatePartitioned
+			upgrading.Message = fmt.Sprintf("Updated all storage pods in the partition to revision %s", hash)
+		} else if canaryWaiting {
+			rollout.State = corev1alpha1.RolloutStateAwaitingPromotion
+			upgrading.Reason = reasonUpdateAwaitingPromotion
+			upgrading.Message = fmt.Sprintf("Canary storage pods are updated to revision %s "+
+				"and the update is waiting to be promoted", hash)
+		} else if !isClusterConditionTrue(cluster, corev1alpha1.ClusterConditionTypeUpgrading) {
+			upgrading.Reason = reasonUpdateInProgress
+			upgrading.Message = fmt.Sprintf("Updated %d of %d storage pods to revision %s",
+				len(newPods), len(newPods)+len(oldPods), hash)
+		}
+		if len(upgrading.Reason) > 0 {
+			setClusterCondition(cluster, upgrading)
+		}
+	}
+	cluster.Status.Rollout = rollout
+	return nil
+}
+
+// updatePodStatus updates the status of the cluster with the number of nodes
+// running the storage pods and the revisions of those pods, similar to the
+// status of a DaemonSet. The given nodes are the ones that should be running
+// the storage pods.
+func (c *Controller) updatePodStatus(
+	cluster *corev1alpha1.StorageCluster,
+	hash string,
+	desiredNodes map[string]bool,
+) error {
+	nodeToStoragePods, err := c.getNodeToStoragePods(cluster)
+	if err != nil {
+		return fmt.Errorf("couldn't get node to storage pod mapping for storage cluster %v: %v",
+			cluster.Name, err)
+	}
+
+	var current, ready, updated, available int32
+	now := metav1.Now()
+	for nodeName := range desiredNodes {
+		storagePods, exists := nodeToStoragePods[nodeName]
+		if !exists || len(storagePods) == 0 {
+			continue
+		}
+		current++
+
+		sort.Sort(podByCreationTimestampAndPhase(storagePods))
+		pod := storagePods[0]
+		if podutil.IsPodReady(pod) {
+			ready++
+			if podutil.IsPodAvailable(pod, 0, now) {
+				available++
+			}
+		}
+		if c.isPodUpdated(cluster, pod, hash) {
+			updated++
+		}
+	}
+
+	cluster.Status.DesiredNumberScheduled = int32(len(desiredNodes))
+	cluster.Status.CurrentNumberScheduled = current
+	cluster.Status.NumberReady = ready
+	cluster.Status.UpdatedNumberScheduled = updated
+	cluster.Status.NumberAvailable = available
+	cluster.Status.UpdateRevision = hash
+
+	// The current revision is the one the storage pods ran before the update
+	// revision was rolled out. Once all pods are updated, it is the update revision.
+	_, oldPods := c.getAllStorageClusterPods(cluster, nodeToStoragePods, hash)
+	if len(oldPods) == 0 {
+		cluster.Status.CurrentRevision = hash
+	} else if cluster.Status.CurrentRevision == "" || cluster.Status.CurrentRevision == hash {
+		cluster.Status.CurrentRevision = oldPods[0].Labels[defaultStorageClusterUniqueLabelKey]
+	}
+	return nil
+}
+
+// rollback restores the spec of the StorageCluster from the revision requested
+// in spec.rollbackTo. The update and delete strategies and the revision history
+// limit are not rolled back, so that the storage pods are updated to the restored
+// revision as per the current update strategy. The rollback request is cleared
+// even if the rollback fails, so that it is not retried.
+func (c *Controller) rollback(cluster *corev1alpha1.StorageCluster) error {
+	toUpdate := cluster.DeepCopy()
+	toUpdate.Spec.RollbackTo = nil
+
+	var condition corev1alpha1.ClusterCondition
+	target, revision, err := c.rollbackTarget(cluster)
+	if err == nil {
+		err = c.Driver.ValidateRollback(cluster, target)
+	}
+	if err != nil {
+		condition = corev1alpha1.ClusterCondition{
+			Type:    corev1alpha1.ClusterConditionTypeDegraded,
+			Status:  corev1alpha1.ConditionTrue,
+			Reason:  reasonRollbackFailed,
+			Message: fmt.Sprintf("Failed to roll back StorageCluster: %v", err),
+		}
+		c.warningEvent(cluster, util.FailedRollbackReason, condition.Message)
+	} else {
+		toUpdate.Spec = target.Spec
+		condition = corev1alpha1.ClusterCondition{
+			Type:    corev1alpha1.ClusterConditionTypeUpgrading,
+			Status:  corev1alpha1.ConditionTrue,
+			Reason:  reasonRolledBack,
+			Message: fmt.Sprintf("Rolled back StorageCluster to revision %d", revision),
+		}
+		logrus.Info(condition.Message)
+		c.recorder.Event(cluster, v1.EventTypeNormal, util.RollbackDoneReason, condition.Message)
+	}
+
+	if err := c.client.Update(context.TODO(), toUpdate); err != nil {
+		return fmt.Errorf("failed to update StorageCluster %v/%v after rollback: %v",
+			cluster.Namespace, cluster.Name, err)
+	}
+	setClusterCondition(toUpdate, condition)
+	return k8sutil.UpdateStorageClusterStatus(c.client, toUpdate)
+}
+
+// rollbackTarget returns the StorageCluster with the spec restored from the revision
+// requested in spec.rollbackTo, along with the number of that revision. Revision 0
+// selects the last revision before the current one.
+func (c *Controller) rollbackTarget(
+	cluster *corev1alpha1.StorageCluster,
+) (*corev1alpha1.StorageCluster, int64, error) {
+	histories, err := c.controlledHistories(cluster)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get revisions: %v", err)
+	}
+
+	current := cluster.DeepCopy()
+	current.Spec.RollbackTo = nil
+	revision := cluster.Spec.RollbackTo.Revision
+	var targetHistory *apps.ControllerRevision
+	for _, history := range histories {
+		if revision != 0 {
+			if history.Revision == revision {
+				targetHistory = history
+				break
+			}
+			continue
+		}
+		isCurrent, err := match(current, history)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !isCurrent && (targetHistory == nil || history.Revision > targetHistory.Revision) {
+			targetHistory = history
+		}
+	}
+	if targetHistory == nil && revision == 0 {
+		return nil, 0, fmt.Errorf("unable to find a previous revision to roll back to")
+	} else if targetHistory == nil {
+		return nil, 0, fmt.Errorf("unable to find revision %d to roll back to", revision)
+	}
+
+	spec, err := specFromHistory(targetHistory)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid spec in revision %d: %v", targetHistory.Revision, err)
+	} else if spec == nil {
+		return nil, 0, fmt.Errorf("revision %d does not have a spec", targetHistory.Revision)
+	}
+
+	target := current.DeepCopy()
+	target.Spec = *spec
+	target.Spec.UpdateStrategy = current.Spec.UpdateStrategy
+	target.Spec.DeleteStrategy = current.Spec.DeleteStrategy
+	target.Spec.RevisionHistoryLimit = current.Spec.RevisionHistoryLimit
+	target.Spec.RollbackTo = nil
+	return target, targetHistory.Revision, nil
+}
+
 // constructHistory finds all histories controlled by the given StorageCluster, and
 // update current history revision number, or create current history if needed to.
 // It also deduplicates current history, and adds missing unique labels to existing histories.
@@ -205,7 +746,8 @@ func (c *Controller) snapshot(
 		return nil, err
 	}
 
-	hash := computeHash(&cluster.Spec, cluster.Status.CollisionCount)
+	hash := computeHash(&cluster.Spec, cluster.Annotations[annotationSecretChecksum],
+		cluster.Status.CollisionCount)
 	name := historyName(cluster.Name, hash)
 	historyLabels := c.storageClusterSelectorLabels(cluster)
 	historyLabels[defaultStorageClusterUniqueLabelKey] = hash
@@ -336,8 +878,16 @@ func (c *Controller) getAllStorageClusterPods(
 	var newPods []*v1.Pod
 	var oldPods []*v1.Pod
 
-	for _, pods := range nodeToStoragePods {
-		for _, pod := range pods {
+	// Go through the nodes in a fixed order, so the storage pods are
+	// updated in the same order across reconciles
+	nodeNames := make([]string, 0, len(nodeToStoragePods))
+	for nodeName := range nodeToStoragePods {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	for _, nodeName := range nodeNames {
+		for _, pod := range nodeToStoragePods[nodeName] {
 			// If the returned error is not nil we have a parse error.
 			// The controller handles this via the hash.
 			if c.isPodUpdated(cluster, pod, hash) {
@@ -350,23 +900,35 @@ func (c *Controller) getAllStorageClusterPods(
 	return newPods, oldPods
 }
 
+// getUnavailableNumbers returns the maximum number of storage pods that can be
+// unavailable, the nodes whose storage pods are unavailable and the number of
+// nodes that should be running a storage pod but are missing it. Nodes claimed
+// by other StorageClusters are not counted.
 func (c *Controller) getUnavailableNumbers(
 	cluster *corev1alpha1.StorageCluster,
 	nodeToStoragePods map[string][]*v1.Pod,
-) (int, int, error) {
+) (int, []string, int, error) {
 	logrus.Debugf("Getting unavailable numbers")
 	nodeList := &v1.NodeList{}
 	err := c.client.List(context.TODO(), nodeList, &client.ListOptions{})
 	if err != nil {
-		return -1, -1, fmt.Errorf("couldn't get list of nodes during rolling "+
+		return -1, nil, -1, fmt.Errorf("couldn't get list of nodes during rolling "+
 			"update of storage cluster  %#v: %v", cluster, err)
 	}
+	claimedNodes, err := c.getNodesClaimedByOtherClusters(cluster, nodeList.Items, nodeToStoragePods)
+	if err != nil {
+		return -1, nil, -1, err
+	}
 
-	var numUnavailable, desiredNumberScheduled int
+	var unavailableNodes []string
+	var numMissing, desiredNumberScheduled int
 	for _, node := range nodeList.Items {
+		if _, claimed := claimedNodes[node.Name]; claimed {
+			continue
+		}
 		wantToRun, _, _, err := c.nodeShouldRunStoragePod(&node, cluster)
 		if err != nil {
-			return -1, -1, err
+			return -1, nil, -1, err
 		}
 		if !wantToRun {
 			continue
@@ -374,7 +936,8 @@ func (c *Controller) getUnavailableNumbers(
 		desiredNumberScheduled++
 		storagePods, exists := nodeToStoragePods[node.Name]
 		if !exists {
-			numUnavailable++
+			unavailableNodes = append(unavailableNodes, node.Name)
+			numMissing++
 			continue
 		}
 		available := false
@@ -387,7 +950,7 @@ func (c *Controller) getUnavailableNumbers(
 			}
 		}
 		if !available {
-			numUnavailable++
+			unavailableNodes = append(unavailableNodes, node.Name)
 		}
 	}
 
@@ -397,11 +960,11 @@ func (c *Controller) getUnavailableNumbers(
 		true,
 	)
 	if err != nil {
-		return -1, -1, fmt.Errorf("invalid value for MaxUnavailable: %v", err)
+		return -1, nil, -1, fmt.Errorf("invalid value for MaxUnavailable: %v", err)
 	}
 	logrus.Debugf("StorageCluster %s/%s, maxUnavailable: %d, numUnavailable: %d",
-		cluster.Namespace, cluster.Name, maxUnavailable, numUnavailable)
-	return maxUnavailable, numUnavailable, nil
+		cluster.Namespace, cluster.Name, maxUnavailable, len(unavailableNodes))
+	return maxUnavailable, unavailableNodes, numMissing, nil
 }
 
 func (c *Controller) cleanupHistory(
@@ -497,6 +1060,23 @@ func (c *Controller) isPodUpdated(
 		return true
 	}
 
+	// Pods using old secrets need an update even if the spec has not changed.
+	// Pods without a checksum were created before the checksum was recorded,
+	// for instance by an older operator, and are assumed to use the current
+	// secrets. Whether they need an update is decided by their spec alone.
+	if podChecksum, exists := pod.Annotations[annotationSecretChecksum]; exists &&
+		podChecksum != cluster.Annotations[annotationSecretChecksum] {
+		return false
+	}
+
+	// If the effective spec of the node group the pod belongs to has not changed,
+	// then changes to the rest of the cluster spec or to other node groups do not
+	// need an update of the pod.
+	if groupHash, exists := pod.Annotations[annotationNodeGroupHash]; exists && len(podHash) > 0 &&
+		groupHash == computeNodeGroupHash(clusterSpecForNode(node, &cluster.Spec)) {
+		return true
+	}
+
 	podHistory := &apps.ControllerRevision{}
 	podHistoryName := historyName(cluster.Name, podHash)
 	err = c.client.Get(
@@ -534,31 +1114,18 @@ func matchSelectedFields(
 	node *v1.Node,
 	oldNodeLabels map[string]string,
 ) (bool, error) {
-	var raw map[string]interface{}
-	err := json.Unmarshal(history.Data.Raw, &raw)
+	oldSpec, err := specFromHistory(history)
 	if err != nil {
 		return false, err
-	}
-
-	spec, ok := raw["spec"].(map[string]interface{})
-	if !ok {
+	} else if oldSpec == nil {
 		return false, nil
 	}
-	delete(spec, "$patch")
-
-	rawHistory, err := json.Marshal(spec)
-	if err != nil {
-		return false, err
-	}
-
-	oldSpec := &corev1alpha1.StorageClusterSpec{}
-	err = json.Unmarshal(rawHistory, oldSpec)
-	if err != nil {
-		return false, err
-	}
 
 	oldNode := node.DeepCopy()
 	oldNode.Labels = oldNodeLabels
+	if !reflect.DeepEqual(nodeGroupTolerations(oldNode, oldSpec), nodeGroupTolerations(node, &cluster.Spec)) {
+		return false, nil
+	}
 	oldSpec = clusterSpecForNode(oldNode, oldSpec)
 	currentSpec := clusterSpecForNode(node, &cluster.Spec)
 
@@ -570,10 +1137,12 @@ func matchSelectedFields(
 		return false, nil
 	} else if !reflect.DeepEqual(oldSpec.Kvdb, currentSpec.Kvdb) {
 		return false, nil
-	} else if !reflect.DeepEqual(oldSpec.CloudStorage, currentSpec.CloudStorage) {
+	} else if !reflect.DeepEqual(podCloudStorage(oldSpec), podCloudStorage(currentSpec)) {
 		return false, nil
 	} else if !reflect.DeepEqual(oldSpec.SecretsProvider, currentSpec.SecretsProvider) {
 		return false, nil
+	} else if !reflect.DeepEqual(oldSpec.Security, currentSpec.Security) {
+		return false, nil
 	} else if !reflect.DeepEqual(oldSpec.StartPort, currentSpec.StartPort) {
 		return false, nil
 	} else if !reflect.DeepEqual(oldSpec.FeatureGates, currentSpec.FeatureGates) {
@@ -584,12 +1153,40 @@ func matchSelectedFields(
 		return false, nil
 	} else if !reflect.DeepEqual(oldSpec.RuntimeOpts, currentSpec.RuntimeOpts) {
 		return false, nil
+	} else if !reflect.DeepEqual(oldSpec.Resources, currentSpec.Resources) {
+		return false, nil
+	} else if oldSpec.PriorityClassName != currentSpec.PriorityClassName {
+		return false, nil
+	} else if !reflect.DeepEqual(csiResources(oldSpec), csiResources(currentSpec)) {
+		return false, nil
 	} else if !isEnvEqual(oldSpec.Env, currentSpec.Env) {
 		return false, nil
 	}
 	return true, nil
 }
 
+// podCloudStorage returns the cloud storage config from the given spec without
+// the requested pool sizes, as storage pools are expanded without restarting
+// the storage pods
+func podCloudStorage(clusterSpec *corev1alpha1.StorageClusterSpec) *corev1alpha1.CloudStorageSpec {
+	if clusterSpec.CloudStorage == nil {
+		return nil
+	}
+	cloudStorage := clusterSpec.CloudStorage.DeepCopy()
+	for i := range cloudStorage.CapacitySpecs {
+		cloudStorage.CapacitySpecs[i].PoolSizeInGiB = 0
+	}
+	return cloudStorage
+}
+
+// csiResources returns the resources of the CSI sidecars from the given spec
+func csiResources(clusterSpec *corev1alpha1.StorageClusterSpec) *v1.ResourceRequirements {
+	if clusterSpec.CSI == nil {
+		return nil
+	}
+	return clusterSpec.CSI.Resources
+}
+
 // clusterSpecForNode returns the corresponding StorageCluster spec for given node.
 // If there is node specific configuration in the cluster, it will merge it with
 // the top level cluster spec and return the updated cluster spec.
@@ -597,15 +1194,26 @@ func clusterSpecForNode(
 	node *v1.Node,
 	clusterSpec *corev1alpha1.StorageClusterSpec,
 ) *corev1alpha1.StorageClusterSpec {
-	var (
-		nodeLabels       = labels.Set(node.Labels)
-		matchingNodeSpec *corev1alpha1.NodeSpec
-	)
+	var matchingNodeSpec *corev1alpha1.NodeSpec
+	if nodeSpec := nodeSpecForNode(node, clusterSpec); nodeSpec != nil {
+		matchingNodeSpec = nodeSpec.DeepCopy()
+	}
+
+	newClusterSpec := clusterSpec.DeepCopy()
+	overwriteClusterSpecWithNodeSpec(newClusterSpec, matchingNodeSpec)
+	return newClusterSpec
+}
 
-	for _, nodeSpec := range clusterSpec.Nodes {
+// nodeSpecForNode returns the node specific configuration from the cluster spec
+// that applies to the given node. Returns nil if there is no matching node spec.
+func nodeSpecForNode(
+	node *v1.Node,
+	clusterSpec *corev1alpha1.StorageClusterSpec,
+) *corev1alpha1.NodeSpec {
+	nodeLabels := labels.Set(node.Labels)
+	for i, nodeSpec := range clusterSpec.Nodes {
 		if nodeSpec.Selector.NodeName == node.Name {
-			matchingNodeSpec = nodeSpec.DeepCopy()
-			break
+			return &clusterSpec.Nodes[i]
 		} else if len(nodeSpec.Selector.NodeName) == 0 {
 			nodeSelector, err := metav1.LabelSelectorAsSelector(nodeSpec.Selector.LabelSelector)
 			if err != nil {
@@ -613,15 +1221,25 @@ func clusterSpecForNode(
 				continue
 			}
 			if nodeSelector.Matches(nodeLabels) {
-				matchingNodeSpec = nodeSpec.DeepCopy()
-				break
+				return &clusterSpec.Nodes[i]
 			}
 		}
 	}
+	return nil
+}
 
-	newClusterSpec := clusterSpec.DeepCopy()
-	overwriteClusterSpecWithNodeSpec(newClusterSpec, matchingNodeSpec)
-	return newClusterSpec
+// nodeGroupTolerations returns the tolerations of the node group the given node
+// belongs to. Unlike the cluster level placement, where pods that no longer fit
+// are removed by the scheduling checks, these are part of the node group spec
+// and changing them updates the storage pods of the group.
+func nodeGroupTolerations(
+	node *v1.Node,
+	clusterSpec *corev1alpha1.StorageClusterSpec,
+) []v1.Toleration {
+	if nodeSpec := nodeSpecForNode(node, clusterSpec); nodeSpec != nil {
+		return nodeSpec.Tolerations
+	}
+	return nil
 }
 
 // overwriteClusterSpecWithNodeSpec updates the input cluster spec configuration
@@ -634,24 +1252,78 @@ func overwriteClusterSpecWithNodeSpec(
 	if nodeSpec == nil {
 		return
 	}
+	if len(nodeSpec.Image) > 0 {
+		clusterSpec.Image = nodeSpec.Image
+	}
+	if len(nodeSpec.Tolerations) > 0 {
+		if clusterSpec.Placement == nil {
+			clusterSpec.Placement = &corev1alpha1.PlacementSpec{}
+		}
+		for _, nodeToleration := range nodeSpec.Tolerations {
+			found := false
+			for _, clusterToleration := range clusterSpec.Placement.Tolerations {
+				if clusterToleration.MatchToleration(&nodeToleration) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				clusterSpec.Placement.Tolerations = append(
+					clusterSpec.Placement.Tolerations, *nodeToleration.DeepCopy())
+			}
+		}
+	}
+	if nodeSpec.CloudStorage != nil {
+		nodeCloudStorage := nodeSpec.CloudStorage.DeepCopy()
+		if clusterSpec.CloudStorage == nil {
+			clusterSpec.CloudStorage = &corev1alpha1.CloudStorageSpec{}
+		}
+		if len(nodeCloudStorage.CapacitySpecs) > 0 {
+			clusterSpec.CloudStorage.DeviceSpecs = nil
+			clusterSpec.CloudStorage.CapacitySpecs = nodeCloudStorage.CapacitySpecs
+		}
+		if nodeCloudStorage.JournalDeviceSpec != nil {
+			clusterSpec.CloudStorage.JournalDeviceSpec = nodeCloudStorage.JournalDeviceSpec
+		}
+		if nodeCloudStorage.SystemMdDeviceSpec != nil {
+			clusterSpec.CloudStorage.SystemMdDeviceSpec = nodeCloudStorage.SystemMdDeviceSpec
+		}
+		if nodeCloudStorage.KvdbDeviceSpec != nil {
+			clusterSpec.CloudStorage.KvdbDeviceSpec = nodeCloudStorage.KvdbDeviceSpec
+		}
+	}
 	if nodeSpec.Storage != nil {
 		clusterSpec.Storage = nodeSpec.Storage.DeepCopy()
 	}
 	if nodeSpec.Network != nil {
 		clusterSpec.Network = nodeSpec.Network.DeepCopy()
 	}
+	if nodeSpec.Resources != nil {
+		clusterSpec.Resources = nodeSpec.Resources.DeepCopy()
+	}
 	if len(nodeSpec.Env) > 0 {
-		envMap := make(map[string]*v1.EnvVar)
+		// Node level env variables override the cluster level ones with the
+		// same name. The order of the variables is kept stable, so that the
+		// pod template does not change between reconciles.
+		nodeEnvs := make(map[string]*v1.EnvVar)
+		for _, nodeEnv := range nodeSpec.Env {
+			nodeEnvs[nodeEnv.Name] = nodeEnv.DeepCopy()
+		}
+		envs := make([]v1.EnvVar, 0, len(clusterSpec.Env)+len(nodeSpec.Env))
 		for _, clusterEnv := range clusterSpec.Env {
-			envMap[clusterEnv.Name] = clusterEnv.DeepCopy()
+			if nodeEnv, exists := nodeEnvs[clusterEnv.Name]; exists {
+				envs = append(envs, *nodeEnv)
+				delete(nodeEnvs, clusterEnv.Name)
+			} else {
+				envs = append(envs, clusterEnv)
+			}
 		}
 		for _, nodeEnv := range nodeSpec.Env {
-			envMap[nodeEnv.Name] = nodeEnv.DeepCopy()
-		}
-		clusterSpec.Env = make([]v1.EnvVar, 0)
-		for _, env := range envMap {
-			clusterSpec.Env = append(clusterSpec.Env, *env)
+			if _, pending := nodeEnvs[nodeEnv.Name]; pending {
+				envs = append(envs, *nodeEnv.DeepCopy())
+			}
 		}
+		clusterSpec.Env = envs
 	}
 	if len(nodeSpec.RuntimeOpts) > 0 {
 		clusterSpec.RuntimeOpts = make(map[string]string)
@@ -699,6 +1371,36 @@ func match(
 	return bytes.Equal(patch, history.Data.Raw), nil
 }
 
+// specFromHistory returns the StorageCluster spec stored in the given revision.
+// It returns nil if the revision does not have a spec.
+func specFromHistory(
+	history *apps.ControllerRevision,
+) (*corev1alpha1.StorageClusterSpec, error) {
+	var raw map[string]interface{}
+	err := json.Unmarshal(history.Data.Raw, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, ok := raw["spec"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	delete(spec, "$patch")
+
+	rawHistory, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	historySpec := &corev1alpha1.StorageClusterSpec{}
+	err = json.Unmarshal(rawHistory, historySpec)
+	if err != nil {
+		return nil, err
+	}
+	return historySpec, nil
+}
+
 // getPatch returns a strategic merge patch that can be applied to restore a StorageCluster
 // to a previous version. If the returned error is nil the patch is valid.
 func getPatch(cluster *corev1alpha1.StorageCluster) ([]byte, error) {
@@ -717,6 +1419,16 @@ func getPatch(cluster *corev1alpha1.StorageCluster) ([]byte, error) {
 	spec := raw["spec"].(map[string]interface{})
 	spec["$patch"] = "replace"
 	objCopy["spec"] = spec
+
+	// Include the checksum of the secrets used by the storage pods, so that a
+	// change in the secrets results in a new revision
+	if checksum := cluster.Annotations[annotationSecretChecksum]; len(checksum) > 0 {
+		objCopy["metadata"] = map[string]interface{}{
+			"annotations": map[string]interface{}{
+				annotationSecretChecksum: checksum,
+			},
+		}
+	}
 	return json.Marshal(objCopy)
 }
 
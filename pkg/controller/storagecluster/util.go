@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"hash/fnv"
 	"reflect"
+	"sort"
+	"strings"
 
 	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
 	v1 "k8s.io/api/core/v1"
@@ -68,13 +70,23 @@ func addOrUpdateStoragePodTolerations(podSpec *v1.PodSpec) {
 	})
 }
 
-// computeHash returns a hash value calculated from StorageClusterSpec and
-// a collisionCount to avoid hash collision. The hash will be safe encoded to
-// avoid bad words.
-func computeHash(clusterSpec *corev1alpha1.StorageClusterSpec, collisionCount *int32) string {
+// computeHash returns a hash value calculated from StorageClusterSpec, the
+// checksum of the secrets used by the storage pods and a collisionCount to avoid
+// hash collision.
+// The hash will be safe encoded to avoid bad words.
+func computeHash(
+	clusterSpec *corev1alpha1.StorageClusterSpec,
+	secretChecksum string,
+	collisionCount *int32,
+) string {
 	storageClusterSpecHasher := fnv.New32a()
 	hashutil.DeepHashObject(storageClusterSpecHasher, *clusterSpec)
 
+	// Add the secret checksum in the hash if the cluster uses any secrets
+	if len(secretChecksum) > 0 {
+		storageClusterSpecHasher.Write([]byte(secretChecksum))
+	}
+
 	// Add collisionCount in the hash if it exists.
 	if collisionCount != nil {
 		collisionCountBytes := make([]byte, 8)
@@ -85,6 +97,41 @@ func computeHash(clusterSpec *corev1alpha1.StorageClusterSpec, collisionCount *i
 	return rand.SafeEncodeString(fmt.Sprint(storageClusterSpecHasher.Sum32()))
 }
 
+// computeNodeGroupHash returns the hash of the effective spec of the storage
+// pods on a group of nodes. It only covers the fields that affect the storage
+// pods, so that changes to other fields of the cluster or to the configuration
+// of other node groups do not change the hash.
+func computeNodeGroupHash(clusterSpec *corev1alpha1.StorageClusterSpec) string {
+	// The order of the env variables does not affect the storage pods
+	env := make([]v1.EnvVar, len(clusterSpec.Env))
+	copy(env, clusterSpec.Env)
+	sort.SliceStable(env, func(i, j int) bool {
+		return env[i].Name < env[j].Name
+	})
+
+	nodeGroupHasher := fnv.New32a()
+	hashutil.DeepHashObject(nodeGroupHasher, []interface{}{
+		clusterSpec.Image,
+		clusterSpec.CustomImageRegistry,
+		clusterSpec.ImagePullSecret,
+		clusterSpec.Placement,
+		clusterSpec.Kvdb,
+		podCloudStorage(clusterSpec),
+		clusterSpec.SecretsProvider,
+		clusterSpec.Security,
+		clusterSpec.StartPort,
+		clusterSpec.FeatureGates,
+		clusterSpec.Network,
+		clusterSpec.Storage,
+		clusterSpec.RuntimeOpts,
+		clusterSpec.Resources,
+		clusterSpec.PriorityClassName,
+		csiResources(clusterSpec),
+		env,
+	})
+	return rand.SafeEncodeString(fmt.Sprint(nodeGroupHasher.Sum32()))
+}
+
 func indexByPodNodeName(obj runtime.Object) []string {
 	pod, isPod := obj.(*v1.Pod)
 	if !isPod {
@@ -150,3 +197,17 @@ func isEnvEqual(listA, listB []v1.EnvVar) bool {
 	}
 	return true
 }
+
+// getCloudProviderName returns the name of the cloud provider of the given nodes
+func getCloudProviderName(nodes []v1.Node) string {
+	for _, node := range nodes {
+		// From kubernetes node spec:  <ProviderName>://<ProviderSpecificNodeID>
+		if len(node.Spec.ProviderID) != 0 {
+			tokens := strings.Split(node.Spec.ProviderID, "://")
+			if len(tokens) == 2 {
+				return tokens[0]
+			} // else provider id is invalid
+		}
+	}
+	return ""
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/libopenstorage/openstorage/api (interfaces: OpenStorageNodeServer,OpenStorageClusterServer)
+// Source: github.com/libopenstorage/openstorage/api (interfaces: OpenStorageNodeServer,OpenStorageClusterServer,OpenStorageVolumeServer,OpenStorageAlertsServer)
 
 // Package mock is a generated GoMock package.
 package mock
@@ -122,3 +122,269 @@ func (m *MockOpenStorageClusterServer) InspectCurrent(arg0 context.Context, arg1
 func (mr *MockOpenStorageClusterServerMockRecorder) InspectCurrent(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectCurrent", reflect.TypeOf((*MockOpenStorageClusterServer)(nil).InspectCurrent), arg0, arg1)
 }
+
+// MockOpenStorageVolumeServer is a mock of OpenStorageVolumeServer interface
+type MockOpenStorageVolumeServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockOpenStorageVolumeServerMockRecorder
+}
+
+// MockOpenStorageVolumeServerMockRecorder is the mock recorder for MockOpenStorageVolumeServer
+type MockOpenStorageVolumeServerMockRecorder struct {
+	mock *MockOpenStorageVolumeServer
+}
+
+// NewMockOpenStorageVolumeServer creates a new mock instance
+func NewMockOpenStorageVolumeServer(ctrl *gomock.Controller) *MockOpenStorageVolumeServer {
+	mock := &MockOpenStorageVolumeServer{ctrl: ctrl}
+	mock.recorder = &MockOpenStorageVolumeServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOpenStorageVolumeServer) EXPECT() *MockOpenStorageVolumeServerMockRecorder {
+	return m.recorder
+}
+
+// CapacityUsage mocks base method
+func (m *MockOpenStorageVolumeServer) CapacityUsage(arg0 context.Context, arg1 *api.SdkVolumeCapacityUsageRequest) (*api.SdkVolumeCapacityUsageResponse, error) {
+	ret := m.ctrl.Call(m, "CapacityUsage", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeCapacityUsageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CapacityUsage indicates an expected call of CapacityUsage
+func (mr *MockOpenStorageVolumeServerMockRecorder) CapacityUsage(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CapacityUsage", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).CapacityUsage), arg0, arg1)
+}
+
+// Clone mocks base method
+func (m *MockOpenStorageVolumeServer) Clone(arg0 context.Context, arg1 *api.SdkVolumeCloneRequest) (*api.SdkVolumeCloneResponse, error) {
+	ret := m.ctrl.Call(m, "Clone", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeCloneResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Clone indicates an expected call of Clone
+func (mr *MockOpenStorageVolumeServerMockRecorder) Clone(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clone", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).Clone), arg0, arg1)
+}
+
+// Create mocks base method
+func (m *MockOpenStorageVolumeServer) Create(arg0 context.Context, arg1 *api.SdkVolumeCreateRequest) (*api.SdkVolumeCreateResponse, error) {
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeCreateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockOpenStorageVolumeServerMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method
+func (m *MockOpenStorageVolumeServer) Delete(arg0 context.Context, arg1 *api.SdkVolumeDeleteRequest) (*api.SdkVolumeDeleteResponse, error) {
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockOpenStorageVolumeServerMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).Delete), arg0, arg1)
+}
+
+// Enumerate mocks base method
+func (m *MockOpenStorageVolumeServer) Enumerate(arg0 context.Context, arg1 *api.SdkVolumeEnumerateRequest) (*api.SdkVolumeEnumerateResponse, error) {
+	ret := m.ctrl.Call(m, "Enumerate", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeEnumerateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enumerate indicates an expected call of Enumerate
+func (mr *MockOpenStorageVolumeServerMockRecorder) Enumerate(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enumerate", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).Enumerate), arg0, arg1)
+}
+
+// EnumerateWithFilters mocks base method
+func (m *MockOpenStorageVolumeServer) EnumerateWithFilters(arg0 context.Context, arg1 *api.SdkVolumeEnumerateWithFiltersRequest) (*api.SdkVolumeEnumerateWithFiltersResponse, error) {
+	ret := m.ctrl.Call(m, "EnumerateWithFilters", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeEnumerateWithFiltersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnumerateWithFilters indicates an expected call of EnumerateWithFilters
+func (mr *MockOpenStorageVolumeServerMockRecorder) EnumerateWithFilters(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnumerateWithFilters", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).EnumerateWithFilters), arg0, arg1)
+}
+
+// Inspect mocks base method
+func (m *MockOpenStorageVolumeServer) Inspect(arg0 context.Context, arg1 *api.SdkVolumeInspectRequest) (*api.SdkVolumeInspectResponse, error) {
+	ret := m.ctrl.Call(m, "Inspect", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeInspectResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Inspect indicates an expected call of Inspect
+func (mr *MockOpenStorageVolumeServerMockRecorder) Inspect(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inspect", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).Inspect), arg0, arg1)
+}
+
+// InspectWithFilters mocks base method
+func (m *MockOpenStorageVolumeServer) InspectWithFilters(arg0 context.Context, arg1 *api.SdkVolumeInspectWithFiltersRequest) (*api.SdkVolumeInspectWithFiltersResponse, error) {
+	ret := m.ctrl.Call(m, "InspectWithFilters", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeInspectWithFiltersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InspectWithFilters indicates an expected call of InspectWithFilters
+func (mr *MockOpenStorageVolumeServerMockRecorder) InspectWithFilters(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectWithFilters", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).InspectWithFilters), arg0, arg1)
+}
+
+// SnapshotCreate mocks base method
+func (m *MockOpenStorageVolumeServer) SnapshotCreate(arg0 context.Context, arg1 *api.SdkVolumeSnapshotCreateRequest) (*api.SdkVolumeSnapshotCreateResponse, error) {
+	ret := m.ctrl.Call(m, "SnapshotCreate", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeSnapshotCreateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotCreate indicates an expected call of SnapshotCreate
+func (mr *MockOpenStorageVolumeServerMockRecorder) SnapshotCreate(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotCreate", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).SnapshotCreate), arg0, arg1)
+}
+
+// SnapshotEnumerate mocks base method
+func (m *MockOpenStorageVolumeServer) SnapshotEnumerate(arg0 context.Context, arg1 *api.SdkVolumeSnapshotEnumerateRequest) (*api.SdkVolumeSnapshotEnumerateResponse, error) {
+	ret := m.ctrl.Call(m, "SnapshotEnumerate", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeSnapshotEnumerateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotEnumerate indicates an expected call of SnapshotEnumerate
+func (mr *MockOpenStorageVolumeServerMockRecorder) SnapshotEnumerate(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotEnumerate", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).SnapshotEnumerate), arg0, arg1)
+}
+
+// SnapshotEnumerateWithFilters mocks base method
+func (m *MockOpenStorageVolumeServer) SnapshotEnumerateWithFilters(arg0 context.Context, arg1 *api.SdkVolumeSnapshotEnumerateWithFiltersRequest) (*api.SdkVolumeSnapshotEnumerateWithFiltersResponse, error) {
+	ret := m.ctrl.Call(m, "SnapshotEnumerateWithFilters", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeSnapshotEnumerateWithFiltersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotEnumerateWithFilters indicates an expected call of SnapshotEnumerateWithFilters
+func (mr *MockOpenStorageVolumeServerMockRecorder) SnapshotEnumerateWithFilters(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotEnumerateWithFilters", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).SnapshotEnumerateWithFilters), arg0, arg1)
+}
+
+// SnapshotRestore mocks base method
+func (m *MockOpenStorageVolumeServer) SnapshotRestore(arg0 context.Context, arg1 *api.SdkVolumeSnapshotRestoreRequest) (*api.SdkVolumeSnapshotRestoreResponse, error) {
+	ret := m.ctrl.Call(m, "SnapshotRestore", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeSnapshotRestoreResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotRestore indicates an expected call of SnapshotRestore
+func (mr *MockOpenStorageVolumeServerMockRecorder) SnapshotRestore(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotRestore", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).SnapshotRestore), arg0, arg1)
+}
+
+// SnapshotScheduleUpdate mocks base method
+func (m *MockOpenStorageVolumeServer) SnapshotScheduleUpdate(arg0 context.Context, arg1 *api.SdkVolumeSnapshotScheduleUpdateRequest) (*api.SdkVolumeSnapshotScheduleUpdateResponse, error) {
+	ret := m.ctrl.Call(m, "SnapshotScheduleUpdate", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeSnapshotScheduleUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotScheduleUpdate indicates an expected call of SnapshotScheduleUpdate
+func (mr *MockOpenStorageVolumeServerMockRecorder) SnapshotScheduleUpdate(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotScheduleUpdate", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).SnapshotScheduleUpdate), arg0, arg1)
+}
+
+// Stats mocks base method
+func (m *MockOpenStorageVolumeServer) Stats(arg0 context.Context, arg1 *api.SdkVolumeStatsRequest) (*api.SdkVolumeStatsResponse, error) {
+	ret := m.ctrl.Call(m, "Stats", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeStatsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats
+func (mr *MockOpenStorageVolumeServerMockRecorder) Stats(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).Stats), arg0, arg1)
+}
+
+// Update mocks base method
+func (m *MockOpenStorageVolumeServer) Update(arg0 context.Context, arg1 *api.SdkVolumeUpdateRequest) (*api.SdkVolumeUpdateResponse, error) {
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkVolumeUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockOpenStorageVolumeServerMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOpenStorageVolumeServer)(nil).Update), arg0, arg1)
+}
+
+// MockOpenStorageAlertsServer is a mock of OpenStorageAlertsServer interface
+type MockOpenStorageAlertsServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockOpenStorageAlertsServerMockRecorder
+}
+
+// MockOpenStorageAlertsServerMockRecorder is the mock recorder for MockOpenStorageAlertsServer
+type MockOpenStorageAlertsServerMockRecorder struct {
+	mock *MockOpenStorageAlertsServer
+}
+
+// NewMockOpenStorageAlertsServer creates a new mock instance
+func NewMockOpenStorageAlertsServer(ctrl *gomock.Controller) *MockOpenStorageAlertsServer {
+	mock := &MockOpenStorageAlertsServer{ctrl: ctrl}
+	mock.recorder = &MockOpenStorageAlertsServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOpenStorageAlertsServer) EXPECT() *MockOpenStorageAlertsServerMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method
+func (m *MockOpenStorageAlertsServer) Delete(arg0 context.Context, arg1 *api.SdkAlertsDeleteRequest) (*api.SdkAlertsDeleteResponse, error) {
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(*api.SdkAlertsDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockOpenStorageAlertsServerMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOpenStorageAlertsServer)(nil).Delete), arg0, arg1)
+}
+
+// EnumerateWithFilters mocks base method
+func (m *MockOpenStorageAlertsServer) EnumerateWithFilters(arg0 *api.SdkAlertsEnumerateWithFiltersRequest, arg1 api.OpenStorageAlerts_EnumerateWithFiltersServer) error {
+	ret := m.ctrl.Call(m, "EnumerateWithFilters", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnumerateWithFilters indicates an expected call of EnumerateWithFilters
+func (mr *MockOpenStorageAlertsServerMockRecorder) EnumerateWithFilters(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnumerateWithFilters", reflect.TypeOf((*MockOpenStorageAlertsServer)(nil).EnumerateWithFilters), arg0, arg1)
+}
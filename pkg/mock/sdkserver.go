@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"sync"
@@ -8,6 +9,7 @@ import (
 	"github.com/libopenstorage/openstorage/api"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -16,6 +18,8 @@ import (
 type SdkServers struct {
 	Cluster *MockOpenStorageClusterServer
 	Node    *MockOpenStorageNodeServer
+	Volume  *MockOpenStorageVolumeServer
+	Alerts  *MockOpenStorageAlertsServer
 }
 
 // SdkServer can be used to create a sdk server which implements mock server
@@ -37,13 +41,27 @@ func NewSdkServer(servers SdkServers) *SdkServer {
 
 // StartOnAddress starts a new gRPC server listening on given address.
 func (m *SdkServer) StartOnAddress(ip, port string) error {
+	return m.start(ip, port)
+}
+
+// StartTLSOnAddress starts a new gRPC server listening on given address
+// that serves the given PEM encoded certificate and key.
+func (m *SdkServer) StartTLSOnAddress(ip, port string, certPEM, keyPEM []byte) error {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	return m.start(ip, port, grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
+}
+
+func (m *SdkServer) start(ip, port string, opts ...grpc.ServerOption) error {
 	var err error
 	m.listener, err = net.Listen("tcp", fmt.Sprintf("%s:%s", ip, port))
 	if err != nil {
 		return err
 	}
 
-	m.server = grpc.NewServer()
+	m.server = grpc.NewServer(opts...)
 
 	if m.servers.Cluster != nil {
 		api.RegisterOpenStorageClusterServer(m.server, m.servers.Cluster)
@@ -51,6 +69,12 @@ func (m *SdkServer) StartOnAddress(ip, port string) error {
 	if m.servers.Node != nil {
 		api.RegisterOpenStorageNodeServer(m.server, m.servers.Node)
 	}
+	if m.servers.Volume != nil {
+		api.RegisterOpenStorageVolumeServer(m.server, m.servers.Volume)
+	}
+	if m.servers.Alerts != nil {
+		api.RegisterOpenStorageAlertsServer(m.server, m.servers.Alerts)
+	}
 
 	reflection.Register(m.server)
 	waitForServer := make(chan bool)
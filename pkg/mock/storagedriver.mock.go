@@ -53,6 +53,36 @@ func (mr *MockDriverMockRecorder) DeleteStorage(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStorage", reflect.TypeOf((*MockDriver)(nil).DeleteStorage), arg0)
 }
 
+// GetPodsUsingStorage mocks base method
+func (m *MockDriver) GetPodsUsingStorage(arg0 *v1alpha1.StorageCluster, arg1 string) ([]v1.Pod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPodsUsingStorage", arg0, arg1)
+	ret0, _ := ret[0].([]v1.Pod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPodsUsingStorage indicates an expected call of GetPodsUsingStorage
+func (mr *MockDriverMockRecorder) GetPodsUsingStorage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodsUsingStorage", reflect.TypeOf((*MockDriver)(nil).GetPodsUsingStorage), arg0, arg1)
+}
+
+// GetPreflightPodSpec mocks base method
+func (m *MockDriver) GetPreflightPodSpec(arg0 *v1alpha1.StorageCluster, arg1 string) (v1.PodSpec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreflightPodSpec", arg0, arg1)
+	ret0, _ := ret[0].(v1.PodSpec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreflightPodSpec indicates an expected call of GetPreflightPodSpec
+func (mr *MockDriverMockRecorder) GetPreflightPodSpec(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreflightPodSpec", reflect.TypeOf((*MockDriver)(nil).GetPreflightPodSpec), arg0, arg1)
+}
+
 // GetSelectorLabels mocks base method
 func (m *MockDriver) GetSelectorLabels() map[string]string {
 	m.ctrl.T.Helper()
@@ -139,6 +169,20 @@ func (mr *MockDriverMockRecorder) PreInstall(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreInstall", reflect.TypeOf((*MockDriver)(nil).PreInstall), arg0)
 }
 
+// PreNodeUpgrade mocks base method
+func (m *MockDriver) PreNodeUpgrade(arg0 *v1alpha1.StorageCluster, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreNodeUpgrade", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PreNodeUpgrade indicates an expected call of PreNodeUpgrade
+func (mr *MockDriverMockRecorder) PreNodeUpgrade(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreNodeUpgrade", reflect.TypeOf((*MockDriver)(nil).PreNodeUpgrade), arg0, arg1)
+}
+
 // SetDefaultsOnStorageCluster mocks base method
 func (m *MockDriver) SetDefaultsOnStorageCluster(arg0 *v1alpha1.StorageCluster) {
 	m.ctrl.T.Helper()
@@ -192,3 +236,17 @@ func (mr *MockDriverMockRecorder) UpdateStorageClusterStatus(arg0 interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStorageClusterStatus", reflect.TypeOf((*MockDriver)(nil).UpdateStorageClusterStatus), arg0)
 }
+
+// ValidateRollback mocks base method
+func (m *MockDriver) ValidateRollback(arg0, arg1 *v1alpha1.StorageCluster) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateRollback", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateRollback indicates an expected call of ValidateRollback
+func (mr *MockDriverMockRecorder) ValidateRollback(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateRollback", reflect.TypeOf((*MockDriver)(nil).ValidateRollback), arg0, arg1)
+}
@@ -600,6 +600,114 @@ func TestDeleteConfigMap(t *testing.T) {
 	require.True(t, errors.IsNotFound(err))
 }
 
+func TestDeleteSecret(t *testing.T) {
+	name := "test"
+	namespace := "test-ns"
+	expected := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	k8sClient := fake.NewFakeClient(expected)
+
+	// Don't delete or throw error if the secret is not present
+	err := DeleteSecret(k8sClient, "not-present-secret", namespace)
+	require.NoError(t, err)
+
+	secret := &v1.Secret{}
+	err = testutil.Get(k8sClient, secret, name, namespace)
+	require.NoError(t, err)
+	require.Equal(t, expected, secret)
+
+	// Don't delete when there is no owner in the secret
+	// but trying to delete for specific owners
+	err = DeleteSecret(k8sClient, name, namespace, metav1.OwnerReference{UID: "foo"})
+	require.NoError(t, err)
+
+	secret = &v1.Secret{}
+	err = testutil.Get(k8sClient, secret, name, namespace)
+	require.NoError(t, err)
+	require.Equal(t, expected, secret)
+
+	// Delete when there is no owner in the secret
+	err = DeleteSecret(k8sClient, name, namespace)
+	require.NoError(t, err)
+
+	secret = &v1.Secret{}
+	err = testutil.Get(k8sClient, secret, name, namespace)
+	require.True(t, errors.IsNotFound(err))
+
+	// Don't delete when the secret is owned by an object
+	// and no owner reference passed in delete call
+	expected.OwnerReferences = []metav1.OwnerReference{{UID: "alpha"}, {UID: "beta"}, {UID: "gamma"}}
+	k8sClient.Create(context.TODO(), expected)
+
+	err = DeleteSecret(k8sClient, name, namespace)
+	require.NoError(t, err)
+
+	secret = &v1.Secret{}
+	err = testutil.Get(k8sClient, secret, name, namespace)
+	require.NoError(t, err)
+	require.Equal(t, expected, secret)
+
+	// Don't delete when the secret is owned by objects
+	// more than what are passed on delete call
+	err = DeleteSecret(k8sClient, name, namespace, metav1.OwnerReference{UID: "beta"})
+	require.NoError(t, err)
+
+	secret = &v1.Secret{}
+	err = testutil.Get(k8sClient, secret, name, namespace)
+	require.NoError(t, err)
+	require.Len(t, secret.OwnerReferences, 2)
+	require.Equal(t, types.UID("alpha"), secret.OwnerReferences[0].UID)
+	require.Equal(t, types.UID("gamma"), secret.OwnerReferences[1].UID)
+
+	// Delete when delete call passes all owners (or more) of the secret
+	err = DeleteSecret(k8sClient, name, namespace,
+		metav1.OwnerReference{UID: "theta"},
+		metav1.OwnerReference{UID: "gamma"},
+		metav1.OwnerReference{UID: "alpha"},
+	)
+	require.NoError(t, err)
+
+	secret = &v1.Secret{}
+	err = testutil.Get(k8sClient, secret, name, namespace)
+	require.True(t, errors.IsNotFound(err))
+}
+
+func TestSecretChangeData(t *testing.T) {
+	k8sClient := fake.NewFakeClient()
+	expected := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test-ns",
+		},
+		Data: map[string][]byte{
+			"key": []byte("foo"),
+		},
+	}
+
+	err := CreateOrUpdateSecret(k8sClient, expected, nil)
+	require.NoError(t, err)
+
+	actual := &v1.Secret{}
+	err = testutil.Get(k8sClient, actual, "test", "test-ns")
+	require.NoError(t, err)
+	require.Equal(t, []byte("foo"), actual.Data["key"])
+
+	// Change data
+	expected.Data["key"] = []byte("bar")
+
+	err = CreateOrUpdateSecret(k8sClient, expected, nil)
+	require.NoError(t, err)
+
+	actual = &v1.Secret{}
+	err = testutil.Get(k8sClient, actual, "test", "test-ns")
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), actual.Data["key"])
+}
+
 func TestDeleteCSIDriver(t *testing.T) {
 	name := "test"
 	expected := &storagev1beta1.CSIDriver{
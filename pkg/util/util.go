@@ -1,12 +1,21 @@
 package util
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"path"
 	"reflect"
+	"sort"
 	"strings"
 
 	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	k8sutil "github.com/libopenstorage/operator/pkg/util/k8s"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Reasons for controller events
@@ -21,6 +30,33 @@ const (
 	FailedValidationReason = "FailedValidation"
 	// FailedComponentReason is added to an event when setting up or removing a component fails.
 	FailedComponentReason = "FailedComponent"
+	// FailedNodeClaimReason is added to an event when nodes selected by a cluster are
+	// already claimed by another cluster.
+	FailedNodeClaimReason = "FailedNodeClaim"
+	// FailedRollbackReason is added to an event when the cluster could not be rolled
+	// back to a previous revision.
+	FailedRollbackReason = "FailedRollback"
+	// RollbackDoneReason is added to an event when the cluster is rolled back to a
+	// previous revision.
+	RollbackDoneReason = "RollbackDone"
+	// FailedPreflightReason is added to an event when a node fails the preflight
+	// checks and the storage pod is not created on it.
+	FailedPreflightReason = "FailedPreflight"
+	// FailedMaintenanceReason is added to an event when a node could not enter or
+	// exit maintenance mode as requested in its StorageNode.
+	FailedMaintenanceReason = "FailedMaintenance"
+	// FailedDecommissionReason is added to an event when a step of the decommission
+	// of a node fails.
+	FailedDecommissionReason = "FailedDecommission"
+	// FailedPoolExpansionReason is added to an event when the storage pools of a
+	// node could not be expanded to their requested size.
+	FailedPoolExpansionReason = "FailedPoolExpansion"
+	// StorageAlertReason is added to an event raised for an alert of the storage
+	// driver. The event is attached to the object the alert is about.
+	StorageAlertReason = "StorageAlert"
+	// KvdbQuorumAtRiskReason is added to an event when the external kvdb has
+	// lost quorum or is one failure away from losing it.
+	KvdbQuorumAtRiskReason = "KvdbQuorumAtRisk"
 )
 
 var (
@@ -63,6 +99,30 @@ func GetImageURN(registryAndRepo, image string) string {
 	return registryAndRepo + "/" + path.Join(imgParts...)
 }
 
+// SecretChecksum returns a checksum of the data in the given secrets, which
+// changes only when the contents of the secrets change
+func SecretChecksum(secrets ...*v1.Secret) string {
+	hash := sha256.New()
+	for i, secret := range secrets {
+		if i > 0 {
+			hash.Write([]byte{0})
+		}
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			hash.Write([]byte(key))
+			hash.Write([]byte{0})
+			hash.Write(secret.Data[key])
+			hash.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
 // HasPullSecretChanged checks if the imagePullSecret in the cluster is the only one
 // in the given list of pull secrets
 func HasPullSecretChanged(
@@ -101,3 +161,185 @@ func HasNodeAffinityChanged(
 	}
 	return !reflect.DeepEqual(cluster.Spec.Placement.NodeAffinity, existingAffinity.NodeAffinity)
 }
+
+// PlacementMatchesNode checks if the node is selected by the required node affinity
+// in the given placement. An empty placement selects all nodes.
+func PlacementMatchesNode(
+	placement *corev1alpha1.PlacementSpec,
+	node *v1.Node,
+) bool {
+	if placement == nil ||
+		placement.NodeAffinity == nil ||
+		placement.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	return v1helper.MatchNodeSelectorTerms(
+		placement.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms,
+		labels.Set(node.Labels),
+		fields.Set{"metadata.name": node.Name},
+	)
+}
+
+// ClusterScopedName returns the name of a cluster-scoped object, like a
+// ClusterRole, created for the StorageCluster in the given namespace. The name
+// is suffixed with the namespace, so that the objects of StorageClusters in
+// different namespaces do not collide.
+func ClusterScopedName(name, namespace string) string {
+	return name + "-" + namespace
+}
+
+// DeleteLegacyClusterRBAC deletes the ClusterRole and ClusterRoleBinding with the
+// given names, as they were created before the names of cluster-scoped objects
+// were suffixed with the namespace. Objects that are not owned by the given
+// owner, for instance those of a StorageCluster in another namespace, are kept.
+func DeleteLegacyClusterRBAC(
+	k8sClient client.Client,
+	clusterRoleName string,
+	clusterRoleBindingName string,
+	owner metav1.OwnerReference,
+) error {
+	if err := k8sutil.DeleteClusterRoleBinding(k8sClient, clusterRoleBindingName, owner); err != nil {
+		return err
+	}
+	return k8sutil.DeleteClusterRole(k8sClient, clusterRoleName, owner)
+}
+
+// SetComponentCondition sets the given component condition in the status of the
+// cluster, replacing the existing condition of the same component. Conditions
+// are kept sorted by the name of the component.
+func SetComponentCondition(
+	cluster *corev1alpha1.StorageCluster,
+	condition corev1alpha1.ComponentCondition,
+) {
+	conditions := cluster.Status.ComponentConditions
+	i := sort.Search(len(conditions), func(i int) bool {
+		return conditions[i].Name >= condition.Name
+	})
+	if i < len(conditions) && conditions[i].Name == condition.Name {
+		conditions[i] = condition
+		return
+	}
+	conditions = append(conditions, corev1alpha1.ComponentCondition{})
+	copy(conditions[i+1:], conditions[i:])
+	conditions[i] = condition
+	cluster.Status.ComponentConditions = conditions
+}
+
+// GetComponentCondition returns the condition of the given component from the
+// status of the cluster, or nil if not present
+func GetComponentCondition(
+	cluster *corev1alpha1.StorageCluster,
+	name string,
+) *corev1alpha1.ComponentCondition {
+	for i, condition := range cluster.Status.ComponentConditions {
+		if condition.Name == name {
+			return &cluster.Status.ComponentConditions[i]
+		}
+	}
+	return nil
+}
+
+// ApplyComponentConfig applies the given configuration of a component to the
+// pod spec of the component. The resources are set on the main container of the
+// component with the given name, as they are sized for it and would otherwise
+// be multiplied by the number of containers in the pod. The tolerations replace
+// the ones from the cluster placement.
+func ApplyComponentConfig(
+	podSpec *v1.PodSpec,
+	config *corev1alpha1.ComponentConfig,
+	containerName string,
+) {
+	if config == nil {
+		return
+	}
+	if config.Resources != nil {
+		for i := range podSpec.Containers {
+			if podSpec.Containers[i].Name == containerName {
+				podSpec.Containers[i].Resources = *config.Resources.DeepCopy()
+			}
+		}
+	}
+	if config.PriorityClassName != "" {
+		podSpec.PriorityClassName = config.PriorityClassName
+	}
+	if len(config.NodeSelector) > 0 {
+		podSpec.NodeSelector = make(map[string]string)
+		for k, v := range config.NodeSelector {
+			podSpec.NodeSelector[k] = v
+		}
+	}
+	if len(config.Tolerations) > 0 {
+		podSpec.Tolerations = make([]v1.Toleration, 0)
+		for _, toleration := range config.Tolerations {
+			podSpec.Tolerations = append(podSpec.Tolerations, *(toleration.DeepCopy()))
+		}
+	}
+	if config.SecurityContext != nil {
+		podSpec.SecurityContext = config.SecurityContext.DeepCopy()
+	}
+}
+
+// HasComponentConfigChanged checks if the resources, priority class, node selector,
+// tolerations or security context in the existing pod spec of a component are
+// different from the ones in the desired pod spec
+func HasComponentConfigChanged(
+	existingPodSpec *v1.PodSpec,
+	desiredPodSpec *v1.PodSpec,
+) bool {
+	if existingPodSpec.PriorityClassName != desiredPodSpec.PriorityClassName ||
+		!(len(existingPodSpec.NodeSelector) == 0 && len(desiredPodSpec.NodeSelector) == 0 ||
+			reflect.DeepEqual(existingPodSpec.NodeSelector, desiredPodSpec.NodeSelector)) ||
+		!(len(existingPodSpec.Tolerations) == 0 && len(desiredPodSpec.Tolerations) == 0 ||
+			reflect.DeepEqual(existingPodSpec.Tolerations, desiredPodSpec.Tolerations)) ||
+		!isPodSecurityContextEqual(existingPodSpec.SecurityContext, desiredPodSpec.SecurityContext) {
+		return true
+	}
+
+	existingContainers := make(map[string]*v1.Container)
+	for i, container := range existingPodSpec.Containers {
+		existingContainers[container.Name] = &existingPodSpec.Containers[i]
+	}
+	for _, container := range desiredPodSpec.Containers {
+		existingContainer, exists := existingContainers[container.Name]
+		if !exists ||
+			HaveResourcesChanged(existingContainer.Resources.Requests, container.Resources.Requests) ||
+			HaveResourcesChanged(existingContainer.Resources.Limits, container.Resources.Limits) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPodSecurityContextEqual checks if the given security contexts are the same.
+// Kubernetes defaults a missing security context to an empty one, so both are
+// considered equal.
+func isPodSecurityContextEqual(
+	existingContext *v1.PodSecurityContext,
+	desiredContext *v1.PodSecurityContext,
+) bool {
+	if existingContext == nil {
+		existingContext = &v1.PodSecurityContext{}
+	}
+	if desiredContext == nil {
+		desiredContext = &v1.PodSecurityContext{}
+	}
+	return reflect.DeepEqual(existingContext, desiredContext)
+}
+
+// HaveResourcesChanged checks if the given resource lists have different
+// quantities for any of the resources
+func HaveResourcesChanged(
+	existingResources v1.ResourceList,
+	desiredResources v1.ResourceList,
+) bool {
+	if len(existingResources) != len(desiredResources) {
+		return true
+	}
+	for name, desiredQuantity := range desiredResources {
+		existingQuantity, exists := existingResources[name]
+		if !exists || existingQuantity.Cmp(desiredQuantity) != 0 {
+			return true
+		}
+	}
+	return false
+}
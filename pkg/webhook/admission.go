@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	corev1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
+	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultWebhookConfigName is the name of the webhook configurations
+	// registered by the operator
+	DefaultWebhookConfigName = "portworx-operator"
+	validatingWebhookName    = "validate.storagecluster.core.libopenstorage.org"
+	defaultingWebhookName    = "default.storagecluster.core.libopenstorage.org"
+	webhookTimeoutSeconds    = 10
+)
+
+// registerValidatingWebhook creates or updates the validating webhook
+// configuration that sends StorageCluster create and update requests to the
+// operator. Requests are admitted if the operator is unreachable, so that
+// StorageClusters can still be edited while the operator is down.
+func registerValidatingWebhook(
+	k8sClient kubernetes.Interface,
+	cfg Config,
+	caBundle []byte,
+) error {
+	webhookConfig := &admissionv1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: DefaultWebhookConfigName,
+		},
+		Webhooks: []admissionv1beta1.Webhook{
+			storageClusterWebhook(validatingWebhookName, ValidatePath, cfg, caBundle),
+		},
+	}
+
+	client := k8sClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+	existing, err := client.Get(webhookConfig.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = client.Create(webhookConfig)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	existing.Webhooks = webhookConfig.Webhooks
+	_, err = client.Update(existing)
+	return err
+}
+
+// registerDefaultingWebhook creates or updates the mutating webhook configuration
+// that sends StorageCluster create and update requests to the operator to set
+// the defaults. Requests are admitted if the operator is unreachable, as the
+// controller also sets the defaults during reconciliation.
+func registerDefaultingWebhook(
+	k8sClient kubernetes.Interface,
+	cfg Config,
+	caBundle []byte,
+) error {
+	webhookConfig := &admissionv1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: DefaultWebhookConfigName,
+		},
+		Webhooks: []admissionv1beta1.Webhook{
+			storageClusterWebhook(defaultingWebhookName, DefaultPath, cfg, caBundle),
+		},
+	}
+
+	client := k8sClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	existing, err := client.Get(webhookConfig.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = client.Create(webhookConfig)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	existing.Webhooks = webhookConfig.Webhooks
+	_, err = client.Update(existing)
+	return err
+}
+
+func storageClusterWebhook(
+	name, path string,
+	cfg Config,
+	caBundle []byte,
+) admissionv1beta1.Webhook {
+	failurePolicy := admissionv1beta1.Ignore
+	sideEffects := admissionv1beta1.SideEffectClassNone
+	timeoutSeconds := int32(webhookTimeoutSeconds)
+	return admissionv1beta1.Webhook{
+		Name: name,
+		ClientConfig: admissionv1beta1.WebhookClientConfig{
+			Service: &admissionv1beta1.ServiceReference{
+				Namespace: cfg.ServiceNamespace,
+				Name:      cfg.ServiceName,
+				Path:      &path,
+			},
+			CABundle: caBundle,
+		},
+		Rules: []admissionv1beta1.RuleWithOperations{
+			{
+				Operations: []admissionv1beta1.OperationType{
+					admissionv1beta1.Create,
+					admissionv1beta1.Update,
+				},
+				Rule: admissionv1beta1.Rule{
+					APIGroups: []string{corev1alpha1.SchemeGroupVersion.Group},
+					APIVersions: []string{
+						corev1alpha1.SchemeGroupVersion.Version,
+						corev1beta1.SchemeGroupVersion.Version,
+					},
+					Resources: []string{corev1alpha1.StorageClusterResourcePlural},
+				},
+			},
+		},
+		FailurePolicy:           &failurePolicy,
+		SideEffects:             &sideEffects,
+		TimeoutSeconds:          &timeoutSeconds,
+		AdmissionReviewVersions: []string{"v1beta1"},
+	}
+}
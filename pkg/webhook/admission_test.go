@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"testing"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8sclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRegisterValidatingWebhook(t *testing.T) {
+	k8sClient := fakek8sclient.NewSimpleClientset()
+	cfg := Config{
+		ServiceName:      DefaultServiceName,
+		ServiceNamespace: "kube-system",
+	}
+
+	err := registerValidatingWebhook(k8sClient, cfg, []byte("ca"))
+	require.NoError(t, err)
+
+	webhookConfig, err := k8sClient.AdmissionregistrationV1beta1().
+		ValidatingWebhookConfigurations().
+		Get(DefaultWebhookConfigName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, webhookConfig.Webhooks, 1)
+	webhook := webhookConfig.Webhooks[0]
+	require.Equal(t, validatingWebhookName, webhook.Name)
+	require.Equal(t, []byte("ca"), webhook.ClientConfig.CABundle)
+	require.Equal(t, DefaultServiceName, webhook.ClientConfig.Service.Name)
+	require.Equal(t, "kube-system", webhook.ClientConfig.Service.Namespace)
+	require.Equal(t, ValidatePath, *webhook.ClientConfig.Service.Path)
+	require.Equal(t, admissionv1beta1.Ignore, *webhook.FailurePolicy)
+	require.Len(t, webhook.Rules, 1)
+	require.ElementsMatch(t,
+		[]admissionv1beta1.OperationType{admissionv1beta1.Create, admissionv1beta1.Update},
+		webhook.Rules[0].Operations)
+	require.Equal(t, []string{corev1alpha1.SchemeGroupVersion.Group}, webhook.Rules[0].APIGroups)
+	require.Equal(t, []string{"v1alpha1", "v1beta1"}, webhook.Rules[0].APIVersions)
+	require.Equal(t, []string{corev1alpha1.StorageClusterResourcePlural}, webhook.Rules[0].Resources)
+
+	// The existing configuration should be updated with the new CA bundle
+	err = registerValidatingWebhook(k8sClient, cfg, []byte("new-ca"))
+	require.NoError(t, err)
+
+	webhookConfig, err = k8sClient.AdmissionregistrationV1beta1().
+		ValidatingWebhookConfigurations().
+		Get(DefaultWebhookConfigName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, webhookConfig.Webhooks, 1)
+	require.Equal(t, []byte("new-ca"), webhookConfig.Webhooks[0].ClientConfig.CABundle)
+}
+
+func TestRegisterDefaultingWebhook(t *testing.T) {
+	k8sClient := fakek8sclient.NewSimpleClientset()
+	cfg := Config{
+		ServiceName:      DefaultServiceName,
+		ServiceNamespace: "kube-system",
+	}
+
+	err := registerDefaultingWebhook(k8sClient, cfg, []byte("ca"))
+	require.NoError(t, err)
+
+	webhookConfig, err := k8sClient.AdmissionregistrationV1beta1().
+		MutatingWebhookConfigurations().
+		Get(DefaultWebhookConfigName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, webhookConfig.Webhooks, 1)
+	webhook := webhookConfig.Webhooks[0]
+	require.Equal(t, defaultingWebhookName, webhook.Name)
+	require.Equal(t, []byte("ca"), webhook.ClientConfig.CABundle)
+	require.Equal(t, DefaultPath, *webhook.ClientConfig.Service.Path)
+	require.Equal(t, admissionv1beta1.Ignore, *webhook.FailurePolicy)
+	require.Len(t, webhook.Rules, 1)
+	require.Equal(t, []string{corev1alpha1.StorageClusterResourcePlural}, webhook.Rules[0].Resources)
+
+	// The existing configuration should be updated with the new CA bundle
+	err = registerDefaultingWebhook(k8sClient, cfg, []byte("new-ca"))
+	require.NoError(t, err)
+
+	webhookConfig, err = k8sClient.AdmissionregistrationV1beta1().
+		MutatingWebhookConfigurations().
+		Get(DefaultWebhookConfigName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, webhookConfig.Webhooks, 1)
+	require.Equal(t, []byte("new-ca"), webhookConfig.Webhooks[0].ClientConfig.CABundle)
+}
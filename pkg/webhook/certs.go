@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	coreops "github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+const (
+	certFileName   = v1.TLSCertKey
+	keyFileName    = v1.TLSPrivateKeyKey
+	caCertFileName = "ca.crt"
+	// certRenewBefore is how long before expiry the certificates are regenerated
+	certRenewBefore = 30 * 24 * time.Hour
+)
+
+// ensureCertificates makes sure valid serving certificates for the webhook
+// service exist in the certificate secret and in the cert directory. It
+// returns the PEM encoded CA bundle which the API server should trust.
+func ensureCertificates(cfg Config) ([]byte, error) {
+	secret, err := coreops.Instance().GetSecret(cfg.CertSecretName, cfg.ServiceNamespace)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if errors.IsNotFound(err) {
+		secret, err = newCertificateSecret(cfg)
+		if err != nil {
+			return nil, err
+		}
+		secret, err = coreops.Instance().CreateSecret(secret)
+		if errors.IsAlreadyExists(err) {
+			// Another replica of the operator created the secret first
+			secret, err = coreops.Instance().GetSecret(cfg.CertSecretName, cfg.ServiceNamespace)
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else if !certificatesValid(secret, serviceHost(cfg)) {
+		logrus.Infof("Regenerating webhook certificates in secret %s/%s",
+			secret.Namespace, secret.Name)
+		newSecret, err := newCertificateSecret(cfg)
+		if err != nil {
+			return nil, err
+		}
+		secret.Data = newSecret.Data
+		secret, err = coreops.Instance().UpdateSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeCertificates(cfg.CertDir, secret); err != nil {
+		return nil, err
+	}
+	return secret.Data[caCertFileName], nil
+}
+
+func newCertificateSecret(cfg Config) (*v1.Secret, error) {
+	host := serviceHost(cfg)
+	alternateDNS := []string{
+		cfg.ServiceName,
+		fmt.Sprintf("%s.%s", cfg.ServiceName, cfg.ServiceNamespace),
+		fmt.Sprintf("%s.cluster.local", host),
+	}
+	certPEM, keyPEM, err := certutil.GenerateSelfSignedCertKey(host, nil, alternateDNS)
+	if err != nil {
+		return nil, err
+	}
+
+	// The generated certificate is followed by the CA that signed it
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, err
+	} else if len(certs) < 2 {
+		return nil, fmt.Errorf("generated certificate does not contain the CA certificate")
+	}
+	caPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  certutil.CertificateBlockType,
+		Bytes: certs[len(certs)-1].Raw,
+	})
+
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cfg.CertSecretName,
+			Namespace: cfg.ServiceNamespace,
+		},
+		Type: v1.SecretTypeTLS,
+		Data: map[string][]byte{
+			certFileName:   certPEM,
+			keyFileName:    keyPEM,
+			caCertFileName: caPEM,
+		},
+	}, nil
+}
+
+// certificatesValid checks if the certificates in the given secret can be used
+// to serve the given host and are not about to expire.
+func certificatesValid(secret *v1.Secret, host string) bool {
+	if len(secret.Data[keyFileName]) == 0 || len(secret.Data[caCertFileName]) == 0 {
+		return false
+	}
+	certs, err := certutil.ParseCertsPEM(secret.Data[certFileName])
+	if err != nil || len(certs) == 0 {
+		return false
+	}
+	if time.Now().Add(certRenewBefore).After(certs[0].NotAfter) {
+		return false
+	}
+	if err := certs[0].VerifyHostname(host); err != nil {
+		return false
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(secret.Data[caCertFileName]) {
+		return false
+	}
+	_, err = certs[0].Verify(x509.VerifyOptions{
+		DNSName:   host,
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	return err == nil
+}
+
+func writeCertificates(dir string, secret *v1.Secret) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	for _, name := range []string{certFileName, keyFileName} {
+		filename := path.Join(dir, name)
+		existing, err := ioutil.ReadFile(filename)
+		if err == nil && bytes.Equal(existing, secret.Data[name]) {
+			continue
+		}
+		if err := ioutil.WriteFile(filename, secret.Data[name], 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func serviceHost(cfg Config) string {
+	return fmt.Sprintf("%s.%s.svc", cfg.ServiceName, cfg.ServiceNamespace)
+}
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	coreops "github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	fakek8sclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnsureCertificates(t *testing.T) {
+	coreops.SetInstance(coreops.New(fakek8sclient.NewSimpleClientset()))
+	certDir, err := ioutil.TempDir("", "webhook-certs")
+	require.NoError(t, err)
+	defer os.RemoveAll(certDir)
+
+	cfg := Config{
+		ServiceName:      DefaultServiceName,
+		ServiceNamespace: "kube-system",
+		CertDir:          certDir,
+		CertSecretName:   DefaultCertSecretName,
+	}
+
+	// Certificates should be generated and stored in the secret and cert dir
+	caBundle, err := ensureCertificates(cfg)
+	require.NoError(t, err)
+	require.NotEmpty(t, caBundle)
+
+	secret, err := coreops.Instance().GetSecret(DefaultCertSecretName, "kube-system")
+	require.NoError(t, err)
+	require.Equal(t, caBundle, secret.Data[caCertFileName])
+	require.True(t, certificatesValid(secret, serviceHost(cfg)))
+
+	certData, err := ioutil.ReadFile(path.Join(certDir, certFileName))
+	require.NoError(t, err)
+	require.Equal(t, secret.Data[certFileName], certData)
+	keyData, err := ioutil.ReadFile(path.Join(certDir, keyFileName))
+	require.NoError(t, err)
+	require.Equal(t, secret.Data[keyFileName], keyData)
+
+	// Existing valid certificates should be reused
+	newCABundle, err := ensureCertificates(cfg)
+	require.NoError(t, err)
+	require.Equal(t, caBundle, newCABundle)
+
+	// Certificates should be regenerated if they are not valid for the service
+	cfg.ServiceName = "new-service"
+	require.False(t, certificatesValid(secret, serviceHost(cfg)))
+
+	newCABundle, err = ensureCertificates(cfg)
+	require.NoError(t, err)
+	require.NotEqual(t, caBundle, newCABundle)
+
+	secret, err = coreops.Instance().GetSecret(DefaultCertSecretName, "kube-system")
+	require.NoError(t, err)
+	require.True(t, certificatesValid(secret, serviceHost(cfg)))
+
+	// Certificates should be regenerated if they are corrupted
+	secret.Data[certFileName] = []byte("invalid")
+	secret, err = coreops.Instance().UpdateSecret(secret)
+	require.NoError(t, err)
+	require.False(t, certificatesValid(secret, serviceHost(cfg)))
+
+	_, err = ensureCertificates(cfg)
+	require.NoError(t, err)
+
+	secret, err = coreops.Instance().GetSecret(DefaultCertSecretName, "kube-system")
+	require.NoError(t, err)
+	require.True(t, certificatesValid(secret, serviceHost(cfg)))
+}
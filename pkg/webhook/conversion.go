@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/libopenstorage/operator/pkg/apis"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	corev1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
+	"github.com/sirupsen/logrus"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// ConversionPath is the path on which the conversion webhook is served
+	ConversionPath = "/convert"
+)
+
+type conversionHandler struct {
+	scheme *runtime.Scheme
+}
+
+// NewConversionHandler returns an http handler that converts StorageCluster
+// and StorageNode objects between the served API versions. It accepts and
+// responds with apiextensions.k8s.io/v1beta1 ConversionReview objects.
+func NewConversionHandler() http.Handler {
+	scheme := runtime.NewScheme()
+	if err := apis.AddToScheme(scheme); err != nil {
+		// The scheme only contains our own types, so this should never happen
+		panic(err)
+	}
+	return &conversionHandler{scheme: scheme}
+}
+
+func (h *conversionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := &apiextensionsv1beta1.ConversionReview{}
+	if err := json.Unmarshal(body, review); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode conversion review: %v", err),
+			http.StatusBadRequest)
+		return
+	} else if review.Request == nil {
+		http.Error(w, "conversion review has no request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = h.convert(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		logrus.Warnf("Failed to write conversion response: %v", err)
+	}
+}
+
+func (h *conversionHandler) convert(
+	req *apiextensionsv1beta1.ConversionRequest,
+) *apiextensionsv1beta1.ConversionResponse {
+	resp := &apiextensionsv1beta1.ConversionResponse{
+		UID: req.UID,
+	}
+
+	desiredGV, err := schema.ParseGroupVersion(req.DesiredAPIVersion)
+	if err != nil {
+		resp.Result = failureStatus(err)
+		return resp
+	}
+
+	for _, raw := range req.Objects {
+		converted, err := h.convertObject(raw.Raw, desiredGV)
+		if err != nil {
+			resp.ConvertedObjects = nil
+			resp.Result = failureStatus(err)
+			return resp
+		}
+		convertedRaw, err := json.Marshal(converted)
+		if err != nil {
+			resp.ConvertedObjects = nil
+			resp.Result = failureStatus(err)
+			return resp
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: convertedRaw})
+	}
+
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+func (h *conversionHandler) convertObject(
+	raw []byte,
+	desiredGV schema.GroupVersion,
+) (runtime.Object, error) {
+	typeMeta := &metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, typeMeta); err != nil {
+		return nil, err
+	}
+	gvk := typeMeta.GroupVersionKind()
+
+	src, err := h.scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, src); err != nil {
+		return nil, err
+	}
+	if gvk.GroupVersion() == desiredGV {
+		return src, nil
+	}
+
+	switch obj := src.(type) {
+	case *corev1alpha1.StorageCluster, *corev1beta1.StorageCluster:
+		return ConvertStorageCluster(obj, desiredGV)
+	case *corev1alpha1.StorageNode, *corev1beta1.StorageNode:
+		return ConvertStorageNode(obj, desiredGV)
+	}
+	return nil, fmt.Errorf("conversion of %v is not supported", gvk)
+}
+
+// ConvertStorageCluster converts the given StorageCluster object to the desired
+// version. All conversions go through the v1alpha1 hub version.
+func ConvertStorageCluster(
+	src runtime.Object,
+	desiredGV schema.GroupVersion,
+) (runtime.Object, error) {
+	hub := &corev1alpha1.StorageCluster{}
+	switch obj := src.(type) {
+	case *corev1alpha1.StorageCluster:
+		hub = obj.DeepCopy()
+	case *corev1beta1.StorageCluster:
+		if err := obj.ConvertTo(hub); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported StorageCluster type %T", src)
+	}
+
+	switch desiredGV {
+	case corev1alpha1.SchemeGroupVersion:
+		hub.APIVersion = desiredGV.String()
+		return hub, nil
+	case corev1beta1.SchemeGroupVersion:
+		dst := &corev1beta1.StorageCluster{}
+		if err := dst.ConvertFrom(hub); err != nil {
+			return nil, err
+		}
+		return dst, nil
+	}
+	return nil, fmt.Errorf("unsupported StorageCluster version %v", desiredGV)
+}
+
+// ConvertStorageNode converts the given StorageNode object to the desired
+// version. All conversions go through the v1alpha1 hub version.
+func ConvertStorageNode(
+	src runtime.Object,
+	desiredGV schema.GroupVersion,
+) (runtime.Object, error) {
+	hub := &corev1alpha1.StorageNode{}
+	switch obj := src.(type) {
+	case *corev1alpha1.StorageNode:
+		hub = obj.DeepCopy()
+	case *corev1beta1.StorageNode:
+		if err := obj.ConvertTo(hub); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported StorageNode type %T", src)
+	}
+
+	switch desiredGV {
+	case corev1alpha1.SchemeGroupVersion:
+		hub.APIVersion = desiredGV.String()
+		return hub, nil
+	case corev1beta1.SchemeGroupVersion:
+		dst := &corev1beta1.StorageNode{}
+		if err := dst.ConvertFrom(hub); err != nil {
+			return nil, err
+		}
+		return dst, nil
+	}
+	return nil, fmt.Errorf("unsupported StorageNode version %v", desiredGV)
+}
+
+func failureStatus(err error) metav1.Status {
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Message: err.Error(),
+	}
+}
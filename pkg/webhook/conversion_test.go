@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	corev1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestStorageClusterConversionRoundTrip(t *testing.T) {
+	cluster := &corev1alpha1.StorageCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "StorageCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-system",
+			Annotations: map[string]string{
+				corev1beta1.AnnotationDisableStorage: "true",
+				corev1beta1.AnnotationServiceType:    string(v1.ServiceTypeLoadBalancer),
+				corev1beta1.AnnotationMiscArgs:       "-a -b",
+				corev1beta1.AnnotationLogFile:        "/var/log/px.log",
+				"other":                              "annotation",
+			},
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "portworx/oci-monitor:2.5.0",
+			Kvdb: &corev1alpha1.KvdbSpec{
+				Internal: true,
+			},
+		},
+		Status: corev1alpha1.StorageClusterStatus{
+			ClusterName: "px-cluster",
+			Phase:       "Online",
+		},
+	}
+
+	obj, err := ConvertStorageCluster(cluster, corev1beta1.SchemeGroupVersion)
+	require.NoError(t, err)
+	betaCluster := obj.(*corev1beta1.StorageCluster)
+	require.Equal(t, corev1beta1.SchemeGroupVersion.String(), betaCluster.APIVersion)
+	require.Equal(t, "StorageCluster", betaCluster.Kind)
+	require.True(t, betaCluster.Spec.DisableStorage)
+	require.Equal(t, v1.ServiceTypeLoadBalancer, betaCluster.Spec.ServiceType)
+	require.Equal(t, "-a -b", betaCluster.Spec.MiscArgs)
+	require.Equal(t, "/var/log/px.log", betaCluster.Spec.LogFile)
+	require.Equal(t, map[string]string{"other": "annotation"}, betaCluster.Annotations)
+	require.Equal(t, cluster.Spec.Image, betaCluster.Spec.Image)
+	require.True(t, betaCluster.Spec.Kvdb.Internal)
+	require.Equal(t, cluster.Status.ClusterName, betaCluster.Status.ClusterName)
+	require.Equal(t, cluster.Status.Phase, betaCluster.Status.Phase)
+
+	obj, err = ConvertStorageCluster(betaCluster, corev1alpha1.SchemeGroupVersion)
+	require.NoError(t, err)
+	require.Equal(t, cluster, obj)
+
+	// Annotations that cannot be restored exactly should not be moved to fields
+	cluster.Annotations = map[string]string{
+		corev1beta1.AnnotationDisableStorage: "false",
+		corev1beta1.AnnotationMiscArgs:       "",
+	}
+
+	obj, err = ConvertStorageCluster(cluster, corev1beta1.SchemeGroupVersion)
+	require.NoError(t, err)
+	betaCluster = obj.(*corev1beta1.StorageCluster)
+	require.False(t, betaCluster.Spec.DisableStorage)
+	require.Empty(t, betaCluster.Spec.MiscArgs)
+	require.Equal(t, cluster.Annotations, betaCluster.Annotations)
+
+	obj, err = ConvertStorageCluster(betaCluster, corev1alpha1.SchemeGroupVersion)
+	require.NoError(t, err)
+	require.Equal(t, cluster, obj)
+}
+
+func TestStorageNodeConversionRoundTrip(t *testing.T) {
+	node := &corev1alpha1.StorageNode{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "StorageNode",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node1",
+			Namespace: "kube-system",
+		},
+		Spec: corev1alpha1.StorageNodeSpec{
+			Version: "2.5.0",
+		},
+		Status: corev1alpha1.NodeStatus{
+			NodeUID: "node-uid",
+			Phase:   "Online",
+		},
+	}
+
+	obj, err := ConvertStorageNode(node, corev1beta1.SchemeGroupVersion)
+	require.NoError(t, err)
+	betaNode := obj.(*corev1beta1.StorageNode)
+	require.Equal(t, corev1beta1.SchemeGroupVersion.String(), betaNode.APIVersion)
+	require.Equal(t, node.Spec.Version, betaNode.Spec.Version)
+	require.Equal(t, node.Status.NodeUID, betaNode.Status.NodeUID)
+	require.Equal(t, node.Status.Phase, betaNode.Status.Phase)
+
+	obj, err = ConvertStorageNode(betaNode, corev1alpha1.SchemeGroupVersion)
+	require.NoError(t, err)
+	require.Equal(t, node, obj)
+}
+
+func TestConversionHandler(t *testing.T) {
+	cluster := &corev1alpha1.StorageCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "StorageCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-system",
+			Annotations: map[string]string{
+				corev1beta1.AnnotationServiceType: string(v1.ServiceTypeNodePort),
+			},
+		},
+	}
+	node := &corev1alpha1.StorageNode{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "StorageNode",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node1",
+			Namespace: "kube-system",
+		},
+	}
+	clusterRaw, err := json.Marshal(cluster)
+	require.NoError(t, err)
+	nodeRaw, err := json.Marshal(node)
+	require.NoError(t, err)
+
+	review := &apiextensionsv1beta1.ConversionReview{
+		Request: &apiextensionsv1beta1.ConversionRequest{
+			UID:               types.UID("review-uid"),
+			DesiredAPIVersion: corev1beta1.SchemeGroupVersion.String(),
+			Objects: []runtime.RawExtension{
+				{Raw: clusterRaw},
+				{Raw: nodeRaw},
+			},
+		},
+	}
+	response := sendConversionReview(t, review)
+	require.Equal(t, types.UID("review-uid"), response.UID)
+	require.Equal(t, metav1.StatusSuccess, response.Result.Status)
+	require.Len(t, response.ConvertedObjects, 2)
+
+	betaCluster := &corev1beta1.StorageCluster{}
+	err = json.Unmarshal(response.ConvertedObjects[0].Raw, betaCluster)
+	require.NoError(t, err)
+	require.Equal(t, corev1beta1.SchemeGroupVersion.String(), betaCluster.APIVersion)
+	require.Equal(t, "StorageCluster", betaCluster.Kind)
+	require.Equal(t, v1.ServiceTypeNodePort, betaCluster.Spec.ServiceType)
+	require.Empty(t, betaCluster.Annotations)
+
+	betaNode := &corev1beta1.StorageNode{}
+	err = json.Unmarshal(response.ConvertedObjects[1].Raw, betaNode)
+	require.NoError(t, err)
+	require.Equal(t, corev1beta1.SchemeGroupVersion.String(), betaNode.APIVersion)
+	require.Equal(t, "StorageNode", betaNode.Kind)
+
+	// Unknown objects should fail the whole conversion
+	review.Request.Objects = append(review.Request.Objects, runtime.RawExtension{
+		Raw: []byte(`{"apiVersion":"v1","kind":"Pod"}`),
+	})
+	response = sendConversionReview(t, review)
+	require.Equal(t, metav1.StatusFailure, response.Result.Status)
+	require.NotEmpty(t, response.Result.Message)
+	require.Empty(t, response.ConvertedObjects)
+
+	// Invalid desired version should fail the conversion
+	review.Request.Objects = review.Request.Objects[:1]
+	review.Request.DesiredAPIVersion = "core.libopenstorage.org/v2"
+	response = sendConversionReview(t, review)
+	require.Equal(t, metav1.StatusFailure, response.Result.Status)
+
+	// Requests without a conversion request should be rejected
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, ConversionPath, bytes.NewBufferString("{}"))
+	NewConversionHandler().ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func sendConversionReview(
+	t *testing.T,
+	review *apiextensionsv1beta1.ConversionReview,
+) *apiextensionsv1beta1.ConversionResponse {
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, ConversionPath, bytes.NewBuffer(body))
+	NewConversionHandler().ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	result := &apiextensionsv1beta1.ConversionReview{}
+	err = json.Unmarshal(recorder.Body.Bytes(), result)
+	require.NoError(t, err)
+	require.Nil(t, result.Request)
+	require.NotNil(t, result.Response)
+	return result.Response
+}
@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	corev1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+type crdClient struct {
+	client apiextensionsclient.Interface
+}
+
+func newCRDClient(config *rest.Config) (*crdClient, error) {
+	client, err := apiextensionsclient.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &crdClient{client: client}, nil
+}
+
+// enableConversion configures the StorageCluster and StorageNode CRDs to use
+// the conversion webhook and starts serving the v1beta1 version.
+func (c *crdClient) enableConversion(cfg Config, caBundle []byte) error {
+	crdNames := []string{
+		fmt.Sprintf("%s.%s", corev1alpha1.StorageClusterResourcePlural, corev1alpha1.SchemeGroupVersion.Group),
+		fmt.Sprintf("%s.%s", corev1alpha1.StorageNodeResourcePlural, corev1alpha1.SchemeGroupVersion.Group),
+	}
+	for _, name := range crdNames {
+		if err := c.enableConversionForCRD(name, cfg, caBundle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *crdClient) enableConversionForCRD(name string, cfg Config, caBundle []byte) error {
+	crd, err := c.client.ApiextensionsV1beta1().CustomResourceDefinitions().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	versions := make([]apiextensionsv1beta1.CustomResourceDefinitionVersion, 0, len(crd.Spec.Versions))
+	for _, version := range crd.Spec.Versions {
+		if version.Name == corev1beta1.SchemeGroupVersion.Version {
+			version.Served = true
+		}
+		versions = append(versions, version)
+	}
+
+	path := ConversionPath
+	conversion := &apiextensionsv1beta1.CustomResourceConversion{
+		Strategy: apiextensionsv1beta1.WebhookConverter,
+		WebhookClientConfig: &apiextensionsv1beta1.WebhookClientConfig{
+			Service: &apiextensionsv1beta1.ServiceReference{
+				Namespace: cfg.ServiceNamespace,
+				Name:      cfg.ServiceName,
+				Path:      &path,
+			},
+			CABundle: caBundle,
+		},
+		ConversionReviewVersions: []string{"v1beta1"},
+	}
+
+	// Newer API servers reject webhook conversion unless unknown fields are
+	// pruned. The schema is not exhaustive, so the spec and status of the
+	// objects are marked to preserve unknown fields; objects are not modified.
+	// The vendored CRD types do not have these fields, hence the raw patch.
+	preserveUnknownFields := map[string]interface{}{
+		"type":                                 "object",
+		"x-kubernetes-preserve-unknown-fields": true,
+	}
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"preserveUnknownFields": false,
+			"versions":              versions,
+			"conversion":            conversion,
+			"validation": map[string]interface{}{
+				"openAPIV3Schema": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"spec":   preserveUnknownFields,
+						"status": preserveUnknownFields,
+					},
+				},
+			},
+		},
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.ApiextensionsV1beta1().CustomResourceDefinitions().Patch(
+		name, types.MergePatchType, patchBytes)
+	return err
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"testing"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	corev1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	fakeextclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEnableConversion(t *testing.T) {
+	group := corev1alpha1.SchemeGroupVersion.Group
+	crdNames := []string{
+		corev1alpha1.StorageClusterResourcePlural + "." + group,
+		corev1alpha1.StorageNodeResourcePlural + "." + group,
+	}
+	fakeExtClient := fakeextclient.NewSimpleClientset()
+	client := &crdClient{client: fakeExtClient}
+	cfg := Config{
+		ServiceName:      DefaultServiceName,
+		ServiceNamespace: "kube-system",
+	}
+
+	// Should fail if the CRDs are not present
+	err := client.enableConversion(cfg, []byte("ca"))
+	require.Error(t, err)
+
+	for _, name := range crdNames {
+		_, err := fakeExtClient.ApiextensionsV1beta1().CustomResourceDefinitions().Create(
+			&apiextensionsv1beta1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+				},
+				Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+					Group: group,
+					Versions: []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+						{
+							Name:    corev1alpha1.SchemeGroupVersion.Version,
+							Served:  true,
+							Storage: true,
+						},
+						{
+							Name:    corev1beta1.SchemeGroupVersion.Version,
+							Served:  false,
+							Storage: false,
+						},
+					},
+				},
+			},
+		)
+		require.NoError(t, err)
+	}
+
+	err = client.enableConversion(cfg, []byte("ca"))
+	require.NoError(t, err)
+
+	for _, name := range crdNames {
+		crd, err := fakeExtClient.ApiextensionsV1beta1().CustomResourceDefinitions().
+			Get(name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Len(t, crd.Spec.Versions, 2)
+		require.True(t, crd.Spec.Versions[0].Served)
+		require.True(t, crd.Spec.Versions[0].Storage)
+		require.True(t, crd.Spec.Versions[1].Served)
+		require.False(t, crd.Spec.Versions[1].Storage)
+
+		require.NotNil(t, crd.Spec.Conversion)
+		require.Equal(t, apiextensionsv1beta1.WebhookConverter, crd.Spec.Conversion.Strategy)
+		clientConfig := crd.Spec.Conversion.WebhookClientConfig
+		require.Equal(t, []byte("ca"), clientConfig.CABundle)
+		require.Equal(t, DefaultServiceName, clientConfig.Service.Name)
+		require.Equal(t, "kube-system", clientConfig.Service.Namespace)
+		require.Equal(t, ConversionPath, *clientConfig.Service.Path)
+	}
+}
@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/libopenstorage/operator/drivers/storage"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	corev1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
+	"github.com/libopenstorage/operator/pkg/controller/storagecluster"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// DefaultPath is the path on which the StorageCluster defaulting webhook is served
+	DefaultPath = "/mutate-storagecluster"
+)
+
+type storageClusterDefaulter struct {
+	driver storage.Driver
+}
+
+// NewDefaultingHandler returns an admission handler that sets the default values
+// on StorageCluster objects on create and update, including the defaults from
+// the given storage driver. This persists the defaults before the object is
+// stored, instead of the controller updating the object after reconciling it.
+func NewDefaultingHandler(driver storage.Driver) admission.Handler {
+	return &storageClusterDefaulter{driver: driver}
+}
+
+func (d *storageClusterDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1beta1.Create && req.Operation != admissionv1beta1.Update {
+		return admission.Allowed("")
+	}
+
+	cluster, err := decodeStorageCluster(req.Kind.Version, req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if cluster.DeletionTimestamp != nil {
+		return admission.Allowed("")
+	}
+
+	storagecluster.SetDefaults(cluster, d.driver)
+
+	defaulted, err := encodeStorageCluster(req.Kind.Version, cluster)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, defaulted)
+}
+
+// encodeStorageCluster converts the given v1alpha1 StorageCluster to the given
+// API version and returns its json representation.
+func encodeStorageCluster(
+	apiVersion string,
+	cluster *corev1alpha1.StorageCluster,
+) ([]byte, error) {
+	var obj runtime.Object
+	switch apiVersion {
+	case corev1alpha1.SchemeGroupVersion.Version:
+		obj = cluster
+	case corev1beta1.SchemeGroupVersion.Version:
+		betaCluster := &corev1beta1.StorageCluster{}
+		if err := betaCluster.ConvertFrom(cluster); err != nil {
+			return nil, err
+		}
+		obj = betaCluster
+	default:
+		return nil, fmt.Errorf("unsupported StorageCluster version %s", apiVersion)
+	}
+	return json.Marshal(obj)
+}
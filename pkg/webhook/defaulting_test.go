@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/golang/mock/gomock"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	corev1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
+	testutil "github.com/libopenstorage/operator/pkg/util/test"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestDefaultingHandler(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	driver := testutil.MockDriver(mockCtrl)
+	handler := NewDefaultingHandler(driver)
+
+	driver.EXPECT().
+		SetDefaultsOnStorageCluster(gomock.Any()).
+		Do(func(cluster *corev1alpha1.StorageCluster) {
+			cluster.Spec.Image = "portworx/oci-monitor:2.5.0"
+			cluster.Spec.Stork = &corev1alpha1.StorkSpec{
+				Enabled: true,
+				Image:   "openstorage/stork:2.4.0",
+			}
+		}).
+		AnyTimes()
+
+	cluster := &corev1alpha1.StorageCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "StorageCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-system",
+		},
+	}
+
+	req := admissionRequest(t, admissionv1beta1.Create, corev1alpha1.SchemeGroupVersion.Version, cluster, nil)
+	resp := handler.Handle(context.TODO(), req)
+	require.True(t, resp.Allowed)
+	require.NotEmpty(t, resp.Patches)
+
+	defaultedCluster := &corev1alpha1.StorageCluster{}
+	applyPatches(t, req.Object.Raw, resp, defaultedCluster)
+	maxUnavailable := intstr.FromInt(1)
+	require.Equal(t, corev1alpha1.RollingUpdateStorageClusterStrategyType, defaultedCluster.Spec.UpdateStrategy.Type)
+	require.Equal(t, &maxUnavailable, defaultedCluster.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable)
+	require.Equal(t, int32(10), *defaultedCluster.Spec.RevisionHistoryLimit)
+	require.Equal(t, v1.PullAlways, defaultedCluster.Spec.ImagePullPolicy)
+	require.Equal(t, "portworx/oci-monitor:2.5.0", defaultedCluster.Spec.Image)
+	require.Equal(t, "openstorage/stork:2.4.0", defaultedCluster.Spec.Stork.Image)
+	require.Empty(t, defaultedCluster.Finalizers)
+
+	// User provided values should not be overwritten
+	cluster.Spec.UpdateStrategy = corev1alpha1.StorageClusterUpdateStrategy{
+		Type: corev1alpha1.OnDeleteStorageClusterStrategyType,
+	}
+	cluster.Spec.ImagePullPolicy = v1.PullIfNotPresent
+	req = admissionRequest(t, admissionv1beta1.Update, corev1alpha1.SchemeGroupVersion.Version, cluster, cluster)
+	resp = handler.Handle(context.TODO(), req)
+	require.True(t, resp.Allowed)
+
+	defaultedCluster = &corev1alpha1.StorageCluster{}
+	applyPatches(t, req.Object.Raw, resp, defaultedCluster)
+	require.Equal(t, corev1alpha1.OnDeleteStorageClusterStrategyType, defaultedCluster.Spec.UpdateStrategy.Type)
+	require.Nil(t, defaultedCluster.Spec.UpdateStrategy.RollingUpdate)
+	require.Equal(t, v1.PullIfNotPresent, defaultedCluster.Spec.ImagePullPolicy)
+
+	// v1beta1 objects should be defaulted and returned in the same version
+	betaCluster := &corev1beta1.StorageCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1beta1.SchemeGroupVersion.String(),
+			Kind:       "StorageCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "kube-system",
+		},
+		Spec: corev1beta1.StorageClusterSpec{
+			MiscArgs: "-a -b",
+		},
+	}
+	req = admissionRequest(t, admissionv1beta1.Create, corev1beta1.SchemeGroupVersion.Version, betaCluster, nil)
+	resp = handler.Handle(context.TODO(), req)
+	require.True(t, resp.Allowed)
+
+	defaultedBetaCluster := &corev1beta1.StorageCluster{}
+	applyPatches(t, req.Object.Raw, resp, defaultedBetaCluster)
+	require.Equal(t, corev1beta1.SchemeGroupVersion.String(), defaultedBetaCluster.APIVersion)
+	require.Equal(t, "-a -b", defaultedBetaCluster.Spec.MiscArgs)
+	require.Empty(t, defaultedBetaCluster.Annotations)
+	require.Equal(t, int32(10), *defaultedBetaCluster.Spec.RevisionHistoryLimit)
+	require.Equal(t, "portworx/oci-monitor:2.5.0", defaultedBetaCluster.Spec.Image)
+
+	// Clusters being deleted should not be defaulted
+	deletionTimestamp := metav1.Now()
+	cluster.DeletionTimestamp = &deletionTimestamp
+	req = admissionRequest(t, admissionv1beta1.Update, corev1alpha1.SchemeGroupVersion.Version, cluster, cluster)
+	resp = handler.Handle(context.TODO(), req)
+	require.True(t, resp.Allowed)
+	require.Empty(t, resp.Patches)
+}
+
+func applyPatches(
+	t *testing.T,
+	original []byte,
+	resp admission.Response,
+	into interface{},
+) {
+	patchBytes, err := json.Marshal(resp.Patches)
+	require.NoError(t, err)
+	patch, err := jsonpatch.DecodePatch(patchBytes)
+	require.NoError(t, err)
+	patched, err := patch.Apply(original)
+	require.NoError(t, err)
+	err = json.Unmarshal(patched, into)
+	require.NoError(t, err)
+}
@@ -0,0 +1,478 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	corev1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
+	"github.com/libopenstorage/operator/pkg/util"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// ValidatePath is the path on which the StorageCluster validating webhook is served
+	ValidatePath = "/validate-storagecluster"
+)
+
+var percentRegex = regexp.MustCompile(`^([0-9]+)%$`)
+
+type storageClusterValidator struct {
+	client client.Client
+}
+
+// NewValidatingHandler returns an admission handler that validates StorageCluster
+// objects on create and update. The given client is used to look up other
+// StorageClusters and the Kubernetes nodes.
+func NewValidatingHandler(k8sClient client.Client) admission.Handler {
+	return &storageClusterValidator{client: k8sClient}
+}
+
+func (v *storageClusterValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1beta1.Create && req.Operation != admissionv1beta1.Update {
+		return admission.Allowed("")
+	}
+
+	cluster, err := decodeStorageCluster(req.Kind.Version, req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var oldCluster *corev1alpha1.StorageCluster
+	if req.Operation == admissionv1beta1.Update {
+		oldCluster, err = decodeStorageCluster(req.Kind.Version, req.OldObject)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// Do not block updates that do not change the spec, like finalizer
+		// removal, or updates on a cluster that is being deleted
+		if cluster.DeletionTimestamp != nil || reflect.DeepEqual(cluster.Spec, oldCluster.Spec) {
+			return admission.Allowed("")
+		}
+	}
+
+	nodeList := &v1.NodeList{}
+	if err := v.client.List(ctx, nodeList, &client.ListOptions{}); err != nil {
+		return admission.Errored(http.StatusInternalServerError,
+			fmt.Errorf("failed to list nodes: %v", err))
+	}
+
+	errList := ValidateStorageCluster(cluster, oldCluster, nodeList.Items)
+	// Only check the node claims when the placement changes, so that existing
+	// overlapping clusters can still be updated
+	if oldCluster == nil || !reflect.DeepEqual(cluster.Spec.Placement, oldCluster.Spec.Placement) {
+		clusterList := &corev1alpha1.StorageClusterList{}
+		if err := v.client.List(ctx, clusterList, &client.ListOptions{}); err != nil {
+			return admission.Errored(http.StatusInternalServerError,
+				fmt.Errorf("failed to list storage clusters: %v", err))
+		}
+		if oldCluster == nil {
+			errList = append(errList, validateNamespaceUnique(cluster, clusterList.Items)...)
+		}
+		errList = append(errList, validatePlacementOverlap(cluster, clusterList.Items, nodeList.Items)...)
+	}
+
+	if len(errList) > 0 {
+		return invalidResponse(cluster, errList)
+	}
+	return admission.Allowed("")
+}
+
+// ValidateStorageCluster validates the spec of the given StorageCluster. The old
+// cluster is nil for new StorageClusters. Nodes are used to detect node specs
+// that select the same node.
+func ValidateStorageCluster(
+	cluster, oldCluster *corev1alpha1.StorageCluster,
+	nodes []v1.Node,
+) field.ErrorList {
+	specPath := field.NewPath("spec")
+	errList := field.ErrorList{}
+
+	errList = append(errList, validateStorage(&cluster.Spec, specPath)...)
+	errList = append(errList, validateRuntimeOptions(cluster.Spec.RuntimeOpts, specPath.Child("runtimeOptions"))...)
+	errList = append(errList, validateUpdateStrategy(&cluster.Spec.UpdateStrategy, specPath.Child("updateStrategy"))...)
+	errList = append(errList, validateNodeSpecs(cluster.Spec.Nodes, nodes, specPath.Child("nodes"))...)
+	errList = append(errList, validateSecurity(cluster.Spec.Security, specPath.Child("security"))...)
+	if rollbackTo := cluster.Spec.RollbackTo; rollbackTo != nil && rollbackTo.Revision < 0 {
+		errList = append(errList, field.Invalid(specPath.Child("rollbackTo", "revision"),
+			rollbackTo.Revision, "must be greater than or equal to 0"))
+	}
+	// A rollback is done by the operator by clearing spec.rollbackTo along with
+	// restoring the spec. The compatibility of the restored image is checked by
+	// the storage driver, so older versions are allowed in that case.
+	isRollback := oldCluster != nil && oldCluster.Spec.RollbackTo != nil && cluster.Spec.RollbackTo == nil
+	if oldCluster != nil && !isRollback {
+		errList = append(errList, validateImageUpdate(cluster.Spec.Image, oldCluster.Spec.Image, specPath.Child("image"))...)
+	}
+	return errList
+}
+
+func validateStorage(
+	spec *corev1alpha1.StorageClusterSpec,
+	specPath *field.Path,
+) field.ErrorList {
+	errList := field.ErrorList{}
+	if spec.CloudStorage == nil {
+		return errList
+	}
+
+	cloudStoragePath := specPath.Child("cloudStorage")
+	if spec.Storage != nil {
+		errList = append(errList, field.Forbidden(cloudStoragePath,
+			"cannot be specified together with spec.storage"))
+	}
+
+	for i, capacitySpec := range spec.CloudStorage.CapacitySpecs {
+		if capacitySpec.MaxCapacityInGiB > 0 &&
+			capacitySpec.MinCapacityInGiB > capacitySpec.MaxCapacityInGiB {
+			errList = append(errList, field.Invalid(
+				cloudStoragePath.Child("capacitySpecs").Index(i).Child("minCapacityInGiB"),
+				capacitySpec.MinCapacityInGiB,
+				fmt.Sprintf("must be less than or equal to maxCapacityInGiB (%d)",
+					capacitySpec.MaxCapacityInGiB),
+			))
+		}
+		if capacitySpec.MaxCapacityInGiB > 0 &&
+			capacitySpec.PoolSizeInGiB > capacitySpec.MaxCapacityInGiB {
+			errList = append(errList, field.Invalid(
+				cloudStoragePath.Child("capacitySpecs").Index(i).Child("poolSizeInGiB"),
+				capacitySpec.PoolSizeInGiB,
+				fmt.Sprintf("must be less than or equal to maxCapacityInGiB (%d)",
+					capacitySpec.MaxCapacityInGiB),
+			))
+		}
+	}
+	return errList
+}
+
+func validateSecurity(
+	security *corev1alpha1.SecuritySpec,
+	fldPath *field.Path,
+) field.ErrorList {
+	errList := field.ErrorList{}
+	if security == nil || security.Auth == nil {
+		return errList
+	}
+	authPath := fldPath.Child("auth")
+	if selfSigned := security.Auth.SelfSigned; selfSigned != nil &&
+		selfSigned.TokenLifetime != nil && selfSigned.TokenLifetime.Duration < 0 {
+		errList = append(errList, field.Invalid(authPath.Child("selfSigned", "tokenLifetime"),
+			selfSigned.TokenLifetime.Duration.String(), "must not be negative"))
+	}
+	if oidc := security.Auth.OIDC; oidc != nil {
+		if oidc.Issuer == "" {
+			errList = append(errList, field.Required(authPath.Child("oidc", "issuer"), ""))
+		}
+		if oidc.ClientID == "" {
+			errList = append(errList, field.Required(authPath.Child("oidc", "clientId"), ""))
+		}
+	}
+	return errList
+}
+
+func validateRuntimeOptions(
+	runtimeOpts map[string]string,
+	fldPath *field.Path,
+) field.ErrorList {
+	errList := field.ErrorList{}
+	for key, value := range runtimeOpts {
+		if _, err := strconv.Atoi(strings.TrimSpace(value)); err != nil {
+			errList = append(errList, field.Invalid(fldPath.Key(key), value, "must be an integer"))
+		}
+	}
+	return errList
+}
+
+func validateUpdateStrategy(
+	strategy *corev1alpha1.StorageClusterUpdateStrategy,
+	fldPath *field.Path,
+) field.ErrorList {
+	errList := field.ErrorList{}
+	if strategy.RollingUpdate == nil {
+		return errList
+	}
+
+	rollingUpdatePath := fldPath.Child("rollingUpdate")
+	errList = append(errList, validateMaxUnavailable(
+		strategy.RollingUpdate.MaxUnavailable, rollingUpdatePath.Child("maxUnavailable"))...)
+
+	if partition := strategy.RollingUpdate.Partition; partition != nil {
+		partitionPath := rollingUpdatePath.Child("partition")
+		if partition.Ordinal != nil && *partition.Ordinal < 0 {
+			errList = append(errList, field.Invalid(partitionPath.Child("ordinal"),
+				*partition.Ordinal, "must be greater than or equal to 0"))
+		}
+		if partition.Selector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(partition.Selector); err != nil {
+				errList = append(errList, field.Invalid(partitionPath.Child("selector"),
+					partition.Selector, err.Error()))
+			}
+		}
+	}
+
+	if canary := strategy.RollingUpdate.Canary; canary != nil && canary.Nodes < 1 {
+		errList = append(errList, field.Invalid(rollingUpdatePath.Child("canary", "nodes"),
+			canary.Nodes, "must be greater than 0"))
+	}
+
+	switch domain := strategy.RollingUpdate.FailureDomain; domain {
+	case "", corev1alpha1.FailureDomainZone, corev1alpha1.FailureDomainRack:
+	default:
+		errList = append(errList, field.NotSupported(rollingUpdatePath.Child("failureDomain"),
+			domain, []string{string(corev1alpha1.FailureDomainZone), string(corev1alpha1.FailureDomainRack)}))
+	}
+	return errList
+}
+
+func validateMaxUnavailable(
+	maxUnavailable *intstr.IntOrString,
+	maxUnavailablePath *field.Path,
+) field.ErrorList {
+	errList := field.ErrorList{}
+	if maxUnavailable == nil {
+		return errList
+	}
+
+	value := maxUnavailable.IntValue()
+	if maxUnavailable.Type == intstr.String {
+		matches := percentRegex.FindStringSubmatch(maxUnavailable.StrVal)
+		if len(matches) != 2 {
+			return append(errList, field.Invalid(maxUnavailablePath, maxUnavailable.StrVal,
+				"must be an integer or percentage (e.g. '5%')"))
+		}
+		value, _ = strconv.Atoi(matches[1])
+		if value > 100 {
+			return append(errList, field.Invalid(maxUnavailablePath, maxUnavailable.StrVal,
+				"must not be greater than 100%"))
+		}
+	}
+
+	if value < 0 {
+		errList = append(errList, field.Invalid(maxUnavailablePath, maxUnavailable.String(),
+			"must be greater than or equal to 0"))
+	} else if value == 0 {
+		errList = append(errList, field.Invalid(maxUnavailablePath, maxUnavailable.String(),
+			"cannot be 0"))
+	}
+	return errList
+}
+
+func validateNodeSpecs(
+	nodeSpecs []corev1alpha1.NodeSpec,
+	nodes []v1.Node,
+	fldPath *field.Path,
+) field.ErrorList {
+	errList := field.ErrorList{}
+	selectors := make([]labels.Selector, len(nodeSpecs))
+
+	for i, nodeSpec := range nodeSpecs {
+		nodePath := fldPath.Index(i)
+		selectorPath := nodePath.Child("selector")
+		errList = append(errList, validateRuntimeOptions(nodeSpec.RuntimeOpts, nodePath.Child("runtimeOptions"))...)
+
+		if len(nodeSpec.Selector.NodeName) == 0 {
+			selector, err := metav1.LabelSelectorAsSelector(nodeSpec.Selector.LabelSelector)
+			if err != nil {
+				errList = append(errList, field.Invalid(selectorPath.Child("labelSelector"),
+					nodeSpec.Selector.LabelSelector, err.Error()))
+				continue
+			}
+			selectors[i] = selector
+		}
+
+		for j := 0; j < i; j++ {
+			prevSelector := nodeSpecs[j].Selector
+			if len(nodeSpec.Selector.NodeName) > 0 &&
+				nodeSpec.Selector.NodeName == prevSelector.NodeName {
+				errList = append(errList, field.Duplicate(selectorPath.Child("nodeName"),
+					nodeSpec.Selector.NodeName))
+				break
+			} else if len(nodeSpec.Selector.NodeName) == 0 && len(prevSelector.NodeName) == 0 &&
+				nodeSpec.Selector.LabelSelector != nil &&
+				reflect.DeepEqual(nodeSpec.Selector.LabelSelector, prevSelector.LabelSelector) {
+				errList = append(errList, field.Duplicate(selectorPath.Child("labelSelector"),
+					nodeSpec.Selector.LabelSelector))
+				break
+			}
+		}
+	}
+	if len(errList) > 0 {
+		return errList
+	}
+
+	// Node specs are matched in order, so a node that matches multiple node
+	// specs would silently ignore all but the first one.
+	reported := make(map[int]bool)
+	for _, node := range nodes {
+		first := -1
+		for i, nodeSpec := range nodeSpecs {
+			if !nodeSpecMatches(&nodeSpec, selectors[i], &node) {
+				continue
+			}
+			if first < 0 {
+				first = i
+			} else if !reported[i] {
+				reported[i] = true
+				errList = append(errList, field.Invalid(fldPath.Index(i).Child("selector"),
+					nodeSpec.Selector,
+					fmt.Sprintf("overlaps with %s on node %s",
+						fldPath.Index(first).Child("selector"), node.Name)))
+			}
+		}
+	}
+	return errList
+}
+
+func nodeSpecMatches(
+	nodeSpec *corev1alpha1.NodeSpec,
+	selector labels.Selector,
+	node *v1.Node,
+) bool {
+	if len(nodeSpec.Selector.NodeName) > 0 {
+		return nodeSpec.Selector.NodeName == node.Name
+	}
+	return selector != nil && selector.Matches(labels.Set(node.Labels))
+}
+
+// validateImageUpdate rejects downgrades of the storage driver image to an older
+// major or minor release. Images without a semantic version tag are not checked.
+func validateImageUpdate(newImage, oldImage string, fldPath *field.Path) field.ErrorList {
+	errList := field.ErrorList{}
+	newVersion, err := imageVersion(newImage)
+	if err != nil {
+		return errList
+	}
+	oldVersion, err := imageVersion(oldImage)
+	if err != nil {
+		return errList
+	}
+
+	newSegments := newVersion.Segments()
+	oldSegments := oldVersion.Segments()
+	if newSegments[0] < oldSegments[0] ||
+		(newSegments[0] == oldSegments[0] && newSegments[1] < oldSegments[1]) {
+		errList = append(errList, field.Forbidden(fldPath,
+			fmt.Sprintf("downgrade from version %s to %s is not supported",
+				oldVersion.Original(), newVersion.Original())))
+	}
+	return errList
+}
+
+func imageVersion(image string) (*version.Version, error) {
+	tagStart := strings.LastIndex(image, ":")
+	if tagStart < 0 || tagStart < strings.LastIndex(image, "/") {
+		return nil, fmt.Errorf("image %s does not have a tag", image)
+	}
+	return version.NewVersion(image[tagStart+1:])
+}
+
+// validateNamespaceUnique rejects a new StorageCluster if there is already one
+// in the same namespace. The objects created for a cluster, like the services,
+// roles and config maps, are named the same for every cluster, so clusters can
+// only be told apart by their namespace.
+func validateNamespaceUnique(
+	current *corev1alpha1.StorageCluster,
+	clusters []corev1alpha1.StorageCluster,
+) field.ErrorList {
+	errList := field.ErrorList{}
+	for _, cluster := range clusters {
+		if cluster.Namespace != current.Namespace || cluster.Name == current.Name {
+			continue
+		}
+		errList = append(errList, field.Forbidden(field.NewPath("metadata", "namespace"),
+			fmt.Sprintf("StorageCluster %s/%s already exists in the namespace. "+
+				"Only one StorageCluster is allowed per namespace", cluster.Namespace, cluster.Name)))
+	}
+	return errList
+}
+
+// validatePlacementOverlap rejects StorageClusters whose placement selects nodes
+// that are also selected by other StorageClusters, as a node can only run the
+// storage pods of a single cluster.
+func validatePlacementOverlap(
+	current *corev1alpha1.StorageCluster,
+	clusters []corev1alpha1.StorageCluster,
+	nodes []v1.Node,
+) field.ErrorList {
+	errList := field.ErrorList{}
+	for _, cluster := range clusters {
+		if (cluster.Name == current.Name && cluster.Namespace == current.Namespace) ||
+			cluster.DeletionTimestamp != nil {
+			continue
+		}
+		var overlappingNodes []string
+		for _, node := range nodes {
+			if util.PlacementMatchesNode(current.Spec.Placement, &node) &&
+				util.PlacementMatchesNode(cluster.Spec.Placement, &node) {
+				overlappingNodes = append(overlappingNodes, node.Name)
+			}
+		}
+		if len(overlappingNodes) > 0 {
+			errList = append(errList, field.Forbidden(field.NewPath("spec", "placement"),
+				fmt.Sprintf("nodes %v are already selected by StorageCluster %s/%s",
+					overlappingNodes, cluster.Namespace, cluster.Name)))
+		}
+	}
+	return errList
+}
+
+// decodeStorageCluster decodes the given StorageCluster of the given API version
+// and converts it to the v1alpha1 hub version. Field names are common to both
+// versions, so validation errors use the same field paths.
+func decodeStorageCluster(
+	apiVersion string,
+	raw runtime.RawExtension,
+) (*corev1alpha1.StorageCluster, error) {
+	switch apiVersion {
+	case corev1alpha1.SchemeGroupVersion.Version:
+		cluster := &corev1alpha1.StorageCluster{}
+		if err := json.Unmarshal(raw.Raw, cluster); err != nil {
+			return nil, err
+		}
+		return cluster, nil
+	case corev1beta1.SchemeGroupVersion.Version:
+		betaCluster := &corev1beta1.StorageCluster{}
+		if err := json.Unmarshal(raw.Raw, betaCluster); err != nil {
+			return nil, err
+		}
+		cluster := &corev1alpha1.StorageCluster{}
+		if err := betaCluster.ConvertTo(cluster); err != nil {
+			return nil, err
+		}
+		return cluster, nil
+	}
+	return nil, fmt.Errorf("unsupported StorageCluster version %s", apiVersion)
+}
+
+func invalidResponse(
+	cluster *corev1alpha1.StorageCluster,
+	errList field.ErrorList,
+) admission.Response {
+	statusErr := errors.NewInvalid(
+		corev1alpha1.SchemeGroupVersion.WithKind("StorageCluster").GroupKind(),
+		cluster.Name,
+		errList,
+	)
+	return admission.Response{
+		AdmissionResponse: admissionv1beta1.AdmissionResponse{
+			Allowed: false,
+			Result:  &statusErr.ErrStatus,
+		},
+	}
+}
@@ -0,0 +1,584 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	corev1alpha1 "github.com/libopenstorage/operator/pkg/apis/core/v1alpha1"
+	corev1beta1 "github.com/libopenstorage/operator/pkg/apis/core/v1beta1"
+	testutil "github.com/libopenstorage/operator/pkg/util/test"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestValidateStorageAndCloudStorage(t *testing.T) {
+	cluster := &corev1alpha1.StorageCluster{
+		Spec: corev1alpha1.StorageClusterSpec{
+			Storage: &corev1alpha1.StorageSpec{},
+			CloudStorage: &corev1alpha1.CloudStorageSpec{
+				CapacitySpecs: []corev1alpha1.CloudStorageCapacitySpec{
+					{
+						MinCapacityInGiB: 100,
+						MaxCapacityInGiB: 200,
+					},
+					{
+						MinCapacityInGiB: 300,
+						MaxCapacityInGiB: 200,
+					},
+					{
+						MinCapacityInGiB: 300,
+					},
+				},
+			},
+		},
+	}
+
+	errList := ValidateStorageCluster(cluster, nil, nil)
+	require.Len(t, errList, 2)
+	require.Equal(t, field.ErrorTypeForbidden, errList[0].Type)
+	require.Equal(t, "spec.cloudStorage", errList[0].Field)
+	require.Equal(t, field.ErrorTypeInvalid, errList[1].Type)
+	require.Equal(t, "spec.cloudStorage.capacitySpecs[1].minCapacityInGiB", errList[1].Field)
+
+	cluster.Spec.Storage = nil
+	cluster.Spec.CloudStorage.CapacitySpecs[1].MaxCapacityInGiB = 300
+	errList = ValidateStorageCluster(cluster, nil, nil)
+	require.Empty(t, errList)
+
+	// Requested pool size should not be more than the maximum capacity
+	cluster.Spec.CloudStorage.CapacitySpecs[0].PoolSizeInGiB = 250
+	cluster.Spec.CloudStorage.CapacitySpecs[2].PoolSizeInGiB = 250
+	errList = ValidateStorageCluster(cluster, nil, nil)
+	require.Len(t, errList, 1)
+	require.Equal(t, field.ErrorTypeInvalid, errList[0].Type)
+	require.Equal(t, "spec.cloudStorage.capacitySpecs[0].poolSizeInGiB", errList[0].Field)
+
+	cluster.Spec.CloudStorage.CapacitySpecs[0].PoolSizeInGiB = 200
+	errList = ValidateStorageCluster(cluster, nil, nil)
+	require.Empty(t, errList)
+}
+
+func TestValidateRuntimeOptions(t *testing.T) {
+	cluster := &corev1alpha1.StorageCluster{
+		Spec: corev1alpha1.StorageClusterSpec{
+			CommonConfig: corev1alpha1.CommonConfig{
+				RuntimeOpts: map[string]string{
+					"valid":   " 10 ",
+					"invalid": "abc",
+				},
+			},
+			Nodes: []corev1alpha1.NodeSpec{
+				{
+					Selector: corev1alpha1.NodeSelector{
+						NodeName: "node1",
+					},
+					CommonConfig: corev1alpha1.CommonConfig{
+						RuntimeOpts: map[string]string{
+							"invalid": "1.5",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	errList := ValidateStorageCluster(cluster, nil, nil)
+	require.Len(t, errList, 2)
+	require.Equal(t, "spec.runtimeOptions[invalid]", errList[0].Field)
+	require.Equal(t, "spec.nodes[0].runtimeOptions[invalid]", errList[1].Field)
+}
+
+func TestValidateSecurity(t *testing.T) {
+	cluster := &corev1alpha1.StorageCluster{
+		Spec: corev1alpha1.StorageClusterSpec{
+			Security: &corev1alpha1.SecuritySpec{
+				Enabled: true,
+				Auth: &corev1alpha1.AuthSpec{
+					SelfSigned: &corev1alpha1.SelfSignedSpec{
+						TokenLifetime: &metav1.Duration{Duration: time.Hour},
+					},
+					OIDC: &corev1alpha1.OIDCSpec{
+						Issuer:   "https://issuer",
+						ClientID: "client",
+					},
+				},
+			},
+		},
+	}
+
+	errList := ValidateStorageCluster(cluster, nil, nil)
+	require.Empty(t, errList)
+
+	cluster.Spec.Security.Auth.SelfSigned.TokenLifetime.Duration = -time.Hour
+	cluster.Spec.Security.Auth.OIDC.ClientID = ""
+
+	errList = ValidateStorageCluster(cluster, nil, nil)
+	require.Len(t, errList, 2)
+	require.Equal(t, "spec.security.auth.selfSigned.tokenLifetime", errList[0].Field)
+	require.Equal(t, "spec.security.auth.oidc.clientId", errList[1].Field)
+}
+
+func TestValidateMaxUnavailable(t *testing.T) {
+	testCases := []struct {
+		maxUnavailable intstr.IntOrString
+		valid          bool
+	}{
+		{intstr.FromInt(1), true},
+		{intstr.FromString("10%"), true},
+		{intstr.FromString("100%"), true},
+		{intstr.FromInt(0), false},
+		{intstr.FromInt(-1), false},
+		{intstr.FromString("0%"), false},
+		{intstr.FromString("101%"), false},
+		{intstr.FromString("abc"), false},
+		{intstr.FromString("10"), false},
+	}
+
+	for _, tc := range testCases {
+		maxUnavailable := tc.maxUnavailable
+		cluster := &corev1alpha1.StorageCluster{
+			Spec: corev1alpha1.StorageClusterSpec{
+				UpdateStrategy: corev1alpha1.StorageClusterUpdateStrategy{
+					Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+					RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+						MaxUnavailable: &maxUnavailable,
+					},
+				},
+			},
+		}
+
+		errList := ValidateStorageCluster(cluster, nil, nil)
+		if tc.valid {
+			require.Empty(t, errList, "maxUnavailable %s", maxUnavailable.String())
+		} else {
+			require.Len(t, errList, 1, "maxUnavailable %s", maxUnavailable.String())
+			require.Equal(t, "spec.updateStrategy.rollingUpdate.maxUnavailable", errList[0].Field)
+		}
+	}
+}
+
+func TestValidateRollingUpdatePartitionAndCanary(t *testing.T) {
+	cluster := &corev1alpha1.StorageCluster{
+		Spec: corev1alpha1.StorageClusterSpec{
+			UpdateStrategy: corev1alpha1.StorageClusterUpdateStrategy{
+				Type: corev1alpha1.RollingUpdateStorageClusterStrategyType,
+				RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{
+					Partition: &corev1alpha1.RollingUpdatePartition{
+						Ordinal: int32Ptr(2),
+						Selector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"rack": "rack-1"},
+						},
+					},
+					Paused: true,
+					Canary: &corev1alpha1.CanaryUpdate{
+						Nodes: 1,
+					},
+				},
+			},
+		},
+	}
+
+	errList := ValidateStorageCluster(cluster, nil, nil)
+	require.Empty(t, errList)
+
+	cluster.Spec.UpdateStrategy.RollingUpdate.Partition.Ordinal = int32Ptr(-1)
+	cluster.Spec.UpdateStrategy.RollingUpdate.Partition.Selector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      "rack",
+				Operator: "Invalid",
+			},
+		},
+	}
+	cluster.Spec.UpdateStrategy.RollingUpdate.Canary.Nodes = 0
+
+	errList = ValidateStorageCluster(cluster, nil, nil)
+	require.Len(t, errList, 3)
+	require.Equal(t, "spec.updateStrategy.rollingUpdate.partition.ordinal", errList[0].Field)
+	require.Equal(t, "spec.updateStrategy.rollingUpdate.partition.selector", errList[1].Field)
+	require.Equal(t, "spec.updateStrategy.rollingUpdate.canary.nodes", errList[2].Field)
+}
+
+func TestValidateRollingUpdateFailureDomain(t *testing.T) {
+	cluster := &corev1alpha1.StorageCluster{
+		Spec: corev1alpha1.StorageClusterSpec{
+			UpdateStrategy: corev1alpha1.StorageClusterUpdateStrategy{
+				Type:          corev1alpha1.RollingUpdateStorageClusterStrategyType,
+				RollingUpdate: &corev1alpha1.RollingUpdateStorageCluster{},
+			},
+		},
+	}
+
+	for _, domain := range []corev1alpha1.FailureDomainType{
+		"",
+		corev1alpha1.FailureDomainZone,
+		corev1alpha1.FailureDomainRack,
+	} {
+		cluster.Spec.UpdateStrategy.RollingUpdate.FailureDomain = domain
+		errList := ValidateStorageCluster(cluster, nil, nil)
+		require.Empty(t, errList)
+	}
+
+	cluster.Spec.UpdateStrategy.RollingUpdate.FailureDomain = "Region"
+	errList := ValidateStorageCluster(cluster, nil, nil)
+	require.Len(t, errList, 1)
+	require.Equal(t, field.ErrorTypeNotSupported, errList[0].Type)
+	require.Equal(t, "spec.updateStrategy.rollingUpdate.failureDomain", errList[0].Field)
+}
+
+func TestValidateNodeSelectors(t *testing.T) {
+	nodes := []v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node1",
+				Labels: map[string]string{"zone": "a", "rack": "1"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node2",
+				Labels: map[string]string{"zone": "b", "rack": "1"},
+			},
+		},
+	}
+	cluster := &corev1alpha1.StorageCluster{
+		Spec: corev1alpha1.StorageClusterSpec{
+			Nodes: []corev1alpha1.NodeSpec{
+				{
+					Selector: corev1alpha1.NodeSelector{
+						NodeName: "node1",
+					},
+				},
+				{
+					Selector: corev1alpha1.NodeSelector{
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"zone": "b"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Disjoint node selectors should be allowed
+	errList := ValidateStorageCluster(cluster, nil, nodes)
+	require.Empty(t, errList)
+
+	// Node selectors that select the same node should be rejected
+	cluster.Spec.Nodes = append(cluster.Spec.Nodes, corev1alpha1.NodeSpec{
+		Selector: corev1alpha1.NodeSelector{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"rack": "1"},
+			},
+		},
+	})
+	errList = ValidateStorageCluster(cluster, nil, nodes)
+	require.Len(t, errList, 1)
+	require.Equal(t, field.ErrorTypeInvalid, errList[0].Type)
+	require.Equal(t, "spec.nodes[2].selector", errList[0].Field)
+	require.Contains(t, errList[0].Detail, "spec.nodes[0].selector on node node1")
+
+	// Overlapping node selectors are fine if they do not select the same node
+	errList = ValidateStorageCluster(cluster, nil, nil)
+	require.Empty(t, errList)
+
+	// Duplicate node names should be rejected
+	cluster.Spec.Nodes[2].Selector = corev1alpha1.NodeSelector{
+		NodeName: "node1",
+	}
+	errList = ValidateStorageCluster(cluster, nil, nil)
+	require.Len(t, errList, 1)
+	require.Equal(t, field.ErrorTypeDuplicate, errList[0].Type)
+	require.Equal(t, "spec.nodes[2].selector.nodeName", errList[0].Field)
+
+	// Duplicate label selectors should be rejected
+	cluster.Spec.Nodes[2].Selector = corev1alpha1.NodeSelector{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"zone": "b"},
+		},
+	}
+	errList = ValidateStorageCluster(cluster, nil, nil)
+	require.Len(t, errList, 1)
+	require.Equal(t, field.ErrorTypeDuplicate, errList[0].Type)
+	require.Equal(t, "spec.nodes[2].selector.labelSelector", errList[0].Field)
+
+	// Invalid label selectors should be rejected
+	cluster.Spec.Nodes[2].Selector = corev1alpha1.NodeSelector{
+		LabelSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      "zone",
+					Operator: "invalid",
+				},
+			},
+		},
+	}
+	errList = ValidateStorageCluster(cluster, nil, nodes)
+	require.Len(t, errList, 1)
+	require.Equal(t, field.ErrorTypeInvalid, errList[0].Type)
+	require.Equal(t, "spec.nodes[2].selector.labelSelector", errList[0].Field)
+}
+
+func TestValidateImageDowngrade(t *testing.T) {
+	testCases := []struct {
+		oldImage string
+		newImage string
+		valid    bool
+	}{
+		{"portworx/oci-monitor:2.5.0", "portworx/oci-monitor:2.5.1", true},
+		{"portworx/oci-monitor:2.5.0", "portworx/oci-monitor:2.6.0", true},
+		{"portworx/oci-monitor:2.5.1", "portworx/oci-monitor:2.5.0", true},
+		{"portworx/oci-monitor:2.5.0", "portworx/oci-monitor:2.4.0", false},
+		{"portworx/oci-monitor:2.5.0", "portworx/oci-monitor:1.9.9", false},
+		{"registry:5000/portworx/oci-monitor:2.5.0", "registry:5000/portworx/oci-monitor:2.4", false},
+		{"portworx/oci-monitor:2.5.0", "portworx/oci-monitor:latest", true},
+		{"portworx/oci-monitor:latest", "portworx/oci-monitor:2.4.0", true},
+		{"registry:5000/portworx/oci-monitor", "portworx/oci-monitor:2.4.0", true},
+	}
+
+	for _, tc := range testCases {
+		oldCluster := &corev1alpha1.StorageCluster{
+			Spec: corev1alpha1.StorageClusterSpec{
+				Image: tc.oldImage,
+			},
+		}
+		cluster := oldCluster.DeepCopy()
+		cluster.Spec.Image = tc.newImage
+
+		errList := ValidateStorageCluster(cluster, oldCluster, nil)
+		if tc.valid {
+			require.Empty(t, errList, "%s -> %s", tc.oldImage, tc.newImage)
+		} else {
+			require.Len(t, errList, 1, "%s -> %s", tc.oldImage, tc.newImage)
+			require.Equal(t, field.ErrorTypeForbidden, errList[0].Type)
+			require.Equal(t, "spec.image", errList[0].Field)
+		}
+	}
+}
+
+func TestValidateRollback(t *testing.T) {
+	oldCluster := &corev1alpha1.StorageCluster{
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "portworx/oci-monitor:2.5.0",
+		},
+	}
+
+	// Negative revisions should be rejected
+	cluster := oldCluster.DeepCopy()
+	cluster.Spec.RollbackTo = &corev1alpha1.RollbackConfig{Revision: -1}
+	errList := ValidateStorageCluster(cluster, oldCluster, nil)
+	require.Len(t, errList, 1)
+	require.Equal(t, "spec.rollbackTo.revision", errList[0].Field)
+
+	cluster.Spec.RollbackTo.Revision = 0
+	errList = ValidateStorageCluster(cluster, oldCluster, nil)
+	require.Empty(t, errList)
+
+	// Downgrades should be allowed when the spec is restored during a rollback
+	oldCluster.Spec.RollbackTo = &corev1alpha1.RollbackConfig{Revision: 1}
+	cluster = oldCluster.DeepCopy()
+	cluster.Spec.Image = "portworx/oci-monitor:2.4.0"
+	cluster.Spec.RollbackTo = nil
+	errList = ValidateStorageCluster(cluster, oldCluster, nil)
+	require.Empty(t, errList)
+
+	// Downgrades should be rejected if the rollback is not done yet
+	cluster.Spec.RollbackTo = oldCluster.Spec.RollbackTo
+	errList = ValidateStorageCluster(cluster, oldCluster, nil)
+	require.Len(t, errList, 1)
+	require.Equal(t, "spec.image", errList[0].Field)
+}
+
+func TestValidatingHandler(t *testing.T) {
+	existingCluster := &corev1alpha1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "existing",
+			Namespace: "kube-system",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Placement: placementForPool("pool-a"),
+		},
+	}
+	nodeA := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-a",
+			Labels: map[string]string{"pool": "pool-a"},
+		},
+	}
+	nodeB := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-b",
+			Labels: map[string]string{"pool": "pool-b"},
+		},
+	}
+	k8sClient := testutil.FakeK8sClient(existingCluster, nodeA, nodeB)
+	handler := NewValidatingHandler(k8sClient)
+
+	// A second StorageCluster selecting the same nodes should be rejected
+	cluster := &corev1alpha1.StorageCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1alpha1.SchemeGroupVersion.String(),
+			Kind:       "StorageCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "px-cluster",
+			Namespace: "tenant",
+		},
+		Spec: corev1alpha1.StorageClusterSpec{
+			Image: "portworx/oci-monitor:2.5.0",
+		},
+	}
+	req := admissionRequest(t, admissionv1beta1.Create, corev1alpha1.SchemeGroupVersion.Version, cluster, nil)
+	resp := handler.Handle(context.TODO(), req)
+	require.False(t, resp.Allowed)
+	require.Equal(t, int32(http.StatusUnprocessableEntity), resp.Result.Code)
+	require.Equal(t, metav1.StatusReasonInvalid, resp.Result.Reason)
+	require.Len(t, resp.Result.Details.Causes, 1)
+	require.Equal(t, "spec.placement", resp.Result.Details.Causes[0].Field)
+	require.Contains(t, resp.Result.Message,
+		"nodes [node-a] are already selected by StorageCluster kube-system/existing")
+
+	// A second StorageCluster with disjoint placement should be allowed
+	cluster.Spec.Placement = placementForPool("pool-b")
+	req = admissionRequest(t, admissionv1beta1.Create, corev1alpha1.SchemeGroupVersion.Version, cluster, nil)
+	resp = handler.Handle(context.TODO(), req)
+	require.True(t, resp.Allowed)
+
+	// A second StorageCluster in the same namespace should be rejected, even
+	// with disjoint placement
+	sameNamespaceCluster := cluster.DeepCopy()
+	sameNamespaceCluster.Namespace = existingCluster.Namespace
+	req = admissionRequest(t, admissionv1beta1.Create, corev1alpha1.SchemeGroupVersion.Version, sameNamespaceCluster, nil)
+	resp = handler.Handle(context.TODO(), req)
+	require.False(t, resp.Allowed)
+	require.Len(t, resp.Result.Details.Causes, 1)
+	require.Equal(t, "metadata.namespace", resp.Result.Details.Causes[0].Field)
+	require.Contains(t, resp.Result.Message,
+		"StorageCluster kube-system/existing already exists in the namespace")
+
+	// Updating the placement to overlap with the existing cluster should be rejected
+	err := k8sClient.Create(context.TODO(), cluster.DeepCopy())
+	require.NoError(t, err)
+
+	newCluster := cluster.DeepCopy()
+	newCluster.Spec.Placement = nil
+	req = admissionRequest(t, admissionv1beta1.Update, corev1alpha1.SchemeGroupVersion.Version, newCluster, cluster)
+	resp = handler.Handle(context.TODO(), req)
+	require.False(t, resp.Allowed)
+	require.Equal(t, "spec.placement", resp.Result.Details.Causes[0].Field)
+
+	// Clusters being deleted do not claim any nodes
+	deletionTimestamp := metav1.Now()
+	existingCluster.DeletionTimestamp = &deletionTimestamp
+	err = k8sClient.Update(context.TODO(), existingCluster)
+	require.NoError(t, err)
+
+	resp = handler.Handle(context.TODO(), req)
+	require.True(t, resp.Allowed)
+
+	// Updating the existing StorageCluster should be allowed
+	newCluster = cluster.DeepCopy()
+	newCluster.Spec.Image = "portworx/oci-monitor:2.6.0"
+	req = admissionRequest(t, admissionv1beta1.Update, corev1alpha1.SchemeGroupVersion.Version, newCluster, cluster)
+	resp = handler.Handle(context.TODO(), req)
+	require.True(t, resp.Allowed)
+
+	// Invalid v1beta1 updates should be rejected with the field paths
+	oldBetaCluster := &corev1beta1.StorageCluster{}
+	err = oldBetaCluster.ConvertFrom(newCluster)
+	require.NoError(t, err)
+	betaCluster := oldBetaCluster.DeepCopy()
+	betaCluster.Spec.Image = "portworx/oci-monitor:2.4.0"
+	betaCluster.Spec.Storage = &corev1beta1.StorageSpec{}
+	betaCluster.Spec.CloudStorage = &corev1beta1.CloudStorageSpec{}
+	req = admissionRequest(t, admissionv1beta1.Update, corev1beta1.SchemeGroupVersion.Version, betaCluster, oldBetaCluster)
+	resp = handler.Handle(context.TODO(), req)
+	require.False(t, resp.Allowed)
+	require.Len(t, resp.Result.Details.Causes, 2)
+	require.Equal(t, "spec.cloudStorage", resp.Result.Details.Causes[0].Field)
+	require.Equal(t, "spec.image", resp.Result.Details.Causes[1].Field)
+
+	// Updates that do not change the spec should be allowed
+	req = admissionRequest(t, admissionv1beta1.Update, corev1beta1.SchemeGroupVersion.Version, betaCluster, betaCluster)
+	resp = handler.Handle(context.TODO(), req)
+	require.True(t, resp.Allowed)
+
+	// Updates on a cluster being deleted should be allowed
+	deletedCluster := betaCluster.DeepCopy()
+	deletedCluster.DeletionTimestamp = &deletionTimestamp
+	deletedCluster.Spec.Image = "portworx/oci-monitor:1.0.0"
+	req = admissionRequest(t, admissionv1beta1.Update, corev1beta1.SchemeGroupVersion.Version, deletedCluster, betaCluster)
+	resp = handler.Handle(context.TODO(), req)
+	require.True(t, resp.Allowed)
+
+	// Delete requests should always be allowed
+	req = admissionRequest(t, admissionv1beta1.Delete, corev1alpha1.SchemeGroupVersion.Version, cluster, nil)
+	resp = handler.Handle(context.TODO(), req)
+	require.True(t, resp.Allowed)
+
+	// Objects that cannot be decoded should be rejected
+	req = admissionRequest(t, admissionv1beta1.Create, "v2", cluster, nil)
+	resp = handler.Handle(context.TODO(), req)
+	require.False(t, resp.Allowed)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Result.Code)
+}
+
+func admissionRequest(
+	t *testing.T,
+	operation admissionv1beta1.Operation,
+	apiVersion string,
+	obj, oldObj runtime.Object,
+) admission.Request {
+	req := admission.Request{
+		AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: operation,
+			Kind: metav1.GroupVersionKind{
+				Group:   corev1alpha1.SchemeGroupVersion.Group,
+				Version: apiVersion,
+				Kind:    "StorageCluster",
+			},
+		},
+	}
+	raw, err := json.Marshal(obj)
+	require.NoError(t, err)
+	req.Object = runtime.RawExtension{Raw: raw}
+	if oldObj != nil {
+		raw, err = json.Marshal(oldObj)
+		require.NoError(t, err)
+		req.OldObject = runtime.RawExtension{Raw: raw}
+	}
+	return req
+}
+
+func placementForPool(pool string) *corev1alpha1.PlacementSpec {
+	return &corev1alpha1.PlacementSpec{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{
+								Key:      "pool",
+								Operator: v1.NodeSelectorOpIn,
+								Values:   []string{pool},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func int32Ptr(val int32) *int32 {
+	return &val
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"fmt"
+
+	"github.com/libopenstorage/operator/drivers/storage"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// DefaultPort is the default port on which the webhook server listens
+	DefaultPort = 8443
+	// DefaultServiceName is the default name of the service fronting the webhook server
+	DefaultServiceName = "portworx-operator-webhook"
+	// DefaultCertDir is the default directory where the serving certificates are stored
+	DefaultCertDir = "/tmp/openstorage-operator/serving-certs"
+	// DefaultCertSecretName is the default name of the secret which stores the serving
+	// certificates, so that they are shared by all replicas of the operator
+	DefaultCertSecretName = "portworx-operator-webhook-certs"
+)
+
+// Config contains the configuration of the operator webhooks
+type Config struct {
+	// ServiceName is the name of the service fronting the webhook server
+	ServiceName string
+	// ServiceNamespace is the namespace of the service fronting the webhook server
+	ServiceNamespace string
+	// CertDir is the directory where the serving certificate and key are written
+	CertDir string
+	// CertSecretName is the name of the secret in ServiceNamespace which stores
+	// the serving certificates
+	CertSecretName string
+	// Driver is the storage driver used to set the StorageCluster defaults
+	Driver storage.Driver
+}
+
+// Setup generates the serving certificates for the webhook server, registers
+// the operator webhooks with the manager and configures the API server to call
+// them. The manager should have been created with the same cert directory.
+func Setup(mgr manager.Manager, cfg Config) error {
+	if len(cfg.ServiceName) == 0 || len(cfg.ServiceNamespace) == 0 {
+		return fmt.Errorf("webhook service name and namespace are required")
+	} else if cfg.Driver == nil {
+		return fmt.Errorf("storage driver is required for the webhooks")
+	}
+	if len(cfg.CertDir) == 0 {
+		cfg.CertDir = DefaultCertDir
+	}
+	if len(cfg.CertSecretName) == 0 {
+		cfg.CertSecretName = DefaultCertSecretName
+	}
+
+	caBundle, err := ensureCertificates(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to setup webhook certificates: %v", err)
+	}
+
+	server := mgr.GetWebhookServer()
+	server.Register(ConversionPath, NewConversionHandler())
+	server.Register(DefaultPath, &admission.Webhook{
+		Handler: NewDefaultingHandler(cfg.Driver),
+	})
+	server.Register(ValidatePath, &admission.Webhook{
+		Handler: NewValidatingHandler(mgr.GetClient()),
+	})
+
+	crdClient, err := newCRDClient(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	if err := crdClient.enableConversion(cfg, caBundle); err != nil {
+		return fmt.Errorf("failed to enable conversion webhook: %v", err)
+	}
+
+	k8sClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	if err := registerDefaultingWebhook(k8sClient, cfg, caBundle); err != nil {
+		return fmt.Errorf("failed to register defaulting webhook: %v", err)
+	}
+	if err := registerValidatingWebhook(k8sClient, cfg, caBundle); err != nil {
+		return fmt.Errorf("failed to register validating webhook: %v", err)
+	}
+
+	logrus.Infof("Webhooks registered with service %s/%s",
+		cfg.ServiceNamespace, cfg.ServiceName)
+	return nil
+}